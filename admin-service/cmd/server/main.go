@@ -13,23 +13,41 @@ import (
 	"admin-service/src/applications/services"
 	adminConfig "admin-service/src/infrastructure/config"
 	"admin-service/src/infrastructure/persistence"
+	adminTelemetry "admin-service/src/infrastructure/telemetry"
 	grpcServer "admin-service/src/interfaces/grpc"
+	"admin-service/src/interfaces/rest"
 	"admin-service/src/interfaces/websocket"
 	backendCoreConfig "backend-core/config"
 	"backend-core/database"
 	"backend-core/database/postgresql"
 	grpcauth "backend-core/grpc/interceptors/auth"
 	grpclogging "backend-core/grpc/interceptors/logging"
+	"backend-core/grpc/interceptors/ratelimit"
+	"backend-core/grpc/interceptors/streamlimit"
 	grpcserver "backend-core/grpc/server"
+	"backend-core/inflight"
 	"backend-core/logging"
+	backendhttp "backend-core/middleware/http"
+	"backend-core/security"
 	"backend-core/telemetry"
 	pb "backend-shared/proto/admin"
 	"backend-shared/utils"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 )
 
+// jwtAudience is the audience of the JWTs accepted by the gRPC API and the chat
+const jwtAudience = "microservices-clients"
+
+const (
+	// shutdownTimeout bounds stopping the HTTP server, the chat hub and the gRPC server
+	shutdownTimeout = 10 * time.Second
+	// telemetryShutdownTimeout bounds flushing buffered spans once the servers are stopped
+	telemetryShutdownTimeout = 5 * time.Second
+)
+
 func main() {
 	// Load configuration
 	cfg, err := adminConfig.Load()
@@ -72,8 +90,10 @@ func main() {
 		logger.Warn("Failed to initialize telemetry, continuing without tracing", "error", err)
 	} else {
 		logger.Info("Telemetry initialized successfully", "service", telemetryConfig.ServiceName, "endpoint", telemetryConfig.OTLPEndpoint)
+		// Deferred first so it runs last, after the servers and the database are shut down
 		defer func() {
-			ctx := context.Background()
+			ctx, cancel := context.WithTimeout(context.Background(), telemetryShutdownTimeout)
+			defer cancel()
 			if err := tel.Shutdown(ctx); err != nil {
 				logger.Error("Failed to shutdown telemetry", "error", err)
 			}
@@ -132,44 +152,92 @@ func main() {
 		// Initialize services
 		userEventService := services.NewUserEventService(userEventRepo, logger)
 
+		// Trace HTTP requests when telemetry is enabled, so their metrics carry trace exemplars
+		var tracing gin.HandlerFunc
+		if tel != nil && tel.Config.Enabled {
+			tracing = tel.GinMiddleware()
+		}
+
 		// Start gRPC and HTTP servers
-		startServers(cfg, userEventService, logger)
+		startServers(cfg, userEventService, tracing, logger)
 	} else {
 		logger.Fatal("Failed to extract GORM database")
 	}
 }
 
-func startServers(cfg *adminConfig.Config, userEventService *services.UserEventService, logger *logging.Logger) {
+func startServers(cfg *adminConfig.Config, userEventService *services.UserEventService, tracing gin.HandlerFunc, logger *logging.Logger) {
+	// Count HTTP and gRPC requests being served so shutdown can wait for them
+	tracker := inflight.NewTracker()
+
+	// Record HTTP and gRPC request metrics for Prometheus
+	metricsService := adminTelemetry.NewMetricsService()
+
 	// Start gRPC server
-	go startGRPCServer(cfg, userEventService, logger)
+	grpcSrv := startGRPCServer(cfg, userEventService, tracker, metricsService, logger)
 
 	// Start HTTP server with WebSocket support
-	startHTTPServer(cfg, userEventService, logger)
-}
+	srv, chatHub := startHTTPServer(cfg, userEventService, tracker, metricsService, tracing, logger)
 
-func startGRPCServer(cfg *adminConfig.Config, userEventService *services.UserEventService, logger *logging.Logger) {
-	lis, err := net.Listen("tcp", fmt.Sprintf("%s:%s", cfg.GRPC.Host, cfg.GRPC.Port))
-	if err != nil {
-		logger.Fatal("Failed to listen on gRPC port", "error", err, "port", cfg.GRPC.Port)
+	// Wait for interrupt signal
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	logger.Info("Shutting down server...")
+
+	// Graceful shutdown; every step shares one deadline
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// 1. End event streams; Shutdown would otherwise wait on them until the timeout
+	userEventService.CloseSubscriptions()
+
+	// 2. Stop accepting HTTP requests and wait for the active ones
+	if err := srv.Shutdown(ctx); err != nil {
+		logger.Error("HTTP server forced to shutdown", "error", err)
 	}
 
-	// Build gRPC server with middleware using backend-core
-	grpcServerConfig := &grpcserver.ServerConfig{
-		Host:                 cfg.GRPC.Host,
-		Port:                 cfg.GRPC.Port,
-		MaxMessageSize:       10 * 1024 * 1024, // 10MB
-		ConnectionTimeout:    120 * time.Second,
-		KeepaliveTime:        30 * time.Second,
-		KeepaliveTimeout:     10 * time.Second,
-		MaxConcurrentStreams: 100,
+	// 3. Close chat connections, which Shutdown does not track once upgraded
+	if err := chatHub.Stop(ctx); err != nil {
+		logger.Error("Chat hub forced to shutdown", "error", err)
 	}
 
-	// Configure authentication
+	// 4. Stop accepting gRPC calls and wait for the active ones
+	stopGRPCServer(ctx, grpcSrv, logger)
+
+	// Wait for any request still counted, e.g. on hijacked connections
+	if err := tracker.WaitForInFlight(ctx); err != nil {
+		logger.Warn("Server shut down with requests still in flight", "in_flight", tracker.Count())
+	}
+
+	logger.Info("Server exited")
+}
+
+// stopGRPCServer stops the gRPC server gracefully, cancelling the calls still running when
+// ctx ends
+func stopGRPCServer(ctx context.Context, grpcSrv *grpc.Server, logger *logging.Logger) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcSrv.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		logger.Error("gRPC server forced to shutdown", "error", ctx.Err())
+		grpcSrv.Stop()
+		<-stopped
+	}
+}
+
+// newAuthConfig returns the credentials accepted by both the gRPC API and the REST API
+func newAuthConfig(cfg *adminConfig.Config) *grpcauth.AuthConfig {
 	authConfig := &grpcauth.AuthConfig{
 		Enabled:     cfg.GRPC.Auth.Enabled,
 		JWTSecret:   cfg.GRPC.Auth.JWTSecret,
 		JWTIssuer:   cfg.GRPC.Auth.JWTIssuer,
-		JWTAudience: "microservices-clients",
+		JWTAudience: jwtAudience,
 		ExemptMethods: []string{
 			"/grpc.health.v1.Health/Check",
 			"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
@@ -181,6 +249,55 @@ func startGRPCServer(cfg *adminConfig.Config, userEventService *services.UserEve
 	if cfg.GRPC.Auth.APIKey != "" {
 		authConfig.APIKeys["auth-service"] = cfg.GRPC.Auth.APIKey
 	}
+	return authConfig
+}
+
+// startGRPCServer starts serving the gRPC API in the background
+func startGRPCServer(cfg *adminConfig.Config, userEventService *services.UserEventService, tracker *inflight.Tracker, metricsService *adminTelemetry.MetricsService, logger *logging.Logger) *grpc.Server {
+	lis, err := net.Listen("tcp", fmt.Sprintf("%s:%s", cfg.GRPC.Host, cfg.GRPC.Port))
+	if err != nil {
+		logger.Fatal("Failed to listen on gRPC port", "error", err, "port", cfg.GRPC.Port)
+	}
+
+	// Build gRPC server with middleware using backend-core
+	grpcServerConfig := &grpcserver.ServerConfig{
+		Host:                 cfg.GRPC.Host,
+		Port:                 cfg.GRPC.Port,
+		MaxMessageSize:       10 * 1024 * 1024, // 10MB
+		ConnectionTimeout:    120 * time.Second,
+		KeepaliveTime:        30 * time.Second,
+		KeepaliveTimeout:     10 * time.Second,
+		MaxConcurrentStreams: cfg.GRPC.MaxConcurrentStreams,
+	}
+
+	// Track active streams across all connections and count rejections once the limit is reached
+	streamLimiter, err := streamlimit.NewStreamLimiter(cfg.GRPC.MaxActiveStreams, nil, logger)
+	if err != nil {
+		logger.Fatal("Failed to create gRPC stream limiter", "error", err)
+	}
+
+	// Configure authentication
+	authConfig := newAuthConfig(cfg)
+
+	// Configure per-subject rate limiting; health checks and reflection are never limited
+	var subjectLimiter *ratelimit.SubjectLimiter
+	if cfg.GRPC.RateLimit.Enabled {
+		limitConfig := &ratelimit.SubjectLimitConfig{
+			Default: ratelimit.SubjectLimit{
+				RequestsPerSecond: cfg.GRPC.RateLimit.RequestsPerSecond,
+				Burst:             cfg.GRPC.RateLimit.Burst,
+			},
+			Subjects:      make(map[string]ratelimit.SubjectLimit, len(cfg.GRPC.RateLimit.Subjects)),
+			ExemptMethods: authConfig.ExemptMethods,
+		}
+		for subject, limit := range cfg.GRPC.RateLimit.Subjects {
+			limitConfig.Subjects[subject] = ratelimit.SubjectLimit{
+				RequestsPerSecond: limit.RequestsPerSecond,
+				Burst:             limit.Burst,
+			}
+		}
+		subjectLimiter = ratelimit.NewSubjectLimiter(limitConfig, logger)
+	}
 
 	// Configure logging
 	loggingConfig := &grpclogging.LoggingConfig{
@@ -190,11 +307,16 @@ func startGRPCServer(cfg *adminConfig.Config, userEventService *services.UserEve
 
 	// Build server with all interceptors in proper order
 	grpcSrv := grpcserver.NewServerBuilder(grpcServerConfig).
-		WithRecovery(logger).               // 1. Catch panics first
-		WithLogging(logger, loggingConfig). // 2. Log all requests
-		WithTracing().                      // 3. Add tracing
-		WithAuth(logger, authConfig).       // 4. Authenticate
-		WithValidation().                   // 5. Validate input
+		WithInFlight(tracker).                // 1. Count calls for graceful shutdown
+		WithMetrics(metricsService).          // 2. Record call counts and durations
+		WithRecovery(logger).                 // 3. Catch panics
+		WithStreamLimit(streamLimiter).       // 4. Enforce concurrent stream limit
+		WithLogging(logger, loggingConfig).   // 5. Log all requests
+		WithTracing().                        // 6. Add tracing
+		WithCorrelation().                    // 7. Add request and correlation IDs
+		WithAuth(logger, authConfig).         // 8. Authenticate
+		WithSubjectRateLimit(subjectLimiter). // 9. Rate limit per authenticated subject
+		WithValidation().                     // 10. Validate input
 		Build()
 
 	// Register service
@@ -204,14 +326,19 @@ func startGRPCServer(cfg *adminConfig.Config, userEventService *services.UserEve
 	// Register reflection service for grpc_cli and similar tools
 	reflection.Register(grpcSrv)
 
-	logger.Info("gRPC server starting with middleware", "address", lis.Addr().String(), "auth_enabled", cfg.GRPC.Auth.Enabled, "middleware", "recovery,logging,tracing,auth,validation")
+	logger.Info("gRPC server starting with middleware", "address", lis.Addr().String(), "auth_enabled", cfg.GRPC.Auth.Enabled, "rate_limit_enabled", cfg.GRPC.RateLimit.Enabled, "max_concurrent_streams", grpcServerConfig.MaxConcurrentStreams, "max_active_streams", cfg.GRPC.MaxActiveStreams, "middleware", "inflight,metrics,recovery,streamlimit,logging,tracing,correlation,auth,ratelimit,validation")
 
-	if err := grpcSrv.Serve(lis); err != nil {
-		logger.Fatal("Failed to serve gRPC", "error", err)
-	}
+	go func() {
+		if err := grpcSrv.Serve(lis); err != nil {
+			logger.Fatal("Failed to serve gRPC", "error", err)
+		}
+	}()
+
+	return grpcSrv
 }
 
-func startHTTPServer(cfg *adminConfig.Config, userEventService *services.UserEventService, logger *logging.Logger) {
+// startHTTPServer starts serving the REST API and the chat in the background
+func startHTTPServer(cfg *adminConfig.Config, userEventService *services.UserEventService, tracker *inflight.Tracker, metricsService *adminTelemetry.MetricsService, tracing gin.HandlerFunc, logger *logging.Logger) (*http.Server, *websocket.ChatHub) {
 	// Set Gin mode
 	if cfg.Logging.Level == "debug" {
 		gin.SetMode(gin.DebugMode)
@@ -220,6 +347,16 @@ func startHTTPServer(cfg *adminConfig.Config, userEventService *services.UserEve
 	}
 
 	router := gin.Default()
+	router.Use(backendhttp.NewInFlightMiddleware(tracker).Handler())
+	// Tracing runs before the metrics middleware, so the request span exists when the
+	// request is recorded
+	if tracing != nil {
+		router.Use(tracing)
+	}
+	router.Use(backendhttp.MetricsMiddleware(metricsService.GetBusinessMetrics()))
+
+	// Prometheus scrape endpoint
+	router.GET("/metrics", metricsService.MetricsHandler())
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -231,32 +368,40 @@ func startHTTPServer(cfg *adminConfig.Config, userEventService *services.UserEve
 	})
 
 	// WebSocket chat endpoint
-	chatHub := websocket.NewChatHub(logger)
+	chatHub := websocket.NewChatHub(websocket.KeepaliveConfig{
+		PingInterval: cfg.Chat.PingInterval,
+		PongTimeout:  cfg.Chat.PongTimeout,
+		WriteTimeout: cfg.Chat.WriteTimeout,
+	}, logger)
 	go chatHub.Run()
 
-	chatHandler := websocket.NewChatHandler(chatHub, logger)
+	// Chat connections are authenticated with the same JWTs as gRPC calls
+	chatHandler := websocket.NewChatHandler(chatHub, &security.JWTConfig{
+		Secret:   cfg.GRPC.Auth.JWTSecret,
+		Issuer:   cfg.GRPC.Auth.JWTIssuer,
+		Audience: jwtAudience,
+	}, logger)
 	router.GET("/chat", chatHandler.HandleChat)
 	router.GET("/chat/stats", chatHandler.HandleChatStats)
 
-	// API routes
-	api := router.Group("/api/v1")
+	// API routes accept the same credentials as the gRPC API
+	api := router.Group("/api/v1", rest.RequireAuth(newAuthConfig(cfg), logger))
 	{
 		// User events endpoint (HTTP alternative to gRPC)
-		api.GET("/events", func(c *gin.Context) {
-			// This can be expanded to provide HTTP access to events
-			c.JSON(http.StatusOK, gin.H{
-				"message": "Use gRPC for user events or WebSocket for chat",
-			})
-		})
+		userEventHandler := rest.NewUserEventHandler(userEventService, logger)
+		api.GET("/events", userEventHandler.GetUserEvents)
+		api.GET("/events/stream", userEventHandler.StreamUserEvents)
 	}
 
 	// Create HTTP server
 	srv := &http.Server{
-		Addr:         fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port),
-		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		Addr:              fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port),
+		Handler:           router,
+		ReadTimeout:       15 * time.Second,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      15 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
 	}
 
 	// Start server in goroutine
@@ -268,22 +413,7 @@ func startHTTPServer(cfg *adminConfig.Config, userEventService *services.UserEve
 		}
 	}()
 
-	// Wait for interrupt signal
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	logger.Info("Shutting down server...")
-
-	// Graceful shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := srv.Shutdown(ctx); err != nil {
-		logger.Error("Server forced to shutdown", "error", err)
-	}
-
-	logger.Info("Server exited")
+	return srv, chatHub
 }
 
 // getEnv retrieves an environment variable or returns a default value
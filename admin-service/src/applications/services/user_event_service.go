@@ -13,6 +13,7 @@ import (
 // UserEventService handles business logic for user events
 type UserEventService struct {
 	repo   domain.UserEventRepository
+	stream *userEventStream
 	logger *logging.Logger
 }
 
@@ -20,6 +21,7 @@ type UserEventService struct {
 func NewUserEventService(repo domain.UserEventRepository, logger *logging.Logger) *UserEventService {
 	return &UserEventService{
 		repo:   repo,
+		stream: newUserEventStream(),
 		logger: logger,
 	}
 }
@@ -47,6 +49,7 @@ func (s *UserEventService) RecordUserCreated(ctx context.Context, userID uuid.UU
 			logging.String("user_id", userID.String()))
 		return nil, err
 	}
+	s.stream.publish(event)
 
 	s.logger.Info("User created event recorded",
 		logging.String("event_id", event.ID.String()),
@@ -84,6 +87,7 @@ func (s *UserEventService) RecordUserUpdated(ctx context.Context, userID uuid.UU
 			logging.String("user_id", userID.String()))
 		return nil, err
 	}
+	s.stream.publish(event)
 
 	s.logger.Info("User updated event recorded",
 		logging.String("event_id", event.ID.String()),
@@ -117,6 +121,7 @@ func (s *UserEventService) RecordUserDeleted(ctx context.Context, userID uuid.UU
 			logging.String("user_id", userID.String()))
 		return nil, err
 	}
+	s.stream.publish(event)
 
 	s.logger.Info("User deleted event recorded",
 		logging.String("event_id", event.ID.String()),
@@ -147,7 +152,11 @@ func (s *UserEventService) GetUserEvents(ctx context.Context, userID uuid.UUID,
 		events, total, err = s.repo.GetByUserID(ctx, userID, pageSize, offset)
 	} else if eventType != "" {
 		events, total, err = s.repo.GetByEventType(ctx, eventType, pageSize, offset)
-	} else if !fromDate.IsZero() && !toDate.IsZero() {
+	} else if !fromDate.IsZero() || !toDate.IsZero() {
+		// An open end of the range runs from the first event or up to now
+		if toDate.IsZero() {
+			toDate = time.Now()
+		}
 		events, total, err = s.repo.GetByDateRange(ctx, fromDate, toDate, pageSize, offset)
 	} else {
 		// Get all events with pagination
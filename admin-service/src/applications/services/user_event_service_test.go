@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"admin-service/src/domain"
+	"backend-core/logging"
+
+	"github.com/google/uuid"
+)
+
+// rangeRecordingRepository records the range GetByDateRange was queried with
+type rangeRecordingRepository struct {
+	domain.UserEventRepository
+	from, to time.Time
+	calls    int
+}
+
+func (r *rangeRecordingRepository) GetByDateRange(ctx context.Context, from, to time.Time, limit, offset int) ([]*domain.UserEvent, int64, error) {
+	r.from, r.to = from, to
+	r.calls++
+	return nil, 0, nil
+}
+
+func TestGetUserEventsHonoursEitherEndOfRange(t *testing.T) {
+	logger := logging.NewNopLogger()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		from, to  time.Time
+		wantFrom  time.Time
+		wantTo    time.Time
+		wantToNow bool
+	}{
+		{name: "both ends", from: from, to: to, wantFrom: from, wantTo: to},
+		{name: "only to", to: to, wantTo: to},
+		{name: "only from", from: from, wantFrom: from, wantToNow: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &rangeRecordingRepository{}
+			service := NewUserEventService(repo, logger)
+
+			before := time.Now()
+			if _, _, err := service.GetUserEvents(context.Background(), uuid.Nil, "", tt.from, tt.to, 1, 20); err != nil {
+				t.Fatalf("GetUserEvents() error = %v", err)
+			}
+
+			if repo.calls != 1 {
+				t.Fatalf("GetByDateRange called %d times, want 1", repo.calls)
+			}
+			if !repo.from.Equal(tt.wantFrom) {
+				t.Errorf("from = %v, want %v", repo.from, tt.wantFrom)
+			}
+			if tt.wantToNow {
+				if repo.to.Before(before) {
+					t.Errorf("to = %v, want now", repo.to)
+				}
+			} else if !repo.to.Equal(tt.wantTo) {
+				t.Errorf("to = %v, want %v", repo.to, tt.wantTo)
+			}
+		})
+	}
+}
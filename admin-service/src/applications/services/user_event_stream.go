@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"admin-service/src/domain"
+
+	"github.com/google/uuid"
+)
+
+// subscriptionBufferSize is how many events may wait for a subscriber before it is dropped
+const subscriptionBufferSize = 64
+
+// UserEventSubscription receives the user events recorded after it was created
+type UserEventSubscription struct {
+	events chan *domain.UserEvent
+	stream *userEventStream
+}
+
+// Events returns the channel new events are delivered on. It is closed when the
+// subscription is closed, when the subscriber falls too far behind, or when the
+// service stops streaming.
+func (s *UserEventSubscription) Events() <-chan *domain.UserEvent {
+	return s.events
+}
+
+// Close stops delivery. It is safe to call more than once.
+func (s *UserEventSubscription) Close() {
+	s.stream.remove(s)
+}
+
+// userEventStream fans recorded events out to subscribers. Delivery never blocks the
+// recording path: a subscriber whose buffer is full is dropped and has to resubscribe.
+type userEventStream struct {
+	mu          sync.Mutex
+	subscribers map[*UserEventSubscription]struct{}
+	closed      bool
+}
+
+func newUserEventStream() *userEventStream {
+	return &userEventStream{
+		subscribers: make(map[*UserEventSubscription]struct{}),
+	}
+}
+
+func (st *userEventStream) subscribe() *UserEventSubscription {
+	sub := &UserEventSubscription{
+		events: make(chan *domain.UserEvent, subscriptionBufferSize),
+		stream: st,
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.closed {
+		close(sub.events)
+		return sub
+	}
+	st.subscribers[sub] = struct{}{}
+	return sub
+}
+
+func (st *userEventStream) publish(event *domain.UserEvent) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	for sub := range st.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			delete(st.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+func (st *userEventStream) remove(sub *UserEventSubscription) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if _, ok := st.subscribers[sub]; ok {
+		delete(st.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+func (st *userEventStream) close() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.closed = true
+	for sub := range st.subscribers {
+		delete(st.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+func (st *userEventStream) count() int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return len(st.subscribers)
+}
+
+// SubscribeUserEvents returns a subscription to the events recorded by this instance from
+// now on. The caller must Close it when done.
+func (s *UserEventService) SubscribeUserEvents() *UserEventSubscription {
+	return s.stream.subscribe()
+}
+
+// SubscriberCount returns how many subscriptions are open
+func (s *UserEventService) SubscriberCount() int {
+	return s.stream.count()
+}
+
+// CloseSubscriptions closes every open subscription and refuses new ones, so long-lived
+// streams end before the server shuts down
+func (s *UserEventService) CloseSubscriptions() {
+	s.stream.close()
+}
+
+// GetEventsSince returns up to limit events recorded after the event eventID, oldest first.
+// When more than limit events follow it, the most recent ones are returned.
+func (s *UserEventService) GetEventsSince(ctx context.Context, eventID uuid.UUID, limit int) ([]*domain.UserEvent, error) {
+	last, err := s.repo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, _, err := s.repo.GetByDateRange(ctx, last.EventTime, time.Now(), limit+1, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events since %s: %w", eventID, err)
+	}
+
+	// Events come newest first and include the last event itself
+	since := make([]*domain.UserEvent, 0, len(events))
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].ID != eventID {
+			since = append(since, events[i])
+		}
+	}
+	if len(since) > limit {
+		since = since[len(since)-limit:]
+	}
+	return since, nil
+}
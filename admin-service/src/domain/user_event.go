@@ -16,6 +16,15 @@ const (
 	EventTypeUserDeleted EventType = "user_deleted"
 )
 
+// IsValid reports whether t is one of the known event types
+func (t EventType) IsValid() bool {
+	switch t {
+	case EventTypeUserCreated, EventTypeUserUpdated, EventTypeUserDeleted:
+		return true
+	}
+	return false
+}
+
 // UserEvent represents a user-related event in the system
 type UserEvent struct {
 	ID          uuid.UUID              `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
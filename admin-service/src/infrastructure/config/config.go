@@ -5,6 +5,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -15,12 +16,19 @@ type Config struct {
 	Database DatabaseConfig `yaml:"database"`
 	GRPC     GRPCConfig     `yaml:"grpc"`
 	Logging  LoggingConfig  `yaml:"logging"`
+	Chat     ChatConfig     `yaml:"chat"`
 }
 
 // ServerConfig holds HTTP server configuration
 type ServerConfig struct {
 	Port string `yaml:"port"`
 	Host string `yaml:"host"`
+	// ReadHeaderTimeout is how long a client may take to send the request headers. Connections
+	// that send them more slowly are closed, so slowloris clients cannot hold connections open.
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" mapstructure:"read_header_timeout"`
+	// MaxHeaderBytes bounds the size of the request line and headers; larger requests are
+	// rejected with 431 Request Header Fields Too Large
+	MaxHeaderBytes int `yaml:"max_header_bytes" mapstructure:"max_header_bytes"`
 }
 
 // DatabaseConfig holds database configuration
@@ -40,9 +48,30 @@ type DatabaseConfig struct {
 
 // GRPCConfig holds gRPC server configuration
 type GRPCConfig struct {
-	Port string     `yaml:"port"`
-	Host string     `yaml:"host"`
-	Auth AuthConfig `yaml:"auth"`
+	Port string `yaml:"port"`
+	Host string `yaml:"host"`
+	// MaxConcurrentStreams is the HTTP/2 limit of concurrent streams on one connection
+	MaxConcurrentStreams uint32 `yaml:"max_concurrent_streams" mapstructure:"max_concurrent_streams"`
+	// MaxActiveStreams caps the streams active across all connections; zero disables the cap
+	MaxActiveStreams uint32          `yaml:"max_active_streams" mapstructure:"max_active_streams"`
+	Auth             AuthConfig      `yaml:"auth"`
+	RateLimit        RateLimitConfig `yaml:"rate_limit" mapstructure:"rate_limit"`
+}
+
+// RateLimitConfig holds the per-subject gRPC rate limits. Subjects are API key service
+// names or JWT subjects.
+type RateLimitConfig struct {
+	Enabled           bool    `yaml:"enabled" mapstructure:"enabled"`
+	RequestsPerSecond float64 `yaml:"requests_per_second" mapstructure:"requests_per_second"`
+	Burst             int     `yaml:"burst" mapstructure:"burst"`
+	// Subjects overrides the default limit for individual subjects
+	Subjects map[string]SubjectRateLimit `yaml:"subjects" mapstructure:"subjects"`
+}
+
+// SubjectRateLimit is the rate limit of one subject
+type SubjectRateLimit struct {
+	RequestsPerSecond float64 `yaml:"requests_per_second" mapstructure:"requests_per_second"`
+	Burst             int     `yaml:"burst" mapstructure:"burst"`
 }
 
 // AuthConfig holds authentication configuration
@@ -60,6 +89,17 @@ type LoggingConfig struct {
 	Output string `yaml:"output"`
 }
 
+// ChatConfig holds WebSocket chat configuration
+type ChatConfig struct {
+	// PingInterval is how often the server pings each client
+	PingInterval time.Duration `yaml:"ping_interval" mapstructure:"ping_interval"`
+	// PongTimeout is how long a client may stay silent before its connection is closed;
+	// it must be longer than PingInterval so a live client can answer the ping
+	PongTimeout time.Duration `yaml:"pong_timeout" mapstructure:"pong_timeout"`
+	// WriteTimeout bounds a single write to a client
+	WriteTimeout time.Duration `yaml:"write_timeout" mapstructure:"write_timeout"`
+}
+
 // Load loads configuration from environment variables and config files
 func Load() (*Config, error) {
 	v := viper.New()
@@ -121,14 +161,21 @@ func setDefaults(v *viper.Viper, environment string) {
 	// Server defaults
 	v.SetDefault("server.port", getEnvOrDefault("SERVER_PORT", "8086"))
 	v.SetDefault("server.host", getEnvOrDefault("SERVER_HOST", "0.0.0.0"))
+	v.SetDefault("server.read_header_timeout", getEnvOrDefault("SERVER_READ_HEADER_TIMEOUT", "10s"))
+	v.SetDefault("server.max_header_bytes", getEnvIntOrDefault("SERVER_MAX_HEADER_BYTES", 64<<10))
 
 	// gRPC defaults
 	v.SetDefault("grpc.port", getEnvOrDefault("GRPC_PORT", "50051"))
 	v.SetDefault("grpc.host", getEnvOrDefault("GRPC_HOST", "0.0.0.0"))
+	v.SetDefault("grpc.max_concurrent_streams", getEnvIntOrDefault("GRPC_MAX_CONCURRENT_STREAMS", 100))
+	v.SetDefault("grpc.max_active_streams", getEnvIntOrDefault("GRPC_MAX_ACTIVE_STREAMS", 1000))
 	v.SetDefault("grpc.auth.enabled", getEnvBoolOrDefault("GRPC_AUTH_ENABLED", false))
 	v.SetDefault("grpc.auth.jwt_secret", getEnvOrDefault("JWT_SECRET", ""))
 	v.SetDefault("grpc.auth.jwt_issuer", getEnvOrDefault("JWT_ISSUER", "microservices"))
 	v.SetDefault("grpc.auth.api_key", getEnvOrDefault("GRPC_API_KEY", ""))
+	v.SetDefault("grpc.rate_limit.enabled", getEnvBoolOrDefault("GRPC_RATE_LIMIT_ENABLED", false))
+	v.SetDefault("grpc.rate_limit.requests_per_second", getEnvIntOrDefault("GRPC_RATE_LIMIT_RPS", 50))
+	v.SetDefault("grpc.rate_limit.burst", getEnvIntOrDefault("GRPC_RATE_LIMIT_BURST", 100))
 
 	// Database defaults
 	v.SetDefault("database.host", getEnvOrDefault("DATABASE_HOST", "localhost"))
@@ -142,6 +189,11 @@ func setDefaults(v *viper.Viper, environment string) {
 	v.SetDefault("database.conn_max_lifetime", "1h")
 	v.SetDefault("database.conn_max_idle_time", "30m")
 
+	// Chat defaults
+	v.SetDefault("chat.ping_interval", getEnvOrDefault("CHAT_PING_INTERVAL", "54s"))
+	v.SetDefault("chat.pong_timeout", getEnvOrDefault("CHAT_PONG_TIMEOUT", "60s"))
+	v.SetDefault("chat.write_timeout", getEnvOrDefault("CHAT_WRITE_TIMEOUT", "10s"))
+
 	// Logging defaults
 	v.SetDefault("logging.level", getEnvOrDefault("LOG_LEVEL", getLogLevelForEnv(environment)))
 	v.SetDefault("logging.format", getEnvOrDefault("LOG_FORMAT", "json"))
@@ -199,11 +251,30 @@ func validateConfig(cfg *Config) error {
 	if cfg.Server.Port == "" {
 		return fmt.Errorf("server port is required")
 	}
+	if cfg.Server.ReadHeaderTimeout <= 0 || cfg.Server.MaxHeaderBytes <= 0 {
+		return fmt.Errorf("server read header timeout and max header bytes must be positive")
+	}
 	if cfg.GRPC.Port == "" {
 		return fmt.Errorf("grpc port is required")
 	}
+	if cfg.GRPC.RateLimit.Enabled {
+		if cfg.GRPC.RateLimit.RequestsPerSecond <= 0 || cfg.GRPC.RateLimit.Burst <= 0 {
+			return fmt.Errorf("grpc rate limit requests per second and burst must be positive")
+		}
+		for subject, limit := range cfg.GRPC.RateLimit.Subjects {
+			if limit.RequestsPerSecond <= 0 || limit.Burst <= 0 {
+				return fmt.Errorf("grpc rate limit of subject %q must have a positive requests per second and burst", subject)
+			}
+		}
+	}
 	if cfg.Database.Host == "" {
 		return fmt.Errorf("database host is required")
 	}
+	if cfg.Chat.PingInterval <= 0 || cfg.Chat.PongTimeout <= 0 || cfg.Chat.WriteTimeout <= 0 {
+		return fmt.Errorf("chat ping interval, pong timeout and write timeout must be positive")
+	}
+	if cfg.Chat.PingInterval >= cfg.Chat.PongTimeout {
+		return fmt.Errorf("chat ping interval must be shorter than the pong timeout")
+	}
 	return nil
 }
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+// loadWithoutConfigFile loads the configuration from defaults and the environment only
+func loadWithoutConfigFile(t *testing.T) *Config {
+	t.Helper()
+
+	t.Chdir(t.TempDir())
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	return cfg
+}
+
+func TestLoadKeepsStreamLimitsSeparate(t *testing.T) {
+	cfg := loadWithoutConfigFile(t)
+	if cfg.GRPC.MaxConcurrentStreams != 100 || cfg.GRPC.MaxActiveStreams != 1000 {
+		t.Errorf("stream limits = %d per connection, %d active, want 100 and 1000",
+			cfg.GRPC.MaxConcurrentStreams, cfg.GRPC.MaxActiveStreams)
+	}
+
+	t.Setenv("GRPC_MAX_ACTIVE_STREAMS", "250")
+	cfg = loadWithoutConfigFile(t)
+	if cfg.GRPC.MaxConcurrentStreams != 100 || cfg.GRPC.MaxActiveStreams != 250 {
+		t.Errorf("stream limits = %d per connection, %d active, want 100 and 250",
+			cfg.GRPC.MaxConcurrentStreams, cfg.GRPC.MaxActiveStreams)
+	}
+}
+
+func TestLoadBoundsRequestHeaders(t *testing.T) {
+	cfg := loadWithoutConfigFile(t)
+	if cfg.Server.ReadHeaderTimeout != 10*time.Second || cfg.Server.MaxHeaderBytes != 64<<10 {
+		t.Errorf("header limits = %v, %d bytes, want 10s and 64KB",
+			cfg.Server.ReadHeaderTimeout, cfg.Server.MaxHeaderBytes)
+	}
+
+	t.Setenv("SERVER_READ_HEADER_TIMEOUT", "2s")
+	t.Setenv("SERVER_MAX_HEADER_BYTES", "4096")
+	cfg = loadWithoutConfigFile(t)
+	if cfg.Server.ReadHeaderTimeout != 2*time.Second || cfg.Server.MaxHeaderBytes != 4096 {
+		t.Errorf("header limits = %v, %d bytes, want 2s and 4096",
+			cfg.Server.ReadHeaderTimeout, cfg.Server.MaxHeaderBytes)
+	}
+}
@@ -0,0 +1,166 @@
+package telemetry
+
+import (
+	"time"
+
+	"backend-core/telemetry"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsService exposes admin-service metrics to Prometheus. HTTP requests are recorded
+// through its business metrics, e.g. by the backend-core metrics middleware, and gRPC
+// calls through the gRPC metrics interceptor, which MetricsService implements as a
+// metrics.MetricsReporter.
+type MetricsService struct {
+	registry        *prometheus.Registry
+	businessMetrics *telemetry.BusinessMetrics
+
+	grpcHandledTotal     *prometheus.CounterVec
+	grpcHandlingDuration *prometheus.HistogramVec
+	grpcMsgReceivedTotal *prometheus.CounterVec
+	grpcMsgSentTotal     *prometheus.CounterVec
+}
+
+// NewMetricsService creates a metrics service with its own registry
+func NewMetricsService() *MetricsService {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	httpMetrics := &prometheusHTTPMetrics{
+		requestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total number of HTTP requests",
+		}, []string{"method", "path", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "path"}),
+		errorsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_errors_total",
+			Help: "Total number of HTTP errors",
+		}, []string{"method", "path", "status"}),
+	}
+
+	ms := &MetricsService{
+		registry: registry,
+		// Only the HTTP counters are set, so the business metrics record to Prometheus
+		businessMetrics: &telemetry.BusinessMetrics{
+			HTTPRequestCounter: httpMetrics,
+			HTTPErrorCounter:   httpMetrics,
+		},
+		grpcHandledTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_handled_total",
+			Help: "Total number of gRPC calls completed on the server",
+		}, []string{"method", "code"}),
+		grpcHandlingDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_handling_seconds",
+			Help:    "gRPC call duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+		grpcMsgReceivedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_msg_received_total",
+			Help: "Total number of gRPC messages received",
+		}, []string{"method"}),
+		grpcMsgSentTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_msg_sent_total",
+			Help: "Total number of gRPC messages sent",
+		}, []string{"method"}),
+	}
+
+	registry.MustRegister(
+		httpMetrics.requestsTotal,
+		httpMetrics.requestDuration,
+		httpMetrics.errorsTotal,
+		ms.grpcHandledTotal,
+		ms.grpcHandlingDuration,
+		ms.grpcMsgReceivedTotal,
+		ms.grpcMsgSentTotal,
+	)
+
+	return ms
+}
+
+// GetBusinessMetrics returns the business metrics HTTP requests are recorded with
+func (ms *MetricsService) GetBusinessMetrics() *telemetry.BusinessMetrics {
+	return ms.businessMetrics
+}
+
+// MetricsHandler returns the Prometheus metrics HTTP handler
+func (ms *MetricsService) MetricsHandler() gin.HandlerFunc {
+	h := promhttp.HandlerFor(ms.registry, promhttp.HandlerOpts{
+		Registry: ms.registry,
+		// Exemplars are only exposed in the OpenMetrics format
+		EnableOpenMetrics: true,
+	})
+
+	return func(c *gin.Context) {
+		h.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// RecordRequest implements metrics.MetricsReporter
+func (ms *MetricsService) RecordRequest(method string, code string, duration time.Duration) {
+	ms.grpcHandledTotal.WithLabelValues(method, code).Inc()
+	ms.grpcHandlingDuration.WithLabelValues(method).Observe(duration.Seconds())
+}
+
+// RecordMessageSent implements metrics.MetricsReporter
+func (ms *MetricsService) RecordMessageSent(method string) {
+	ms.grpcMsgSentTotal.WithLabelValues(method).Inc()
+}
+
+// RecordMessageReceived implements metrics.MetricsReporter
+func (ms *MetricsService) RecordMessageReceived(method string) {
+	ms.grpcMsgReceivedTotal.WithLabelValues(method).Inc()
+}
+
+// prometheusHTTPMetrics records the HTTP business metrics to Prometheus. admin-service has
+// no database, cache, Kafka or user metrics of its own, so those are no-ops.
+type prometheusHTTPMetrics struct {
+	requestsTotal   *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	errorsTotal     *prometheus.CounterVec
+}
+
+func (p *prometheusHTTPMetrics) RecordHTTPRequest(method, path, status string, duration float64) {
+	p.requestsTotal.WithLabelValues(method, path, status).Inc()
+	p.requestDuration.WithLabelValues(method, path).Observe(duration)
+}
+
+// RecordHTTPRequestWithTraceID implements telemetry.HTTPExemplarRecorder, keeping the trace ID
+// as an exemplar of the duration bucket the request falls in
+func (p *prometheusHTTPMetrics) RecordHTTPRequestWithTraceID(method, path, status string, duration float64, traceID string) {
+	p.requestsTotal.WithLabelValues(method, path, status).Inc()
+	observer := p.requestDuration.WithLabelValues(method, path)
+	if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok {
+		exemplarObserver.ObserveWithExemplar(duration, prometheus.Labels{"trace_id": traceID})
+		return
+	}
+	observer.Observe(duration)
+}
+
+func (p *prometheusHTTPMetrics) RecordHTTPError(method, path, status string) {
+	p.errorsTotal.WithLabelValues(method, path, status).Inc()
+}
+
+func (p *prometheusHTTPMetrics) RecordDBOperation(operation, table string, duration float64)        {}
+func (p *prometheusHTTPMetrics) SetDBConnectionsActive(count float64)                               {}
+func (p *prometheusHTTPMetrics) RecordCacheHit(cacheType, key string)                               {}
+func (p *prometheusHTTPMetrics) RecordCacheMiss(cacheType, key string)                              {}
+func (p *prometheusHTTPMetrics) RecordCacheOperation(operation, cacheType string, duration float64) {}
+func (p *prometheusHTTPMetrics) RecordKafkaMessageProduced(topic string, duration float64)          {}
+func (p *prometheusHTTPMetrics) RecordKafkaMessageConsumed(topic string)                            {}
+func (p *prometheusHTTPMetrics) RecordUserCreation(username, email string)                          {}
+func (p *prometheusHTTPMetrics) RecordUserRetrieval(userID string)                                  {}
+func (p *prometheusHTTPMetrics) RecordUserActivation(userID string)                                 {}
+func (p *prometheusHTTPMetrics) SetMemoryUsage(bytes float64)                                       {}
+func (p *prometheusHTTPMetrics) SetCPUUsage(percent float64)                                        {}
+func (p *prometheusHTTPMetrics) SetGoroutinesCount(count int64)                                     {}
@@ -0,0 +1,69 @@
+package telemetry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	backendhttp "backend-core/middleware/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// withSpan stands in for the tracing middleware, putting a span with the given sampling
+// decision in the request context
+func withSpan(sampled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		config := trace.SpanContextConfig{
+			TraceID: trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+			SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		}
+		if sampled {
+			config.TraceFlags = trace.FlagsSampled
+		}
+		ctx := trace.ContextWithSpanContext(c.Request.Context(), trace.NewSpanContext(config))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// scrapeOpenMetrics serves one request through the metrics middleware behind tracing and
+// returns the metrics in the OpenMetrics format
+func scrapeOpenMetrics(t *testing.T, tracing gin.HandlerFunc) string {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	ms := NewMetricsService()
+	router := gin.New()
+	router.GET("/metrics", ms.MetricsHandler())
+	router.GET("/users/:id", tracing, backendhttp.MetricsMiddleware(ms.GetBusinessMetrics()), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Body.String()
+}
+
+func TestHTTPDurationKeepsTraceExemplar(t *testing.T) {
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	sampled := scrapeOpenMetrics(t, withSpan(true))
+	if !strings.Contains(sampled, `trace_id="`+traceID+`"`) {
+		t.Errorf("metrics of a sampled request have no exemplar for trace %s:\n%s", traceID, sampled)
+	}
+
+	unsampled := scrapeOpenMetrics(t, withSpan(false))
+	if !strings.Contains(unsampled, "http_request_duration_seconds") {
+		t.Fatalf("unsampled request not recorded:\n%s", unsampled)
+	}
+	if strings.Contains(unsampled, "trace_id=") {
+		t.Errorf("metrics of an unsampled request have an exemplar:\n%s", unsampled)
+	}
+}
@@ -0,0 +1,74 @@
+package rest
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"backend-core/ctxkeys"
+	grpcauth "backend-core/grpc/interceptors/auth"
+	"backend-core/logging"
+	"backend-core/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAuth accepts the same credentials as the gRPC API: a configured service key in the
+// X-API-Key header, or a bearer JWT. Requests are let through unchecked when authentication
+// is disabled.
+func RequireAuth(config *grpcauth.AuthConfig, logger *logging.Logger) gin.HandlerFunc {
+	jwtConfig := &security.JWTConfig{
+		Secret:   config.JWTSecret,
+		Issuer:   config.JWTIssuer,
+		Audience: config.JWTAudience,
+	}
+
+	return func(c *gin.Context) {
+		if !config.Enabled {
+			c.Next()
+			return
+		}
+
+		// Try API key authentication first
+		if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+			if serviceName, valid := validateAPIKey(config, apiKey); valid {
+				ctxkeys.SetUserID(c, serviceName)
+				c.Next()
+				return
+			}
+		}
+
+		// Try JWT authentication
+		token, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !found || token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing or invalid authorization header"})
+			return
+		}
+
+		claims, err := security.ValidateJWT(token, jwtConfig)
+		if err != nil {
+			logger.Warn("JWT validation failed",
+				logging.String("path", c.FullPath()),
+				logging.Error(err))
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+
+		userID, _ := claims["user_id"].(string)
+		if userID == "" {
+			userID, _ = claims["sub"].(string)
+		}
+		ctxkeys.SetUserID(c, userID)
+		c.Next()
+	}
+}
+
+// validateAPIKey returns the service the API key was issued to
+func validateAPIKey(config *grpcauth.AuthConfig, apiKey string) (string, bool) {
+	for serviceName, validKey := range config.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(apiKey), []byte(validKey)) == 1 {
+			return serviceName, true
+		}
+	}
+	return "", false
+}
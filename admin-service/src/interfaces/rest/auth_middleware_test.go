@@ -0,0 +1,76 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"backend-core/ctxkeys"
+	grpcauth "backend-core/grpc/interceptors/auth"
+	"backend-core/logging"
+	"backend-core/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequireAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logging.NewNopLogger()
+
+	authConfig := &grpcauth.AuthConfig{
+		Enabled:     true,
+		JWTSecret:   "test-secret",
+		JWTIssuer:   "auth-service",
+		JWTAudience: "microservices-clients",
+		APIKeys:     map[string]string{"auth-service": "service-key"},
+	}
+	token, err := security.NewJWTManager(authConfig.JWTSecret, time.Minute, authConfig.JWTIssuer, authConfig.JWTAudience).
+		GenerateAccessToken("user-1", "alice", "user")
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	forged, err := security.NewJWTManager("other-secret", time.Minute, authConfig.JWTIssuer, authConfig.JWTAudience).
+		GenerateAccessToken("user-1", "alice", "user")
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	router := gin.New()
+	router.GET("/api/v1/events", RequireAuth(authConfig, logger), func(c *gin.Context) {
+		userID, _ := ctxkeys.GetUserID(c)
+		c.String(http.StatusOK, userID)
+	})
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantStatus int
+		wantUser   string
+	}{
+		{name: "no credentials", wantStatus: http.StatusUnauthorized},
+		{name: "valid JWT", headers: map[string]string{"Authorization": "Bearer " + token}, wantStatus: http.StatusOK, wantUser: "user-1"},
+		{name: "JWT signed with another secret", headers: map[string]string{"Authorization": "Bearer " + forged}, wantStatus: http.StatusUnauthorized},
+		{name: "valid API key", headers: map[string]string{"X-API-Key": "service-key"}, wantStatus: http.StatusOK, wantUser: "auth-service"},
+		{name: "unknown API key", headers: map[string]string{"X-API-Key": "guess"}, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/events", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantUser != "" && rec.Body.String() != tt.wantUser {
+				t.Errorf("user_id = %q, want %q", rec.Body.String(), tt.wantUser)
+			}
+		})
+	}
+}
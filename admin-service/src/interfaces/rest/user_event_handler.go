@@ -0,0 +1,159 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"admin-service/src/applications/services"
+	"admin-service/src/domain"
+	"backend-core/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// UserEventsResponse is a page of user events
+type UserEventsResponse struct {
+	Events     []*domain.UserEvent `json:"events"`
+	Total      int64               `json:"total"`
+	Page       int                 `json:"page"`
+	PageSize   int                 `json:"page_size"`
+	TotalPages int                 `json:"total_pages"`
+}
+
+// UserEventHandler serves user events over HTTP
+type UserEventHandler struct {
+	userEventService *services.UserEventService
+	logger           *logging.Logger
+}
+
+// NewUserEventHandler creates a new user event handler
+func NewUserEventHandler(userEventService *services.UserEventService, logger *logging.Logger) *UserEventHandler {
+	return &UserEventHandler{
+		userEventService: userEventService,
+		logger:           logger,
+	}
+}
+
+// GetUserEvents returns user events, newest first, filtered by ?user_id= and ?type= or by the
+// ?from= and ?to= RFC 3339 time range, and paginated with ?page= and ?page_size=.
+// The time range cannot be combined with the other filters. Either end of the range may be
+// omitted: from defaults to the beginning of time and to defaults to now.
+func (h *UserEventHandler) GetUserEvents(c *gin.Context) {
+	var userID uuid.UUID
+	if value := c.Query("user_id"); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "user_id must be a UUID"})
+			return
+		}
+		userID = parsed
+	}
+
+	eventType := domain.EventType(c.Query("type"))
+	if eventType != "" && !eventType.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown event type"})
+		return
+	}
+
+	fromDate, ok := parseTimeParam(c, "from")
+	if !ok {
+		return
+	}
+	toDate, ok := parseTimeParam(c, "to")
+	if !ok {
+		return
+	}
+	hasRange := !fromDate.IsZero() || !toDate.IsZero()
+	if hasRange {
+		if userID != uuid.Nil || eventType != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from and to cannot be combined with user_id or type"})
+			return
+		}
+		if toDate.IsZero() {
+			toDate = time.Now()
+		}
+		if fromDate.After(toDate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+			return
+		}
+	}
+
+	page, ok := parseIntParam(c, "page", 1, 1, 0)
+	if !ok {
+		return
+	}
+	pageSize, ok := parseIntParam(c, "page_size", defaultPageSize, 1, maxPageSize)
+	if !ok {
+		return
+	}
+
+	events, total, err := h.userEventService.GetUserEvents(
+		c.Request.Context(),
+		userID,
+		eventType,
+		fromDate,
+		toDate,
+		page,
+		pageSize,
+	)
+	if err != nil {
+		h.logger.Error("Failed to get user events", logging.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get events"})
+		return
+	}
+
+	if events == nil {
+		events = []*domain.UserEvent{}
+	}
+	c.JSON(http.StatusOK, &UserEventsResponse{
+		Events:     events,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+	})
+}
+
+// parseTimeParam parses the RFC 3339 query parameter name, returning the zero time when it is
+// absent. It writes a 400 response and returns false when the value is invalid.
+func parseTimeParam(c *gin.Context, name string) (time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, true
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": name + " must be an RFC 3339 timestamp"})
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// parseIntParam parses the integer query parameter name, returning def when it is absent.
+// The value must be at least min and, when max is positive, at most max. It writes a 400
+// response and returns false when the value is invalid.
+func parseIntParam(c *gin.Context, name string, def, min, max int) (int, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return def, true
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || (max > 0 && n > max) {
+		message := name + " must be an integer of at least " + strconv.Itoa(min)
+		if max > 0 {
+			message = name + " must be an integer between " + strconv.Itoa(min) + " and " + strconv.Itoa(max)
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,131 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"admin-service/src/domain"
+	"backend-core/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxReplayEvents bounds how many missed events are sent to a resuming client
+	maxReplayEvents = 500
+	// streamHeartbeatInterval is how often an idle stream sends a comment, which keeps
+	// proxies from closing it and detects clients that went away
+	streamHeartbeatInterval = 15 * time.Second
+	// streamWriteTimeout bounds a single write to the client
+	streamWriteTimeout = 10 * time.Second
+	// streamRetry is the reconnection delay suggested to clients, in milliseconds
+	streamRetry = 3000
+)
+
+// StreamUserEvents streams user events as server-sent events as they are recorded. Each event
+// carries its ID, so a client reconnecting with the Last-Event-ID header first receives the
+// events it missed, up to maxReplayEvents, and then the live stream. Only events recorded by
+// this instance are streamed live.
+func (h *UserEventHandler) StreamUserEvents(c *gin.Context) {
+	var lastEventID uuid.UUID
+	if value := c.GetHeader("Last-Event-ID"); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Last-Event-ID must be a UUID"})
+			return
+		}
+		lastEventID = parsed
+	}
+
+	// Subscribe before reading missed events so nothing recorded in between is lost
+	sub := h.userEventService.SubscribeUserEvents()
+	defer sub.Close()
+
+	ctx := c.Request.Context()
+	sent := make(map[uuid.UUID]struct{})
+	var missed []*domain.UserEvent
+	if lastEventID != uuid.Nil {
+		events, err := h.userEventService.GetEventsSince(ctx, lastEventID, maxReplayEvents)
+		if err != nil {
+			h.logger.Warn("Failed to replay user events, streaming live events only",
+				logging.Error(err),
+				logging.String("last_event_id", lastEventID.String()))
+		}
+		missed = events
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// The server read timeout would otherwise cancel the request context mid-stream;
+	// a client disconnect is still noticed by the connection's background read
+	rc := http.NewResponseController(c.Writer)
+	if err := rc.SetReadDeadline(time.Time{}); err != nil {
+		h.logger.Warn("Failed to clear user event stream read deadline", logging.Error(err))
+	}
+	write := func(format string, args ...interface{}) bool {
+		// The server write timeout would otherwise end the stream
+		if err := rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil {
+			h.logger.Warn("Failed to set user event stream write deadline", logging.Error(err))
+		}
+		if _, err := fmt.Fprintf(c.Writer, format, args...); err != nil {
+			return false
+		}
+		c.Writer.Flush()
+		return true
+	}
+
+	if !write("retry: %d\n\n", streamRetry) {
+		return
+	}
+	for _, event := range missed {
+		if !h.writeEvent(write, event) {
+			return
+		}
+		sent[event.ID] = struct{}{}
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				// Closed on shutdown or because the client fell behind; it reconnects
+				// with Last-Event-ID and catches up from the database
+				return
+			}
+			if _, ok := sent[event.ID]; ok {
+				continue
+			}
+			if !h.writeEvent(write, event) {
+				return
+			}
+		case <-heartbeat.C:
+			if !write(": heartbeat\n\n") {
+				return
+			}
+		}
+	}
+}
+
+// writeEvent writes event as a server-sent event named after its type
+func (h *UserEventHandler) writeEvent(write func(string, ...interface{}) bool, event *domain.UserEvent) bool {
+	data, err := json.Marshal(event)
+	if err != nil {
+		h.logger.Error("Failed to encode user event",
+			logging.Error(err),
+			logging.String("event_id", event.ID.String()))
+		return true
+	}
+	return write("id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.EventType, data)
+}
@@ -1,21 +1,49 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"net"
 	"net/http"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"backend-core/logging"
+	"backend-core/security"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
+// maxRoomNameLength bounds the room names clients may join
+const maxRoomNameLength = 128
+
+// Keepalive defaults used for unset KeepaliveConfig fields
+const (
+	defaultPingInterval = 54 * time.Second
+	defaultPongTimeout  = 60 * time.Second
+	defaultWriteTimeout = 10 * time.Second
+)
+
+var (
+	errClientNotFound = errors.New("client not connected")
+	errInvalidRoom    = errors.New("invalid room name")
+	errMissingToken   = errors.New("missing token")
+	errInvalidToken   = errors.New("invalid or expired token")
+)
+
+// bearerSubprotocol marks the token in the Sec-WebSocket-Protocol header of browsers, which
+// cannot set the Authorization header on WebSocket requests. Only the marker is echoed back.
+const bearerSubprotocol = "bearer"
+
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
+	Subprotocols:    []string{bearerSubprotocol},
 	CheckOrigin: func(r *http.Request) bool {
 		// In production, implement proper origin checking
 		return true
@@ -29,102 +57,314 @@ type ChatMessage struct {
 	Username  string    `json:"username"`
 	Message   string    `json:"message"`
 	Timestamp time.Time `json:"timestamp"`
-	Type      string    `json:"type"` // "text", "system", "user_joined", "user_left"
+	Type      string    `json:"type"`           // "text", "system", "user_joined", "user_left"
+	Room      string    `json:"room,omitempty"` // empty for messages to every client
+}
+
+// KeepaliveConfig controls how dead connections are detected. The server pings every client
+// each PingInterval, and a client that sends nothing, not even a pong, for PongTimeout is
+// disconnected and removed from the hub.
+type KeepaliveConfig struct {
+	PingInterval time.Duration
+	PongTimeout  time.Duration
+	WriteTimeout time.Duration
 }
 
 // Client represents a connected WebSocket client
 type Client struct {
-	ID       string
+	// ID identifies the connection; one user may have several
+	ID string
+	// UserID is the authenticated user the connection belongs to
+	UserID   string
 	Username string
-	Conn     *websocket.Conn
-	Send     chan *ChatMessage
-	Hub      *ChatHub
+	// Room is the room the client joined when connecting, empty if none
+	Room string
+	Conn *websocket.Conn
+	// Send queues encoded messages for the client's connection
+	Send chan []byte
+	Hub  *ChatHub
 }
 
-// ChatHub manages all connected clients
+// ChatHub manages all connected clients and the rooms they joined.
+// Messages without a room reach every client; messages to a room only reach its members.
 type ChatHub struct {
 	clients    map[string]*Client
+	rooms      map[string]map[*Client]bool
 	broadcast  chan *ChatMessage
 	register   chan *Client
 	unregister chan *Client
 	mu         sync.RWMutex
+	keepalive  KeepaliveConfig
+	reaped     atomic.Int64
 	logger     *logging.Logger
+
+	// quit is closed by Stop; done is closed once Run has disconnected every client
+	quit     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
 }
 
-// NewChatHub creates a new chat hub
-func NewChatHub(logger *logging.Logger) *ChatHub {
+// NewChatHub creates a new chat hub. Zero keepalive fields use the defaults.
+func NewChatHub(keepalive KeepaliveConfig, logger *logging.Logger) *ChatHub {
+	if keepalive.PingInterval <= 0 {
+		keepalive.PingInterval = defaultPingInterval
+	}
+	if keepalive.PongTimeout <= 0 {
+		keepalive.PongTimeout = defaultPongTimeout
+	}
+	if keepalive.WriteTimeout <= 0 {
+		keepalive.WriteTimeout = defaultWriteTimeout
+	}
+
 	return &ChatHub{
 		clients:    make(map[string]*Client),
+		rooms:      make(map[string]map[*Client]bool),
 		broadcast:  make(chan *ChatMessage, 256),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
+		keepalive:  keepalive,
 		logger:     logger,
+		quit:       make(chan struct{}),
+		done:       make(chan struct{}),
 	}
 }
 
-// Run starts the chat hub
+// Run starts the chat hub. It returns after Stop is called.
 func (h *ChatHub) Run() {
+	defer close(h.done)
+
 	for {
 		select {
+		case <-h.quit:
+			h.disconnectAll()
+			return
+
 		case client := <-h.register:
 			h.mu.Lock()
 			h.clients[client.ID] = client
+			if client.Room != "" {
+				h.addToRoom(client, client.Room)
+			}
 			h.mu.Unlock()
 
-			h.logger.Info("Client registered", "client_id", client.ID, "username", client.Username)
+			h.logger.Info("Client registered",
+				logging.String("client_id", client.ID),
+				logging.String("username", client.Username),
+				logging.String("room", client.Room))
 
 			// Send user joined notification
-			h.broadcast <- &ChatMessage{
+			h.dispatch(&ChatMessage{
 				ID:        uuid.New().String(),
-				UserID:    client.ID,
+				UserID:    client.UserID,
 				Username:  client.Username,
 				Message:   client.Username + " joined the chat",
 				Timestamp: time.Now(),
 				Type:      "user_joined",
-			}
+				Room:      client.Room,
+			})
 
 		case client := <-h.unregister:
-			h.mu.Lock()
-			if _, ok := h.clients[client.ID]; ok {
-				delete(h.clients, client.ID)
-				close(client.Send)
-			}
-			h.mu.Unlock()
+			h.removeClient(client)
 
 			h.logger.Info("Client unregistered",
 				logging.String("client_id", client.ID),
 				logging.String("username", client.Username))
 
 			// Send user left notification
-			h.broadcast <- &ChatMessage{
+			h.dispatch(&ChatMessage{
 				ID:        uuid.New().String(),
-				UserID:    client.ID,
+				UserID:    client.UserID,
 				Username:  client.Username,
 				Message:   client.Username + " left the chat",
 				Timestamp: time.Now(),
 				Type:      "user_left",
-			}
+				Room:      client.Room,
+			})
 
 		case message := <-h.broadcast:
-			h.mu.RLock()
-			for _, client := range h.clients {
-				select {
-				case client.Send <- message:
-				default:
-					// Client's send channel is full, close it
-					h.mu.RUnlock()
-					h.mu.Lock()
-					close(client.Send)
-					delete(h.clients, client.ID)
-					h.mu.Unlock()
-					h.mu.RLock()
-				}
-			}
-			h.mu.RUnlock()
+			h.dispatch(message)
 		}
 	}
 }
 
+// Stop stops Run and disconnects every client, waiting until that is done or ctx ends.
+// Connections arriving afterwards are closed right away. It is safe to call more than once.
+func (h *ChatHub) Stop(ctx context.Context) error {
+	h.stopOnce.Do(func() {
+		close(h.quit)
+	})
+
+	select {
+	case <-h.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// disconnectAll closes the connection of every client
+func (h *ChatHub) disconnectAll() {
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.clients))
+	for _, client := range h.clients {
+		clients = append(clients, client)
+	}
+	h.mu.RUnlock()
+
+	// Closing Send makes writePump send a close frame and close the connection
+	for _, client := range clients {
+		h.removeClient(client)
+	}
+
+	h.logger.Info("Chat hub stopped", logging.Int("disconnected_clients", len(clients)))
+}
+
+// JoinRoom adds the client to room. Joining a room the client is already in has no effect.
+func (h *ChatHub) JoinRoom(clientID, room string) error {
+	if !validRoom(room) {
+		return errInvalidRoom
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	client, ok := h.clients[clientID]
+	if !ok {
+		return errClientNotFound
+	}
+	h.addToRoom(client, room)
+	return nil
+}
+
+// LeaveRoom removes the client from room
+func (h *ChatHub) LeaveRoom(clientID, room string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	client, ok := h.clients[clientID]
+	if !ok {
+		return errClientNotFound
+	}
+	h.removeFromRoom(client, room)
+	return nil
+}
+
+// BroadcastToRoom sends msg as is to every client in room. It never blocks: clients whose
+// send queue is full are disconnected.
+func (h *ChatHub) BroadcastToRoom(room string, msg []byte) {
+	h.mu.RLock()
+	recipients := make([]*Client, 0, len(h.rooms[room]))
+	for client := range h.rooms[room] {
+		recipients = append(recipients, client)
+	}
+	h.mu.RUnlock()
+
+	h.deliver(recipients, msg)
+}
+
+// InRoom reports whether the client is a member of room
+func (h *ChatHub) InRoom(clientID, room string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	client, ok := h.clients[clientID]
+	return ok && h.rooms[room][client]
+}
+
+// dispatch encodes message and sends it to its room, or to every client if it has none
+func (h *ChatHub) dispatch(message *ChatMessage) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		h.logger.Error("Failed to encode message",
+			logging.Error(err),
+			logging.String("message_id", message.ID))
+		return
+	}
+
+	if message.Room != "" {
+		h.BroadcastToRoom(message.Room, data)
+		return
+	}
+
+	h.mu.RLock()
+	recipients := make([]*Client, 0, len(h.clients))
+	for _, client := range h.clients {
+		recipients = append(recipients, client)
+	}
+	h.mu.RUnlock()
+
+	h.deliver(recipients, data)
+}
+
+// deliver queues data for each recipient and disconnects those whose send queue is full
+func (h *ChatHub) deliver(recipients []*Client, data []byte) {
+	var slow []*Client
+
+	// Send under the read lock so removeClient cannot close a channel mid-send
+	h.mu.RLock()
+	for _, client := range recipients {
+		if _, connected := h.clients[client.ID]; !connected {
+			continue
+		}
+		select {
+		case client.Send <- data:
+		default:
+			slow = append(slow, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, client := range slow {
+		h.logger.Warn("Client send queue full, disconnecting",
+			logging.String("client_id", client.ID))
+		h.removeClient(client)
+	}
+}
+
+// removeClient disconnects the client and drops it from every room.
+// It is safe to call for a client that was already removed.
+func (h *ChatHub) removeClient(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.clients[client.ID]; !ok {
+		return
+	}
+	delete(h.clients, client.ID)
+	for room := range h.rooms {
+		h.removeFromRoom(client, room)
+	}
+	close(client.Send)
+}
+
+// addToRoom adds client to room. h.mu must be held for writing.
+func (h *ChatHub) addToRoom(client *Client, room string) {
+	members, ok := h.rooms[room]
+	if !ok {
+		members = make(map[*Client]bool)
+		h.rooms[room] = members
+	}
+	members[client] = true
+}
+
+// removeFromRoom removes client from room, dropping the room once it is empty.
+// h.mu must be held for writing.
+func (h *ChatHub) removeFromRoom(client *Client, room string) {
+	members, ok := h.rooms[room]
+	if !ok {
+		return
+	}
+	delete(members, client)
+	if len(members) == 0 {
+		delete(h.rooms, room)
+	}
+}
+
+// validRoom reports whether room can be used as a room name
+func validRoom(room string) bool {
+	return room != "" && len(room) <= maxRoomNameLength
+}
+
 // GetConnectedUsers returns the list of connected users
 func (h *ChatHub) GetConnectedUsers() []string {
 	h.mu.RLock()
@@ -144,23 +384,57 @@ func (h *ChatHub) GetClientCount() int {
 	return len(h.clients)
 }
 
+// GetKeepalive returns the keepalive settings in use
+func (h *ChatHub) GetKeepalive() KeepaliveConfig {
+	return h.keepalive
+}
+
+// GetReapedCount returns the number of connections closed for not answering pings
+func (h *ChatHub) GetReapedCount() int64 {
+	return h.reaped.Load()
+}
+
+// GetRoomClientCounts returns the number of clients in each room
+func (h *ChatHub) GetRoomClientCounts() map[string]int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	counts := make(map[string]int, len(h.rooms))
+	for room, members := range h.rooms {
+		counts[room] = len(members)
+	}
+	return counts
+}
+
 // readPump reads messages from the WebSocket connection
 func (c *Client) readPump() {
 	defer func() {
-		c.Hub.unregister <- c
+		select {
+		case c.Hub.unregister <- c:
+		case <-c.Hub.quit:
+			c.Hub.removeClient(c)
+		}
 		c.Conn.Close()
 	}()
 
-	c.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+	pongTimeout := c.Hub.keepalive.PongTimeout
+	c.Conn.SetReadDeadline(time.Now().Add(pongTimeout))
 	c.Conn.SetPongHandler(func(string) error {
-		c.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+		c.Conn.SetReadDeadline(time.Now().Add(pongTimeout))
 		return nil
 	})
 
 	for {
 		_, messageBytes, err := c.Conn.ReadMessage()
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				// No pong or message within the timeout: the connection is dead
+				c.Hub.reaped.Add(1)
+				c.Hub.logger.Info("Closing unresponsive WebSocket connection",
+					logging.String("client_id", c.ID),
+					logging.Duration("pong_timeout", pongTimeout))
+			} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				c.Hub.logger.Error("WebSocket error",
 					logging.Error(err),
 					logging.String("client_id", c.ID))
@@ -168,6 +442,9 @@ func (c *Client) readPump() {
 			break
 		}
 
+		// Any message proves the client is alive
+		c.Conn.SetReadDeadline(time.Now().Add(pongTimeout))
+
 		// Parse message
 		var msg ChatMessage
 		if err := json.Unmarshal(messageBytes, &msg); err != nil {
@@ -179,21 +456,37 @@ func (c *Client) readPump() {
 
 		// Add metadata
 		msg.ID = uuid.New().String()
-		msg.UserID = c.ID
+		msg.UserID = c.UserID
 		msg.Username = c.Username
 		msg.Timestamp = time.Now()
 		if msg.Type == "" {
 			msg.Type = "text"
 		}
 
+		// Messages go to the room the client connected to unless they name another one,
+		// which the client must have joined
+		if msg.Room == "" {
+			msg.Room = c.Room
+		}
+		if msg.Room != "" && !c.Hub.InRoom(c.ID, msg.Room) {
+			c.Hub.logger.Warn("Client sent message to a room it has not joined",
+				logging.String("client_id", c.ID),
+				logging.String("room", msg.Room))
+			continue
+		}
+
 		// Broadcast message
-		c.Hub.broadcast <- &msg
+		select {
+		case c.Hub.broadcast <- &msg:
+		case <-c.Hub.quit:
+			return
+		}
 	}
 }
 
 // writePump writes messages to the WebSocket connection
 func (c *Client) writePump() {
-	ticker := time.NewTicker(54 * time.Second)
+	ticker := time.NewTicker(c.Hub.keepalive.PingInterval)
 	defer func() {
 		ticker.Stop()
 		c.Conn.Close()
@@ -202,15 +495,14 @@ func (c *Client) writePump() {
 	for {
 		select {
 		case message, ok := <-c.Send:
-			c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			c.Conn.SetWriteDeadline(time.Now().Add(c.Hub.keepalive.WriteTimeout))
 			if !ok {
 				// Hub closed the channel
 				c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
 				return
 			}
 
-			// Write message as JSON
-			if err := c.Conn.WriteJSON(message); err != nil {
+			if err := c.Conn.WriteMessage(websocket.TextMessage, message); err != nil {
 				c.Hub.logger.Error("Failed to write message",
 					logging.Error(err),
 					logging.String("client_id", c.ID))
@@ -218,8 +510,9 @@ func (c *Client) writePump() {
 			}
 
 		case <-ticker.C:
-			c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			c.Conn.SetWriteDeadline(time.Now().Add(c.Hub.keepalive.WriteTimeout))
 			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				// Closing the connection ends readPump, which unregisters the client
 				return
 			}
 		}
@@ -228,24 +521,46 @@ func (c *Client) writePump() {
 
 // ChatHandler handles WebSocket chat connections
 type ChatHandler struct {
-	hub    *ChatHub
-	logger *logging.Logger
+	hub       *ChatHub
+	jwtConfig *security.JWTConfig
+	logger    *logging.Logger
 }
 
-// NewChatHandler creates a new chat handler
-func NewChatHandler(hub *ChatHub, logger *logging.Logger) *ChatHandler {
+// NewChatHandler creates a new chat handler. Connections must present a JWT valid for jwtConfig.
+func NewChatHandler(hub *ChatHub, jwtConfig *security.JWTConfig, logger *logging.Logger) *ChatHandler {
 	return &ChatHandler{
-		hub:    hub,
-		logger: logger,
+		hub:       hub,
+		jwtConfig: jwtConfig,
+		logger:    logger,
 	}
 }
 
-// HandleChat handles WebSocket connections for chat
+// HandleChat handles WebSocket connections for chat. The JWT is read from the Authorization
+// header or, for browsers that cannot set headers on WebSocket requests, from the
+// Sec-WebSocket-Protocol header as the "bearer" subprotocol followed by the token, and is
+// validated before the upgrade. Tokens are never accepted in the URL, where access logs
+// would record them. With ?room= the client joins that room and
+// its messages only reach the room's members.
 func (h *ChatHandler) HandleChat(c *gin.Context) {
-	// Get username from query parameter or use default
-	username := c.Query("username")
-	if username == "" {
-		username = "Anonymous_" + uuid.New().String()[:8]
+	if h.jwtConfig == nil || h.jwtConfig.Secret == "" {
+		h.logger.Error("Chat connection refused, JWT secret not configured")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Chat authentication not configured"})
+		return
+	}
+
+	userID, username, err := h.authenticate(c)
+	if err != nil {
+		h.logger.Warn("Chat authentication failed",
+			logging.Error(err),
+			logging.String("remote_addr", c.ClientIP()))
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	room := c.Query("room")
+	if room != "" && !validRoom(room) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room name"})
+		return
 	}
 
 	// Upgrade connection to WebSocket
@@ -259,26 +574,85 @@ func (h *ChatHandler) HandleChat(c *gin.Context) {
 	// Create client
 	client := &Client{
 		ID:       uuid.New().String(),
+		UserID:   userID,
 		Username: username,
+		Room:     room,
 		Conn:     conn,
-		Send:     make(chan *ChatMessage, 256),
+		Send:     make(chan []byte, 256),
 		Hub:      h.hub,
 	}
 
-	// Register client
-	h.hub.register <- client
+	// Register client, unless the hub is shutting down
+	select {
+	case h.hub.register <- client:
+	case <-h.hub.quit:
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"),
+			time.Now().Add(h.hub.keepalive.WriteTimeout))
+		conn.Close()
+		return
+	}
 
 	// Start read and write pumps
 	go client.writePump()
 	go client.readPump()
 }
 
+// authenticate validates the token of the upgrade request and returns the user it belongs to.
+// The username falls back to the user ID when the token has none.
+func (h *ChatHandler) authenticate(c *gin.Context) (userID, username string, err error) {
+	token := subprotocolToken(websocket.Subprotocols(c.Request))
+	if header := c.GetHeader("Authorization"); header != "" {
+		scheme, value, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, "bearer") {
+			return "", "", errInvalidToken
+		}
+		token = value
+	}
+	if token == "" {
+		return "", "", errMissingToken
+	}
+
+	claims, err := security.ValidateJWT(token, h.jwtConfig)
+	if err != nil {
+		return "", "", errInvalidToken
+	}
+
+	userID, _ = claims["user_id"].(string)
+	if userID == "" {
+		return "", "", errInvalidToken
+	}
+	username, _ = claims["username"].(string)
+	if username == "" {
+		username = userID
+	}
+	return userID, username, nil
+}
+
+// subprotocolToken returns the token offered after the bearer subprotocol, if any
+func subprotocolToken(protocols []string) string {
+	for i, protocol := range protocols {
+		if protocol == bearerSubprotocol && i+1 < len(protocols) {
+			return protocols[i+1]
+		}
+	}
+	return ""
+}
+
 // HandleChatStats returns chat statistics
 func (h *ChatHandler) HandleChatStats(c *gin.Context) {
+	keepalive := h.hub.GetKeepalive()
 	stats := gin.H{
 		"connected_users": h.hub.GetConnectedUsers(),
 		"client_count":    h.hub.GetClientCount(),
-		"timestamp":       time.Now().Format(time.RFC3339),
+		"rooms":           h.hub.GetRoomClientCounts(),
+		"keepalive": gin.H{
+			"ping_interval": keepalive.PingInterval.String(),
+			"pong_timeout":  keepalive.PongTimeout.String(),
+			"write_timeout": keepalive.WriteTimeout.String(),
+		},
+		"reaped_connections": h.hub.GetReapedCount(),
+		"timestamp":          time.Now().Format(time.RFC3339),
 	}
 
 	c.JSON(http.StatusOK, stats)
@@ -0,0 +1,70 @@
+package websocket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend-core/logging"
+	"backend-core/security"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+func newTestChatServer(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	logger := logging.NewNopLogger()
+	jwtConfig := &security.JWTConfig{Secret: "test-secret", Issuer: "auth-service", Audience: "microservices-clients"}
+	token, err := security.NewJWTManager(jwtConfig.Secret, time.Minute, jwtConfig.Issuer, jwtConfig.Audience).
+		GenerateAccessToken("user-1", "alice", "user")
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	hub := NewChatHub(KeepaliveConfig{}, logger)
+	go hub.Run()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		hub.Stop(ctx)
+	})
+
+	router := gin.New()
+	router.GET("/chat", NewChatHandler(hub, jwtConfig, logger).HandleChat)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server, token
+}
+
+func TestHandleChatAcceptsTokenAsSubprotocol(t *testing.T) {
+	server, token := newTestChatServer(t)
+	dialer := websocket.Dialer{Subprotocols: []string{bearerSubprotocol, token}}
+
+	conn, resp, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/chat", nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+
+	if got := resp.Header.Get("Sec-WebSocket-Protocol"); got != bearerSubprotocol {
+		t.Errorf("selected subprotocol = %q, want %q", got, bearerSubprotocol)
+	}
+}
+
+func TestHandleChatRejectsTokenInQuery(t *testing.T) {
+	server, token := newTestChatServer(t)
+
+	_, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/chat?token="+token, nil)
+	if err == nil {
+		t.Fatal("Dial() with the token in the query succeeded, want it rejected")
+	}
+	if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("response = %v, want status %d", resp, http.StatusUnauthorized)
+	}
+}
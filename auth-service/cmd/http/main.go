@@ -17,7 +17,10 @@ import (
 	backendCoreConfig "backend-core/config"
 	"backend-core/database"
 	"backend-core/database/gorm"
+	"backend-core/database/postgresql"
+	"backend-core/inflight"
 	"backend-core/logging"
+	backendhttp "backend-core/middleware/http"
 
 	// "backend-core/telemetry" // Temporarily disabled
 
@@ -95,7 +98,7 @@ func main() {
 	var dbAdapter *adapters.DatabaseAdapter
 	db, dbAdapter, err = initializeDatabaseWithAdapter(cfg.Database, logger)
 	if err != nil {
-		logger.Warn("Failed to initialize database, using in-memory storage",
+		logger.Warn("Failed to initialize database, starting in degraded mode",
 			"error", err,
 			"host", cfg.Database.Host,
 			"port", cfg.Database.Port,
@@ -105,8 +108,12 @@ func main() {
 		dbAdapter = nil
 	}
 
-	// Initialize the complete auth service using service factory
-	serviceFactory := applications.NewServiceFactory(cfg, db, dbAdapter, logger)
+	// Initialize the complete auth service using service factory. Without a database it
+	// starts in degraded mode; only a misconfiguration stops startup here.
+	serviceFactory, err := applications.NewServiceFactory(cfg, db, dbAdapter, logger)
+	if err != nil {
+		logger.Fatal("Failed to create service factory", "error", err)
+	}
 	ginRouter := serviceFactory.CreateRouter(cfg.Kafka.Brokers)
 
 	// Ensure graceful shutdown of service factory
@@ -118,11 +125,11 @@ func main() {
 		}
 	}()
 
+	// Count requests being served so shutdown can wait for them
+	tracker := inflight.NewTracker()
+
 	// Start HTTP server
-	server := &http.Server{
-		Addr:    ":" + cfg.Server.Port,
-		Handler: ginRouter, // Modified
-	}
+	server := cfg.Server.NewHTTPServer(backendhttp.NewInFlightMiddleware(tracker).Wrap(ginRouter))
 
 	// Start server in goroutine
 	go func() {
@@ -145,6 +152,11 @@ func main() {
 		logger.Fatal("Server forced to shutdown:", "error", err)
 	}
 
+	// Shutdown does not wait for hijacked connections, so wait for their handlers too
+	if err := tracker.WaitForInFlight(ctx); err != nil {
+		logger.Warn("Server shut down with requests still in flight", "in_flight", tracker.Count())
+	}
+
 	logger.Info("Server exited")
 }
 
@@ -186,10 +198,11 @@ func initializeDatabaseWithAdapter(cfg config.DatabaseConfig, logger *logging.Lo
 	logger.Info("✅ Database connection established")
 
 	// Create auth-service specific database adapter
-	// Note: We need to cast the database to the correct type for the adapter
+	// Note: The adapter needs a GORM database, so wrap the PostgreSQL database's GORM instance
 	var dbAdapter *adapters.DatabaseAdapter
-	if gormDB, ok := db.(gorm.Database); ok {
-		dbAdapter = adapters.NewDatabaseAdapter(gormDB, logger)
+	if postgresWrapper, ok := db.(*postgresql.DatabaseWrapper); ok && postgresWrapper.GetGormDB() != nil {
+		gormDatabase := gorm.NewGormDatabase(dbConfig, postgresWrapper.GetGormDB(), logger)
+		dbAdapter = adapters.NewDatabaseAdapter(gormDatabase, logger)
 
 		// Initialize database with auth-service specific configuration
 		ctx := context.Background()
@@ -0,0 +1,48 @@
+-- Migration: create_authorization_audit_log_table
+-- Description: Create append-only audit trail of authorization decisions
+
+-- +++++ UP
+-- Create authorization_audit_log table
+CREATE TABLE IF NOT EXISTS authorization_audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id VARCHAR(255) NOT NULL,
+    check_type VARCHAR(20) NOT NULL,
+    resource VARCHAR(100),
+    action VARCHAR(100),
+    role VARCHAR(100),
+    mode VARCHAR(20) NOT NULL,
+    decision VARCHAR(10) NOT NULL,
+    status_code INTEGER NOT NULL,
+    request_id VARCHAR(100),
+    correlation_id VARCHAR(100),
+    method VARCHAR(10),
+    path TEXT,
+    decided_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_authorization_audit_log_user_id ON authorization_audit_log(user_id);
+CREATE INDEX IF NOT EXISTS idx_authorization_audit_log_decided_at ON authorization_audit_log(decided_at);
+CREATE INDEX IF NOT EXISTS idx_authorization_audit_log_correlation_id ON authorization_audit_log(correlation_id);
+
+-- Reject updates and deletes so recorded decisions cannot be altered
+CREATE OR REPLACE FUNCTION prevent_authorization_audit_log_change() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'authorization_audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_authorization_audit_log_immutable ON authorization_audit_log;
+CREATE TRIGGER trg_authorization_audit_log_immutable
+    BEFORE UPDATE OR DELETE ON authorization_audit_log
+    FOR EACH ROW EXECUTE FUNCTION prevent_authorization_audit_log_change();
+
+-- +++++ DOWN
+-- Drop authorization_audit_log table, trigger and indexes
+DROP TRIGGER IF EXISTS trg_authorization_audit_log_immutable ON authorization_audit_log;
+DROP FUNCTION IF EXISTS prevent_authorization_audit_log_change();
+DROP INDEX IF EXISTS idx_authorization_audit_log_correlation_id;
+DROP INDEX IF EXISTS idx_authorization_audit_log_decided_at;
+DROP INDEX IF EXISTS idx_authorization_audit_log_user_id;
+DROP TABLE IF EXISTS authorization_audit_log;
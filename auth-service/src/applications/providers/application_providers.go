@@ -11,6 +11,7 @@ import (
 	// "auth-service/src/infrastructure/telemetry" // Temporarily disabled
 	"auth-service/src/infrastructure/worker"
 	"backend-core/logging"
+	"backend-core/telemetry"
 )
 
 // EventBusProvider creates an event bus
@@ -18,6 +19,20 @@ func EventBusProvider(brokers []string, logger *logging.Logger) services.EventBu
 	return kafka.NewKafkaEventBus(brokers, logger)
 }
 
+// BusinessMetricsProvider creates business metrics recorded through the global OpenTelemetry
+// meter provider, so they are exported once one is installed. It falls back to no-op metrics
+// if the instruments cannot be created.
+func BusinessMetricsProvider(logger *logging.Logger) *telemetry.BusinessMetrics {
+	metrics, err := telemetry.NewBusinessMetrics(&telemetry.Telemetry{
+		Config: telemetry.TelemetryConfig{ServiceName: "auth-service", Enabled: true},
+	})
+	if err != nil {
+		logger.Warn("Failed to create business metrics, continuing without them", logging.Error(err))
+		return telemetry.NewNoopBusinessMetrics()
+	}
+	return metrics
+}
+
 // WorkerPoolProvider creates a worker pool for async task processing
 func WorkerPoolProvider(logger *logging.Logger) *worker.WorkerPool {
 	config := &worker.WorkerPoolConfig{
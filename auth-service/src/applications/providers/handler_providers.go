@@ -5,12 +5,14 @@ import (
 	"time"
 
 	"auth-service/src/applications/services"
+	"auth-service/src/domain/authorization"
 	"auth-service/src/infrastructure/config"
 	"auth-service/src/infrastructure/identity/keycloak"
 	"auth-service/src/interfaces/rest/handlers"
 	"backend-core/cache"
 	"backend-core/logging"
 	"backend-core/security"
+	"backend-core/telemetry"
 )
 
 // UserHandlerProvider creates a user handler
@@ -32,10 +34,12 @@ func AuthHandlerProvider(
 	return handlers.NewAuthHandler(userApplicationService, jwtManager, logger, identityProvider, authConfig)
 }
 
-// KeycloakAdapterProvider creates a Keycloak adapter using config
+// KeycloakAdapterProvider creates a Keycloak adapter using config that records its cache
+// hits and misses in metrics
 func KeycloakAdapterProvider(
 	cfg *config.Config,
 	cache cache.Cache,
+	metrics *telemetry.BusinessMetrics,
 	logger *logging.Logger,
 ) (*keycloak.KeycloakAdapter, error) {
 	fmt.Printf("DEBUG KEYCLOAK CONFIG: BaseURL='%s', Realm='%s', ClientID='%s', ClientSecret='%s'\n",
@@ -54,20 +58,42 @@ func KeycloakAdapterProvider(
 		cacheTTL = 5 * time.Minute
 	}
 
+	// Unset or invalid durations below are left zero, which the adapter replaces with its defaults
+	cacheOperationTimeout := optionalDuration(logger, "cache_operation_timeout", cfg.Keycloak.CacheOperationTimeout)
+	tokenRefreshWindow := optionalDuration(logger, "token_refresh_window", cfg.Keycloak.TokenRefreshWindow)
+	tokenRefreshInterval := optionalDuration(logger, "token_refresh_interval", cfg.Keycloak.TokenRefreshInterval)
+	jwksCacheTTL := optionalDuration(logger, "oauth.jwks_cache_ttl", cfg.Keycloak.OAuth.JWKSCacheTTL)
+	jwksMinRefreshInterval := optionalDuration(logger, "oauth.jwks_min_refresh_interval", cfg.Keycloak.OAuth.JWKSMinRefreshInterval)
+	circuitBreakerCooldown := optionalDuration(logger, "circuit_breaker.cooldown", cfg.Keycloak.CircuitBreaker.Cooldown)
+
 	// Create Keycloak config from application config
 	keycloakConfig := keycloak.KeycloakConfig{
-		BaseURL:         cfg.Keycloak.BaseURL,
-		Realm:           cfg.Keycloak.Realm,
-		ClientID:        cfg.Keycloak.ClientID,
-		ClientSecret:    cfg.Keycloak.ClientSecret,
-		RedirectURI:     cfg.Keycloak.RedirectURI,
-		Scopes:          cfg.Keycloak.Scopes,
-		Timeout:         timeout,
-		RetryAttempts:   cfg.Keycloak.RetryAttempts,
-		CacheTTL:        cacheTTL,
-		EnableSSO:       cfg.Keycloak.EnableSSO,
-		EnableMFA:       cfg.Keycloak.EnableMFA,
-		EnableRiskBased: cfg.Keycloak.EnableRiskBased,
+		BaseURL:               cfg.Keycloak.BaseURL,
+		Realm:                 cfg.Keycloak.Realm,
+		ClientID:              cfg.Keycloak.ClientID,
+		ClientSecret:          cfg.Keycloak.ClientSecret,
+		RedirectURI:           cfg.Keycloak.RedirectURI,
+		Scopes:                cfg.Keycloak.Scopes,
+		Timeout:               timeout,
+		RetryAttempts:         cfg.Keycloak.RetryAttempts,
+		CacheTTL:              cacheTTL,
+		CacheOperationTimeout: cacheOperationTimeout,
+		TokenRefreshWindow:    tokenRefreshWindow,
+		TokenRefreshInterval:  tokenRefreshInterval,
+		EnableSSO:             cfg.Keycloak.EnableSSO,
+		EnableMFA:             cfg.Keycloak.EnableMFA,
+		MFATOTPSkew:           cfg.Keycloak.MFATOTPSkew,
+		EnableRiskBased:       cfg.Keycloak.EnableRiskBased,
+		OAuth: keycloak.OAuthConfig{
+			JWKSCacheTTL:           jwksCacheTTL,
+			JWKSMinRefreshInterval: jwksMinRefreshInterval,
+		},
+		// Zero values are replaced with DefaultCircuitBreakerConfig by the client
+		CircuitBreaker: keycloak.CircuitBreakerConfig{
+			FailureThreshold:  cfg.Keycloak.CircuitBreaker.FailureThreshold,
+			Cooldown:          circuitBreakerCooldown,
+			HalfOpenSuccesses: cfg.Keycloak.CircuitBreaker.HalfOpenSuccesses,
+		},
 	}
 
 	fmt.Printf("DEBUG KEYCLOAK CONFIG: BaseURL='%s', Realm='%s', ClientID='%s'\n",
@@ -85,7 +111,26 @@ func KeycloakAdapterProvider(
 		return nil, err
 	}
 
-	return keycloak.NewKeycloakAdapter(client, cache, keycloakConfig, logger), nil
+	adapter := keycloak.NewKeycloakAdapter(client, cache, keycloakConfig, logger)
+	adapter.SetBusinessMetrics(metrics)
+	return adapter, nil
+}
+
+// optionalDuration parses the Keycloak setting name. An empty value is zero; an invalid one is
+// logged and also zero, so the Keycloak package falls back to its default.
+func optionalDuration(logger *logging.Logger, name, value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		logger.Warn("Invalid Keycloak duration, using the default",
+			logging.String("setting", name),
+			logging.String("value", value),
+			logging.Error(err))
+		return 0
+	}
+	return d
 }
 
 // KeycloakApplicationServiceProvider creates a Keycloak application service
@@ -103,3 +148,14 @@ func KeycloakHandlerProvider(
 ) *handlers.KeycloakHandler {
 	return handlers.NewKeycloakHandler(keycloakService, logger)
 }
+
+// PermissionHandlerProvider creates a handler serving the authenticated user's permissions
+func PermissionHandlerProvider(
+	authConfig *config.AuthorizationConfig,
+	roleRepo authorization.RoleRepository,
+	permissionRepo authorization.PermissionRepository,
+	keycloakAdapter *keycloak.KeycloakAdapter,
+	logger *logging.Logger,
+) *handlers.PermissionHandler {
+	return handlers.NewPermissionHandler(authConfig, roleRepo, permissionRepo, keycloakAdapter, logger)
+}
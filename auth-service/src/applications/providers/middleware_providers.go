@@ -2,13 +2,16 @@ package providers
 
 import (
 	"fmt"
+	"time"
 
 	"auth-service/src/domain/authorization"
+	"auth-service/src/infrastructure/audit"
 	"auth-service/src/infrastructure/config"
 	"auth-service/src/infrastructure/identity/keycloak"
 	"auth-service/src/interfaces/rest/middleware"
 	"backend-core/cache/decorators"
 	backendConfig "backend-core/config"
+	"backend-core/database"
 	"backend-core/logging"
 	"backend-core/security"
 )
@@ -25,6 +28,7 @@ func UnifiedAuthorizationMiddlewareProvider(
 	keycloakAdapter *keycloak.KeycloakAdapter,
 	roleRepo authorization.RoleRepository,
 	permissionRepo authorization.PermissionRepository,
+	auditLogger *audit.AuditLogger,
 	logger *logging.Logger,
 ) *middleware.UnifiedAuthorizationMiddleware {
 	// Debug: Print actual config values
@@ -44,7 +48,7 @@ func UnifiedAuthorizationMiddlewareProvider(
 		cfg.Authorization.Mode = config.AuthorizationModeJWTWithDB
 	}
 
-	return middleware.NewUnifiedAuthorizationMiddleware(
+	m := middleware.NewUnifiedAuthorizationMiddleware(
 		&cfg.Authorization,
 		jwtManager,
 		keycloakAdapter,
@@ -52,6 +56,57 @@ func UnifiedAuthorizationMiddlewareProvider(
 		permissionRepo,
 		logger,
 	)
+	if auditLogger != nil {
+		m.SetAuditLogger(auditLogger)
+	}
+	return m
+}
+
+// AuthorizationAuditLoggerProvider creates and starts the authorization decision audit logger.
+// It returns nil when auditing is disabled or the audit repository cannot be created.
+// The caller must Close it on shutdown to flush queued decisions.
+func AuthorizationAuditLoggerProvider(cfg *config.Config, db database.Database, logger *logging.Logger) *audit.AuditLogger {
+	auditCfg := cfg.Authorization.Audit
+	if !auditCfg.Enabled {
+		return nil
+	}
+
+	repo, err := AuthorizationAuditRepositoryProvider(db, logger)
+	if err != nil {
+		logger.Warn("Authorization audit disabled", logging.Error(err))
+		return nil
+	}
+
+	flushInterval := time.Second
+	if auditCfg.FlushInterval != "" {
+		if flushInterval, err = time.ParseDuration(auditCfg.FlushInterval); err != nil {
+			logger.Warn("Authorization audit disabled, invalid flush interval",
+				logging.String("flush_interval", auditCfg.FlushInterval),
+				logging.Error(err))
+			return nil
+		}
+	}
+
+	auditLogger := audit.NewAuditLogger(repo, audit.Config{
+		BufferSize:    auditCfg.BufferSize,
+		BatchSize:     auditCfg.BatchSize,
+		FlushInterval: flushInterval,
+	}, logger)
+	auditLogger.Start()
+	return auditLogger
+}
+
+// AuthenticationMiddlewareProvider creates the authentication middleware that sets the caller
+// identity for both service JWTs and Keycloak tokens
+func AuthenticationMiddlewareProvider(
+	cfg *config.Config,
+	jwtManager *security.JWTManager,
+	keycloakAdapter *keycloak.KeycloakAdapter,
+	logger *logging.Logger,
+) *middleware.AuthenticationMiddleware {
+	logger.Info("Creating authentication middleware",
+		logging.String("identity_provider", string(cfg.Authorization.IdentityProvider)))
+	return middleware.NewAuthenticationMiddleware(&cfg.Authorization, jwtManager, keycloakAdapter, logger)
 }
 
 // JWTAuthMiddlewareProvider creates a JWT authentication middleware wrapper
@@ -65,11 +120,14 @@ func JWTAuthMiddlewareProvider(
 
 // KeycloakAuthorizationMiddlewareProvider creates a Keycloak authorization middleware
 func KeycloakAuthorizationMiddlewareProvider(
+	cfg *config.AuthorizationConfig,
 	keycloakAdapter *keycloak.KeycloakAdapter,
 	logger *logging.Logger,
 ) *middleware.KeycloakAuthorizationMiddleware {
 	logger.Info("Creating Keycloak authorization middleware")
-	return middleware.NewKeycloakAuthorizationMiddleware(keycloakAdapter, logger)
+	keycloakAuth := middleware.NewKeycloakAuthorizationMiddleware(keycloakAdapter, logger)
+	keycloakAuth.SetIncludeAuthorizationDetails(cfg.IncludeAuthorizationDetails)
+	return keycloakAuth
 }
 
 // CacheMiddlewareProvider creates a cache middleware
@@ -2,14 +2,17 @@ package providers
 
 import (
 	"fmt"
+	"time"
 
 	"auth-service/src/domain/authorization"
 	"auth-service/src/domain/repositories"
 	memoryCache "auth-service/src/infrastructure/cache/memory"
 	redisCache "auth-service/src/infrastructure/cache/redis"
+	authConfig "auth-service/src/infrastructure/config"
 	authorizationRepo "auth-service/src/infrastructure/persistence/authorization"
 	memoryRepo "auth-service/src/infrastructure/persistence/memory"
 	postgresRepo "auth-service/src/infrastructure/persistence/postgres"
+	"backend-core/cache"
 	"backend-core/config"
 	"backend-core/database"
 	"backend-core/database/gorm"
@@ -66,22 +69,92 @@ func UserCacheProvider(db database.Database, logger *logging.Logger) repositorie
 	return memoryCache.NewMemoryUserCache(logger)
 }
 
-// RoleRepositoryProvider creates a role repository
-func RoleRepositoryProvider(db database.Database, logger *logging.Logger) authorization.RoleRepository {
+// PermissionCacheProvider creates the cache shared by the role and permission repositories,
+// so role changes invalidate the permission lists the permission repository caches
+func PermissionCacheProvider(logger *logging.Logger) *cache.CacheManager {
+	redisConfig := &config.RedisConfig{
+		Name:     "auth-service-permissions",
+		Addr:     "localhost:6379", // Use localhost for simplicity
+		Password: "",
+		DB:       0,
+		PoolSize: 10,
+	}
+	logger.Info("Creating permission cache", logging.String("addr", redisConfig.Addr))
+	return cache.NewCacheManager(cache.NewRedisCache(redisConfig))
+}
+
+// RoleRepositoryProvider creates a role repository that invalidates the permission caches in
+// cacheMgr when role grants change; cacheMgr may be nil.
+// It fails if the database does not expose a GORM connection.
+func RoleRepositoryProvider(db database.Database, cacheMgr *cache.CacheManager, logger *logging.Logger) (authorization.RoleRepository, error) {
 	if db == nil {
-		logger.Warn("Database is nil, role repository will not function")
-		return nil
+		return nil, fmt.Errorf("role repository requires a database")
+	}
+
+	return authorizationRepo.NewRoleRepositoryWithCache(db, logger, cacheMgr)
+}
+
+// PermissionRepositoryProvider creates a permission repository caching lookups in cacheMgr for
+// the configured cache_ttl; without cacheMgr lookups are not cached.
+// It fails if the database does not expose a GORM connection.
+func PermissionRepositoryProvider(cfg *authConfig.AuthorizationConfig, db database.Database, cacheMgr *cache.CacheManager, logger *logging.Logger) (authorization.PermissionRepository, error) {
+	if db == nil {
+		return nil, fmt.Errorf("permission repository requires a database")
+	}
+	if cacheMgr == nil {
+		return authorizationRepo.NewPermissionRepository(db, logger)
+	}
+
+	// A zero TTL makes the repository use its default
+	var ttl time.Duration
+	if cacheTTL := cfg.JWTWithDBAuth.CacheTTL; cacheTTL != "" {
+		parsed, err := time.ParseDuration(cacheTTL)
+		if err != nil {
+			logger.Warn("Invalid permission cache TTL, using the default",
+				logging.String("cache_ttl", cacheTTL),
+				logging.Error(err))
+		} else {
+			ttl = parsed
+		}
 	}
 
-	return authorizationRepo.NewRoleRepository(db, logger)
+	return authorizationRepo.NewPermissionRepositoryWithCacheTTL(db, logger, cacheMgr, ttl)
 }
 
-// PermissionRepositoryProvider creates a permission repository
-func PermissionRepositoryProvider(db database.Database, logger *logging.Logger) authorization.PermissionRepository {
+// AuthorizationAuditRepositoryProvider creates the authorization decision audit repository.
+// It fails if the database does not expose a GORM connection.
+func AuthorizationAuditRepositoryProvider(db database.Database, logger *logging.Logger) (authorization.AuthorizationAuditRepository, error) {
 	if db == nil {
-		logger.Warn("Database is nil, permission repository will not function")
+		return nil, fmt.Errorf("authorization audit repository requires a database")
+	}
+
+	return authorizationRepo.NewAuthorizationAuditRepository(db, logger)
+}
+
+// PermissionCacheWarmerProvider creates the startup permission cache warmer.
+// It returns nil when warming is disabled or misconfigured. The repository must be
+// cache-backed for warming to have any effect.
+func PermissionCacheWarmerProvider(cfg *authConfig.AuthorizationConfig, repo authorization.PermissionRepository, logger *logging.Logger) *authorizationRepo.PermissionCacheWarmer {
+	warmerCfg := cfg.JWTWithDBAuth.CacheWarmer
+	if !warmerCfg.Enabled || repo == nil {
 		return nil
 	}
 
-	return authorizationRepo.NewPermissionRepository(db, logger)
+	source, err := authorizationRepo.NewStaticUserSource(warmerCfg.UserIDs)
+	if err != nil {
+		logger.Warn("Permission cache warmer disabled", logging.Error(err))
+		return nil
+	}
+
+	timeout := 30 * time.Second
+	if warmerCfg.Timeout != "" {
+		if timeout, err = time.ParseDuration(warmerCfg.Timeout); err != nil {
+			logger.Warn("Permission cache warmer disabled, invalid timeout",
+				logging.String("timeout", warmerCfg.Timeout),
+				logging.Error(err))
+			return nil
+		}
+	}
+
+	return authorizationRepo.NewPermissionCacheWarmer(repo, source, warmerCfg.MaxUsers, timeout, logger)
 }
@@ -8,7 +8,9 @@ import (
 
 	"auth-service/src/applications/providers"
 	"auth-service/src/applications/services"
+	"auth-service/src/domain/authorization"
 	"auth-service/src/infrastructure/adapters"
+	"auth-service/src/infrastructure/audit"
 	"auth-service/src/infrastructure/config"
 	"auth-service/src/infrastructure/identity/keycloak"
 	"auth-service/src/interfaces/rest/middleware"
@@ -16,6 +18,7 @@ import (
 	// localTelemetry "auth-service/src/infrastructure/telemetry" // Temporarily disabled
 	"auth-service/src/infrastructure/worker"
 	"auth-service/src/interfaces/rest/handlers"
+	"backend-core/cache"
 	"backend-core/database"
 	"backend-core/logging"
 
@@ -31,23 +34,50 @@ type ServiceFactory struct {
 	dbAdapter  *adapters.DatabaseAdapter
 	logger     *logging.Logger
 	workerPool *worker.WorkerPool
+
+	// roleRepo and permissionRepo are only set in jwt_with_db mode, and share permissionCache
+	// so role changes invalidate cached permissions
+	roleRepo        authorization.RoleRepository
+	permissionRepo  authorization.PermissionRepository
+	permissionCache *cache.CacheManager
+
+	// auditLogger records authorization decisions; it is nil when auditing is disabled
+	auditLogger *audit.AuditLogger
+
+	// stopCacheWarmer cancels a permission cache warm-up still running at shutdown
+	stopCacheWarmer context.CancelFunc
+
+	// cacheMiddleware holds the response cache connection; it is nil when the cache is unavailable
+	cacheMiddleware *middleware.CacheMiddleware
+
+	// keycloakAdapter runs the token refresher until Shutdown; it is nil unless Keycloak is the identity provider
+	keycloakAdapter *keycloak.KeycloakAdapter
 }
 
-// NewServiceFactory creates a new service factory
-func NewServiceFactory(cfg *config.Config, db database.Database, dbAdapter *adapters.DatabaseAdapter, logger *logging.Logger) *ServiceFactory {
+// NewServiceFactory creates a new service factory. It fails with a *DependencyError if a
+// dependency the configured modes require is missing, e.g. the Keycloak settings in keycloak
+// mode. A nil db starts the service in degraded mode instead.
+func NewServiceFactory(cfg *config.Config, db database.Database, dbAdapter *adapters.DatabaseAdapter, logger *logging.Logger) (*ServiceFactory, error) {
+	f := &ServiceFactory{
+		cfg:       cfg,
+		db:        db,
+		dbAdapter: dbAdapter,
+		logger:    logger,
+	}
+	if err := f.validateDependencies(); err != nil {
+		return nil, err
+	}
+	if err := f.createAuthorizationRepositories(); err != nil {
+		return nil, err
+	}
+
 	// Create worker pool for async task processing
-	workerPool := providers.WorkerPoolProvider(logger)
+	f.workerPool = providers.WorkerPoolProvider(logger)
 
 	// Temporarily disable telemetry for testing login issue
 	logger.Info("Temporarily disabling telemetry for testing")
 
-	return &ServiceFactory{
-		cfg:        cfg,
-		db:         db,
-		dbAdapter:  dbAdapter,
-		logger:     logger,
-		workerPool: workerPool,
-	}
+	return f, nil
 }
 
 // CreateRouter creates the complete router with all dependencies
@@ -100,8 +130,8 @@ func (f *ServiceFactory) CreateRouter(brokers []string) *gin.Engine {
 	)
 
 	// Create authorization repositories (commented out for now as they're not used in simplified router)
-	// roleRepo := providers.RoleRepositoryProvider(f.db, f.logger)
-	// permissionRepo := providers.PermissionRepositoryProvider(f.db, f.logger)
+	// roleRepo, err := providers.RoleRepositoryProvider(f.db, f.logger)
+	// permissionRepo, err := providers.PermissionRepositoryProvider(f.db, f.logger)
 
 	// Create cache for Keycloak (using a simple in-memory cache for now)
 	// TODO: Use proper cache from backend-core
@@ -112,6 +142,9 @@ func (f *ServiceFactory) CreateRouter(brokers []string) *gin.Engine {
 	// }
 	// keycloakApplicationService := providers.KeycloakApplicationServiceProvider(keycloakAdapter, f.logger)
 
+	// Preload the permissions of the configured users so they do not hit a cold cache
+	f.startPermissionCacheWarmer()
+
 	// Create handlers
 	userHandler := providers.UserHandlerProvider(userApplicationService, f.logger)
 
@@ -123,6 +156,7 @@ func (f *ServiceFactory) CreateRouter(brokers []string) *gin.Engine {
 
 	// Create cache middleware
 	cacheMiddleware := providers.CacheMiddlewareProvider(f.cfg, f.logger)
+	f.cacheMiddleware = cacheMiddleware
 
 	// Create Keycloak authorization middleware
 	var keycloakAdapter *keycloak.KeycloakAdapter
@@ -133,14 +167,17 @@ func (f *ServiceFactory) CreateRouter(brokers []string) *gin.Engine {
 	if f.cfg.Authorization.IdentityProvider == config.IdentityProviderKeycloak {
 		f.logger.Info("Creating Keycloak adapter for authorization")
 		var err error
-		keycloakAdapter, err = providers.KeycloakAdapterProvider(f.cfg, nil, f.logger)
+		keycloakAdapter, err = providers.KeycloakAdapterProvider(f.cfg, nil, providers.BusinessMetricsProvider(f.logger), f.logger)
 		if err != nil {
 			f.logger.Warn("Failed to create Keycloak adapter, authorization may not work", "error", err)
 			keycloakAdapter = nil
 		} else {
 			f.logger.Info("Keycloak adapter created successfully")
+			// Keep cached auth results usable by refreshing their tokens before they expire
+			f.keycloakAdapter = keycloakAdapter
+			keycloakAdapter.StartTokenRefresher(context.Background())
 			// Create Keycloak authorization middleware
-			keycloakAuth = providers.KeycloakAuthorizationMiddlewareProvider(keycloakAdapter, f.logger)
+			keycloakAuth = providers.KeycloakAuthorizationMiddlewareProvider(&f.cfg.Authorization, keycloakAdapter, f.logger)
 			f.logger.Info("Keycloak authorization middleware created successfully")
 		}
 	} else {
@@ -156,6 +193,27 @@ func (f *ServiceFactory) CreateRouter(brokers []string) *gin.Engine {
 	f.logger.Info("Creating route manager")
 	routeManager := providers.RouteManagerProvider(authHandler, userHandler, cacheMiddleware, keycloakAuth, f.logger, telemetryMiddleware)
 
+	// Serve the caller's effective permissions from the source the configured mode enforces against
+	permissionHandler := f.createPermissionHandler(keycloakAdapter)
+	authMiddleware := providers.AuthenticationMiddlewareProvider(f.cfg, jwtManager, keycloakAdapter, f.logger)
+	routeManager.SetPermissionRoutes(permissionHandler, authMiddleware)
+
+	// Let Keycloak users enroll an authenticator for MFA
+	if keycloakAdapter != nil {
+		keycloakService := providers.KeycloakApplicationServiceProvider(keycloakAdapter, f.logger)
+		routeManager.SetMFARoutes(providers.KeycloakHandlerProvider(keycloakService, f.logger), authMiddleware)
+	}
+
+	// Authorize the user routes in the configured mode, auditing every decision
+	if f.cfg.Authorization.Enabled {
+		f.auditLogger = providers.AuthorizationAuditLoggerProvider(f.cfg, f.db, f.logger)
+		unifiedAuth := providers.UnifiedAuthorizationMiddlewareProvider(
+			f.cfg, jwtManager, keycloakAdapter, f.roleRepo, f.permissionRepo, f.auditLogger, f.logger,
+		)
+		routeManager.SetAuthorization(unifiedAuth)
+	}
+	routeManager.SetDegradedMode(f.degradedMode())
+
 	// Setup routes and middleware
 	f.logger.Info("Setting up routes")
 	ginRouter := routeManager.SetupRoutes()
@@ -190,6 +248,21 @@ func (f *ServiceFactory) Shutdown(ctx context.Context) error {
 	// 	}
 	// }
 
+	if f.stopCacheWarmer != nil {
+		f.stopCacheWarmer()
+	}
+
+	if f.keycloakAdapter != nil {
+		f.keycloakAdapter.StopTokenRefresher()
+	}
+
+	// Close the response cache connection
+	if f.cacheMiddleware != nil {
+		if err := f.cacheMiddleware.Close(); err != nil {
+			f.logger.Warn("Failed to close response cache", "error", err)
+		}
+	}
+
 	// Shutdown worker pool
 	if f.workerPool != nil {
 		if err := f.workerPool.Shutdown(ctx); err != nil {
@@ -199,10 +272,55 @@ func (f *ServiceFactory) Shutdown(ctx context.Context) error {
 		f.logger.Info("Worker pool shutdown completed")
 	}
 
+	// Flush the authorization decisions still queued for the audit log
+	if f.auditLogger != nil {
+		if err := f.auditLogger.Close(ctx); err != nil {
+			f.logger.Error("Failed to flush authorization audit log", "error", err)
+			return fmt.Errorf("failed to flush authorization audit log: %w", err)
+		}
+		f.logger.Info("Authorization audit log flushed")
+	}
+
 	f.logger.Info("All service factory components shutdown completed")
 	return nil
 }
 
+// startPermissionCacheWarmer warms the permission cache in the background when the cache
+// warmer is enabled. The warm-up is bounded by its configured timeout and canceled on Shutdown.
+func (f *ServiceFactory) startPermissionCacheWarmer() {
+	warmer := providers.PermissionCacheWarmerProvider(&f.cfg.Authorization, f.permissionRepo, f.logger)
+	if warmer == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f.stopCacheWarmer = cancel
+	go func() {
+		if err := warmer.WarmUp(ctx); err != nil {
+			f.logger.Warn("Permission cache warm-up failed", "error", err)
+		}
+	}()
+}
+
+// degradedMode returns the degraded mode flag, which is enabled when there is no database.
+// Enabling it is logged as a warning, since most of the API is then refused.
+func (f *ServiceFactory) degradedMode() *middleware.DegradedMode {
+	if f.db != nil {
+		return middleware.NewDegradedMode("")
+	}
+
+	f.logger.Warn("⚠️ DEGRADED MODE: auth-service is running without a database",
+		"unavailable", "login, registration, password management and /api/v1/users",
+		"available", "logout, token refresh, status and health endpoints")
+	return middleware.NewDegradedMode("database not connected")
+}
+
+// createPermissionHandler creates the permission handler. The role and permission repositories
+// are only set in jwt_with_db mode with a database, where createAuthorizationRepositories has created them.
+func (f *ServiceFactory) createPermissionHandler(keycloakAdapter *keycloak.KeycloakAdapter) *handlers.PermissionHandler {
+	return providers.PermissionHandlerProvider(&f.cfg.Authorization, f.roleRepo, f.permissionRepo, keycloakAdapter, f.logger)
+}
+
 // addHealthCheckEndpoint adds a health check endpoint that uses the database adapter
 func (f *ServiceFactory) addHealthCheckEndpoint(router *gin.Engine) {
 	router.GET("/api/v1/health", func(c *gin.Context) {
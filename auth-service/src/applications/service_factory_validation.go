@@ -0,0 +1,94 @@
+package applications
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"auth-service/src/applications/providers"
+	"auth-service/src/infrastructure/config"
+)
+
+// DependencyError reports the dependencies the configured modes require but the service
+// factory was not given
+type DependencyError struct {
+	Missing []string
+}
+
+func (e *DependencyError) Error() string {
+	return "service factory is missing required dependencies: " + strings.Join(e.Missing, "; ")
+}
+
+// validateDependencies checks that every dependency the configured identity provider and
+// authorization mode rely on is present, so a missing one stops startup instead of
+// panicking on the first request that needs it. It only inspects the factory. Without a
+// database the service runs in degraded mode, so the jwt_with_db mode does not require one.
+func (f *ServiceFactory) validateDependencies() error {
+	if f.cfg == nil {
+		return errors.New("service factory requires a config")
+	}
+	if f.logger == nil {
+		return errors.New("service factory requires a logger")
+	}
+
+	var missing []string
+
+	if f.cfg.Authorization.Mode == config.AuthorizationModeKeycloak {
+		missing = append(missing, f.missingKeycloakConfig("keycloak authorization mode")...)
+	}
+
+	if f.cfg.Authorization.IdentityProvider == config.IdentityProviderKeycloak {
+		missing = append(missing, f.missingKeycloakConfig("keycloak identity provider")...)
+	}
+
+	if f.db != nil && f.dbAdapter == nil {
+		missing = append(missing, "database adapter (required when a database is configured)")
+	}
+
+	if len(missing) > 0 {
+		return &DependencyError{Missing: missing}
+	}
+	return nil
+}
+
+// createAuthorizationRepositories creates the role and permission repositories the
+// jwt_with_db mode authorizes against, sharing one permission cache so role changes
+// invalidate cached permissions. Without a database they are left nil and the routes
+// needing them are refused by the degraded mode.
+func (f *ServiceFactory) createAuthorizationRepositories() error {
+	if f.cfg.Authorization.Mode != config.AuthorizationModeJWTWithDB || f.db == nil {
+		return nil
+	}
+
+	permissionCache := providers.PermissionCacheProvider(f.logger)
+
+	var missing []string
+	roleRepo, err := providers.RoleRepositoryProvider(f.db, permissionCache, f.logger)
+	if err != nil {
+		missing = append(missing, fmt.Sprintf("role repository (required by the jwt_with_db authorization mode): %v", err))
+	}
+	permissionRepo, err := providers.PermissionRepositoryProvider(&f.cfg.Authorization, f.db, permissionCache, f.logger)
+	if err != nil {
+		missing = append(missing, fmt.Sprintf("permission repository (required by the jwt_with_db authorization mode): %v", err))
+	}
+	if len(missing) > 0 {
+		return &DependencyError{Missing: missing}
+	}
+
+	f.permissionCache = permissionCache
+	f.roleRepo = roleRepo
+	f.permissionRepo = permissionRepo
+	return nil
+}
+
+// missingKeycloakConfig returns the Keycloak settings that are required by requiredBy but not set
+func (f *ServiceFactory) missingKeycloakConfig(requiredBy string) []string {
+	var missing []string
+	if f.cfg.Keycloak.BaseURL == "" {
+		missing = append(missing, fmt.Sprintf("keycloak.base_url (required by the %s)", requiredBy))
+	}
+	if f.cfg.Keycloak.Realm == "" {
+		missing = append(missing, fmt.Sprintf("keycloak.realm (required by the %s)", requiredBy))
+	}
+	return missing
+}
@@ -0,0 +1,51 @@
+package applications
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"auth-service/src/infrastructure/config"
+	"backend-core/logging"
+)
+
+func TestNewServiceFactoryRejectsMissingKeycloakConfig(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Authorization.Mode = config.AuthorizationModeKeycloak
+
+	_, err := NewServiceFactory(cfg, nil, nil, logging.NewNopLogger())
+
+	var dependencyErr *DependencyError
+	if !errors.As(err, &dependencyErr) {
+		t.Fatalf("err = %v, want a *DependencyError", err)
+	}
+	if !strings.Contains(err.Error(), "keycloak.base_url") || !strings.Contains(err.Error(), "keycloak.realm") {
+		t.Errorf("err = %q, want it to name keycloak.base_url and keycloak.realm", err)
+	}
+}
+
+func TestNewServiceFactoryWithoutDatabaseStartsDegraded(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Authorization.Mode = config.AuthorizationModeJWTWithDB
+
+	f, err := NewServiceFactory(cfg, nil, nil, logging.NewNopLogger())
+	if err != nil {
+		t.Fatalf("NewServiceFactory() error = %v, want degraded mode", err)
+	}
+	if f.roleRepo != nil || f.permissionRepo != nil {
+		t.Error("authorization repositories set without a database")
+	}
+}
+
+func TestValidateDependenciesLeavesFactoryUnchanged(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Authorization.Mode = config.AuthorizationModeJWTWithDB
+	f := &ServiceFactory{cfg: cfg, logger: logging.NewNopLogger()}
+
+	if err := f.validateDependencies(); err != nil {
+		t.Fatalf("validateDependencies() error = %v", err)
+	}
+	if f.roleRepo != nil || f.permissionRepo != nil || f.permissionCache != nil {
+		t.Error("validateDependencies created authorization dependencies")
+	}
+}
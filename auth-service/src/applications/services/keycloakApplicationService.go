@@ -214,6 +214,24 @@ func (s *KeycloakApplicationService) InitiateMFA(ctx context.Context, userID str
 	return challenge, nil
 }
 
+// EnrollTOTP enrolls a user for TOTP MFA and returns the secret and otpauth URL. An enrolled
+// user's secret is only replaced when replace is set.
+func (s *KeycloakApplicationService) EnrollTOTP(ctx context.Context, userID string, replace bool) (string, string, error) {
+	s.logger.Info("Enrolling TOTP",
+		logging.String("user_id", userID),
+		logging.Bool("replace", replace))
+
+	secret, otpauthURL, err := s.adapter.EnrollTOTP(ctx, userID, replace)
+	if err != nil {
+		s.logger.Error("TOTP enrollment failed",
+			logging.Error(err),
+			logging.String("user_id", userID))
+		return "", "", err
+	}
+
+	return secret, otpauthURL, nil
+}
+
 // VerifyMFA verifies MFA challenge response
 func (s *KeycloakApplicationService) VerifyMFA(ctx context.Context, challengeID, code string) (*models.MFAVerification, error) {
 	s.logger.Info("Verifying MFA challenge",
@@ -247,3 +265,8 @@ func (s *KeycloakApplicationService) HealthCheck(ctx context.Context) error {
 
 	return nil
 }
+
+// CircuitBreakerState returns the state of the circuit breaker guarding Keycloak calls
+func (s *KeycloakApplicationService) CircuitBreakerState() string {
+	return s.adapter.CircuitBreakerState()
+}
@@ -0,0 +1,50 @@
+package authorization
+
+import (
+	"context"
+	"time"
+)
+
+// AuthorizationDecisionOutcome is the result of an authorization check
+type AuthorizationDecisionOutcome string
+
+const (
+	// DecisionAllow means the request was let through
+	DecisionAllow AuthorizationDecisionOutcome = "allow"
+	// DecisionDeny means the request was rejected
+	DecisionDeny AuthorizationDecisionOutcome = "deny"
+)
+
+// AuthorizationCheck is the kind of authorization check that was made
+type AuthorizationCheck string
+
+const (
+	// CheckPermission is a resource/action permission check
+	CheckPermission AuthorizationCheck = "permission"
+	// CheckRole is a role membership check
+	CheckRole AuthorizationCheck = "role"
+)
+
+// AuthorizationDecision is one audited allow/deny decision. Decisions are append-only:
+// once recorded they are never updated or deleted.
+type AuthorizationDecision struct {
+	UserID        string                       `json:"user_id"`
+	Check         AuthorizationCheck           `json:"check"`
+	Resource      string                       `json:"resource,omitempty"`
+	Action        string                       `json:"action,omitempty"`
+	Role          string                       `json:"role,omitempty"`
+	Mode          string                       `json:"mode"`
+	Decision      AuthorizationDecisionOutcome `json:"decision"`
+	StatusCode    int                          `json:"status_code"`
+	RequestID     string                       `json:"request_id,omitempty"`
+	CorrelationID string                       `json:"correlation_id,omitempty"`
+	Method        string                       `json:"method,omitempty"`
+	Path          string                       `json:"path,omitempty"`
+	Timestamp     time.Time                    `json:"timestamp"`
+}
+
+// AuthorizationAuditRepository persists authorization decisions
+type AuthorizationAuditRepository interface {
+	// SaveDecisions appends decisions to the audit trail
+	SaveDecisions(ctx context.Context, decisions []*AuthorizationDecision) error
+}
@@ -0,0 +1,115 @@
+package authorization
+
+import "strings"
+
+// Default permission string conventions, e.g. "users:read" and "users:*"
+const (
+	DefaultPermissionSeparator = ":"
+	DefaultPermissionWildcard  = "*"
+)
+
+// ResourceHierarchySeparator separates the levels of a hierarchical resource such as "documents/reports"
+const ResourceHierarchySeparator = "/"
+
+// PermissionFormat describes how a permission string joins a resource and an action.
+// The default format is "resource:action"; tokens using "resource.action" or scope-style
+// "action:resource" strings are read by changing the separator or setting ActionFirst.
+type PermissionFormat struct {
+	// Separator joins the resource and the action
+	Separator string
+	// Wildcard stands for any action, or for any resource and action when used in both places
+	Wildcard string
+	// ActionFirst puts the action before the resource, as OAuth scopes like "read:users" do
+	ActionFirst bool
+}
+
+// DefaultPermissionFormat returns the "resource:action" format with "*" wildcards
+func DefaultPermissionFormat() PermissionFormat {
+	return PermissionFormat{
+		Separator: DefaultPermissionSeparator,
+		Wildcard:  DefaultPermissionWildcard,
+	}
+}
+
+// NewPermissionFormat creates a permission format. An empty separator or wildcard uses the default.
+func NewPermissionFormat(separator, wildcard string, actionFirst bool) PermissionFormat {
+	format := DefaultPermissionFormat()
+	if separator != "" {
+		format.Separator = separator
+	}
+	if wildcard != "" {
+		format.Wildcard = wildcard
+	}
+	format.ActionFirst = actionFirst
+	return format
+}
+
+// Format returns the permission string for resource and action
+func (f PermissionFormat) Format(resource, action string) string {
+	if f.ActionFirst {
+		return action + f.Separator + resource
+	}
+	return resource + f.Separator + action
+}
+
+// Parse splits a permission string into its resource and action. Resources may contain the
+// separator themselves ("users.profile.read" is resource "users.profile"), so the action is
+// taken from the far end. ok is false when either part is missing.
+func (f PermissionFormat) Parse(permission string) (resource, action string, ok bool) {
+	if f.ActionFirst {
+		action, resource, ok = strings.Cut(permission, f.Separator)
+	} else {
+		i := strings.LastIndex(permission, f.Separator)
+		if i < 0 {
+			return "", "", false
+		}
+		resource, action, ok = permission[:i], permission[i+len(f.Separator):], true
+	}
+	if !ok || resource == "" || action == "" {
+		return "", "", false
+	}
+	return resource, action, true
+}
+
+// Grants reports whether permission allows action on resource: an exact match, a wildcard
+// action on the same resource, or a wildcard for both resource and action
+func (f PermissionFormat) Grants(permission, resource, action string) bool {
+	granted, grantedAction, ok := f.Parse(permission)
+	if !ok {
+		return false
+	}
+	if grantedAction != action && grantedAction != f.Wildcard {
+		return false
+	}
+	return granted == resource || (granted == f.Wildcard && grantedAction == f.Wildcard)
+}
+
+// Allows reports whether any of permissions grants action on resource
+func (f PermissionFormat) Allows(permissions []string, resource, action string) bool {
+	for _, permission := range permissions {
+		if f.Grants(permission, resource, action) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResourceAncestors returns resource followed by each of its parents, nearest first:
+// "documents/reports/q1" gives "documents/reports/q1", "documents/reports" and "documents".
+// A permission on any of them applies to resource.
+func ResourceAncestors(resource string) []string {
+	resource = strings.Trim(resource, ResourceHierarchySeparator)
+	if resource == "" {
+		return nil
+	}
+
+	ancestors := []string{resource}
+	for {
+		i := strings.LastIndex(resource, ResourceHierarchySeparator)
+		if i <= 0 {
+			return ancestors
+		}
+		resource = resource[:i]
+		ancestors = append(ancestors, resource)
+	}
+}
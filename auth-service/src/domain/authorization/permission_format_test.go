@@ -0,0 +1,75 @@
+package authorization
+
+import "testing"
+
+func TestPermissionFormatGrants(t *testing.T) {
+	tests := []struct {
+		name       string
+		format     PermissionFormat
+		permission string
+		resource   string
+		action     string
+		want       bool
+	}{
+		{name: "default exact", format: DefaultPermissionFormat(), permission: "users:read", resource: "users", action: "read", want: true},
+		{name: "default other action", format: DefaultPermissionFormat(), permission: "users:read", resource: "users", action: "write", want: false},
+		{name: "default action wildcard", format: DefaultPermissionFormat(), permission: "users:*", resource: "users", action: "delete", want: true},
+		{name: "default full wildcard", format: DefaultPermissionFormat(), permission: "*:*", resource: "orders", action: "read", want: true},
+		{name: "default resource wildcard only", format: DefaultPermissionFormat(), permission: "*:read", resource: "orders", action: "read", want: false},
+		{name: "default other format", format: DefaultPermissionFormat(), permission: "users.read", resource: "users", action: "read", want: false},
+		{name: "dot exact", format: NewPermissionFormat(".", "", false), permission: "users.read", resource: "users", action: "read", want: true},
+		{name: "dot nested resource", format: NewPermissionFormat(".", "", false), permission: "users.profile.read", resource: "users.profile", action: "read", want: true},
+		{name: "dot wildcard", format: NewPermissionFormat(".", "", false), permission: "users.*", resource: "users", action: "write", want: true},
+		{name: "dot other format", format: NewPermissionFormat(".", "", false), permission: "users:read", resource: "users", action: "read", want: false},
+		{name: "scope style", format: NewPermissionFormat(":", "", true), permission: "read:users", resource: "users", action: "read", want: true},
+		{name: "scope style reversed", format: NewPermissionFormat(":", "", true), permission: "users:read", resource: "users", action: "read", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.format.Grants(tt.permission, tt.resource, tt.action); got != tt.want {
+				t.Errorf("Grants(%q, %q, %q) = %v, want %v", tt.permission, tt.resource, tt.action, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermissionFormatRoundTrip(t *testing.T) {
+	for _, format := range []PermissionFormat{
+		DefaultPermissionFormat(),
+		NewPermissionFormat(".", "", false),
+		NewPermissionFormat(":", "", true),
+	} {
+		resource, action, ok := format.Parse(format.Format("users", "read"))
+		if !ok || resource != "users" || action != "read" {
+			t.Errorf("%+v: Parse(Format(users, read)) = %q, %q, %v", format, resource, action, ok)
+		}
+	}
+
+	for _, permission := range []string{"", "users", "users:", ":read"} {
+		if _, _, ok := DefaultPermissionFormat().Parse(permission); ok {
+			t.Errorf("Parse(%q) succeeded, want it rejected", permission)
+		}
+	}
+}
+
+func TestResourceAncestors(t *testing.T) {
+	tests := map[string][]string{
+		"documents/reports/q1": {"documents/reports/q1", "documents/reports", "documents"},
+		"/documents/":          {"documents"},
+		"documents":            {"documents"},
+		"":                     nil,
+	}
+	for resource, want := range tests {
+		got := ResourceAncestors(resource)
+		if len(got) != len(want) {
+			t.Errorf("ResourceAncestors(%q) = %v, want %v", resource, got, want)
+			continue
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("ResourceAncestors(%q) = %v, want %v", resource, got, want)
+				break
+			}
+		}
+	}
+}
@@ -51,6 +51,11 @@ func (p *Permission) SetUUID(id uuid.UUID) {
 	p.ID = entityID
 }
 
+// PermissionKey returns the "resource:action" key batch permission checks report results under
+func PermissionKey(resource, action string) string {
+	return DefaultPermissionFormat().Format(resource, action)
+}
+
 // UserRole represents the relationship between users and roles
 type UserRole struct {
 	models.BaseEntity `json:",inline"`
@@ -155,6 +160,9 @@ type PermissionRepository interface {
 	// GetRolePermissions retrieves all permissions for a role
 	GetRolePermissions(ctx context.Context, roleID uuid.UUID) ([]*Permission, error)
 
+	// GetPermissionsForRoles retrieves the permissions of several roles in one query, keyed by role ID
+	GetPermissionsForRoles(ctx context.Context, roleIDs []uuid.UUID) (map[uuid.UUID][]*Permission, error)
+
 	// GetUserPermissions retrieves all permissions for a user (through roles)
 	GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]*Permission, error)
 
@@ -164,6 +172,15 @@ type PermissionRepository interface {
 	// RemovePermissionFromRole removes a permission from a role
 	RemovePermissionFromRole(ctx context.Context, roleID, permissionID uuid.UUID) error
 
-	// CheckUserPermission checks if a user has a specific permission
+	// CheckUserPermission checks if a user has a specific permission, honoring
+	// "resource:*" and "*:*" wildcard permissions
 	CheckUserPermission(ctx context.Context, userID uuid.UUID, resource, action string) (bool, error)
+
+	// CheckUserPermissionHierarchical is CheckUserPermission where a permission on a resource
+	// also applies to the resources below it, so "documents" covers "documents/reports"
+	CheckUserPermissionHierarchical(ctx context.Context, userID uuid.UUID, resource, action string) (bool, error)
+
+	// CheckUserPermissions checks several permissions in one query, with the same wildcard
+	// rules as CheckUserPermission. Results are keyed by PermissionKey(resource, action).
+	CheckUserPermissions(ctx context.Context, userID uuid.UUID, perms []Permission) (map[string]bool, error)
 }
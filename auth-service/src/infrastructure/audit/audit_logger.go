@@ -0,0 +1,176 @@
+package audit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"auth-service/src/domain/authorization"
+	"backend-core/logging"
+)
+
+const (
+	defaultBufferSize    = 1024
+	defaultBatchSize     = 100
+	defaultFlushInterval = time.Second
+	// saveTimeout bounds a single write of a batch to the repository
+	saveTimeout = 5 * time.Second
+)
+
+// Config holds the AuditLogger buffering settings. Zero values use the defaults.
+type Config struct {
+	// BufferSize is how many decisions may wait to be written before new ones are dropped
+	BufferSize int
+	// BatchSize is how many decisions are written at once
+	BatchSize int
+	// FlushInterval is how long a partial batch may wait before it is written
+	FlushInterval time.Duration
+}
+
+// AuditLogger records authorization decisions without slowing down the request path:
+// Log only queues the decision, and a background worker writes queued decisions to the
+// repository in batches. Close flushes everything queued before returning.
+type AuditLogger struct {
+	repo          authorization.AuthorizationAuditRepository
+	logger        *logging.Logger
+	entries       chan *authorization.AuthorizationDecision
+	batchSize     int
+	flushInterval time.Duration
+
+	mu     sync.RWMutex
+	closed bool
+
+	startOnce sync.Once
+	done      chan struct{}
+	dropped   atomic.Int64
+}
+
+// NewAuditLogger creates an audit logger writing to repo. Call Start to begin writing.
+func NewAuditLogger(repo authorization.AuthorizationAuditRepository, cfg Config, logger *logging.Logger) *AuditLogger {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaultBufferSize
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultFlushInterval
+	}
+
+	return &AuditLogger{
+		repo:          repo,
+		logger:        logger,
+		entries:       make(chan *authorization.AuthorizationDecision, cfg.BufferSize),
+		batchSize:     cfg.BatchSize,
+		flushInterval: cfg.FlushInterval,
+		done:          make(chan struct{}),
+	}
+}
+
+// Start starts the background writer. Calling it again has no effect.
+func (a *AuditLogger) Start() {
+	a.startOnce.Do(func() {
+		go a.run()
+	})
+}
+
+// Log queues decision for writing. It never blocks: when the buffer is full, or the logger
+// is closed, the decision is dropped and counted in Dropped.
+func (a *AuditLogger) Log(decision *authorization.AuthorizationDecision) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.closed {
+		a.drop(decision, "audit logger closed")
+		return
+	}
+
+	select {
+	case a.entries <- decision:
+	default:
+		a.drop(decision, "audit buffer full")
+	}
+}
+
+// Dropped returns how many decisions could not be queued
+func (a *AuditLogger) Dropped() int64 {
+	return a.dropped.Load()
+}
+
+// Close stops accepting decisions and waits until every queued one has been written,
+// or ctx is done. It is safe to call more than once.
+func (a *AuditLogger) Close(ctx context.Context) error {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.entries)
+	}
+	a.mu.Unlock()
+
+	// Drain what was queued even if Start was never called
+	a.Start()
+
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		a.logger.Warn("Audit logger closed before all decisions were written",
+			logging.Int("pending", len(a.entries)))
+		return ctx.Err()
+	}
+}
+
+// run writes queued decisions in batches until the queue is closed and drained
+func (a *AuditLogger) run() {
+	defer close(a.done)
+
+	ticker := time.NewTicker(a.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*authorization.AuthorizationDecision, 0, a.batchSize)
+	for {
+		select {
+		case decision, ok := <-a.entries:
+			if !ok {
+				a.write(batch)
+				return
+			}
+			batch = append(batch, decision)
+			if len(batch) >= a.batchSize {
+				a.write(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				a.write(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// write saves batch, logging rather than returning failures so the worker keeps going
+func (a *AuditLogger) write(batch []*authorization.AuthorizationDecision) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), saveTimeout)
+	defer cancel()
+
+	if err := a.repo.SaveDecisions(ctx, batch); err != nil {
+		a.logger.Error("Failed to write authorization audit decisions",
+			logging.Error(err),
+			logging.Int("count", len(batch)))
+	}
+}
+
+// drop counts a decision that could not be queued
+func (a *AuditLogger) drop(decision *authorization.AuthorizationDecision, reason string) {
+	a.dropped.Add(1)
+	a.logger.Warn("Authorization audit decision dropped",
+		logging.String("reason", reason),
+		logging.String("user_id", decision.UserID),
+		logging.String("decision", string(decision.Decision)))
+}
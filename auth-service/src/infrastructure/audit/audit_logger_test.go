@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"auth-service/src/domain/authorization"
+	"backend-core/logging"
+)
+
+// memoryRepository is an AuthorizationAuditRepository keeping saved decisions in memory
+type memoryRepository struct {
+	mu        sync.Mutex
+	decisions []*authorization.AuthorizationDecision
+}
+
+func (r *memoryRepository) SaveDecisions(ctx context.Context, decisions []*authorization.AuthorizationDecision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.decisions = append(r.decisions, decisions...)
+	return nil
+}
+
+func (r *memoryRepository) saved() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.decisions)
+}
+
+func newTestAuditLogger(t *testing.T, repo *memoryRepository, cfg Config) *AuditLogger {
+	t.Helper()
+
+	logger := logging.NewNopLogger()
+	return NewAuditLogger(repo, cfg, logger)
+}
+
+func TestCloseFlushesQueuedDecisions(t *testing.T) {
+	repo := &memoryRepository{}
+	// A long flush interval and large batches leave everything queued until Close
+	auditLogger := newTestAuditLogger(t, repo, Config{BatchSize: 100, FlushInterval: time.Hour})
+	auditLogger.Start()
+
+	for i := 0; i < 10; i++ {
+		auditLogger.Log(&authorization.AuthorizationDecision{UserID: "user-1", Decision: authorization.DecisionAllow})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := auditLogger.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := repo.saved(); got != 10 {
+		t.Errorf("saved %d decisions, want 10", got)
+	}
+
+	// Decisions logged after Close are dropped instead of blocking or panicking
+	auditLogger.Log(&authorization.AuthorizationDecision{UserID: "user-1", Decision: authorization.DecisionDeny})
+	if got := auditLogger.Dropped(); got != 1 {
+		t.Errorf("Dropped() = %d, want 1", got)
+	}
+}
+
+func TestLogDropsWhenBufferIsFull(t *testing.T) {
+	repo := &memoryRepository{}
+	// Not started, so nothing leaves the buffer
+	auditLogger := newTestAuditLogger(t, repo, Config{BufferSize: 2})
+
+	for i := 0; i < 3; i++ {
+		auditLogger.Log(&authorization.AuthorizationDecision{UserID: "user-1", Decision: authorization.DecisionAllow})
+	}
+	if got := auditLogger.Dropped(); got != 1 {
+		t.Errorf("Dropped() = %d, want 1", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := auditLogger.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := repo.saved(); got != 2 {
+		t.Errorf("saved %d decisions, want 2", got)
+	}
+}
@@ -39,6 +39,10 @@ type AuthorizationConfig struct {
 	// Enabled enables/disables authorization checks
 	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
 
+	// IncludeAuthorizationDetails adds the caller's permissions/roles to 403 responses.
+	// Useful in development; keep it off in production to avoid leaking them.
+	IncludeAuthorizationDetails bool `yaml:"include_authorization_details" mapstructure:"include_authorization_details"`
+
 	// JWTAuth holds JWT-based authorization settings
 	JWTAuth JWTAuthConfig `yaml:"jwt_auth" mapstructure:"jwt_auth"`
 
@@ -47,6 +51,24 @@ type AuthorizationConfig struct {
 
 	// KeycloakAuth holds Keycloak authorization settings
 	KeycloakAuth KeycloakAuthConfig `yaml:"keycloak_auth" mapstructure:"keycloak_auth"`
+
+	// Audit holds the authorization decision audit trail settings
+	Audit AuthorizationAuditConfig `yaml:"audit" mapstructure:"audit"`
+}
+
+// AuthorizationAuditConfig holds authorization decision audit trail settings
+type AuthorizationAuditConfig struct {
+	// Enabled records every allow/deny decision in the authorization_audit_log table
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+
+	// BufferSize is how many decisions may wait to be written before new ones are dropped
+	BufferSize int `yaml:"buffer_size" mapstructure:"buffer_size"`
+
+	// BatchSize is how many decisions are written at once
+	BatchSize int `yaml:"batch_size" mapstructure:"batch_size"`
+
+	// FlushInterval is how long a partial batch may wait before it is written (default: "1s")
+	FlushInterval string `yaml:"flush_interval" mapstructure:"flush_interval"`
 }
 
 // JWTAuthConfig holds JWT authorization settings (token-based only)
@@ -62,6 +84,21 @@ type JWTAuthConfig struct {
 
 	// PermissionsClaimKey is the JWT claim key for permissions (default: "permissions")
 	PermissionsClaimKey string `yaml:"permissions_claim_key" mapstructure:"permissions_claim_key"`
+
+	// PermissionFormat describes how the permission strings in the token are written
+	PermissionFormat PermissionFormatConfig `yaml:"permission_format" mapstructure:"permission_format"`
+}
+
+// PermissionFormatConfig holds the permission string convention used by tokens
+type PermissionFormatConfig struct {
+	// Separator joins resource and action (default: ":")
+	Separator string `yaml:"separator" mapstructure:"separator"`
+
+	// Wildcard matches any action, or any resource and action (default: "*")
+	Wildcard string `yaml:"wildcard" mapstructure:"wildcard"`
+
+	// ActionFirst reads scope-style "action:resource" strings
+	ActionFirst bool `yaml:"action_first" mapstructure:"action_first"`
 }
 
 // JWTWithDBAuthConfig holds JWT with database authorization settings
@@ -77,6 +114,24 @@ type JWTWithDBAuthConfig struct {
 
 	// RefreshOnAccess refreshes cache TTL on access
 	RefreshOnAccess bool `yaml:"refresh_on_access" mapstructure:"refresh_on_access"`
+
+	// CacheWarmer preloads effective permissions for the most active users at startup
+	CacheWarmer PermissionCacheWarmerConfig `yaml:"cache_warmer" mapstructure:"cache_warmer"`
+}
+
+// PermissionCacheWarmerConfig holds startup permission cache warming settings
+type PermissionCacheWarmerConfig struct {
+	// Enabled enables warming at startup
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+
+	// UserIDs lists the users to warm, most active first
+	UserIDs []string `yaml:"user_ids" mapstructure:"user_ids"`
+
+	// MaxUsers bounds how many users are warmed
+	MaxUsers int `yaml:"max_users" mapstructure:"max_users"`
+
+	// Timeout bounds how long warming may take (default: "30s")
+	Timeout string `yaml:"timeout" mapstructure:"timeout"`
 }
 
 // KeycloakAuthConfig holds Keycloak authorization settings
@@ -105,12 +160,21 @@ func NewAuthorizationConfig() AuthorizationConfig {
 			UsePermissions:      true,
 			RolesClaimKey:       "roles",
 			PermissionsClaimKey: "permissions",
+			PermissionFormat: PermissionFormatConfig{
+				Separator: ":",
+				Wildcard:  "*",
+			},
 		},
 		JWTWithDBAuth: JWTWithDBAuthConfig{
 			UseRoles:        true,
 			UsePermissions:  true,
 			CacheTTL:        "15m",
 			RefreshOnAccess: true,
+			CacheWarmer: PermissionCacheWarmerConfig{
+				Enabled:  false,
+				MaxUsers: 100,
+				Timeout:  "30s",
+			},
 		},
 		KeycloakAuth: KeycloakAuthConfig{
 			Enabled:                  false,
@@ -118,5 +182,11 @@ func NewAuthorizationConfig() AuthorizationConfig {
 			FallbackToJWT:            true,
 			UseAuthorizationServices: false,
 		},
+		Audit: AuthorizationAuditConfig{
+			Enabled:       false,
+			BufferSize:    1024,
+			BatchSize:     100,
+			FlushInterval: "1s",
+		},
 	}
 }
@@ -2,10 +2,12 @@ package config
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"reflect"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
@@ -25,8 +27,32 @@ type Config struct {
 // ServerConfig holds server configuration
 type ServerConfig struct {
 	Port string `yaml:"port" mapstructure:"port"`
+
+	// ReadHeaderTimeout is how long a client may take to send the request headers. Connections
+	// that send them more slowly are closed, so slowloris clients cannot hold connections open.
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" mapstructure:"read_header_timeout"`
+	// MaxHeaderBytes bounds the size of the request line and headers; larger requests are
+	// rejected with 431 Request Header Fields Too Large
+	MaxHeaderBytes int `yaml:"max_header_bytes" mapstructure:"max_header_bytes"`
+}
+
+// NewHTTPServer creates an HTTP server listening on Port that bounds how long and how
+// large the request headers may be
+func (c ServerConfig) NewHTTPServer(handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              ":" + c.Port,
+		Handler:           handler,
+		ReadHeaderTimeout: c.ReadHeaderTimeout,
+		MaxHeaderBytes:    c.MaxHeaderBytes,
+	}
 }
 
+// HTTP server defaults applied when the config leaves them unset
+const (
+	DefaultReadHeaderTimeout = 10 * time.Second
+	DefaultMaxHeaderBytes    = 64 << 10
+)
+
 // DatabaseConfig holds database configuration
 type DatabaseConfig struct {
 	Type                       string `yaml:"type" mapstructure:"type"`
@@ -72,22 +98,27 @@ type JWTConfig struct {
 
 // KeycloakConfig holds Keycloak configuration
 type KeycloakConfig struct {
-	BaseURL         string       `yaml:"base_url" mapstructure:"base_url"`
-	Realm           string       `yaml:"realm" mapstructure:"realm"`
-	ClientID        string       `yaml:"client_id" mapstructure:"client_id"`
-	ClientSecret    string       `yaml:"client_secret" mapstructure:"client_secret"`
-	RedirectURI     string       `yaml:"redirect_uri" mapstructure:"redirect_uri"`
-	Scopes          []string     `yaml:"scopes" mapstructure:"scopes"`
-	Timeout         string       `yaml:"timeout" mapstructure:"timeout"`
-	RetryAttempts   int          `yaml:"retry_attempts" mapstructure:"retry_attempts"`
-	CacheTTL        string       `yaml:"cache_ttl" mapstructure:"cache_ttl"`
-	EnableSSO       bool         `yaml:"enable_sso" mapstructure:"enable_sso"`
-	EnableMFA       bool         `yaml:"enable_mfa" mapstructure:"enable_mfa"`
-	EnableRiskBased bool         `yaml:"enable_risk_based" mapstructure:"enable_risk_based"`
-	SAML            SAMLConfig   `yaml:"saml" mapstructure:"saml"`
-	OAuth           OAuthConfig  `yaml:"oauth" mapstructure:"oauth"`
-	Policy          PolicyConfig `yaml:"policy" mapstructure:"policy"`
-	Admin           AdminConfig  `yaml:"admin" mapstructure:"admin"`
+	BaseURL               string               `yaml:"base_url" mapstructure:"base_url"`
+	Realm                 string               `yaml:"realm" mapstructure:"realm"`
+	ClientID              string               `yaml:"client_id" mapstructure:"client_id"`
+	ClientSecret          string               `yaml:"client_secret" mapstructure:"client_secret"`
+	RedirectURI           string               `yaml:"redirect_uri" mapstructure:"redirect_uri"`
+	Scopes                []string             `yaml:"scopes" mapstructure:"scopes"`
+	Timeout               string               `yaml:"timeout" mapstructure:"timeout"`
+	RetryAttempts         int                  `yaml:"retry_attempts" mapstructure:"retry_attempts"`
+	CacheTTL              string               `yaml:"cache_ttl" mapstructure:"cache_ttl"`
+	CacheOperationTimeout string               `yaml:"cache_operation_timeout" mapstructure:"cache_operation_timeout"` // Bound on each cache call before falling back to Keycloak
+	TokenRefreshWindow    string               `yaml:"token_refresh_window" mapstructure:"token_refresh_window"`       // Cached tokens expiring within it are refreshed
+	TokenRefreshInterval  string               `yaml:"token_refresh_interval" mapstructure:"token_refresh_interval"`   // How often cached tokens are checked
+	EnableSSO             bool                 `yaml:"enable_sso" mapstructure:"enable_sso"`
+	EnableMFA             bool                 `yaml:"enable_mfa" mapstructure:"enable_mfa"`
+	MFATOTPSkew           int                  `yaml:"mfa_totp_skew" mapstructure:"mfa_totp_skew"` // TOTP steps accepted either side of now
+	EnableRiskBased       bool                 `yaml:"enable_risk_based" mapstructure:"enable_risk_based"`
+	SAML                  SAMLConfig           `yaml:"saml" mapstructure:"saml"`
+	OAuth                 OAuthConfig          `yaml:"oauth" mapstructure:"oauth"`
+	Policy                PolicyConfig         `yaml:"policy" mapstructure:"policy"`
+	Admin                 AdminConfig          `yaml:"admin" mapstructure:"admin"`
+	CircuitBreaker        CircuitBreakerConfig `yaml:"circuit_breaker" mapstructure:"circuit_breaker"`
 }
 
 // SAMLConfig holds SAML-specific configuration
@@ -109,6 +140,9 @@ type OAuthConfig struct {
 	JWKSURL          string   `yaml:"jwks_url" mapstructure:"jwks_url"`
 	Scopes           []string `yaml:"scopes" mapstructure:"scopes"`
 	Enabled          bool     `yaml:"enabled" mapstructure:"enabled"`
+
+	JWKSCacheTTL           string `yaml:"jwks_cache_ttl" mapstructure:"jwks_cache_ttl"`                       // How long fetched signing keys are cached
+	JWKSMinRefreshInterval string `yaml:"jwks_min_refresh_interval" mapstructure:"jwks_min_refresh_interval"` // Minimum time between JWKS fetches
 }
 
 // PolicyConfig holds policy engine configuration
@@ -126,6 +160,13 @@ type AdminConfig struct {
 	Enabled  bool   `yaml:"enabled" mapstructure:"enabled"`
 }
 
+// CircuitBreakerConfig holds the circuit breaker settings for Keycloak HTTP calls
+type CircuitBreakerConfig struct {
+	FailureThreshold  int    `yaml:"failure_threshold" mapstructure:"failure_threshold"`     // Consecutive failures that open the breaker
+	Cooldown          string `yaml:"cooldown" mapstructure:"cooldown"`                       // How long the breaker stays open
+	HalfOpenSuccesses int    `yaml:"half_open_successes" mapstructure:"half_open_successes"` // Successes needed to close it again
+}
+
 // expandEnvInYAML expands environment variables in YAML content
 // Supports ${VAR_NAME:default_value} syntax
 func expandEnvInYAML(yamlContent []byte) []byte {
@@ -237,6 +278,14 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("error unmarshaling config: %w", err)
 	}
 
+	// Set defaults for the HTTP server if not set
+	if cfg.Server.ReadHeaderTimeout <= 0 {
+		cfg.Server.ReadHeaderTimeout = DefaultReadHeaderTimeout
+	}
+	if cfg.Server.MaxHeaderBytes <= 0 {
+		cfg.Server.MaxHeaderBytes = DefaultMaxHeaderBytes
+	}
+
 	// Set defaults for authorization if not set
 	if cfg.Authorization.Mode == "" {
 		cfg.Authorization.Mode = AuthorizationModeJWT
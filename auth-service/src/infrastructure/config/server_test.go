@@ -0,0 +1,86 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startHTTPServer serves cfg.NewHTTPServer on a local port and returns its address
+func startHTTPServer(t *testing.T, cfg ServerConfig) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := cfg.NewHTTPServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+	return listener.Addr().String()
+}
+
+func TestHTTPServerDropsSlowHeaders(t *testing.T) {
+	addr := startHTTPServer(t, ServerConfig{
+		ReadHeaderTimeout: 100 * time.Millisecond,
+		MaxHeaderBytes:    DefaultMaxHeaderBytes,
+	})
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	// Start a request and never finish its headers
+	if _, err := io.WriteString(conn, "GET / HTTP/1.1\r\nHost: localhost\r\nX-Slow: "); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	started := time.Now()
+	conn.SetReadDeadline(started.Add(5 * time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		t.Fatal("expected the server to close the connection at the read header timeout")
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Errorf("connection was closed after %v, expected it at the read header timeout", elapsed)
+	}
+}
+
+func TestHTTPServerRejectsOversizedHeaders(t *testing.T) {
+	addr := startHTTPServer(t, ServerConfig{
+		ReadHeaderTimeout: time.Second,
+		MaxHeaderBytes:    1 << 10,
+	})
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	// net/http allows 4KB of slack over MaxHeaderBytes
+	request := "GET / HTTP/1.1\r\nHost: localhost\r\nX-Large: " + strings.Repeat("x", 8<<10) + "\r\n\r\n"
+	if _, err := io.WriteString(conn, request); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("read response: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestHeaderFieldsTooLarge {
+		t.Errorf("expected 431, got %d", resp.StatusCode)
+	}
+}
@@ -51,7 +51,8 @@ func NewAdminClient(config AdminClientConfig, logger *logging.Logger) (*AdminCli
 			MaxBackoff:        5 * time.Second,
 		}).
 		WithLogging(logger).
-		WithTracing()
+		WithTracing().
+		WithCorrelation()
 
 	// Add API key authentication if configured
 	if config.APIKey != "" {
@@ -71,7 +72,7 @@ func NewAdminClient(config AdminClientConfig, logger *logging.Logger) (*AdminCli
 
 	logger.Info("Connected to admin service with middleware",
 		logging.String("target", target),
-		logging.String("middleware", "retry,logging,tracing"))
+		logging.String("middleware", "retry,logging,tracing,correlation"))
 
 	return &AdminClient{
 		client: pb.NewAdminServiceClient(conn),
@@ -2,20 +2,52 @@ package keycloak
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"net/url"
+	"sync"
 	"time"
 
 	"auth-service/src/infrastructure/identity/models"
 	"backend-core/cache"
 	"backend-core/logging"
+	"backend-core/telemetry"
 )
 
+// defaultCacheOperationTimeout bounds cache operations when CacheOperationTimeout is not set
+const defaultCacheOperationTimeout = 200 * time.Millisecond
+
+// ssoStateTTL is how long an SSO login has to come back to the callback
+const ssoStateTTL = 10 * time.Minute
+
+// randomTokenBytes is the size of OAuth states and MFA challenge IDs, 128 bits of entropy
+const randomTokenBytes = 16
+
+// keycloakCacheType labels the adapter's cache metrics
+const keycloakCacheType = "keycloak"
+
+// errCacheTimeout is returned by cache helpers when the cache does not answer in time
+var errCacheTimeout = errors.New("keycloak cache operation timed out")
+
 // KeycloakAdapter provides a service-specific interface to Keycloak
 type KeycloakAdapter struct {
-	client *KeycloakClient
-	cache  cache.Cache
-	logger *logging.Logger
-	config KeycloakConfig
+	client    *KeycloakClient
+	cache     cache.Cache
+	logger    *logging.Logger
+	config    KeycloakConfig
+	cacheKeys adapterCacheKeys
+
+	// metrics receives cache hits, misses and timeouts per operation; nil disables them
+	metrics *telemetry.BusinessMetrics
+
+	// authUsers holds the usernames whose auth results this adapter cached, for the token refresher
+	authUsers sync.Map
+
+	refresherMu sync.Mutex
+	stopChan    chan struct{}
+	refresherWg sync.WaitGroup
 }
 
 // NewKeycloakAdapter creates a new Keycloak adapter
@@ -31,9 +63,9 @@ func NewKeycloakAdapter(client *KeycloakClient, cache cache.Cache, config Keyclo
 // Authenticate authenticates a user with Keycloak
 func (a *KeycloakAdapter) Authenticate(ctx context.Context, credentials models.Credentials) (*models.AuthResult, error) {
 	// Check cache first
-	cacheKey := fmt.Sprintf("auth:%s", credentials.Username)
+	cacheKey := a.cacheKeys.auth(credentials.Username)
 	var cached models.AuthResult
-	if err := a.getFromCache(ctx, cacheKey, &cached); err == nil {
+	if err := a.getCached(ctx, "auth", cacheKey, &cached); err == nil {
 		a.logger.Debug("Authentication result found in cache",
 			logging.String("username", credentials.Username))
 		return &cached, nil
@@ -53,6 +85,8 @@ func (a *KeycloakAdapter) Authenticate(ctx context.Context, credentials models.C
 		a.logger.Warn("Failed to cache authentication result",
 			logging.Error(err),
 			logging.String("username", credentials.Username))
+	} else {
+		a.authUsers.Store(credentials.Username, struct{}{})
 	}
 
 	a.logger.Info("User authenticated successfully",
@@ -65,9 +99,9 @@ func (a *KeycloakAdapter) Authenticate(ctx context.Context, credentials models.C
 // ValidateToken validates a token with Keycloak
 func (a *KeycloakAdapter) ValidateToken(ctx context.Context, token string) (*models.TokenInfo, error) {
 	// Check cache first
-	cacheKey := fmt.Sprintf("token:%s", token)
+	cacheKey := a.cacheKeys.token(token)
 	var cached models.TokenInfo
-	if err := a.getFromCache(ctx, cacheKey, &cached); err == nil {
+	if err := a.getCached(ctx, "token", cacheKey, &cached); err == nil {
 		a.logger.Debug("Token validation result found in cache")
 		return &cached, nil
 	}
@@ -96,9 +130,9 @@ func (a *KeycloakAdapter) ValidateToken(ctx context.Context, token string) (*mod
 // GetUserProfile retrieves user profile from Keycloak
 func (a *KeycloakAdapter) GetUserProfile(ctx context.Context, userID string) (*models.UserProfile, error) {
 	// Check cache first
-	cacheKey := fmt.Sprintf("profile:%s", userID)
+	cacheKey := a.cacheKeys.profile(userID)
 	var cached models.UserProfile
-	if err := a.getFromCache(ctx, cacheKey, &cached); err == nil {
+	if err := a.getCached(ctx, "profile", cacheKey, &cached); err == nil {
 		a.logger.Debug("User profile found in cache",
 			logging.String("user_id", userID))
 		return &cached, nil
@@ -130,9 +164,9 @@ func (a *KeycloakAdapter) GetUserProfile(ctx context.Context, userID string) (*m
 // CheckPermission checks user permission with Keycloak
 func (a *KeycloakAdapter) CheckPermission(ctx context.Context, userID, resource, action string) (bool, error) {
 	// Check cache first
-	cacheKey := fmt.Sprintf("permission:%s:%s:%s", userID, resource, action)
+	cacheKey := a.cacheKeys.permission(userID, resource, action)
 	var cached bool
-	if err := a.getFromCache(ctx, cacheKey, &cached); err == nil {
+	if err := a.getCached(ctx, "permission", cacheKey, &cached); err == nil {
 		a.logger.Debug("Permission check result found in cache",
 			logging.String("user_id", userID),
 			logging.String("resource", resource),
@@ -172,9 +206,9 @@ func (a *KeycloakAdapter) CheckPermission(ctx context.Context, userID, resource,
 // GetUserRoles retrieves user roles from Keycloak
 func (a *KeycloakAdapter) GetUserRoles(ctx context.Context, userID string) ([]string, error) {
 	// Check cache first
-	cacheKey := fmt.Sprintf("roles:%s", userID)
+	cacheKey := a.cacheKeys.roles(userID)
 	var cached []string
-	if err := a.getFromCache(ctx, cacheKey, &cached); err == nil {
+	if err := a.getCached(ctx, "roles", cacheKey, &cached); err == nil {
 		a.logger.Debug("User roles found in cache",
 			logging.String("user_id", userID))
 		return cached, nil
@@ -206,9 +240,9 @@ func (a *KeycloakAdapter) GetUserRoles(ctx context.Context, userID string) ([]st
 // GetUserPermissions retrieves user permissions from Keycloak
 func (a *KeycloakAdapter) GetUserPermissions(ctx context.Context, userID string) ([]string, error) {
 	// Check cache first
-	cacheKey := fmt.Sprintf("permissions:%s", userID)
+	cacheKey := a.cacheKeys.permissions(userID)
 	var cached []string
-	if err := a.getFromCache(ctx, cacheKey, &cached); err == nil {
+	if err := a.getCached(ctx, "permissions", cacheKey, &cached); err == nil {
 		a.logger.Debug("User permissions found in cache",
 			logging.String("user_id", userID))
 		return cached, nil
@@ -260,8 +294,11 @@ func (a *KeycloakAdapter) RevokeToken(ctx context.Context, token string) error {
 		return err
 	}
 
-	// Clear any cached data for this token
-	a.clearTokenCache(ctx, token)
+	// Clear any cached data for this token; the token is already revoked, so a
+	// partial cache failure only leaves entries to expire on their own
+	if err := a.clearTokenCache(ctx, token); err != nil {
+		a.logger.Warn("Token cache only partially cleared after revocation", logging.Error(err))
+	}
 
 	a.logger.Info("Token revoked successfully")
 
@@ -282,32 +319,84 @@ func (a *KeycloakAdapter) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
-// InitiateSSOLogin initiates an SSO login flow
+// VerifyToken validates a token's signature and claims locally, and returns its claims
+func (a *KeycloakAdapter) VerifyToken(ctx context.Context, token string) (map[string]interface{}, error) {
+	return a.client.VerifyToken(ctx, token)
+}
+
+// ClientID returns the Keycloak client the adapter authenticates as
+func (a *KeycloakAdapter) ClientID() string {
+	return a.config.ClientID
+}
+
+// CircuitBreakerState returns the state of the circuit breaker guarding Keycloak calls
+func (a *KeycloakAdapter) CircuitBreakerState() string {
+	return a.client.CircuitBreakerState()
+}
+
+// InitiateSSOLogin initiates an SSO login flow. The returned state is stored in the cache
+// and must be presented back to ValidateSSOState by the callback.
 func (a *KeycloakAdapter) InitiateSSOLogin(ctx context.Context, provider string) (*models.AuthURL, error) {
 	if !a.config.EnableSSO {
 		return nil, fmt.Errorf("SSO is not enabled")
 	}
 
-	// Build authorization URL with Keycloak identity provider
-	authURL := fmt.Sprintf("%s?client_id=%s&redirect_uri=%s&response_type=code&scope=%s",
-		a.config.OAuth.AuthorizationURL,
-		a.config.ClientID,
-		a.config.RedirectURI,
-		"openid profile email")
+	state, err := generateState()
+	if err != nil {
+		return nil, err
+	}
+
+	// The callback rejects states it cannot find, so an unstored state would fail the login anyway
+	if err := a.setCache(ctx, a.cacheKeys.ssoState(state), provider, ssoStateTTL); err != nil {
+		a.logger.Error("Failed to store SSO state", logging.Error(err))
+		return nil, fmt.Errorf("failed to store SSO state: %w", err)
+	}
 
+	// Build authorization URL with Keycloak identity provider
+	query := url.Values{}
+	query.Set("client_id", a.config.ClientID)
+	query.Set("redirect_uri", a.config.RedirectURI)
+	query.Set("response_type", "code")
+	query.Set("scope", "openid profile email")
+	query.Set("state", state)
 	if provider != "" {
-		authURL += fmt.Sprintf("&kc_idp_hint=%s", provider)
+		query.Set("kc_idp_hint", provider)
 	}
+	authURL := a.config.OAuth.AuthorizationURL + "?" + query.Encode()
 
 	a.logger.Info("SSO login initiated",
 		logging.String("provider", provider))
 
 	return &models.AuthURL{
 		URL:   authURL,
-		State: generateState(),
+		State: state,
 	}, nil
 }
 
+// ValidateSSOState checks that state was issued by InitiateSSOLogin and has not been used yet.
+// A state is accepted once; it returns ErrInvalidState for unknown, expired or reused states.
+func (a *KeycloakAdapter) ValidateSSOState(ctx context.Context, state string) error {
+	if state == "" {
+		return ErrInvalidState
+	}
+
+	if a.cache == nil {
+		return ErrInvalidState
+	}
+
+	// Reading and deleting in one step lets only one of several concurrent callbacks consume the state
+	cacheKey := a.cacheKeys.ssoState(state)
+	var provider string
+	if err := a.withCacheTimeout(ctx, "get_delete", cacheKey, func(ctx context.Context) error {
+		return a.cache.GetDelete(ctx, cacheKey, &provider)
+	}); err != nil {
+		a.logger.Warn("SSO state not found", logging.Error(err))
+		return ErrInvalidState
+	}
+
+	return nil
+}
+
 // InitiateMFA initiates MFA challenge
 func (a *KeycloakAdapter) InitiateMFA(ctx context.Context, userID string) (*models.MFAChallenge, error) {
 	if !a.config.EnableMFA {
@@ -316,14 +405,19 @@ func (a *KeycloakAdapter) InitiateMFA(ctx context.Context, userID string) (*mode
 
 	// In Keycloak, MFA is typically handled during authentication flow
 	// This is a simplified implementation
+	challengeID, err := generateChallengeID()
+	if err != nil {
+		return nil, err
+	}
 	challenge := &models.MFAChallenge{
-		ChallengeID: generateChallengeID(),
-		Methods:     []string{"totp", "sms", "email"},
+		ChallengeID: challengeID,
+		UserID:      userID,
+		Methods:     []string{"totp"},
 		ExpiresAt:   time.Now().Add(5 * time.Minute),
 	}
 
 	// Store challenge in cache
-	cacheKey := fmt.Sprintf("mfa:%s", challenge.ChallengeID)
+	cacheKey := a.cacheKeys.mfa(challenge.ChallengeID)
 	if err := a.setCache(ctx, cacheKey, challenge, 5*time.Minute); err != nil {
 		a.logger.Warn("Failed to cache MFA challenge", logging.Error(err))
 	}
@@ -342,19 +436,24 @@ func (a *KeycloakAdapter) VerifyMFA(ctx context.Context, challengeID, code strin
 	}
 
 	// Retrieve challenge from cache
-	cacheKey := fmt.Sprintf("mfa:%s", challengeID)
+	cacheKey := a.cacheKeys.mfa(challengeID)
 	var challenge models.MFAChallenge
 	if err := a.getFromCache(ctx, cacheKey, &challenge); err != nil {
 		a.logger.Error("MFA challenge not found", logging.Error(err))
 		return nil, ErrMFAFailed
 	}
 
-	// Verify the code (simplified - in production, verify with Keycloak)
-	// For now, accept any 6-digit code
-	if len(code) != 6 {
+	if challenge.UserID == "" || time.Now().After(challenge.ExpiresAt) {
 		return nil, ErrMFAFailed
 	}
 
+	if err := a.verifyTOTP(ctx, challenge.UserID, code); err != nil {
+		a.logger.Warn("MFA verification failed",
+			logging.String("challenge_id", challengeID),
+			logging.String("user_id", challenge.UserID))
+		return nil, err
+	}
+
 	verification := &models.MFAVerification{
 		Success:    true,
 		Method:     "totp",
@@ -363,7 +462,9 @@ func (a *KeycloakAdapter) VerifyMFA(ctx context.Context, challengeID, code strin
 
 	// Clear challenge from cache
 	if a.cache != nil {
-		_ = a.cache.Delete(ctx, cacheKey)
+		_ = a.withCacheTimeout(ctx, "delete", cacheKey, func(ctx context.Context) error {
+			return a.cache.Delete(ctx, cacheKey)
+		})
 	}
 
 	a.logger.Info("MFA verification successful",
@@ -374,12 +475,31 @@ func (a *KeycloakAdapter) VerifyMFA(ctx context.Context, challengeID, code strin
 
 // Cache helper methods
 
+// SetBusinessMetrics sets where cache hits, misses and timeouts are recorded
+func (a *KeycloakAdapter) SetBusinessMetrics(metrics *telemetry.BusinessMetrics) {
+	a.metrics = metrics
+}
+
+// getCached reads a cached result for operation and records the hit or miss. The operation,
+// not the key, is recorded so tokens and usernames never end up in metric labels.
+func (a *KeycloakAdapter) getCached(ctx context.Context, operation, key string, dest interface{}) error {
+	err := a.getFromCache(ctx, key, dest)
+	if err != nil {
+		a.metrics.RecordCacheMiss(ctx, keycloakCacheType, operation)
+		return err
+	}
+	a.metrics.RecordCacheHit(ctx, keycloakCacheType, operation)
+	return nil
+}
+
 func (a *KeycloakAdapter) getFromCache(ctx context.Context, key string, dest interface{}) error {
 	if a.cache == nil {
 		return fmt.Errorf("cache not available")
 	}
 
-	return a.cache.Get(ctx, key, dest)
+	return a.withCacheTimeout(ctx, "get", key, func(ctx context.Context) error {
+		return a.cache.Get(ctx, key, dest)
+	})
 }
 
 func (a *KeycloakAdapter) setCache(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
@@ -387,42 +507,108 @@ func (a *KeycloakAdapter) setCache(ctx context.Context, key string, value interf
 		return fmt.Errorf("cache not available")
 	}
 
-	return a.cache.Set(ctx, key, value, ttl)
+	return a.withCacheTimeout(ctx, "set", key, func(ctx context.Context) error {
+		return a.cache.Set(ctx, key, value, ttl)
+	})
 }
 
-func (a *KeycloakAdapter) clearTokenCache(ctx context.Context, token string) {
+// clearTokenCache removes the cached entries of the user the token belongs to. When the
+// token's subject cannot be read it falls back to clearing every user's entries. Each key or
+// pattern is attempted even if another fails; the failures are returned together.
+func (a *KeycloakAdapter) clearTokenCache(ctx context.Context, token string) error {
 	if a.cache == nil {
-		return
+		return nil
+	}
+
+	patterns := []string{a.cacheKeys.token(token)}
+	claims, err := decodeAccessTokenClaims(token)
+	if err == nil && claims.Subject != "" {
+		patterns = append(patterns,
+			a.cacheKeys.profile(claims.Subject),
+			a.cacheKeys.roles(claims.Subject),
+			a.cacheKeys.permissions(claims.Subject),
+			a.cacheKeys.permission(claims.Subject, "*", "*"),
+		)
+		if claims.Username != "" {
+			patterns = append(patterns, a.cacheKeys.auth(claims.Username))
+		}
+	} else {
+		a.logger.Warn("Could not determine token subject, clearing cache for all users", logging.Error(err))
+		patterns = append(patterns,
+			a.cacheKeys.auth("*"),
+			a.cacheKeys.profile("*"),
+			a.cacheKeys.roles("*"),
+			a.cacheKeys.permissions("*"),
+			a.cacheKeys.permission("*", "*", "*"),
+		)
+	}
+
+	var errs []error
+	for _, pattern := range patterns {
+		err := a.withCacheTimeout(ctx, "delete_pattern", pattern, func(ctx context.Context) error {
+			return a.cache.DeletePattern(ctx, pattern)
+		})
+		if err != nil {
+			a.logger.Warn("Failed to clear cache pattern",
+				logging.Error(err),
+				logging.String("key_prefix", keyPrefix(pattern)))
+			errs = append(errs, fmt.Errorf("%s: %w", keyPrefix(pattern), err))
+		}
 	}
 
-	// Clear token-related cache entries
-	patterns := []string{
-		"token:" + token,
-		"auth:*",
-		"profile:*",
-		"roles:*",
-		"permissions:*",
+	return errors.Join(errs...)
+}
+
+// withCacheTimeout runs a cache operation bounded by CacheOperationTimeout. A slow or hung
+// cache returns errCacheTimeout once the timeout passes, so callers fall through to Keycloak
+// instead of blocking. The operation's own context is cancelled at the same time.
+func (a *KeycloakAdapter) withCacheTimeout(ctx context.Context, operation, key string, fn func(ctx context.Context) error) error {
+	timeout := a.config.CacheOperationTimeout
+	if timeout <= 0 {
+		timeout = defaultCacheOperationTimeout
 	}
 
-	for _, pattern := range patterns {
-		if err := a.cache.DeletePattern(ctx, pattern); err != nil {
-			a.logger.Warn("Failed to clear cache pattern",
-				logging.Error(err),
-				logging.String("pattern", pattern))
+	opCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(opCtx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-opCtx.Done():
+		if ctx.Err() != nil {
+			return ctx.Err()
 		}
+		a.metrics.RecordCacheTimeout(ctx, keycloakCacheType, operation)
+		a.logger.Warn("Keycloak cache operation timed out",
+			logging.String("operation", operation),
+			logging.String("key_prefix", keyPrefix(key)),
+			logging.Duration("timeout", timeout))
+		return errCacheTimeout
 	}
 }
 
 // Helper functions
 
-func generateState() string {
-	// Generate a random state for OAuth2 flow
-	// In production, use crypto/rand
-	return fmt.Sprintf("state_%d", time.Now().UnixNano())
+// generateState returns an unguessable OAuth state
+func generateState() (string, error) {
+	return randomToken()
 }
 
-func generateChallengeID() string {
-	// Generate a unique challenge ID
-	// In production, use UUID or crypto/rand
-	return fmt.Sprintf("challenge_%d", time.Now().UnixNano())
+// generateChallengeID returns an unguessable MFA challenge ID
+func generateChallengeID() (string, error) {
+	return randomToken()
+}
+
+// randomToken returns randomTokenBytes bytes from crypto/rand as unpadded URL-safe base64
+func randomToken() (string, error) {
+	b := make([]byte, randomTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
@@ -0,0 +1,305 @@
+package keycloak
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"path"
+	"testing"
+	"time"
+
+	"backend-core/cache"
+	"backend-core/logging"
+	"backend-core/telemetry"
+)
+
+// mapCache is a cache.Cache holding JSON values in memory
+type mapCache struct {
+	cache.Cache
+	values map[string][]byte
+}
+
+func (m *mapCache) Get(ctx context.Context, key string, dest interface{}) error {
+	data, ok := m.values[key]
+	if !ok {
+		return cache.ErrCacheMiss
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (m *mapCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	m.values[key] = data
+	return nil
+}
+
+func (m *mapCache) GetDelete(ctx context.Context, key string, dest interface{}) error {
+	if err := m.Get(ctx, key, dest); err != nil {
+		return err
+	}
+	delete(m.values, key)
+	return nil
+}
+
+func (m *mapCache) Delete(ctx context.Context, key string) error {
+	delete(m.values, key)
+	return nil
+}
+
+func (m *mapCache) DeletePattern(ctx context.Context, pattern string) error {
+	for key := range m.values {
+		if matched, _ := path.Match(pattern, key); matched {
+			delete(m.values, key)
+		}
+	}
+	return nil
+}
+
+// cacheCounter counts the cache hits, misses and timeouts recorded per cache type and operation
+type cacheCounter struct {
+	telemetry.ContextlessMetrics
+	hits     map[string]int
+	misses   map[string]int
+	timeouts map[string]int
+}
+
+func (c *cacheCounter) RecordCacheHit(cacheType, key string) {
+	c.hits[cacheType+":"+key]++
+}
+
+func (c *cacheCounter) RecordCacheMiss(cacheType, key string) {
+	c.misses[cacheType+":"+key]++
+}
+
+func (c *cacheCounter) RecordCacheTimeout(cacheType, operation string) {
+	c.timeouts[cacheType+":"+operation]++
+}
+
+func TestAdapterRecordsCacheHitsAndMisses(t *testing.T) {
+	server := newFakeKeycloak(t)
+	logger := logging.NewNopLogger()
+	keycloakConfig := KeycloakConfig{
+		BaseURL:      server.URL,
+		Realm:        "test",
+		ClientID:     "auth-service",
+		ClientSecret: "secret",
+		CacheTTL:     time.Minute,
+	}
+	client, err := NewKeycloakClient(keycloakConfig, logger)
+	if err != nil {
+		t.Fatalf("NewKeycloakClient() error = %v", err)
+	}
+	adapter := NewKeycloakAdapter(client, &mapCache{values: make(map[string][]byte)}, keycloakConfig, logger)
+	counter := &cacheCounter{hits: make(map[string]int), misses: make(map[string]int)}
+	adapter.SetBusinessMetrics(&telemetry.BusinessMetrics{CacheHitCounter: counter, CacheMissCounter: counter})
+
+	// A miss falls through to Keycloak
+	if _, err := adapter.GetUserRoles(context.Background(), "user-1"); err != nil {
+		t.Fatalf("first GetUserRoles() error = %v", err)
+	}
+	if counter.misses["keycloak:roles"] != 1 || counter.hits["keycloak:roles"] != 0 {
+		t.Fatalf("after the first call hits = %v, misses = %v, want one roles miss", counter.hits, counter.misses)
+	}
+
+	// A hit is served from the cache, without Keycloak
+	server.Close()
+	roles, err := adapter.GetUserRoles(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("second GetUserRoles() error = %v, want the cached roles", err)
+	}
+	if len(roles) != 3 {
+		t.Errorf("cached roles = %v, want 3 roles", roles)
+	}
+	if counter.misses["keycloak:roles"] != 1 || counter.hits["keycloak:roles"] != 1 {
+		t.Errorf("after the second call hits = %v, misses = %v, want one roles hit and one miss", counter.hits, counter.misses)
+	}
+}
+
+// hungCache is a cache.Cache whose reads and writes block until the test ends, like a hung Redis
+type hungCache struct {
+	cache.Cache
+	release chan struct{}
+}
+
+func (h *hungCache) Get(ctx context.Context, key string, dest interface{}) error {
+	<-h.release
+	return cache.ErrCacheMiss
+}
+
+func (h *hungCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	<-h.release
+	return nil
+}
+
+func TestAdapterFallsBackToKeycloakWhenCacheHangs(t *testing.T) {
+	server := newFakeKeycloak(t)
+	logger := logging.NewNopLogger()
+	keycloakConfig := KeycloakConfig{
+		BaseURL:               server.URL,
+		Realm:                 "test",
+		ClientID:              "auth-service",
+		ClientSecret:          "secret",
+		CacheTTL:              time.Minute,
+		CacheOperationTimeout: 20 * time.Millisecond,
+	}
+	client, err := NewKeycloakClient(keycloakConfig, logger)
+	if err != nil {
+		t.Fatalf("NewKeycloakClient() error = %v", err)
+	}
+	hung := &hungCache{release: make(chan struct{})}
+	t.Cleanup(func() { close(hung.release) })
+	adapter := NewKeycloakAdapter(client, hung, keycloakConfig, logger)
+	counter := &cacheCounter{timeouts: make(map[string]int)}
+	adapter.SetBusinessMetrics(&telemetry.BusinessMetrics{CacheTimeoutCounter: counter})
+
+	start := time.Now()
+	roles, err := adapter.GetUserRoles(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("GetUserRoles() error = %v, want the roles from Keycloak", err)
+	}
+	if len(roles) != 3 {
+		t.Errorf("roles = %v, want 3 roles from Keycloak", roles)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("GetUserRoles() took %v with a hung cache", elapsed)
+	}
+	if counter.timeouts["keycloak:get"] != 1 || counter.timeouts["keycloak:set"] != 1 {
+		t.Errorf("cache timeouts = %v, want one for the read and one for the write", counter.timeouts)
+	}
+}
+
+// unsignedToken returns a JWT carrying claims, which clearTokenCache reads without verifying
+func unsignedToken(t *testing.T, claims map[string]string) string {
+	t.Helper()
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("failed to encode claims: %v", err)
+	}
+	return "e30." + base64.RawURLEncoding.EncodeToString(payload) + ".signature"
+}
+
+func TestClearTokenCacheOnlyClearsTheTokenUser(t *testing.T) {
+	logger := logging.NewNopLogger()
+	keys := adapterCacheKeys{}
+	seeded := func() *mapCache {
+		store := &mapCache{values: make(map[string][]byte)}
+		for _, user := range []struct{ id, name string }{{"user-1", "ann"}, {"user-2", "bob"}} {
+			for _, key := range []string{
+				keys.auth(user.name),
+				keys.profile(user.id),
+				keys.roles(user.id),
+				keys.permissions(user.id),
+				keys.permission(user.id, "users", "read"),
+			} {
+				store.values[key] = []byte("{}")
+			}
+		}
+		return store
+	}
+
+	store := seeded()
+	adapter := NewKeycloakAdapter(nil, store, KeycloakConfig{}, logger)
+	token := unsignedToken(t, map[string]string{"sub": "user-1", "preferred_username": "ann"})
+	if err := adapter.clearTokenCache(context.Background(), token); err != nil {
+		t.Fatalf("clearTokenCache() error = %v", err)
+	}
+
+	for _, key := range []string{"auth:ann", "profile:user-1", "roles:user-1", "permissions:user-1", "permission:user-1:users:read"} {
+		if _, ok := store.values[key]; ok {
+			t.Errorf("entry %s of the logged-out user survived", key)
+		}
+	}
+	for _, key := range []string{"auth:bob", "profile:user-2", "roles:user-2", "permissions:user-2", "permission:user-2:users:read"} {
+		if _, ok := store.values[key]; !ok {
+			t.Errorf("entry %s of another user was cleared", key)
+		}
+	}
+
+	// Without a readable subject every user's entries are cleared
+	store = seeded()
+	adapter = NewKeycloakAdapter(nil, store, KeycloakConfig{}, logger)
+	if err := adapter.clearTokenCache(context.Background(), "opaque-token"); err != nil {
+		t.Fatalf("clearTokenCache() error = %v", err)
+	}
+	if len(store.values) != 0 {
+		t.Errorf("entries %v survived the fallback", store.values)
+	}
+}
+
+func TestRandomTokensAreUnguessable(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		token, err := randomToken()
+		if err != nil {
+			t.Fatalf("randomToken() error = %v", err)
+		}
+		decoded, err := base64.RawURLEncoding.DecodeString(token)
+		if err != nil {
+			t.Fatalf("token %q is not URL-safe base64: %v", token, err)
+		}
+		if len(decoded) < 16 {
+			t.Fatalf("token %q carries %d bits, want at least 128", token, len(decoded)*8)
+		}
+		if seen[token] {
+			t.Fatalf("token %q generated twice", token)
+		}
+		seen[token] = true
+	}
+}
+
+func TestSSOStateIsValidatedOnce(t *testing.T) {
+	logger := logging.NewNopLogger()
+	keycloakConfig := KeycloakConfig{
+		BaseURL:     "https://keycloak.example.com",
+		Realm:       "test",
+		ClientID:    "auth-service",
+		RedirectURI: "https://app.example.com/callback",
+		EnableSSO:   true,
+	}
+	keycloakConfig.BuildURLs()
+	adapter := NewKeycloakAdapter(nil, &mapCache{values: make(map[string][]byte)}, keycloakConfig, logger)
+	ctx := context.Background()
+
+	authURL, err := adapter.InitiateSSOLogin(ctx, "google")
+	if err != nil {
+		t.Fatalf("InitiateSSOLogin() error = %v", err)
+	}
+	parsed, err := url.Parse(authURL.URL)
+	if err != nil {
+		t.Fatalf("invalid authorization URL %q: %v", authURL.URL, err)
+	}
+	if parsed.Query().Get("state") != authURL.State {
+		t.Errorf("URL state = %q, want %q", parsed.Query().Get("state"), authURL.State)
+	}
+
+	if err := adapter.ValidateSSOState(ctx, "forged-state"); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("ValidateSSOState(forged) error = %v, want ErrInvalidState", err)
+	}
+	if err := adapter.ValidateSSOState(ctx, authURL.State); err != nil {
+		t.Fatalf("ValidateSSOState() error = %v", err)
+	}
+	if err := adapter.ValidateSSOState(ctx, authURL.State); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("replayed ValidateSSOState() error = %v, want ErrInvalidState", err)
+	}
+}
+
+func TestKeyPrefixHidesSecrets(t *testing.T) {
+	keys := adapterCacheKeys{}
+	tests := map[string]string{
+		keys.token("eyJhbGciOi.secret.sig"): "token",
+		keys.ssoState("state-value"):        "sso_state",
+		keys.permission("user-1", "a", "b"): "permission",
+		"no-separator":                      "no-separator",
+	}
+	for key, want := range tests {
+		if got := keyPrefix(key); got != want {
+			t.Errorf("keyPrefix(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package keycloak
+
+import (
+	"fmt"
+	"strings"
+)
+
+// adapterCacheKeys builds the cache keys used by KeycloakAdapter.
+// Reads, writes and invalidation must all go through it so the keys cannot drift.
+type adapterCacheKeys struct{}
+
+// auth returns the cache key for an authentication result
+func (adapterCacheKeys) auth(username string) string {
+	return fmt.Sprintf("auth:%s", username)
+}
+
+// token returns the cache key for a token validation result
+func (adapterCacheKeys) token(token string) string {
+	return fmt.Sprintf("token:%s", token)
+}
+
+// profile returns the cache key for a user profile
+func (adapterCacheKeys) profile(userID string) string {
+	return fmt.Sprintf("profile:%s", userID)
+}
+
+// permission returns the cache key for a single permission check
+func (adapterCacheKeys) permission(userID, resource, action string) string {
+	return fmt.Sprintf("permission:%s:%s:%s", userID, resource, action)
+}
+
+// roles returns the cache key for a user's roles
+func (adapterCacheKeys) roles(userID string) string {
+	return fmt.Sprintf("roles:%s", userID)
+}
+
+// permissions returns the cache key for a user's permission list
+func (adapterCacheKeys) permissions(userID string) string {
+	return fmt.Sprintf("permissions:%s", userID)
+}
+
+// mfa returns the cache key for an MFA challenge
+func (adapterCacheKeys) mfa(challengeID string) string {
+	return fmt.Sprintf("mfa:%s", challengeID)
+}
+
+// ssoState returns the cache key for a pending SSO login state
+func (adapterCacheKeys) ssoState(state string) string {
+	return fmt.Sprintf("sso_state:%s", state)
+}
+
+// totpSecret returns the cache key for a user's TOTP secret
+func (adapterCacheKeys) totpSecret(userID string) string {
+	return fmt.Sprintf("totp_secret:%s", userID)
+}
+
+// totpUsed returns the cache key marking a user's TOTP code for step as used
+func (adapterCacheKeys) totpUsed(userID string, step uint64) string {
+	return fmt.Sprintf("totp_used:%s:%d", userID, step)
+}
+
+// keyPrefix returns the kind of entry key refers to, e.g. "token" for a token key. Keys carry
+// bearer tokens, SSO states and MFA challenge IDs, so only their prefix may be logged.
+func keyPrefix(key string) string {
+	prefix, _, _ := strings.Cut(key, ":")
+	return prefix
+}
@@ -9,9 +9,11 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"auth-service/src/infrastructure/identity/models"
+	"backend-core/grpc/interceptors/retry"
 	"backend-core/logging"
 )
 
@@ -26,6 +28,13 @@ type KeycloakClient struct {
 	accessToken  string
 	tokenExpiry  time.Time
 	config       KeycloakConfig
+	breaker      *retry.CircuitBreaker
+	jwks         *JWKSCache
+
+	// Service account token for admin operations, see service_account.go
+	serviceToken       string
+	serviceTokenExpiry time.Time
+	serviceMu          sync.Mutex
 }
 
 // NewKeycloakClient creates a new Keycloak HTTP client
@@ -36,7 +45,19 @@ func NewKeycloakClient(config KeycloakConfig, logger *logging.Logger) (*Keycloak
 
 	config.BuildURLs()
 
-	return &KeycloakClient{
+	breakerConfig := config.CircuitBreaker
+	defaults := DefaultCircuitBreakerConfig()
+	if breakerConfig.FailureThreshold <= 0 {
+		breakerConfig.FailureThreshold = defaults.FailureThreshold
+	}
+	if breakerConfig.Cooldown <= 0 {
+		breakerConfig.Cooldown = defaults.Cooldown
+	}
+	if breakerConfig.HalfOpenSuccesses <= 0 {
+		breakerConfig.HalfOpenSuccesses = defaults.HalfOpenSuccesses
+	}
+
+	client := &KeycloakClient{
 		baseURL:      config.BaseURL,
 		realm:        config.Realm,
 		clientID:     config.ClientID,
@@ -46,7 +67,12 @@ func NewKeycloakClient(config KeycloakConfig, logger *logging.Logger) (*Keycloak
 		},
 		logger: logger,
 		config: config,
-	}, nil
+		breaker: retry.NewCircuitBreaker(breakerConfig.FailureThreshold,
+			breakerConfig.Cooldown, breakerConfig.HalfOpenSuccesses),
+	}
+	client.jwks = newJWKSCache(client.fetchJWKS, config.OAuth.JWKSCacheTTL, config.OAuth.JWKSMinRefreshInterval, logger)
+
+	return client, nil
 }
 
 // Authenticate authenticates a user with Keycloak using Resource Owner Password Credentials flow
@@ -176,7 +202,7 @@ func (c *KeycloakClient) GetUserProfile(ctx context.Context, userID string) (*mo
 		Email:      userInfo.Email,
 		FirstName:  userInfo.GivenName,
 		LastName:   userInfo.FamilyName,
-		Roles:      userInfo.RealmRoles,
+		Roles:      userInfo.RealmAccess.Roles,
 		Groups:     userInfo.Groups,
 		Attributes: make(map[string]interface{}),
 		CreatedAt:  time.Now(),
@@ -226,9 +252,10 @@ func (c *KeycloakClient) CheckPermissions(ctx context.Context, userID, resource,
 	return result, nil
 }
 
-// GetUserRoles retrieves user roles from Keycloak
+// GetUserRoles retrieves the user's effective realm roles and the roles granted for the
+// configured client from the Admin API, authenticating as the client's service account
 func (c *KeycloakClient) GetUserRoles(ctx context.Context, userID string) ([]string, error) {
-	userInfo, err := c.getUserInfo(ctx, c.accessToken)
+	roles, err := c.getUserRoleMappings(ctx, userID)
 	if err != nil {
 		c.logger.Error("Failed to get user roles from Keycloak",
 			logging.Error(err),
@@ -236,19 +263,6 @@ func (c *KeycloakClient) GetUserRoles(ctx context.Context, userID string) ([]str
 		return nil, err
 	}
 
-	roles := userInfo.RealmRoles
-	if userInfo.ResourceAccess != nil {
-		if clientRoles, ok := userInfo.ResourceAccess[c.clientID]; ok {
-			if clientRoleList, ok := clientRoles["roles"].([]interface{}); ok {
-				for _, role := range clientRoleList {
-					if roleStr, ok := role.(string); ok {
-						roles = append(roles, roleStr)
-					}
-				}
-			}
-		}
-	}
-
 	c.logger.Debug("User roles retrieved from Keycloak",
 		logging.String("user_id", userID),
 		logging.Int("role_count", len(roles)))
@@ -354,6 +368,73 @@ func (c *KeycloakClient) HealthCheck(ctx context.Context) error {
 
 // Helper methods
 
+// adminRealmURL returns the Admin API URL of the configured realm
+func (c *KeycloakClient) adminRealmURL() string {
+	return c.baseURL + "/admin/realms/" + url.PathEscape(c.realm)
+}
+
+// getUserRoleMappings reads the composite realm and client role mappings of userID
+func (c *KeycloakClient) getUserRoleMappings(ctx context.Context, userID string) ([]string, error) {
+	userURL := c.adminRealmURL() + "/users/" + url.PathEscape(userID)
+
+	realmRoles, err := c.getRoleNames(ctx, userURL+"/role-mappings/realm/composite")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get realm roles: %w", err)
+	}
+
+	clientUUID, err := c.getClientUUID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	clientRoles, err := c.getRoleNames(ctx, userURL+"/role-mappings/clients/"+url.PathEscape(clientUUID)+"/composite")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client roles: %w", err)
+	}
+
+	return mergeRoles(realmRoles, clientRoles), nil
+}
+
+// getClientUUID looks up the internal ID of the configured client, which the Admin API
+// uses in place of the client ID
+func (c *KeycloakClient) getClientUUID(ctx context.Context) (string, error) {
+	body, err := c.get(ctx, c.adminRealmURL()+"/clients?clientId="+url.QueryEscape(c.clientID))
+	if err != nil {
+		return "", fmt.Errorf("failed to look up client %s: %w", c.clientID, err)
+	}
+
+	var clients []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &clients); err != nil {
+		return "", fmt.Errorf("failed to parse clients: %w", err)
+	}
+	if len(clients) == 0 {
+		return "", fmt.Errorf("client %s not found in realm %s", c.clientID, c.realm)
+	}
+	return clients[0].ID, nil
+}
+
+// getRoleNames returns the names of the roles listed at endpoint
+func (c *KeycloakClient) getRoleNames(ctx context.Context, endpoint string) ([]string, error) {
+	body, err := c.get(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var roles []struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(body, &roles); err != nil {
+		return nil, fmt.Errorf("failed to parse roles: %w", err)
+	}
+
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	return names, nil
+}
+
 // getUserInfo retrieves user information from Keycloak userinfo endpoint
 func (c *KeycloakClient) getUserInfo(ctx context.Context, accessToken string) (*KeycloakUserInfo, error) {
 	userInfoURL := c.config.OAuth.UserInfoURL
@@ -366,7 +447,7 @@ func (c *KeycloakClient) getUserInfo(ctx context.Context, accessToken string) (*
 	req.Header.Set("Authorization", "Bearer "+accessToken)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.doRequest(req)
 	if err != nil {
 		return nil, err
 	}
@@ -390,6 +471,43 @@ func (c *KeycloakClient) getUserInfo(ctx context.Context, accessToken string) (*
 	return &userInfo, nil
 }
 
+// doRequest sends req through the circuit breaker. Transport errors and 5xx responses count
+// as failures; once the breaker opens, requests fail fast with ErrKeycloakUnavailable until
+// the cooldown has passed.
+func (c *KeycloakClient) doRequest(req *http.Request) (*http.Response, error) {
+	if !c.breaker.CanAttempt() {
+		return nil, fmt.Errorf("%w: circuit breaker is open", ErrKeycloakUnavailable)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.recordFailure(err)
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		c.recordFailure(fmt.Errorf("HTTP %d", resp.StatusCode))
+	} else {
+		c.breaker.RecordSuccess()
+	}
+
+	return resp, nil
+}
+
+// recordFailure records a failed call and logs when it opens the breaker
+func (c *KeycloakClient) recordFailure(err error) {
+	wasOpen := c.breaker.GetState() == retry.StateOpen
+	c.breaker.RecordFailure()
+	if !wasOpen && c.breaker.GetState() == retry.StateOpen {
+		c.logger.Warn("Keycloak circuit breaker opened", logging.Error(err))
+	}
+}
+
+// CircuitBreakerState returns the state of the circuit breaker guarding Keycloak calls
+func (c *KeycloakClient) CircuitBreakerState() string {
+	return c.breaker.GetState().String()
+}
+
 func (c *KeycloakClient) postForm(ctx context.Context, endpoint string, data url.Values) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(data.Encode()))
 	if err != nil {
@@ -398,7 +516,7 @@ func (c *KeycloakClient) postForm(ctx context.Context, endpoint string, data url
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.doRequest(req)
 	if err != nil {
 		return nil, err
 	}
@@ -427,13 +545,15 @@ func (c *KeycloakClient) post(ctx context.Context, endpoint string, data interfa
 		return nil, err
 	}
 
-	// Add authorization header if we have a token
-	if c.accessToken != "" && time.Now().Before(c.tokenExpiry) {
-		req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	// Admin operations authenticate as the client's service account
+	serviceToken, err := c.GetServiceToken(ctx)
+	if err != nil {
+		return nil, err
 	}
+	req.Header.Set("Authorization", "Bearer "+serviceToken)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.doRequest(req)
 	if err != nil {
 		return nil, err
 	}
@@ -451,19 +571,55 @@ func (c *KeycloakClient) post(ctx context.Context, endpoint string, data interfa
 	return body, nil
 }
 
+// put sends data to an Admin API endpoint that answers with no content, such as a user update
+func (c *KeycloakClient) put(ctx context.Context, endpoint string, data interface{}) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+
+	// Admin operations authenticate as the client's service account
+	serviceToken, err := c.GetServiceToken(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+serviceToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("HTTP %d: %s - %s", resp.StatusCode, resp.Status, string(body))
+	}
+
+	return nil
+}
+
 func (c *KeycloakClient) get(ctx context.Context, endpoint string) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	// Add authorization header if we have a token
-	if c.accessToken != "" && time.Now().Before(c.tokenExpiry) {
-		req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	// Admin operations authenticate as the client's service account
+	serviceToken, err := c.GetServiceToken(ctx)
+	if err != nil {
+		return nil, err
 	}
+	req.Header.Set("Authorization", "Bearer "+serviceToken)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.doRequest(req)
 	if err != nil {
 		return nil, err
 	}
@@ -490,7 +646,12 @@ type KeycloakUserInfo struct {
 	FamilyName        string                            `json:"family_name"`
 	Email             string                            `json:"email"`
 	EmailVerified     bool                              `json:"email_verified"`
-	RealmRoles        []string                          `json:"realm_access.roles"`
+	RealmAccess       KeycloakRealmAccess               `json:"realm_access"`
 	Groups            []string                          `json:"groups"`
 	ResourceAccess    map[string]map[string]interface{} `json:"resource_access"`
 }
+
+// KeycloakRealmAccess holds the realm-level roles from the realm_access claim
+type KeycloakRealmAccess struct {
+	Roles []string `json:"roles"`
+}
@@ -0,0 +1,145 @@
+package keycloak
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"backend-core/logging"
+)
+
+// newFakeKeycloak serves the token and userinfo endpoints and the Admin API representation
+// and role mappings of user-1. Admin calls must carry the service account token.
+func newFakeKeycloak(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	writeJSON := func(w http.ResponseWriter, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+	requireServiceToken := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer service-token" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next(w, r)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/realms/test/protocol/openid-connect/token", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{"access_token": "service-token", "token_type": "Bearer", "expires_in": 300})
+	})
+	mux.HandleFunc("/realms/test/protocol/openid-connect/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(sampleUserInfo))
+	})
+	mux.HandleFunc("/admin/realms/test/clients", requireServiceToken(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("clientId") != "auth-service" {
+			writeJSON(w, []interface{}{})
+			return
+		}
+		writeJSON(w, []map[string]string{{"id": "client-uuid", "clientId": "auth-service"}})
+	}))
+	var userMu sync.Mutex
+	user := map[string]interface{}{"id": "user-1", "username": "ann", "attributes": map[string][]string{"locale": {"en"}}}
+	mux.HandleFunc("/admin/realms/test/users/user-1", requireServiceToken(func(w http.ResponseWriter, r *http.Request) {
+		userMu.Lock()
+		defer userMu.Unlock()
+
+		if r.Method == http.MethodPut {
+			updated := make(map[string]interface{})
+			if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			user = updated
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, user)
+	}))
+	mux.HandleFunc("/admin/realms/test/users/user-1/role-mappings/realm/composite", requireServiceToken(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []map[string]string{{"name": "user"}, {"name": "offline_access"}})
+	}))
+	mux.HandleFunc("/admin/realms/test/users/user-1/role-mappings/clients/client-uuid/composite", requireServiceToken(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []map[string]string{{"name": "admin"}, {"name": "user"}})
+	}))
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// sampleUserInfo is a userinfo response as returned by Keycloak
+const sampleUserInfo = `{
+	"sub": "user-1",
+	"preferred_username": "ann",
+	"email": "ann@example.com",
+	"email_verified": true,
+	"realm_access": {"roles": ["user", "offline_access"]},
+	"resource_access": {"auth-service": {"roles": ["admin"]}},
+	"groups": ["/staff"]
+}`
+
+func TestUserInfoParsesRealmRoles(t *testing.T) {
+	var userInfo KeycloakUserInfo
+	if err := json.Unmarshal([]byte(sampleUserInfo), &userInfo); err != nil {
+		t.Fatalf("failed to parse user info: %v", err)
+	}
+	if want := []string{"user", "offline_access"}; !reflect.DeepEqual(userInfo.RealmAccess.Roles, want) {
+		t.Errorf("realm roles = %v, want %v", userInfo.RealmAccess.Roles, want)
+	}
+
+	server := newFakeKeycloak(t)
+	logger := logging.NewNopLogger()
+	client, err := NewKeycloakClient(KeycloakConfig{
+		BaseURL:      server.URL,
+		Realm:        "test",
+		ClientID:     "auth-service",
+		ClientSecret: "secret",
+	}, logger)
+	if err != nil {
+		t.Fatalf("NewKeycloakClient() error = %v", err)
+	}
+
+	profile, err := client.GetUserProfile(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("GetUserProfile() error = %v", err)
+	}
+	if want := []string{"user", "offline_access"}; !reflect.DeepEqual(profile.Roles, want) {
+		t.Errorf("profile roles = %v, want %v", profile.Roles, want)
+	}
+}
+
+func TestGetUserRolesLooksUpTheRequestedUser(t *testing.T) {
+	server := newFakeKeycloak(t)
+	logger := logging.NewNopLogger()
+	client, err := NewKeycloakClient(KeycloakConfig{
+		BaseURL:      server.URL,
+		Realm:        "test",
+		ClientID:     "auth-service",
+		ClientSecret: "secret",
+	}, logger)
+	if err != nil {
+		t.Fatalf("NewKeycloakClient() error = %v", err)
+	}
+
+	roles, err := client.GetUserRoles(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("GetUserRoles() error = %v", err)
+	}
+	want := []string{"user", "offline_access", "admin"}
+	if !reflect.DeepEqual(roles, want) {
+		t.Errorf("GetUserRoles() = %v, want %v", roles, want)
+	}
+
+	if _, err := client.GetUserRoles(context.Background(), "user-2"); err == nil {
+		t.Error("GetUserRoles() of an unknown user succeeded, want an error")
+	}
+}
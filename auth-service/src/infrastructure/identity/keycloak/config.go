@@ -6,18 +6,22 @@ import (
 
 // KeycloakConfig holds configuration for Keycloak integration
 type KeycloakConfig struct {
-	BaseURL         string        `yaml:"base_url" env:"KEYCLOAK_BASE_URL"`
-	Realm           string        `yaml:"realm" env:"KEYCLOAK_REALM"`
-	ClientID        string        `yaml:"client_id" env:"KEYCLOAK_CLIENT_ID"`
-	ClientSecret    string        `yaml:"client_secret" env:"KEYCLOAK_CLIENT_SECRET"`
-	RedirectURI     string        `yaml:"redirect_uri" env:"KEYCLOAK_REDIRECT_URI"`
-	Scopes          []string      `yaml:"scopes" env:"KEYCLOAK_SCOPES"`
-	Timeout         time.Duration `yaml:"timeout" env:"KEYCLOAK_TIMEOUT"`
-	RetryAttempts   int           `yaml:"retry_attempts" env:"KEYCLOAK_RETRY_ATTEMPTS"`
-	CacheTTL        time.Duration `yaml:"cache_ttl" env:"KEYCLOAK_CACHE_TTL"`
-	EnableSSO       bool          `yaml:"enable_sso" env:"KEYCLOAK_ENABLE_SSO"`
-	EnableMFA       bool          `yaml:"enable_mfa" env:"KEYCLOAK_ENABLE_MFA"`
-	EnableRiskBased bool          `yaml:"enable_risk_based" env:"KEYCLOAK_ENABLE_RISK_BASED"`
+	BaseURL               string        `yaml:"base_url" env:"KEYCLOAK_BASE_URL"`
+	Realm                 string        `yaml:"realm" env:"KEYCLOAK_REALM"`
+	ClientID              string        `yaml:"client_id" env:"KEYCLOAK_CLIENT_ID"`
+	ClientSecret          string        `yaml:"client_secret" env:"KEYCLOAK_CLIENT_SECRET"`
+	RedirectURI           string        `yaml:"redirect_uri" env:"KEYCLOAK_REDIRECT_URI"`
+	Scopes                []string      `yaml:"scopes" env:"KEYCLOAK_SCOPES"`
+	Timeout               time.Duration `yaml:"timeout" env:"KEYCLOAK_TIMEOUT"`
+	RetryAttempts         int           `yaml:"retry_attempts" env:"KEYCLOAK_RETRY_ATTEMPTS"`
+	CacheTTL              time.Duration `yaml:"cache_ttl" env:"KEYCLOAK_CACHE_TTL"`
+	CacheOperationTimeout time.Duration `yaml:"cache_operation_timeout" env:"KEYCLOAK_CACHE_OPERATION_TIMEOUT"`
+	TokenRefreshWindow    time.Duration `yaml:"token_refresh_window" env:"KEYCLOAK_TOKEN_REFRESH_WINDOW"`
+	TokenRefreshInterval  time.Duration `yaml:"token_refresh_interval" env:"KEYCLOAK_TOKEN_REFRESH_INTERVAL"`
+	EnableSSO             bool          `yaml:"enable_sso" env:"KEYCLOAK_ENABLE_SSO"`
+	EnableMFA             bool          `yaml:"enable_mfa" env:"KEYCLOAK_ENABLE_MFA"`
+	MFATOTPSkew           int           `yaml:"mfa_totp_skew" env:"KEYCLOAK_MFA_TOTP_SKEW"`
+	EnableRiskBased       bool          `yaml:"enable_risk_based" env:"KEYCLOAK_ENABLE_RISK_BASED"`
 
 	// SAML Configuration
 	SAML SAMLConfig `yaml:"saml"`
@@ -30,6 +34,9 @@ type KeycloakConfig struct {
 
 	// Admin API Configuration
 	Admin AdminConfig `yaml:"admin"`
+
+	// Circuit Breaker Configuration
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit_breaker"`
 }
 
 // SAMLConfig holds SAML-specific configuration
@@ -51,6 +58,11 @@ type OAuthConfig struct {
 	JWKSURL          string   `yaml:"jwks_url" env:"KEYCLOAK_OAUTH_JWKS_URL"`
 	Scopes           []string `yaml:"scopes" env:"KEYCLOAK_OAUTH_SCOPES"`
 	Enabled          bool     `yaml:"enabled" env:"KEYCLOAK_OAUTH_ENABLED"`
+
+	// JWKSCacheTTL is how long fetched signing keys are cached
+	JWKSCacheTTL time.Duration `yaml:"jwks_cache_ttl" env:"KEYCLOAK_OAUTH_JWKS_CACHE_TTL"`
+	// JWKSMinRefreshInterval is the minimum time between JWKS fetches, bounding refreshes forced by unknown kids
+	JWKSMinRefreshInterval time.Duration `yaml:"jwks_min_refresh_interval" env:"KEYCLOAK_OAUTH_JWKS_MIN_REFRESH_INTERVAL"`
 }
 
 // PolicyConfig holds policy engine configuration
@@ -68,21 +80,32 @@ type AdminConfig struct {
 	Enabled  bool   `yaml:"enabled" env:"KEYCLOAK_ADMIN_ENABLED"`
 }
 
+// CircuitBreakerConfig holds the circuit breaker settings for Keycloak HTTP calls
+type CircuitBreakerConfig struct {
+	FailureThreshold  int           `yaml:"failure_threshold" env:"KEYCLOAK_CB_FAILURE_THRESHOLD"`
+	Cooldown          time.Duration `yaml:"cooldown" env:"KEYCLOAK_CB_COOLDOWN"`
+	HalfOpenSuccesses int           `yaml:"half_open_successes" env:"KEYCLOAK_CB_HALF_OPEN_SUCCESSES"`
+}
+
 // NewKeycloakConfig creates a new Keycloak configuration from environment-aware defaults
 func NewKeycloakConfig() *KeycloakConfig {
 	return &KeycloakConfig{
-		BaseURL:         "",
-		Realm:           "",
-		ClientID:        "",
-		ClientSecret:    "",
-		RedirectURI:     "",
-		Scopes:          []string{"openid", "profile", "email", "roles"},
-		Timeout:         30 * time.Second,
-		RetryAttempts:   3,
-		CacheTTL:        5 * time.Minute,
-		EnableSSO:       true,
-		EnableMFA:       true,
-		EnableRiskBased: false,
+		BaseURL:               "",
+		Realm:                 "",
+		ClientID:              "",
+		ClientSecret:          "",
+		RedirectURI:           "",
+		Scopes:                []string{"openid", "profile", "email", "roles"},
+		Timeout:               30 * time.Second,
+		RetryAttempts:         3,
+		CacheTTL:              5 * time.Minute,
+		CacheOperationTimeout: 200 * time.Millisecond,
+		TokenRefreshWindow:    time.Minute,
+		TokenRefreshInterval:  30 * time.Second,
+		EnableSSO:             true,
+		EnableMFA:             true,
+		MFATOTPSkew:           1,
+		EnableRiskBased:       false,
 		SAML: SAMLConfig{
 			EntityID:    "",
 			SSOURL:      "",
@@ -92,13 +115,15 @@ func NewKeycloakConfig() *KeycloakConfig {
 			Enabled:     false,
 		},
 		OAuth: OAuthConfig{
-			AuthorizationURL: "",
-			TokenURL:         "",
-			UserInfoURL:      "",
-			LogoutURL:        "",
-			JWKSURL:          "",
-			Scopes:           []string{"openid", "profile", "email", "roles"},
-			Enabled:          true,
+			AuthorizationURL:       "",
+			TokenURL:               "",
+			UserInfoURL:            "",
+			LogoutURL:              "",
+			JWKSURL:                "",
+			Scopes:                 []string{"openid", "profile", "email", "roles"},
+			Enabled:                true,
+			JWKSCacheTTL:           10 * time.Minute,
+			JWKSMinRefreshInterval: 30 * time.Second,
 		},
 		Policy: PolicyConfig{
 			PolicyURL:   "",
@@ -111,6 +136,16 @@ func NewKeycloakConfig() *KeycloakConfig {
 			Password: "",
 			Enabled:  false,
 		},
+		CircuitBreaker: DefaultCircuitBreakerConfig(),
+	}
+}
+
+// DefaultCircuitBreakerConfig returns the circuit breaker settings used when none are configured
+func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		FailureThreshold:  5,
+		Cooldown:          30 * time.Second,
+		HalfOpenSuccesses: 1,
 	}
 }
 
@@ -23,10 +23,14 @@ var (
 	ErrUserNotFound  = errors.New("user not found")
 	ErrInvalidUserID = errors.New("invalid user ID")
 
+	// SSO errors
+	ErrInvalidState = errors.New("invalid or expired SSO state")
+
 	// MFA errors
-	ErrMFARequired      = errors.New("MFA verification required")
-	ErrMFAFailed        = errors.New("MFA verification failed")
-	ErrInvalidMFAMethod = errors.New("invalid MFA method")
+	ErrMFARequired        = errors.New("MFA verification required")
+	ErrMFAFailed          = errors.New("MFA verification failed")
+	ErrInvalidMFAMethod   = errors.New("invalid MFA method")
+	ErrMFAAlreadyEnrolled = errors.New("user is already enrolled for MFA")
 
 	// General errors
 	ErrKeycloakUnavailable = errors.New("keycloak service unavailable")
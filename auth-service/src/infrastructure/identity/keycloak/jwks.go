@@ -0,0 +1,217 @@
+package keycloak
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"backend-core/logging"
+)
+
+// ErrUnknownKeyID is returned when a token's kid is not in the realm's JWKS, even after a refresh
+var ErrUnknownKeyID = errors.New("unknown signing key ID")
+
+const (
+	// defaultJWKSCacheTTL is how long fetched keys are trusted before the JWKS is fetched again
+	defaultJWKSCacheTTL = 10 * time.Minute
+	// defaultJWKSMinRefreshInterval bounds how often an unknown kid may force a refresh
+	defaultJWKSMinRefreshInterval = 30 * time.Second
+)
+
+// jsonWebKey is one entry of a JWKS document
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// jwksFetcher fetches the raw JWKS document
+type jwksFetcher func(ctx context.Context) ([]byte, error)
+
+// JWKSCache caches the realm's RSA signing keys by kid. Keys are refetched once the TTL
+// expires, and a kid that is not cached forces an early refresh to pick up rotated keys.
+// Forced refreshes happen at most once per minRefreshInterval, so tokens with forged kids
+// cannot make every request hit Keycloak.
+type JWKSCache struct {
+	fetch              jwksFetcher
+	ttl                time.Duration
+	minRefreshInterval time.Duration
+	logger             *logging.Logger
+
+	mu          sync.RWMutex
+	keys        map[string]*rsa.PublicKey
+	fetchedAt   time.Time
+	refreshMu   sync.Mutex
+	lastRefresh time.Time
+	lastErr     error
+}
+
+// newJWKSCache creates a JWKS cache. Zero durations use the defaults.
+func newJWKSCache(fetch jwksFetcher, ttl, minRefreshInterval time.Duration, logger *logging.Logger) *JWKSCache {
+	if ttl <= 0 {
+		ttl = defaultJWKSCacheTTL
+	}
+	if minRefreshInterval <= 0 {
+		minRefreshInterval = defaultJWKSMinRefreshInterval
+	}
+	return &JWKSCache{
+		fetch:              fetch,
+		ttl:                ttl,
+		minRefreshInterval: minRefreshInterval,
+		logger:             logger,
+		keys:               make(map[string]*rsa.PublicKey),
+	}
+}
+
+// GetKey returns the public key for kid, refreshing the JWKS when it is stale or kid is unknown
+func (c *JWKSCache) GetKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	key, fresh := c.lookup(kid)
+	if key != nil && fresh {
+		return key, nil
+	}
+
+	if err := c.refresh(ctx); err != nil {
+		// Keep serving a known key when Keycloak cannot be reached
+		if key != nil {
+			c.logger.Warn("Using stale JWKS key after refresh failure",
+				logging.String("kid", kid),
+				logging.Error(err))
+			return key, nil
+		}
+		return nil, err
+	}
+
+	if key, _ := c.lookup(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownKeyID, kid)
+}
+
+// lookup returns the cached key for kid and whether the cache is within its TTL
+func (c *JWKSCache) lookup(kid string) (*rsa.PublicKey, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.keys[kid], !c.fetchedAt.IsZero() && time.Since(c.fetchedAt) < c.ttl
+}
+
+// refresh fetches the JWKS, at most once per minRefreshInterval. Callers arriving within the
+// interval, including those that waited on another caller's refresh, get that refresh's result.
+func (c *JWKSCache) refresh(ctx context.Context) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	if !c.lastRefresh.IsZero() && time.Since(c.lastRefresh) < c.minRefreshInterval {
+		return c.lastErr
+	}
+	c.lastRefresh = time.Now()
+	c.lastErr = c.load(ctx)
+	return c.lastErr
+}
+
+// load fetches and parses the JWKS and replaces the cached keys
+func (c *JWKSCache) load(ctx context.Context) error {
+	body, err := c.fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+
+	keys, err := parseJWKS(body)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.keys = keys
+	c.fetchedAt = time.Now()
+	c.mu.Unlock()
+
+	c.logger.Debug("JWKS refreshed", logging.Int("key_count", len(keys)))
+	return nil
+}
+
+// parseJWKS extracts the RSA signing keys from a JWKS document
+func parseJWKS(body []byte) (map[string]*rsa.PublicKey, error) {
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse JWKS: %v", ErrInvalidResponse, err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(doc.Keys))
+	for _, jwk := range doc.Keys {
+		if jwk.Kty != "RSA" || jwk.Kid == "" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := jwk.rsaPublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %s: %v", ErrInvalidResponse, jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+
+	return keys, nil
+}
+
+// rsaPublicKey decodes the modulus and exponent of an RSA JWK
+func (k jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() > int64(^uint32(0)>>1) {
+		return nil, fmt.Errorf("exponent out of range")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(exponent.Int64()),
+	}, nil
+}
+
+// fetchJWKS fetches the realm's JWKS document through the circuit breaker
+func (c *KeycloakClient) fetchJWKS(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.config.OAuth.JWKSURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d: %s - %s", resp.StatusCode, resp.Status, string(body))
+	}
+
+	return body, nil
+}
+
+// SigningKey returns the realm public key for a token's kid, using the JWKS cache
+func (c *KeycloakClient) SigningKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	return c.jwks.GetKey(ctx, kid)
+}
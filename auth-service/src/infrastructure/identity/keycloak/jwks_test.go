@@ -0,0 +1,113 @@
+package keycloak
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"backend-core/logging"
+)
+
+// jwksServer serves a JWKS document holding the current keys and counts the fetches
+type jwksServer struct {
+	keys    map[string]*rsa.PublicKey
+	fetches int
+}
+
+func (s *jwksServer) fetch(ctx context.Context) ([]byte, error) {
+	s.fetches++
+	doc := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+	for kid, key := range s.keys {
+		doc.Keys = append(doc.Keys, jsonWebKey{
+			Kid: kid,
+			Kty: "RSA",
+			Use: "sig",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	return json.Marshal(doc)
+}
+
+func newSigningKey(t *testing.T) *rsa.PublicKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return &key.PublicKey
+}
+
+func newTestJWKSCache(t *testing.T, server *jwksServer) *JWKSCache {
+	t.Helper()
+
+	logger := logging.NewNopLogger()
+	return newJWKSCache(server.fetch, time.Hour, time.Minute, logger)
+}
+
+func TestJWKSCacheHit(t *testing.T) {
+	key := newSigningKey(t)
+	server := &jwksServer{keys: map[string]*rsa.PublicKey{"k1": key}}
+	cache := newTestJWKSCache(t, server)
+
+	for i := 0; i < 3; i++ {
+		got, err := cache.GetKey(context.Background(), "k1")
+		if err != nil {
+			t.Fatalf("GetKey() error = %v", err)
+		}
+		if got.N.Cmp(key.N) != 0 || got.E != key.E {
+			t.Fatalf("GetKey() returned a different key")
+		}
+	}
+	if server.fetches != 1 {
+		t.Errorf("JWKS fetched %d times, want 1", server.fetches)
+	}
+}
+
+func TestJWKSCacheRefreshesOnRotation(t *testing.T) {
+	server := &jwksServer{keys: map[string]*rsa.PublicKey{"k1": newSigningKey(t)}}
+	cache := newTestJWKSCache(t, server)
+	if _, err := cache.GetKey(context.Background(), "k1"); err != nil {
+		t.Fatalf("GetKey(k1) error = %v", err)
+	}
+
+	// Keycloak rotates to a new key after the refresh interval has passed
+	server.keys = map[string]*rsa.PublicKey{"k2": newSigningKey(t)}
+	cache.lastRefresh = time.Now().Add(-2 * time.Minute)
+
+	if _, err := cache.GetKey(context.Background(), "k2"); err != nil {
+		t.Fatalf("GetKey(k2) after rotation error = %v", err)
+	}
+	if server.fetches != 2 {
+		t.Errorf("JWKS fetched %d times, want 2", server.fetches)
+	}
+}
+
+func TestJWKSCacheRateLimitsForgedKeyIDs(t *testing.T) {
+	server := &jwksServer{keys: map[string]*rsa.PublicKey{"k1": newSigningKey(t)}}
+	cache := newTestJWKSCache(t, server)
+	if _, err := cache.GetKey(context.Background(), "k1"); err != nil {
+		t.Fatalf("GetKey(k1) error = %v", err)
+	}
+	// Let the first forged kid force one refresh
+	cache.lastRefresh = time.Now().Add(-2 * time.Minute)
+
+	for i := 0; i < 10; i++ {
+		if _, err := cache.GetKey(context.Background(), "forged"); !errors.Is(err, ErrUnknownKeyID) {
+			t.Fatalf("GetKey(forged) error = %v, want ErrUnknownKeyID", err)
+		}
+	}
+	if server.fetches != 2 {
+		t.Errorf("JWKS fetched %d times, want 2: one load and one forced refresh", server.fetches)
+	}
+}
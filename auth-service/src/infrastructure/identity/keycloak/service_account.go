@@ -0,0 +1,89 @@
+package keycloak
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"auth-service/src/infrastructure/identity/models"
+	"backend-core/logging"
+)
+
+// serviceTokenRefreshMargin is how long before expiry the service token is renewed
+const serviceTokenRefreshMargin = 30 * time.Second
+
+// AuthenticateClientCredentials obtains a service account token with the client credentials
+// grant, using the configured client ID and secret, and stores it for admin operations
+func (c *KeycloakClient) AuthenticateClientCredentials(ctx context.Context) (*models.AuthResult, error) {
+	c.serviceMu.Lock()
+	defer c.serviceMu.Unlock()
+
+	return c.authenticateClientCredentials(ctx)
+}
+
+// GetServiceToken returns the service account access token, obtaining a new one when there
+// is none or it expires within serviceTokenRefreshMargin
+func (c *KeycloakClient) GetServiceToken(ctx context.Context) (string, error) {
+	c.serviceMu.Lock()
+	defer c.serviceMu.Unlock()
+
+	if c.serviceToken != "" && time.Until(c.serviceTokenExpiry) > serviceTokenRefreshMargin {
+		return c.serviceToken, nil
+	}
+
+	result, err := c.authenticateClientCredentials(ctx)
+	if err != nil {
+		return "", err
+	}
+	return result.AccessToken, nil
+}
+
+// authenticateClientCredentials performs the grant. Callers must hold serviceMu.
+func (c *KeycloakClient) authenticateClientCredentials(ctx context.Context) (*models.AuthResult, error) {
+	data := url.Values{}
+	data.Set("grant_type", "client_credentials")
+	data.Set("client_id", c.clientID)
+	data.Set("client_secret", c.clientSecret)
+
+	resp, err := c.postForm(ctx, c.config.OAuth.TokenURL, data)
+	if err != nil {
+		c.logger.Error("Keycloak client credentials grant failed", logging.Error(err))
+		return nil, fmt.Errorf("%w: %v", ErrAuthenticationFailed, err)
+	}
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int    `json:"expires_in"`
+		Scope       string `json:"scope"`
+	}
+	if err := json.Unmarshal(resp, &tokenResp); err != nil {
+		return nil, fmt.Errorf("failed to parse client credentials result: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return nil, fmt.Errorf("%w: no access token in client credentials response", ErrInvalidResponse)
+	}
+
+	now := time.Now()
+	c.serviceToken = tokenResp.AccessToken
+	c.serviceTokenExpiry = now.Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+
+	c.logger.Debug("Service account token obtained from Keycloak",
+		logging.Int("expires_in", tokenResp.ExpiresIn))
+
+	result := &models.AuthResult{
+		AccessToken: tokenResp.AccessToken,
+		TokenType:   tokenResp.TokenType,
+		ExpiresIn:   tokenResp.ExpiresIn,
+		Scope:       tokenResp.Scope,
+		IssuedAt:    now,
+	}
+	if claims, err := decodeAccessTokenClaims(tokenResp.AccessToken); err == nil {
+		result.UserID = claims.Subject
+		result.Username = claims.Username
+	}
+
+	return result, nil
+}
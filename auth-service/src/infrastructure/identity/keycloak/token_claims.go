@@ -0,0 +1,138 @@
+package keycloak
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// KeycloakTokenClaims holds the role claims Keycloak puts in its access tokens
+type KeycloakTokenClaims struct {
+	Subject        string                                 `json:"sub"`
+	Username       string                                 `json:"preferred_username"`
+	RealmAccess    KeycloakRealmAccess                    `json:"realm_access"`
+	ResourceAccess map[string]KeycloakResourceAccessRoles `json:"resource_access"`
+}
+
+// KeycloakResourceAccessRoles holds the roles granted for one client in the resource_access claim
+type KeycloakResourceAccessRoles struct {
+	Roles []string `json:"roles"`
+}
+
+// ClientRoles returns the roles granted for clientID
+func (c *KeycloakTokenClaims) ClientRoles(clientID string) []string {
+	if c == nil || c.ResourceAccess == nil {
+		return nil
+	}
+	return c.ResourceAccess[clientID].Roles
+}
+
+// decodeAccessTokenClaims reads the claims of a Keycloak access token.
+// The signature is not verified: the token was issued to this client by Keycloak,
+// and only role claims are read from it.
+func decodeAccessTokenClaims(token string) (*KeycloakTokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: access token is not a JWT", ErrInvalidToken)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode access token payload: %v", ErrInvalidToken, err)
+	}
+
+	var claims KeycloakTokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse access token claims: %v", ErrInvalidToken, err)
+	}
+
+	return &claims, nil
+}
+
+// mergeRoles appends the roles of extra not already in roles
+func mergeRoles(roles []string, extra ...[]string) []string {
+	seen := make(map[string]bool, len(roles))
+	merged := make([]string, 0, len(roles))
+	for _, role := range roles {
+		if !seen[role] {
+			seen[role] = true
+			merged = append(merged, role)
+		}
+	}
+	for _, list := range extra {
+		for _, role := range list {
+			if !seen[role] {
+				seen[role] = true
+				merged = append(merged, role)
+			}
+		}
+	}
+	return merged
+}
+
+// TokenIdentity is the identity carried by a verified Keycloak access token
+type TokenIdentity struct {
+	Subject     string
+	Username    string
+	Roles       []string
+	Permissions []string
+	Scopes      []string
+}
+
+// IdentityFromClaims reads the identity from verified access token claims. Roles are the realm
+// roles plus the roles granted for clientID, permissions are the "resource:scope" pairs of the
+// authorization claim Keycloak adds to RPTs, and scopes come from the space separated scope claim.
+func IdentityFromClaims(claims map[string]interface{}, clientID string) *TokenIdentity {
+	identity := &TokenIdentity{
+		Roles:       []string{},
+		Permissions: []string{},
+		Scopes:      []string{},
+	}
+	identity.Subject, _ = claims["sub"].(string)
+	identity.Username, _ = claims["preferred_username"].(string)
+
+	if realmAccess, ok := claims["realm_access"].(map[string]interface{}); ok {
+		identity.Roles = mergeRoles(identity.Roles, claimStrings(realmAccess["roles"]))
+	}
+	if resourceAccess, ok := claims["resource_access"].(map[string]interface{}); ok {
+		if clientAccess, ok := resourceAccess[clientID].(map[string]interface{}); ok {
+			identity.Roles = mergeRoles(identity.Roles, claimStrings(clientAccess["roles"]))
+		}
+	}
+
+	if authz, ok := claims["authorization"].(map[string]interface{}); ok {
+		permissions, _ := authz["permissions"].([]interface{})
+		for _, p := range permissions {
+			permission, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			resource, _ := permission["rsname"].(string)
+			for _, scope := range claimStrings(permission["scopes"]) {
+				identity.Permissions = append(identity.Permissions, resource+":"+scope)
+			}
+		}
+	}
+
+	if scope, ok := claims["scope"].(string); ok {
+		identity.Scopes = strings.Fields(scope)
+	}
+
+	return identity
+}
+
+// claimStrings returns the strings of a JSON array claim
+func claimStrings(value interface{}) []string {
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -0,0 +1,144 @@
+package keycloak
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"auth-service/src/infrastructure/identity/models"
+	"backend-core/logging"
+)
+
+const (
+	// defaultTokenRefreshWindow is used when TokenRefreshWindow is not set
+	defaultTokenRefreshWindow = time.Minute
+	// defaultTokenRefreshInterval is used when TokenRefreshInterval is not set
+	defaultTokenRefreshInterval = 30 * time.Second
+)
+
+// StartTokenRefresher starts a background goroutine that periodically refreshes cached auth
+// results whose access token expires within TokenRefreshWindow, so callers served from the
+// cache keep getting a valid token. It stops when ctx is done or StopTokenRefresher is called.
+// Calling it while the refresher is running has no effect.
+func (a *KeycloakAdapter) StartTokenRefresher(ctx context.Context) {
+	a.refresherMu.Lock()
+	defer a.refresherMu.Unlock()
+
+	if a.stopChan != nil {
+		return
+	}
+	a.stopChan = make(chan struct{})
+
+	interval := a.config.TokenRefreshInterval
+	if interval <= 0 {
+		interval = defaultTokenRefreshInterval
+	}
+
+	a.refresherWg.Add(1)
+	go a.runTokenRefresher(ctx, interval, a.stopChan)
+
+	a.logger.Info("Keycloak token refresher started",
+		logging.Duration("interval", interval),
+		logging.Duration("window", a.tokenRefreshWindow()))
+}
+
+// StopTokenRefresher stops the token refresher and waits for an in-flight scan to finish
+func (a *KeycloakAdapter) StopTokenRefresher() {
+	a.refresherMu.Lock()
+	defer a.refresherMu.Unlock()
+
+	if a.stopChan == nil {
+		return
+	}
+	close(a.stopChan)
+	a.refresherWg.Wait()
+	a.stopChan = nil
+
+	a.logger.Info("Keycloak token refresher stopped")
+}
+
+// runTokenRefresher scans the cached auth results every interval until stopped
+func (a *KeycloakAdapter) runTokenRefresher(ctx context.Context, interval time.Duration, stop <-chan struct{}) {
+	defer a.refresherWg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			a.refreshExpiringTokens(ctx)
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// refreshExpiringTokens refreshes every tracked auth result that expires within the refresh
+// window. Usernames whose auth result can no longer be read from the cache stop being tracked.
+func (a *KeycloakAdapter) refreshExpiringTokens(ctx context.Context) {
+	window := a.tokenRefreshWindow()
+
+	a.authUsers.Range(func(key, _ interface{}) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+
+		username := key.(string)
+		cacheKey := a.cacheKeys.auth(username)
+
+		var cached models.AuthResult
+		if err := a.getFromCache(ctx, cacheKey, &cached); err != nil {
+			// A slow cache says nothing about the entry, so keep tracking it for the next scan
+			if !errors.Is(err, errCacheTimeout) && ctx.Err() == nil {
+				a.authUsers.Delete(username)
+			}
+			return true
+		}
+
+		expiresAt := cached.IssuedAt.Add(time.Duration(cached.ExpiresIn) * time.Second)
+		if cached.RefreshToken == "" || time.Until(expiresAt) > window {
+			return true
+		}
+
+		if err := a.refreshCachedAuth(ctx, cacheKey, &cached); err != nil {
+			a.logger.Warn("Failed to refresh cached Keycloak token",
+				logging.Error(err),
+				logging.String("username", username))
+		}
+		return true
+	})
+}
+
+// refreshCachedAuth refreshes the tokens of a cached auth result and writes it back to the cache
+func (a *KeycloakAdapter) refreshCachedAuth(ctx context.Context, cacheKey string, cached *models.AuthResult) error {
+	tokens, err := a.RefreshToken(ctx, cached.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	cached.AccessToken = tokens.AccessToken
+	if tokens.RefreshToken != "" {
+		cached.RefreshToken = tokens.RefreshToken
+	}
+	if tokens.TokenType != "" {
+		cached.TokenType = tokens.TokenType
+	}
+	if tokens.Scope != "" {
+		cached.Scope = tokens.Scope
+	}
+	cached.ExpiresIn = tokens.ExpiresIn
+	cached.IssuedAt = time.Now()
+
+	return a.setCache(ctx, cacheKey, cached, a.config.CacheTTL)
+}
+
+// tokenRefreshWindow returns how close to expiry a cached token must be to get refreshed
+func (a *KeycloakAdapter) tokenRefreshWindow() time.Duration {
+	if a.config.TokenRefreshWindow > 0 {
+		return a.config.TokenRefreshWindow
+	}
+	return defaultTokenRefreshWindow
+}
@@ -0,0 +1,80 @@
+package keycloak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"backend-core/logging"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// issuer returns the issuer Keycloak puts in tokens for the configured realm
+func (c *KeycloakClient) issuer() string {
+	return c.baseURL + "/realms/" + c.realm
+}
+
+// VerifyToken validates an access token locally against the realm's JWKS: the RS256
+// signature, exp/nbf/iat, the issuer, and that the token was issued for the configured
+// client. Only when the token's kid is not in the JWKS, even after a refresh, does it fall
+// back to the introspection endpoint. It returns the token's claims.
+func (c *KeycloakClient) VerifyToken(ctx context.Context, token string) (map[string]interface{}, error) {
+	parser := jwt.Parser{ValidMethods: []string{jwt.SigningMethodRS256.Alg()}}
+
+	claims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" {
+			return nil, fmt.Errorf("%w: token has no kid", ErrInvalidToken)
+		}
+		return c.jwks.GetKey(ctx, kid)
+	})
+	if errors.Is(err, ErrUnknownKeyID) {
+		c.logger.Debug("Token kid not in JWKS, falling back to introspection", logging.Error(err))
+		return c.introspectClaims(ctx, token)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if err := c.verifyIssuedFor(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// verifyIssuedFor checks the issuer and that the configured client is the token's
+// audience or authorized party. Keycloak access tokens often carry aud "account" and
+// name the requesting client only in azp.
+func (c *KeycloakClient) verifyIssuedFor(claims jwt.MapClaims) error {
+	if !claims.VerifyIssuer(c.issuer(), true) {
+		return fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+
+	azp, _ := claims["azp"].(string)
+	if !claims.VerifyAudience(c.clientID, true) && azp != c.clientID {
+		return fmt.Errorf("%w: token not issued for client %s", ErrInvalidToken, c.clientID)
+	}
+
+	return nil
+}
+
+// introspectClaims validates token with the introspection endpoint and returns its claims
+func (c *KeycloakClient) introspectClaims(ctx context.Context, token string) (map[string]interface{}, error) {
+	info, err := c.ValidateToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Active {
+		return nil, fmt.Errorf("%w: token is not active", ErrInvalidToken)
+	}
+
+	claims := jwt.MapClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(token, claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	return claims, nil
+}
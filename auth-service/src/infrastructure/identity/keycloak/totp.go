@@ -0,0 +1,220 @@
+package keycloak
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"backend-core/logging"
+)
+
+// RFC 6238 parameters, the defaults every authenticator app supports
+const (
+	totpPeriod      = 30 * time.Second
+	totpDigits      = 6
+	totpModulus     = 1000000 // 10^totpDigits
+	totpSecretBytes = 20
+
+	// defaultTOTPSkew is used when MFATOTPSkew is not set
+	defaultTOTPSkew = 1
+
+	// totpSecretAttribute is the Keycloak user attribute holding the TOTP secret
+	totpSecretAttribute = "totp_secret"
+)
+
+// totpEncoding is the base32 form authenticator apps expect secrets in
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// EnrollTOTP creates a new TOTP secret for userID and returns it together with an
+// otpauth:// URL for authenticator apps. The secret is stored in the user's Keycloak
+// attributes, so it survives cache evictions and restarts; the cache only holds a copy.
+// A user who is already enrolled gets ErrMFAAlreadyEnrolled unless replace is set, since the
+// new secret invalidates the authenticator the user has set up.
+func (a *KeycloakAdapter) EnrollTOTP(ctx context.Context, userID string, replace bool) (secret string, otpauthURL string, err error) {
+	if !a.config.EnableMFA {
+		return "", "", fmt.Errorf("MFA is not enabled")
+	}
+	if userID == "" {
+		return "", "", ErrInvalidUserID
+	}
+
+	if !replace {
+		existing, err := a.totpSecret(ctx, userID)
+		if err != nil {
+			return "", "", err
+		}
+		if existing != "" {
+			return "", "", ErrMFAAlreadyEnrolled
+		}
+	}
+
+	raw := make([]byte, totpSecretBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+	secret = totpEncoding.EncodeToString(raw)
+
+	if err := a.client.SetUserAttribute(ctx, userID, totpSecretAttribute, secret); err != nil {
+		a.logger.Error("Failed to store TOTP secret",
+			logging.Error(err),
+			logging.String("user_id", userID))
+		return "", "", fmt.Errorf("failed to store TOTP secret: %w", err)
+	}
+
+	// A cached copy of a replaced secret would keep the old authenticator working
+	if err := a.setCache(ctx, a.cacheKeys.totpSecret(userID), secret, a.config.CacheTTL); err != nil {
+		a.logger.Warn("Failed to cache TOTP secret", logging.Error(err))
+		if a.cache != nil {
+			_ = a.withCacheTimeout(ctx, "delete", a.cacheKeys.totpSecret(userID), func(ctx context.Context) error {
+				return a.cache.Delete(ctx, a.cacheKeys.totpSecret(userID))
+			})
+		}
+	}
+
+	a.logger.Info("TOTP enrolled",
+		logging.String("user_id", userID),
+		logging.Bool("replaced", replace))
+
+	return secret, a.totpURL(userID, secret), nil
+}
+
+// totpSecret returns the TOTP secret of userID, or "" when the user is not enrolled. It reads
+// the cached copy first and falls back to the user's Keycloak attributes.
+func (a *KeycloakAdapter) totpSecret(ctx context.Context, userID string) (string, error) {
+	cacheKey := a.cacheKeys.totpSecret(userID)
+	var secret string
+	if err := a.getFromCache(ctx, cacheKey, &secret); err == nil && secret != "" {
+		return secret, nil
+	}
+
+	secret, err := a.client.GetUserAttribute(ctx, userID, totpSecretAttribute)
+	if err != nil {
+		return "", fmt.Errorf("failed to read TOTP secret: %w", err)
+	}
+	if secret != "" {
+		if err := a.setCache(ctx, cacheKey, secret, a.config.CacheTTL); err != nil {
+			a.logger.Debug("Failed to cache TOTP secret", logging.Error(err))
+		}
+	}
+	return secret, nil
+}
+
+// verifyTOTP checks code against the user's TOTP secret, accepting the steps within MFATOTPSkew
+// of the current one. A code is accepted once per step; reusing it returns ErrMFAFailed.
+func (a *KeycloakAdapter) verifyTOTP(ctx context.Context, userID, code string) error {
+	secret, err := a.totpSecret(ctx, userID)
+	if err != nil || secret == "" {
+		a.logger.Warn("TOTP secret not found",
+			logging.Error(err),
+			logging.String("user_id", userID))
+		return ErrMFAFailed
+	}
+
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		a.logger.Error("Stored TOTP secret is invalid",
+			logging.Error(err),
+			logging.String("user_id", userID))
+		return ErrMFAFailed
+	}
+
+	skew := a.totpSkew()
+	step, ok := matchTOTP(key, code, time.Now(), skew)
+	if !ok {
+		return ErrMFAFailed
+	}
+
+	// Increment is atomic, so of two requests racing with the same code only one sees 1
+	usedKey := a.cacheKeys.totpUsed(userID, step)
+	var uses int64
+	err = a.withCacheTimeout(ctx, "increment", usedKey, func(ctx context.Context) error {
+		var err error
+		uses, err = a.cache.Increment(ctx, usedKey)
+		return err
+	})
+	if err != nil {
+		a.logger.Error("Failed to record TOTP use", logging.Error(err))
+		return ErrMFAFailed
+	}
+	if uses > 1 {
+		a.logger.Warn("TOTP code replayed", logging.String("user_id", userID))
+		return ErrMFAFailed
+	}
+
+	// The step can no longer be matched once the skew window has passed it
+	ttl := time.Duration(2*skew+1) * totpPeriod
+	if err := a.withCacheTimeout(ctx, "expire", usedKey, func(ctx context.Context) error {
+		return a.cache.Expire(ctx, usedKey, ttl)
+	}); err != nil {
+		a.logger.Warn("Failed to set TOTP replay marker expiry", logging.Error(err))
+	}
+
+	return nil
+}
+
+// totpSkew returns how many steps either side of the current one are accepted. An unset or
+// zero skew uses defaultTOTPSkew, since without any tolerance the slightest clock drift
+// between server and authenticator rejects valid codes.
+func (a *KeycloakAdapter) totpSkew() int {
+	if a.config.MFATOTPSkew <= 0 {
+		return defaultTOTPSkew
+	}
+	return a.config.MFATOTPSkew
+}
+
+// totpURL builds the otpauth:// URL authenticator apps enroll from
+func (a *KeycloakAdapter) totpURL(userID, secret string) string {
+	issuer := a.config.Realm
+	if issuer == "" {
+		issuer = "auth-service"
+	}
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprintf("%d", totpDigits))
+	query.Set("period", fmt.Sprintf("%d", int(totpPeriod.Seconds())))
+
+	label := url.PathEscape(issuer + ":" + userID)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// matchTOTP returns the time step code is valid for, looking skew steps either side of now
+func matchTOTP(key []byte, code string, now time.Time, skew int) (uint64, bool) {
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := uint64(now.Unix()) / uint64(totpPeriod.Seconds())
+	for offset := -skew; offset <= skew; offset++ {
+		step := current + uint64(int64(offset))
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// totpCode computes the RFC 4226 HOTP value of key for counter
+func totpCode(key []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%totpModulus)
+}
@@ -0,0 +1,135 @@
+package keycloak
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"backend-core/logging"
+)
+
+// Increment counts in the JSON value of key, like Redis INCR
+func (m *mapCache) Increment(ctx context.Context, key string) (int64, error) {
+	count, _ := strconv.ParseInt(string(m.values[key]), 10, 64)
+	count++
+	m.values[key] = []byte(strconv.FormatInt(count, 10))
+	return count, nil
+}
+
+func (m *mapCache) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	return nil
+}
+
+func newTOTPAdapter(t *testing.T) (*KeycloakAdapter, *mapCache) {
+	t.Helper()
+
+	server := newFakeKeycloak(t)
+	logger := logging.NewNopLogger()
+	keycloakConfig := KeycloakConfig{
+		BaseURL:      server.URL,
+		Realm:        "test",
+		ClientID:     "auth-service",
+		ClientSecret: "secret",
+		CacheTTL:     time.Minute,
+		EnableMFA:    true,
+	}
+	client, err := NewKeycloakClient(keycloakConfig, logger)
+	if err != nil {
+		t.Fatalf("NewKeycloakClient() error = %v", err)
+	}
+	store := &mapCache{values: make(map[string][]byte)}
+	return NewKeycloakAdapter(client, store, keycloakConfig, logger), store
+}
+
+// verifyCurrentCode runs an MFA challenge for user-1 answered with the current code of secret
+func verifyCurrentCode(t *testing.T, adapter *KeycloakAdapter, secret string) error {
+	t.Helper()
+
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		t.Fatalf("invalid secret %q: %v", secret, err)
+	}
+	challenge, err := adapter.InitiateMFA(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("InitiateMFA() error = %v", err)
+	}
+	step := uint64(time.Now().Unix()) / uint64(totpPeriod.Seconds())
+	_, err = adapter.VerifyMFA(context.Background(), challenge.ChallengeID, totpCode(key, step))
+	return err
+}
+
+func TestEnrollTOTPPersistsSecretInKeycloak(t *testing.T) {
+	adapter, store := newTOTPAdapter(t)
+	ctx := context.Background()
+
+	secret, otpauthURL, err := adapter.EnrollTOTP(ctx, "user-1", false)
+	if err != nil {
+		t.Fatalf("EnrollTOTP() error = %v", err)
+	}
+	if !strings.Contains(otpauthURL, "secret="+secret) {
+		t.Errorf("otpauth URL %q does not carry the secret", otpauthURL)
+	}
+
+	stored, err := adapter.client.GetUserAttribute(ctx, "user-1", totpSecretAttribute)
+	if err != nil || stored != secret {
+		t.Fatalf("Keycloak attribute = %q, %v, want the enrolled secret", stored, err)
+	}
+	if locale, _ := adapter.client.GetUserAttribute(ctx, "user-1", "locale"); locale != "en" {
+		t.Errorf("other attribute locale = %q after enrollment, want it kept", locale)
+	}
+
+	// Losing the cache, e.g. to an eviction or a flush, does not lose the enrollment
+	store.values = make(map[string][]byte)
+	if err := verifyCurrentCode(t, adapter, secret); err != nil {
+		t.Errorf("VerifyMFA() after the cache was flushed error = %v", err)
+	}
+}
+
+func TestEnrollTOTPRefusesReenrollmentUnlessReplacing(t *testing.T) {
+	adapter, store := newTOTPAdapter(t)
+	ctx := context.Background()
+
+	first, _, err := adapter.EnrollTOTP(ctx, "user-1", false)
+	if err != nil {
+		t.Fatalf("EnrollTOTP() error = %v", err)
+	}
+
+	store.values = make(map[string][]byte)
+	if _, _, err := adapter.EnrollTOTP(ctx, "user-1", false); !errors.Is(err, ErrMFAAlreadyEnrolled) {
+		t.Fatalf("second EnrollTOTP() error = %v, want %v", err, ErrMFAAlreadyEnrolled)
+	}
+	if err := verifyCurrentCode(t, adapter, first); err != nil {
+		t.Errorf("refused re-enrollment broke the enrolled authenticator: %v", err)
+	}
+
+	second, _, err := adapter.EnrollTOTP(ctx, "user-1", true)
+	if err != nil {
+		t.Fatalf("EnrollTOTP() replacing error = %v", err)
+	}
+	if second == first {
+		t.Fatal("replacing enrollment kept the old secret")
+	}
+	if err := verifyCurrentCode(t, adapter, first); !errors.Is(err, ErrMFAFailed) {
+		t.Errorf("VerifyMFA() with the replaced secret error = %v, want %v", err, ErrMFAFailed)
+	}
+}
+
+func TestTOTPSkewDefaultsWhenUnset(t *testing.T) {
+	tests := []struct {
+		configured int
+		want       int
+	}{
+		{configured: 0, want: defaultTOTPSkew},
+		{configured: -1, want: defaultTOTPSkew},
+		{configured: 2, want: 2},
+	}
+	for _, tt := range tests {
+		adapter := NewKeycloakAdapter(nil, nil, KeycloakConfig{MFATOTPSkew: tt.configured}, logging.NewNopLogger())
+		if got := adapter.totpSkew(); got != tt.want {
+			t.Errorf("totpSkew() with MFATOTPSkew %d = %d, want %d", tt.configured, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package keycloak
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// GetUserAttribute returns the first value of the user attribute name from the Admin API,
+// or "" when the user does not have it
+func (c *KeycloakClient) GetUserAttribute(ctx context.Context, userID, name string) (string, error) {
+	user, err := c.getUserRepresentation(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	values, err := userAttributes(user)
+	if err != nil {
+		return "", err
+	}
+	if len(values[name]) == 0 {
+		return "", nil
+	}
+	return values[name][0], nil
+}
+
+// SetUserAttribute sets the user attribute name to value through the Admin API, keeping the
+// user's other attributes. Keycloak replaces the whole attribute map on update, so the user
+// is read first and written back with the one attribute changed. On Keycloak 24 and later the
+// realm's user profile must allow the attribute, e.g. by enabling unmanaged attributes.
+func (c *KeycloakClient) SetUserAttribute(ctx context.Context, userID, name, value string) error {
+	user, err := c.getUserRepresentation(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	values, err := userAttributes(user)
+	if err != nil {
+		return err
+	}
+	values[name] = []string{value}
+	user["attributes"] = values
+
+	if err := c.put(ctx, c.userURL(userID), user); err != nil {
+		return fmt.Errorf("failed to update user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// userURL returns the Admin API URL of userID
+func (c *KeycloakClient) userURL(userID string) string {
+	return c.adminRealmURL() + "/users/" + url.PathEscape(userID)
+}
+
+// getUserRepresentation reads userID from the Admin API as a generic representation, so
+// fields this client does not model survive being written back
+func (c *KeycloakClient) getUserRepresentation(ctx context.Context, userID string) (map[string]interface{}, error) {
+	body, err := c.get(ctx, c.userURL(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user %s: %w", userID, err)
+	}
+
+	var user map[string]interface{}
+	if err := json.Unmarshal(body, &user); err != nil {
+		return nil, fmt.Errorf("failed to parse user %s: %w", userID, err)
+	}
+	return user, nil
+}
+
+// userAttributes returns the attributes of a user representation
+func userAttributes(user map[string]interface{}) (map[string][]string, error) {
+	values := make(map[string][]string)
+	raw, ok := user["attributes"]
+	if !ok || raw == nil {
+		return values, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse user attributes: %w", err)
+	}
+	return values, nil
+}
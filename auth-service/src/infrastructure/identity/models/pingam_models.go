@@ -126,6 +126,7 @@ type RiskAssessment struct {
 // MFAChallenge represents MFA challenge information
 type MFAChallenge struct {
 	ChallengeID string    `json:"challenge_id"`
+	UserID      string    `json:"user_id,omitempty"`
 	Methods     []string  `json:"methods"`
 	ExpiresAt   time.Time `json:"expires_at"`
 }
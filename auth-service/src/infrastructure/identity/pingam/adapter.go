@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"auth-service/src/domain/authorization"
 	"auth-service/src/infrastructure/identity/models"
 	"backend-core/cache"
 	"backend-core/logging"
@@ -169,6 +170,56 @@ func (a *PingAMAdapter) CheckPermission(ctx context.Context, userID, resource, a
 	return result.Allowed, nil
 }
 
+// CheckPermissions checks several permissions of a user at once. Each permission without a
+// cached result is decided by PingAM's authorize endpoint, like CheckPermission. Results are
+// cached under their own batch-permission: prefix and keyed by
+// authorization.PermissionKey(resource, action).
+func (a *PingAMAdapter) CheckPermissions(ctx context.Context, userID string, perms []authorization.Permission) (map[string]bool, error) {
+	results := make(map[string]bool, len(perms))
+
+	misses := 0
+	for _, perm := range perms {
+		key := authorization.PermissionKey(perm.Resource, perm.Action)
+		if _, done := results[key]; done {
+			continue
+		}
+
+		cacheKey := fmt.Sprintf("batch-permission:%s:%s:%s", userID, perm.Resource, perm.Action)
+		var cached bool
+		if err := a.getFromCache(ctx, cacheKey, &cached); err == nil {
+			results[key] = cached
+			continue
+		}
+		misses++
+
+		result, err := a.client.CheckPermissions(ctx, userID, perm.Resource, perm.Action)
+		if err != nil {
+			a.logger.Error("Batch permission check failed",
+				logging.Error(err),
+				logging.String("user_id", userID),
+				logging.String("resource", perm.Resource),
+				logging.String("action", perm.Action))
+			return nil, err
+		}
+		results[key] = result.Allowed
+
+		if err := a.setCache(ctx, cacheKey, result.Allowed, a.config.CacheTTL); err != nil {
+			a.logger.Warn("Failed to cache permission result",
+				logging.Error(err),
+				logging.String("user_id", userID),
+				logging.String("resource", perm.Resource),
+				logging.String("action", perm.Action))
+		}
+	}
+
+	a.logger.Debug("Permissions checked successfully",
+		logging.String("user_id", userID),
+		logging.Int("permission_count", len(perms)),
+		logging.Int("cache_misses", misses))
+
+	return results, nil
+}
+
 // GetUserRoles retrieves user roles from PingAM
 func (a *PingAMAdapter) GetUserRoles(ctx context.Context, userID string) ([]string, error) {
 	// Check cache first
@@ -312,6 +363,7 @@ func (a *PingAMAdapter) clearTokenCache(ctx context.Context, token string) {
 		"profile:*",
 		"roles:*",
 		"permissions:*",
+		"batch-permission:*",
 	}
 
 	for _, pattern := range patterns {
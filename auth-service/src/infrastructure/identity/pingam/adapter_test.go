@@ -0,0 +1,101 @@
+package pingam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"auth-service/src/domain/authorization"
+	"backend-core/cache"
+	"backend-core/logging"
+)
+
+// mapCache is a cache.Cache holding JSON values in memory
+type mapCache struct {
+	cache.Cache
+	values map[string][]byte
+}
+
+func (m *mapCache) Get(ctx context.Context, key string, dest interface{}) error {
+	data, ok := m.values[key]
+	if !ok {
+		return cache.ErrCacheMiss
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (m *mapCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	m.values[key] = data
+	return nil
+}
+
+func TestCheckPermissionsDelegatesToAuthorizeEndpoint(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/authorize" {
+			http.NotFound(w, r)
+			return
+		}
+		calls.Add(1)
+		var req struct {
+			Resource string `json:"resource"`
+			Action   string `json:"action"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(map[string]interface{}{"allowed": req.Resource == "users" && req.Action == "read"})
+	}))
+	defer server.Close()
+
+	logger := logging.NewNopLogger()
+	pingamConfig := PingAMConfig{BaseURL: server.URL, Timeout: time.Second, CacheTTL: time.Minute}
+	store := &mapCache{values: map[string][]byte{
+		// A CheckPermission result must not be read by the batch check
+		"permission:user-1:users:write": []byte("true"),
+	}}
+	adapter := NewPingAMAdapter(NewPingAMClient(pingamConfig, logger), store, pingamConfig, logger)
+
+	perms := []authorization.Permission{{Resource: "users", Action: "read"}, {Resource: "users", Action: "write"}}
+	results, err := adapter.CheckPermissions(context.Background(), "user-1", perms)
+	if err != nil {
+		t.Fatalf("CheckPermissions() error = %v", err)
+	}
+
+	if !results[authorization.PermissionKey("users", "read")] || results[authorization.PermissionKey("users", "write")] {
+		t.Errorf("CheckPermissions() = %v, want only users:read allowed", results)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("authorize endpoint called %d times, want 2", got)
+	}
+
+	var keys []string
+	for key := range store.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	want := []string{"batch-permission:user-1:users:read", "batch-permission:user-1:users:write", "permission:user-1:users:write"}
+	if len(keys) != len(want) {
+		t.Fatalf("cache keys = %v, want %v", keys, want)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Fatalf("cache keys = %v, want %v", keys, want)
+		}
+	}
+
+	// Cached batch results are reused
+	if _, err := adapter.CheckPermissions(context.Background(), "user-1", perms); err != nil {
+		t.Fatalf("CheckPermissions() error = %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("authorize endpoint called %d times after a cached check, want 2", got)
+	}
+}
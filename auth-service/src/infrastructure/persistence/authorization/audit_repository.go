@@ -0,0 +1,87 @@
+package authorization
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"auth-service/src/domain/authorization"
+	"backend-core/logging"
+
+	gormio "gorm.io/gorm"
+)
+
+// auditInsertBatchSize bounds how many decisions go into one INSERT
+const auditInsertBatchSize = 100
+
+// authorizationAuditRecord is the authorization_audit_log row of a decision
+type authorizationAuditRecord struct {
+	UserID        string    `gorm:"column:user_id"`
+	CheckType     string    `gorm:"column:check_type"`
+	Resource      string    `gorm:"column:resource"`
+	Action        string    `gorm:"column:action"`
+	Role          string    `gorm:"column:role"`
+	Mode          string    `gorm:"column:mode"`
+	Decision      string    `gorm:"column:decision"`
+	StatusCode    int       `gorm:"column:status_code"`
+	RequestID     string    `gorm:"column:request_id"`
+	CorrelationID string    `gorm:"column:correlation_id"`
+	Method        string    `gorm:"column:method"`
+	Path          string    `gorm:"column:path"`
+	DecidedAt     time.Time `gorm:"column:decided_at"`
+}
+
+// TableName returns the audit table name
+func (authorizationAuditRecord) TableName() string {
+	return "authorization_audit_log"
+}
+
+// authorizationAuditRepository implements authorization.AuthorizationAuditRepository.
+// It only ever inserts; the table rejects updates and deletes.
+type authorizationAuditRepository struct {
+	db     *gormio.DB
+	logger *logging.Logger
+}
+
+// NewAuthorizationAuditRepository creates a new authorization audit repository
+func NewAuthorizationAuditRepository(database interface{}, logger *logging.Logger) (authorization.AuthorizationAuditRepository, error) {
+	db, err := extractGormDB(database, logger)
+	if err != nil {
+		return nil, err
+	}
+	return &authorizationAuditRepository{
+		db:     db,
+		logger: logger,
+	}, nil
+}
+
+// SaveDecisions inserts decisions into the audit table
+func (r *authorizationAuditRepository) SaveDecisions(ctx context.Context, decisions []*authorization.AuthorizationDecision) error {
+	if len(decisions) == 0 {
+		return nil
+	}
+
+	records := make([]authorizationAuditRecord, 0, len(decisions))
+	for _, d := range decisions {
+		records = append(records, authorizationAuditRecord{
+			UserID:        d.UserID,
+			CheckType:     string(d.Check),
+			Resource:      d.Resource,
+			Action:        d.Action,
+			Role:          d.Role,
+			Mode:          d.Mode,
+			Decision:      string(d.Decision),
+			StatusCode:    d.StatusCode,
+			RequestID:     d.RequestID,
+			CorrelationID: d.CorrelationID,
+			Method:        d.Method,
+			Path:          d.Path,
+			DecidedAt:     d.Timestamp,
+		})
+	}
+
+	if err := r.db.WithContext(ctx).CreateInBatches(records, auditInsertBatchSize).Error; err != nil {
+		return fmt.Errorf("failed to save %d authorization decisions: %w", len(records), err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package authorization
+
+import (
+	"fmt"
+
+	"auth-service/src/domain/authorization"
+
+	"github.com/google/uuid"
+)
+
+// permissionCacheKeys builds the cache keys used by the permission repository.
+// Read and invalidation paths must both go through it so the keys cannot drift.
+type permissionCacheKeys struct{}
+
+// byID returns the cache key for a permission looked up by ID
+func (permissionCacheKeys) byID(id uuid.UUID) string {
+	return fmt.Sprintf("permission:id:%s", id.String())
+}
+
+// byName returns the cache key for a permission looked up by name
+func (permissionCacheKeys) byName(name string) string {
+	return fmt.Sprintf("permission:name:%s", name)
+}
+
+// byResourceAndAction returns the cache key for a permission looked up by resource and action
+func (permissionCacheKeys) byResourceAndAction(resource, action string) string {
+	return fmt.Sprintf("permission:resource_action:%s:%s", resource, action)
+}
+
+// active returns the cache key for the active permissions list
+func (permissionCacheKeys) active() string {
+	return "permission:active"
+}
+
+// userPermissions returns the cache key for a user's effective permissions
+func (permissionCacheKeys) userPermissions(userID uuid.UUID) string {
+	return fmt.Sprintf("permission:user:%s", userID.String())
+}
+
+// allUserPermissions returns the pattern matching every user's effective permissions
+func (permissionCacheKeys) allUserPermissions() string {
+	return "permission:user:*"
+}
+
+// rolePermissions returns the cache key for a role's active permissions
+func (permissionCacheKeys) rolePermissions(roleID uuid.UUID) string {
+	return fmt.Sprintf("permission:role:%s", roleID.String())
+}
+
+// allRolePermissions returns the pattern matching every role's permission list
+func (permissionCacheKeys) allRolePermissions() string {
+	return "permission:role:*"
+}
+
+// forPermission returns every cache key that may hold the given permission
+func (k permissionCacheKeys) forPermission(permission *authorization.Permission) []string {
+	return []string{
+		k.byID(permission.GetUUID()),
+		k.byName(permission.Name),
+		k.byResourceAndAction(permission.Resource, permission.Action),
+		k.active(),
+	}
+}
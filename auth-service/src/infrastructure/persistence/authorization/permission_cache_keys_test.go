@@ -0,0 +1,90 @@
+package authorization
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"auth-service/src/domain/authorization"
+	"backend-core/cache"
+	"backend-core/logging"
+
+	"github.com/google/uuid"
+)
+
+// recordingCache is an in-memory cache.Cache recording the keys read and deleted
+type recordingCache struct {
+	cache.Cache
+	values   map[string][]byte
+	ttls     map[string]time.Duration
+	read     []string
+	deleted  []string
+	patterns []string
+}
+
+func newRecordingCache() *recordingCache {
+	return &recordingCache{values: make(map[string][]byte), ttls: make(map[string]time.Duration)}
+}
+
+func (c *recordingCache) Get(ctx context.Context, key string, dest interface{}) error {
+	c.read = append(c.read, key)
+	data, ok := c.values[key]
+	if !ok {
+		return cache.ErrCacheMiss
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (c *recordingCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.values[key] = data
+	c.ttls[key] = expiration
+	return nil
+}
+
+func (c *recordingCache) Delete(ctx context.Context, key string) error {
+	c.deleted = append(c.deleted, key)
+	delete(c.values, key)
+	return nil
+}
+
+func (c *recordingCache) DeletePattern(ctx context.Context, pattern string) error {
+	c.patterns = append(c.patterns, pattern)
+	return nil
+}
+
+func TestInvalidationDeletesTheKeysReadByLookups(t *testing.T) {
+	db, _ := newDryRunDB(t, "postgres")
+	store := newRecordingCache()
+	repo := &permissionRepository{db: db, logger: logging.NewNopLogger(), cacheMgr: cache.NewCacheManager(store)}
+	ctx := context.Background()
+
+	permission := &authorization.Permission{Name: "users:read", Resource: "users", Action: "read"}
+	permission.SetUUID(uuid.New())
+
+	repo.GetByID(ctx, permission.GetUUID())
+	repo.GetByName(ctx, permission.Name)
+	repo.GetByResourceAndAction(ctx, permission.Resource, permission.Action)
+	if len(store.read) != 3 {
+		t.Fatalf("lookups read %d cache keys, want 3: %v", len(store.read), store.read)
+	}
+
+	repo.invalidatePermissionCache(ctx, permission)
+
+	deleted := make(map[string]bool, len(store.deleted))
+	for _, key := range store.deleted {
+		deleted[key] = true
+	}
+	for _, key := range store.read {
+		if !deleted[key] {
+			t.Errorf("key %q read by a lookup was not invalidated, deleted %v", key, store.deleted)
+		}
+	}
+	if !deleted[repo.cacheKeys.active()] {
+		t.Errorf("active permissions list was not invalidated, deleted %v", store.deleted)
+	}
+}
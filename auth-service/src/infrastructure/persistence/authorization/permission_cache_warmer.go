@@ -0,0 +1,151 @@
+package authorization
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"auth-service/src/domain/authorization"
+	"backend-core/cache/redis/reload"
+	"backend-core/logging"
+
+	"github.com/google/uuid"
+)
+
+// ActiveUserSource supplies the users whose permissions are worth preloading
+type ActiveUserSource interface {
+	// ActiveUserIDs returns up to limit user IDs, most active first
+	ActiveUserIDs(ctx context.Context, limit int) ([]uuid.UUID, error)
+}
+
+// StaticUserSource is an ActiveUserSource backed by a fixed list, e.g. from configuration
+type StaticUserSource []uuid.UUID
+
+// ActiveUserIDs returns the first limit user IDs of the list
+func (s StaticUserSource) ActiveUserIDs(_ context.Context, limit int) ([]uuid.UUID, error) {
+	if limit > 0 && len(s) > limit {
+		return s[:limit], nil
+	}
+	return s, nil
+}
+
+// NewStaticUserSource parses configured user IDs into a StaticUserSource
+func NewStaticUserSource(userIDs []string) (StaticUserSource, error) {
+	source := make(StaticUserSource, 0, len(userIDs))
+	for _, raw := range userIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user ID %q: %w", raw, err)
+		}
+		source = append(source, id)
+	}
+	return source, nil
+}
+
+// PermissionCacheWarmer preloads users' effective permissions into the permission cache
+// so the first request after a deploy does not pay for resolving them.
+// It warms through a cache-backed PermissionRepository, so entries land under the
+// same keys and TTL that normal lookups use.
+type PermissionCacheWarmer struct {
+	repo     authorization.PermissionRepository
+	source   ActiveUserSource
+	maxUsers int
+	timeout  time.Duration
+	logger   *logging.Logger
+	warmed   atomic.Bool
+}
+
+var _ reload.CacheWarmer = (*PermissionCacheWarmer)(nil)
+
+// NewPermissionCacheWarmer creates a warmer for at most maxUsers users taking at most timeout.
+// A zero timeout means warming is bounded only by the caller's context.
+func NewPermissionCacheWarmer(repo authorization.PermissionRepository, source ActiveUserSource, maxUsers int, timeout time.Duration, logger *logging.Logger) *PermissionCacheWarmer {
+	return &PermissionCacheWarmer{
+		repo:     repo,
+		source:   source,
+		maxUsers: maxUsers,
+		timeout:  timeout,
+		logger:   logger,
+	}
+}
+
+// WarmUp preloads permissions for the users supplied by the source
+func (w *PermissionCacheWarmer) WarmUp(ctx context.Context) error {
+	ctx, cancel := w.withTimeout(ctx)
+	defer cancel()
+
+	userIDs, err := w.source.ActiveUserIDs(ctx, w.maxUsers)
+	if err != nil {
+		return fmt.Errorf("failed to load users to warm: %w", err)
+	}
+
+	return w.warmUsers(ctx, userIDs)
+}
+
+// WarmUpKeys preloads permissions for the given user IDs
+func (w *PermissionCacheWarmer) WarmUpKeys(ctx context.Context, keys []string) error {
+	ctx, cancel := w.withTimeout(ctx)
+	defer cancel()
+
+	userIDs := make([]uuid.UUID, 0, len(keys))
+	for _, key := range keys {
+		id, err := uuid.Parse(key)
+		if err != nil {
+			return fmt.Errorf("invalid user ID %q: %w", key, err)
+		}
+		userIDs = append(userIDs, id)
+	}
+
+	return w.warmUsers(ctx, userIDs)
+}
+
+// IsWarmedUp reports whether a warm-up has completed
+func (w *PermissionCacheWarmer) IsWarmedUp(_ context.Context) (bool, error) {
+	return w.warmed.Load(), nil
+}
+
+// warmUsers resolves each user's permissions, stopping early if ctx is done.
+// Individual failures are logged and skipped so one bad user does not block the rest.
+func (w *PermissionCacheWarmer) warmUsers(ctx context.Context, userIDs []uuid.UUID) error {
+	if w.maxUsers > 0 && len(userIDs) > w.maxUsers {
+		userIDs = userIDs[:w.maxUsers]
+	}
+
+	start := time.Now()
+	warmed, failed := 0, 0
+	for _, userID := range userIDs {
+		if err := ctx.Err(); err != nil {
+			w.logger.Warn("Permission cache warm-up interrupted",
+				logging.Int("warmed", warmed),
+				logging.Int("remaining", len(userIDs)-warmed-failed),
+				logging.Error(err))
+			return fmt.Errorf("permission cache warm-up interrupted: %w", err)
+		}
+
+		if _, err := w.repo.GetUserPermissions(ctx, userID); err != nil {
+			failed++
+			w.logger.Warn("Failed to warm user permissions",
+				logging.String("user_id", userID.String()),
+				logging.Error(err))
+			continue
+		}
+		warmed++
+	}
+
+	w.warmed.Store(true)
+	w.logger.Info("Permission cache warmed",
+		logging.Int("warmed", warmed),
+		logging.Int("failed", failed),
+		logging.Duration("duration", time.Since(start)))
+
+	return nil
+}
+
+// withTimeout applies the configured timeout to ctx
+func (w *PermissionCacheWarmer) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if w.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, w.timeout)
+}
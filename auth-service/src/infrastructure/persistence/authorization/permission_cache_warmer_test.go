@@ -0,0 +1,95 @@
+package authorization
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"auth-service/src/domain/authorization"
+	"backend-core/logging"
+
+	"github.com/google/uuid"
+	gormio "gorm.io/gorm"
+)
+
+// recordingPermissionRepository records the users whose permissions were resolved
+type recordingPermissionRepository struct {
+	authorization.PermissionRepository
+	resolved []uuid.UUID
+	// cancel, when set, is called after the first resolution
+	cancel context.CancelFunc
+}
+
+func (r *recordingPermissionRepository) GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]*authorization.Permission, error) {
+	r.resolved = append(r.resolved, userID)
+	if r.cancel != nil {
+		r.cancel()
+	}
+	return nil, nil
+}
+
+func TestWarmUpResolvesSeededUsersUpToMax(t *testing.T) {
+	repo, store, _ := newCachedPermissionRepository(t)
+	permission := &authorization.Permission{Name: "users:read", Resource: "users", Action: "read"}
+	permission.SetUUID(uuid.New())
+	// Hand every user's permission query the same permission instead of a database. The dry
+	// run cannot parse the entity ID of the scanned model, so that error is dropped as well.
+	if err := repo.db.Callback().Query().Replace("gorm:query", func(tx *gormio.DB) {
+		if dest, ok := tx.Statement.Dest.(*[]*authorization.Permission); ok {
+			*dest = []*authorization.Permission{permission}
+			tx.Error = nil
+		}
+	}); err != nil {
+		t.Fatalf("failed to replace query callback: %v", err)
+	}
+
+	seeded := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	warmer := NewPermissionCacheWarmer(repo, StaticUserSource(seeded), 2, time.Second, logging.NewNopLogger())
+
+	if err := warmer.WarmUp(context.Background()); err != nil {
+		t.Fatalf("WarmUp() error = %v", err)
+	}
+
+	for _, userID := range seeded[:2] {
+		key := repo.cacheKeys.userPermissions(userID)
+		var entries []permissionCacheEntry
+		if err := json.Unmarshal(store.values[key], &entries); err != nil {
+			t.Errorf("cache key %q was not warmed: %v", key, err)
+			continue
+		}
+		if len(entries) != 1 || entries[0].ID != permission.GetUUID().String() {
+			t.Errorf("cache key %q = %+v, want the user's permission %s", key, entries, permission.Name)
+		}
+	}
+	if key := repo.cacheKeys.userPermissions(seeded[2]); store.values[key] != nil {
+		t.Errorf("cache key %q was warmed beyond the maximum of 2 users", key)
+	}
+	if warmed, _ := warmer.IsWarmedUp(context.Background()); !warmed {
+		t.Error("IsWarmedUp() = false after a completed warm-up")
+	}
+}
+
+func TestWarmUpStopsWhenCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	repo := &recordingPermissionRepository{cancel: cancel}
+	source := StaticUserSource{uuid.New(), uuid.New(), uuid.New()}
+	warmer := NewPermissionCacheWarmer(repo, source, 0, 0, logging.NewNopLogger())
+
+	if err := warmer.WarmUp(ctx); err == nil {
+		t.Fatal("WarmUp() of a canceled context succeeded, want an error")
+	}
+	if len(repo.resolved) != 1 {
+		t.Errorf("resolved %d users, want 1 before the cancellation", len(repo.resolved))
+	}
+	if warmed, _ := warmer.IsWarmedUp(context.Background()); warmed {
+		t.Error("IsWarmedUp() = true after an interrupted warm-up")
+	}
+}
+
+func TestNewStaticUserSourceRejectsInvalidIDs(t *testing.T) {
+	if _, err := NewStaticUserSource([]string{uuid.NewString(), "not-a-uuid"}); err == nil {
+		t.Error("NewStaticUserSource() accepted an invalid user ID")
+	}
+}
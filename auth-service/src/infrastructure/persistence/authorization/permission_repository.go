@@ -2,100 +2,136 @@ package authorization
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"auth-service/src/domain/authorization"
 	"backend-core/cache"
+	"backend-core/database/observability"
 	"backend-core/logging"
 	"backend-core/telemetry"
 
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/trace"
 	gormio "gorm.io/gorm"
 )
 
+const (
+	// permissionCacheTTL is how long found permissions and permission lists stay cached
+	// when the repository is not given a TTL
+	permissionCacheTTL = 5 * time.Minute
+	// permissionNotFoundTTL is how long a not-found lookup stays cached
+	permissionNotFoundTTL = 30 * time.Second
+)
+
 // permissionRepository implements authorization.PermissionRepository
 type permissionRepository struct {
+	*observability.BaseRepository
 	db        *gormio.DB
 	logger    *logging.Logger
 	cacheMgr  *cache.CacheManager
-	telemetry *telemetry.Telemetry
+	cacheKeys permissionCacheKeys
+	cacheTTL  time.Duration
 }
 
 // NewPermissionRepository creates a new permission repository
-func NewPermissionRepository(database interface{}, logger *logging.Logger) authorization.PermissionRepository {
-	db := extractGormDB(database, logger)
-	if db == nil {
-		return nil
+func NewPermissionRepository(database interface{}, logger *logging.Logger) (authorization.PermissionRepository, error) {
+	db, err := extractGormDB(database, logger)
+	if err != nil {
+		return nil, err
 	}
 	return &permissionRepository{
-		db:     db,
-		logger: logger,
-	}
+		BaseRepository: observability.NewBaseRepository("permissions", logger, nil),
+		db:             db,
+		logger:         logger,
+	}, nil
 }
 
 // NewPermissionRepositoryWithCache creates a new permission repository with caching enabled
-func NewPermissionRepositoryWithCache(database interface{}, logger *logging.Logger, cacheMgr *cache.CacheManager) authorization.PermissionRepository {
-	db := extractGormDB(database, logger)
-	if db == nil {
-		return nil
+func NewPermissionRepositoryWithCache(database interface{}, logger *logging.Logger, cacheMgr *cache.CacheManager) (authorization.PermissionRepository, error) {
+	db, err := extractGormDB(database, logger)
+	if err != nil {
+		return nil, err
 	}
 	return &permissionRepository{
-		db:       db,
-		logger:   logger,
-		cacheMgr: cacheMgr,
+		BaseRepository: observability.NewBaseRepository("permissions", logger, nil),
+		db:             db,
+		logger:         logger,
+		cacheMgr:       cacheMgr,
+	}, nil
+}
+
+// NewPermissionRepositoryWithCacheTTL creates a new permission repository with caching enabled,
+// keeping cached permissions and permission lists for ttl. A ttl of zero uses the default.
+func NewPermissionRepositoryWithCacheTTL(database interface{}, logger *logging.Logger, cacheMgr *cache.CacheManager, ttl time.Duration) (authorization.PermissionRepository, error) {
+	db, err := extractGormDB(database, logger)
+	if err != nil {
+		return nil, err
 	}
+	return &permissionRepository{
+		BaseRepository: observability.NewBaseRepository("permissions", logger, nil),
+		db:             db,
+		logger:         logger,
+		cacheMgr:       cacheMgr,
+		cacheTTL:       ttl,
+	}, nil
 }
 
 // NewPermissionRepositoryWithTelemetry creates a new permission repository with telemetry enabled
-func NewPermissionRepositoryWithTelemetry(database interface{}, logger *logging.Logger, telemetry *telemetry.Telemetry) authorization.PermissionRepository {
-	db := extractGormDB(database, logger)
-	if db == nil {
-		return nil
+func NewPermissionRepositoryWithTelemetry(database interface{}, logger *logging.Logger, telemetry *telemetry.Telemetry) (authorization.PermissionRepository, error) {
+	db, err := extractGormDB(database, logger)
+	if err != nil {
+		return nil, err
 	}
 	return &permissionRepository{
-		db:        db,
-		logger:    logger,
-		telemetry: telemetry,
-	}
+		BaseRepository: observability.NewBaseRepository("permissions", logger, telemetry),
+		db:             db,
+		logger:         logger,
+	}, nil
 }
 
 // NewPermissionRepositoryWithAll creates a new permission repository with all features enabled
-func NewPermissionRepositoryWithAll(database interface{}, logger *logging.Logger, cacheMgr *cache.CacheManager, telemetry *telemetry.Telemetry) authorization.PermissionRepository {
-	db := extractGormDB(database, logger)
-	if db == nil {
-		return nil
+func NewPermissionRepositoryWithAll(database interface{}, logger *logging.Logger, cacheMgr *cache.CacheManager, telemetry *telemetry.Telemetry) (authorization.PermissionRepository, error) {
+	db, err := extractGormDB(database, logger)
+	if err != nil {
+		return nil, err
 	}
 	return &permissionRepository{
-		db:        db,
-		logger:    logger,
-		cacheMgr:  cacheMgr,
-		telemetry: telemetry,
-	}
+		BaseRepository: observability.NewBaseRepository("permissions", logger, telemetry),
+		db:             db,
+		logger:         logger,
+		cacheMgr:       cacheMgr,
+	}, nil
 }
 
+// ErrGormDBUnavailable is returned by the repository constructors when the database
+// they are given does not expose a GORM connection
+var ErrGormDBUnavailable = errors.New("database does not expose a GORM connection")
+
 // extractGormDB extracts *gorm.DB from various database interface types
-func extractGormDB(database interface{}, logger *logging.Logger) *gormio.DB {
+func extractGormDB(database interface{}, logger *logging.Logger) (*gormio.DB, error) {
 	// Try direct gorm database interface
 	if db, ok := database.(interface{ GetGormDB() *gormio.DB }); ok {
-		return db.GetGormDB()
+		if gdb := db.GetGormDB(); gdb != nil {
+			return gdb, nil
+		}
 	}
 
 	// Try core database interface with GetGormDB method
 	if coreDB, ok := database.(interface{ GetGormDB() interface{} }); ok {
-		if gdb, ok := coreDB.GetGormDB().(*gormio.DB); ok {
-			return gdb
+		if gdb, ok := coreDB.GetGormDB().(*gormio.DB); ok && gdb != nil {
+			return gdb, nil
 		}
 	}
 
-	logger.Error("Failed to extract GORM database from provided database interface")
-	return nil
+	err := fmt.Errorf("%w: got %T", ErrGormDBUnavailable, database)
+	logger.Error("Failed to extract GORM database from provided database interface", logging.Error(err))
+	return nil, err
 }
 
 func (r *permissionRepository) Create(ctx context.Context, permission *authorization.Permission) error {
-	ctx, span := r.startSpan(ctx, "permission_repository.create")
+	ctx, span := r.StartSpan(ctx, "permission_repository.create")
 	defer span.End()
 
 	span.SetAttributes(
@@ -110,8 +146,8 @@ func (r *permissionRepository) Create(ctx context.Context, permission *authoriza
 	permission.ModifiedAt = time.Now()
 
 	if err := r.db.WithContext(ctx).Create(permission).Error; err != nil {
-		r.recordError(span, err)
-		r.recordMetric("permission_create_errors_total", 1, map[string]string{"error": err.Error()})
+		r.RecordError(span, err)
+		r.RecordMetric(ctx, "permission_create_errors_total", 1, nil)
 		r.logger.Error("Failed to create permission",
 			logging.Error(err),
 			logging.String("permission_name", permission.Name))
@@ -122,8 +158,8 @@ func (r *permissionRepository) Create(ctx context.Context, permission *authoriza
 
 	// Record success metrics
 	duration := time.Since(startTime).Milliseconds()
-	r.recordMetric("permission_create_duration_ms", float64(duration), nil)
-	r.recordMetric("permission_create_total", 1, nil)
+	r.RecordMetric(ctx, "permission_create_duration_ms", float64(duration), nil)
+	r.RecordMetric(ctx, "permission_create_total", 1, nil)
 
 	// Invalidate related cache entries
 	r.invalidatePermissionCache(ctx, permission)
@@ -136,19 +172,13 @@ func (r *permissionRepository) Create(ctx context.Context, permission *authoriza
 }
 
 func (r *permissionRepository) GetByID(ctx context.Context, id uuid.UUID) (*authorization.Permission, error) {
-	cacheKey := fmt.Sprintf("permission:id:%s", id.String())
+	cacheKey := r.cacheKeys.byID(id)
 
 	// Try cache first if cache manager is available
 	if r.cacheMgr != nil {
-		var permission authorization.Permission
-		err := r.cacheMgr.Remember(ctx, cacheKey, &permission, func() (interface{}, error) {
+		return r.rememberPermission(ctx, cacheKey, func() (*authorization.Permission, error) {
 			return r.getByIDFromDB(ctx, id)
-		}, time.Minute*5) // Cache for 5 minutes
-
-		if err == nil && permission.ID.String() != "" {
-			return &permission, nil
-		}
-		// If cache miss or error, fall back to database
+		})
 	}
 
 	return r.getByIDFromDB(ctx, id)
@@ -171,19 +201,13 @@ func (r *permissionRepository) getByIDFromDB(ctx context.Context, id uuid.UUID)
 }
 
 func (r *permissionRepository) GetByName(ctx context.Context, name string) (*authorization.Permission, error) {
-	cacheKey := fmt.Sprintf("permission:name:%s", name)
+	cacheKey := r.cacheKeys.byName(name)
 
 	// Try cache first if cache manager is available
 	if r.cacheMgr != nil {
-		var permission authorization.Permission
-		err := r.cacheMgr.Remember(ctx, cacheKey, &permission, func() (interface{}, error) {
+		return r.rememberPermission(ctx, cacheKey, func() (*authorization.Permission, error) {
 			return r.getByNameFromDB(ctx, name)
-		}, time.Minute*5) // Cache for 5 minutes
-
-		if err == nil && permission.ID.String() != "" {
-			return &permission, nil
-		}
-		// If cache miss or error, fall back to database
+		})
 	}
 
 	return r.getByNameFromDB(ctx, name)
@@ -206,6 +230,20 @@ func (r *permissionRepository) getByNameFromDB(ctx context.Context, name string)
 }
 
 func (r *permissionRepository) GetByResourceAndAction(ctx context.Context, resource, action string) (*authorization.Permission, error) {
+	cacheKey := r.cacheKeys.byResourceAndAction(resource, action)
+
+	// Try cache first if cache manager is available
+	if r.cacheMgr != nil {
+		return r.rememberPermission(ctx, cacheKey, func() (*authorization.Permission, error) {
+			return r.getByResourceAndActionFromDB(ctx, resource, action)
+		})
+	}
+
+	return r.getByResourceAndActionFromDB(ctx, resource, action)
+}
+
+// getByResourceAndActionFromDB retrieves permission from database (internal method)
+func (r *permissionRepository) getByResourceAndActionFromDB(ctx context.Context, resource, action string) (*authorization.Permission, error) {
 	var permission authorization.Permission
 	if err := r.db.WithContext(ctx).
 		Where("resource = ? AND action = ?", resource, action).
@@ -287,6 +325,17 @@ func (r *permissionRepository) Delete(ctx context.Context, id uuid.UUID) error {
 }
 
 func (r *permissionRepository) GetRolePermissions(ctx context.Context, roleID uuid.UUID) ([]*authorization.Permission, error) {
+	if r.cacheMgr == nil {
+		return r.getRolePermissionsFromDB(ctx, roleID)
+	}
+
+	return r.rememberPermissions(ctx, r.cacheKeys.rolePermissions(roleID), func() ([]*authorization.Permission, error) {
+		return r.getRolePermissionsFromDB(ctx, roleID)
+	})
+}
+
+// getRolePermissionsFromDB retrieves a role's active permissions from database (internal method)
+func (r *permissionRepository) getRolePermissionsFromDB(ctx context.Context, roleID uuid.UUID) ([]*authorization.Permission, error) {
 	var permissions []*authorization.Permission
 
 	// Use GORM Joins to get permissions for a role through role_permissions table
@@ -294,7 +343,7 @@ func (r *permissionRepository) GetRolePermissions(ctx context.Context, roleID uu
 		Table("permissions").
 		Select("permissions.*").
 		Joins("INNER JOIN role_permissions rp ON permissions.id = rp.permission_id").
-		Where("rp.role_id = ? AND permissions.is_active = ?", roleID.String(), true).
+		Where(newUUIDBinding(r.db).equals("rp.role_id")+" AND permissions.is_active = ?", roleID, true).
 		Order("permissions.resource, permissions.action").
 		Find(&permissions).Error
 
@@ -308,7 +357,76 @@ func (r *permissionRepository) GetRolePermissions(ctx context.Context, roleID uu
 	return permissions, nil
 }
 
+// rolePermissionRow is a permission row tagged with the role it was reached through
+type rolePermissionRow struct {
+	authorization.Permission
+	RoleID string `gorm:"column:role_id"`
+}
+
+func (r *permissionRepository) GetPermissionsForRoles(ctx context.Context, roleIDs []uuid.UUID) (map[uuid.UUID][]*authorization.Permission, error) {
+	result := make(map[uuid.UUID][]*authorization.Permission, len(roleIDs))
+	if len(roleIDs) == 0 {
+		return result, nil
+	}
+
+	// args holds the distinct role IDs followed by the is_active flag
+	args := make([]interface{}, 0, len(roleIDs)+1)
+	for _, roleID := range roleIDs {
+		// Every requested role gets an entry, even if it has no permissions
+		if _, seen := result[roleID]; !seen {
+			result[roleID] = []*authorization.Permission{}
+			args = append(args, roleID)
+		}
+	}
+	roleCount := len(args)
+	args = append(args, true)
+
+	var rows []rolePermissionRow
+
+	// Fetch the permissions of all roles in a single join instead of one query per role
+	err := r.db.WithContext(ctx).
+		Table("permissions").
+		Select("permissions.*, rp.role_id AS role_id").
+		Joins("INNER JOIN role_permissions rp ON permissions.id = rp.permission_id").
+		Where(newUUIDBinding(r.db).in("rp.role_id", roleCount)+" AND permissions.is_active = ?", args...).
+		Order("rp.role_id, permissions.resource, permissions.action").
+		Find(&rows).Error
+
+	if err != nil {
+		r.logger.Error("Failed to get permissions for roles",
+			logging.Error(err),
+			logging.Int("role_count", roleCount))
+		return nil, fmt.Errorf("failed to get permissions for roles: %w", err)
+	}
+
+	for i := range rows {
+		roleID, err := uuid.Parse(rows[i].RoleID)
+		if err != nil {
+			r.logger.Warn("Skipping role permission with invalid role ID",
+				logging.String("role_id", rows[i].RoleID))
+			continue
+		}
+		permission := rows[i].Permission
+		result[roleID] = append(result[roleID], &permission)
+	}
+
+	return result, nil
+}
+
 func (r *permissionRepository) GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]*authorization.Permission, error) {
+	if r.cacheMgr == nil {
+		return r.getUserPermissionsFromDB(ctx, userID)
+	}
+
+	return r.rememberPermissions(ctx, r.cacheKeys.userPermissions(userID), func() ([]*authorization.Permission, error) {
+		return r.getUserPermissionsFromDB(ctx, userID)
+	})
+}
+
+// getUserPermissionsFromDB retrieves a user's effective permissions from database (internal method).
+// One join resolves the permissions of all the user's roles, so this does not go through
+// GetPermissionsForRoles, which would take a second query to look up the role IDs first.
+func (r *permissionRepository) getUserPermissionsFromDB(ctx context.Context, userID uuid.UUID) ([]*authorization.Permission, error) {
 	var permissions []*authorization.Permission
 
 	// Use GORM Joins to get permissions for a user through roles and role_permissions
@@ -318,8 +436,8 @@ func (r *permissionRepository) GetUserPermissions(ctx context.Context, userID uu
 		Joins("INNER JOIN role_permissions rp ON permissions.id = rp.permission_id").
 		Joins("INNER JOIN user_roles ur ON rp.role_id = ur.role_id").
 		Joins("INNER JOIN roles r ON ur.role_id = r.id").
-		Where("ur.user_id = ? AND permissions.is_active = ? AND r.is_active = ?",
-			userID.String(), true, true).
+		Where(newUUIDBinding(r.db).equals("ur.user_id")+" AND permissions.is_active = ? AND r.is_active = ?",
+			userID, true, true).
 		Order("permissions.resource, permissions.action").
 		Find(&permissions).Error
 
@@ -352,6 +470,10 @@ func (r *permissionRepository) AssignPermissionToRole(ctx context.Context, roleI
 		return fmt.Errorf("failed to assign permission: %w", err)
 	}
 
+	// The role and its users now resolve to a different permission set
+	r.invalidateRolePermissionsCache(ctx, roleID)
+	r.invalidateUserPermissionsCache(ctx)
+
 	r.logger.Info("Permission assigned to role successfully",
 		logging.String("role_id", roleID.String()),
 		logging.String("permission_id", permissionID.String()),
@@ -361,8 +483,9 @@ func (r *permissionRepository) AssignPermissionToRole(ctx context.Context, roleI
 }
 
 func (r *permissionRepository) RemovePermissionFromRole(ctx context.Context, roleID, permissionID uuid.UUID) error {
+	uuids := newUUIDBinding(r.db)
 	if err := r.db.WithContext(ctx).
-		Where("role_id = ? AND permission_id = ?", roleID, permissionID).
+		Where(uuids.equals("role_id")+" AND "+uuids.equals("permission_id"), roleID, permissionID).
 		Delete(&authorization.RolePermission{}).Error; err != nil {
 		r.logger.Error("Failed to remove permission from role",
 			logging.Error(err),
@@ -371,6 +494,10 @@ func (r *permissionRepository) RemovePermissionFromRole(ctx context.Context, rol
 		return fmt.Errorf("failed to remove permission: %w", err)
 	}
 
+	// The role and its users now resolve to a different permission set
+	r.invalidateRolePermissionsCache(ctx, roleID)
+	r.invalidateUserPermissionsCache(ctx)
+
 	r.logger.Info("Permission removed from role successfully",
 		logging.String("role_id", roleID.String()),
 		logging.String("permission_id", permissionID.String()))
@@ -379,7 +506,50 @@ func (r *permissionRepository) RemovePermissionFromRole(ctx context.Context, rol
 }
 
 func (r *permissionRepository) CheckUserPermission(ctx context.Context, userID uuid.UUID, resource, action string) (bool, error) {
+	if r.cacheMgr != nil {
+		return r.checkCachedUserPermission(ctx, userID, []string{resource}, action)
+	}
+	return r.checkUserPermission(ctx, userID, []string{resource}, resource, action)
+}
+
+func (r *permissionRepository) CheckUserPermissionHierarchical(ctx context.Context, userID uuid.UUID, resource, action string) (bool, error) {
+	resources := authorization.ResourceAncestors(resource)
+	if len(resources) == 0 {
+		return false, nil
+	}
+	if r.cacheMgr != nil {
+		return r.checkCachedUserPermission(ctx, userID, resources, action)
+	}
+	return r.checkUserPermission(ctx, userID, resources, resource, action)
+}
+
+// checkCachedUserPermission answers a permission check from the user's cached effective
+// permissions, applying the same wildcard rules as checkUserPermission. Every check of a
+// user shares one cache entry, which the grant changes already invalidate.
+func (r *permissionRepository) checkCachedUserPermission(ctx context.Context, userID uuid.UUID, resources []string, action string) (bool, error) {
+	permissions, err := r.GetUserPermissions(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	format := authorization.DefaultPermissionFormat()
+	for _, permission := range permissions {
+		granted := format.Format(permission.Resource, permission.Action)
+		for _, resource := range resources {
+			if format.Grants(granted, resource, action) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// checkUserPermission reports whether the user's active roles grant action on any of resources,
+// either exactly, through a "resource:*" permission, or through a "*:*" permission.
+// resource is the originally requested resource, used for logging.
+func (r *permissionRepository) checkUserPermission(ctx context.Context, userID uuid.UUID, resources []string, resource, action string) (bool, error) {
 	var count int64
+	wildcard := authorization.DefaultPermissionWildcard
 
 	// Use GORM Joins to check if user has permission through roles and role_permissions
 	err := r.db.WithContext(ctx).
@@ -388,8 +558,9 @@ func (r *permissionRepository) CheckUserPermission(ctx context.Context, userID u
 		Joins("INNER JOIN role_permissions rp ON permissions.id = rp.permission_id").
 		Joins("INNER JOIN user_roles ur ON rp.role_id = ur.role_id").
 		Joins("INNER JOIN roles r ON ur.role_id = r.id").
-		Where("ur.user_id = ? AND permissions.resource = ? AND permissions.action = ? AND permissions.is_active = ? AND r.is_active = ?",
-			userID.String(), resource, action, true, true).
+		Where(newUUIDBinding(r.db).equals("ur.user_id")+" AND permissions.is_active = ? AND r.is_active = ?", userID, true, true).
+		Where("((permissions.resource IN ? AND permissions.action IN ?) OR (permissions.resource = ? AND permissions.action = ?))",
+			resources, []string{action, wildcard}, wildcard, wildcard).
 		Count(&count).Error
 
 	if err != nil {
@@ -407,26 +578,208 @@ func (r *permissionRepository) CheckUserPermission(ctx context.Context, userID u
 		logging.String("user_id", userID.String()),
 		logging.String("resource", resource),
 		logging.String("action", action),
+		logging.Int("resources_checked", len(resources)),
 		logging.Bool("has_permission", hasPermission))
 
 	return hasPermission, nil
 }
 
+func (r *permissionRepository) CheckUserPermissions(ctx context.Context, userID uuid.UUID, perms []authorization.Permission) (map[string]bool, error) {
+	results := make(map[string]bool, len(perms))
+	if len(perms) == 0 {
+		return results, nil
+	}
+
+	format := authorization.DefaultPermissionFormat()
+	if r.cacheMgr != nil {
+		permissions, err := r.GetUserPermissions(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		grantedKeys := make([]string, len(permissions))
+		for i, permission := range permissions {
+			grantedKeys[i] = format.Format(permission.Resource, permission.Action)
+		}
+		for _, perm := range perms {
+			results[authorization.PermissionKey(perm.Resource, perm.Action)] = format.Allows(grantedKeys, perm.Resource, perm.Action)
+		}
+		return results, nil
+	}
+
+	resources := []string{format.Wildcard}
+	for _, perm := range perms {
+		resources = append(resources, perm.Resource)
+	}
+
+	// Load every grant that could match one of the requested resources in a single query,
+	// then apply the CheckUserPermission wildcard rules to each requested permission
+	var granted []struct {
+		Resource string
+		Action   string
+	}
+	err := r.db.WithContext(ctx).
+		Table("permissions").
+		Distinct("permissions.resource", "permissions.action").
+		Joins("INNER JOIN role_permissions rp ON permissions.id = rp.permission_id").
+		Joins("INNER JOIN user_roles ur ON rp.role_id = ur.role_id").
+		Joins("INNER JOIN roles r ON ur.role_id = r.id").
+		Where(newUUIDBinding(r.db).equals("ur.user_id")+" AND permissions.is_active = ? AND r.is_active = ?", userID, true, true).
+		Where("permissions.resource IN ?", resources).
+		Scan(&granted).Error
+
+	if err != nil {
+		r.logger.Error("Failed to check user permissions",
+			logging.Error(err),
+			logging.String("user_id", userID.String()),
+			logging.Int("permission_count", len(perms)))
+		return nil, fmt.Errorf("failed to check permissions: %w", err)
+	}
+
+	grantedKeys := make([]string, len(granted))
+	for i, g := range granted {
+		grantedKeys[i] = format.Format(g.Resource, g.Action)
+	}
+	for _, perm := range perms {
+		results[authorization.PermissionKey(perm.Resource, perm.Action)] = format.Allows(grantedKeys, perm.Resource, perm.Action)
+	}
+
+	r.logger.Debug("User permissions checked",
+		logging.String("user_id", userID.String()),
+		logging.Int("permission_count", len(perms)),
+		logging.Int("grants_matched", len(granted)))
+
+	return results, nil
+}
+
+// permissionCacheEntry is the cached result of a single permission lookup.
+// NotFound marks a cached not-found so it can be told apart from a cache miss.
+// The ID is stored separately because EntityID does not survive JSON encoding.
+type permissionCacheEntry struct {
+	ID         string                    `json:"id,omitempty"`
+	Permission *authorization.Permission `json:"permission,omitempty"`
+	NotFound   bool                      `json:"not_found,omitempty"`
+}
+
+// rememberPermission returns the cached lookup result for key, calling load on a miss.
+// Found permissions are cached for permissionCacheTTL and not-found results for
+// permissionNotFoundTTL. Cache failures fall back to load.
+func (r *permissionRepository) rememberPermission(ctx context.Context, key string, load func() (*authorization.Permission, error)) (*authorization.Permission, error) {
+	var entry permissionCacheEntry
+	err := r.cacheMgr.Get(ctx, key, &entry)
+	switch {
+	case err == nil && entry.NotFound:
+		return nil, fmt.Errorf("permission not found: %w", gormio.ErrRecordNotFound)
+	case err == nil && entry.Permission != nil && entry.ID != "":
+		if id, parseErr := uuid.Parse(entry.ID); parseErr == nil {
+			entry.Permission.SetUUID(id)
+			return entry.Permission, nil
+		}
+	case err != nil && !errors.Is(err, cache.ErrCacheMiss):
+		r.logger.Warn("Failed to read permission from cache",
+			logging.Error(err),
+			logging.String("cache_key", key))
+	}
+
+	permission, err := load()
+	if err != nil {
+		if errors.Is(err, gormio.ErrRecordNotFound) {
+			r.putPermissionCacheEntry(ctx, key, permissionCacheEntry{NotFound: true}, permissionNotFoundTTL)
+		}
+		return nil, err
+	}
+
+	r.putPermissionCacheEntry(ctx, key, permissionCacheEntry{
+		ID:         permission.ID.String(),
+		Permission: permission,
+	}, r.permissionTTL())
+
+	return permission, nil
+}
+
+// rememberPermissions returns the permission list cached under key, calling load and caching
+// its result on a miss. Cache failures fall back to load.
+func (r *permissionRepository) rememberPermissions(ctx context.Context, key string, load func() ([]*authorization.Permission, error)) ([]*authorization.Permission, error) {
+	var (
+		entries   []permissionCacheEntry
+		loaded    []*authorization.Permission
+		wasLoaded bool
+		loadErr   error
+	)
+
+	err := r.cacheMgr.Remember(ctx, key, &entries, func() (interface{}, error) {
+		loaded, loadErr = load()
+		if loadErr != nil {
+			return nil, loadErr
+		}
+		wasLoaded = true
+		return permissionCacheEntriesFor(loaded), nil
+	}, r.permissionTTL())
+
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	if err != nil {
+		r.logger.Warn("Failed to cache permission list",
+			logging.Error(err),
+			logging.String("cache_key", key))
+	}
+	if wasLoaded {
+		return loaded, nil
+	}
+	if err == nil {
+		if permissions, ok := permissionsFromCacheEntries(entries); ok {
+			return permissions, nil
+		}
+	}
+	return load()
+}
+
+// permissionCacheEntriesFor converts a permission list into its cached form
+func permissionCacheEntriesFor(permissions []*authorization.Permission) []permissionCacheEntry {
+	entries := make([]permissionCacheEntry, 0, len(permissions))
+	for _, permission := range permissions {
+		entries = append(entries, permissionCacheEntry{ID: permission.ID.String(), Permission: permission})
+	}
+	return entries
+}
+
+// permissionsFromCacheEntries restores a cached permission list, reporting false if any entry is unusable
+func permissionsFromCacheEntries(entries []permissionCacheEntry) ([]*authorization.Permission, bool) {
+	permissions := make([]*authorization.Permission, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Permission == nil {
+			return nil, false
+		}
+		id, err := uuid.Parse(entry.ID)
+		if err != nil {
+			return nil, false
+		}
+		entry.Permission.SetUUID(id)
+		permissions = append(permissions, entry.Permission)
+	}
+	return permissions, true
+}
+
+// putPermissionCacheEntry stores a lookup result, logging rather than failing on cache errors
+func (r *permissionRepository) putPermissionCacheEntry(ctx context.Context, key string, entry permissionCacheEntry, ttl time.Duration) {
+	if err := r.cacheMgr.Put(ctx, key, entry, ttl); err != nil {
+		r.logger.Warn("Failed to cache permission lookup",
+			logging.Error(err),
+			logging.String("cache_key", key))
+	}
+}
+
 // invalidatePermissionCache invalidates cache entries related to a permission
 func (r *permissionRepository) invalidatePermissionCache(ctx context.Context, permission *authorization.Permission) {
 	if r.cacheMgr == nil {
 		return
 	}
 
-	// Invalidate specific permission caches
-	cacheKeys := []string{
-		fmt.Sprintf("permission:id:%s", permission.ID.String()),
-		fmt.Sprintf("permission:name:%s", permission.Name),
-		fmt.Sprintf("permission:resource_action:%s_%s", permission.Resource, permission.Action),
-		"permission:active", // Invalidate active permissions list
-	}
+	r.invalidateUserPermissionsCache(ctx)
+	r.invalidateAllRolePermissionsCache(ctx)
 
-	for _, key := range cacheKeys {
+	// Invalidate specific permission caches and the active permissions list
+	for _, key := range r.cacheKeys.forPermission(permission) {
 		if err := r.cacheMgr.Forget(ctx, key); err != nil {
 			r.logger.Warn("Failed to invalidate cache key",
 				logging.String("cache_key", key),
@@ -435,33 +788,54 @@ func (r *permissionRepository) invalidatePermissionCache(ctx context.Context, pe
 	}
 }
 
-// startSpan starts a new telemetry span if telemetry is enabled
-func (r *permissionRepository) startSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
-	if r.telemetry == nil {
-		return ctx, trace.SpanFromContext(ctx)
+// invalidateUserPermissionsCache drops every cached user permission list.
+// Role grants fan out to an unknown set of users, so the lists are cleared wholesale.
+func (r *permissionRepository) invalidateUserPermissionsCache(ctx context.Context) {
+	if r.cacheMgr == nil {
+		return
 	}
 
-	return r.telemetry.GetTracer().Start(ctx, operation)
+	pattern := r.cacheKeys.allUserPermissions()
+	if err := r.cacheMgr.GetCache().DeletePattern(ctx, pattern); err != nil {
+		r.logger.Warn("Failed to invalidate user permission caches",
+			logging.String("cache_pattern", pattern),
+			logging.Error(err))
+	}
 }
 
-// recordError records an error in the telemetry span
-func (r *permissionRepository) recordError(span trace.Span, err error) {
-	if span != nil {
-		span.RecordError(err)
-		span.SetStatus(1, err.Error()) // 1 = Error status
+// invalidateRolePermissionsCache drops the cached permission list of a role
+func (r *permissionRepository) invalidateRolePermissionsCache(ctx context.Context, roleID uuid.UUID) {
+	if r.cacheMgr == nil {
+		return
+	}
+
+	key := r.cacheKeys.rolePermissions(roleID)
+	if err := r.cacheMgr.Forget(ctx, key); err != nil {
+		r.logger.Warn("Failed to invalidate role permission cache",
+			logging.String("cache_key", key),
+			logging.Error(err))
 	}
 }
 
-// recordMetric records a metric if telemetry is enabled
-func (r *permissionRepository) recordMetric(name string, value float64, labels map[string]string) {
-	if r.telemetry == nil {
+// invalidateAllRolePermissionsCache drops every cached role permission list, for changes to a
+// permission that any number of roles may hold
+func (r *permissionRepository) invalidateAllRolePermissionsCache(ctx context.Context) {
+	if r.cacheMgr == nil {
 		return
 	}
 
-	// This is a simplified metric recording - in a real implementation,
-	// you would use the telemetry meter to create proper metrics
-	r.logger.Debug("Recording metric",
-		logging.String("metric", name),
-		logging.Any("value", value),
-		logging.Any("labels", labels))
+	pattern := r.cacheKeys.allRolePermissions()
+	if err := r.cacheMgr.GetCache().DeletePattern(ctx, pattern); err != nil {
+		r.logger.Warn("Failed to invalidate role permission caches",
+			logging.String("cache_pattern", pattern),
+			logging.Error(err))
+	}
+}
+
+// permissionTTL returns how long cached permissions and permission lists are kept
+func (r *permissionRepository) permissionTTL() time.Duration {
+	if r.cacheTTL > 0 {
+		return r.cacheTTL
+	}
+	return permissionCacheTTL
 }
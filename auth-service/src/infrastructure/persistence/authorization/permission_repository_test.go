@@ -0,0 +1,278 @@
+package authorization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"auth-service/src/domain/authorization"
+	"backend-core/cache"
+	"backend-core/logging"
+
+	"github.com/google/uuid"
+	gormio "gorm.io/gorm"
+)
+
+// newCachedPermissionRepository returns a permission repository over a dry-run Postgres
+// database and an in-memory cache, with the statements sent to the database
+func newCachedPermissionRepository(t *testing.T) (*permissionRepository, *recordingCache, *[]recordedStatement) {
+	t.Helper()
+
+	db, statements := newDryRunDB(t, "postgres")
+	store := newRecordingCache()
+	repo := &permissionRepository{db: db, logger: logging.NewNopLogger(), cacheMgr: cache.NewCacheManager(store)}
+	return repo, store, statements
+}
+
+func TestGetByResourceAndActionUsesCache(t *testing.T) {
+	repo, store, statements := newCachedPermissionRepository(t)
+	ctx := context.Background()
+
+	permission := &authorization.Permission{Name: "users:read", Resource: "users", Action: "read"}
+	permission.SetUUID(uuid.New())
+	key := repo.cacheKeys.byResourceAndAction(permission.Resource, permission.Action)
+	repo.putPermissionCacheEntry(ctx, key, permissionCacheEntry{ID: permission.GetUUID().String(), Permission: permission}, repo.permissionTTL())
+
+	cached, err := repo.GetByResourceAndAction(ctx, "users", "read")
+	if err != nil {
+		t.Fatalf("GetByResourceAndAction() error = %v", err)
+	}
+	if cached.GetUUID() != permission.GetUUID() || cached.Name != permission.Name {
+		t.Errorf("GetByResourceAndAction() = %+v, want the cached %+v", cached, permission)
+	}
+	if len(*statements) != 0 {
+		t.Fatalf("cached lookup queried the database %d times", len(*statements))
+	}
+
+	repo.invalidatePermissionCache(ctx, permission)
+	if _, ok := store.values[key]; ok {
+		t.Fatalf("cache key %q survived invalidation", key)
+	}
+
+	repo.GetByResourceAndAction(ctx, "users", "read")
+	if len(*statements) != 1 {
+		t.Errorf("lookup after invalidation queried the database %d times, want 1", len(*statements))
+	}
+}
+
+func TestRememberPermission(t *testing.T) {
+	ctx := context.Background()
+	stored := &authorization.Permission{Name: "users:read", Resource: "users", Action: "read"}
+	stored.SetUUID(uuid.New())
+	notFound := func() (*authorization.Permission, error) {
+		return nil, fmt.Errorf("permission not found: %w", gormio.ErrRecordNotFound)
+	}
+
+	t.Run("cached hit", func(t *testing.T) {
+		repo, _, _ := newCachedPermissionRepository(t)
+		repo.putPermissionCacheEntry(ctx, "key", permissionCacheEntry{ID: stored.GetUUID().String(), Permission: stored}, permissionCacheTTL)
+
+		loads := 0
+		permission, err := repo.rememberPermission(ctx, "key", func() (*authorization.Permission, error) {
+			loads++
+			return nil, errors.New("unexpected load")
+		})
+		if err != nil || permission.GetUUID() != stored.GetUUID() {
+			t.Errorf("rememberPermission() = %v, %v, want the cached permission", permission, err)
+		}
+		if loads != 0 {
+			t.Errorf("cached hit loaded %d times", loads)
+		}
+	})
+
+	t.Run("cached not-found", func(t *testing.T) {
+		repo, store, _ := newCachedPermissionRepository(t)
+
+		loads := 0
+		load := func() (*authorization.Permission, error) {
+			loads++
+			return notFound()
+		}
+		for i := 0; i < 3; i++ {
+			if _, err := repo.rememberPermission(ctx, "key", load); !errors.Is(err, gormio.ErrRecordNotFound) {
+				t.Fatalf("lookup %d error = %v, want a not-found error", i, err)
+			}
+		}
+		if loads != 1 {
+			t.Errorf("missing permission loaded %d times, want 1", loads)
+		}
+		if store.ttls["key"] != permissionNotFoundTTL {
+			t.Errorf("not-found cached for %v, want %v", store.ttls["key"], permissionNotFoundTTL)
+		}
+	})
+
+	t.Run("genuine miss", func(t *testing.T) {
+		repo, store, _ := newCachedPermissionRepository(t)
+
+		loads := 0
+		load := func() (*authorization.Permission, error) {
+			loads++
+			return stored, nil
+		}
+		for i := 0; i < 2; i++ {
+			permission, err := repo.rememberPermission(ctx, "key", load)
+			if err != nil || permission.GetUUID() != stored.GetUUID() {
+				t.Fatalf("lookup %d = %v, %v, want the loaded permission", i, permission, err)
+			}
+		}
+		if loads != 1 {
+			t.Errorf("permission loaded %d times, want 1", loads)
+		}
+		if store.ttls["key"] != permissionCacheTTL {
+			t.Errorf("permission cached for %v, want %v", store.ttls["key"], permissionCacheTTL)
+		}
+	})
+}
+
+// mongoDatabase stands in for a database that is not backed by GORM
+type mongoDatabase struct{}
+
+func (mongoDatabase) GetGormDB() interface{} { return nil }
+
+func TestConstructorsRejectNonGormDatabase(t *testing.T) {
+	logger := logging.NewNopLogger()
+
+	constructors := map[string]func(database interface{}) (interface{}, error){
+		"NewPermissionRepository": func(database interface{}) (interface{}, error) {
+			return NewPermissionRepository(database, logger)
+		},
+		"NewRoleRepository": func(database interface{}) (interface{}, error) {
+			return NewRoleRepository(database, logger)
+		},
+		"NewSeeder": func(database interface{}) (interface{}, error) {
+			return NewSeeder(database, logger)
+		},
+	}
+	for name, construct := range constructors {
+		for _, database := range []interface{}{nil, "postgres://localhost", mongoDatabase{}} {
+			if _, err := construct(database); !errors.Is(err, ErrGormDBUnavailable) {
+				t.Errorf("%s(%T) error = %v, want ErrGormDBUnavailable", name, database, err)
+			}
+		}
+	}
+}
+
+func TestCheckUserPermissionHierarchical(t *testing.T) {
+	repo, store, _ := newCachedPermissionRepository(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	var granted []*authorization.Permission
+	for _, grant := range [][2]string{{"documents", "read"}, {"reports/finance", "*"}} {
+		permission := &authorization.Permission{Resource: grant[0], Action: grant[1], IsActive: true}
+		permission.SetUUID(uuid.New())
+		granted = append(granted, permission)
+	}
+	store.Set(ctx, repo.cacheKeys.userPermissions(userID), permissionCacheEntriesFor(granted), permissionCacheTTL)
+
+	tests := []struct {
+		resource string
+		action   string
+		want     bool
+	}{
+		{resource: "documents", action: "read", want: true},
+		{resource: "documents/reports", action: "read", want: true},
+		{resource: "documents/reports/q1", action: "read", want: true},
+		{resource: "documents/reports", action: "write", want: false},
+		{resource: "documentsx", action: "read", want: false},
+		{resource: "reports/finance/q1", action: "delete", want: true},
+		{resource: "reports", action: "read", want: false},
+		{resource: "", action: "read", want: false},
+	}
+	for _, tt := range tests {
+		got, err := repo.CheckUserPermissionHierarchical(ctx, userID, tt.resource, tt.action)
+		if err != nil {
+			t.Fatalf("CheckUserPermissionHierarchical(%q, %q) error = %v", tt.resource, tt.action, err)
+		}
+		if got != tt.want {
+			t.Errorf("CheckUserPermissionHierarchical(%q, %q) = %v, want %v", tt.resource, tt.action, got, tt.want)
+		}
+	}
+}
+
+func TestCheckUserPermissionHierarchicalQueriesAncestorsAndWildcards(t *testing.T) {
+	db, statements := newDryRunDB(t, "postgres")
+	repo := &permissionRepository{db: db, logger: logging.NewNopLogger()}
+
+	repo.CheckUserPermissionHierarchical(context.Background(), uuid.New(), "documents/reports", "read")
+
+	if len(*statements) != 1 {
+		t.Fatalf("recorded %d statements, want 1", len(*statements))
+	}
+	// The user ID and active flags come first, then the expanded resource and action lists
+	// and the full wildcard
+	vars := (*statements)[0].vars
+	want := []interface{}{"documents/reports", "documents", "read", "*", "*", "*"}
+	if len(vars) != 3+len(want) || !reflect.DeepEqual(vars[3:], want) {
+		t.Errorf("parameters = %#v, want the user, the active flags and %v", vars, want)
+	}
+}
+
+func TestGetPermissionsForRolesGroupsThreeRolesInOneQuery(t *testing.T) {
+	db, statements := newDryRunDB(t, "postgres")
+	repo := &permissionRepository{db: db, logger: logging.NewNopLogger()}
+	admin, editor, viewer := uuid.New(), uuid.New(), uuid.New()
+
+	row := func(roleID, resource, action string) rolePermissionRow {
+		permission := authorization.Permission{Name: resource + ":" + action, Resource: resource, Action: action}
+		permission.SetUUID(uuid.New())
+		return rolePermissionRow{Permission: permission, RoleID: roleID}
+	}
+	rows := []rolePermissionRow{
+		row(admin.String(), "users", "delete"),
+		row(admin.String(), "users", "read"),
+		row(editor.String(), "users", "read"),
+		row("not-a-uuid", "users", "write"),
+	}
+	// Hand the joined rows to the query instead of a database. The dry run cannot parse the
+	// entity ID of the scanned model, so that error is dropped along with the real query.
+	if err := db.Callback().Query().Replace("gorm:query", func(tx *gormio.DB) {
+		if dest, ok := tx.Statement.Dest.(*[]rolePermissionRow); ok {
+			*dest = rows
+			tx.Error = nil
+		}
+	}); err != nil {
+		t.Fatalf("failed to replace query callback: %v", err)
+	}
+
+	byRole, err := repo.GetPermissionsForRoles(context.Background(), []uuid.UUID{admin, editor, viewer})
+	if err != nil {
+		t.Fatalf("GetPermissionsForRoles() error = %v", err)
+	}
+
+	if len(*statements) != 1 {
+		t.Fatalf("recorded %d statements, want 1", len(*statements))
+	}
+	if sql := (*statements)[0].sql; !strings.Contains(sql, "rp.role_id IN (CAST($1 AS uuid), CAST($2 AS uuid), CAST($3 AS uuid))") {
+		t.Errorf("SQL %q does not select the three roles in one IN clause", sql)
+	}
+
+	names := func(permissions []*authorization.Permission) []string {
+		result := make([]string, len(permissions))
+		for i, permission := range permissions {
+			result[i] = permission.Name
+		}
+		return result
+	}
+	want := map[uuid.UUID][]string{
+		admin:  {"users:delete", "users:read"},
+		editor: {"users:read"},
+		viewer: {},
+	}
+	if len(byRole) != len(want) {
+		t.Errorf("GetPermissionsForRoles() returned %d roles, want %d", len(byRole), len(want))
+	}
+	for roleID, wantNames := range want {
+		permissions, ok := byRole[roleID]
+		if !ok {
+			t.Errorf("role %s missing from the result", roleID)
+			continue
+		}
+		if got := names(permissions); !reflect.DeepEqual(got, wantNames) {
+			t.Errorf("permissions of role %s = %q, want %q", roleID, got, wantNames)
+		}
+	}
+}
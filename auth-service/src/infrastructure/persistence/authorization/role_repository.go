@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"auth-service/src/domain/authorization"
+	"backend-core/cache"
 	"backend-core/database/gorm"
 	"backend-core/logging"
 
@@ -16,11 +17,19 @@ import (
 // roleRepository implements authorization.RoleRepository
 type roleRepository struct {
 	*gorm.GormRepository[authorization.Role]
-	logger *logging.Logger
+	logger    *logging.Logger
+	cacheMgr  *cache.CacheManager
+	cacheKeys permissionCacheKeys
 }
 
 // NewRoleRepository creates a new role repository
-func NewRoleRepository(database interface{}, logger *logging.Logger) authorization.RoleRepository {
+func NewRoleRepository(database interface{}, logger *logging.Logger) (authorization.RoleRepository, error) {
+	return NewRoleRepositoryWithCache(database, logger, nil)
+}
+
+// NewRoleRepositoryWithCache creates a new role repository that drops the cached permission
+// lists of the permission repository sharing cacheMgr whenever role grants change
+func NewRoleRepositoryWithCache(database interface{}, logger *logging.Logger, cacheMgr *cache.CacheManager) (authorization.RoleRepository, error) {
 	// Type assert to get the gorm database methods
 	var gormDB gorm.Database
 	if db, ok := database.(gorm.Database); ok {
@@ -35,8 +44,9 @@ func NewRoleRepository(database interface{}, logger *logging.Logger) authorizati
 			}
 		}
 		if gormDB == nil {
-			logger.Error("Failed to extract GORM database from provided database interface")
-			return nil
+			err := fmt.Errorf("%w: got %T", ErrGormDBUnavailable, database)
+			logger.Error("Failed to extract GORM database from provided database interface", logging.Error(err))
+			return nil, err
 		}
 	}
 
@@ -45,7 +55,8 @@ func NewRoleRepository(database interface{}, logger *logging.Logger) authorizati
 	return &roleRepository{
 		GormRepository: baseRepo,
 		logger:         logger,
-	}
+		cacheMgr:       cacheMgr,
+	}, nil
 }
 
 // simpleGormWrapper wraps a GORM DB to implement gorm.Database interface
@@ -236,6 +247,7 @@ func (r *roleRepository) Update(ctx context.Context, role *authorization.Role) e
 			logging.String("role_id", role.ID.String()))
 		return fmt.Errorf("failed to update role: %w", err)
 	}
+	r.invalidateRoleGrants(ctx, role.GetUUID())
 
 	r.logger.Info("Role updated successfully",
 		logging.String("role_id", role.ID.String()),
@@ -251,6 +263,7 @@ func (r *roleRepository) Delete(ctx context.Context, id uuid.UUID) error {
 			logging.String("role_id", id.String()))
 		return fmt.Errorf("failed to delete role: %w", err)
 	}
+	r.invalidateRoleGrants(ctx, id)
 
 	r.logger.Info("Role deleted successfully",
 		logging.String("role_id", id.String()))
@@ -262,11 +275,12 @@ func (r *roleRepository) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]
 	var roles []*authorization.Role
 
 	// Use GORM Joins to get roles for a user through user_roles table
-	err := r.GetGormDB().WithContext(ctx).
+	db := r.GetGormDB()
+	err := db.WithContext(ctx).
 		Table("roles").
 		Select("roles.*").
 		Joins("INNER JOIN user_roles ur ON roles.id = ur.role_id").
-		Where("ur.user_id = ? AND roles.is_active = ?", userID.String(), true).
+		Where(newUUIDBinding(db).equals("ur.user_id")+" AND roles.is_active = ?", userID, true).
 		Order("roles.name").
 		Find(&roles).Error
 
@@ -300,6 +314,7 @@ func (r *roleRepository) AssignRoleToUser(ctx context.Context, userID, roleID uu
 			logging.String("role_id", roleID.String()))
 		return fmt.Errorf("failed to assign role: %w", err)
 	}
+	r.invalidateUserGrants(ctx, userID)
 
 	r.logger.Info("Role assigned to user successfully",
 		logging.String("user_id", userID.String()),
@@ -312,7 +327,8 @@ func (r *roleRepository) AssignRoleToUser(ctx context.Context, userID, roleID uu
 func (r *roleRepository) RemoveRoleFromUser(ctx context.Context, userID, roleID uuid.UUID) error {
 	// Use GormRepository's underlying database connection to delete UserRole
 	db := r.GetGormDB().WithContext(ctx)
-	if err := db.Where("user_id = ? AND role_id = ?", userID.String(), roleID.String()).
+	uuids := newUUIDBinding(db)
+	if err := db.Where(uuids.equals("user_id")+" AND "+uuids.equals("role_id"), userID, roleID).
 		Delete(&authorization.UserRole{}).Error; err != nil {
 		r.logger.Error("Failed to remove role from user",
 			logging.Error(err),
@@ -320,6 +336,7 @@ func (r *roleRepository) RemoveRoleFromUser(ctx context.Context, userID, roleID
 			logging.String("role_id", roleID.String()))
 		return fmt.Errorf("failed to remove role: %w", err)
 	}
+	r.invalidateUserGrants(ctx, userID)
 
 	r.logger.Info("Role removed from user successfully",
 		logging.String("user_id", userID.String()),
@@ -327,3 +344,39 @@ func (r *roleRepository) RemoveRoleFromUser(ctx context.Context, userID, roleID
 
 	return nil
 }
+
+// invalidateUserGrants drops the cached effective permissions of a user whose roles changed
+func (r *roleRepository) invalidateUserGrants(ctx context.Context, userID uuid.UUID) {
+	if r.cacheMgr == nil {
+		return
+	}
+
+	key := r.cacheKeys.userPermissions(userID)
+	if err := r.cacheMgr.Forget(ctx, key); err != nil {
+		r.logger.Warn("Failed to invalidate user permission cache",
+			logging.String("cache_key", key),
+			logging.Error(err))
+	}
+}
+
+// invalidateRoleGrants drops the cached permission list of a changed or deleted role, and every
+// cached user permission list, since any number of users may hold the role
+func (r *roleRepository) invalidateRoleGrants(ctx context.Context, roleID uuid.UUID) {
+	if r.cacheMgr == nil {
+		return
+	}
+
+	key := r.cacheKeys.rolePermissions(roleID)
+	if err := r.cacheMgr.Forget(ctx, key); err != nil {
+		r.logger.Warn("Failed to invalidate role permission cache",
+			logging.String("cache_key", key),
+			logging.Error(err))
+	}
+
+	pattern := r.cacheKeys.allUserPermissions()
+	if err := r.cacheMgr.GetCache().DeletePattern(ctx, pattern); err != nil {
+		r.logger.Warn("Failed to invalidate user permission caches",
+			logging.String("cache_pattern", pattern),
+			logging.Error(err))
+	}
+}
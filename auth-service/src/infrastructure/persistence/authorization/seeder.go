@@ -0,0 +1,361 @@
+package authorization
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"auth-service/src/domain/authorization"
+	"backend-core/database/observability"
+	"backend-core/logging"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+	gormio "gorm.io/gorm"
+)
+
+// SeedDefinition declares the permissions, roles and user assignments an environment should have
+type SeedDefinition struct {
+	Permissions []PermissionSeed `json:"permissions" yaml:"permissions"`
+	Roles       []RoleSeed       `json:"roles" yaml:"roles"`
+	Assignments []UserRoleSeed   `json:"assignments" yaml:"assignments"`
+}
+
+// PermissionSeed declares a permission, identified by name
+type PermissionSeed struct {
+	Name        string `json:"name" yaml:"name"`
+	Resource    string `json:"resource" yaml:"resource"`
+	Action      string `json:"action" yaml:"action"`
+	Description string `json:"description" yaml:"description"`
+	IsActive    *bool  `json:"is_active,omitempty" yaml:"is_active,omitempty"`
+}
+
+// RoleSeed declares a role, identified by name, and the names of the permissions it grants
+type RoleSeed struct {
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description" yaml:"description"`
+	IsActive    *bool    `json:"is_active,omitempty" yaml:"is_active,omitempty"`
+	Permissions []string `json:"permissions" yaml:"permissions"`
+}
+
+// UserRoleSeed declares the names of the roles assigned to a user
+type UserRoleSeed struct {
+	UserID uuid.UUID `json:"user_id" yaml:"user_id"`
+	Roles  []string  `json:"roles" yaml:"roles"`
+}
+
+// SeedReport lists what a seed run changed
+type SeedReport struct {
+	PermissionsCreated []string `json:"permissions_created"`
+	PermissionsUpdated []string `json:"permissions_updated"`
+	RolesCreated       []string `json:"roles_created"`
+	RolesUpdated       []string `json:"roles_updated"`
+	PermissionsGranted []string `json:"permissions_granted"`
+	RolesAssigned      []string `json:"roles_assigned"`
+}
+
+// Changed reports whether the seed run modified anything
+func (r *SeedReport) Changed() bool {
+	return len(r.PermissionsCreated) > 0 || len(r.PermissionsUpdated) > 0 ||
+		len(r.RolesCreated) > 0 || len(r.RolesUpdated) > 0 ||
+		len(r.PermissionsGranted) > 0 || len(r.RolesAssigned) > 0
+}
+
+// LoadSeedFile reads a seed definition from a .yaml, .yml or .json file
+func LoadSeedFile(path string) (*SeedDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed file: %w", err)
+	}
+
+	var def SeedDefinition
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &def)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &def)
+	default:
+		return nil, fmt.Errorf("unsupported seed file extension %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse seed file: %w", err)
+	}
+
+	return &def, nil
+}
+
+// Seeder idempotently applies a SeedDefinition, creating missing entries and
+// updating changed ones. Entries not mentioned in the definition are left alone.
+type Seeder struct {
+	db        *gormio.DB
+	logger    *logging.Logger
+	appliedBy string
+}
+
+// NewSeeder creates a new authorization seeder
+func NewSeeder(database interface{}, logger *logging.Logger) (*Seeder, error) {
+	db, err := extractGormDB(database, logger)
+	if err != nil {
+		return nil, err
+	}
+	return &Seeder{
+		db:        db,
+		logger:    logger,
+		appliedBy: "seeder",
+	}, nil
+}
+
+// Apply applies the definition in a single transaction and reports what changed.
+// Nothing is written if any step fails.
+func (s *Seeder) Apply(ctx context.Context, def *SeedDefinition) (*SeedReport, error) {
+	if err := def.validate(); err != nil {
+		return nil, err
+	}
+
+	report := &SeedReport{}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gormio.DB) error {
+		permissionRepo := &permissionRepository{
+			BaseRepository: observability.NewBaseRepository("permissions", s.logger, nil),
+			db:             tx,
+			logger:         s.logger,
+		}
+		roleRepo, err := NewRoleRepository(&simpleGormWrapper{db: tx, logger: s.logger}, s.logger)
+		if err != nil {
+			return err
+		}
+
+		permissions, err := s.applyPermissions(ctx, permissionRepo, def.Permissions, report)
+		if err != nil {
+			return err
+		}
+
+		roles, err := s.applyRoles(ctx, roleRepo, def.Roles, report)
+		if err != nil {
+			return err
+		}
+
+		if err := s.applyRolePermissions(ctx, tx, permissionRepo, def.Roles, roles, permissions, report); err != nil {
+			return err
+		}
+
+		return s.applyUserRoles(ctx, tx, roleRepo, def.Assignments, roles, report)
+	})
+	if err != nil {
+		s.logger.Error("Failed to apply authorization seed", logging.Error(err))
+		return nil, fmt.Errorf("failed to apply seed: %w", err)
+	}
+
+	s.logger.Info("Authorization seed applied",
+		logging.Bool("changed", report.Changed()),
+		logging.Int("permissions_created", len(report.PermissionsCreated)),
+		logging.Int("permissions_updated", len(report.PermissionsUpdated)),
+		logging.Int("roles_created", len(report.RolesCreated)),
+		logging.Int("roles_updated", len(report.RolesUpdated)),
+		logging.Int("permissions_granted", len(report.PermissionsGranted)),
+		logging.Int("roles_assigned", len(report.RolesAssigned)))
+
+	return report, nil
+}
+
+// applyPermissions creates or updates the declared permissions and returns all permissions by name
+func (s *Seeder) applyPermissions(ctx context.Context, repo authorization.PermissionRepository, seeds []PermissionSeed, report *SeedReport) (map[string]*authorization.Permission, error) {
+	existing, err := repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*authorization.Permission, len(existing))
+	for _, p := range existing {
+		byName[p.Name] = p
+	}
+
+	for _, seed := range seeds {
+		isActive := seed.IsActive == nil || *seed.IsActive
+
+		current, ok := byName[seed.Name]
+		if !ok {
+			permission := &authorization.Permission{
+				Name:        seed.Name,
+				Resource:    seed.Resource,
+				Action:      seed.Action,
+				Description: seed.Description,
+				IsActive:    isActive,
+			}
+			permission.CreatedBy = s.appliedBy
+			permission.ModifiedBy = s.appliedBy
+			if err := repo.Create(ctx, permission); err != nil {
+				return nil, err
+			}
+			byName[seed.Name] = permission
+			report.PermissionsCreated = append(report.PermissionsCreated, seed.Name)
+			continue
+		}
+
+		if current.Resource == seed.Resource && current.Action == seed.Action &&
+			current.Description == seed.Description && current.IsActive == isActive {
+			continue
+		}
+
+		current.Resource = seed.Resource
+		current.Action = seed.Action
+		current.Description = seed.Description
+		current.IsActive = isActive
+		current.ModifiedBy = s.appliedBy
+		if err := repo.Update(ctx, current); err != nil {
+			return nil, err
+		}
+		report.PermissionsUpdated = append(report.PermissionsUpdated, seed.Name)
+	}
+
+	return byName, nil
+}
+
+// applyRoles creates or updates the declared roles and returns all roles by name
+func (s *Seeder) applyRoles(ctx context.Context, repo authorization.RoleRepository, seeds []RoleSeed, report *SeedReport) (map[string]*authorization.Role, error) {
+	existing, err := repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*authorization.Role, len(existing))
+	for _, r := range existing {
+		byName[r.Name] = r
+	}
+
+	for _, seed := range seeds {
+		isActive := seed.IsActive == nil || *seed.IsActive
+
+		current, ok := byName[seed.Name]
+		if !ok {
+			role := &authorization.Role{
+				Name:        seed.Name,
+				Description: seed.Description,
+				IsActive:    isActive,
+			}
+			role.CreatedBy = s.appliedBy
+			role.ModifiedBy = s.appliedBy
+			if err := repo.Create(ctx, role); err != nil {
+				return nil, err
+			}
+			byName[seed.Name] = role
+			report.RolesCreated = append(report.RolesCreated, seed.Name)
+			continue
+		}
+
+		if current.Description == seed.Description && current.IsActive == isActive {
+			continue
+		}
+
+		current.Description = seed.Description
+		current.IsActive = isActive
+		current.ModifiedBy = s.appliedBy
+		if err := repo.Update(ctx, current); err != nil {
+			return nil, err
+		}
+		report.RolesUpdated = append(report.RolesUpdated, seed.Name)
+	}
+
+	return byName, nil
+}
+
+// applyRolePermissions grants each role the declared permissions it does not have yet
+func (s *Seeder) applyRolePermissions(ctx context.Context, tx *gormio.DB, repo authorization.PermissionRepository, seeds []RoleSeed, roles map[string]*authorization.Role, permissions map[string]*authorization.Permission, report *SeedReport) error {
+	for _, seed := range seeds {
+		role := roles[seed.Name]
+		for _, name := range seed.Permissions {
+			permission, ok := permissions[name]
+			if !ok {
+				return fmt.Errorf("role %q references unknown permission %q", seed.Name, name)
+			}
+
+			exists, err := s.exists(ctx, tx, &authorization.RolePermission{},
+				"role_id = ? AND permission_id = ?", role.GetUUID(), permission.GetUUID())
+			if err != nil {
+				return err
+			}
+			if exists {
+				continue
+			}
+
+			if err := repo.AssignPermissionToRole(ctx, role.GetUUID(), permission.GetUUID(), s.appliedBy); err != nil {
+				return err
+			}
+			report.PermissionsGranted = append(report.PermissionsGranted, seed.Name+":"+name)
+		}
+	}
+
+	return nil
+}
+
+// applyUserRoles assigns each user the declared roles they do not have yet
+func (s *Seeder) applyUserRoles(ctx context.Context, tx *gormio.DB, repo authorization.RoleRepository, seeds []UserRoleSeed, roles map[string]*authorization.Role, report *SeedReport) error {
+	for _, seed := range seeds {
+		for _, name := range seed.Roles {
+			role, ok := roles[name]
+			if !ok {
+				return fmt.Errorf("assignment for user %s references unknown role %q", seed.UserID, name)
+			}
+
+			exists, err := s.exists(ctx, tx, &authorization.UserRole{},
+				"user_id = ? AND role_id = ?", seed.UserID, role.GetUUID())
+			if err != nil {
+				return err
+			}
+			if exists {
+				continue
+			}
+
+			if err := repo.AssignRoleToUser(ctx, seed.UserID, role.GetUUID(), s.appliedBy); err != nil {
+				return err
+			}
+			report.RolesAssigned = append(report.RolesAssigned, seed.UserID.String()+":"+name)
+		}
+	}
+
+	return nil
+}
+
+// exists reports whether a row of model matches the condition
+func (s *Seeder) exists(ctx context.Context, tx *gormio.DB, model interface{}, query string, args ...interface{}) (bool, error) {
+	var count int64
+	if err := tx.WithContext(ctx).Model(model).Where(query, args...).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check existing assignment: %w", err)
+	}
+	return count > 0, nil
+}
+
+// validate checks the definition for missing names and duplicates before anything is written
+func (d *SeedDefinition) validate() error {
+	permissions := make(map[string]bool, len(d.Permissions))
+	for _, p := range d.Permissions {
+		if p.Name == "" || p.Resource == "" || p.Action == "" {
+			return fmt.Errorf("permission seed requires name, resource and action")
+		}
+		if permissions[p.Name] {
+			return fmt.Errorf("duplicate permission %q in seed", p.Name)
+		}
+		permissions[p.Name] = true
+	}
+
+	roles := make(map[string]bool, len(d.Roles))
+	for _, r := range d.Roles {
+		if r.Name == "" {
+			return fmt.Errorf("role seed requires a name")
+		}
+		if roles[r.Name] {
+			return fmt.Errorf("duplicate role %q in seed", r.Name)
+		}
+		roles[r.Name] = true
+	}
+
+	for _, a := range d.Assignments {
+		if a.UserID == uuid.Nil {
+			return fmt.Errorf("assignment seed requires a user_id")
+		}
+	}
+
+	return nil
+}
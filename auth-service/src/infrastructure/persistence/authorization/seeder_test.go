@@ -0,0 +1,134 @@
+package authorization
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"auth-service/src/domain/authorization"
+	"backend-core/logging"
+)
+
+// memoryPermissionRepository keeps permissions in memory and counts the writes
+type memoryPermissionRepository struct {
+	authorization.PermissionRepository
+	permissions []*authorization.Permission
+	writes      int
+}
+
+func (r *memoryPermissionRepository) GetAll(ctx context.Context) ([]*authorization.Permission, error) {
+	return r.permissions, nil
+}
+
+func (r *memoryPermissionRepository) Create(ctx context.Context, permission *authorization.Permission) error {
+	r.permissions = append(r.permissions, permission)
+	r.writes++
+	return nil
+}
+
+func (r *memoryPermissionRepository) Update(ctx context.Context, permission *authorization.Permission) error {
+	r.writes++
+	return nil
+}
+
+// memoryRoleRepository keeps roles in memory and counts the writes
+type memoryRoleRepository struct {
+	authorization.RoleRepository
+	roles  []*authorization.Role
+	writes int
+}
+
+func (r *memoryRoleRepository) GetAll(ctx context.Context) ([]*authorization.Role, error) {
+	return r.roles, nil
+}
+
+func (r *memoryRoleRepository) Create(ctx context.Context, role *authorization.Role) error {
+	r.roles = append(r.roles, role)
+	r.writes++
+	return nil
+}
+
+func (r *memoryRoleRepository) Update(ctx context.Context, role *authorization.Role) error {
+	r.writes++
+	return nil
+}
+
+const testSeedFile = `
+permissions:
+  - name: users.read
+    resource: users
+    action: read
+  - name: users.write
+    resource: users
+    action: write
+    description: Manage users
+roles:
+  - name: admin
+    permissions: [users.read, users.write]
+  - name: auditor
+    is_active: false
+    permissions: [users.read]
+`
+
+func TestSeederSecondRunIsNoOp(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seed.yaml")
+	if err := os.WriteFile(path, []byte(testSeedFile), 0o600); err != nil {
+		t.Fatalf("failed to write seed file: %v", err)
+	}
+	def, err := LoadSeedFile(path)
+	if err != nil {
+		t.Fatalf("LoadSeedFile() error = %v", err)
+	}
+	if err := def.validate(); err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+
+	seeder := &Seeder{logger: logging.NewNopLogger(), appliedBy: "seeder"}
+	permissions := &memoryPermissionRepository{}
+	roles := &memoryRoleRepository{}
+	ctx := context.Background()
+
+	apply := func() *SeedReport {
+		report := &SeedReport{}
+		if _, err := seeder.applyPermissions(ctx, permissions, def.Permissions, report); err != nil {
+			t.Fatalf("applyPermissions() error = %v", err)
+		}
+		if _, err := seeder.applyRoles(ctx, roles, def.Roles, report); err != nil {
+			t.Fatalf("applyRoles() error = %v", err)
+		}
+		return report
+	}
+
+	first := apply()
+	if len(first.PermissionsCreated) != 2 || len(first.RolesCreated) != 2 {
+		t.Fatalf("first run report = %+v, want 2 permissions and 2 roles created", first)
+	}
+	if roles.roles[1].IsActive {
+		t.Error("auditor role created active, want inactive")
+	}
+
+	writes := permissions.writes + roles.writes
+	if second := apply(); second.Changed() {
+		t.Errorf("second run report = %+v, want no changes", second)
+	}
+	if permissions.writes+roles.writes != writes {
+		t.Errorf("second run wrote %d times, want none", permissions.writes+roles.writes-writes)
+	}
+
+	// Changing a declared field updates the entry in place
+	def.Permissions[0].Description = "Read users"
+	if third := apply(); len(third.PermissionsUpdated) != 1 || third.PermissionsUpdated[0] != "users.read" {
+		t.Errorf("run after an edit report = %+v, want users.read updated", third)
+	}
+}
+
+func TestSeedDefinitionRejectsDuplicates(t *testing.T) {
+	def := &SeedDefinition{Permissions: []PermissionSeed{
+		{Name: "users.read", Resource: "users", Action: "read"},
+		{Name: "users.read", Resource: "users", Action: "list"},
+	}}
+	if err := def.validate(); err == nil {
+		t.Error("validate() accepted a duplicate permission")
+	}
+}
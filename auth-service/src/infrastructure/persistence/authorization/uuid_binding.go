@@ -0,0 +1,44 @@
+package authorization
+
+import (
+	"strings"
+
+	gormio "gorm.io/gorm"
+)
+
+// uuidBinding builds the conditions that compare UUID columns with bound uuid.UUID values.
+// The driver binds a uuid.UUID as its canonical text form. On Postgres, where the columns
+// are native uuid, the placeholder is cast to uuid so the parameter is typed as a uuid and
+// the column's index is used. Other dialects store UUIDs in string columns, which the
+// uncast text form compares against directly.
+type uuidBinding struct {
+	cast bool
+}
+
+// newUUIDBinding returns the UUID binding for the dialect of db
+func newUUIDBinding(db *gormio.DB) uuidBinding {
+	return uuidBinding{cast: db != nil && db.Dialector != nil && db.Dialector.Name() == "postgres"}
+}
+
+// placeholder returns the placeholder for one UUID parameter
+func (b uuidBinding) placeholder() string {
+	if b.cast {
+		return "CAST(? AS uuid)"
+	}
+	return "?"
+}
+
+// equals returns the condition comparing column with one UUID parameter
+func (b uuidBinding) equals(column string) string {
+	return column + " = " + b.placeholder()
+}
+
+// in returns the condition matching column against n UUID parameters, which are passed
+// individually rather than as one slice so each gets its own placeholder
+func (b uuidBinding) in(column string, n int) string {
+	placeholders := make([]string, n)
+	for i := range placeholders {
+		placeholders[i] = b.placeholder()
+	}
+	return column + " IN (" + strings.Join(placeholders, ", ") + ")"
+}
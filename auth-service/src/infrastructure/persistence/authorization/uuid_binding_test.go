@@ -0,0 +1,153 @@
+package authorization
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"auth-service/src/domain/authorization"
+	backendGorm "backend-core/database/gorm"
+	"backend-core/logging"
+
+	"github.com/google/uuid"
+	gormio "gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	gormLogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// dryRunDialector builds SQL for the named dialect without a database
+type dryRunDialector struct {
+	name string
+}
+
+func (d dryRunDialector) Name() string { return d.name }
+
+func (d dryRunDialector) Initialize(db *gormio.DB) error {
+	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{})
+	return nil
+}
+
+func (d dryRunDialector) Migrator(db *gormio.DB) gormio.Migrator { return nil }
+
+func (d dryRunDialector) DataTypeOf(field *schema.Field) string {
+	if field.DataType == "" {
+		return "uuid"
+	}
+	return string(field.DataType)
+}
+
+func (d dryRunDialector) DefaultValueOf(field *schema.Field) clause.Expression {
+	return clause.Expr{SQL: "DEFAULT"}
+}
+
+func (d dryRunDialector) BindVarTo(writer clause.Writer, stmt *gormio.Statement, v interface{}) {
+	if d.name == "postgres" {
+		writer.WriteString("$" + strconv.Itoa(len(stmt.Vars)))
+		return
+	}
+	writer.WriteByte('?')
+}
+
+func (d dryRunDialector) QuoteTo(writer clause.Writer, str string) { writer.WriteString(str) }
+
+func (d dryRunDialector) Explain(sql string, vars ...interface{}) string { return sql }
+
+// recordedStatement is the WHERE clause and bound parameters of one statement
+type recordedStatement struct {
+	sql  string
+	vars []interface{}
+}
+
+// newDryRunDB returns a database for dialect that records the WHERE clause of every query
+// and delete. Only the WHERE clause is built, as it does not depend on the model schema.
+func newDryRunDB(t *testing.T, dialect string) (*gormio.DB, *[]recordedStatement) {
+	t.Helper()
+
+	db, err := gormio.Open(dryRunDialector{name: dialect}, &gormio.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               gormLogger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("failed to open dry-run database: %v", err)
+	}
+
+	var statements []recordedStatement
+	record := func(tx *gormio.DB) {
+		tx.Statement.Build("WHERE")
+		statements = append(statements, recordedStatement{sql: tx.Statement.SQL.String(), vars: tx.Statement.Vars})
+		tx.Statement.SQL.Reset()
+		tx.Statement.Vars = nil
+	}
+	if err := db.Callback().Query().Before("gorm:query").Register("test:record", record); err != nil {
+		t.Fatalf("failed to register query callback: %v", err)
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("test:record", record); err != nil {
+		t.Fatalf("failed to register delete callback: %v", err)
+	}
+	return db, &statements
+}
+
+func TestJoinQueriesBindUUIDParameters(t *testing.T) {
+	userID := uuid.New()
+	roleID := uuid.New()
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		dialect string
+		want    string
+	}{
+		{dialect: "postgres", want: "CAST($1 AS uuid)"},
+		// Dialects storing UUIDs in string columns compare against the text form
+		{dialect: "mysql", want: "user_id = ?"},
+	} {
+		t.Run(tt.dialect, func(t *testing.T) {
+			db, statements := newDryRunDB(t, tt.dialect)
+			logger := logging.NewNopLogger()
+			permissions := &permissionRepository{db: db, logger: logger}
+			roles := &roleRepository{
+				GormRepository: backendGorm.NewGormRepository[authorization.Role](&simpleGormWrapper{db: db, logger: logger}, "Role", logger),
+				logger:         logger,
+			}
+
+			permissions.getUserPermissionsFromDB(ctx, userID)
+			permissions.checkUserPermission(ctx, userID, []string{"users"}, "users", "read")
+			roles.GetUserRoles(ctx, userID)
+			roles.RemoveRoleFromUser(ctx, userID, roleID)
+
+			if len(*statements) != 4 {
+				t.Fatalf("recorded %d statements, want 4", len(*statements))
+			}
+			for _, statement := range *statements {
+				if !strings.Contains(statement.sql, tt.want) {
+					t.Errorf("SQL %q does not contain %q", statement.sql, tt.want)
+				}
+				if id, ok := statement.vars[0].(uuid.UUID); !ok || id != userID {
+					t.Errorf("first parameter of %q = %#v, want the user ID as a uuid.UUID", statement.sql, statement.vars[0])
+				}
+			}
+		})
+	}
+}
+
+func TestGetPermissionsForRolesBindsEachRoleID(t *testing.T) {
+	db, statements := newDryRunDB(t, "postgres")
+	repo := &permissionRepository{db: db, logger: logging.NewNopLogger()}
+	first, second := uuid.New(), uuid.New()
+
+	repo.GetPermissionsForRoles(context.Background(), []uuid.UUID{first, second, first})
+
+	if len(*statements) != 1 {
+		t.Fatalf("recorded %d statements, want 1", len(*statements))
+	}
+	statement := (*statements)[0]
+	if !strings.Contains(statement.sql, "rp.role_id IN (CAST($1 AS uuid), CAST($2 AS uuid))") {
+		t.Errorf("SQL %q does not bind each role ID as a uuid", statement.sql)
+	}
+	if len(statement.vars) != 3 || statement.vars[0] != first || statement.vars[1] != second || statement.vars[2] != true {
+		t.Errorf("parameters = %#v, want both role IDs and the is_active flag", statement.vars)
+	}
+}
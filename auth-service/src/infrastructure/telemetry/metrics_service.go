@@ -155,20 +155,21 @@ func createPrometheusBusinessMetrics(registry *prometheus.Registry) (*telemetry.
 	}, []string{"topic"})
 
 	// Business Metrics
-	userCreationsTotal := prometheus.NewCounterVec(prometheus.CounterOpts{
+	// The user counters have no per-user labels, which would create a time series per user
+	userCreationsTotal := prometheus.NewCounter(prometheus.CounterOpts{
 		Name: "user_creations_total",
 		Help: "Total number of user creations",
-	}, []string{"username", "email"})
+	})
 
-	userRetrievalsTotal := prometheus.NewCounterVec(prometheus.CounterOpts{
+	userRetrievalsTotal := prometheus.NewCounter(prometheus.CounterOpts{
 		Name: "user_retrievals_total",
 		Help: "Total number of user retrievals",
-	}, []string{"user_id"})
+	})
 
-	userActivationsTotal := prometheus.NewCounterVec(prometheus.CounterOpts{
+	userActivationsTotal := prometheus.NewCounter(prometheus.CounterOpts{
 		Name: "user_activations_total",
 		Help: "Total number of user activations",
-	}, []string{"user_id"})
+	})
 
 	// System Metrics
 	memoryUsage := prometheus.NewGauge(prometheus.GaugeOpts{
@@ -264,9 +265,9 @@ type prometheusBusinessMetrics struct {
 	kafkaMessagesProduced  *prometheus.CounterVec
 	kafkaMessagesConsumed  *prometheus.CounterVec
 	kafkaPublishDuration   *prometheus.HistogramVec
-	userCreationsTotal     *prometheus.CounterVec
-	userRetrievalsTotal    *prometheus.CounterVec
-	userActivationsTotal   *prometheus.CounterVec
+	userCreationsTotal     prometheus.Counter
+	userRetrievalsTotal    prometheus.Counter
+	userActivationsTotal   prometheus.Counter
 	memoryUsage            prometheus.Gauge
 	cpuUsage               prometheus.Gauge
 	goroutinesCount        prometheus.Gauge
@@ -314,15 +315,15 @@ func (p *prometheusBusinessMetrics) RecordKafkaMessageConsumed(topic string) {
 }
 
 func (p *prometheusBusinessMetrics) RecordUserCreation(username, email string) {
-	p.userCreationsTotal.WithLabelValues(username, email).Inc()
+	p.userCreationsTotal.Inc()
 }
 
 func (p *prometheusBusinessMetrics) RecordUserRetrieval(userID string) {
-	p.userRetrievalsTotal.WithLabelValues(userID).Inc()
+	p.userRetrievalsTotal.Inc()
 }
 
 func (p *prometheusBusinessMetrics) RecordUserActivation(userID string) {
-	p.userActivationsTotal.WithLabelValues(userID).Inc()
+	p.userActivationsTotal.Inc()
 }
 
 func (p *prometheusBusinessMetrics) SetMemoryUsage(bytes float64) {
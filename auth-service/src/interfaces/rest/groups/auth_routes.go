@@ -8,7 +8,8 @@ import (
 
 // AuthRoutes defines authentication-related routes
 type AuthRoutes struct {
-	authHandler *handlers.AuthHandler
+	authHandler   *handlers.AuthHandler
+	databaseGuard gin.HandlerFunc
 }
 
 // NewAuthRoutes creates a new auth routes group
@@ -18,17 +19,30 @@ func NewAuthRoutes(authHandler *handlers.AuthHandler) *AuthRoutes {
 	}
 }
 
+// RequireDatabase runs guard before the routes that read or write users. Logout and token
+// refresh only need the token, so they are not guarded. It must be called before RegisterRoutes.
+func (r *AuthRoutes) RequireDatabase(guard gin.HandlerFunc) {
+	r.databaseGuard = guard
+}
+
 // RegisterRoutes registers all authentication routes
 func (r *AuthRoutes) RegisterRoutes(router *gin.RouterGroup) {
 	auth := router.Group("/auth")
 	{
-		auth.POST("/login", r.authHandler.Login)
-		auth.POST("/register", r.authHandler.Register)
 		auth.POST("/logout", r.authHandler.Logout)
-		auth.POST("/forgot-password", r.authHandler.ForgotPassword)
-		auth.POST("/reset-password", r.authHandler.ResetPassword)
-		auth.POST("/verify-email", r.authHandler.VerifyEmail)
-		auth.POST("/change-password", r.authHandler.ChangePassword)
 		auth.POST("/refresh-token", r.authHandler.RefreshToken)
 	}
+
+	users := auth
+	if r.databaseGuard != nil {
+		users = auth.Group("", r.databaseGuard)
+	}
+	{
+		users.POST("/login", r.authHandler.Login)
+		users.POST("/register", r.authHandler.Register)
+		users.POST("/forgot-password", r.authHandler.ForgotPassword)
+		users.POST("/reset-password", r.authHandler.ResetPassword)
+		users.POST("/verify-email", r.authHandler.VerifyEmail)
+		users.POST("/change-password", r.authHandler.ChangePassword)
+	}
 }
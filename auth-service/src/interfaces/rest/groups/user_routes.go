@@ -11,6 +11,9 @@ import (
 type UserRoutes struct {
 	userHandler     *handlers.UserHandler
 	cacheMiddleware *middleware.CacheMiddleware
+	databaseGuard   gin.HandlerFunc
+	authenticate    gin.HandlerFunc
+	authorize       func(resource, action string) gin.HandlerFunc
 }
 
 // NewUserRoutes creates a new user routes group
@@ -21,26 +24,53 @@ func NewUserRoutes(userHandler *handlers.UserHandler, cacheMiddleware *middlewar
 	}
 }
 
+// RequireDatabase runs guard before every user route, all of which need the database.
+// It must be called before RegisterRoutes.
+func (r *UserRoutes) RequireDatabase(guard gin.HandlerFunc) {
+	r.databaseGuard = guard
+}
+
+// RequirePermissions runs authenticate and then authorize for the permission each user route
+// needs, before the cache so cached responses are not served to unauthorized callers.
+// It must be called before RegisterRoutes.
+func (r *UserRoutes) RequirePermissions(authenticate gin.HandlerFunc, authorize func(resource, action string) gin.HandlerFunc) {
+	r.authenticate = authenticate
+	r.authorize = authorize
+}
+
+// permission returns the handlers checking the caller may perform action on users,
+// followed by handlers. Without RequirePermissions it returns handlers unchanged.
+func (r *UserRoutes) permission(action string, handlers ...gin.HandlerFunc) []gin.HandlerFunc {
+	if r.authenticate == nil || r.authorize == nil {
+		return handlers
+	}
+	return append([]gin.HandlerFunc{r.authenticate, r.authorize("users", action)}, handlers...)
+}
+
 // RegisterRoutes registers all user routes
 func (r *UserRoutes) RegisterRoutes(router *gin.RouterGroup) {
 	userCacheConfig := middleware.UserCacheConfig()
 
+	if r.databaseGuard != nil {
+		router = router.Group("", r.databaseGuard)
+	}
+
 	// Check if cache middleware is available
 	if r.cacheMiddleware != nil {
 		// User routes with cache strategies
-		router.GET("/users/:id", r.cacheMiddleware.CacheGet(userCacheConfig), r.userHandler.GetUser)
-		router.GET("/users", r.cacheMiddleware.CacheList(userCacheConfig), r.userHandler.ListUsers)
+		router.GET("/users/:id", r.permission("read", r.cacheMiddleware.CacheGet(userCacheConfig), r.userHandler.GetUser)...)
+		router.GET("/users", r.permission("read", r.cacheMiddleware.CacheList(userCacheConfig), r.userHandler.ListUsers)...)
 
 		// Apply cache invalidation to mutation endpoints
-		router.POST("/users", r.cacheMiddleware.InvalidateCache(userCacheConfig), r.userHandler.CreateUser)
+		router.POST("/users", r.permission("create", r.cacheMiddleware.InvalidateCache(userCacheConfig), r.userHandler.CreateUser)...)
 		// TODO: Implement UpdateUser and DeleteUser methods in UserHandler
 		// router.PUT("/users/:id", r.cacheMiddleware.InvalidateCache(userCacheConfig), r.userHandler.UpdateUser)
 		// router.DELETE("/users/:id", r.cacheMiddleware.InvalidateCache(userCacheConfig), r.userHandler.DeleteUser)
 	} else {
 		// Fallback to routes without cache middleware
-		router.GET("/users/:id", r.userHandler.GetUser)
-		router.GET("/users", r.userHandler.ListUsers)
-		router.POST("/users", r.userHandler.CreateUser)
+		router.GET("/users/:id", r.permission("read", r.userHandler.GetUser)...)
+		router.GET("/users", r.permission("read", r.userHandler.ListUsers)...)
+		router.POST("/users", r.permission("create", r.userHandler.CreateUser)...)
 		// TODO: Implement UpdateUser and DeleteUser methods in UserHandler
 		// router.PUT("/users/:id", r.userHandler.UpdateUser)
 		// router.DELETE("/users/:id", r.userHandler.DeleteUser)
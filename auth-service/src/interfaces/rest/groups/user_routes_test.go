@@ -0,0 +1,60 @@
+package groups
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestUserRoutesRequirePermissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var checked []string
+	authenticate := func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+		}
+	}
+	authorize := func(resource, action string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			checked = append(checked, resource+":"+action)
+			c.AbortWithStatus(http.StatusForbidden)
+		}
+	}
+
+	routes := NewUserRoutes(nil, nil)
+	routes.RequirePermissions(authenticate, authorize)
+	router := gin.New()
+	routes.RegisterRoutes(router.Group("/api/v1"))
+
+	for _, tc := range []struct {
+		method, path, auth string
+		want               int
+		permission         string
+	}{
+		{http.MethodGet, "/api/v1/users/42", "", http.StatusUnauthorized, ""},
+		{http.MethodGet, "/api/v1/users/42", "Bearer token", http.StatusForbidden, "users:read"},
+		{http.MethodGet, "/api/v1/users", "Bearer token", http.StatusForbidden, "users:read"},
+		{http.MethodPost, "/api/v1/users", "Bearer token", http.StatusForbidden, "users:create"},
+	} {
+		checked = nil
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != tc.want {
+			t.Errorf("%s %s: status = %d, want %d", tc.method, tc.path, rec.Code, tc.want)
+		}
+		if tc.permission == "" && len(checked) != 0 {
+			t.Errorf("%s %s: permissions %v checked for an unauthenticated caller", tc.method, tc.path, checked)
+		}
+		if tc.permission != "" && (len(checked) != 1 || checked[0] != tc.permission) {
+			t.Errorf("%s %s: permissions checked = %v, want [%s]", tc.method, tc.path, checked, tc.permission)
+		}
+	}
+}
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"auth-service/src/applications/services"
+	"auth-service/src/infrastructure/identity/keycloak"
 	"auth-service/src/infrastructure/identity/models"
+	"backend-core/ctxkeys"
 	"backend-core/logging"
 
 	"github.com/gin-gonic/gin"
@@ -191,7 +194,7 @@ func (h *KeycloakHandler) InitiateSSOLogin(c *gin.Context) {
 
 // InitiateMFA handles MFA challenge initiation
 func (h *KeycloakHandler) InitiateMFA(c *gin.Context) {
-	userID := c.GetString("user_id")
+	userID := c.GetString(ctxkeys.UserID)
 	if userID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
 		return
@@ -206,6 +209,42 @@ func (h *KeycloakHandler) InitiateMFA(c *gin.Context) {
 	c.JSON(http.StatusOK, challenge)
 }
 
+// EnrollTOTP enrolls the authenticated user for TOTP MFA. A user who is already enrolled gets
+// 409 unless the request sets "replace", because replacing the secret locks out the
+// authenticator the user has set up.
+func (h *KeycloakHandler) EnrollTOTP(c *gin.Context) {
+	userID, ok := ctxkeys.GetUserID(c)
+	if !ok || userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var req struct {
+		Replace bool `json:"replace"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	secret, otpauthURL, err := h.service.EnrollTOTP(c.Request.Context(), userID, req.Replace)
+	if errors.Is(err, keycloak.ErrMFAAlreadyEnrolled) {
+		c.JSON(http.StatusConflict, gin.H{"error": "TOTP is already enrolled; set replace to enroll a new authenticator"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "TOTP enrollment failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":      secret,
+		"otpauth_url": otpauthURL,
+	})
+}
+
 // VerifyMFA handles MFA verification
 func (h *KeycloakHandler) VerifyMFA(c *gin.Context) {
 	var req struct {
@@ -232,13 +271,15 @@ func (h *KeycloakHandler) HealthCheck(c *gin.Context) {
 	err := h.service.HealthCheck(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{
-			"status": "unhealthy",
-			"error":  err.Error(),
+			"status":          "unhealthy",
+			"error":           err.Error(),
+			"circuit_breaker": h.service.CircuitBreakerState(),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"status": "healthy",
+		"status":          "healthy",
+		"circuit_breaker": h.service.CircuitBreakerState(),
 	})
 }
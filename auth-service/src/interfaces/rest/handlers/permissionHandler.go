@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+
+	"auth-service/src/domain/authorization"
+	"auth-service/src/infrastructure/config"
+	"auth-service/src/infrastructure/identity/keycloak"
+	"backend-core/ctxkeys"
+	"backend-core/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var (
+	errInvalidUserID               = errors.New("invalid user ID")
+	errPermissionSourceUnavailable = errors.New("permission source not configured")
+)
+
+// UserPermissionsResponse is the effective permission set of the authenticated user
+type UserPermissionsResponse struct {
+	UserID      string                 `json:"user_id"`
+	Mode        string                 `json:"mode"`
+	Permissions []string               `json:"permissions"`
+	Roles       []RolePermissionsGroup `json:"roles,omitempty"`
+}
+
+// RolePermissionsGroup lists the permissions one role grants
+type RolePermissionsGroup struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
+
+// PermissionHandler serves the permissions of the authenticated user. They are read from the
+// same source the authorization middleware enforces against for the configured mode: token
+// claims for jwt, the database for jwt_with_db and Keycloak for keycloak.
+type PermissionHandler struct {
+	authConfig      *config.AuthorizationConfig
+	roleRepo        authorization.RoleRepository
+	permissionRepo  authorization.PermissionRepository
+	keycloakAdapter *keycloak.KeycloakAdapter
+	logger          *logging.Logger
+}
+
+// NewPermissionHandler creates a new permission handler. The repositories are only needed in
+// jwt_with_db mode and keycloakAdapter only in keycloak mode; the others may be nil.
+func NewPermissionHandler(
+	authConfig *config.AuthorizationConfig,
+	roleRepo authorization.RoleRepository,
+	permissionRepo authorization.PermissionRepository,
+	keycloakAdapter *keycloak.KeycloakAdapter,
+	logger *logging.Logger,
+) *PermissionHandler {
+	return &PermissionHandler{
+		authConfig:      authConfig,
+		roleRepo:        roleRepo,
+		permissionRepo:  permissionRepo,
+		keycloakAdapter: keycloakAdapter,
+		logger:          logger,
+	}
+}
+
+// GetMyPermissions returns the effective permissions of the authenticated user as sorted
+// "resource:action" strings. With ?group_by=role the permissions granted by each role are
+// listed as well, which is only possible in jwt_with_db mode.
+// @Summary Get current user permissions
+// @Description Get the effective permissions of the authenticated user
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param group_by query string false "Set to 'role' to group permissions by role"
+// @Success 200 {object} UserPermissionsResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /me/permissions [get]
+func (h *PermissionHandler) GetMyPermissions(c *gin.Context) {
+	userID, ok := ctxkeys.GetUserID(c)
+	if !ok || userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	groupByRole := false
+	switch c.Query("group_by") {
+	case "":
+	case "role":
+		groupByRole = true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "group_by must be 'role'"})
+		return
+	}
+
+	mode := h.authConfig.Mode
+	if groupByRole && mode != config.AuthorizationModeJWTWithDB {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Role grouping not available",
+			"message": "Permissions can only be grouped by role in jwt_with_db authorization mode",
+		})
+		return
+	}
+
+	response := &UserPermissionsResponse{
+		UserID: userID,
+		Mode:   string(mode),
+	}
+
+	var err error
+	switch mode {
+	case config.AuthorizationModeJWT:
+		response.Permissions = h.tokenPermissions(c)
+	case config.AuthorizationModeJWTWithDB:
+		err = h.databasePermissions(c.Request.Context(), userID, groupByRole, response)
+	case config.AuthorizationModeKeycloak:
+		response.Permissions, err = h.keycloakPermissions(c, userID)
+	default:
+		h.logger.Error("Unknown authorization mode", logging.String("mode", string(mode)))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authorization mode not configured"})
+		return
+	}
+
+	if err == errInvalidUserID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to get user permissions",
+			logging.Error(err),
+			logging.String("user_id", userID),
+			logging.String("mode", string(mode)))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user permissions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// tokenPermissions returns the permissions carried by the caller's token, normalized from the
+// configured permission format to "resource:action"
+func (h *PermissionHandler) tokenPermissions(c *gin.Context) []string {
+	cfg := h.authConfig.JWTAuth.PermissionFormat
+	format := authorization.NewPermissionFormat(cfg.Separator, cfg.Wildcard, cfg.ActionFirst)
+
+	permissions := ctxkeys.GetPermissions(c)
+	keys := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		resource, action, ok := format.Parse(permission)
+		if !ok {
+			continue
+		}
+		keys = append(keys, authorization.PermissionKey(resource, action))
+	}
+	return sortedUnique(keys)
+}
+
+// databasePermissions fills response from the user's role grants in the database
+func (h *PermissionHandler) databasePermissions(ctx context.Context, userID string, groupByRole bool, response *UserPermissionsResponse) error {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return errInvalidUserID
+	}
+	if h.permissionRepo == nil {
+		return errPermissionSourceUnavailable
+	}
+
+	permissions, err := h.permissionRepo.GetUserPermissions(ctx, userUUID)
+	if err != nil {
+		return err
+	}
+	response.Permissions = permissionKeys(permissions)
+
+	if !groupByRole {
+		return nil
+	}
+	if h.roleRepo == nil {
+		return errPermissionSourceUnavailable
+	}
+
+	roles, err := h.roleRepo.GetUserRoles(ctx, userUUID)
+	if err != nil {
+		return err
+	}
+	roleIDs := make([]uuid.UUID, 0, len(roles))
+	for _, role := range roles {
+		roleIDs = append(roleIDs, role.GetUUID())
+	}
+	byRole, err := h.permissionRepo.GetPermissionsForRoles(ctx, roleIDs)
+	if err != nil {
+		return err
+	}
+
+	response.Roles = make([]RolePermissionsGroup, 0, len(roles))
+	for _, role := range roles {
+		response.Roles = append(response.Roles, RolePermissionsGroup{
+			Role:        role.Name,
+			Permissions: permissionKeys(byRole[role.GetUUID()]),
+		})
+	}
+	sort.Slice(response.Roles, func(i, j int) bool {
+		return response.Roles[i].Role < response.Roles[j].Role
+	})
+	return nil
+}
+
+// keycloakPermissions returns the user's permissions from Keycloak, falling back to the token
+// claims when Keycloak cannot be reached and FallbackToJWT is enabled
+func (h *PermissionHandler) keycloakPermissions(c *gin.Context, userID string) ([]string, error) {
+	if h.keycloakAdapter == nil {
+		if h.authConfig.KeycloakAuth.FallbackToJWT {
+			return h.tokenPermissions(c), nil
+		}
+		return nil, errPermissionSourceUnavailable
+	}
+
+	permissions, err := h.keycloakAdapter.GetUserPermissions(c.Request.Context(), userID)
+	if err != nil {
+		if h.authConfig.KeycloakAuth.FallbackToJWT {
+			h.logger.Warn("Failed to get permissions from Keycloak, falling back to token claims",
+				logging.Error(err),
+				logging.String("user_id", userID))
+			return h.tokenPermissions(c), nil
+		}
+		return nil, err
+	}
+
+	format := authorization.DefaultPermissionFormat()
+	keys := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		resource, action, ok := format.Parse(permission)
+		if !ok {
+			continue
+		}
+		keys = append(keys, authorization.PermissionKey(resource, action))
+	}
+	return sortedUnique(keys), nil
+}
+
+// permissionKeys converts permissions to sorted, de-duplicated "resource:action" strings
+func permissionKeys(permissions []*authorization.Permission) []string {
+	keys := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		keys = append(keys, authorization.PermissionKey(permission.Resource, permission.Action))
+	}
+	return sortedUnique(keys)
+}
+
+// sortedUnique sorts keys and drops duplicates in place
+func sortedUnique(keys []string) []string {
+	sort.Strings(keys)
+	out := keys[:0]
+	for _, key := range keys {
+		if len(out) == 0 || key != out[len(out)-1] {
+			out = append(out, key)
+		}
+	}
+	return out
+}
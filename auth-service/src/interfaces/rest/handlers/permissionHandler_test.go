@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"auth-service/src/domain/authorization"
+	"auth-service/src/infrastructure/config"
+	"backend-core/ctxkeys"
+	"backend-core/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// staticRoleRepository returns a fixed set of roles for every user
+type staticRoleRepository struct {
+	authorization.RoleRepository
+	roles []*authorization.Role
+}
+
+func (r *staticRoleRepository) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]*authorization.Role, error) {
+	return r.roles, nil
+}
+
+// groupedPermissionRepository serves role permissions from a map and counts the batch calls.
+// Per-role lookups are not implemented, so using them fails the test.
+type groupedPermissionRepository struct {
+	authorization.PermissionRepository
+	byRole     map[uuid.UUID][]*authorization.Permission
+	batchCalls int
+}
+
+func (r *groupedPermissionRepository) GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]*authorization.Permission, error) {
+	var permissions []*authorization.Permission
+	for _, rolePermissions := range r.byRole {
+		permissions = append(permissions, rolePermissions...)
+	}
+	return permissions, nil
+}
+
+func (r *groupedPermissionRepository) GetPermissionsForRoles(ctx context.Context, roleIDs []uuid.UUID) (map[uuid.UUID][]*authorization.Permission, error) {
+	r.batchCalls++
+	result := make(map[uuid.UUID][]*authorization.Permission, len(roleIDs))
+	for _, roleID := range roleIDs {
+		result[roleID] = r.byRole[roleID]
+	}
+	return result, nil
+}
+
+func newRole(name string) *authorization.Role {
+	role := &authorization.Role{Name: name}
+	role.SetUUID(uuid.New())
+	return role
+}
+
+func TestGetMyPermissionsGroupsRolesInOneCall(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logging.NewNopLogger()
+
+	admin, editor, viewer := newRole("admin"), newRole("editor"), newRole("viewer")
+	permissions := &groupedPermissionRepository{byRole: map[uuid.UUID][]*authorization.Permission{
+		admin.GetUUID():  {{Resource: "users", Action: "delete"}},
+		editor.GetUUID(): {{Resource: "posts", Action: "write"}, {Resource: "posts", Action: "read"}},
+		viewer.GetUUID(): {{Resource: "posts", Action: "read"}},
+	}}
+	handler := NewPermissionHandler(
+		&config.AuthorizationConfig{Mode: config.AuthorizationModeJWTWithDB},
+		&staticRoleRepository{roles: []*authorization.Role{viewer, admin, editor}},
+		permissions,
+		nil,
+		logger,
+	)
+
+	router := gin.New()
+	router.GET("/me/permissions", func(c *gin.Context) {
+		ctxkeys.SetUserID(c, uuid.NewString())
+	}, handler.GetMyPermissions)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/me/permissions?group_by=role", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if permissions.batchCalls != 1 {
+		t.Errorf("GetPermissionsForRoles called %d times, want 1", permissions.batchCalls)
+	}
+
+	var response UserPermissionsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	want := map[string][]string{
+		"admin":  {"users:delete"},
+		"editor": {"posts:read", "posts:write"},
+		"viewer": {"posts:read"},
+	}
+	if len(response.Roles) != len(want) {
+		t.Fatalf("roles = %+v, want %d groups", response.Roles, len(want))
+	}
+	for i, group := range response.Roles {
+		wantPermissions := want[group.Role]
+		if i > 0 && response.Roles[i-1].Role > group.Role {
+			t.Errorf("roles not sorted: %+v", response.Roles)
+		}
+		if len(group.Permissions) != len(wantPermissions) {
+			t.Errorf("role %s permissions = %v, want %v", group.Role, group.Permissions, wantPermissions)
+			continue
+		}
+		for j := range wantPermissions {
+			if group.Permissions[j] != wantPermissions[j] {
+				t.Errorf("role %s permissions = %v, want %v", group.Role, group.Permissions, wantPermissions)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"auth-service/src/infrastructure/config"
+	"auth-service/src/infrastructure/identity/keycloak"
+	"backend-core/ctxkeys"
+	"backend-core/logging"
+	"backend-core/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Authentication sources recorded under ctxkeys.AuthSource
+const (
+	AuthSourceJWT      = "jwt"
+	AuthSourceKeycloak = "keycloak"
+)
+
+// Identity is the caller identity the AuthenticationMiddleware puts in the request context.
+// Every token type produces the same shape, so authorization middlewares need not care which
+// kind of token the caller presented.
+type Identity struct {
+	UserID      string
+	Username    string
+	Roles       []string
+	Permissions []string
+	Scopes      []string
+	Source      string
+}
+
+// AuthenticationMiddleware validates the bearer token of a request, either a service JWT or a
+// Keycloak access token, and sets the caller identity in the context under the ctxkeys keys:
+// UserID, Username, Roles, Permissions, Scopes and AuthSource. Slices are never nil.
+type AuthenticationMiddleware struct {
+	authConfig      *config.AuthorizationConfig
+	jwtManager      *security.JWTManager
+	keycloakAdapter *keycloak.KeycloakAdapter
+	logger          *logging.Logger
+}
+
+// NewAuthenticationMiddleware creates a new authentication middleware. keycloakAdapter may be
+// nil, in which case only service JWTs are accepted.
+func NewAuthenticationMiddleware(
+	authConfig *config.AuthorizationConfig,
+	jwtManager *security.JWTManager,
+	keycloakAdapter *keycloak.KeycloakAdapter,
+	logger *logging.Logger,
+) *AuthenticationMiddleware {
+	return &AuthenticationMiddleware{
+		authConfig:      authConfig,
+		jwtManager:      jwtManager,
+		keycloakAdapter: keycloakAdapter,
+		logger:          logger,
+	}
+}
+
+// RequireAuth returns a middleware that rejects requests without a valid token and sets the
+// caller identity for the ones with one
+func (m *AuthenticationMiddleware) RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			m.logger.Warn("Missing or malformed Authorization header")
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Authorization header required. Expected: Bearer <token>",
+				"code":  "AUTH_HEADER_MISSING",
+			})
+			c.Abort()
+			return
+		}
+
+		token := strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer "))
+		if token == "" {
+			m.logger.Warn("Empty token")
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Token is required",
+				"code":  "EMPTY_TOKEN",
+			})
+			c.Abort()
+			return
+		}
+
+		identity, err := m.authenticate(c, token)
+		if err != nil {
+			m.logger.Warn("Token authentication failed", logging.Error(err))
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid or expired token",
+				"code":  "INVALID_TOKEN",
+			})
+			c.Abort()
+			return
+		}
+
+		SetIdentity(c, identity)
+
+		m.logger.Debug("Request authenticated",
+			logging.String("user_id", identity.UserID),
+			logging.String("auth_source", identity.Source))
+
+		c.Next()
+	}
+}
+
+// authenticate validates token as a Keycloak access token when Keycloak is the identity
+// provider, falling back to a service JWT when FallbackToJWT is set, and as a service JWT otherwise
+func (m *AuthenticationMiddleware) authenticate(c *gin.Context, token string) (*Identity, error) {
+	if !m.useKeycloak() {
+		return m.authenticateJWT(c, token)
+	}
+
+	identity, err := m.authenticateKeycloak(c, token)
+	if err == nil || !m.authConfig.KeycloakAuth.FallbackToJWT || m.jwtManager == nil {
+		return identity, err
+	}
+
+	m.logger.Debug("Keycloak token verification failed, trying service JWT", logging.Error(err))
+	return m.authenticateJWT(c, token)
+}
+
+// useKeycloak reports whether tokens should be verified with Keycloak
+func (m *AuthenticationMiddleware) useKeycloak() bool {
+	if m.keycloakAdapter == nil {
+		return false
+	}
+	return m.authConfig.IdentityProvider == config.IdentityProviderKeycloak ||
+		m.authConfig.Mode == config.AuthorizationModeKeycloak
+}
+
+// authenticateJWT validates a service JWT. The claims are also kept under ctxkeys.UserClaims
+// for the issuer and audience checks of the authorization middleware.
+func (m *AuthenticationMiddleware) authenticateJWT(c *gin.Context, token string) (*Identity, error) {
+	claims, err := m.jwtManager.ValidateAccessToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := []string{}
+	if claims.Role != "" {
+		roles = append(roles, claims.Role)
+	}
+
+	c.Set(ctxkeys.UserClaims, claims)
+	c.Set(ctxkeys.UserRole, claims.Role)
+
+	return &Identity{
+		UserID:      claims.UserID,
+		Username:    claims.Username,
+		Roles:       roles,
+		Permissions: []string{},
+		Scopes:      []string{},
+		Source:      AuthSourceJWT,
+	}, nil
+}
+
+// authenticateKeycloak verifies a Keycloak access token. The raw claims are also kept under
+// ctxkeys.JWTClaims for the Keycloak role middleware.
+func (m *AuthenticationMiddleware) authenticateKeycloak(c *gin.Context, token string) (*Identity, error) {
+	claims, err := m.keycloakAdapter.VerifyToken(c.Request.Context(), token)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenIdentity := keycloak.IdentityFromClaims(claims, m.keycloakAdapter.ClientID())
+	c.Set(ctxkeys.JWTClaims, claims)
+
+	return &Identity{
+		UserID:      tokenIdentity.Subject,
+		Username:    tokenIdentity.Username,
+		Roles:       tokenIdentity.Roles,
+		Permissions: tokenIdentity.Permissions,
+		Scopes:      tokenIdentity.Scopes,
+		Source:      AuthSourceKeycloak,
+	}, nil
+}
+
+// SetIdentity sets identity in the gin context, and the user ID in the request context for logging
+func SetIdentity(c *gin.Context, identity *Identity) {
+	ctxkeys.SetUserID(c, identity.UserID)
+	c.Set(ctxkeys.Username, identity.Username)
+	c.Set(ctxkeys.Roles, identity.Roles)
+	c.Set(ctxkeys.Permissions, identity.Permissions)
+	c.Set(ctxkeys.Scopes, identity.Scopes)
+	c.Set(ctxkeys.AuthSource, identity.Source)
+
+	c.Request = c.Request.WithContext(ctxkeys.WithUserID(c.Request.Context(), identity.UserID))
+}
+
+// GetIdentity reads the identity set by the AuthenticationMiddleware from c
+func GetIdentity(c *gin.Context) (*Identity, bool) {
+	userID, ok := ctxkeys.GetUserID(c)
+	if !ok {
+		return nil, false
+	}
+	username, _ := c.Get(ctxkeys.Username)
+	source, _ := c.Get(ctxkeys.AuthSource)
+
+	identity := &Identity{
+		UserID:      userID,
+		Roles:       ctxkeys.GetRoles(c),
+		Permissions: ctxkeys.GetPermissions(c),
+		Scopes:      ctxkeys.GetScopes(c),
+	}
+	identity.Username, _ = username.(string)
+	identity.Source, _ = source.(string)
+	return identity, true
+}
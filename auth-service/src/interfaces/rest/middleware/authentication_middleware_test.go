@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"auth-service/src/infrastructure/config"
+	"auth-service/src/infrastructure/identity/keycloak"
+	"backend-core/ctxkeys"
+	"backend-core/logging"
+	"backend-core/security"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// newFakeRealm serves the JWKS of a Keycloak realm called test holding key under kid k1
+func newFakeRealm(t *testing.T, key *rsa.PrivateKey) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/realms/test/protocol/openid-connect/certs", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kid": "k1",
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// authenticate serves one request carrying token through the authentication middleware and
+// returns the status and the identity the next handler saw
+func authenticate(m *AuthenticationMiddleware, token string) (int, map[ctxkeys.Key]interface{}) {
+	gin.SetMode(gin.TestMode)
+	var seen map[ctxkeys.Key]interface{}
+	router := gin.New()
+	router.GET("/resource", m.RequireAuth(), func(c *gin.Context) {
+		seen = make(map[ctxkeys.Key]interface{})
+		for _, key := range []ctxkeys.Key{ctxkeys.UserID, ctxkeys.Username, ctxkeys.Roles, ctxkeys.Permissions, ctxkeys.Scopes, ctxkeys.AuthSource} {
+			if value, exists := c.Get(key); exists {
+				seen[key] = value
+			}
+		}
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Code, seen
+}
+
+func TestAuthenticationSetsTheSameIdentityShape(t *testing.T) {
+	logger := logging.NewNopLogger()
+
+	jwtManager := security.NewJWTManager("secret", time.Minute, "auth-service", "auth-service")
+	jwtToken, err := jwtManager.GenerateAccessToken("user-1", "ann", "admin")
+	if err != nil {
+		t.Fatalf("GenerateAccessToken() error = %v", err)
+	}
+	jwtAuth := NewAuthenticationMiddleware(
+		&config.AuthorizationConfig{Mode: config.AuthorizationModeJWT},
+		jwtManager, nil, logger,
+	)
+
+	signingKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	realm := newFakeRealm(t, signingKey)
+	keycloakConfig := keycloak.KeycloakConfig{BaseURL: realm.URL, Realm: "test", ClientID: "auth-service", ClientSecret: "secret"}
+	client, err := keycloak.NewKeycloakClient(keycloakConfig, logger)
+	if err != nil {
+		t.Fatalf("NewKeycloakClient() error = %v", err)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":                realm.URL + "/realms/test",
+		"aud":                "account",
+		"azp":                "auth-service",
+		"sub":                "user-1",
+		"preferred_username": "ann",
+		"exp":                time.Now().Add(time.Minute).Unix(),
+		"realm_access":       map[string]interface{}{"roles": []string{"admin"}},
+		"scope":              "openid profile",
+	})
+	token.Header["kid"] = "k1"
+	keycloakToken, err := token.SignedString(signingKey)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	keycloakAuth := NewAuthenticationMiddleware(
+		&config.AuthorizationConfig{Mode: config.AuthorizationModeKeycloak},
+		nil, keycloak.NewKeycloakAdapter(client, nil, keycloakConfig, logger), logger,
+	)
+
+	jwtCode, jwtIdentity := authenticate(jwtAuth, jwtToken)
+	keycloakCode, keycloakIdentity := authenticate(keycloakAuth, keycloakToken)
+	if jwtCode != http.StatusOK || keycloakCode != http.StatusOK {
+		t.Fatalf("status = %d for the JWT and %d for the Keycloak token, want %d", jwtCode, keycloakCode, http.StatusOK)
+	}
+
+	for _, identity := range []map[ctxkeys.Key]interface{}{jwtIdentity, keycloakIdentity} {
+		if identity[ctxkeys.UserID] != "user-1" || identity[ctxkeys.Username] != "ann" {
+			t.Errorf("identity = %v, want user-1 ann", identity)
+		}
+		if !reflect.DeepEqual(identity[ctxkeys.Roles], []string{"admin"}) {
+			t.Errorf("roles = %#v, want [admin]", identity[ctxkeys.Roles])
+		}
+		for _, key := range []ctxkeys.Key{ctxkeys.Permissions, ctxkeys.Scopes} {
+			if values, ok := identity[key].([]string); !ok || values == nil {
+				t.Errorf("%s = %#v, want a non-nil []string", key, identity[key])
+			}
+		}
+	}
+	if jwtIdentity[ctxkeys.AuthSource] != AuthSourceJWT || keycloakIdentity[ctxkeys.AuthSource] != AuthSourceKeycloak {
+		t.Errorf("sources = %v and %v, want %s and %s", jwtIdentity[ctxkeys.AuthSource], keycloakIdentity[ctxkeys.AuthSource], AuthSourceJWT, AuthSourceKeycloak)
+	}
+}
+
+func TestAuthenticationRejectsInvalidToken(t *testing.T) {
+	jwtManager := security.NewJWTManager("secret", time.Minute, "auth-service", "auth-service")
+	m := NewAuthenticationMiddleware(&config.AuthorizationConfig{Mode: config.AuthorizationModeJWT}, jwtManager, nil, logging.NewNopLogger())
+
+	if code, identity := authenticate(m, "not-a-token"); code != http.StatusUnauthorized || identity != nil {
+		t.Errorf("status = %d, identity = %v, want %d and no identity", code, identity, http.StatusUnauthorized)
+	}
+}
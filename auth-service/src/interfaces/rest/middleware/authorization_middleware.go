@@ -2,14 +2,14 @@ package middleware
 
 import (
 	"context"
-	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
 
+	"auth-service/src/domain/authorization"
 	"auth-service/src/infrastructure/identity/keycloak"
 	"auth-service/src/infrastructure/identity/pingam"
+	"backend-core/ctxkeys"
 	"backend-core/logging"
 
 	"github.com/gin-gonic/gin"
@@ -17,8 +17,9 @@ import (
 
 // AuthorizationMiddleware handles PingAM authorization checks
 type AuthorizationMiddleware struct {
-	pingamAdapter *pingam.PingAMAdapter
-	logger        *logging.Logger
+	pingamAdapter  *pingam.PingAMAdapter
+	logger         *logging.Logger
+	includeDetails bool
 }
 
 // NewAuthorizationMiddleware creates a new authorization middleware
@@ -29,11 +30,16 @@ func NewAuthorizationMiddleware(pingamAdapter *pingam.PingAMAdapter, logger *log
 	}
 }
 
+// SetIncludeAuthorizationDetails controls whether 403 responses list the user's roles
+func (m *AuthorizationMiddleware) SetIncludeAuthorizationDetails(include bool) {
+	m.includeDetails = include
+}
+
 // RequirePermission returns a middleware that checks if user has required permission
 func (m *AuthorizationMiddleware) RequirePermission(resource, action string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Extract user ID from context (set by authentication middleware)
-		userID, exists := c.Get("user_id")
+		userID, exists := c.Get(ctxkeys.UserID)
 		if !exists {
 			m.logger.Warn("User ID not found in context for authorization check")
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -136,7 +142,7 @@ func (m *AuthorizationMiddleware) RequirePermission(resource, action string) gin
 // RequireAnyPermission checks if user has at least one of the required permissions
 func (m *AuthorizationMiddleware) RequireAnyPermission(permissions map[string]string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userID, exists := c.Get("user_id")
+		userID, exists := c.Get(ctxkeys.UserID)
 		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 			c.Abort()
@@ -146,15 +152,20 @@ func (m *AuthorizationMiddleware) RequireAnyPermission(permissions map[string]st
 		ctx := context.Background()
 		userIDStr := userID.(string)
 
-		// Check each permission
+		// Check every permission in a single batch
+		results, err := m.pingamAdapter.CheckPermissions(ctx, userIDStr, permissionList(permissions))
+		if err != nil {
+			m.logger.Error("Failed to check permissions with PingAM",
+				logging.Error(err),
+				logging.String("user_id", userIDStr))
+		}
 		for resource, action := range permissions {
-			allowed, err := m.pingamAdapter.CheckPermission(ctx, userIDStr, resource, action)
-			if err == nil && allowed {
+			if results[authorization.PermissionKey(resource, action)] {
 				m.logger.Info("Permission granted (any)",
 					logging.String("user_id", userIDStr),
 					logging.String("resource", resource),
 					logging.String("action", action))
-				c.Set("permission_allowed", allowed)
+				c.Set("permission_allowed", true)
 				c.Next()
 				return
 			}
@@ -174,7 +185,7 @@ func (m *AuthorizationMiddleware) RequireAnyPermission(permissions map[string]st
 // RequireAllPermissions checks if user has all of the required permissions
 func (m *AuthorizationMiddleware) RequireAllPermissions(permissions map[string]string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userID, exists := c.Get("user_id")
+		userID, exists := c.Get(ctxkeys.UserID)
 		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 			c.Abort()
@@ -184,10 +195,15 @@ func (m *AuthorizationMiddleware) RequireAllPermissions(permissions map[string]s
 		ctx := context.Background()
 		userIDStr := userID.(string)
 
-		// Check each permission
+		// Check every permission in a single batch
+		results, err := m.pingamAdapter.CheckPermissions(ctx, userIDStr, permissionList(permissions))
+		if err != nil {
+			m.logger.Error("Failed to check permissions with PingAM",
+				logging.Error(err),
+				logging.String("user_id", userIDStr))
+		}
 		for resource, action := range permissions {
-			allowed, err := m.pingamAdapter.CheckPermission(ctx, userIDStr, resource, action)
-			if err != nil || !allowed {
+			if err != nil || !results[authorization.PermissionKey(resource, action)] {
 				m.logger.Warn("Permission denied (all required)",
 					logging.String("user_id", userIDStr),
 					logging.String("resource", resource),
@@ -207,10 +223,19 @@ func (m *AuthorizationMiddleware) RequireAllPermissions(permissions map[string]s
 	}
 }
 
+// permissionList converts a resource to action map into the list batch permission checks take
+func permissionList(permissions map[string]string) []authorization.Permission {
+	list := make([]authorization.Permission, 0, len(permissions))
+	for resource, action := range permissions {
+		list = append(list, authorization.Permission{Resource: resource, Action: action})
+	}
+	return list
+}
+
 // RequireRole checks if user has a specific role
 func (m *AuthorizationMiddleware) RequireRole(role string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userID, exists := c.Get("user_id")
+		userID, exists := c.Get(ctxkeys.UserID)
 		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 			c.Abort()
@@ -245,14 +270,16 @@ func (m *AuthorizationMiddleware) RequireRole(role string) gin.HandlerFunc {
 		if !hasRole {
 			m.logger.Warn("Required role not found",
 				logging.String("user_id", userIDStr),
-				logging.String("required_role", role))
+				logging.String("required_role", role),
+				logging.Any("user_roles", roles))
+			details := gin.H{"required_role": role}
+			if m.includeDetails {
+				details["user_roles"] = roles
+			}
 			c.JSON(http.StatusForbidden, gin.H{
 				"error":   "Forbidden",
 				"message": "Required role not found",
-				"details": gin.H{
-					"required_role": role,
-					"user_roles":    roles,
-				},
+				"details": details,
 			})
 			c.Abort()
 			return
@@ -261,7 +288,7 @@ func (m *AuthorizationMiddleware) RequireRole(role string) gin.HandlerFunc {
 		m.logger.Info("Role check passed",
 			logging.String("user_id", userIDStr),
 			logging.String("role", role))
-		c.Set("user_roles", roles)
+		c.Set(ctxkeys.UserRoles, roles)
 		c.Next()
 	}
 }
@@ -270,6 +297,7 @@ func (m *AuthorizationMiddleware) RequireRole(role string) gin.HandlerFunc {
 type KeycloakAuthorizationMiddleware struct {
 	keycloakAdapter *keycloak.KeycloakAdapter
 	logger          *logging.Logger
+	includeDetails  bool
 }
 
 // NewKeycloakAuthorizationMiddleware creates a new Keycloak authorization middleware
@@ -280,6 +308,11 @@ func NewKeycloakAuthorizationMiddleware(keycloakAdapter *keycloak.KeycloakAdapte
 	}
 }
 
+// SetIncludeAuthorizationDetails controls whether 403 responses list the user's roles
+func (m *KeycloakAuthorizationMiddleware) SetIncludeAuthorizationDetails(include bool) {
+	m.includeDetails = include
+}
+
 // RequireValidKeycloakToken validates Keycloak JWT token and sets claims in context
 func (m *KeycloakAuthorizationMiddleware) RequireValidKeycloakToken() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -319,24 +352,10 @@ func (m *KeycloakAuthorizationMiddleware) RequireValidKeycloakToken() gin.Handle
 			return
 		}
 
-		// For now, we'll assume the token is valid and parse it
-		// In a real implementation, you'd validate the token signature with Keycloak's public key
-		// For this demo, we'll just decode the JWT payload
-		parts := strings.Split(tokenString, ".")
-		if len(parts) != 3 {
-			fmt.Printf("DEBUG: Invalid JWT format\n")
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "Unauthorized",
-				"message": "Invalid token format",
-			})
-			c.Abort()
-			return
-		}
-
-		// Decode the payload (second part)
-		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		// Verify the signature, expiry, issuer and audience against Keycloak's realm keys
+		claims, err := m.keycloakAdapter.VerifyToken(c.Request.Context(), tokenString)
 		if err != nil {
-			fmt.Printf("DEBUG: Failed to decode JWT payload: %v\n", err)
+			m.logger.Warn("Keycloak token verification failed", logging.Error(err))
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":   "Unauthorized",
 				"message": "Invalid token",
@@ -345,20 +364,8 @@ func (m *KeycloakAuthorizationMiddleware) RequireValidKeycloakToken() gin.Handle
 			return
 		}
 
-		// Parse JSON claims
-		var claims map[string]interface{}
-		if err := json.Unmarshal(payload, &claims); err != nil {
-			fmt.Printf("DEBUG: Failed to parse JWT claims: %v\n", err)
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "Unauthorized",
-				"message": "Invalid token claims",
-			})
-			c.Abort()
-			return
-		}
-
 		// Set claims in context for role checking middleware
-		c.Set("jwt_claims", claims)
+		c.Set(ctxkeys.JWTClaims, claims)
 		fmt.Printf("DEBUG: JWT claims set in context\n")
 
 		c.Next()
@@ -372,7 +379,7 @@ func (m *KeycloakAuthorizationMiddleware) RequireKeycloakRole(requiredRoles []st
 		fmt.Printf("DEBUG: Required roles: %v\n", requiredRoles)
 
 		// Extract roles from JWT token claims (set by token validation middleware)
-		claims, exists := c.Get("jwt_claims")
+		claims, exists := ctxkeys.GetJWTClaims(c)
 		if !exists {
 			fmt.Printf("DEBUG: JWT claims not found in context\n")
 			m.logger.Warn("JWT claims not found in context for Keycloak role authorization")
@@ -391,7 +398,7 @@ func (m *KeycloakAuthorizationMiddleware) RequireKeycloakRole(requiredRoles []st
 		var userRoles []string
 
 		// Check realm_access.roles
-		if realmAccess, ok := claims.(map[string]interface{})["realm_access"].(map[string]interface{}); ok {
+		if realmAccess, ok := claims["realm_access"].(map[string]interface{}); ok {
 			if roles, ok := realmAccess["roles"].([]interface{}); ok {
 				for _, role := range roles {
 					if roleStr, ok := role.(string); ok {
@@ -402,7 +409,7 @@ func (m *KeycloakAuthorizationMiddleware) RequireKeycloakRole(requiredRoles []st
 		}
 
 		// Check resource_access.{client}.roles (client-specific roles)
-		if resourceAccess, ok := claims.(map[string]interface{})["resource_access"].(map[string]interface{}); ok {
+		if resourceAccess, ok := claims["resource_access"].(map[string]interface{}); ok {
 			// Look for client-specific roles (client ID should match the one configured)
 			for _, clientRoles := range resourceAccess {
 				if clientRoleMap, ok := clientRoles.(map[string]interface{}); ok {
@@ -436,13 +443,14 @@ func (m *KeycloakAuthorizationMiddleware) RequireKeycloakRole(requiredRoles []st
 				logging.String("required_roles", fmt.Sprintf("%v", requiredRoles)),
 				logging.String("user_roles", fmt.Sprintf("%v", userRoles)))
 
+			details := gin.H{"required_roles": requiredRoles}
+			if m.includeDetails {
+				details["user_roles"] = userRoles
+			}
 			c.JSON(http.StatusForbidden, gin.H{
 				"error":   "Forbidden",
 				"message": "Insufficient permissions - required role not found",
-				"details": gin.H{
-					"required_roles": requiredRoles,
-					"user_roles":     userRoles,
-				},
+				"details": details,
 			})
 			c.Abort()
 			return
@@ -453,7 +461,7 @@ func (m *KeycloakAuthorizationMiddleware) RequireKeycloakRole(requiredRoles []st
 			logging.String("user_roles", fmt.Sprintf("%v", userRoles)))
 
 		// Store role information in context for later use
-		c.Set("user_roles", userRoles)
+		c.Set(ctxkeys.UserRoles, userRoles)
 		c.Set("authorized_roles", requiredRoles)
 
 		c.Next()
@@ -464,7 +472,7 @@ func (m *KeycloakAuthorizationMiddleware) RequireKeycloakRole(requiredRoles []st
 func (m *KeycloakAuthorizationMiddleware) RequireKeycloakPermission(resource, scope string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Extract user ID from JWT claims
-		claims, exists := c.Get("jwt_claims")
+		claims, exists := ctxkeys.GetJWTClaims(c)
 		if !exists {
 			m.logger.Warn("JWT claims not found in context for Keycloak permission check")
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -477,7 +485,7 @@ func (m *KeycloakAuthorizationMiddleware) RequireKeycloakPermission(resource, sc
 
 		// Extract subject (user ID) from claims
 		var userID string
-		if sub, ok := claims.(map[string]interface{})["sub"].(string); ok {
+		if sub, ok := claims["sub"].(string); ok {
 			userID = sub
 		} else {
 			m.logger.Warn("Subject (sub) claim not found in JWT for permission check")
@@ -570,6 +578,24 @@ func (m *KeycloakAuthorizationMiddleware) RequireKeycloakPermission(resource, sc
 	}
 }
 
+// RequireValidSSOState rejects SSO callbacks whose state query parameter was not issued by
+// InitiateSSOLogin, or was already used, protecting the callback against CSRF and replay
+func (m *KeycloakAuthorizationMiddleware) RequireValidSSOState() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := m.keycloakAdapter.ValidateSSOState(c.Request.Context(), c.Query("state")); err != nil {
+			m.logger.Warn("SSO callback rejected", logging.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":             "Invalid State",
+				"error_description": err.Error(),
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // checkKeycloakPermission implements the actual Keycloak Authorization Services call
 // This is a placeholder - implement based on your Keycloak Authorization Services setup
 func (m *KeycloakAuthorizationMiddleware) checkKeycloakPermission(ctx context.Context, accessToken, resource, scope string) (bool, error) {
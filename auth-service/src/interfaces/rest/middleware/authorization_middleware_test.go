@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"auth-service/src/infrastructure/config"
+	"backend-core/ctxkeys"
+	"backend-core/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// deniedDetails returns the details object of a 403 response
+func deniedDetails(t *testing.T, code int, body []byte) map[string]interface{} {
+	t.Helper()
+
+	if code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d", code, http.StatusForbidden)
+	}
+	var response struct {
+		Details map[string]interface{} `json:"details"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	return response.Details
+}
+
+func TestKeycloakRoleDenialIncludesRolesOnlyWhenEnabled(t *testing.T) {
+	withRoles := func(c *gin.Context) {
+		c.Set(ctxkeys.JWTClaims, map[string]interface{}{
+			"realm_access": map[string]interface{}{"roles": []interface{}{"user"}},
+		})
+	}
+
+	for _, include := range []bool{false, true} {
+		m := NewKeycloakAuthorizationMiddleware(nil, logging.NewNopLogger())
+		m.SetIncludeAuthorizationDetails(include)
+
+		rec := serveAuthorized(withRoles, m.RequireKeycloakRole([]string{"admin"}))
+
+		details := deniedDetails(t, rec.Code, rec.Body.Bytes())
+		if _, listed := details["user_roles"]; listed != include {
+			t.Errorf("with details %v, user_roles listed = %v", include, listed)
+		}
+	}
+}
+
+func TestUnifiedPermissionDenialIncludesPermissionsOnlyWhenEnabled(t *testing.T) {
+	withPermissions := func(c *gin.Context) {
+		c.Set(ctxkeys.AuthSource, AuthSourceJWT)
+		c.Set(ctxkeys.UserID, "user-1")
+		c.Set(ctxkeys.Permissions, []string{"users:write"})
+	}
+
+	for _, include := range []bool{false, true} {
+		m := NewUnifiedAuthorizationMiddleware(&config.AuthorizationConfig{
+			Enabled:                     true,
+			Mode:                        config.AuthorizationModeJWT,
+			IncludeAuthorizationDetails: include,
+		}, nil, nil, nil, nil, logging.NewNopLogger())
+
+		rec := serveAuthorized(withPermissions, m.RequirePermission("users", "read"))
+
+		details := deniedDetails(t, rec.Code, rec.Body.Bytes())
+		if _, listed := details["your_permissions"]; listed != include {
+			t.Errorf("with details %v, your_permissions listed = %v", include, listed)
+		}
+	}
+}
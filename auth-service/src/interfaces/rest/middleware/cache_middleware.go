@@ -2,11 +2,14 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"backend-core/cache"
 	"backend-core/cache/decorators"
+	"backend-core/ctxkeys"
 	"backend-core/logging"
 
 	"github.com/gin-gonic/gin"
@@ -21,9 +24,16 @@ type CacheConfig struct {
 	ListTTL      time.Duration // Time to live for cached lists
 }
 
+// CacheAdmin invalidates cache entries for the admin cache endpoint.
+// *decorators.CacheDecorator implements it.
+type CacheAdmin interface {
+	InvalidateNamespace(ctx context.Context, pattern string) error
+}
+
 // CacheMiddleware provides generic cache functionality for any resource
 type CacheMiddleware struct {
 	cacheDecorator *decorators.CacheDecorator
+	admin          CacheAdmin
 	logger         *logging.Logger
 }
 
@@ -31,10 +41,19 @@ type CacheMiddleware struct {
 func NewCacheMiddleware(cacheDecorator *decorators.CacheDecorator, logger *logging.Logger) *CacheMiddleware {
 	return &CacheMiddleware{
 		cacheDecorator: cacheDecorator,
+		admin:          cacheDecorator,
 		logger:         logger,
 	}
 }
 
+// Close closes the cache connection
+func (m *CacheMiddleware) Close() error {
+	if m.cacheDecorator == nil {
+		return nil
+	}
+	return m.cacheDecorator.Close()
+}
+
 // CacheGet provides generic cache for GET operations by ID
 func (m *CacheMiddleware) CacheGet(config CacheConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -323,6 +342,60 @@ func (m *CacheMiddleware) CacheClearHandler() gin.HandlerFunc {
 	}
 }
 
+// CacheInvalidateRequest is the body of the cache invalidation endpoint
+type CacheInvalidateRequest struct {
+	// Pattern selects the entries to invalidate and must start with a namespace, e.g. "users:*"
+	Pattern string `json:"pattern" binding:"required"`
+}
+
+// CacheInvalidateHandler invalidates the cache entries matching a namespace pattern. The entries
+// live in the Redis server the replicas share, so no replica reads them afterwards. Patterns
+// without a namespace are rejected.
+func (m *CacheMiddleware) CacheInvalidateHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req CacheInvalidateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"error":   "Request body must contain a pattern",
+			})
+			return
+		}
+
+		if err := m.admin.InvalidateNamespace(c.Request.Context(), req.Pattern); err != nil {
+			if errors.Is(err, cache.ErrUnsafePattern) {
+				c.JSON(400, gin.H{
+					"success": false,
+					"error":   err.Error(),
+				})
+				return
+			}
+			m.logger.Error("Failed to invalidate cache pattern",
+				logging.String("pattern", req.Pattern),
+				logging.Error(err))
+			c.JSON(500, gin.H{
+				"success": false,
+				"error":   "Failed to invalidate cache",
+			})
+			return
+		}
+
+		var subject string
+		if claims, ok := ctxkeys.GetJWTClaims(c); ok {
+			subject, _ = claims["sub"].(string)
+		}
+		m.logger.Info("Cache pattern invalidated by admin",
+			logging.String("pattern", req.Pattern),
+			logging.String("subject", subject))
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Cache invalidated successfully",
+			"pattern": req.Pattern,
+		})
+	}
+}
+
 // CacheResponseWriter is a custom response writer that captures the response
 type CacheResponseWriter struct {
 	gin.ResponseWriter
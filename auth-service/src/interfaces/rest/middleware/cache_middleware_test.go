@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"backend-core/cache"
+	"backend-core/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeCacheAdmin records the patterns it is given and fails with err
+type fakeCacheAdmin struct {
+	err      error
+	patterns []string
+}
+
+func (a *fakeCacheAdmin) InvalidateNamespace(ctx context.Context, pattern string) error {
+	a.patterns = append(a.patterns, pattern)
+	return a.err
+}
+
+// serveCacheAdmin posts body to handler and returns the response
+func serveCacheAdmin(handler gin.HandlerFunc, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/admin/cache", handler)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/admin/cache", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCacheInvalidateHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		err          error
+		wantStatus   int
+		wantPatterns []string
+	}{
+		{"namespace pattern", `{"pattern":"users:*"}`, nil, http.StatusOK, []string{"users:*"}},
+		{"unsafe pattern", `{"pattern":"*"}`, cache.ErrUnsafePattern, http.StatusBadRequest, []string{"*"}},
+		{"cache failure", `{"pattern":"users:*"}`, errors.New("connection refused"), http.StatusInternalServerError, []string{"users:*"}},
+		{"missing pattern", `{}`, nil, http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			admin := &fakeCacheAdmin{err: tt.err}
+			m := &CacheMiddleware{admin: admin, logger: logging.NewNopLogger()}
+
+			rec := serveCacheAdmin(m.CacheInvalidateHandler(), tt.body)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if !reflect.DeepEqual(admin.patterns, tt.wantPatterns) {
+				t.Errorf("invalidated patterns = %q, want %q", admin.patterns, tt.wantPatterns)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"backend-core/ctxkeys"
 	"backend-core/logging"
 	"net/http"
 
@@ -14,7 +15,7 @@ func CasbinHandler() gin.HandlerFunc {
 		// For now, just allow all authenticated users
 
 		// Get user ID from context (set by JWT middleware)
-		userID, exists := c.Get("user_id")
+		userID, exists := c.Get(ctxkeys.UserID)
 		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 			c.Abort()
@@ -40,7 +41,7 @@ func CasbinHandler() gin.HandlerFunc {
 func CasbinHandlerWithEnforcer(enforcer interface{}, logger *logging.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get user ID from context (set by JWT middleware)
-		userID, exists := c.Get("user_id")
+		userID, exists := c.Get(ctxkeys.UserID)
 		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 			c.Abort()
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// degradedRetryAfter is the Retry-After sent with 503s from endpoints unavailable in degraded mode
+const degradedRetryAfter = 30 * time.Second
+
+// DegradedMode records whether auth-service is running without its database. Endpoints that
+// need the database are then refused with 503 instead of serving from a fallback that does
+// not persist anything.
+type DegradedMode struct {
+	reason string
+}
+
+// NewDegradedMode creates the degraded mode flag. An empty reason means the service is not degraded.
+func NewDegradedMode(reason string) *DegradedMode {
+	return &DegradedMode{reason: reason}
+}
+
+// Enabled reports whether the service is running in degraded mode
+func (d *DegradedMode) Enabled() bool {
+	return d != nil && d.reason != ""
+}
+
+// Reason returns why the service is degraded, or "" if it is not
+func (d *DegradedMode) Reason() string {
+	if d == nil {
+		return ""
+	}
+	return d.reason
+}
+
+// RequireDatabase refuses requests with 503 while the service is in degraded mode
+func (d *DegradedMode) RequireDatabase() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !d.Enabled() {
+			c.Next()
+			return
+		}
+		c.Header("Retry-After", strconv.Itoa(int(degradedRetryAfter.Seconds())))
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"success": false,
+			"error":   "Service degraded",
+			"message": "This endpoint requires the database, which is unavailable: " + d.reason,
+		})
+	}
+}
+
+// ReadinessHandler reports whether the service is ready. A degraded service is still ready,
+// since its stateless endpoints work, but reports status "degraded" and why.
+func (d *DegradedMode) ReadinessHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !d.Enabled() {
+			c.JSON(http.StatusOK, gin.H{
+				"status":   "ready",
+				"degraded": false,
+			})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"status":   "degraded",
+			"degraded": true,
+			"reason":   d.reason,
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 
+	"backend-core/ctxkeys"
 	"backend-core/logging"
 	"backend-core/security"
 
@@ -77,10 +78,11 @@ func JWTAuthMiddleware(jwtManager *security.JWTManager, logger *logging.Logger)
 		}
 
 		// Set user information in context
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("user_role", claims.Role)
-		c.Set("user_claims", claims)
+		ctxkeys.SetUserID(c, claims.UserID)
+		c.Set(ctxkeys.Username, claims.Username)
+		c.Set(ctxkeys.UserRole, claims.Role)
+		c.Set(ctxkeys.UserClaims, claims)
+		c.Set(ctxkeys.AuthSource, AuthSourceJWT)
 
 		logger.Debug("JWT authentication successful",
 			logging.String("user_id", claims.UserID),
@@ -120,10 +122,11 @@ func JWTAuthOptional(jwtManager *security.JWTManager, logger *logging.Logger) gi
 		}
 
 		// Valid token, set user info
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("user_role", claims.Role)
-		c.Set("user_claims", claims)
+		ctxkeys.SetUserID(c, claims.UserID)
+		c.Set(ctxkeys.Username, claims.Username)
+		c.Set(ctxkeys.UserRole, claims.Role)
+		c.Set(ctxkeys.UserClaims, claims)
+		c.Set(ctxkeys.AuthSource, AuthSourceJWT)
 
 		logger.Debug("Optional JWT authentication successful",
 			logging.String("user_id", claims.UserID))
@@ -135,7 +138,7 @@ func JWTAuthOptional(jwtManager *security.JWTManager, logger *logging.Logger) gi
 // RequireRole middleware ensures user has specific role
 func RequireRole(requiredRole string, logger *logging.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		claims, exists := c.Get("user_claims")
+		claims, exists := c.Get(ctxkeys.UserClaims)
 		if !exists {
 			logger.Warn("User claims not found in context")
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -179,7 +182,7 @@ func RequireRole(requiredRole string, logger *logging.Logger) gin.HandlerFunc {
 // RequireAnyRole middleware ensures user has at least one of the specified roles
 func RequireAnyRole(allowedRoles []string, logger *logging.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		claims, exists := c.Get("user_claims")
+		claims, exists := c.Get(ctxkeys.UserClaims)
 		if !exists {
 			logger.Warn("User claims not found in context")
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -230,7 +233,7 @@ func RequireAnyRole(allowedRoles []string, logger *logging.Logger) gin.HandlerFu
 
 // GetUserClaims helper function to extract user claims from context
 func GetUserClaims(c *gin.Context) (*security.Claims, error) {
-	claims, exists := c.Get("user_claims")
+	claims, exists := c.Get(ctxkeys.UserClaims)
 	if !exists {
 		return nil, ErrUserClaimsNotFound
 	}
@@ -245,12 +248,11 @@ func GetUserClaims(c *gin.Context) (*security.Claims, error) {
 
 // GetUserID helper function to extract user ID from context
 func GetUserID(c *gin.Context) (string, error) {
-	userID, exists := c.Get("user_id")
-	if !exists {
+	if _, exists := c.Get(ctxkeys.UserID); !exists {
 		return "", ErrUserIDNotFound
 	}
 
-	id, ok := userID.(string)
+	id, ok := ctxkeys.GetUserID(c)
 	if !ok {
 		return "", ErrInvalidUserID
 	}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"time"
 
+	"backend-core/ctxkeys"
 	"backend-core/logging"
 
 	"github.com/gin-gonic/gin"
@@ -57,7 +58,7 @@ func (m *LoggingMiddleware) RequestLogging() gin.HandlerFunc {
 			logging.Int("status", responseWriter.statusCode),
 			logging.Duration("duration", duration),
 			logging.Int("response_size", len(responseWriter.body)),
-			logging.String("request_id", c.GetString("request_id")),
+			logging.String("request_id", c.GetString(ctxkeys.RequestID)),
 		)
 
 		// Log request body for debugging (if enabled)
@@ -97,8 +98,8 @@ func (m *LoggingMiddleware) AuditLogging() gin.HandlerFunc {
 				logging.String("ip", c.ClientIP()),
 				logging.String("user_agent", c.Request.UserAgent()),
 				logging.Int("status", c.Writer.Status()),
-				logging.String("user_id", c.GetString("user_id")),
-				logging.String("request_id", c.GetString("request_id")),
+				logging.String("user_id", c.GetString(ctxkeys.UserID)),
+				logging.String("request_id", c.GetString(ctxkeys.RequestID)),
 				logging.Time("timestamp", time.Now()),
 			)
 		}
@@ -140,7 +141,7 @@ func (m *LoggingMiddleware) PerformanceLogging() gin.HandlerFunc {
 			logging.String("method", c.Request.Method),
 			logging.String("path", c.Request.URL.Path),
 			logging.Duration("duration", duration),
-			logging.String("request_id", c.GetString("request_id")),
+			logging.String("request_id", c.GetString(ctxkeys.RequestID)),
 		)
 
 		// Log slow requests
@@ -149,7 +150,7 @@ func (m *LoggingMiddleware) PerformanceLogging() gin.HandlerFunc {
 				logging.String("method", c.Request.Method),
 				logging.String("path", c.Request.URL.Path),
 				logging.Duration("duration", duration),
-				logging.String("request_id", c.GetString("request_id")),
+				logging.String("request_id", c.GetString(ctxkeys.RequestID)),
 			)
 		}
 	}
@@ -3,6 +3,8 @@ package middleware
 import (
 	"time"
 
+	"backend-core/ctxkeys"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -12,8 +14,8 @@ func OperationRecord() gin.HandlerFunc {
 		start := time.Now()
 
 		// Get user information from context
-		userID, _ := c.Get("user_id")
-		userEmail, _ := c.Get("user_email")
+		userID, _ := c.Get(ctxkeys.UserID)
+		userEmail, _ := c.Get(ctxkeys.UserEmail)
 
 		// Log operation start
 		// TODO: Pass logger from context or create a global logger
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"auth-service/src/applications/services"
+	"backend-core/ctxkeys"
 	"backend-core/logging"
 
 	"github.com/gin-gonic/gin"
@@ -54,10 +55,10 @@ func PingAMAuth(pingamService *services.PingAMApplicationService, logger *loggin
 		}
 
 		// Set user context
-		c.Set("user_id", session.UserID)
-		c.Set("session_id", session.ID)
-		c.Set("session", session)
-		c.Set("auth_source", "pingam")
+		c.Set(ctxkeys.UserID, session.UserID)
+		c.Set(ctxkeys.SessionID, session.ID)
+		c.Set(ctxkeys.Session, session)
+		c.Set(ctxkeys.AuthSource, "pingam")
 
 		logger.Debug("User authenticated with PingAM",
 			logging.String("user_id", session.UserID),
@@ -71,7 +72,7 @@ func PingAMAuth(pingamService *services.PingAMApplicationService, logger *loggin
 func PingAMAuthz(pingamService *services.PingAMApplicationService, resource, action string, logger *logging.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get user from context
-		userID, exists := c.Get("user_id")
+		userID, exists := c.Get(ctxkeys.UserID)
 		if !exists {
 			logger.Error("User not authenticated")
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -128,11 +129,12 @@ func PingAMAuthz(pingamService *services.PingAMApplicationService, resource, act
 	}
 }
 
-// PingAMRoleAuthz middleware for role-based authorization
-func PingAMRoleAuthz(pingamService *services.PingAMApplicationService, requiredRoles []string, logger *logging.Logger) gin.HandlerFunc {
+// PingAMRoleAuthz middleware for role-based authorization.
+// includeDetails controls whether 403 responses list the user's roles.
+func PingAMRoleAuthz(pingamService *services.PingAMApplicationService, requiredRoles []string, includeDetails bool, logger *logging.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get user from context
-		userID, exists := c.Get("user_id")
+		userID, exists := c.Get(ctxkeys.UserID)
 		if !exists {
 			logger.Error("User not authenticated")
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -177,19 +179,22 @@ func PingAMRoleAuthz(pingamService *services.PingAMApplicationService, requiredR
 				logging.String("user_id", userID.(string)),
 				logging.Any("user_roles", userRoles),
 				logging.Any("required_roles", requiredRoles))
-			c.JSON(http.StatusForbidden, gin.H{
+			body := gin.H{
 				"error":          "Insufficient role permissions",
 				"code":           "INSUFFICIENT_ROLE_PERMISSIONS",
 				"user_id":        userID,
-				"user_roles":     userRoles,
 				"required_roles": requiredRoles,
-			})
+			}
+			if includeDetails {
+				body["user_roles"] = userRoles
+			}
+			c.JSON(http.StatusForbidden, body)
 			c.Abort()
 			return
 		}
 
 		// Set role context
-		c.Set("user_roles", userRoles)
+		c.Set(ctxkeys.UserRoles, userRoles)
 		c.Set("required_roles", requiredRoles)
 
 		logger.Debug("User authorized with required role",
@@ -201,11 +206,12 @@ func PingAMRoleAuthz(pingamService *services.PingAMApplicationService, requiredR
 	}
 }
 
-// PingAMPermissionAuthz middleware for permission-based authorization
-func PingAMPermissionAuthz(pingamService *services.PingAMApplicationService, requiredPermissions []string, logger *logging.Logger) gin.HandlerFunc {
+// PingAMPermissionAuthz middleware for permission-based authorization.
+// includeDetails controls whether 403 responses list the user's permissions.
+func PingAMPermissionAuthz(pingamService *services.PingAMApplicationService, requiredPermissions []string, includeDetails bool, logger *logging.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get user from context
-		userID, exists := c.Get("user_id")
+		userID, exists := c.Get(ctxkeys.UserID)
 		if !exists {
 			logger.Error("User not authenticated")
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -255,20 +261,23 @@ func PingAMPermissionAuthz(pingamService *services.PingAMApplicationService, req
 				logging.Any("user_permissions", userPermissions),
 				logging.Any("required_permissions", requiredPermissions),
 				logging.Any("missing_permissions", missingPermissions))
-			c.JSON(http.StatusForbidden, gin.H{
+			body := gin.H{
 				"error":                "Insufficient permissions",
 				"code":                 "INSUFFICIENT_PERMISSIONS",
 				"user_id":              userID,
-				"user_permissions":     userPermissions,
 				"required_permissions": requiredPermissions,
 				"missing_permissions":  missingPermissions,
-			})
+			}
+			if includeDetails {
+				body["user_permissions"] = userPermissions
+			}
+			c.JSON(http.StatusForbidden, body)
 			c.Abort()
 			return
 		}
 
 		// Set permission context
-		c.Set("user_permissions", userPermissions)
+		c.Set(ctxkeys.UserPermissions, userPermissions)
 		c.Set("required_permissions", requiredPermissions)
 
 		logger.Debug("User authorized with required permissions",
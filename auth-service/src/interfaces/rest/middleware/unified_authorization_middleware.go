@@ -3,10 +3,13 @@ package middleware
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"auth-service/src/domain/authorization"
+	"auth-service/src/infrastructure/audit"
 	"auth-service/src/infrastructure/config"
 	"auth-service/src/infrastructure/identity/keycloak"
+	"backend-core/ctxkeys"
 	"backend-core/logging"
 	"backend-core/security"
 
@@ -21,6 +24,7 @@ type UnifiedAuthorizationMiddleware struct {
 	keycloakAdapter *keycloak.KeycloakAdapter
 	roleRepo        authorization.RoleRepository
 	permissionRepo  authorization.PermissionRepository
+	auditLogger     *audit.AuditLogger
 	logger          *logging.Logger
 }
 
@@ -43,6 +47,11 @@ func NewUnifiedAuthorizationMiddleware(
 	}
 }
 
+// SetAuditLogger makes the middleware record every allow/deny decision with auditLogger
+func (m *UnifiedAuthorizationMiddleware) SetAuditLogger(auditLogger *audit.AuditLogger) {
+	m.auditLogger = auditLogger
+}
+
 // RequirePermission checks if user has required permission based on configured mode
 func (m *UnifiedAuthorizationMiddleware) RequirePermission(resource, action string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -53,8 +62,19 @@ func (m *UnifiedAuthorizationMiddleware) RequirePermission(resource, action stri
 			return
 		}
 
+		decision := &authorization.AuthorizationDecision{
+			Check:    authorization.CheckPermission,
+			Resource: resource,
+			Action:   action,
+		}
+
+		if !m.requireAuthenticationContext(c) {
+			m.recordDecision(c, decision, false)
+			return
+		}
+
 		// Get user_id from context (set by JWT auth middleware)
-		userID, exists := c.Get("user_id")
+		userID, exists := c.Get(ctxkeys.UserID)
 		if !exists {
 			m.logger.Warn("User ID not found in context for authorization check")
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -62,6 +82,7 @@ func (m *UnifiedAuthorizationMiddleware) RequirePermission(resource, action stri
 				"message": "User not authenticated",
 			})
 			c.Abort()
+			m.recordDecision(c, decision, false)
 			return
 		}
 
@@ -72,8 +93,10 @@ func (m *UnifiedAuthorizationMiddleware) RequirePermission(resource, action stri
 				"error": "Internal Server Error",
 			})
 			c.Abort()
+			m.recordDecision(c, decision, false)
 			return
 		}
+		decision.UserID = userIDStr
 
 		// Route to appropriate authorization method based on mode
 		m.logger.Info("Authorization mode check",
@@ -81,13 +104,14 @@ func (m *UnifiedAuthorizationMiddleware) RequirePermission(resource, action stri
 			logging.String("resource", resource),
 			logging.String("action", action))
 
+		allowed := false
 		switch m.authConfig.Mode {
 		case config.AuthorizationModeJWT:
-			m.handleJWTAuthorization(c, userIDStr, resource, action)
+			allowed = m.handleJWTAuthorization(c, userIDStr, resource, action)
 		case config.AuthorizationModeJWTWithDB:
-			m.handleJWTWithDBAuthorization(c, userIDStr, resource, action)
+			allowed = m.handleJWTWithDBAuthorization(c, userIDStr, resource, action)
 		case config.AuthorizationModeKeycloak:
-			m.handleKeycloakAuthorization(c, userIDStr, resource, action)
+			allowed = m.handleKeycloakAuthorization(c, userIDStr, resource, action)
 		default:
 			m.logger.Error("Unknown authorization mode", logging.String("mode", string(m.authConfig.Mode)))
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -95,18 +119,54 @@ func (m *UnifiedAuthorizationMiddleware) RequirePermission(resource, action stri
 			})
 			c.Abort()
 		}
+
+		m.recordDecision(c, decision, allowed)
+		if allowed {
+			c.Next()
+		}
 	}
 }
 
-// handleJWTAuthorization checks permissions from JWT claims only
-func (m *UnifiedAuthorizationMiddleware) handleJWTAuthorization(c *gin.Context, userID, resource, action string) {
+// recordDecision completes decision from the request and hands it to the audit logger, if any.
+// It must run before the next handler so the status code is the one of a denial, not of the
+// downstream response.
+func (m *UnifiedAuthorizationMiddleware) recordDecision(c *gin.Context, decision *authorization.AuthorizationDecision, allowed bool) {
+	if m.auditLogger == nil {
+		return
+	}
+
+	decision.Mode = string(m.authConfig.Mode)
+	decision.Decision = authorization.DecisionDeny
+	decision.StatusCode = c.Writer.Status()
+	if allowed {
+		decision.Decision = authorization.DecisionAllow
+		decision.StatusCode = http.StatusOK
+	}
+	decision.RequestID = ctxkeys.GetRequestID(c)
+	decision.CorrelationID = ctxkeys.GetCorrelationID(c)
+	decision.Method = c.Request.Method
+	decision.Path = c.FullPath()
+	decision.Timestamp = time.Now().UTC()
+
+	m.auditLogger.Log(decision)
+}
+
+// handleJWTAuthorization checks permissions from JWT claims only.
+// Like the other handle functions it reports whether access is granted, having already
+// written the error response when it is not.
+func (m *UnifiedAuthorizationMiddleware) handleJWTAuthorization(c *gin.Context, userID, resource, action string) bool {
 	m.logger.Info("Checking permission using JWT claims",
 		logging.String("user_id", userID),
 		logging.String("resource", resource),
 		logging.String("action", action))
 
+	// Re-check the token was minted for this service before trusting its claims
+	if !m.verifyTokenAudience(c, userID) {
+		return false
+	}
+
 	// Get permissions from context (set by JWT middleware)
-	permissions, exists := c.Get("permissions")
+	permissions, exists := c.Get(ctxkeys.Permissions)
 	if !exists || permissions == nil {
 		m.logger.Warn("No permissions found in JWT token",
 			logging.String("user_id", userID))
@@ -115,7 +175,7 @@ func (m *UnifiedAuthorizationMiddleware) handleJWTAuthorization(c *gin.Context,
 			"message": "No permissions found in token",
 		})
 		c.Abort()
-		return
+		return false
 	}
 
 	// Convert permissions to slice
@@ -126,40 +186,112 @@ func (m *UnifiedAuthorizationMiddleware) handleJWTAuthorization(c *gin.Context,
 			"error": "Invalid token permissions format",
 		})
 		c.Abort()
-		return
+		return false
 	}
 
 	// Check if required permission exists
-	if !hasPermission(permSlice, resource, action) {
+	format := m.permissionFormat()
+	required := format.Format(resource, action)
+	if !format.Allows(permSlice, resource, action) {
 		m.logger.Warn("Permission denied (JWT)",
 			logging.String("user_id", userID),
-			logging.String("required", resource+":"+action))
+			logging.String("required", required),
+			logging.Any("user_permissions", permSlice))
+		details := gin.H{"required_permission": required}
+		if m.authConfig.IncludeAuthorizationDetails {
+			details["your_permissions"] = permSlice
+		}
 		c.JSON(http.StatusForbidden, gin.H{
 			"error":   "Forbidden",
 			"message": "You do not have permission to perform this action",
-			"details": gin.H{
-				"required_permission": resource + ":" + action,
-				"your_permissions":    permSlice,
-			},
+			"details": details,
 		})
 		c.Abort()
-		return
+		return false
 	}
 
 	m.logger.Info("Permission granted (JWT)",
 		logging.String("user_id", userID),
-		logging.String("permission", resource+":"+action))
-	c.Set("authorization_mode", "jwt")
-	c.Next()
+		logging.String("permission", required))
+	c.Set(ctxkeys.AuthorizationMode, "jwt")
+	return true
+}
+
+// verifyTokenAudience checks the issuer and audience of the JWT claims set by the JWT
+// auth middleware, aborting with 401 when they are missing or do not match
+func (m *UnifiedAuthorizationMiddleware) verifyTokenAudience(c *gin.Context, userID string) bool {
+	if m.jwtManager == nil {
+		return true
+	}
+
+	var claims *security.Claims
+	if value, exists := c.Get(ctxkeys.UserClaims); exists {
+		claims, _ = value.(*security.Claims)
+	}
+
+	if err := m.jwtManager.VerifyIssuerAndAudience(claims); err != nil {
+		m.logger.Warn("JWT rejected at authorization",
+			logging.String("user_id", userID),
+			logging.Error(err))
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "Token is not valid for this service",
+		})
+		c.Abort()
+		return false
+	}
+
+	return true
+}
+
+// permissionFormat returns the configured convention for permission strings in tokens
+func (m *UnifiedAuthorizationMiddleware) permissionFormat() authorization.PermissionFormat {
+	cfg := m.authConfig.JWTAuth.PermissionFormat
+	return authorization.NewPermissionFormat(cfg.Separator, cfg.Wildcard, cfg.ActionFirst)
+}
+
+// requireAuthenticationContext checks that an authentication middleware ran before the
+// authorization checks. Without one, a missing user, permissions or roles say nothing about
+// the caller: the route is misconfigured, so it aborts with 500 rather than a 401 or 403 that
+// would blame the caller.
+func (m *UnifiedAuthorizationMiddleware) requireAuthenticationContext(c *gin.Context) bool {
+	if hasAuthenticationContext(c) {
+		return true
+	}
+
+	m.logger.Error("Authorization reached without an authentication context; is an authentication middleware registered before it?",
+		logging.String("path", c.FullPath()))
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error":   "Authorization Misconfigured",
+		"message": "Authentication context is missing",
+		"reason":  "authentication_middleware_not_run",
+	})
+	c.Abort()
+	return false
+}
+
+// hasAuthenticationContext reports whether an authentication middleware populated c,
+// recognised by the source or claims it leaves behind
+func hasAuthenticationContext(c *gin.Context) bool {
+	for _, key := range []ctxkeys.Key{ctxkeys.AuthSource, ctxkeys.UserClaims, ctxkeys.JWTClaims} {
+		if _, exists := c.Get(key); exists {
+			return true
+		}
+	}
+	return false
 }
 
 // handleJWTWithDBAuthorization checks permissions from database
-func (m *UnifiedAuthorizationMiddleware) handleJWTWithDBAuthorization(c *gin.Context, userID, resource, action string) {
+func (m *UnifiedAuthorizationMiddleware) handleJWTWithDBAuthorization(c *gin.Context, userID, resource, action string) bool {
 	m.logger.Info("Checking permission using JWT with Database",
 		logging.String("user_id", userID),
 		logging.String("resource", resource),
 		logging.String("action", action))
 
+	if !m.requireAuthorizationRepositories(c) {
+		return false
+	}
+
 	ctx := context.Background()
 
 	// Parse user ID to UUID
@@ -170,7 +302,7 @@ func (m *UnifiedAuthorizationMiddleware) handleJWTWithDBAuthorization(c *gin.Con
 			"error": "Invalid user ID",
 		})
 		c.Abort()
-		return
+		return false
 	}
 
 	// Check if we should use roles or permissions
@@ -185,7 +317,7 @@ func (m *UnifiedAuthorizationMiddleware) handleJWTWithDBAuthorization(c *gin.Con
 				"error": "Failed to verify permissions",
 			})
 			c.Abort()
-			return
+			return false
 		}
 
 		if !hasPermission {
@@ -198,18 +330,18 @@ func (m *UnifiedAuthorizationMiddleware) handleJWTWithDBAuthorization(c *gin.Con
 				"message": "You do not have permission to perform this action",
 			})
 			c.Abort()
-			return
+			return false
 		}
 	}
 
 	m.logger.Info("Permission granted (JWT with DB)",
 		logging.String("user_id", userID))
-	c.Set("authorization_mode", "jwt_with_db")
-	c.Next()
+	c.Set(ctxkeys.AuthorizationMode, "jwt_with_db")
+	return true
 }
 
 // handleKeycloakAuthorization checks permissions with Keycloak
-func (m *UnifiedAuthorizationMiddleware) handleKeycloakAuthorization(c *gin.Context, userID, resource, action string) {
+func (m *UnifiedAuthorizationMiddleware) handleKeycloakAuthorization(c *gin.Context, userID, resource, action string) bool {
 	m.logger.Info("Checking permission with Keycloak",
 		logging.String("user_id", userID),
 		logging.String("resource", resource),
@@ -227,8 +359,7 @@ func (m *UnifiedAuthorizationMiddleware) handleKeycloakAuthorization(c *gin.Cont
 		// Fallback to JWT if configured
 		if m.authConfig.KeycloakAuth.FallbackToJWT {
 			m.logger.Info("Falling back to JWT authorization")
-			m.handleJWTAuthorization(c, userID, resource, action)
-			return
+			return m.handleJWTAuthorization(c, userID, resource, action)
 		}
 
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -236,7 +367,7 @@ func (m *UnifiedAuthorizationMiddleware) handleKeycloakAuthorization(c *gin.Cont
 			"message": "Failed to verify permissions with Keycloak",
 		})
 		c.Abort()
-		return
+		return false
 	}
 
 	if !allowed {
@@ -249,13 +380,13 @@ func (m *UnifiedAuthorizationMiddleware) handleKeycloakAuthorization(c *gin.Cont
 			"message": "You do not have permission to perform this action",
 		})
 		c.Abort()
-		return
+		return false
 	}
 
 	m.logger.Info("Permission granted (Keycloak)",
 		logging.String("user_id", userID))
-	c.Set("authorization_mode", "keycloak")
-	c.Next()
+	c.Set(ctxkeys.AuthorizationMode, "keycloak")
+	return true
 }
 
 // RequireRole checks if user has a specific role
@@ -266,37 +397,66 @@ func (m *UnifiedAuthorizationMiddleware) RequireRole(role string) gin.HandlerFun
 			return
 		}
 
-		userID, exists := c.Get("user_id")
+		decision := &authorization.AuthorizationDecision{
+			Check: authorization.CheckRole,
+			Role:  role,
+		}
+
+		if !m.requireAuthenticationContext(c) {
+			m.recordDecision(c, decision, false)
+			return
+		}
+
+		userID, exists := c.Get(ctxkeys.UserID)
 		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 			c.Abort()
+			m.recordDecision(c, decision, false)
 			return
 		}
 
-		userIDStr := userID.(string)
+		userIDStr, ok := userID.(string)
+		if !ok {
+			m.logger.Error("Invalid user ID type in context")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+			c.Abort()
+			m.recordDecision(c, decision, false)
+			return
+		}
+		decision.UserID = userIDStr
 
+		allowed := false
 		switch m.authConfig.Mode {
 		case config.AuthorizationModeJWT:
-			m.handleJWTRoleCheck(c, userIDStr, role)
+			allowed = m.handleJWTRoleCheck(c, userIDStr, role)
 		case config.AuthorizationModeJWTWithDB:
-			m.handleJWTWithDBRoleCheck(c, userIDStr, role)
+			allowed = m.handleJWTWithDBRoleCheck(c, userIDStr, role)
 		case config.AuthorizationModeKeycloak:
-			m.handleKeycloakRoleCheck(c, userIDStr, role)
+			allowed = m.handleKeycloakRoleCheck(c, userIDStr, role)
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid authorization mode"})
 			c.Abort()
 		}
+
+		m.recordDecision(c, decision, allowed)
+		if allowed {
+			c.Next()
+		}
 	}
 }
 
 // handleJWTRoleCheck checks role from JWT claims
-func (m *UnifiedAuthorizationMiddleware) handleJWTRoleCheck(c *gin.Context, userID, role string) {
-	roles, exists := c.Get("roles")
+func (m *UnifiedAuthorizationMiddleware) handleJWTRoleCheck(c *gin.Context, userID, role string) bool {
+	if !m.verifyTokenAudience(c, userID) {
+		return false
+	}
+
+	roles, exists := c.Get(ctxkeys.Roles)
 	if !exists || roles == nil {
 		m.logger.Warn("No roles found in JWT token", logging.String("user_id", userID))
 		c.JSON(http.StatusForbidden, gin.H{"error": "No roles found in token"})
 		c.Abort()
-		return
+		return false
 	}
 
 	roleSlice := convertToStringSlice(roles)
@@ -309,21 +469,25 @@ func (m *UnifiedAuthorizationMiddleware) handleJWTRoleCheck(c *gin.Context, user
 			"message": "Required role not found",
 		})
 		c.Abort()
-		return
+		return false
 	}
 
 	m.logger.Info("Role check passed (JWT)", logging.String("user_id", userID))
-	c.Next()
+	return true
 }
 
 // handleJWTWithDBRoleCheck checks role from database
-func (m *UnifiedAuthorizationMiddleware) handleJWTWithDBRoleCheck(c *gin.Context, userID, role string) {
+func (m *UnifiedAuthorizationMiddleware) handleJWTWithDBRoleCheck(c *gin.Context, userID, role string) bool {
+	if !m.requireAuthorizationRepositories(c) {
+		return false
+	}
+
 	ctx := context.Background()
 	userUUID, err := uuid.Parse(userID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
 		c.Abort()
-		return
+		return false
 	}
 
 	roles, err := m.roleRepo.GetUserRoles(ctx, userUUID)
@@ -331,7 +495,7 @@ func (m *UnifiedAuthorizationMiddleware) handleJWTWithDBRoleCheck(c *gin.Context
 		m.logger.Error("Failed to get user roles from database", logging.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify roles"})
 		c.Abort()
-		return
+		return false
 	}
 
 	hasRequiredRole := false
@@ -345,14 +509,26 @@ func (m *UnifiedAuthorizationMiddleware) handleJWTWithDBRoleCheck(c *gin.Context
 	if !hasRequiredRole {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Required role not found"})
 		c.Abort()
-		return
+		return false
 	}
 
-	c.Next()
+	return true
+}
+
+// requireAuthorizationRepositories refuses the request with 503 when the role and
+// permission repositories are missing, as they are while the service runs without a database
+func (m *UnifiedAuthorizationMiddleware) requireAuthorizationRepositories(c *gin.Context) bool {
+	if m.roleRepo != nil && m.permissionRepo != nil {
+		return true
+	}
+	m.logger.Warn("Authorization data unavailable, database not connected")
+	c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Authorization data unavailable"})
+	c.Abort()
+	return false
 }
 
 // handleKeycloakRoleCheck checks role from Keycloak
-func (m *UnifiedAuthorizationMiddleware) handleKeycloakRoleCheck(c *gin.Context, userID, role string) {
+func (m *UnifiedAuthorizationMiddleware) handleKeycloakRoleCheck(c *gin.Context, userID, role string) bool {
 	ctx := context.Background()
 	roles, err := m.keycloakAdapter.GetUserRoles(ctx, userID)
 	if err != nil {
@@ -360,22 +536,21 @@ func (m *UnifiedAuthorizationMiddleware) handleKeycloakRoleCheck(c *gin.Context,
 
 		// Fallback to JWT if configured
 		if m.authConfig.KeycloakAuth.FallbackToJWT {
-			m.handleJWTRoleCheck(c, userID, role)
-			return
+			return m.handleJWTRoleCheck(c, userID, role)
 		}
 
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify roles"})
 		c.Abort()
-		return
+		return false
 	}
 
 	if !containsString(roles, role) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Required role not found"})
 		c.Abort()
-		return
+		return false
 	}
 
-	c.Next()
+	return true
 }
 
 // Helper functions
@@ -398,16 +573,6 @@ func convertToStringSlice(data interface{}) []string {
 	return nil
 }
 
-func hasPermission(permissions []string, resource, action string) bool {
-	requiredPermission := resource + ":" + action
-	for _, perm := range permissions {
-		if perm == requiredPermission || perm == resource+":*" || perm == "*:*" {
-			return true
-		}
-	}
-	return false
-}
-
 func hasRole(roles []string, role string) bool {
 	for _, r := range roles {
 		if r == role {
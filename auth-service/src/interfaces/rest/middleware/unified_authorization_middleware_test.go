@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auth-service/src/infrastructure/config"
+	"backend-core/ctxkeys"
+	"backend-core/logging"
+	"backend-core/security"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// serveAuthorized runs authorize behind setup, which stands in for the authentication
+// middleware, and returns the recorded response
+func serveAuthorized(setup, authorize gin.HandlerFunc) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/resource", setup, authorize, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/resource", nil))
+	return rec
+}
+
+func newJWTUnifiedMiddleware(t *testing.T) *UnifiedAuthorizationMiddleware {
+	return NewUnifiedAuthorizationMiddleware(
+		&config.AuthorizationConfig{Enabled: true, Mode: config.AuthorizationModeJWT},
+		nil, nil, nil, nil, logging.NewNopLogger(),
+	)
+}
+
+func TestUnifiedAuthorizationWithoutAuthenticationMiddleware(t *testing.T) {
+	m := newJWTUnifiedMiddleware(t)
+	noAuthentication := func(c *gin.Context) {}
+
+	for name, authorize := range map[string]gin.HandlerFunc{
+		"RequirePermission": m.RequirePermission("users", "read"),
+		"RequireRole":       m.RequireRole("admin"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := serveAuthorized(noAuthentication, authorize)
+
+			if rec.Code != http.StatusInternalServerError {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+			}
+			var body map[string]interface{}
+			json.Unmarshal(rec.Body.Bytes(), &body)
+			if body["reason"] != "authentication_middleware_not_run" {
+				t.Errorf("reason = %v, want authentication_middleware_not_run", body["reason"])
+			}
+		})
+	}
+}
+
+func TestUnifiedAuthorizationRejectsNonStringUserID(t *testing.T) {
+	m := newJWTUnifiedMiddleware(t)
+	authenticated := func(c *gin.Context) {
+		c.Set(ctxkeys.AuthSource, AuthSourceJWT)
+		c.Set(ctxkeys.UserID, 42)
+	}
+
+	for name, authorize := range map[string]gin.HandlerFunc{
+		"RequirePermission": m.RequirePermission("users", "read"),
+		"RequireRole":       m.RequireRole("admin"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if rec := serveAuthorized(authenticated, authorize); rec.Code != http.StatusInternalServerError {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+			}
+		})
+	}
+}
+
+func TestUnifiedAuthorizationRequirePermissionJWT(t *testing.T) {
+	m := newJWTUnifiedMiddleware(t)
+	withPermissions := func(permissions ...string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set(ctxkeys.AuthSource, AuthSourceJWT)
+			c.Set(ctxkeys.UserID, "user-1")
+			c.Set(ctxkeys.Permissions, permissions)
+		}
+	}
+
+	if rec := serveAuthorized(withPermissions("users:read"), m.RequirePermission("users", "read")); rec.Code != http.StatusOK {
+		t.Errorf("status with the permission = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec := serveAuthorized(withPermissions("users:write"), m.RequirePermission("users", "read")); rec.Code != http.StatusForbidden {
+		t.Errorf("status without the permission = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestUnifiedAuthorizationJWTWithDBWithoutRepositories(t *testing.T) {
+	m := NewUnifiedAuthorizationMiddleware(
+		&config.AuthorizationConfig{Enabled: true, Mode: config.AuthorizationModeJWTWithDB},
+		nil, nil, nil, nil, logging.NewNopLogger(),
+	)
+	authenticated := func(c *gin.Context) {
+		c.Set(ctxkeys.AuthSource, AuthSourceJWT)
+		c.Set(ctxkeys.UserID, "4f1c2f6e-9a4b-4c59-8d3e-2f0f5f0a7b11")
+	}
+
+	for name, authorize := range map[string]gin.HandlerFunc{
+		"RequirePermission": m.RequirePermission("users", "read"),
+		"RequireRole":       m.RequireRole("admin"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if rec := serveAuthorized(authenticated, authorize); rec.Code != http.StatusServiceUnavailable {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+			}
+		})
+	}
+}
+
+func TestUnifiedAuthorizationJWTChecksAudience(t *testing.T) {
+	jwtManager := security.NewJWTManager("secret", time.Minute, "auth-service", "admin-api")
+	m := NewUnifiedAuthorizationMiddleware(
+		&config.AuthorizationConfig{Enabled: true, Mode: config.AuthorizationModeJWT},
+		jwtManager, nil, nil, nil, logging.NewNopLogger(),
+	)
+	withAudience := func(audience string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set(ctxkeys.AuthSource, AuthSourceJWT)
+			c.Set(ctxkeys.UserID, "user-1")
+			c.Set(ctxkeys.Permissions, []string{"users:read"})
+			c.Set(ctxkeys.Roles, []string{"admin"})
+			c.Set(ctxkeys.UserClaims, &security.Claims{
+				UserID: "user-1",
+				RegisteredClaims: jwt.RegisteredClaims{
+					Issuer:   "auth-service",
+					Audience: jwt.ClaimStrings{audience},
+				},
+			})
+		}
+	}
+
+	for name, authorize := range map[string]gin.HandlerFunc{
+		"RequirePermission": m.RequirePermission("users", "read"),
+		"RequireRole":       m.RequireRole("admin"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if rec := serveAuthorized(withAudience("admin-api"), authorize); rec.Code != http.StatusOK {
+				t.Errorf("status with the configured audience = %d, want %d", rec.Code, http.StatusOK)
+			}
+			if rec := serveAuthorized(withAudience("billing-api"), authorize); rec.Code != http.StatusUnauthorized {
+				t.Errorf("status with another audience = %d, want %d", rec.Code, http.StatusUnauthorized)
+			}
+		})
+	}
+}
+
+func TestUnifiedAuthorizationRequirePermissionDotFormat(t *testing.T) {
+	authConfig := &config.AuthorizationConfig{Enabled: true, Mode: config.AuthorizationModeJWT}
+	authConfig.JWTAuth.PermissionFormat = config.PermissionFormatConfig{Separator: "."}
+	m := NewUnifiedAuthorizationMiddleware(authConfig, nil, nil, nil, nil, logging.NewNopLogger())
+	withPermissions := func(permissions ...string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set(ctxkeys.AuthSource, AuthSourceJWT)
+			c.Set(ctxkeys.UserID, "user-1")
+			c.Set(ctxkeys.Permissions, permissions)
+		}
+	}
+
+	if rec := serveAuthorized(withPermissions("users.read"), m.RequirePermission("users", "read")); rec.Code != http.StatusOK {
+		t.Errorf("status with users.read = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec := serveAuthorized(withPermissions("users:read"), m.RequirePermission("users", "read")); rec.Code != http.StatusForbidden {
+		t.Errorf("status with users:read = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
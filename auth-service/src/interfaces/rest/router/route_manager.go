@@ -74,6 +74,12 @@ type RouteManager struct {
 	keycloakAuth        *middleware.KeycloakAuthorizationMiddleware
 	logger              *logging.Logger
 	telemetryMiddleware gin.HandlerFunc
+
+	permissionHandler *handlers.PermissionHandler
+	keycloakHandler   *handlers.KeycloakHandler
+	authMiddleware    *middleware.AuthenticationMiddleware
+	degradedMode      *middleware.DegradedMode
+	authorization     *middleware.UnifiedAuthorizationMiddleware
 }
 
 // NewRouteManager creates a new route manager
@@ -95,6 +101,33 @@ func NewRouteManager(
 	}
 }
 
+// SetPermissionRoutes enables GET /api/v1/me/permissions, served by permissionHandler behind
+// authMiddleware. It must be called before SetupRoutes.
+func (rm *RouteManager) SetPermissionRoutes(permissionHandler *handlers.PermissionHandler, authMiddleware *middleware.AuthenticationMiddleware) {
+	rm.permissionHandler = permissionHandler
+	rm.authMiddleware = authMiddleware
+}
+
+// SetMFARoutes enables POST /api/v1/mfa/totp/enroll, served by keycloakHandler behind
+// authMiddleware. It must be called before SetupRoutes.
+func (rm *RouteManager) SetMFARoutes(keycloakHandler *handlers.KeycloakHandler, authMiddleware *middleware.AuthenticationMiddleware) {
+	rm.keycloakHandler = keycloakHandler
+	rm.authMiddleware = authMiddleware
+}
+
+// SetAuthorization checks the permission each user route needs with authorization, after
+// authenticating the caller with the middleware given to SetPermissionRoutes. It must be
+// called before SetupRoutes.
+func (rm *RouteManager) SetAuthorization(authorization *middleware.UnifiedAuthorizationMiddleware) {
+	rm.authorization = authorization
+}
+
+// SetDegradedMode refuses the endpoints that need the database with 503 while degradedMode is
+// enabled, and reports it on GET /readyz. It must be called before SetupRoutes.
+func (rm *RouteManager) SetDegradedMode(degradedMode *middleware.DegradedMode) {
+	rm.degradedMode = degradedMode
+}
+
 // SetupRoutes configures all application routes
 func (rm *RouteManager) SetupRoutes() *gin.Engine {
 	// Create Gin engine
@@ -111,10 +144,20 @@ func (rm *RouteManager) SetupRoutes() *gin.Engine {
 	authRoutes := groups.NewAuthRoutes(rm.authHandler)
 	userRoutes := groups.NewUserRoutes(rm.userHandler, rm.cacheMiddleware)
 	systemRoutes := groups.NewSystemRoutes(rm.cacheMiddleware)
+	if rm.degradedMode.Enabled() {
+		authRoutes.RequireDatabase(rm.degradedMode.RequireDatabase())
+		userRoutes.RequireDatabase(rm.degradedMode.RequireDatabase())
+	}
+	if rm.authorization != nil && rm.authMiddleware != nil {
+		userRoutes.RequirePermissions(rm.authMiddleware.RequireAuth(), rm.authorization.RequirePermission)
+	}
 
 	// Register system routes (health, cache, etc.) - includes swagger now
 	systemRoutes.RegisterRoutes(router)
 
+	// Readiness, including whether the service is degraded
+	router.GET("/readyz", rm.degradedMode.ReadinessHandler())
+
 	// Register admin routes AFTER system routes with middleware
 	fmt.Printf("DEBUG: Registering admin routes AFTER system routes with middleware\n")
 	if rm.keycloakAuth != nil {
@@ -127,6 +170,13 @@ func (rm *RouteManager) SetupRoutes() *gin.Engine {
 			c.JSON(200, gin.H{"message": "Admin users endpoint", "status": "success"})
 		})
 		fmt.Printf("DEBUG: Admin routes registered with Keycloak middleware\n")
+
+		// Cache maintenance is destructive, so it is only offered to verified tokens carrying the admin role
+		if rm.cacheMiddleware != nil {
+			requireToken := rm.keycloakAuth.RequireValidKeycloakToken()
+			requireAdmin := rm.keycloakAuth.RequireKeycloakRole([]string{"admin"})
+			router.POST("/admin/cache/invalidate", requireToken, requireAdmin, rm.cacheMiddleware.CacheInvalidateHandler())
+		}
 	} else {
 		router.GET("/admin/test", func(c *gin.Context) {
 			fmt.Printf("DEBUG: Admin /test endpoint handler called (no auth)\n")
@@ -139,10 +189,20 @@ func (rm *RouteManager) SetupRoutes() *gin.Engine {
 		fmt.Printf("DEBUG: Admin routes registered without middleware\n")
 	}
 
-	// Register SSO callback endpoint
-	router.GET("/callback", func(c *gin.Context) {
+	// Register SSO callback endpoint. The state is checked before the code is exchanged;
+	// without Keycloak no state can have been issued, so every callback is rejected.
+	validateState := func(c *gin.Context) {
+		c.JSON(400, gin.H{
+			"error":             "Invalid State",
+			"error_description": "SSO is not configured",
+		})
+		c.Abort()
+	}
+	if rm.keycloakAuth != nil {
+		validateState = rm.keycloakAuth.RequireValidSSOState()
+	}
+	router.GET("/callback", validateState, func(c *gin.Context) {
 		code := c.Query("code")
-		state := c.Query("state")
 		error_param := c.Query("error")
 
 		if error_param != "" {
@@ -201,6 +261,16 @@ func (rm *RouteManager) SetupRoutes() *gin.Engine {
 
 		// Register user routes
 		userRoutes.RegisterRoutes(api)
+
+		// Effective permissions of the caller, for rendering permission-aware UIs
+		if rm.permissionHandler != nil && rm.authMiddleware != nil {
+			api.GET("/me/permissions", rm.authMiddleware.RequireAuth(), rm.permissionHandler.GetMyPermissions)
+		}
+
+		// TOTP enrollment of the caller
+		if rm.keycloakHandler != nil && rm.authMiddleware != nil {
+			api.POST("/mfa/totp/enroll", rm.authMiddleware.RequireAuth(), rm.keycloakHandler.EnrollTOTP)
+		}
 	}
 
 	rm.logger.Info("All routes registered successfully")
@@ -0,0 +1,142 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"auth-service/src/infrastructure/identity/keycloak"
+	"auth-service/src/interfaces/rest/middleware"
+
+	"backend-core/logging"
+)
+
+func newTestRouteManager(t *testing.T) *RouteManager {
+	t.Helper()
+
+	logger := logging.NewNopLogger()
+
+	keycloakConfig := keycloak.KeycloakConfig{
+		BaseURL:      "http://keycloak.invalid",
+		Realm:        "test",
+		ClientID:     "auth-service",
+		ClientSecret: "secret",
+	}
+	client, err := keycloak.NewKeycloakClient(keycloakConfig, logger)
+	if err != nil {
+		t.Fatalf("failed to create Keycloak client: %v", err)
+	}
+	adapter := keycloak.NewKeycloakAdapter(client, nil, keycloakConfig, logger)
+
+	return NewRouteManager(
+		nil,
+		nil,
+		middleware.NewCacheMiddleware(nil, logger),
+		middleware.NewKeycloakAuthorizationMiddleware(adapter, logger),
+		logger,
+		nil,
+	)
+}
+
+func TestAdminCacheRoutesRequireVerifiedToken(t *testing.T) {
+	router := newTestRouteManager(t).SetupRoutes()
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantMessage   string
+	}{
+		{name: "no token", wantMessage: "Authorization header required"},
+		{name: "unverifiable token", authorization: "Bearer not-a-jwt", wantMessage: "Invalid token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/cache/invalidate", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantMessage) {
+				t.Errorf("body = %s, want message %q", rec.Body.String(), tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestDegradedModeRefusesDatabaseEndpoints(t *testing.T) {
+	rm := newTestRouteManager(t)
+	rm.SetDegradedMode(middleware.NewDegradedMode("database connection failed"))
+	router := rm.SetupRoutes()
+
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodPost, "/api/v1/auth/login", http.StatusServiceUnavailable},
+		{http.MethodPost, "/api/v1/auth/register", http.StatusServiceUnavailable},
+		{http.MethodPost, "/api/v1/auth/change-password", http.StatusServiceUnavailable},
+		{http.MethodGet, "/api/v1/users", http.StatusServiceUnavailable},
+		{http.MethodGet, "/api/v1/users/42", http.StatusServiceUnavailable},
+		{http.MethodGet, "/health", http.StatusOK},
+		{http.MethodGet, "/api/v1/status", http.StatusOK},
+		{http.MethodGet, "/readyz", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.want != http.StatusServiceUnavailable {
+				return
+			}
+			if rec.Header().Get("Retry-After") == "" {
+				t.Error("expected a Retry-After header")
+			}
+			if !strings.Contains(rec.Body.String(), "database connection failed") {
+				t.Errorf("body = %s, want the degraded reason", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestReadyzReportsDegradedMode(t *testing.T) {
+	tests := []struct {
+		name   string
+		reason string
+		want   []string
+	}{
+		{name: "healthy", want: []string{`"status":"ready"`, `"degraded":false`}},
+		{name: "degraded", reason: "database connection failed",
+			want: []string{`"status":"degraded"`, `"degraded":true`, `"reason":"database connection failed"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rm := newTestRouteManager(t)
+			rm.SetDegradedMode(middleware.NewDegradedMode(tt.reason))
+			rec := httptest.NewRecorder()
+			rm.SetupRoutes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("body = %s, want %s", rec.Body.String(), want)
+				}
+			}
+		})
+	}
+}
@@ -14,6 +14,9 @@ type Cache interface {
 	// Basic operations
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
 	Get(ctx context.Context, key string, dest interface{}) error
+	// GetDelete reads key into dest and removes it in one atomic step, returning
+	// ErrCacheMiss when the key does not exist
+	GetDelete(ctx context.Context, key string, dest interface{}) error
 	Delete(ctx context.Context, key string) error
 	DeletePattern(ctx context.Context, pattern string) error
 	Exists(ctx context.Context, key string) (bool, error)
@@ -14,6 +14,7 @@ import (
 // CacheDecorator provides a decorator pattern for caching operations
 type CacheDecorator struct {
 	cache           cache.Cache
+	redisCache      *cache.RedisCache
 	strategyManager *strategies.StrategyManager
 	logger          *logging.Logger
 	config          *CacheDecoratorConfig
@@ -93,6 +94,7 @@ func NewCacheDecorator(cfg config.RedisConfig, logger *logging.Logger, decorator
 
 	return &CacheDecorator{
 		cache:           redisCache,
+		redisCache:      redisCache,
 		strategyManager: strategyManager,
 		logger:          logger,
 		config:          decoratorConfig,
@@ -280,6 +282,20 @@ func (cd *CacheDecorator) InvalidatePattern(ctx context.Context, pattern string)
 	return cd.cache.DeletePattern(ctx, fullPattern)
 }
 
+// InvalidateNamespace invalidates the entries matching pattern, which must start with a
+// namespace such as "users:*"
+func (cd *CacheDecorator) InvalidateNamespace(ctx context.Context, pattern string) error {
+	if err := cache.ValidateInvalidationPattern(pattern); err != nil {
+		return err
+	}
+
+	fullPattern := cd.buildKey(pattern)
+
+	cd.logger.Info("Invalidating cache namespace", logging.String("pattern", fullPattern))
+
+	return cd.redisCache.SafeInvalidatePattern(ctx, fullPattern)
+}
+
 // ClearAll clears all cache entries
 func (cd *CacheDecorator) ClearAll(ctx context.Context) error {
 	cd.logger.Info("Clearing all cache entries")
@@ -343,6 +359,14 @@ func (cd *CacheDecorator) GetCache() cache.Cache {
 	return cd.cache
 }
 
+// GetRedisCache returns the Redis cache the decorator stores entries in, or nil if it has none
+func (cd *CacheDecorator) GetRedisCache() *cache.RedisCache {
+	if cd == nil {
+		return nil
+	}
+	return cd.redisCache
+}
+
 // GetStrategyManager returns the strategy manager
 func (cd *CacheDecorator) GetStrategyManager() *strategies.StrategyManager {
 	return cd.strategyManager
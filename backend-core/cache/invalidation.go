@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidatorNotConfigured is returned when an invalidation needs a CacheInvalidator and none is set
+	ErrInvalidatorNotConfigured = errors.New("no invalidator configured")
+	// ErrWarmerNotConfigured is returned when a warm-up needs a CacheWarmer and none is set
+	ErrWarmerNotConfigured = errors.New("no warmer configured")
+	// ErrUnsafePattern is returned for invalidation patterns that do not start with a namespace
+	ErrUnsafePattern = errors.New("invalidation pattern must start with a namespace, e.g. \"users:*\"")
+	// ErrInvalidWarmUpKeys is returned for warm-up requests with no keys, too many keys or wildcard keys
+	ErrInvalidWarmUpKeys = errors.New("invalid warm-up keys")
+)
+
+const (
+	// MaxWarmUpKeys bounds how many keys a single warm-up request may load
+	MaxWarmUpKeys = 1000
+
+	// globChars are the characters Redis treats as wildcards in a key pattern
+	globChars = "*?[\\"
+)
+
+// ValidateInvalidationPattern guards pattern invalidation against wiping unrelated data.
+// The pattern must have a literal namespace before its first wildcard, so "users:*" and
+// "users:42" are accepted while "", "*" and "*:profile" are not.
+func ValidateInvalidationPattern(pattern string) error {
+	pattern = strings.TrimSpace(pattern)
+	namespace := pattern
+	if i := strings.IndexAny(pattern, globChars); i >= 0 {
+		namespace = pattern[:i]
+	}
+	if strings.Trim(namespace, ":") == "" {
+		return fmt.Errorf("%w: %q", ErrUnsafePattern, pattern)
+	}
+	return nil
+}
+
+// ValidateWarmUpKeys checks that keys holds between 1 and MaxWarmUpKeys exact keys
+func ValidateWarmUpKeys(keys []string) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("%w: no keys given", ErrInvalidWarmUpKeys)
+	}
+	if len(keys) > MaxWarmUpKeys {
+		return fmt.Errorf("%w: %d keys given, at most %d allowed", ErrInvalidWarmUpKeys, len(keys), MaxWarmUpKeys)
+	}
+	for _, key := range keys {
+		if strings.TrimSpace(key) == "" || strings.ContainsAny(key, globChars) {
+			return fmt.Errorf("%w: %q is not an exact key", ErrInvalidWarmUpKeys, key)
+		}
+	}
+	return nil
+}
+
+// SafeInvalidatePattern invalidates the keys matching pattern within the cache's key prefix
+// after checking it with ValidateInvalidationPattern. The configured CacheInvalidator is used
+// when there is one; otherwise the matching keys are deleted.
+func (r *RedisCache) SafeInvalidatePattern(ctx context.Context, pattern string) error {
+	if err := ValidateInvalidationPattern(pattern); err != nil {
+		return err
+	}
+	if r.invalidator == nil {
+		return r.DeletePattern(ctx, pattern)
+	}
+	return r.InvalidatePattern(ctx, pattern)
+}
+
+// SafeWarmUpKeys loads keys through the configured CacheWarmer after checking them with ValidateWarmUpKeys
+func (r *RedisCache) SafeWarmUpKeys(ctx context.Context, keys []string) error {
+	if err := ValidateWarmUpKeys(keys); err != nil {
+		return err
+	}
+	return r.WarmUpKeys(ctx, keys)
+}
@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrLockNotHeld is returned when releasing or extending a lock this instance does not hold,
+// either because it was never acquired or because it expired and was taken by someone else
+var ErrLockNotHeld = errors.New("lock not held")
+
+// releaseLockScript deletes the lock only if it still carries our token, so an expired
+// lock that was since acquired elsewhere is never released by mistake.
+//
+// KEYS[1] lock key
+// ARGV[1] token
+// Returns 1 when released, 0 when the lock is not ours
+var releaseLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// extendLockScript resets the lock expiry only if it still carries our token.
+//
+// KEYS[1] lock key
+// ARGV[1] token, ARGV[2] new TTL in milliseconds
+// Returns 1 when extended, 0 when the lock is not ours
+var extendLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// RedisLock is a distributed mutual-exclusion lock backed by a single Redis key.
+// The key holds a random token so only the holder can release or extend it, and
+// it expires after the TTL so a crashed holder cannot keep it forever.
+type RedisLock struct {
+	client redis.UniversalClient
+	key    string
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewRedisLock creates a lock named name in the key namespace of the given Redis cache
+func NewRedisLock(cache *RedisCache, name string, ttl time.Duration) *RedisLock {
+	return NewRedisLockWithClient(cache.GetClient(), cache.key(context.Background(), "lock:"+name), ttl)
+}
+
+// NewRedisLockWithClient creates a lock stored under key using an existing Redis client
+func NewRedisLockWithClient(client redis.UniversalClient, key string, ttl time.Duration) *RedisLock {
+	return &RedisLock{
+		client: client,
+		key:    key,
+		ttl:    ttl,
+	}
+}
+
+// Key returns the Redis key the lock is stored under
+func (l *RedisLock) Key() string {
+	return l.key
+}
+
+// TryAcquire takes the lock if nobody holds it, without waiting.
+// It reports false when the lock is held elsewhere.
+func (l *RedisLock) TryAcquire(ctx context.Context) (bool, error) {
+	if l.ttl < time.Millisecond {
+		return false, fmt.Errorf("lock TTL must be at least 1ms, got %s", l.ttl)
+	}
+
+	token, err := lockToken()
+	if err != nil {
+		return false, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ok, err := l.client.SetNX(ctx, l.key, token, l.ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lock %s: %w", l.key, err)
+	}
+	if ok {
+		l.token = token
+	}
+	return ok, nil
+}
+
+// Extend resets the lock expiry to ttl from now. It returns ErrLockNotHeld if the lock
+// has expired or was never acquired.
+func (l *RedisLock) Extend(ctx context.Context, ttl time.Duration) error {
+	if ttl < time.Millisecond {
+		return fmt.Errorf("lock TTL must be at least 1ms, got %s", ttl)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.token == "" {
+		return ErrLockNotHeld
+	}
+
+	res, err := extendLockScript.Run(ctx, l.client, []string{l.key}, l.token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return fmt.Errorf("failed to extend lock %s: %w", l.key, err)
+	}
+	if res == 0 {
+		l.token = ""
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Release gives the lock up. It returns ErrLockNotHeld if the lock had already expired
+// or was never acquired.
+func (l *RedisLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.token == "" {
+		return ErrLockNotHeld
+	}
+
+	res, err := releaseLockScript.Run(ctx, l.client, []string{l.key}, l.token).Int64()
+	if err != nil {
+		return fmt.Errorf("failed to release lock %s: %w", l.key, err)
+	}
+	l.token = ""
+	if res == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// lockToken returns a random token identifying one acquisition of a lock
+func lockToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
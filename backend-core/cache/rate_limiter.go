@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// slidingWindowScript trims the window, counts it and records the request if it fits,
+// all in one round trip so concurrent instances cannot race between the steps.
+//
+// KEYS[1] window key
+// ARGV[1] now in milliseconds, ARGV[2] window in milliseconds, ARGV[3] limit, ARGV[4] member
+// Returns {allowed (0/1), remaining, reset at in milliseconds}
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+
+local reset = now + window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window
+end
+
+return {allowed, limit - count, reset}
+`)
+
+// RedisRateLimiter is a distributed sliding-window rate limiter backed by Redis sorted sets.
+// State is shared by every instance using the same Redis, and survives restarts.
+type RedisRateLimiter struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisRateLimiter creates a rate limiter using the client and key namespace of the given Redis cache
+func NewRedisRateLimiter(cache *RedisCache) *RedisRateLimiter {
+	limiter := NewRedisRateLimiterWithClient(cache.GetClient())
+	limiter.prefix = cache.key(context.Background(), "ratelimit") + ":"
+	return limiter
+}
+
+// NewRedisRateLimiterWithClient creates a rate limiter using an existing Redis client
+func NewRedisRateLimiterWithClient(client redis.UniversalClient) *RedisRateLimiter {
+	return &RedisRateLimiter{
+		client: client,
+		prefix: "ratelimit:",
+	}
+}
+
+// Allow records a request for key and reports whether it fits within limit requests per window.
+// remaining is the number of requests still allowed in the current window and resetAt is
+// when the oldest request in the window expires.
+func (l *RedisRateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (allowed bool, remaining int, resetAt time.Time, err error) {
+	if limit <= 0 {
+		return false, 0, time.Now().Add(window), nil
+	}
+	if window < time.Millisecond {
+		return false, 0, time.Time{}, fmt.Errorf("rate limit window must be at least 1ms, got %s", window)
+	}
+
+	member, err := windowMember()
+	if err != nil {
+		return false, 0, time.Time{}, err
+	}
+
+	now := time.Now().UnixMilli()
+	res, err := slidingWindowScript.Run(ctx, l.client, []string{l.prefix + key},
+		now, window.Milliseconds(), limit, member).Int64Slice()
+	if err != nil {
+		return false, 0, time.Time{}, fmt.Errorf("failed to evaluate rate limit: %w", err)
+	}
+	if len(res) != 3 {
+		return false, 0, time.Time{}, fmt.Errorf("unexpected rate limit script result: %v", res)
+	}
+
+	return res[0] == 1, int(res[1]), time.UnixMilli(res[2]), nil
+}
+
+// windowMember returns a unique sorted-set member so simultaneous requests are counted separately
+func windowMember() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate rate limit member: %w", err)
+	}
+	return strconv.FormatInt(time.Now().UnixNano(), 10) + "-" + hex.EncodeToString(buf), nil
+}
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -13,6 +14,18 @@ import (
 	"backend-core/config"
 
 	"github.com/go-redis/redis/v8"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrRedisJSONUnavailable is returned by the JSON operations when the server does not
+// have the RedisJSON module; callers can fall back to whole-value Get/Set
+var ErrRedisJSONUnavailable = operations.ErrRedisJSONUnavailable
+
+const (
+	// gzipTransformerName is the name EnableCompression registers its transformer under
+	gzipTransformerName = "gzip"
+	// gzipTransformerPriority places compression in the same slot as CompressionTransformer
+	gzipTransformerPriority = 3
 )
 
 // RedisCache implements the Cache interface using Redis
@@ -20,11 +33,15 @@ import (
 type RedisCache struct {
 	client       redis.UniversalClient
 	config       *config.RedisConfig
+	keyPrefix    string
+	multiTenant  bool
 	ops          *operations.RedisOperations
 	counters     *operations.RedisCounters
 	lists        *operations.RedisLists
 	sets         *operations.RedisSets
+	sortedSets   *operations.RedisSortedSets
 	hashes       *operations.RedisHashes
+	json         *operations.RedisJSON
 	transactions *transactions.RedisTransactions
 	reloader     reload.CacheReloader
 	invalidator  reload.CacheInvalidator
@@ -33,6 +50,8 @@ type RedisCache struct {
 	hookManager        *reload.ReloadHookManager
 	strategyManager    *reload.CustomReloadStrategyManager
 	transformerManager *transformers.DataTransformerManager
+	// loads collapses concurrent GetOrSet loads of the same key
+	loads singleflight.Group
 }
 
 // NewRedisCache creates a new Redis cache instance from config
@@ -43,11 +62,15 @@ func NewRedisCache(cfg *config.RedisConfig) *RedisCache {
 	return &RedisCache{
 		client:             redisClient,
 		config:             cfg,
+		keyPrefix:          cfg.KeyPrefix,
+		multiTenant:        cfg.MultiTenant,
 		ops:                operations.NewRedisOperations(redisClient),
 		counters:           operations.NewRedisCounters(redisClient),
 		lists:              operations.NewRedisLists(redisClient),
 		sets:               operations.NewRedisSets(redisClient),
+		sortedSets:         operations.NewRedisSortedSets(redisClient),
 		hashes:             operations.NewRedisHashes(redisClient),
+		json:               operations.NewRedisJSON(redisClient),
 		transactions:       transactions.NewRedisTransactions(redisClient),
 		hookManager:        reload.NewReloadHookManager(),
 		strategyManager:    reload.NewCustomReloadStrategyManager(),
@@ -68,43 +91,94 @@ func NewStandaloneRedisCache(addr, password string, db int) *RedisCache {
 	return NewRedisCache(cfg)
 }
 
+// key applies the configured namespace to a key or pattern: the KeyPrefix and, when
+// multi-tenancy is enabled and ctx carries a tenant ID, the tenant namespace.
+// Without either keys are used unchanged.
+func (r *RedisCache) key(ctx context.Context, key string) string {
+	if r.multiTenant {
+		if namespace := tenantNamespace(ctx); namespace != "" {
+			key = namespace + ":" + key
+		}
+	}
+	if r.keyPrefix == "" {
+		return key
+	}
+	return r.keyPrefix + ":" + key
+}
+
+// KeyPrefix returns the namespace prepended to every key, or "" if none is configured
+func (r *RedisCache) KeyPrefix() string {
+	return r.keyPrefix
+}
+
 // ============================================================================
 // Basic Operations (delegated to RedisOperations)
 // ============================================================================
 
 // Set stores a value in the cache
 func (r *RedisCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
-	return r.ops.Set(ctx, key, value, expiration)
+	return r.ops.Set(ctx, r.key(ctx, key), value, expiration)
 }
 
-// Get retrieves a value from the cache
+// Get retrieves a value from the cache. With a refresh-ahead reloader, a hit on a key that
+// is about to expire also starts reloading it in the background.
 func (r *RedisCache) Get(ctx context.Context, key string, dest interface{}) error {
-	return r.ops.Get(ctx, key, dest)
+	if err := r.ops.Get(ctx, r.key(ctx, key), dest); err != nil {
+		// Translate the operations sentinel so callers can match on ErrCacheMiss
+		if err == operations.ErrCacheMiss {
+			return ErrCacheMiss
+		}
+		return err
+	}
+	r.refreshAhead(ctx, key)
+	return nil
+}
+
+// refreshAhead hands a cache hit on key to the reloader if it refreshes keys ahead of expiry
+func (r *RedisCache) refreshAhead(ctx context.Context, key string) {
+	refresher, ok := r.reloader.(reload.RefreshAheader)
+	if !ok {
+		return
+	}
+	// A failed check only skips the early reload; the key is still loaded again once it expires
+	refresher.RefreshAhead(ctx, key)
+}
+
+// GetDelete retrieves a value and removes it from the cache atomically, so concurrent
+// callers cannot both read it
+func (r *RedisCache) GetDelete(ctx context.Context, key string, dest interface{}) error {
+	if err := r.ops.GetDelete(ctx, r.key(ctx, key), dest); err != nil {
+		if err == operations.ErrCacheMiss {
+			return ErrCacheMiss
+		}
+		return err
+	}
+	return nil
 }
 
 // Delete removes a value from the cache
 func (r *RedisCache) Delete(ctx context.Context, key string) error {
-	return r.ops.Delete(ctx, key)
+	return r.ops.Delete(ctx, r.key(ctx, key))
 }
 
 // DeletePattern removes all keys matching a pattern
 func (r *RedisCache) DeletePattern(ctx context.Context, pattern string) error {
-	return r.ops.DeletePattern(ctx, pattern)
+	return r.ops.DeletePattern(ctx, r.key(ctx, pattern))
 }
 
 // Exists checks if a key exists in the cache
 func (r *RedisCache) Exists(ctx context.Context, key string) (bool, error) {
-	return r.ops.Exists(ctx, key)
+	return r.ops.Exists(ctx, r.key(ctx, key))
 }
 
 // Expire sets the expiration time for a key
 func (r *RedisCache) Expire(ctx context.Context, key string, expiration time.Duration) error {
-	return r.ops.Expire(ctx, key, expiration)
+	return r.ops.Expire(ctx, r.key(ctx, key), expiration)
 }
 
 // TTL returns the time to live for a key
 func (r *RedisCache) TTL(ctx context.Context, key string) (time.Duration, error) {
-	return r.ops.TTL(ctx, key)
+	return r.ops.TTL(ctx, r.key(ctx, key))
 }
 
 // Ping tests the Redis connection
@@ -112,28 +186,71 @@ func (r *RedisCache) Ping(ctx context.Context) error {
 	return r.ops.Ping(ctx)
 }
 
+// ============================================================================
+// Cache-Aside Operations
+// ============================================================================
+
+// GetOrSet reads key into dest, calling loader on a miss and caching its result for ttl.
+//
+// Concurrent misses on the same key within this instance share a single loader call;
+// the other callers wait for it and receive the same value. Values are stored with the
+// same JSON encoding as Set, so they can be read back with Get and overwritten with Set
+// as usual. Errors other than a miss are returned without calling loader, and loader
+// errors are returned without caching anything.
+func (r *RedisCache) GetOrSet(ctx context.Context, key string, dest interface{}, ttl time.Duration, loader func() (interface{}, error)) error {
+	err := r.Get(ctx, key, dest)
+	if err != ErrCacheMiss {
+		return err
+	}
+
+	// Collapse loads on the namespaced key so tenants never share a loaded value
+	fullKey := r.key(ctx, key)
+	data, err, _ := r.loads.Do(fullKey, func() (interface{}, error) {
+		value, err := loader()
+		if err != nil {
+			return nil, err
+		}
+
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal value: %w", err)
+		}
+
+		if err := r.ops.Set(ctx, fullKey, json.RawMessage(encoded), ttl); err != nil {
+			return nil, fmt.Errorf("failed to cache loaded value: %w", err)
+		}
+
+		return encoded, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data.([]byte), dest)
+}
+
 // ============================================================================
 // Counter Operations (delegated to RedisCounters)
 // ============================================================================
 
 // Increment increments a counter
 func (r *RedisCache) Increment(ctx context.Context, key string) (int64, error) {
-	return r.counters.Increment(ctx, key)
+	return r.counters.Increment(ctx, r.key(ctx, key))
 }
 
 // IncrementBy increments a counter by a specific amount
 func (r *RedisCache) IncrementBy(ctx context.Context, key string, value int64) (int64, error) {
-	return r.counters.IncrementBy(ctx, key, value)
+	return r.counters.IncrementBy(ctx, r.key(ctx, key), value)
 }
 
 // Decrement decrements a counter
 func (r *RedisCache) Decrement(ctx context.Context, key string) (int64, error) {
-	return r.counters.Decrement(ctx, key)
+	return r.counters.Decrement(ctx, r.key(ctx, key))
 }
 
 // DecrementBy decrements a counter by a specific amount
 func (r *RedisCache) DecrementBy(ctx context.Context, key string, value int64) (int64, error) {
-	return r.counters.DecrementBy(ctx, key, value)
+	return r.counters.DecrementBy(ctx, r.key(ctx, key), value)
 }
 
 // ============================================================================
@@ -142,17 +259,17 @@ func (r *RedisCache) DecrementBy(ctx context.Context, key string, value int64) (
 
 // ListPush adds a value to the end of a list
 func (r *RedisCache) ListPush(ctx context.Context, key string, value interface{}) error {
-	return r.lists.ListPush(ctx, key, value)
+	return r.lists.ListPush(ctx, r.key(ctx, key), value)
 }
 
 // ListPop removes and returns the last element of a list
 func (r *RedisCache) ListPop(ctx context.Context, key string, dest interface{}) error {
-	return r.lists.ListPop(ctx, key, dest)
+	return r.lists.ListPop(ctx, r.key(ctx, key), dest)
 }
 
 // ListLength returns the length of a list
 func (r *RedisCache) ListLength(ctx context.Context, key string) (int64, error) {
-	return r.lists.ListLength(ctx, key)
+	return r.lists.ListLength(ctx, r.key(ctx, key))
 }
 
 // ============================================================================
@@ -161,17 +278,50 @@ func (r *RedisCache) ListLength(ctx context.Context, key string) (int64, error)
 
 // SetAdd adds a member to a set
 func (r *RedisCache) SetAdd(ctx context.Context, key string, member interface{}) error {
-	return r.sets.SetAdd(ctx, key, member)
+	return r.sets.SetAdd(ctx, r.key(ctx, key), member)
 }
 
 // SetMembers returns all members of a set
 func (r *RedisCache) SetMembers(ctx context.Context, key string) ([]string, error) {
-	return r.sets.SetMembers(ctx, key)
+	return r.sets.SetMembers(ctx, r.key(ctx, key))
 }
 
 // SetIsMember checks if a member exists in a set
 func (r *RedisCache) SetIsMember(ctx context.Context, key string, member interface{}) (bool, error) {
-	return r.sets.SetIsMember(ctx, key, member)
+	return r.sets.SetIsMember(ctx, r.key(ctx, key), member)
+}
+
+// ============================================================================
+// Sorted Set Operations (delegated to RedisSortedSets)
+// ============================================================================
+
+// ZAdd adds a member with the given score to a sorted set
+func (r *RedisCache) ZAdd(ctx context.Context, key string, score float64, member interface{}) error {
+	return r.sortedSets.ZAdd(ctx, r.key(ctx, key), score, member)
+}
+
+// ZRange returns the members between the start and stop ranks of a sorted set
+func (r *RedisCache) ZRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return r.sortedSets.ZRange(ctx, r.key(ctx, key), start, stop)
+}
+
+// ZRangeByScore returns the members of a sorted set with scores between min and max
+func (r *RedisCache) ZRangeByScore(ctx context.Context, key, min, max string) ([]string, error) {
+	return r.sortedSets.ZRangeByScore(ctx, r.key(ctx, key), min, max)
+}
+
+// ZRem removes members from a sorted set
+func (r *RedisCache) ZRem(ctx context.Context, key string, members ...interface{}) error {
+	return r.sortedSets.ZRem(ctx, r.key(ctx, key), members...)
+}
+
+// ZScore returns the score of a member in a sorted set
+func (r *RedisCache) ZScore(ctx context.Context, key string, member interface{}) (float64, error) {
+	score, err := r.sortedSets.ZScore(ctx, r.key(ctx, key), member)
+	if err == operations.ErrCacheMiss {
+		return 0, ErrCacheMiss
+	}
+	return score, err
 }
 
 // ============================================================================
@@ -180,17 +330,37 @@ func (r *RedisCache) SetIsMember(ctx context.Context, key string, member interfa
 
 // HashSet sets a field in a hash
 func (r *RedisCache) HashSet(ctx context.Context, key, field string, value interface{}) error {
-	return r.hashes.HashSet(ctx, key, field, value)
+	return r.hashes.HashSet(ctx, r.key(ctx, key), field, value)
 }
 
 // HashGet gets a field from a hash
 func (r *RedisCache) HashGet(ctx context.Context, key, field string, dest interface{}) error {
-	return r.hashes.HashGet(ctx, key, field, dest)
+	return r.hashes.HashGet(ctx, r.key(ctx, key), field, dest)
 }
 
 // HashGetAll gets all fields from a hash
 func (r *RedisCache) HashGetAll(ctx context.Context, key string) (map[string]string, error) {
-	return r.hashes.HashGetAll(ctx, key)
+	return r.hashes.HashGetAll(ctx, r.key(ctx, key))
+}
+
+// ============================================================================
+// JSON Operations (delegated to RedisJSON, requires the RedisJSON module)
+// ============================================================================
+
+// JSONSet sets the value at path in the JSON document stored at key.
+// Returns ErrRedisJSONUnavailable if the server lacks the RedisJSON module.
+func (r *RedisCache) JSONSet(ctx context.Context, key, path string, value interface{}) error {
+	return r.json.JSONSet(ctx, r.key(ctx, key), path, value)
+}
+
+// JSONGet reads the value at path in the JSON document stored at key into dest.
+// Returns ErrRedisJSONUnavailable if the server lacks the RedisJSON module.
+func (r *RedisCache) JSONGet(ctx context.Context, key, path string, dest interface{}) error {
+	err := r.json.JSONGet(ctx, r.key(ctx, key), path, dest)
+	if err == operations.ErrCacheMiss {
+		return ErrCacheMiss
+	}
+	return err
 }
 
 // ============================================================================
@@ -212,7 +382,8 @@ func (r *RedisCache) IsCluster() bool {
 	return r.config != nil && r.config.UseCluster
 }
 
-// GetClient returns the underlying Redis client
+// GetClient returns the underlying Redis client.
+// Commands issued on it directly are not namespaced by KeyPrefix.
 func (r *RedisCache) GetClient() redis.UniversalClient {
 	return r.client
 }
@@ -228,7 +399,11 @@ func (r *RedisCache) BeginTransaction(ctx context.Context) *transactions.RedisTr
 
 // Watch watches one or more keys for modifications during a transaction
 func (r *RedisCache) Watch(ctx context.Context, keys ...string) error {
-	return r.transactions.Watch(ctx, keys...)
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = r.key(ctx, key)
+	}
+	return r.transactions.Watch(ctx, prefixed...)
 }
 
 // WithTransaction executes a function within a transaction
@@ -262,21 +437,34 @@ func (r *RedisCache) WithWatchTransaction(ctx context.Context, keys []string, fn
 // Cache Reloading Methods
 // ============================================================================
 
-// SetReloader sets the cache reloader
+// SetReloader sets the cache reloader. A reloader implementing reload.KeyMapper stores
+// entries under the cache's key namespace, the same keys Get and Set use.
 func (r *RedisCache) SetReloader(reloader reload.CacheReloader) {
+	r.mapKeys(reloader)
 	r.reloader = reloader
 }
 
-// SetInvalidator sets the cache invalidator
+// SetInvalidator sets the cache invalidator. An invalidator implementing reload.KeyMapper
+// deletes keys within the cache's key namespace, the same keys Get and Set use.
 func (r *RedisCache) SetInvalidator(invalidator reload.CacheInvalidator) {
+	r.mapKeys(invalidator)
 	r.invalidator = invalidator
 }
 
-// SetWarmer sets the cache warmer
+// SetWarmer sets the cache warmer. A warmer implementing reload.KeyMapper stores entries
+// under the cache's key namespace, the same keys Get and Set use.
 func (r *RedisCache) SetWarmer(warmer reload.CacheWarmer) {
+	r.mapKeys(warmer)
 	r.warmer = warmer
 }
 
+// mapKeys installs the cache's key namespace into component if it is a reload.KeyMapper
+func (r *RedisCache) mapKeys(component interface{}) {
+	if mapper, ok := component.(reload.KeyMapper); ok {
+		mapper.SetKeyFunc(r.key)
+	}
+}
+
 // GetReloader returns the cache reloader
 func (r *RedisCache) GetReloader() reload.CacheReloader {
 	return r.reloader
@@ -319,7 +507,7 @@ func (r *RedisCache) ReloadAll(ctx context.Context) error {
 // Invalidate invalidates a cache entry
 func (r *RedisCache) Invalidate(ctx context.Context, key string) error {
 	if r.invalidator == nil {
-		return fmt.Errorf("no invalidator configured")
+		return ErrInvalidatorNotConfigured
 	}
 	return r.invalidator.Invalidate(ctx, key)
 }
@@ -327,7 +515,7 @@ func (r *RedisCache) Invalidate(ctx context.Context, key string) error {
 // InvalidatePattern invalidates cache entries matching a pattern
 func (r *RedisCache) InvalidatePattern(ctx context.Context, pattern string) error {
 	if r.invalidator == nil {
-		return fmt.Errorf("no invalidator configured")
+		return ErrInvalidatorNotConfigured
 	}
 	return r.invalidator.InvalidatePattern(ctx, pattern)
 }
@@ -335,7 +523,7 @@ func (r *RedisCache) InvalidatePattern(ctx context.Context, pattern string) erro
 // InvalidateAll invalidates all cache entries
 func (r *RedisCache) InvalidateAll(ctx context.Context) error {
 	if r.invalidator == nil {
-		return fmt.Errorf("no invalidator configured")
+		return ErrInvalidatorNotConfigured
 	}
 	return r.invalidator.InvalidateAll(ctx)
 }
@@ -343,7 +531,7 @@ func (r *RedisCache) InvalidateAll(ctx context.Context) error {
 // InvalidateAndReload invalidates and reloads a cache entry
 func (r *RedisCache) InvalidateAndReload(ctx context.Context, key string) error {
 	if r.invalidator == nil {
-		return fmt.Errorf("no invalidator configured")
+		return ErrInvalidatorNotConfigured
 	}
 	return r.invalidator.InvalidateAndReload(ctx, key)
 }
@@ -351,7 +539,7 @@ func (r *RedisCache) InvalidateAndReload(ctx context.Context, key string) error
 // WarmUp warms up the cache
 func (r *RedisCache) WarmUp(ctx context.Context) error {
 	if r.warmer == nil {
-		return fmt.Errorf("no warmer configured")
+		return ErrWarmerNotConfigured
 	}
 	return r.warmer.WarmUp(ctx)
 }
@@ -359,7 +547,7 @@ func (r *RedisCache) WarmUp(ctx context.Context) error {
 // WarmUpKeys warms up specific cache keys
 func (r *RedisCache) WarmUpKeys(ctx context.Context, keys []string) error {
 	if r.warmer == nil {
-		return fmt.Errorf("no warmer configured")
+		return ErrWarmerNotConfigured
 	}
 	return r.warmer.WarmUpKeys(ctx, keys)
 }
@@ -367,11 +555,21 @@ func (r *RedisCache) WarmUpKeys(ctx context.Context, keys []string) error {
 // IsWarmedUp checks if cache is warmed up
 func (r *RedisCache) IsWarmedUp(ctx context.Context) (bool, error) {
 	if r.warmer == nil {
-		return false, fmt.Errorf("no warmer configured")
+		return false, ErrWarmerNotConfigured
 	}
 	return r.warmer.IsWarmedUp(ctx)
 }
 
+// VerifyConsistency compares the cached keys starting with prefix against the reloader's data
+// source and reports mismatches, correcting them when opts.AutoCorrect is set. Keys and prefix
+// are used as the reloader stores them, without the cache's key prefix.
+func (r *RedisCache) VerifyConsistency(ctx context.Context, prefix string, opts reload.VerifyOptions) (*reload.VerificationReport, error) {
+	if r.reloader == nil {
+		return nil, fmt.Errorf("no reloader configured")
+	}
+	return reload.NewCacheVerifier(r.client, r.reloader.GetDataSource()).Verify(ctx, prefix, opts)
+}
+
 // ============================================================================
 // Custom Reload Logic Methods
 // ============================================================================
@@ -426,6 +624,13 @@ func (r *RedisCache) RemoveDataTransformer(name string) {
 	r.transformerManager.RemoveTransformer(name)
 }
 
+// EnableCompression registers a GzipTransformer so values stored through ReloadWithTransformation
+// of at least minSize bytes are compressed. Read them back with GetTransformed.
+func (r *RedisCache) EnableCompression(minSize int) {
+	r.transformerManager.RemoveTransformer(gzipTransformerName)
+	r.transformerManager.AddTransformer(transformers.NewGzipTransformer(gzipTransformerName, gzipTransformerPriority, minSize))
+}
+
 // ReloadWithCustomLogic reloads data using custom logic
 func (r *RedisCache) ReloadWithCustomLogic(ctx context.Context, key string, strategyName string) error {
 	if r.reloader == nil {
@@ -438,8 +643,8 @@ func (r *RedisCache) ReloadWithCustomLogic(ctx context.Context, key string, stra
 		return fmt.Errorf("custom strategy %s not found", strategyName)
 	}
 
-	// Check if should reload
-	lastReload := time.Now().Add(-time.Hour) // Default to 1 hour ago
+	// Check if should reload; a key that was never reloaded has a zero last-reload time
+	lastReload, _ := r.reloader.GetLastReload(key)
 	if strategy.ShouldReload(ctx, key, lastReload) {
 		// Execute pre-reload hooks
 		if err := r.hookManager.ExecuteBeforeReload(ctx, key, nil); err != nil {
@@ -487,9 +692,8 @@ func (r *RedisCache) ReloadBatchWithCustomLogic(ctx context.Context, keys []stri
 
 	// Filter keys that should be reloaded
 	var keysToReload []string
-	lastReload := time.Now().Add(-time.Hour) // Default to 1 hour ago
-
 	for _, key := range keys {
+		lastReload, _ := r.reloader.GetLastReload(key)
 		if strategy.ShouldReload(ctx, key, lastReload) {
 			keysToReload = append(keysToReload, key)
 		}
@@ -537,8 +741,12 @@ func (r *RedisCache) ReloadBatchWithCustomLogic(ctx context.Context, keys []stri
 	return nil
 }
 
-// Clear clears all data from the cache
+// Clear clears all data from the cache.
+// With a KeyPrefix or a tenant in ctx only the keys in that namespace are removed.
 func (r *RedisCache) Clear(ctx context.Context) error {
+	if r.key(ctx, "") != "" {
+		return r.ops.DeletePattern(ctx, r.key(ctx, "*"))
+	}
 	return r.client.FlushDB(ctx).Err()
 }
 
@@ -571,10 +779,33 @@ func (r *RedisCache) ReloadWithTransformation(ctx context.Context, key string) e
 		return fmt.Errorf("failed to transform data: %w", err)
 	}
 
-	// Store transformed data in cache
-	if err := r.client.Set(ctx, key, transformedData, time.Hour).Err(); err != nil {
+	// Store transformed data in cache with the reloader's configured TTL
+	if err := r.client.Set(ctx, r.key(ctx, key), transformedData, r.reloader.GetTTL()).Err(); err != nil {
 		return fmt.Errorf("failed to store transformed data: %w", err)
 	}
 
+	r.reloader.MarkReloaded(key)
+	return nil
+}
+
+// GetTransformed reads a value stored by ReloadWithTransformation, reverses reversible
+// transformations such as compression, and unmarshals it into dest
+func (r *RedisCache) GetTransformed(ctx context.Context, key string, dest interface{}) error {
+	data, err := r.client.Get(ctx, r.key(ctx, key)).Bytes()
+	if err == redis.Nil {
+		return ErrCacheMiss
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get transformed data: %w", err)
+	}
+
+	restored, err := r.transformerManager.RestoreData(ctx, key, data)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(restored, dest); err != nil {
+		return fmt.Errorf("failed to unmarshal transformed data: %w", err)
+	}
 	return nil
 }
@@ -0,0 +1,81 @@
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrRedisJSONUnavailable is returned when the server does not have the RedisJSON module loaded
+var ErrRedisJSONUnavailable = errors.New("redis: RedisJSON module is not available")
+
+// RedisJSON module availability, detected on first use
+const (
+	jsonModuleUnknown int32 = iota
+	jsonModuleAvailable
+	jsonModuleUnavailable
+)
+
+// RedisJSON handles RedisJSON path operations
+type RedisJSON struct {
+	client redis.UniversalClient
+	module atomic.Int32
+}
+
+// NewRedisJSON creates a new RedisJSON instance
+func NewRedisJSON(client redis.UniversalClient) *RedisJSON {
+	return &RedisJSON{client: client}
+}
+
+// JSONSet sets the value at path in the JSON document stored at key.
+// Use path "$" to set the whole document.
+func (r *RedisJSON) JSONSet(ctx context.Context, key, path string, value interface{}) error {
+	if r.module.Load() == jsonModuleUnavailable {
+		return ErrRedisJSONUnavailable
+	}
+
+	jsonValue, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value: %w", err)
+	}
+
+	return r.detect(r.client.Do(ctx, "JSON.SET", key, path, string(jsonValue)).Err())
+}
+
+// JSONGet reads the value at path in the JSON document stored at key into dest.
+// JSONPath queries ("$...") return an array of matches; legacy paths return the value itself.
+func (r *RedisJSON) JSONGet(ctx context.Context, key, path string, dest interface{}) error {
+	if r.module.Load() == jsonModuleUnavailable {
+		return ErrRedisJSONUnavailable
+	}
+
+	val, err := r.client.Do(ctx, "JSON.GET", key, path).Text()
+	if err != nil {
+		if err == redis.Nil {
+			r.module.CompareAndSwap(jsonModuleUnknown, jsonModuleAvailable)
+			return ErrCacheMiss
+		}
+		return r.detect(err)
+	}
+	r.module.CompareAndSwap(jsonModuleUnknown, jsonModuleAvailable)
+
+	return json.Unmarshal([]byte(val), dest)
+}
+
+// detect records module availability from a command result, mapping a missing module to ErrRedisJSONUnavailable
+func (r *RedisJSON) detect(err error) error {
+	if err == nil {
+		r.module.CompareAndSwap(jsonModuleUnknown, jsonModuleAvailable)
+		return nil
+	}
+	if strings.Contains(strings.ToLower(err.Error()), "unknown command") {
+		r.module.Store(jsonModuleUnavailable)
+		return ErrRedisJSONUnavailable
+	}
+	return err
+}
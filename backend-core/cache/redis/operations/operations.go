@@ -40,6 +40,31 @@ func (r *RedisOperations) Get(ctx context.Context, key string, dest interface{})
 	return json.Unmarshal([]byte(val), dest)
 }
 
+// getDeleteScript reads and removes a key in one step, so only one caller can consume it.
+// It stands in for GETDEL, which needs Redis 6.2.
+//
+// KEYS[1] key
+// Returns the value, or false when the key does not exist
+var getDeleteScript = redis.NewScript(`
+local value = redis.call('GET', KEYS[1])
+if value then
+	redis.call('DEL', KEYS[1])
+end
+return value
+`)
+
+// GetDelete retrieves a value and removes it from the cache atomically
+func (r *RedisOperations) GetDelete(ctx context.Context, key string, dest interface{}) error {
+	val, err := getDeleteScript.Run(ctx, r.client, []string{key}).Text()
+	if err != nil {
+		if err == redis.Nil {
+			return ErrCacheMiss
+		}
+		return err
+	}
+	return json.Unmarshal([]byte(val), dest)
+}
+
 // Delete removes a value from the cache
 func (r *RedisOperations) Delete(ctx context.Context, key string) error {
 	return r.client.Del(ctx, key).Err()
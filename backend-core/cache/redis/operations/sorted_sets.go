@@ -0,0 +1,68 @@
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisSortedSets handles Redis sorted set operations
+type RedisSortedSets struct {
+	client redis.UniversalClient
+}
+
+// NewRedisSortedSets creates a new RedisSortedSets instance
+func NewRedisSortedSets(client redis.UniversalClient) *RedisSortedSets {
+	return &RedisSortedSets{client: client}
+}
+
+// ZAdd adds a member with the given score to a sorted set, updating the score if it exists
+func (r *RedisSortedSets) ZAdd(ctx context.Context, key string, score float64, member interface{}) error {
+	jsonValue, err := json.Marshal(member)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value: %w", err)
+	}
+	return r.client.ZAdd(ctx, key, &redis.Z{Score: score, Member: jsonValue}).Err()
+}
+
+// ZRange returns the members between the start and stop ranks, lowest score first
+func (r *RedisSortedSets) ZRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return r.client.ZRange(ctx, key, start, stop).Result()
+}
+
+// ZRangeByScore returns the members with scores between min and max, lowest score first.
+// Bounds use Redis syntax, so "-inf", "+inf" and exclusive bounds such as "(10" are accepted.
+func (r *RedisSortedSets) ZRangeByScore(ctx context.Context, key, min, max string) ([]string, error) {
+	return r.client.ZRangeByScore(ctx, key, &redis.ZRangeBy{Min: min, Max: max}).Result()
+}
+
+// ZRem removes members from a sorted set
+func (r *RedisSortedSets) ZRem(ctx context.Context, key string, members ...interface{}) error {
+	values := make([]interface{}, 0, len(members))
+	for _, member := range members {
+		jsonValue, err := json.Marshal(member)
+		if err != nil {
+			return fmt.Errorf("failed to marshal value: %w", err)
+		}
+		values = append(values, jsonValue)
+	}
+	return r.client.ZRem(ctx, key, values...).Err()
+}
+
+// ZScore returns the score of a member, or ErrCacheMiss if the member is not in the set
+func (r *RedisSortedSets) ZScore(ctx context.Context, key string, member interface{}) (float64, error) {
+	jsonValue, err := json.Marshal(member)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal value: %w", err)
+	}
+	score, err := r.client.ZScore(ctx, key, string(jsonValue)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return 0, ErrCacheMiss
+		}
+		return 0, err
+	}
+	return score, nil
+}
@@ -0,0 +1,41 @@
+package reload
+
+import (
+	"context"
+	"strings"
+)
+
+// KeyFunc maps the key a data source knows an entry by to the key it is stored under in
+// Redis, e.g. by prepending a key prefix or tenant namespace. It must only prepend, so a
+// pattern maps the same way as the keys it matches.
+type KeyFunc func(ctx context.Context, key string) string
+
+// KeyMapper is implemented by the Redis components that store entries under mapped keys.
+// RedisCache installs its own key namespace into the components it is given.
+type KeyMapper interface {
+	// SetKeyFunc sets the mapping applied to every key before it reaches Redis. It must
+	// be called before the component is used.
+	SetKeyFunc(keyFunc KeyFunc)
+}
+
+// storageKey returns the Redis key for key, which is key itself without a KeyFunc
+func storageKey(ctx context.Context, keyFunc KeyFunc, key string) string {
+	if keyFunc == nil {
+		return key
+	}
+	return keyFunc(ctx, key)
+}
+
+// sourceKeys strips the namespace keyFunc adds from keys read back from Redis, giving
+// the keys the data source knows the entries by
+func sourceKeys(ctx context.Context, keyFunc KeyFunc, keys []string) []string {
+	if keyFunc == nil {
+		return keys
+	}
+	namespace := keyFunc(ctx, "")
+	result := make([]string, len(keys))
+	for i, key := range keys {
+		result[i] = strings.TrimPrefix(key, namespace)
+	}
+	return result
+}
@@ -12,6 +12,7 @@ import (
 type RedisCacheInvalidator struct {
 	client   redis.UniversalClient
 	reloader CacheReloader
+	keyFunc  KeyFunc
 }
 
 // NewRedisCacheInvalidator creates a new Redis cache invalidator
@@ -22,10 +23,15 @@ func NewRedisCacheInvalidator(client redis.UniversalClient, reloader CacheReload
 	}
 }
 
+// SetKeyFunc sets the mapping from data source keys and patterns to the Redis keys entries are stored under
+func (i *RedisCacheInvalidator) SetKeyFunc(keyFunc KeyFunc) {
+	i.keyFunc = keyFunc
+}
+
 // Invalidate invalidates a single cache entry
 func (i *RedisCacheInvalidator) Invalidate(ctx context.Context, key string) error {
 	// Delete the key from cache
-	if err := i.client.Del(ctx, key).Err(); err != nil {
+	if err := i.client.Del(ctx, storageKey(ctx, i.keyFunc, key)).Err(); err != nil {
 		return fmt.Errorf("failed to invalidate key %s: %w", key, err)
 	}
 
@@ -35,7 +41,7 @@ func (i *RedisCacheInvalidator) Invalidate(ctx context.Context, key string) erro
 // InvalidatePattern invalidates cache entries matching a pattern
 func (i *RedisCacheInvalidator) InvalidatePattern(ctx context.Context, pattern string) error {
 	// Get all keys matching the pattern
-	keys, err := i.client.Keys(ctx, pattern).Result()
+	keys, err := i.client.Keys(ctx, storageKey(ctx, i.keyFunc, pattern)).Result()
 	if err != nil {
 		return fmt.Errorf("failed to get keys matching pattern %s: %w", pattern, err)
 	}
@@ -82,7 +88,7 @@ func (i *RedisCacheInvalidator) InvalidateAndReload(ctx context.Context, key str
 // InvalidatePatternAndReload invalidates and reloads cache entries matching a pattern
 func (i *RedisCacheInvalidator) InvalidatePatternAndReload(ctx context.Context, pattern string) error {
 	// Get all keys matching the pattern
-	keys, err := i.client.Keys(ctx, pattern).Result()
+	keys, err := i.client.Keys(ctx, storageKey(ctx, i.keyFunc, pattern)).Result()
 	if err != nil {
 		return fmt.Errorf("failed to get keys matching pattern %s: %w", pattern, err)
 	}
@@ -98,7 +104,7 @@ func (i *RedisCacheInvalidator) InvalidatePatternAndReload(ctx context.Context,
 
 	// Reload all keys if reloader is available
 	if i.reloader != nil {
-		if err := i.reloader.ReloadBatch(ctx, keys); err != nil {
+		if err := i.reloader.ReloadBatch(ctx, sourceKeys(ctx, i.keyFunc, keys)); err != nil {
 			return fmt.Errorf("failed to reload keys matching pattern %s: %w", pattern, err)
 		}
 	}
@@ -109,7 +115,7 @@ func (i *RedisCacheInvalidator) InvalidatePatternAndReload(ctx context.Context,
 // InvalidateWithTTL invalidates a key and sets a new TTL
 func (i *RedisCacheInvalidator) InvalidateWithTTL(ctx context.Context, key string, ttl time.Duration) error {
 	// Set the key with a very short TTL instead of deleting
-	if err := i.client.Expire(ctx, key, ttl).Err(); err != nil {
+	if err := i.client.Expire(ctx, storageKey(ctx, i.keyFunc, key), ttl).Err(); err != nil {
 		return fmt.Errorf("failed to set TTL for key %s: %w", key, err)
 	}
 
@@ -119,7 +125,7 @@ func (i *RedisCacheInvalidator) InvalidateWithTTL(ctx context.Context, key strin
 // InvalidateAndSet invalidates a key and sets a new value
 func (i *RedisCacheInvalidator) InvalidateAndSet(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
 	// Set the new value directly
-	if err := i.client.Set(ctx, key, value, ttl).Err(); err != nil {
+	if err := i.client.Set(ctx, storageKey(ctx, i.keyFunc, key), value, ttl).Err(); err != nil {
 		return fmt.Errorf("failed to set new value for key %s: %w", key, err)
 	}
 
@@ -9,12 +9,16 @@ import (
 	"github.com/go-redis/redis/v8"
 )
 
+// warmedUpMarker is the key set once the cache has been warmed up
+const warmedUpMarker = "cache:warmed_up"
+
 // RedisCacheWarmer implements cache warming for Redis
 type RedisCacheWarmer struct {
 	client   redis.UniversalClient
 	reloader CacheReloader
 	config   *CacheReloadConfig
 	warmedUp bool
+	keyFunc  KeyFunc
 	mu       sync.RWMutex
 }
 
@@ -28,6 +32,11 @@ func NewRedisCacheWarmer(client redis.UniversalClient, reloader CacheReloader, c
 	}
 }
 
+// SetKeyFunc sets the mapping from data source keys to the Redis keys entries are stored under
+func (w *RedisCacheWarmer) SetKeyFunc(keyFunc KeyFunc) {
+	w.keyFunc = keyFunc
+}
+
 // WarmUp warms up the cache with data
 func (w *RedisCacheWarmer) WarmUp(ctx context.Context) error {
 	w.mu.Lock()
@@ -126,7 +135,7 @@ func (w *RedisCacheWarmer) warmUpBatch(ctx context.Context, source DataSource, k
 		}
 
 		// Store in cache
-		if err := w.client.Set(ctx, key, data, w.config.TTL).Err(); err != nil {
+		if err := w.client.Set(ctx, storageKey(ctx, w.keyFunc, key), data, w.config.TTL).Err(); err != nil {
 			return fmt.Errorf("failed to store key %s: %w", key, err)
 		}
 	}
@@ -140,7 +149,7 @@ func (w *RedisCacheWarmer) IsWarmedUp(ctx context.Context) (bool, error) {
 	defer w.mu.RUnlock()
 
 	// Check if we have a warm-up marker
-	exists, err := w.client.Exists(ctx, "cache:warmed_up").Result()
+	exists, err := w.client.Exists(ctx, storageKey(ctx, w.keyFunc, warmedUpMarker)).Result()
 	if err != nil {
 		return false, fmt.Errorf("failed to check warm-up status: %w", err)
 	}
@@ -157,12 +166,12 @@ func (w *RedisCacheWarmer) SetWarmedUp(ctx context.Context, warmedUp bool) error
 
 	if warmedUp {
 		// Set a marker in cache
-		if err := w.client.Set(ctx, "cache:warmed_up", true, 24*time.Hour).Err(); err != nil {
+		if err := w.client.Set(ctx, storageKey(ctx, w.keyFunc, warmedUpMarker), true, 24*time.Hour).Err(); err != nil {
 			return fmt.Errorf("failed to set warm-up marker: %w", err)
 		}
 	} else {
 		// Remove the marker
-		if err := w.client.Del(ctx, "cache:warmed_up").Err(); err != nil {
+		if err := w.client.Del(ctx, storageKey(ctx, w.keyFunc, warmedUpMarker)).Err(); err != nil {
 			return fmt.Errorf("failed to remove warm-up marker: %w", err)
 		}
 	}
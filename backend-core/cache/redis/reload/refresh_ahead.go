@@ -0,0 +1,106 @@
+package reload
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	// defaultRefreshAheadFraction is used when RefreshAheadFraction is not set
+	defaultRefreshAheadFraction = 0.2
+	// defaultRefreshAheadBeta is used when RefreshAheadBeta is not set
+	defaultRefreshAheadBeta = 1.0
+	// defaultRefreshAheadDelta stands in for the reload time until one has been measured
+	defaultRefreshAheadDelta = 100 * time.Millisecond
+)
+
+// RefreshAheader is implemented by reloaders that reload keys in the background shortly
+// before they expire. RedisCache calls it on every cache hit.
+type RefreshAheader interface {
+	RefreshAhead(ctx context.Context, key string) (bool, error)
+}
+
+// ShouldRefreshAhead decides whether key should be reloaded before it expires.
+// Once the key's remaining TTL drops below RefreshAheadFraction of the configured TTL,
+// it uses XFetch: reload when delta * beta * -ln(rand) >= remaining TTL, where delta is the
+// average reload time. The chance of reloading grows as expiry approaches, so on a hot
+// key one caller reloads early instead of every caller missing at once.
+func (r *RedisCacheReloader) ShouldRefreshAhead(ctx context.Context, key string) (bool, error) {
+	r.mu.RLock()
+	ttl := r.config.TTL
+	fraction := r.config.RefreshAheadFraction
+	beta := r.config.RefreshAheadBeta
+	delta := r.metrics.AverageReloadTime
+	r.mu.RUnlock()
+
+	if ttl <= 0 {
+		return false, nil
+	}
+	if fraction <= 0 {
+		fraction = defaultRefreshAheadFraction
+	}
+	if beta <= 0 {
+		beta = defaultRefreshAheadBeta
+	}
+	if delta <= 0 {
+		delta = defaultRefreshAheadDelta
+	}
+
+	remaining, err := r.client.PTTL(ctx, storageKey(ctx, r.keyFunc, key)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to get TTL for key %s: %w", key, err)
+	}
+	// Missing keys (-2) are loaded on miss and keys without expiry (-1) never expire
+	if remaining < 0 {
+		return false, nil
+	}
+	if float64(remaining) > float64(ttl)*fraction {
+		return false, nil
+	}
+
+	// 1-rand.Float64() is in (0, 1], keeping the logarithm finite
+	gap := float64(delta) * beta * -math.Log(1-rand.Float64())
+	return gap >= float64(remaining), nil
+}
+
+// RefreshAhead reloads key in the background when ShouldRefreshAhead decides it is time,
+// and reports whether a reload was started. At most one refresh per stored key runs at a
+// time, so the same key of different tenants is refreshed independently. The reload keeps
+// ctx's values, such as the tenant, but not its deadline. It only acts when the strategy
+// is StrategyRefreshAhead.
+func (r *RedisCacheReloader) RefreshAhead(ctx context.Context, key string) (bool, error) {
+	if r.GetReloadStrategy() != StrategyRefreshAhead {
+		return false, nil
+	}
+
+	refresh, err := r.ShouldRefreshAhead(ctx, key)
+	if err != nil || !refresh {
+		return false, err
+	}
+
+	inFlightKey := storageKey(ctx, r.keyFunc, key)
+	if _, inFlight := r.refreshing.LoadOrStore(inFlightKey, struct{}{}); inFlight {
+		return false, nil
+	}
+
+	go func() {
+		defer r.refreshing.Delete(inFlightKey)
+
+		timeout := r.GetTTL()
+		if timeout <= 0 || timeout > time.Minute {
+			timeout = time.Minute
+		}
+		reloadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		defer cancel()
+
+		if err := r.Reload(reloadCtx, key); err != nil {
+			// Log error but keep serving the current value until it expires
+			fmt.Printf("Refresh-ahead reload failed for key %s: %v\n", key, err)
+		}
+	}()
+
+	return true, nil
+}
@@ -49,6 +49,15 @@ type CacheReloader interface {
 
 	// SetDataSource sets the data source
 	SetDataSource(source DataSource)
+
+	// GetTTL returns the time-to-live applied to reloaded data
+	GetTTL() time.Duration
+
+	// GetLastReload returns when key was last reloaded, and false if it never was
+	GetLastReload(key string) (time.Time, bool)
+
+	// MarkReloaded records that key was reloaded now
+	MarkReloaded(key string)
 }
 
 // CacheInvalidator defines the interface for cache invalidation
@@ -90,6 +99,8 @@ const (
 	StrategyLazy CacheReloadStrategy = "lazy"
 	// StrategyScheduled reloads on schedule
 	StrategyScheduled CacheReloadStrategy = "scheduled"
+	// StrategyRefreshAhead reloads popular keys in the background shortly before they expire
+	StrategyRefreshAhead CacheReloadStrategy = "refresh_ahead"
 )
 
 // CacheReloadTrigger defines what triggers a cache reload
@@ -139,6 +150,12 @@ type CacheReloadConfig struct {
 
 	// WarmUpKeys defines specific keys to warm up
 	WarmUpKeys []string `mapstructure:"warm_up_keys" json:"warm_up_keys" yaml:"warm_up_keys"`
+
+	// RefreshAheadFraction is the fraction of TTL remaining below which refresh-ahead may reload a key
+	RefreshAheadFraction float64 `mapstructure:"refresh_ahead_fraction" json:"refresh_ahead_fraction" yaml:"refresh_ahead_fraction"`
+
+	// RefreshAheadBeta scales how eagerly refresh-ahead reloads; above 1 favors earlier reloads
+	RefreshAheadBeta float64 `mapstructure:"refresh_ahead_beta" json:"refresh_ahead_beta" yaml:"refresh_ahead_beta"`
 }
 
 // ReloadResult represents the result of a cache reload operation
@@ -191,16 +208,27 @@ type RedisCacheReloader struct {
 	metrics  *ReloadMetrics
 	mu       sync.RWMutex
 	stopChan chan struct{}
+
+	// lastReload has its own lock because it is updated while mu is held
+	lastReload   map[string]time.Time
+	lastReloadMu sync.RWMutex
+
+	// refreshing holds the keys with a refresh-ahead reload in flight
+	refreshing sync.Map
+
+	// keyFunc maps data source keys to Redis keys, see SetKeyFunc
+	keyFunc KeyFunc
 }
 
 // NewRedisCacheReloader creates a new Redis cache reloader
 func NewRedisCacheReloader(client redis.UniversalClient, config *CacheReloadConfig, source DataSource) *RedisCacheReloader {
 	reloader := &RedisCacheReloader{
-		client:   client,
-		config:   config,
-		source:   source,
-		metrics:  &ReloadMetrics{},
-		stopChan: make(chan struct{}),
+		client:     client,
+		config:     config,
+		source:     source,
+		metrics:    &ReloadMetrics{},
+		stopChan:   make(chan struct{}),
+		lastReload: make(map[string]time.Time),
 	}
 
 	// Start scheduled reloading if enabled
@@ -237,12 +265,12 @@ func (r *RedisCacheReloader) Reload(ctx context.Context, key string) error {
 	// Store in cache based on strategy
 	switch r.config.Strategy {
 	case StrategyRefresh, StrategyReplace:
-		err = r.client.Set(ctx, key, data, r.config.TTL).Err()
+		err = r.client.Set(ctx, storageKey(ctx, r.keyFunc, key), data, r.config.TTL).Err()
 	case StrategyLazy:
 		// For lazy loading, we don't pre-populate the cache
 		return nil
 	default:
-		err = r.client.Set(ctx, key, data, r.config.TTL).Err()
+		err = r.client.Set(ctx, storageKey(ctx, r.keyFunc, key), data, r.config.TTL).Err()
 	}
 
 	if err != nil {
@@ -250,6 +278,7 @@ func (r *RedisCacheReloader) Reload(ctx context.Context, key string) error {
 		return fmt.Errorf("failed to store data for key %s: %w", key, err)
 	}
 
+	r.MarkReloaded(key)
 	return nil
 }
 
@@ -322,11 +351,12 @@ func (r *RedisCacheReloader) ReloadAll(ctx context.Context) error {
 
 	// Store all data
 	for key, data := range allData {
-		if err := r.client.Set(ctx, key, data, r.config.TTL).Err(); err != nil {
+		if err := r.client.Set(ctx, storageKey(ctx, r.keyFunc, key), data, r.config.TTL).Err(); err != nil {
 			result.Errors = append(result.Errors, fmt.Errorf("failed to store key %s: %w", key, err))
 			result.KeysFailed++
 		} else {
 			result.KeysReloaded++
+			r.MarkReloaded(key)
 		}
 	}
 
@@ -352,17 +382,23 @@ func (r *RedisCacheReloader) reloadBatch(ctx context.Context, keys []string, res
 		}
 
 		// Store in cache
-		if err := r.client.Set(ctx, key, data, r.config.TTL).Err(); err != nil {
+		if err := r.client.Set(ctx, storageKey(ctx, r.keyFunc, key), data, r.config.TTL).Err(); err != nil {
 			result.Errors = append(result.Errors, fmt.Errorf("failed to store key %s: %w", key, err))
 			result.KeysFailed++
 		} else {
 			result.KeysReloaded++
+			r.MarkReloaded(key)
 		}
 	}
 
 	return nil
 }
 
+// SetKeyFunc sets the mapping from data source keys to the Redis keys entries are stored under
+func (r *RedisCacheReloader) SetKeyFunc(keyFunc KeyFunc) {
+	r.keyFunc = keyFunc
+}
+
 // GetReloadStrategy returns the current reload strategy
 func (r *RedisCacheReloader) GetReloadStrategy() CacheReloadStrategy {
 	r.mu.RLock()
@@ -391,6 +427,28 @@ func (r *RedisCacheReloader) SetDataSource(source DataSource) {
 	r.source = source
 }
 
+// GetTTL returns the time-to-live applied to reloaded data
+func (r *RedisCacheReloader) GetTTL() time.Duration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.config.TTL
+}
+
+// GetLastReload returns when key was last reloaded, and false if it never was
+func (r *RedisCacheReloader) GetLastReload(key string) (time.Time, bool) {
+	r.lastReloadMu.RLock()
+	defer r.lastReloadMu.RUnlock()
+	t, ok := r.lastReload[key]
+	return t, ok
+}
+
+// MarkReloaded records that key was reloaded now
+func (r *RedisCacheReloader) MarkReloaded(key string) {
+	r.lastReloadMu.Lock()
+	defer r.lastReloadMu.Unlock()
+	r.lastReload[key] = time.Now()
+}
+
 // startScheduledReload starts the scheduled reload process
 func (r *RedisCacheReloader) startScheduledReload() {
 	ticker := time.NewTicker(r.config.ReloadInterval)
@@ -416,11 +474,8 @@ func (r *RedisCacheReloader) Stop() {
 	close(r.stopChan)
 }
 
-// updateMetrics updates the reload metrics
+// updateMetrics updates the reload metrics. Callers must hold r.mu.
 func (r *RedisCacheReloader) updateMetrics(duration time.Duration, success bool) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
 	r.metrics.TotalReloads++
 	if success {
 		r.metrics.SuccessfulReloads++
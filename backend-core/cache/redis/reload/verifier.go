@@ -0,0 +1,258 @@
+package reload
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// MismatchReason describes why a cached entry failed verification
+type MismatchReason string
+
+const (
+	// MismatchValue means the cached value differs from the data source value
+	MismatchValue MismatchReason = "value_mismatch"
+	// MismatchSourceError means the data source could not load or validate the key
+	MismatchSourceError MismatchReason = "source_error"
+	// MismatchCacheError means the cached value could not be read
+	MismatchCacheError MismatchReason = "cache_error"
+)
+
+// defaultVerifyScanCount is the SCAN batch size used when VerifyOptions.ScanCount is not set
+const defaultVerifyScanCount = 500
+
+// ErrEmptyVerifyPrefix is returned when Verify is called without a key prefix
+var ErrEmptyVerifyPrefix = errors.New("cache verification requires a key prefix")
+
+// VerifyOptions controls a cache verification run
+type VerifyOptions struct {
+	// AutoCorrect overwrites mismatched cached values with the data source value, keeping their TTL.
+	// Entries the data source cannot load are reported but never deleted.
+	AutoCorrect bool
+
+	// ScanCount is the SCAN batch size used to list cached keys
+	ScanCount int64
+}
+
+// VerificationMismatch is a cached entry that does not match the data source
+type VerificationMismatch struct {
+	Key       string         `json:"key"`
+	Reason    MismatchReason `json:"reason"`
+	Cached    string         `json:"cached,omitempty"`
+	Expected  string         `json:"expected,omitempty"`
+	Error     string         `json:"error,omitempty"`
+	Corrected bool           `json:"corrected"`
+}
+
+// VerificationReport is the result of a cache verification run
+type VerificationReport struct {
+	// Prefix is the key prefix that was verified
+	Prefix string `json:"prefix"`
+
+	// KeysChecked is the number of cached keys compared with the data source
+	KeysChecked int `json:"keys_checked"`
+
+	// NotCached lists data source keys under the prefix that have no cached entry.
+	// They are informational: entries are often cached lazily.
+	NotCached []string `json:"not_cached,omitempty"`
+
+	// Mismatches lists the cached entries that failed verification
+	Mismatches []VerificationMismatch `json:"mismatches,omitempty"`
+
+	// Corrected is the number of mismatches fixed by AutoCorrect
+	Corrected int `json:"corrected"`
+
+	// Duration is the time taken for the verification
+	Duration time.Duration `json:"duration"`
+
+	// Timestamp is when the verification started
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Consistent reports whether every cached entry matched the data source
+func (r *VerificationReport) Consistent() bool {
+	return len(r.Mismatches) == 0
+}
+
+// CacheVerifier compares cached entries with their data source. Values are compared in the
+// form RedisCacheReloader stores them, and JSON values are compared by content, so key order
+// and whitespace do not count as differences.
+type CacheVerifier struct {
+	client redis.UniversalClient
+	source DataSource
+}
+
+// NewCacheVerifier creates a new cache verifier
+func NewCacheVerifier(client redis.UniversalClient, source DataSource) *CacheVerifier {
+	return &CacheVerifier{
+		client: client,
+		source: source,
+	}
+}
+
+// Verify compares every cached key starting with prefix against the data source and reports
+// the ones that differ. Nothing is modified unless opts.AutoCorrect is set.
+func (v *CacheVerifier) Verify(ctx context.Context, prefix string, opts VerifyOptions) (*VerificationReport, error) {
+	if prefix == "" {
+		return nil, ErrEmptyVerifyPrefix
+	}
+	if v.source == nil {
+		return nil, fmt.Errorf("no data source available for verification")
+	}
+
+	report := &VerificationReport{
+		Prefix:    prefix,
+		Timestamp: time.Now(),
+	}
+	defer func() {
+		report.Duration = time.Since(report.Timestamp)
+	}()
+
+	cachedKeys, err := v.scanKeys(ctx, prefix+"*", opts.ScanCount)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := make(map[string]struct{}, len(cachedKeys))
+	for _, key := range cachedKeys {
+		cached[key] = struct{}{}
+		if mismatch := v.verifyKey(ctx, key, opts.AutoCorrect); mismatch != nil {
+			if mismatch.Corrected {
+				report.Corrected++
+			}
+			report.Mismatches = append(report.Mismatches, *mismatch)
+		}
+		report.KeysChecked++
+	}
+
+	sourceKeys, err := v.source.GetDataKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data keys: %w", err)
+	}
+	for _, key := range sourceKeys {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if _, ok := cached[key]; !ok {
+			report.NotCached = append(report.NotCached, key)
+		}
+	}
+
+	return report, nil
+}
+
+// verifyKey compares one cached key with the data source, returning nil when they match
+func (v *CacheVerifier) verifyKey(ctx context.Context, key string, autoCorrect bool) *VerificationMismatch {
+	cachedValue, err := v.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		// Expired or deleted since the scan
+		return nil
+	}
+	if err != nil {
+		return &VerificationMismatch{Key: key, Reason: MismatchCacheError, Error: err.Error()}
+	}
+
+	data, err := v.source.LoadData(ctx, key)
+	if err == nil {
+		err = v.source.ValidateData(data)
+	}
+	if err != nil {
+		return &VerificationMismatch{Key: key, Reason: MismatchSourceError, Cached: cachedValue, Error: err.Error()}
+	}
+
+	expected, err := encodeVerifyValue(data)
+	if err != nil {
+		return &VerificationMismatch{Key: key, Reason: MismatchSourceError, Cached: cachedValue, Error: err.Error()}
+	}
+	if valuesMatch(cachedValue, expected) {
+		return nil
+	}
+
+	mismatch := &VerificationMismatch{
+		Key:      key,
+		Reason:   MismatchValue,
+		Cached:   cachedValue,
+		Expected: expected,
+	}
+	if autoCorrect {
+		if err := v.client.Set(ctx, key, expected, redis.KeepTTL).Err(); err != nil {
+			mismatch.Error = fmt.Sprintf("failed to correct: %v", err)
+		} else {
+			mismatch.Corrected = true
+		}
+	}
+	return mismatch
+}
+
+// scanKeys lists the keys matching pattern
+func (v *CacheVerifier) scanKeys(ctx context.Context, pattern string, count int64) ([]string, error) {
+	if count <= 0 {
+		count = defaultVerifyScanCount
+	}
+
+	var keys []string
+	var cursor uint64
+	for {
+		batch, next, err := v.client.Scan(ctx, cursor, pattern, count).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan keys matching %s: %w", pattern, err)
+		}
+		keys = append(keys, batch...)
+		if next == 0 {
+			return keys, nil
+		}
+		cursor = next
+	}
+}
+
+// encodeVerifyValue returns data in the form Redis stores it: strings and bytes as they are,
+// numbers and booleans the way the Redis client formats them, and anything else as JSON
+func encodeVerifyValue(data interface{}) (string, error) {
+	switch v := data.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case encoding.BinaryMarshaler:
+		b, err := v.MarshalBinary()
+		return string(b), err
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode source value: %w", err)
+	}
+	return string(b), nil
+}
+
+// valuesMatch compares two stored values, by content when both are JSON
+func valuesMatch(cached, expected string) bool {
+	if cached == expected {
+		return true
+	}
+
+	var a, b interface{}
+	if json.Unmarshal([]byte(cached), &a) != nil || json.Unmarshal([]byte(expected), &b) != nil {
+		return false
+	}
+	return reflect.DeepEqual(a, b)
+}
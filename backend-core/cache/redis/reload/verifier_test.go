@@ -0,0 +1,117 @@
+package reload
+
+import (
+	"context"
+	"path"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// mapClient is a redis.UniversalClient holding string values in memory. Only the commands
+// used by the verifier are implemented.
+type mapClient struct {
+	redis.UniversalClient
+	values map[string]string
+	sets   int
+}
+
+func (m *mapClient) Get(ctx context.Context, key string) *redis.StringCmd {
+	value, ok := m.values[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(value, nil)
+}
+
+func (m *mapClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	m.values[key] = value.(string)
+	m.sets++
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (m *mapClient) Scan(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd {
+	var keys []string
+	for key := range m.values {
+		if matched, _ := path.Match(match, key); matched {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return redis.NewScanCmdResult(keys, 0, nil)
+}
+
+func newVerifierFixture() (*mapClient, *MockDataSource) {
+	client := &mapClient{values: map[string]string{
+		"users:1":  `{"name":"alice","age":30}`,
+		"users:2":  `{"name":"bob","age":40}`,
+		"orders:1": "stale",
+	}}
+	source := NewMockDataSource()
+	// Same content as the cache in a different key order
+	source.SetData("users:1", map[string]interface{}{"age": 30, "name": "alice"})
+	// The database was updated without the cache
+	source.SetData("users:2", map[string]interface{}{"name": "bob", "age": 41})
+	source.SetData("users:3", map[string]interface{}{"name": "carol", "age": 25})
+	source.SetData("orders:1", "fresh")
+	return client, source
+}
+
+func TestVerifyDetectsMismatchWithoutModifying(t *testing.T) {
+	client, source := newVerifierFixture()
+
+	report, err := NewCacheVerifier(client, source).Verify(context.Background(), "users:", VerifyOptions{})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	if report.KeysChecked != 2 {
+		t.Errorf("KeysChecked = %d, want 2", report.KeysChecked)
+	}
+	if report.Consistent() || len(report.Mismatches) != 1 {
+		t.Fatalf("mismatches = %+v, want one", report.Mismatches)
+	}
+	mismatch := report.Mismatches[0]
+	if mismatch.Key != "users:2" || mismatch.Reason != MismatchValue || mismatch.Corrected {
+		t.Errorf("mismatch = %+v, want an uncorrected value mismatch on users:2", mismatch)
+	}
+	if len(report.NotCached) != 1 || report.NotCached[0] != "users:3" {
+		t.Errorf("NotCached = %v, want [users:3]", report.NotCached)
+	}
+	if client.sets != 0 || client.values["users:2"] != `{"name":"bob","age":40}` {
+		t.Errorf("verification without AutoCorrect modified the cache")
+	}
+}
+
+func TestVerifyAutoCorrects(t *testing.T) {
+	client, source := newVerifierFixture()
+	verifier := NewCacheVerifier(client, source)
+
+	report, err := verifier.Verify(context.Background(), "users:", VerifyOptions{AutoCorrect: true})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if report.Corrected != 1 || !report.Mismatches[0].Corrected {
+		t.Fatalf("report = %+v, want the mismatch corrected", report)
+	}
+	if client.values["orders:1"] != "stale" {
+		t.Error("auto-correction touched a key outside the prefix")
+	}
+
+	report, err = verifier.Verify(context.Background(), "users:", VerifyOptions{})
+	if err != nil {
+		t.Fatalf("second Verify() error = %v", err)
+	}
+	if !report.Consistent() {
+		t.Errorf("mismatches after correction = %+v", report.Mismatches)
+	}
+}
+
+func TestVerifyRequiresPrefix(t *testing.T) {
+	client, source := newVerifierFixture()
+	if _, err := NewCacheVerifier(client, source).Verify(context.Background(), "", VerifyOptions{}); err != ErrEmptyVerifyPrefix {
+		t.Errorf("Verify() error = %v, want ErrEmptyVerifyPrefix", err)
+	}
+}
@@ -19,3 +19,11 @@ type DataTransformer interface {
 	// GetPriority returns the priority of this transformer (lower = higher priority)
 	GetPriority() int
 }
+
+// ReversibleTransformer is a DataTransformer whose output must be restored when read back
+type ReversibleTransformer interface {
+	DataTransformer
+
+	// Restore reverses Transform on a value read from cache
+	Restore(ctx context.Context, key string, data []byte) ([]byte, error)
+}
@@ -51,6 +51,25 @@ func (m *DataTransformerManager) TransformData(ctx context.Context, key string,
 	return transformedData, nil
 }
 
+// RestoreData reverses the reversible transformers on data read from cache, last applied first
+func (m *DataTransformerManager) RestoreData(ctx context.Context, key string, data []byte) ([]byte, error) {
+	restored := data
+
+	for i := len(m.transformers) - 1; i >= 0; i-- {
+		transformer, ok := m.transformers[i].(ReversibleTransformer)
+		if !ok {
+			continue
+		}
+		out, err := transformer.Restore(ctx, key, restored)
+		if err != nil {
+			return nil, fmt.Errorf("transformer %s failed to restore: %w", transformer.GetName(), err)
+		}
+		restored = out
+	}
+
+	return restored, nil
+}
+
 // GetTransformers returns all registered transformers
 func (m *DataTransformerManager) GetTransformers() []DataTransformer {
 	return m.transformers
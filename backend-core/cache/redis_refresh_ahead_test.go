@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend-core/cache/redis/reload"
+	"backend-core/config"
+)
+
+// newRefreshAheadCache returns a cache whose refresh-ahead reloader loads "fresh" for users:1
+func newRefreshAheadCache(t *testing.T) (*RedisCache, *fakeRedis) {
+	t.Helper()
+
+	server := newFakeRedis(t)
+	r := NewRedisCache(&config.RedisConfig{Addr: server.listener.Addr().String()})
+	t.Cleanup(func() { r.Close() })
+
+	source := reload.NewMockDataSource()
+	source.SetData("users:1", `"fresh"`)
+	r.SetReloader(reload.NewRedisCacheReloader(r.GetClient(), &reload.CacheReloadConfig{
+		TTL:                  10 * time.Second,
+		Strategy:             reload.StrategyRefreshAhead,
+		RefreshAheadFraction: 0.5,
+		// A large beta makes the XFetch draw reload every key inside the window
+		RefreshAheadBeta: 1e9,
+	}, source))
+	return r, server
+}
+
+func TestGetRefreshesKeyInsideWindowInBackground(t *testing.T) {
+	r, server := newRefreshAheadCache(t)
+	ctx := context.Background()
+
+	// 2s left of a 10s TTL is inside the 50% refresh-ahead window
+	if err := r.Set(ctx, "users:1", "stale", 2*time.Second); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	var value string
+	if err := r.Get(ctx, "users:1", &value); err != nil || value != "stale" {
+		t.Fatalf("Get() = %q, %v, want the cached %q", value, err, "stale")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for server.value("users:1") != `"fresh"` {
+		if time.Now().After(deadline) {
+			t.Fatalf("stored value = %s, want it reloaded in the background", server.value("users:1"))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := r.Get(ctx, "users:1", &value); err != nil || value != "fresh" {
+		t.Errorf("Get() after the refresh = %q, %v, want %q", value, err, "fresh")
+	}
+}
+
+func TestGetLeavesKeyOutsideWindowAlone(t *testing.T) {
+	r, server := newRefreshAheadCache(t)
+	ctx := context.Background()
+
+	// 9s left of a 10s TTL is outside the 50% refresh-ahead window
+	if err := r.Set(ctx, "users:1", "stale", 9*time.Second); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	var value string
+	if err := r.Get(ctx, "users:1", &value); err != nil || value != "stale" {
+		t.Fatalf("Get() = %q, %v, want the cached %q", value, err, "stale")
+	}
+
+	// Longer than a reload takes, so a reload would have finished by now
+	time.Sleep(100 * time.Millisecond)
+	if got := server.value("users:1"); got != `"stale"` {
+		t.Errorf("stored value = %s, want the key left untouched", got)
+	}
+}
@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"backend-core/cache/redis/reload"
+	"backend-core/config"
+	"backend-core/ctxkeys"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var errCommandRecorded = errors.New("command recorded")
+
+// recordingHook records every command and stops it before it reaches the network
+type recordingHook struct {
+	commands []string
+}
+
+func (h *recordingHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	args := make([]string, len(cmd.Args()))
+	for i, arg := range cmd.Args() {
+		args[i] = fmt.Sprint(arg)
+	}
+	h.commands = append(h.commands, strings.Join(args, " "))
+	return ctx, errCommandRecorded
+}
+
+func (h *recordingHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	return nil
+}
+
+func (h *recordingHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return ctx, errCommandRecorded
+}
+
+func (h *recordingHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	return nil
+}
+
+func newRecordingCache(t *testing.T) (*RedisCache, *recordingHook) {
+	t.Helper()
+
+	r := NewRedisCache(&config.RedisConfig{Addr: "127.0.0.1:0", KeyPrefix: "svc", MultiTenant: true})
+	t.Cleanup(func() { r.Close() })
+
+	hook := &recordingHook{}
+	r.GetClient().AddHook(hook)
+
+	reloadConfig := &reload.CacheReloadConfig{TTL: time.Minute, Strategy: reload.StrategyRefresh}
+	source := reload.NewMockDataSource()
+	source.SetData("users:1", "alice")
+
+	reloader := reload.NewRedisCacheReloader(r.GetClient(), reloadConfig, source)
+	r.SetReloader(reloader)
+	r.SetInvalidator(reload.NewRedisCacheInvalidator(r.GetClient(), reloader))
+	r.SetWarmer(reload.NewRedisCacheWarmer(r.GetClient(), reloader, reloadConfig))
+	return r, hook
+}
+
+func TestReloadPathsUseCacheKeyNamespace(t *testing.T) {
+	ctx := ctxkeys.WithTenantID(context.Background(), "acme")
+	const storedKey = "svc:tenant:acme:users:1"
+
+	for name, run := range map[string]func(r *RedisCache) error{
+		"Reload":              func(r *RedisCache) error { return r.Reload(ctx, "users:1") },
+		"ReloadBatch":         func(r *RedisCache) error { return r.ReloadBatch(ctx, []string{"users:1"}) },
+		"Invalidate":          func(r *RedisCache) error { return r.Invalidate(ctx, "users:1") },
+		"InvalidatePattern":   func(r *RedisCache) error { return r.InvalidatePattern(ctx, "users:1") },
+		"InvalidateAndReload": func(r *RedisCache) error { return r.InvalidateAndReload(ctx, "users:1") },
+		"SafeWarmUpKeys":      func(r *RedisCache) error { return r.SafeWarmUpKeys(ctx, []string{"users:1"}) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			r, hook := newRecordingCache(t)
+			run(r)
+
+			if len(hook.commands) == 0 {
+				t.Fatal("no Redis command was sent")
+			}
+			for _, command := range hook.commands {
+				if fields := strings.Fields(command); len(fields) < 2 || fields[1] != storedKey {
+					t.Errorf("command %q does not address %q", command, storedKey)
+				}
+			}
+		})
+	}
+}
+
+func TestSafeInvalidatePatternAppliesNamespaceOnce(t *testing.T) {
+	r, hook := newRecordingCache(t)
+	ctx := ctxkeys.WithTenantID(context.Background(), "acme")
+
+	r.SafeInvalidatePattern(ctx, "users:*")
+
+	if len(hook.commands) != 1 || hook.commands[0] != "keys svc:tenant:acme:users:*" {
+		t.Errorf("commands = %q, want [%q]", hook.commands, "keys svc:tenant:acme:users:*")
+	}
+}
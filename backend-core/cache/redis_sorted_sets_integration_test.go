@@ -0,0 +1,79 @@
+//go:build integration
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+)
+
+// newIntegrationRedisCache connects to the Redis at REDIS_ADDR, skipping the test without one
+func newIntegrationRedisCache(t *testing.T) *RedisCache {
+	t.Helper()
+
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_ADDR not set")
+	}
+	redisCache := NewStandaloneRedisCache(addr, os.Getenv("REDIS_PASSWORD"), 0)
+	if err := redisCache.Ping(context.Background()); err != nil {
+		t.Fatalf("failed to reach Redis at %s: %v", addr, err)
+	}
+	t.Cleanup(func() { redisCache.Close() })
+	return redisCache
+}
+
+func TestRedisCacheSortedSets(t *testing.T) {
+	redisCache := newIntegrationRedisCache(t)
+	ctx := context.Background()
+	key := "test:leaderboard:" + t.Name()
+	redisCache.Delete(ctx, key)
+	t.Cleanup(func() { redisCache.Delete(context.Background(), key) })
+
+	for member, score := range map[string]float64{"ann": 30, "bob": 10, "cid": 20} {
+		if err := redisCache.ZAdd(ctx, key, score, member); err != nil {
+			t.Fatalf("ZAdd(%s) error = %v", member, err)
+		}
+	}
+
+	members, err := redisCache.ZRange(ctx, key, 0, -1)
+	if err != nil {
+		t.Fatalf("ZRange() error = %v", err)
+	}
+	if want := []string{`"bob"`, `"cid"`, `"ann"`}; !equalStrings(members, want) {
+		t.Errorf("ZRange() = %v, want %v", members, want)
+	}
+
+	members, err = redisCache.ZRangeByScore(ctx, key, "(10", "+inf")
+	if err != nil {
+		t.Fatalf("ZRangeByScore() error = %v", err)
+	}
+	if want := []string{`"cid"`, `"ann"`}; !equalStrings(members, want) {
+		t.Errorf("ZRangeByScore() = %v, want %v", members, want)
+	}
+
+	if score, err := redisCache.ZScore(ctx, key, "cid"); err != nil || score != 20 {
+		t.Errorf("ZScore(cid) = %v, %v, want 20", score, err)
+	}
+
+	if err := redisCache.ZRem(ctx, key, "cid"); err != nil {
+		t.Fatalf("ZRem() error = %v", err)
+	}
+	if _, err := redisCache.ZScore(ctx, key, "cid"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("ZScore() of a removed member error = %v, want ErrCacheMiss", err)
+	}
+}
+
+func equalStrings(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
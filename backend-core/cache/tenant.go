@@ -0,0 +1,27 @@
+package cache
+
+import (
+	"context"
+	"net/url"
+
+	"backend-core/ctxkeys"
+)
+
+// tenantKeyPrefix starts the namespace of tenant-scoped keys
+const tenantKeyPrefix = "tenant"
+
+// tenantNamespace returns the key namespace of the tenant in ctx, or "" when ctx has no tenant.
+// The tenant ID is escaped so it cannot contain the ":" separator or wildcards, which keeps one
+// tenant's namespace from overlapping another's.
+func tenantNamespace(ctx context.Context) string {
+	tenantID := ctxkeys.TenantIDFrom(ctx)
+	if tenantID == "" {
+		return ""
+	}
+	return tenantKeyPrefix + ":" + url.QueryEscape(tenantID)
+}
+
+// IsMultiTenant reports whether keys are namespaced by the tenant ID in the context
+func (r *RedisCache) IsMultiTenant() bool {
+	return r.multiTenant
+}
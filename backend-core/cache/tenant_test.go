@@ -0,0 +1,231 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"backend-core/config"
+	"backend-core/ctxkeys"
+)
+
+// fakeRedis is an in-memory Redis server speaking just enough RESP for GET, SET, DEL and PTTL
+type fakeRedis struct {
+	listener net.Listener
+
+	mu      sync.Mutex
+	values  map[string]string
+	expires map[string]time.Time
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &fakeRedis{listener: listener, values: make(map[string]string), expires: make(map[string]time.Time)}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+// keys returns the stored keys in order
+func (s *fakeRedis) keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// value returns the stored value of key
+func (s *fakeRedis) value(key string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.values[key]
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		if _, err := io.WriteString(conn, s.execute(args)); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeRedis) execute(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch strings.ToLower(args[0]) {
+	case "ping":
+		return "+PONG\r\n"
+	case "set":
+		s.values[args[1]] = args[2]
+		delete(s.expires, args[1])
+		if len(args) == 5 {
+			n, err := strconv.Atoi(args[4])
+			if err != nil {
+				return "-ERR value is not an integer\r\n"
+			}
+			unit := time.Second
+			if strings.EqualFold(args[3], "px") {
+				unit = time.Millisecond
+			}
+			s.expires[args[1]] = time.Now().Add(time.Duration(n) * unit)
+		}
+		return "+OK\r\n"
+	case "get":
+		value, ok := s.lookup(args[1])
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case "del":
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, ok := s.lookup(key); ok {
+				delete(s.values, key)
+				delete(s.expires, key)
+				deleted++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", deleted)
+	case "pttl":
+		if _, ok := s.lookup(args[1]); !ok {
+			return ":-2\r\n"
+		}
+		expires, ok := s.expires[args[1]]
+		if !ok {
+			return ":-1\r\n"
+		}
+		return fmt.Sprintf(":%d\r\n", time.Until(expires).Milliseconds())
+	default:
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+// lookup returns the value of key unless it is missing or expired. The caller holds s.mu.
+func (s *fakeRedis) lookup(key string) (string, bool) {
+	if expires, ok := s.expires[key]; ok && !time.Now().Before(expires) {
+		delete(s.values, key)
+		delete(s.expires, key)
+	}
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// readCommand reads one command sent as a RESP array of bulk strings
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	header, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(header, "*") {
+		return nil, errors.New("expected an array")
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(header[1:]))
+	if err != nil || count < 1 {
+		return nil, errors.New("invalid array length")
+	}
+
+	args := make([]string, count)
+	for i := range args {
+		length, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(length, "$")))
+		if err != nil {
+			return nil, errors.New("invalid bulk string length")
+		}
+		arg := make([]byte, n+2)
+		if _, err := io.ReadFull(reader, arg); err != nil {
+			return nil, err
+		}
+		args[i] = string(arg[:n])
+	}
+	return args, nil
+}
+
+func TestTenantKeysAreNamespaced(t *testing.T) {
+	tests := []struct {
+		name        string
+		multiTenant bool
+		wantKey     string
+	}{
+		{"multi-tenant", true, "tenant:acme:users:1"},
+		{"single tenant", false, "users:1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeRedis(t)
+			r := NewRedisCache(&config.RedisConfig{Addr: server.listener.Addr().String(), MultiTenant: tt.multiTenant})
+			t.Cleanup(func() { r.Close() })
+
+			ctx := ctxkeys.WithTenantID(context.Background(), "acme")
+			if err := r.Set(ctx, "users:1", "alice", 0); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+
+			if keys := server.keys(); len(keys) != 1 || keys[0] != tt.wantKey {
+				t.Errorf("stored keys = %q, want [%q]", keys, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestTenantCannotReadAnotherTenantsKeys(t *testing.T) {
+	server := newFakeRedis(t)
+	r := NewRedisCache(&config.RedisConfig{Addr: server.listener.Addr().String(), MultiTenant: true})
+	t.Cleanup(func() { r.Close() })
+
+	acme := ctxkeys.WithTenantID(context.Background(), "acme")
+	globex := ctxkeys.WithTenantID(context.Background(), "globex")
+	if err := r.Set(acme, "users:1", "alice", 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	var value string
+	if err := r.Get(globex, "users:1", &value); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("Get() under another tenant error = %v, value = %q, want %v", err, value, ErrCacheMiss)
+	}
+	if err := r.Get(context.Background(), "users:1", &value); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("Get() without a tenant error = %v, value = %q, want %v", err, value, ErrCacheMiss)
+	}
+	if err := r.Get(acme, "users:1", &value); err != nil || value != "alice" {
+		t.Errorf("Get() under the same tenant = %q, %v, want %q", value, err, "alice")
+	}
+}
@@ -14,7 +14,7 @@ type DsnProvider interface {
 
 // DatabaseConfig holds enhanced database configuration for production
 type DatabaseConfig struct {
-	Type     string `mapstructure:"type" validate:"required,oneof=postgresql postgres mongodb"` // postgresql, mongodb
+	Type     string `mapstructure:"type" validate:"required,oneof=postgresql postgres mongodb mysql"` // postgresql, mongodb, mysql
 	Host     string `mapstructure:"host" validate:"required"`
 	Port     int    `mapstructure:"port" validate:"required,min=1,max=65535"`
 	Database string `mapstructure:"database" validate:"required"`
@@ -40,9 +40,13 @@ type DatabaseConfig struct {
 	// Logging settings
 	LogLevel string `mapstructure:"log_level" validate:"omitempty,oneof=silent error warn info"`
 
+	// MultiTenant restricts repository reads and writes to the tenant in the request context
+	MultiTenant bool `mapstructure:"multi_tenant"`
+
 	// Database-specific configurations
 	PostgreSQL *PostgreSQLConfig `mapstructure:"postgresql,omitempty" validate:"omitempty"`
 	MongoDB    *MongoDBConfig    `mapstructure:"mongodb,omitempty" validate:"omitempty"`
+	MySQL      *MySQLConfig      `mapstructure:"mysql,omitempty" validate:"omitempty"`
 }
 
 // Dsn implements the DsnProvider interface
@@ -68,6 +72,16 @@ func (c *DatabaseConfig) Dsn() string {
 		mongoConfig.SetCredentials(c.Username, c.Password)
 		mongoConfig.AddHost(c.Host, fmt.Sprintf("%d", c.Port))
 		return mongoConfig.Dsn()
+	case "mysql":
+		if c.MySQL != nil {
+			return c.MySQL.Dsn()
+		}
+		// Fallback to basic config
+		mysqlConfig := NewMySQLConfig()
+		mysqlConfig.SetConnection(c.Host, c.Port, c.Database)
+		mysqlConfig.SetCredentials(c.Username, c.Password)
+		mysqlConfig.SetSSLMode(c.SSLMode)
+		return mysqlConfig.Dsn()
 	default:
 		return ""
 	}
@@ -25,6 +25,16 @@ func NewDSNProvider(dbType string, config *DatabaseConfig) DsnProvider {
 		mongoConfig.SetCredentials(config.Username, config.Password)
 		mongoConfig.AddHost(config.Host, fmt.Sprintf("%d", config.Port))
 		return mongoConfig
+	case "mysql":
+		if config.MySQL != nil {
+			return config.MySQL
+		}
+		// Fallback to basic config
+		mysqlConfig := NewMySQLConfig()
+		mysqlConfig.SetConnection(config.Host, config.Port, config.Database)
+		mysqlConfig.SetCredentials(config.Username, config.Password)
+		mysqlConfig.SetSSLMode(config.SSLMode)
+		return mysqlConfig
 	default:
 		return nil
 	}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// MySQLConfig holds MySQL-specific configuration
+type MySQLConfig struct {
+	Host     string            `mapstructure:"host"`
+	Port     int               `mapstructure:"port"`
+	Database string            `mapstructure:"database"`
+	Username string            `mapstructure:"username"`
+	Password string            `mapstructure:"password"`
+	Params   map[string]string `mapstructure:"params"`
+}
+
+// NewMySQLConfig creates a new MySQL configuration with defaults. Times are parsed into
+// time.Time in UTC, and text is exchanged as utf8mb4.
+func NewMySQLConfig() *MySQLConfig {
+	return &MySQLConfig{
+		Host:     "localhost",
+		Port:     3306,
+		Database: "microservices",
+		Username: "root",
+		Password: "",
+		Params: map[string]string{
+			"charset":   "utf8mb4",
+			"parseTime": "true",
+			"loc":       "UTC",
+		},
+	}
+}
+
+// Dsn returns the MySQL connection string in the go-sql-driver format. The driver formats it,
+// so credentials and parameter values are escaped the way it parses them back.
+func (m *MySQLConfig) Dsn() string {
+	cfg := m.driverConfig()
+	cfg.User = m.Username
+	cfg.Passwd = m.Password
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(m.Host, strconv.Itoa(m.Port))
+	cfg.DBName = m.Database
+	return cfg.FormatDSN()
+}
+
+// driverConfig returns a driver configuration holding Params. The driver parses them so
+// options such as loc and parseTime land in their typed fields. Params it rejects are kept
+// as they are, leaving the driver to report them when the connection is opened.
+func (m *MySQLConfig) driverConfig() *mysql.Config {
+	if len(m.Params) == 0 {
+		return mysql.NewConfig()
+	}
+
+	params := make(url.Values, len(m.Params))
+	for key, value := range m.Params {
+		params.Set(key, value)
+	}
+	cfg, err := mysql.ParseDSN("/?" + params.Encode())
+	if err != nil {
+		cfg = mysql.NewConfig()
+		cfg.Params = m.Params
+	}
+	return cfg
+}
+
+// AddParam adds a parameter to the DSN
+func (m *MySQLConfig) AddParam(key, value string) {
+	if m.Params == nil {
+		m.Params = make(map[string]string)
+	}
+	m.Params[key] = value
+}
+
+// SetSSLMode sets TLS from a PostgreSQL-style SSL mode: "disable" turns TLS off, "require"
+// encrypts without verifying the server certificate, and "verify-ca" and "verify-full"
+// verify it. An empty mode keeps the driver default.
+func (m *MySQLConfig) SetSSLMode(mode string) {
+	switch mode {
+	case "disable":
+		m.AddParam("tls", "false")
+	case "require":
+		m.AddParam("tls", "skip-verify")
+	case "verify-ca", "verify-full":
+		m.AddParam("tls", "true")
+	}
+}
+
+// SetCredentials sets username and password
+func (m *MySQLConfig) SetCredentials(username, password string) {
+	m.Username = username
+	m.Password = password
+}
+
+// SetConnection sets host, port, and database
+func (m *MySQLConfig) SetConnection(host string, port int, database string) {
+	m.Host = host
+	m.Port = port
+	m.Database = database
+}
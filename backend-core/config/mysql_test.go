@@ -0,0 +1,52 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestMySQLDsnDefaults(t *testing.T) {
+	want := "root@tcp(localhost:3306)/microservices?parseTime=true&charset=utf8mb4"
+	if got := NewMySQLConfig().Dsn(); got != want {
+		t.Errorf("Dsn() = %q, want %q", got, want)
+	}
+}
+
+func TestMySQLDsnRoundTripsSpecialCharacters(t *testing.T) {
+	m := NewMySQLConfig()
+	m.SetCredentials("app", "p@ss:w?rd&x=1")
+	m.SetConnection("db.internal", 3307, "orders")
+	m.AddParam("loc", "Asia/Ho_Chi_Minh")
+	m.AddParam("time_zone", "'+07:00'")
+
+	cfg, err := mysql.ParseDSN(m.Dsn())
+	if err != nil {
+		t.Fatalf("ParseDSN(%q) error = %v", m.Dsn(), err)
+	}
+
+	if cfg.User != "app" || cfg.Passwd != "p@ss:w?rd&x=1" {
+		t.Errorf("credentials = %q:%q, want %q:%q", cfg.User, cfg.Passwd, "app", "p@ss:w?rd&x=1")
+	}
+	if cfg.Addr != "db.internal:3307" || cfg.DBName != "orders" {
+		t.Errorf("address = %q/%q, want %q/%q", cfg.Addr, cfg.DBName, "db.internal:3307", "orders")
+	}
+	if cfg.Loc == nil || cfg.Loc.String() != "Asia/Ho_Chi_Minh" {
+		t.Errorf("loc = %v, want Asia/Ho_Chi_Minh", cfg.Loc)
+	}
+	if !cfg.ParseTime {
+		t.Error("parseTime was dropped")
+	}
+	if got := cfg.Params["time_zone"]; got != "'+07:00'" {
+		t.Errorf("time_zone = %q, want %q", got, "'+07:00'")
+	}
+}
+
+func TestMySQLDsnKeepsInvalidParamsForTheDriverToReject(t *testing.T) {
+	m := NewMySQLConfig()
+	m.AddParam("loc", "Nowhere/Invalid")
+
+	if _, err := mysql.ParseDSN(m.Dsn()); err == nil {
+		t.Errorf("ParseDSN(%q) accepted an unknown location", m.Dsn())
+	}
+}
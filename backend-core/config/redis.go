@@ -14,6 +14,15 @@ type RedisConfig struct {
 	Password string `mapstructure:"password" json:"password" yaml:"password"`
 	DB       int    `mapstructure:"db" json:"db" yaml:"db" validate:"min=0,max=15"`
 
+	// KeyPrefix namespaces every key as "<prefix>:<key>" so services can share an instance.
+	// Empty means keys are used as given.
+	KeyPrefix string `mapstructure:"key_prefix" json:"key_prefix" yaml:"key_prefix"`
+
+	// MultiTenant namespaces keys by the tenant ID in the request context (ctxkeys.TenantID)
+	// as "<prefix>:tenant:<tenant>:<key>", so one tenant can never read another's entries.
+	// Operations whose context has no tenant use the shared, untenanted namespace.
+	MultiTenant bool `mapstructure:"multi_tenant" json:"multi_tenant" yaml:"multi_tenant"`
+
 	// Enhanced connection pooling for production
 	PoolSize     int `mapstructure:"pool_size" validate:"required,min=1"`
 	MinIdleConns int `mapstructure:"min_idle_conns" validate:"min=0"`
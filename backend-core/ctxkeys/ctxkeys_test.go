@@ -0,0 +1,94 @@
+package ctxkeys
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestContextHelpers(t *testing.T) {
+	ctx := context.Background()
+	ctx = WithRequestID(ctx, "req-1")
+	ctx = WithCorrelationID(ctx, "corr-1")
+	ctx = WithUserID(ctx, "user-1")
+	ctx = WithTenantID(ctx, "tenant-1")
+	ctx = WithServiceName(ctx, "auth-service")
+
+	for name, got := range map[string]string{
+		"RequestIDFrom":     RequestIDFrom(ctx),
+		"CorrelationIDFrom": CorrelationIDFrom(ctx),
+		"UserIDFrom":        UserIDFrom(ctx),
+		"TenantIDFrom":      TenantIDFrom(ctx),
+		"ServiceNameFrom":   ServiceNameFrom(ctx),
+	} {
+		if got == "" {
+			t.Errorf("%s() = \"\", want the value set under the typed key", name)
+		}
+	}
+	if ctx.Value("user_id") != nil {
+		t.Error("typed user ID is visible under the bare string key")
+	}
+}
+
+func TestContextHelpersReadLegacyStringKeys(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "request_id", "legacy-req")
+	if got := RequestIDFrom(ctx); got != "legacy-req" {
+		t.Errorf("RequestIDFrom() = %q, want the legacy value", got)
+	}
+
+	ctx = context.WithValue(context.Background(), "tenant_id", "legacy-tenant")
+	if got := TenantIDFrom(ctx); got != "" {
+		t.Errorf("TenantIDFrom() = %q, want the bare string key ignored", got)
+	}
+
+	if got := UserIDFrom(nil); got != "" {
+		t.Errorf("UserIDFrom(nil) = %q, want \"\"", got)
+	}
+}
+
+func TestGinHelpers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+
+	SetUserID(c, "user-1")
+	SetTenantID(c, "tenant-1")
+	c.Set(Roles, []string{"admin"})
+	c.Set(Permissions, []interface{}{"users:read", 42, "users:write"})
+	c.Set(JWTClaims, map[string]interface{}{"sub": "user-1"})
+
+	if userID, ok := GetUserID(c); !ok || userID != "user-1" {
+		t.Errorf("GetUserID() = %q, %v, want user-1", userID, ok)
+	}
+	if got := GetTenantID(c); got != "tenant-1" {
+		t.Errorf("GetTenantID() = %q, want tenant-1", got)
+	}
+	if got := TenantIDFrom(c.Request.Context()); got != "tenant-1" {
+		t.Errorf("tenant in the request context = %q, want tenant-1", got)
+	}
+	if got := GetRoles(c); !reflect.DeepEqual(got, []string{"admin"}) {
+		t.Errorf("GetRoles() = %v, want [admin]", got)
+	}
+	if got := GetPermissions(c); !reflect.DeepEqual(got, []string{"users:read", "users:write"}) {
+		t.Errorf("GetPermissions() = %v, want the string permissions", got)
+	}
+	if claims, ok := GetJWTClaims(c); !ok || claims["sub"] != "user-1" {
+		t.Errorf("GetJWTClaims() = %v, %v, want the claims", claims, ok)
+	}
+	if _, exists := c.Get("user_id"); exists {
+		t.Error("user ID is set under the bare string key")
+	}
+}
+
+func TestGetUserIDRejectsNonString(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set(UserID, 42)
+
+	if _, ok := GetUserID(c); ok {
+		t.Error("GetUserID() accepted a non-string user ID")
+	}
+}
@@ -13,6 +13,7 @@ type DatabaseType string
 const (
 	PostgreSQL DatabaseType = "postgresql"
 	MongoDB    DatabaseType = "mongodb"
+	MySQL      DatabaseType = "mysql"
 )
 
 // Database defines the interface for database operations
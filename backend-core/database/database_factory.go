@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"backend-core/config"
+	"backend-core/database/mysql"
 	"backend-core/database/postgresql"
 	"backend-core/logging"
 	// "backend-core/database/mongodb" // Temporarily disabled due to dependency issues
@@ -17,16 +18,21 @@ func NewDatabaseFactory() *DatabaseFactory {
 	return &DatabaseFactory{}
 }
 
-// CreateDatabase creates a database instance based on the type
-func (f *DatabaseFactory) CreateDatabase(dbType DatabaseType, cfg *config.DatabaseConfig) (Database, error) {
-	switch dbType {
-	case PostgreSQL:
-		db := postgresql.NewPostgreSQLDatabase(cfg)
-		return &postgresql.DatabaseWrapper{PostgreSQLDatabase: db}, nil
+// CreateDatabase creates a database instance for the driver named by cfg.Type
+func (f *DatabaseFactory) CreateDatabase(cfg *config.DatabaseConfig) (Database, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("database config is required")
+	}
+
+	switch DatabaseType(cfg.Type) {
+	case PostgreSQL, "postgres":
+		return f.CreatePostgreSQLDatabase(cfg)
+	case MySQL:
+		return f.CreateMySQLDatabase(cfg)
 	case MongoDB:
-		return nil, fmt.Errorf("MongoDB support temporarily disabled due to dependency issues")
+		return f.CreateMongoDBDatabase(cfg)
 	default:
-		return nil, fmt.Errorf("unsupported database type: %s", dbType)
+		return nil, fmt.Errorf("unsupported database type: %s", cfg.Type)
 	}
 }
 
@@ -36,6 +42,12 @@ func (f *DatabaseFactory) CreatePostgreSQLDatabase(cfg *config.DatabaseConfig) (
 	return &postgresql.DatabaseWrapper{PostgreSQLDatabase: db}, nil
 }
 
+// CreateMySQLDatabase creates a MySQL database instance
+func (f *DatabaseFactory) CreateMySQLDatabase(cfg *config.DatabaseConfig) (Database, error) {
+	db := mysql.NewMySQLDatabase(cfg)
+	return &mysql.DatabaseWrapper{MySQLDatabase: db}, nil
+}
+
 // CreateMongoDBDatabase creates a MongoDB database instance
 func (f *DatabaseFactory) CreateMongoDBDatabase(cfg *config.DatabaseConfig) (Database, error) {
 	return nil, fmt.Errorf("MongoDB support temporarily disabled due to dependency issues")
@@ -52,6 +64,8 @@ func (f *DatabaseFactory) CreateRepository(db Database, collectionName string) i
 			Output: "stdout",
 		})
 		return postgresql.NewPostgreSQLRepository[interface{}](db.PostgreSQLDatabase, collectionName, logger)
+	case *mysql.DatabaseWrapper:
+		return db.MySQLDatabase.GetRepository(collectionName)
 	// case *mongodb.MongoDBDatabase:
 	//	return mongodb.NewMongoDBRepository[interface{}](db, collectionName)
 	default:
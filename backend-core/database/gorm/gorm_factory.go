@@ -58,6 +58,12 @@ func (f *GormDatabaseFactory) createPostgreSQLDatabase(config DatabaseConfig) (D
 		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
 	}
 
+	if config.MultiTenant {
+		if err := RegisterTenantScope(db); err != nil {
+			return nil, err
+		}
+	}
+
 	// Configure connection pool
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -0,0 +1,113 @@
+package gorm
+
+import (
+	"fmt"
+	"reflect"
+
+	"backend-core/database/tenancy"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// tenantScopeCallback is the name the tenant scope callbacks are registered under
+const tenantScopeCallback = "tenancy:scope"
+
+// RegisterTenantScope restricts every query, update and delete on models with a tenant_id
+// field to the tenant in the statement context, and stamps that tenant on created rows.
+// Operations without a tenant in the context fail with tenancy.ErrTenantRequired unless the
+// context comes from tenancy.WithoutTenantScope. Models without a tenant_id field and raw SQL
+// are not scoped.
+func RegisterTenantScope(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	if err := callbacks.Create().Before("gorm:create").Register(tenantScopeCallback, stampTenant); err != nil {
+		return fmt.Errorf("failed to register tenant scope for create: %w", err)
+	}
+	if err := callbacks.Query().Before("gorm:query").Register(tenantScopeCallback, scopeToTenant); err != nil {
+		return fmt.Errorf("failed to register tenant scope for query: %w", err)
+	}
+	if err := callbacks.Update().Before("gorm:update").Register(tenantScopeCallback, scopeToTenant); err != nil {
+		return fmt.Errorf("failed to register tenant scope for update: %w", err)
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register(tenantScopeCallback, scopeToTenant); err != nil {
+		return fmt.Errorf("failed to register tenant scope for delete: %w", err)
+	}
+	if err := callbacks.Row().Before("gorm:row").Register(tenantScopeCallback, scopeToTenant); err != nil {
+		return fmt.Errorf("failed to register tenant scope for row: %w", err)
+	}
+	return nil
+}
+
+// tenantField returns the tenant_id field of the statement's model, or nil when the
+// statement is not tenant-scoped
+func tenantField(db *gorm.DB) *schema.Field {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return nil
+	}
+	return db.Statement.Schema.LookUpField(tenancy.Column)
+}
+
+// scopeToTenant adds "tenant_id = ?" for the context tenant to the statement
+func scopeToTenant(db *gorm.DB) {
+	field := tenantField(db)
+	if field == nil {
+		return
+	}
+
+	tenantID, scoped, err := tenancy.Resolve(db.Statement.Context)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	if !scoped {
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenantID},
+	}})
+}
+
+// stampTenant sets the context tenant on rows being created, rejecting rows that already
+// belong to another tenant
+func stampTenant(db *gorm.DB) {
+	field := tenantField(db)
+	if field == nil {
+		return
+	}
+
+	tenantID, scoped, err := tenancy.Resolve(db.Statement.Context)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	if !scoped {
+		return
+	}
+
+	ctx := db.Statement.Context
+	stamp := func(row reflect.Value) {
+		value, zero := field.ValueOf(ctx, row)
+		if zero {
+			if err := field.Set(ctx, row, tenantID); err != nil {
+				db.AddError(fmt.Errorf("failed to set tenant: %w", err))
+			}
+			return
+		}
+		if fmt.Sprint(value) != tenantID {
+			db.AddError(tenancy.ErrTenantMismatch)
+		}
+	}
+
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len() && db.Error == nil; i++ {
+			stamp(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		stamp(rv)
+	}
+}
@@ -0,0 +1,150 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"backend-core/ctxkeys"
+	"backend-core/database/tenancy"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type tenantRow struct {
+	ID       uint
+	TenantID string
+	Name     string
+}
+
+type globalRow struct {
+	ID   uint
+	Name string
+}
+
+// newDryRunDB returns a tenant-scoped GORM DB that builds statements without a server
+func newDryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost user=test dbname=test sslmode=disable"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to open dry-run database: %v", err)
+	}
+	if err := RegisterTenantScope(db); err != nil {
+		t.Fatalf("RegisterTenantScope() error = %v", err)
+	}
+	return db
+}
+
+func TestTenantScopeRestrictsQueriesToContextTenant(t *testing.T) {
+	db := newDryRunDB(t)
+	ctx := ctxkeys.WithTenantID(context.Background(), "tenant-a")
+
+	var rows []tenantRow
+	stmt := db.WithContext(ctx).Where("name = ?", "x").Find(&rows).Statement
+
+	if stmt.Error != nil {
+		t.Fatalf("Find() error = %v", stmt.Error)
+	}
+	sql := stmt.SQL.String()
+	if !strings.Contains(sql, `"tenant_rows"."tenant_id" = `) {
+		t.Errorf("query %q is not scoped to the tenant", sql)
+	}
+	if !containsVar(stmt.Vars, "tenant-a") {
+		t.Errorf("query vars %v do not include the context tenant", stmt.Vars)
+	}
+}
+
+func TestTenantScopeRestrictsUpdatesAndDeletes(t *testing.T) {
+	db := newDryRunDB(t)
+	ctx := ctxkeys.WithTenantID(context.Background(), "tenant-a")
+
+	update := db.WithContext(ctx).Model(&tenantRow{}).Where("id = ?", 1).Update("name", "y").Statement
+	if update.Error != nil {
+		t.Fatalf("Update() error = %v", update.Error)
+	}
+	if !strings.Contains(update.SQL.String(), "tenant_id") || !containsVar(update.Vars, "tenant-a") {
+		t.Errorf("update %q with vars %v is not scoped to the tenant", update.SQL.String(), update.Vars)
+	}
+
+	del := db.WithContext(ctx).Where("id = ?", 1).Delete(&tenantRow{}).Statement
+	if del.Error != nil {
+		t.Fatalf("Delete() error = %v", del.Error)
+	}
+	if !strings.Contains(del.SQL.String(), "tenant_id") || !containsVar(del.Vars, "tenant-a") {
+		t.Errorf("delete %q with vars %v is not scoped to the tenant", del.SQL.String(), del.Vars)
+	}
+}
+
+func TestTenantScopeRequiresTenant(t *testing.T) {
+	db := newDryRunDB(t)
+
+	var rows []tenantRow
+	err := db.WithContext(context.Background()).Find(&rows).Error
+
+	if !errors.Is(err, tenancy.ErrTenantRequired) {
+		t.Errorf("Find() without a tenant error = %v, want %v", err, tenancy.ErrTenantRequired)
+	}
+}
+
+func TestTenantScopeEscapeHatch(t *testing.T) {
+	db := newDryRunDB(t)
+	ctx := tenancy.WithoutTenantScope(context.Background())
+
+	var rows []tenantRow
+	stmt := db.WithContext(ctx).Find(&rows).Statement
+
+	if stmt.Error != nil {
+		t.Fatalf("Find() with WithoutTenantScope error = %v", stmt.Error)
+	}
+	if sql := stmt.SQL.String(); strings.Contains(sql, "tenant_id") {
+		t.Errorf("unscoped query %q is restricted to a tenant", sql)
+	}
+}
+
+func TestTenantScopeIgnoresModelsWithoutTenant(t *testing.T) {
+	db := newDryRunDB(t)
+
+	var rows []globalRow
+	stmt := db.WithContext(context.Background()).Find(&rows).Statement
+
+	if stmt.Error != nil {
+		t.Fatalf("Find() on a model without tenant_id error = %v", stmt.Error)
+	}
+	if sql := stmt.SQL.String(); strings.Contains(sql, "tenant_id") {
+		t.Errorf("query %q on a model without tenant_id is scoped", sql)
+	}
+}
+
+func TestTenantScopeStampsCreatedRows(t *testing.T) {
+	db := newDryRunDB(t)
+	ctx := ctxkeys.WithTenantID(context.Background(), "tenant-a")
+
+	row := &tenantRow{Name: "x"}
+	if err := db.WithContext(ctx).Create(row).Error; err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if row.TenantID != "tenant-a" {
+		t.Errorf("created row tenant = %q, want %q", row.TenantID, "tenant-a")
+	}
+
+	other := &tenantRow{TenantID: "tenant-b", Name: "x"}
+	if err := db.WithContext(ctx).Create(other).Error; !errors.Is(err, tenancy.ErrTenantMismatch) {
+		t.Errorf("Create() of another tenant's row error = %v, want %v", err, tenancy.ErrTenantMismatch)
+	}
+}
+
+func containsVar(vars []interface{}, want string) bool {
+	for _, v := range vars {
+		if s, ok := v.(string); ok && s == want {
+			return true
+		}
+	}
+	return false
+}
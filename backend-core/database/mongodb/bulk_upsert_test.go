@@ -0,0 +1,72 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type syncedRecord struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	ExternalID string             `bson:"external_id"`
+	Name       string             `bson:"name"`
+}
+
+// hasField reports whether doc has a field named key
+func hasField(doc bson.D, key string) bool {
+	for _, element := range doc {
+		if element.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func TestUpsertReplacementDropsZeroID(t *testing.T) {
+	key, replacement, err := upsertReplacement(&syncedRecord{ExternalID: "ext-1", Name: "first"}, "external_id")
+	if err != nil {
+		t.Fatalf("upsertReplacement() error = %v", err)
+	}
+
+	if key.StringValue() != "ext-1" {
+		t.Errorf("key = %v, want ext-1", key)
+	}
+	if hasField(replacement, "_id") {
+		t.Errorf("replacement %v has the zero _id", replacement)
+	}
+	if !hasField(replacement, "external_id") || !hasField(replacement, "name") {
+		t.Errorf("replacement %v lost entity fields", replacement)
+	}
+}
+
+func TestUpsertReplacementKeepsSetID(t *testing.T) {
+	id := primitive.NewObjectID()
+	_, replacement, err := upsertReplacement(&syncedRecord{ID: id, ExternalID: "ext-1"}, "external_id")
+	if err != nil {
+		t.Fatalf("upsertReplacement() error = %v", err)
+	}
+
+	if len(replacement) == 0 || replacement[0].Key != "_id" {
+		t.Fatalf("replacement %v does not start with _id", replacement)
+	}
+	if got := replacement[0].Value.(bson.RawValue).ObjectID(); got != id {
+		t.Errorf("_id = %v, want %v", got, id)
+	}
+}
+
+func TestUpsertReplacementRequiresKeyField(t *testing.T) {
+	if _, _, err := upsertReplacement(&syncedRecord{ExternalID: "ext-1"}, "missing"); err == nil {
+		t.Error("upsertReplacement() with a missing key field succeeded")
+	}
+}
+
+func TestBulkUpsertWithoutEntitiesIsNoop(t *testing.T) {
+	repo := &MongoDBRepository[syncedRecord]{}
+
+	matched, upserted, err := repo.BulkUpsert(context.Background(), "external_id", nil)
+	if err != nil || matched != 0 || upserted != 0 {
+		t.Errorf("BulkUpsert(nil) = %d, %d, %v, want 0, 0, nil", matched, upserted, err)
+	}
+}
@@ -0,0 +1,81 @@
+package mongodb
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// pageCursor is the decoded form of an opaque pagination cursor token.
+// It records the sort field and direction the cursor was issued for, plus the
+// sort value and _id of the last item on the previous page.
+type pageCursor struct {
+	Field string        `bson:"f"`
+	Order int           `bson:"o"`
+	Value bson.RawValue `bson:"v"`
+	ID    bson.RawValue `bson:"id"`
+}
+
+// encodeCursor encodes a cursor into an opaque URL-safe base64 token
+func encodeCursor(c pageCursor) (string, error) {
+	data, err := bson.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor decodes an opaque cursor token
+func decodeCursor(token string) (pageCursor, error) {
+	var c pageCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, fmt.Errorf("invalid cursor: %w", err)
+	}
+	if err := bson.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("invalid cursor: %w", err)
+	}
+	if c.Field == "" || (c.Order != 1 && c.Order != -1) {
+		return c, fmt.Errorf("invalid cursor: missing sort field or direction")
+	}
+
+	return c, nil
+}
+
+// cursorFromDocument builds the cursor pointing after doc for the given sort
+func cursorFromDocument(doc interface{}, field string, order int) (pageCursor, error) {
+	id, err := lookupBSONValue(doc, "_id")
+	if err != nil {
+		return pageCursor{}, fmt.Errorf("cannot build cursor: %w", err)
+	}
+
+	value := id
+	if field != "_id" {
+		value, err = lookupBSONValue(doc, field)
+		if err != nil {
+			return pageCursor{}, fmt.Errorf("cannot build cursor: %w", err)
+		}
+	}
+
+	return pageCursor{Field: field, Order: order, Value: value, ID: id}, nil
+}
+
+// rangeFilter returns the filter selecting documents strictly after the cursor
+func (c pageCursor) rangeFilter() bson.M {
+	op := "$gt"
+	if c.Order < 0 {
+		op = "$lt"
+	}
+
+	if c.Field == "_id" {
+		return bson.M{"_id": bson.M{op: c.ID}}
+	}
+
+	// Tie-break on _id so items sharing a sort value are neither skipped nor repeated
+	return bson.M{"$or": bson.A{
+		bson.M{c.Field: bson.M{op: c.Value}},
+		bson.M{c.Field: c.Value, "_id": bson.M{op: c.ID}},
+	}}
+}
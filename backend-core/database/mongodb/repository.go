@@ -4,12 +4,16 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	"backend-core/database"
+	"backend-core/database/observability"
 	"backend-core/logging"
+	"backend-core/telemetry"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -18,9 +22,12 @@ import (
 
 // MongoDBRepository implements the Repository interface for MongoDB
 type MongoDBRepository[T any] struct {
+	*observability.BaseRepository
 	collection *mongo.Collection
 	logger     *logging.Logger
 	client     *mongo.Client
+	// tenantScoped restricts every operation to the tenant in the context
+	tenantScoped bool
 }
 
 // ValidateEntity validates an entity before operations
@@ -44,20 +51,6 @@ func (r *MongoDBRepository[T]) LogOperation(operation string, err error, fields
 	}
 }
 
-// LogQuery logs database queries
-func (r *MongoDBRepository[T]) LogQuery(query string, entity interface{}, duration time.Duration, err error) {
-	if err != nil {
-		r.logger.Error("Database query failed",
-			logging.String("query", query),
-			logging.Duration("duration", duration),
-			logging.Error(err))
-	} else {
-		r.logger.Info("Database query completed",
-			logging.String("query", query),
-			logging.Duration("duration", duration))
-	}
-}
-
 // ValidateID validates an ID before operations
 func (r *MongoDBRepository[T]) ValidateID(id interface{}) error {
 	// Basic validation - can be extended as needed
@@ -69,10 +62,17 @@ func (r *MongoDBRepository[T]) ValidateID(id interface{}) error {
 
 // NewMongoDBRepository creates a new MongoDB repository
 func NewMongoDBRepository[T any](db *MongoDBDatabase, collectionName string) *MongoDBRepository[T] {
+	return NewMongoDBRepositoryWithTelemetry[T](db, collectionName, nil)
+}
+
+// NewMongoDBRepositoryWithTelemetry creates a new MongoDB repository that traces and measures its queries
+func NewMongoDBRepositoryWithTelemetry[T any](db *MongoDBDatabase, collectionName string, telemetry *telemetry.Telemetry) *MongoDBRepository[T] {
 	return &MongoDBRepository[T]{
-		collection: db.database.Collection(collectionName),
-		client:     db.client,
-		logger:     db.logger,
+		BaseRepository: observability.NewBaseRepository(collectionName, db.logger, telemetry),
+		collection:     db.database.Collection(collectionName),
+		client:         db.client,
+		logger:         db.logger,
+		tenantScoped:   db.config != nil && db.config.MultiTenant,
 	}
 }
 
@@ -84,6 +84,9 @@ func (r *MongoDBRepository[T]) Create(ctx context.Context, entity *T) error {
 		r.LogOperation("create", err, zap.String("error", "validation_failed"))
 		return err
 	}
+	if err := r.stampTenant(ctx, entity); err != nil {
+		return err
+	}
 
 	_, err := r.collection.InsertOne(ctx, entity)
 	duration := time.Since(start)
@@ -105,6 +108,9 @@ func (r *MongoDBRepository[T]) CreateBatch(ctx context.Context, entities []*T) e
 	// Convert to []interface{} for MongoDB
 	docs := make([]interface{}, len(entities))
 	for i, entity := range entities {
+		if err := r.stampTenant(ctx, entity); err != nil {
+			return fmt.Errorf("invalid entity at index %d: %w", i, err)
+		}
 		docs[i] = entity
 	}
 
@@ -135,8 +141,13 @@ func (r *MongoDBRepository[T]) GetByID(ctx context.Context, id interface{}) (*T,
 		return nil, fmt.Errorf("invalid ID format: %w", err)
 	}
 
+	filter, err := r.scopeFilter(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return nil, err
+	}
+
 	var entity T
-	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&entity)
+	err = r.collection.FindOne(ctx, filter).Decode(&entity)
 	duration := time.Since(start)
 
 	r.LogQuery("FIND_ONE", map[string]interface{}{"id": id}, duration, err)
@@ -159,8 +170,13 @@ func (r *MongoDBRepository[T]) GetByID(ctx context.Context, id interface{}) (*T,
 func (r *MongoDBRepository[T]) GetByField(ctx context.Context, field string, value interface{}) (*T, error) {
 	start := time.Now()
 
+	filter, err := r.scopeFilter(ctx, bson.M{field: value})
+	if err != nil {
+		return nil, err
+	}
+
 	var entity T
-	err := r.collection.FindOne(ctx, bson.M{field: value}).Decode(&entity)
+	err = r.collection.FindOne(ctx, filter).Decode(&entity)
 	duration := time.Since(start)
 
 	r.LogQuery("FIND_ONE", map[string]interface{}{"field": field, "value": value}, duration, err)
@@ -185,7 +201,10 @@ func (r *MongoDBRepository[T]) GetAll(ctx context.Context, filter database.Filte
 	start := time.Now()
 
 	// Convert filter to MongoDB filter
-	mongoFilter := r.convertFilter(filter)
+	mongoFilter, err := r.scopeFilter(ctx, r.convertFilter(filter))
+	if err != nil {
+		return nil, err
+	}
 
 	// Build options
 	opts := options.Find()
@@ -240,7 +259,15 @@ func (r *MongoDBRepository[T]) Update(ctx context.Context, entity *T) error {
 		return fmt.Errorf("invalid ID format: %w", err)
 	}
 
-	_, err = r.collection.ReplaceOne(ctx, bson.M{"_id": objectID}, entity)
+	if err := r.stampTenant(ctx, entity); err != nil {
+		return err
+	}
+	filter, err := r.scopeFilter(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.collection.ReplaceOne(ctx, filter, entity)
 	duration := time.Since(start)
 
 	r.LogQuery("REPLACE_ONE", entity, duration, err)
@@ -263,8 +290,13 @@ func (r *MongoDBRepository[T]) UpdateField(ctx context.Context, id interface{},
 		return fmt.Errorf("invalid ID format: %w", err)
 	}
 
+	filter, err := r.scopeFilter(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+
 	_, err = r.collection.UpdateOne(ctx,
-		bson.M{"_id": objectID},
+		filter,
 		bson.M{"$set": bson.M{field: value}},
 	)
 	duration := time.Since(start)
@@ -289,10 +321,16 @@ func (r *MongoDBRepository[T]) Upsert(ctx context.Context, filter database.Filte
 		return err
 	}
 
-	mongoFilter := r.convertFilter(filter)
+	if err := r.stampTenant(ctx, entity); err != nil {
+		return err
+	}
+	mongoFilter, err := r.scopeFilter(ctx, r.convertFilter(filter))
+	if err != nil {
+		return err
+	}
 	opts := options.Replace().SetUpsert(true)
 
-	_, err := r.collection.ReplaceOne(ctx, mongoFilter, entity, opts)
+	_, err = r.collection.ReplaceOne(ctx, mongoFilter, entity, opts)
 	duration := time.Since(start)
 
 	r.LogQuery("REPLACE_ONE_UPSERT", map[string]interface{}{"filter": filter, "entity": entity}, duration, err)
@@ -304,6 +342,71 @@ func (r *MongoDBRepository[T]) Upsert(ctx context.Context, filter database.Filte
 	return err
 }
 
+// BulkUpsert creates or replaces many entities in a single unordered bulk write.
+// Each entity is matched on keyField using the value of the field mapped to it,
+// so one failing entity does not stop the rest from being written. A zero _id is left
+// out of the replacement, so new entities get an _id generated and existing documents
+// keep theirs. It returns the
+// number of matched (replaced) and upserted (inserted) documents. An empty
+// entities slice is a no-op and returns no error.
+func (r *MongoDBRepository[T]) BulkUpsert(ctx context.Context, keyField string, entities []*T) (matched, upserted int64, err error) {
+	start := time.Now()
+
+	if len(entities) == 0 {
+		return 0, 0, nil
+	}
+	if keyField == "" {
+		return 0, 0, fmt.Errorf("key field is required for bulk upsert")
+	}
+
+	models := make([]mongo.WriteModel, 0, len(entities))
+	for i, entity := range entities {
+		if err := r.ValidateEntity(entity); err != nil {
+			return 0, 0, fmt.Errorf("invalid entity at index %d: %w", i, err)
+		}
+
+		if err := r.stampTenant(ctx, entity); err != nil {
+			return 0, 0, fmt.Errorf("invalid entity at index %d: %w", i, err)
+		}
+
+		key, replacement, err := upsertReplacement(entity, keyField)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid entity at index %d: %w", i, err)
+		}
+
+		filter, err := r.scopeFilter(ctx, bson.M{keyField: key})
+		if err != nil {
+			return 0, 0, err
+		}
+
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(filter).
+			SetReplacement(replacement).
+			SetUpsert(true))
+	}
+
+	opts := options.BulkWrite().SetOrdered(false)
+
+	result, err := r.collection.BulkWrite(ctx, models, opts)
+	duration := time.Since(start)
+
+	if result != nil {
+		matched = result.MatchedCount
+		upserted = result.UpsertedCount
+	}
+
+	r.LogQuery("BULK_WRITE_UPSERT", map[string]interface{}{"key_field": keyField, "count": len(entities)}, duration, err)
+	r.LogOperation("bulk_upsert", err,
+		zap.String("key_field", keyField),
+		zap.Int("count", len(entities)),
+		zap.Int64("matched", matched),
+		zap.Int64("upserted", upserted),
+		zap.Duration("duration", duration),
+	)
+
+	return matched, upserted, err
+}
+
 // Delete deletes an entity by ID
 func (r *MongoDBRepository[T]) Delete(ctx context.Context, id interface{}) error {
 	start := time.Now()
@@ -318,7 +421,12 @@ func (r *MongoDBRepository[T]) Delete(ctx context.Context, id interface{}) error
 		return fmt.Errorf("invalid ID format: %w", err)
 	}
 
-	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	filter, err := r.scopeFilter(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.collection.DeleteOne(ctx, filter)
 	duration := time.Since(start)
 
 	r.LogQuery("DELETE_ONE", map[string]interface{}{"id": id}, duration, err)
@@ -348,7 +456,12 @@ func (r *MongoDBRepository[T]) DeleteBatch(ctx context.Context, ids []interface{
 		objectIDs[i] = objectID
 	}
 
-	_, err := r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": objectIDs}})
+	filter, err := r.scopeFilter(ctx, bson.M{"_id": bson.M{"$in": objectIDs}})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.collection.DeleteMany(ctx, filter)
 	duration := time.Since(start)
 
 	r.LogQuery("DELETE_MANY", map[string]interface{}{"ids": ids}, duration, err)
@@ -364,7 +477,10 @@ func (r *MongoDBRepository[T]) DeleteBatch(ctx context.Context, ids []interface{
 func (r *MongoDBRepository[T]) Count(ctx context.Context, filter database.Filter) (int64, error) {
 	start := time.Now()
 
-	mongoFilter := r.convertFilter(filter)
+	mongoFilter, err := r.scopeFilter(ctx, r.convertFilter(filter))
+	if err != nil {
+		return 0, err
+	}
 
 	count, err := r.collection.CountDocuments(ctx, mongoFilter)
 	duration := time.Since(start)
@@ -392,7 +508,10 @@ func (r *MongoDBRepository[T]) Exists(ctx context.Context, filter database.Filte
 func (r *MongoDBRepository[T]) Find(ctx context.Context, query database.Query) ([]*T, error) {
 	start := time.Now()
 
-	mongoFilter := r.convertFilter(query.Filter)
+	mongoFilter, err := r.scopeFilter(ctx, r.convertFilter(query.Filter))
+	if err != nil {
+		return nil, err
+	}
 
 	// Build options
 	opts := options.Find()
@@ -435,6 +554,97 @@ func (r *MongoDBRepository[T]) Find(ctx context.Context, query database.Query) (
 	return entities, err
 }
 
+// FindCursor executes a query using keyset pagination instead of skip/limit.
+// The page is sorted by query.OrderBy (defaulting to _id) in query.Order with _id
+// as a tie-breaker, and its size is query.Pagination.PageSize; Pagination.Page is
+// ignored. An empty cursor starts from the beginning. The returned nextCursor is
+// an opaque token for the following page, or empty when there are no more items.
+func (r *MongoDBRepository[T]) FindCursor(ctx context.Context, query database.Query, cursor string) ([]*T, string, error) {
+	start := time.Now()
+
+	field := query.OrderBy
+	if field == "" {
+		field = "_id"
+	}
+	order := 1
+	if query.Order == "desc" {
+		order = -1
+	}
+
+	mongoFilter, err := r.scopeFilter(ctx, r.convertFilter(query.Filter))
+	if err != nil {
+		return nil, "", err
+	}
+
+	if cursor != "" {
+		pc, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		if pc.Field != field || pc.Order != order {
+			return nil, "", fmt.Errorf("invalid cursor: issued for a different sort order")
+		}
+		if len(mongoFilter) > 0 {
+			mongoFilter = bson.M{"$and": bson.A{mongoFilter, pc.rangeFilter()}}
+		} else {
+			mongoFilter = pc.rangeFilter()
+		}
+	}
+
+	// Build options
+	sort := bson.D{{Key: field, Value: order}}
+	if field != "_id" {
+		sort = append(sort, bson.E{Key: "_id", Value: order})
+	}
+	opts := options.Find().SetSort(sort)
+
+	// Fetch one extra item to know whether another page exists
+	pageSize := query.Pagination.PageSize
+	if pageSize > 0 {
+		opts.SetLimit(int64(pageSize) + 1)
+	}
+
+	mongoCursor, err := r.collection.Find(ctx, mongoFilter, opts)
+	if err != nil {
+		duration := time.Since(start)
+		r.LogQuery("FIND_CURSOR", query, duration, err)
+		return nil, "", err
+	}
+	defer mongoCursor.Close(ctx)
+
+	var entities []*T
+	err = mongoCursor.All(ctx, &entities)
+	duration := time.Since(start)
+
+	r.LogQuery("FIND_CURSOR", query, duration, err)
+	r.LogOperation("find_cursor", err,
+		zap.Any("query", query),
+		zap.Int("count", len(entities)),
+		zap.Duration("duration", duration),
+	)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if pageSize <= 0 || len(entities) <= pageSize {
+		return entities, "", nil
+	}
+
+	entities = entities[:pageSize]
+
+	pc, err := cursorFromDocument(entities[pageSize-1], field, order)
+	if err != nil {
+		return nil, "", err
+	}
+	nextCursor, err := encodeCursor(pc)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return entities, nextCursor, nil
+}
+
 // WithTransaction executes a function within a transaction
 func (r *MongoDBRepository[T]) WithTransaction(ctx context.Context, fn func(database.Repository[T]) error) error {
 	session, err := r.client.StartSession()
@@ -446,9 +656,10 @@ func (r *MongoDBRepository[T]) WithTransaction(ctx context.Context, fn func(data
 	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
 		// Create a new repository with the session context
 		txRepo := &MongoDBRepository[T]{
-			collection: r.collection,
-			client:     r.client,
-			logger:     r.logger,
+			collection:   r.collection,
+			client:       r.client,
+			logger:       r.logger,
+			tenantScoped: r.tenantScoped,
 		}
 		return nil, fn(txRepo)
 	})
@@ -488,12 +699,20 @@ func (r *MongoDBRepository[T]) convertToObjectID(id interface{}) (primitive.Obje
 }
 
 func (r *MongoDBRepository[T]) extractID(entity *T) (interface{}, error) {
-	// This is a simplified implementation
-	// In a real implementation, you would use reflection to find the ID field
-	// or use struct tags to identify the ID field
 	val := reflect.ValueOf(entity).Elem()
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("no ID field found in entity")
+	}
+
+	// Prefer the field mapped to _id through its bson tag
+	if field, ok := findBSONIDField(val); ok {
+		if field.IsZero() {
+			return nil, fmt.Errorf("ID field is empty")
+		}
+		return field.Interface(), nil
+	}
 
-	// Look for common ID field names
+	// Fall back to common ID field names
 	idFields := []string{"ID", "Id", "id", "_id"}
 	for _, fieldName := range idFields {
 		field := val.FieldByName(fieldName)
@@ -505,6 +724,101 @@ func (r *MongoDBRepository[T]) extractID(entity *T) (interface{}, error) {
 	return nil, fmt.Errorf("no ID field found in entity")
 }
 
+// findBSONIDField finds the struct field tagged as bson "_id"
+func findBSONIDField(val reflect.Value) (reflect.Value, bool) {
+	return findBSONField(val, "_id")
+}
+
+// findBSONField finds the struct field tagged with the bson name, descending into
+// untagged embedded structs and structs tagged inline
+func findBSONField(val reflect.Value, name string) (reflect.Value, bool) {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		structField := typ.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		tag := structField.Tag.Get("bson")
+		tagName, opts, _ := strings.Cut(tag, ",")
+		if tagName == name {
+			return val.Field(i), true
+		}
+
+		field := val.Field(i)
+		inline := (structField.Anonymous && tag == "") || strings.Contains(","+opts+",", ",inline,")
+		if inline && field.Kind() == reflect.Struct {
+			if nested, ok := findBSONField(field, name); ok {
+				return nested, true
+			}
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+// lookupBSONValue returns the value of a (dotted) field as the entity is stored in MongoDB
+func lookupBSONValue(entity interface{}, field string) (bson.RawValue, error) {
+	raw, err := bson.Marshal(entity)
+	if err != nil {
+		return bson.RawValue{}, fmt.Errorf("failed to marshal entity: %w", err)
+	}
+
+	value, err := bson.Raw(raw).LookupErr(strings.Split(field, ".")...)
+	if err != nil {
+		return bson.RawValue{}, fmt.Errorf("entity has no %s field", field)
+	}
+
+	return value, nil
+}
+
+// upsertReplacement marshals entity and returns the value of its keyField together with
+// the document to replace the match with. A zero _id is dropped from the document: it
+// would give every inserted document the same _id, and fail to replace a document that
+// already has another one.
+func upsertReplacement(entity interface{}, keyField string) (bson.RawValue, bson.D, error) {
+	raw, err := bson.Marshal(entity)
+	if err != nil {
+		return bson.RawValue{}, nil, fmt.Errorf("failed to marshal entity: %w", err)
+	}
+
+	key, err := bson.Raw(raw).LookupErr(strings.Split(keyField, ".")...)
+	if err != nil {
+		return bson.RawValue{}, nil, fmt.Errorf("entity has no %s field", keyField)
+	}
+
+	elements, err := bson.Raw(raw).Elements()
+	if err != nil {
+		return bson.RawValue{}, nil, fmt.Errorf("failed to read entity: %w", err)
+	}
+	replacement := make(bson.D, 0, len(elements))
+	for _, element := range elements {
+		if element.Key() == "_id" && isZeroID(element.Value()) {
+			continue
+		}
+		replacement = append(replacement, bson.E{Key: element.Key(), Value: element.Value()})
+	}
+
+	return key, replacement, nil
+}
+
+// isZeroID reports whether value is an unset _id: null, or the zero value of its type
+func isZeroID(value bson.RawValue) bool {
+	switch value.Type {
+	case bsontype.Null, bsontype.Undefined:
+		return true
+	case bsontype.ObjectID:
+		return value.ObjectID().IsZero()
+	case bsontype.String:
+		return value.StringValue() == ""
+	case bsontype.Int32:
+		return value.Int32() == 0
+	case bsontype.Int64:
+		return value.Int64() == 0
+	}
+	return false
+}
+
 func getCollectionNameFromType[T any]() string {
 	var t T
 	rt := reflect.TypeOf(t)
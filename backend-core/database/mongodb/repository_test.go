@@ -0,0 +1,53 @@
+package mongodb
+
+import (
+	"testing"
+)
+
+type taggedUser struct {
+	UserID string `bson:"_id,omitempty"`
+	Name   string `bson:"name"`
+}
+
+type untaggedUser struct {
+	ID   string
+	Name string
+}
+
+type AuditFields struct {
+	DocumentID string `bson:"_id"`
+}
+
+type embeddedUser struct {
+	AuditFields `bson:",inline"`
+	Name        string `bson:"name"`
+}
+
+func TestExtractID(t *testing.T) {
+	t.Run("bson tag", func(t *testing.T) {
+		id, err := (&MongoDBRepository[taggedUser]{}).extractID(&taggedUser{UserID: "u-1", Name: "ann"})
+		if err != nil || id != "u-1" {
+			t.Errorf("extractID() = %v, %v, want u-1", id, err)
+		}
+	})
+
+	t.Run("field name", func(t *testing.T) {
+		id, err := (&MongoDBRepository[untaggedUser]{}).extractID(&untaggedUser{ID: "u-2"})
+		if err != nil || id != "u-2" {
+			t.Errorf("extractID() = %v, %v, want u-2", id, err)
+		}
+	})
+
+	t.Run("inline struct", func(t *testing.T) {
+		id, err := (&MongoDBRepository[embeddedUser]{}).extractID(&embeddedUser{AuditFields: AuditFields{DocumentID: "u-3"}})
+		if err != nil || id != "u-3" {
+			t.Errorf("extractID() = %v, %v, want u-3", id, err)
+		}
+	})
+
+	t.Run("empty tagged ID", func(t *testing.T) {
+		if _, err := (&MongoDBRepository[taggedUser]{}).extractID(&taggedUser{Name: "ann"}); err == nil {
+			t.Error("extractID() of an entity without an ID succeeded")
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"backend-core/database/tenancy"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// scopeFilter restricts filter to the context tenant when the repository is tenant-scoped.
+// A filter that already names tenant_id is combined with $and, so it can only narrow the
+// result, never reach another tenant.
+func (r *MongoDBRepository[T]) scopeFilter(ctx context.Context, filter bson.M) (bson.M, error) {
+	if !r.tenantScoped {
+		return filter, nil
+	}
+
+	tenantID, scoped, err := tenancy.Resolve(ctx)
+	if err != nil || !scoped {
+		return filter, err
+	}
+
+	if _, exists := filter[tenancy.Column]; exists {
+		return bson.M{"$and": bson.A{filter, bson.M{tenancy.Column: tenantID}}}, nil
+	}
+	scopedFilter := make(bson.M, len(filter)+1)
+	for field, value := range filter {
+		scopedFilter[field] = value
+	}
+	scopedFilter[tenancy.Column] = tenantID
+	return scopedFilter, nil
+}
+
+// stampTenant sets the context tenant on an entity being written when its tenant_id field is
+// empty, and rejects entities that belong to another tenant
+func (r *MongoDBRepository[T]) stampTenant(ctx context.Context, entity *T) error {
+	if !r.tenantScoped {
+		return nil
+	}
+
+	tenantID, scoped, err := tenancy.Resolve(ctx)
+	if err != nil || !scoped {
+		return err
+	}
+
+	val := reflect.ValueOf(entity).Elem()
+	if val.Kind() != reflect.Struct {
+		return fmt.Errorf("entity has no %s field", tenancy.Column)
+	}
+	field, ok := findBSONField(val, tenancy.Column)
+	if !ok {
+		return fmt.Errorf("entity has no %s field", tenancy.Column)
+	}
+
+	if field.IsZero() {
+		if field.Kind() != reflect.String || !field.CanSet() {
+			return fmt.Errorf("%s field must be a string to be set", tenancy.Column)
+		}
+		field.SetString(tenantID)
+		return nil
+	}
+	if fmt.Sprint(field.Interface()) != tenantID {
+		return tenancy.ErrTenantMismatch
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"backend-core/ctxkeys"
+	"backend-core/database/tenancy"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type tenantDoc struct {
+	ID       string `bson:"_id,omitempty"`
+	TenantID string `bson:"tenant_id"`
+	Name     string `bson:"name"`
+}
+
+func TestScopeFilterAddsContextTenant(t *testing.T) {
+	repo := &MongoDBRepository[tenantDoc]{tenantScoped: true}
+	ctx := ctxkeys.WithTenantID(context.Background(), "tenant-a")
+
+	filter, err := repo.scopeFilter(ctx, bson.M{"name": "x"})
+
+	if err != nil {
+		t.Fatalf("scopeFilter() error = %v", err)
+	}
+	want := bson.M{"name": "x", "tenant_id": "tenant-a"}
+	if !reflect.DeepEqual(filter, want) {
+		t.Errorf("scopeFilter() = %v, want %v", filter, want)
+	}
+}
+
+func TestScopeFilterCannotReachAnotherTenant(t *testing.T) {
+	repo := &MongoDBRepository[tenantDoc]{tenantScoped: true}
+	ctx := ctxkeys.WithTenantID(context.Background(), "tenant-a")
+
+	filter, err := repo.scopeFilter(ctx, bson.M{"tenant_id": "tenant-b"})
+
+	if err != nil {
+		t.Fatalf("scopeFilter() error = %v", err)
+	}
+	want := bson.M{"$and": bson.A{bson.M{"tenant_id": "tenant-b"}, bson.M{"tenant_id": "tenant-a"}}}
+	if !reflect.DeepEqual(filter, want) {
+		t.Errorf("scopeFilter() = %v, want %v", filter, want)
+	}
+}
+
+func TestScopeFilterRequiresTenant(t *testing.T) {
+	repo := &MongoDBRepository[tenantDoc]{tenantScoped: true}
+
+	if _, err := repo.scopeFilter(context.Background(), bson.M{}); !errors.Is(err, tenancy.ErrTenantRequired) {
+		t.Errorf("scopeFilter() without a tenant error = %v, want %v", err, tenancy.ErrTenantRequired)
+	}
+}
+
+func TestScopeFilterEscapeHatch(t *testing.T) {
+	repo := &MongoDBRepository[tenantDoc]{tenantScoped: true}
+	ctx := tenancy.WithoutTenantScope(context.Background())
+
+	filter, err := repo.scopeFilter(ctx, bson.M{"name": "x"})
+
+	if err != nil {
+		t.Fatalf("scopeFilter() error = %v", err)
+	}
+	if _, scoped := filter["tenant_id"]; scoped {
+		t.Errorf("scopeFilter() with WithoutTenantScope = %v, want no tenant", filter)
+	}
+}
+
+func TestStampTenant(t *testing.T) {
+	repo := &MongoDBRepository[tenantDoc]{tenantScoped: true}
+	ctx := ctxkeys.WithTenantID(context.Background(), "tenant-a")
+
+	doc := &tenantDoc{Name: "x"}
+	if err := repo.stampTenant(ctx, doc); err != nil {
+		t.Fatalf("stampTenant() error = %v", err)
+	}
+	if doc.TenantID != "tenant-a" {
+		t.Errorf("stamped tenant = %q, want %q", doc.TenantID, "tenant-a")
+	}
+
+	other := &tenantDoc{TenantID: "tenant-b"}
+	if err := repo.stampTenant(ctx, other); !errors.Is(err, tenancy.ErrTenantMismatch) {
+		t.Errorf("stampTenant() of another tenant's document error = %v, want %v", err, tenancy.ErrTenantMismatch)
+	}
+}
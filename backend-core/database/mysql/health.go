@@ -0,0 +1,340 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"backend-core/config"
+	"backend-core/database/health"
+)
+
+// MySQLHealthChecker implements health checking for MySQL
+type MySQLHealthChecker struct {
+	db            *sql.DB
+	config        *config.DatabaseConfig
+	status        health.HealthStatus
+	lastCheck     time.Time
+	healthHistory []health.HealthCheck
+	mu            sync.RWMutex
+}
+
+// NewMySQLHealthChecker creates a new MySQL health checker
+func NewMySQLHealthChecker(db *sql.DB, config *config.DatabaseConfig) *MySQLHealthChecker {
+	return &MySQLHealthChecker{
+		db:            db,
+		config:        config,
+		status:        health.HealthStatusUnknown,
+		healthHistory: make([]health.HealthCheck, 0),
+	}
+}
+
+// CheckHealth performs a comprehensive health check
+func (h *MySQLHealthChecker) CheckHealth(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	start := time.Now()
+	details := make(map[string]interface{})
+
+	var check *health.HealthCheck
+
+	// Check connection
+	if err := h.CheckConnection(ctx); err != nil {
+		check = health.NewHealthCheck(health.HealthStatusUnhealthy, time.Since(start), err, details)
+		h.status = health.HealthStatusUnhealthy
+	} else {
+		// Check performance
+		metrics, err := h.CheckPerformance(ctx)
+		if err != nil {
+			check = health.NewHealthCheck(health.HealthStatusDegraded, time.Since(start), err, details)
+			h.status = health.HealthStatusDegraded
+		} else {
+			details["metrics"] = metrics
+			check = health.NewHealthCheck(health.HealthStatusHealthy, time.Since(start), nil, details)
+			h.status = health.HealthStatusHealthy
+		}
+	}
+
+	h.lastCheck = start
+	h.healthHistory = append(h.healthHistory, *check)
+
+	// Keep only last 100 health checks
+	if len(h.healthHistory) > 100 {
+		h.healthHistory = h.healthHistory[len(h.healthHistory)-100:]
+	}
+
+	return check.GetError()
+}
+
+// CheckConnection checks the database connection
+func (h *MySQLHealthChecker) CheckConnection(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := h.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("database connection failed: %w", err)
+	}
+
+	return nil
+}
+
+// CheckPerformance checks database performance metrics
+func (h *MySQLHealthChecker) CheckPerformance(ctx context.Context) (*health.PerformanceMetrics, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// Get connection stats
+	stats := h.db.Stats()
+
+	// Test query performance
+	start := time.Now()
+	var result int
+	err := h.db.QueryRowContext(ctx, "SELECT 1").Scan(&result)
+	responseTime := time.Since(start)
+	lastQueryTime := time.Now()
+
+	if err != nil {
+		return nil, fmt.Errorf("performance check query failed: %w", err)
+	}
+
+	// The server counters are informational, so failing to read them is not unhealthy
+	queryCount, errorCount, err := h.getMySQLMetrics(ctx)
+	if err != nil {
+		fmt.Printf("Warning: failed to get MySQL metrics: %v\n", err)
+	}
+
+	return health.NewPerformanceMetrics(
+		stats.InUse,
+		stats.Idle,
+		stats.MaxOpenConnections,
+		responseTime,
+		lastQueryTime,
+		queryCount,
+		errorCount,
+	), nil
+}
+
+// GetStatus returns the current health status
+func (h *MySQLHealthChecker) GetStatus() health.HealthStatus {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.status
+}
+
+// GetLastHealthCheck returns the timestamp of the last health check
+func (h *MySQLHealthChecker) GetLastHealthCheck() time.Time {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.lastCheck
+}
+
+// GetHealthHistory returns the health check history
+func (h *MySQLHealthChecker) GetHealthHistory() []health.HealthCheck {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.healthHistory
+}
+
+// getMySQLMetrics reads the statements the server has run and the connections it aborted
+// from its global status counters
+func (h *MySQLHealthChecker) getMySQLMetrics(ctx context.Context) (queryCount, errorCount int64, err error) {
+	rows, err := h.db.QueryContext(ctx,
+		"SHOW GLOBAL STATUS WHERE Variable_name IN ('Questions', 'Aborted_connects')")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var value int64
+		if err := rows.Scan(&name, &value); err != nil {
+			return 0, 0, err
+		}
+		switch name {
+		case "Questions":
+			queryCount = value
+		case "Aborted_connects":
+			errorCount = value
+		}
+	}
+	return queryCount, errorCount, rows.Err()
+}
+
+// MySQLMonitor implements monitoring for MySQL
+type MySQLMonitor struct {
+	monitoring   bool
+	startTime    time.Time
+	lastActivity time.Time
+
+	queries           int64
+	failedQueries     int64
+	queryDuration     time.Duration
+	slowestQuery      time.Duration
+	fastestQuery      time.Duration
+	connections       int64
+	failedConnections int64
+	transactions      int64
+	failedTrans       int64
+	rolledBackTrans   int64
+	transDuration     time.Duration
+
+	mu sync.RWMutex
+}
+
+// NewMySQLMonitor creates a new MySQL monitor
+func NewMySQLMonitor() *MySQLMonitor {
+	return &MySQLMonitor{}
+}
+
+// StartMonitoring starts monitoring database operations
+func (m *MySQLMonitor) StartMonitoring(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.monitoring {
+		return fmt.Errorf("monitoring is already started")
+	}
+
+	m.monitoring = true
+	m.startTime = time.Now()
+	m.lastActivity = time.Now()
+
+	return nil
+}
+
+// StopMonitoring stops monitoring database operations
+func (m *MySQLMonitor) StopMonitoring(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.monitoring {
+		return fmt.Errorf("monitoring is not started")
+	}
+
+	m.monitoring = false
+	return nil
+}
+
+// IsMonitoring returns whether monitoring is active
+func (m *MySQLMonitor) IsMonitoring() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.monitoring
+}
+
+// RecordQuery records a query execution
+func (m *MySQLMonitor) RecordQuery(query string, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.queries++
+	if err != nil {
+		m.failedQueries++
+	}
+	m.queryDuration += duration
+	if duration > m.slowestQuery {
+		m.slowestQuery = duration
+	}
+	if m.fastestQuery == 0 || duration < m.fastestQuery {
+		m.fastestQuery = duration
+	}
+
+	m.lastActivity = time.Now()
+}
+
+// RecordConnection records a connection event
+func (m *MySQLMonitor) RecordConnection(connected bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.connections++
+	if err != nil {
+		m.failedConnections++
+	}
+
+	m.lastActivity = time.Now()
+}
+
+// RecordTransaction records a transaction event
+func (m *MySQLMonitor) RecordTransaction(operation string, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.transactions++
+	if err != nil {
+		m.failedTrans++
+	}
+	if operation == "rollback" {
+		m.rolledBackTrans++
+	}
+	m.transDuration += duration
+
+	m.lastActivity = time.Now()
+}
+
+// GetQueryStats returns query statistics
+func (m *MySQLMonitor) GetQueryStats() health.QueryStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.queryStats()
+}
+
+// GetConnectionStats returns connection statistics
+func (m *MySQLMonitor) GetConnectionStats() health.ConnectionStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return health.NewConnectionStats(m.connections, m.failedConnections)
+}
+
+// GetTransactionStats returns transaction statistics
+func (m *MySQLMonitor) GetTransactionStats() health.TransactionStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.transactionStats()
+}
+
+// GetOverallStats returns overall statistics
+func (m *MySQLMonitor) GetOverallStats() health.StatsResult {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return health.NewOverallStats(
+		m.queryStats(),
+		health.NewConnectionStats(m.connections, m.failedConnections),
+		m.transactionStats(),
+		time.Since(m.startTime),
+		m.lastActivity,
+	)
+}
+
+func (m *MySQLMonitor) queryStats() health.QueryStats {
+	return health.NewQueryStats(
+		m.queries,
+		m.queries-m.failedQueries,
+		m.failedQueries,
+		average(m.queryDuration, m.queries),
+		m.slowestQuery,
+		m.fastestQuery,
+	)
+}
+
+func (m *MySQLMonitor) transactionStats() health.TransactionStats {
+	return health.NewTransactionStats(
+		m.transactions,
+		m.transactions-m.failedTrans,
+		m.failedTrans,
+		m.rolledBackTrans,
+		average(m.transDuration, m.transactions),
+	)
+}
+
+func average(total time.Duration, count int64) time.Duration {
+	if count == 0 {
+		return 0
+	}
+	return total / time.Duration(count)
+}
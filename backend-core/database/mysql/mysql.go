@@ -0,0 +1,444 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"backend-core/config"
+	"backend-core/database/gorm"
+	"backend-core/database/health"
+	"backend-core/database/interfaces"
+	"backend-core/database/postgresql"
+	"backend-core/logging"
+
+	"gorm.io/driver/mysql"
+	gormDB "gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// MySQLDatabase implements the ExtendedDatabase interface for MySQL
+type MySQLDatabase struct {
+	config           *config.DatabaseConfig
+	logger           *logging.Logger
+	gormDB           *gormDB.DB
+	sqlDB            *sql.DB
+	migrationManager *postgresql.PostgreSQLMigrationManager
+	healthChecker    *MySQLHealthChecker
+	monitor          *MySQLMonitor
+	isConnected      bool
+	connectedAt      time.Time
+}
+
+// NewMySQLDatabase creates a new MySQL database instance
+func NewMySQLDatabase(cfg *config.DatabaseConfig) *MySQLDatabase {
+	logger, _ := logging.NewLogger(&config.LoggingConfig{
+		Level:      "info",
+		Format:     "json",
+		Output:     "stdout",
+		MaxSize:    100,
+		MaxBackups: 3,
+		MaxAge:     28,
+		Compress:   true,
+	})
+
+	return &MySQLDatabase{
+		config: cfg,
+		logger: logger,
+	}
+}
+
+// LogConnection logs connection events
+func (m *MySQLDatabase) LogConnection(event string, err error) {
+	if err != nil {
+		m.logger.Error("MySQL connection event", "event", event, "error", err)
+	} else {
+		m.logger.Info("MySQL connection event", "event", event)
+	}
+}
+
+// AutoMigrate performs automatic migration
+func (m *MySQLDatabase) AutoMigrate(ctx context.Context, models ...interface{}) error {
+	return m.gormDB.WithContext(ctx).AutoMigrate(models...)
+}
+
+// Migrate performs manual migration
+func (m *MySQLDatabase) Migrate(ctx context.Context, models ...interface{}) error {
+	migrator := m.gormDB.WithContext(ctx).Migrator()
+	for _, model := range models {
+		if !migrator.HasTable(model) {
+			if err := migrator.CreateTable(model); err != nil {
+				return err
+			}
+		} else {
+			if err := migrator.AutoMigrate(model); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DropTable drops tables
+func (m *MySQLDatabase) DropTable(ctx context.Context, models ...interface{}) error {
+	migrator := m.gormDB.WithContext(ctx).Migrator()
+	for _, model := range models {
+		if migrator.HasTable(model) {
+			if err := migrator.DropTable(model); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// HasTable checks if a table exists
+func (m *MySQLDatabase) HasTable(ctx context.Context, model interface{}) bool {
+	return m.gormDB.WithContext(ctx).Migrator().HasTable(model)
+}
+
+// GetMigrationStatus returns migration status
+func (m *MySQLDatabase) GetMigrationStatus(ctx context.Context) ([]gorm.MigrationInfo, error) {
+	// Simplified implementation
+	return []gorm.MigrationInfo{}, nil
+}
+
+// BeginTransaction starts a new transaction
+func (m *MySQLDatabase) BeginTransaction(ctx context.Context) (gorm.Transaction, error) {
+	tx := m.gormDB.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return gorm.NewGormTransaction(tx), nil
+}
+
+// WithTransaction executes a function within a transaction
+func (m *MySQLDatabase) WithTransaction(ctx context.Context, fn func(gorm.Transaction) error) error {
+	return m.gormDB.WithContext(ctx).Transaction(func(tx *gormDB.DB) error {
+		return fn(gorm.NewGormTransaction(tx))
+	})
+}
+
+// GetRepository creates a repository (for gorm.Database interface)
+func (m *MySQLDatabase) GetRepository(entityType string) interface{} {
+	return gorm.NewGormRepository[interface{}](m, entityType, m.logger)
+}
+
+// GetRepositoryDefault creates a repository (parameterless version for Database interface)
+func (m *MySQLDatabase) GetRepositoryDefault() interface{} {
+	return gorm.NewGormRepository[interface{}](m, "default", m.logger)
+}
+
+// DatabaseWrapper wraps MySQLDatabase to implement the main Database interface
+type DatabaseWrapper struct {
+	*MySQLDatabase
+}
+
+// GetRepository implements the main Database interface
+func (w *DatabaseWrapper) GetRepository() interface{} {
+	return w.GetRepositoryDefault()
+}
+
+// WithTransaction implements the main Database interface
+func (w *DatabaseWrapper) WithTransaction(ctx context.Context, fn func(context.Context) error) error {
+	return w.MySQLDatabase.WithTransaction(ctx, func(tx gorm.Transaction) error {
+		return fn(ctx)
+	})
+}
+
+// GetLogger returns the logger
+func (m *MySQLDatabase) GetLogger() *logging.Logger {
+	return m.logger
+}
+
+// GetGormDB returns the GORM database instance
+func (m *MySQLDatabase) GetGormDB() *gormDB.DB {
+	return m.gormDB
+}
+
+// HealthCheck performs a health check on the database
+func (m *MySQLDatabase) HealthCheck(ctx context.Context) error {
+	if m.healthChecker == nil {
+		return fmt.Errorf("health checker not initialized")
+	}
+	return m.healthChecker.CheckHealth(ctx)
+}
+
+// GetConfig returns the database configuration
+func (m *MySQLDatabase) GetConfig() gorm.DatabaseConfig {
+	return gorm.DatabaseConfig{
+		DatabaseConfig: *m.config,
+		AliasName:      "mysql",
+		Singular:       true,
+		LogZap:         true,
+	}
+}
+
+// IsConnected returns the connection status
+func (m *MySQLDatabase) IsConnected() bool {
+	return m.isConnected
+}
+
+// SetConnected sets the connection status
+func (m *MySQLDatabase) SetConnected(connected bool) {
+	m.isConnected = connected
+	if connected {
+		m.connectedAt = time.Now()
+	}
+}
+
+// GetConnectedAt returns when the database was connected
+func (m *MySQLDatabase) GetConnectedAt() time.Time {
+	return m.connectedAt
+}
+
+// Connect establishes connection to MySQL
+func (m *MySQLDatabase) Connect(ctx context.Context) error {
+	// Use DSN provider for connection string generation
+	cfg := m.GetConfig()
+	dsnProvider := config.NewDSNProvider(cfg.Type, &cfg.DatabaseConfig)
+	if dsnProvider == nil {
+		return fmt.Errorf("unsupported database type: %s", cfg.Type)
+	}
+
+	dsn := dsnProvider.Dsn()
+	m.LogConnection("connecting", nil)
+
+	// Configure GORM
+	gormConfig := &gormDB.Config{
+		Logger: logger.Default.LogMode(getLogLevel(cfg.LogLevel)),
+		NowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+
+	db, err := gormDB.Open(mysql.Open(dsn), gormConfig)
+	if err != nil {
+		m.LogConnection("connection_failed", err)
+		return fmt.Errorf("failed to connect to MySQL: %w", err)
+	}
+
+	// Restrict GORM operations to the context tenant
+	if cfg.MultiTenant {
+		if err := gorm.RegisterTenantScope(db); err != nil {
+			m.LogConnection("tenant_scope_failed", err)
+			return err
+		}
+	}
+
+	// Get underlying sql.DB for connection pool management
+	sqlDB, err := db.DB()
+	if err != nil {
+		m.LogConnection("pool_creation_failed", err)
+		return fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+
+	// Configure connection pool
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	// Test connection
+	if err := sqlDB.PingContext(ctx); err != nil {
+		m.LogConnection("ping_failed", err)
+		return fmt.Errorf("failed to ping MySQL: %w", err)
+	}
+
+	// Set database components
+	m.gormDB = db
+	m.sqlDB = sqlDB
+	m.SetConnected(true)
+
+	// Initialize components. The schema_migrations table of the PostgreSQL migration
+	// manager is plain SQL that MySQL accepts as well.
+	m.migrationManager = postgresql.NewPostgreSQLMigrationManager(db)
+	m.healthChecker = NewMySQLHealthChecker(sqlDB, &cfg.DatabaseConfig)
+	m.monitor = NewMySQLMonitor()
+
+	m.LogConnection("connected", nil)
+	return nil
+}
+
+// Disconnect closes the database connection
+func (m *MySQLDatabase) Disconnect(ctx context.Context) error {
+	if m.sqlDB != nil {
+		m.LogConnection("disconnecting", nil)
+		if err := m.sqlDB.Close(); err != nil {
+			m.LogConnection("disconnect_failed", err)
+			return fmt.Errorf("failed to close MySQL connection: %w", err)
+		}
+		m.SetConnected(false)
+		m.LogConnection("disconnected", nil)
+	}
+	return nil
+}
+
+// Ping tests the database connection
+func (m *MySQLDatabase) Ping(ctx context.Context) error {
+	if m.sqlDB == nil {
+		return fmt.Errorf("MySQL is not connected")
+	}
+
+	if err := m.sqlDB.PingContext(ctx); err != nil {
+		m.LogConnection("ping_failed", err)
+		return fmt.Errorf("MySQL ping failed: %w", err)
+	}
+
+	return nil
+}
+
+// IsHealthy checks if the database is healthy
+func (m *MySQLDatabase) IsHealthy(ctx context.Context) bool {
+	if m.healthChecker != nil {
+		return m.healthChecker.GetStatus() == health.HealthStatusHealthy
+	}
+
+	// Fallback to ping
+	return m.Ping(ctx) == nil
+}
+
+// RunMigrations runs database migrations
+func (m *MySQLDatabase) RunMigrations(ctx context.Context) error {
+	if m.migrationManager != nil {
+		return m.migrationManager.RunMigrations(ctx)
+	}
+	return fmt.Errorf("migration manager not initialized")
+}
+
+// RollbackMigration rolls back a specific migration
+func (m *MySQLDatabase) RollbackMigration(ctx context.Context, version string) error {
+	if m.migrationManager != nil {
+		return m.migrationManager.RollbackMigration(ctx, version)
+	}
+	return fmt.Errorf("migration manager not initialized")
+}
+
+// GetStats returns connection statistics
+func (m *MySQLDatabase) GetStats() interface{} {
+	if m.sqlDB == nil {
+		return health.NewConnectionStats(0, 0)
+	}
+
+	stats := m.sqlDB.Stats()
+	return health.NewConnectionStats(
+		int64(stats.OpenConnections),
+		0, // ConnectionFailures would need to be tracked separately
+	)
+}
+
+// SetMaxOpenConns sets the maximum number of open connections
+func (m *MySQLDatabase) SetMaxOpenConns(max int) {
+	if m.sqlDB != nil {
+		m.sqlDB.SetMaxOpenConns(max)
+	}
+}
+
+// SetMaxIdleConns sets the maximum number of idle connections
+func (m *MySQLDatabase) SetMaxIdleConns(max int) {
+	if m.sqlDB != nil {
+		m.sqlDB.SetMaxIdleConns(max)
+	}
+}
+
+// SetConnMaxLifetime sets the maximum lifetime of connections
+func (m *MySQLDatabase) SetConnMaxLifetime(d time.Duration) {
+	if m.sqlDB != nil {
+		m.sqlDB.SetConnMaxLifetime(d)
+	}
+}
+
+// ExtendedDatabase interface methods
+
+// GetDriver returns the GORM database instance
+func (m *MySQLDatabase) GetDriver() interface{} {
+	return m.gormDB
+}
+
+// GetConnectionPool returns the underlying sql.DB
+func (m *MySQLDatabase) GetConnectionPool() interface{} {
+	return m.sqlDB
+}
+
+// GetQueryBuilder returns nil, as MySQL has no query builder yet. The PostgreSQL one uses
+// operators MySQL does not understand.
+func (m *MySQLDatabase) GetQueryBuilder() interface{} {
+	return nil
+}
+
+// GetMigrationManager returns the migration manager
+func (m *MySQLDatabase) GetMigrationManager() interface{} {
+	return m.migrationManager
+}
+
+// GetTransactionManager returns the MySQL transaction manager
+func (m *MySQLDatabase) GetTransactionManager() interfaces.TransactionManager {
+	return &TransactionManager{db: m.sqlDB}
+}
+
+// GetHealthChecker returns the MySQL health checker
+func (m *MySQLDatabase) GetHealthChecker() interfaces.DatabaseHealthChecker {
+	return m.healthChecker
+}
+
+// GetMonitor returns the MySQL monitor
+func (m *MySQLDatabase) GetMonitor() interfaces.DatabaseMonitor {
+	return m.monitor
+}
+
+// TransactionManager runs MySQL transactions on the underlying sql.DB
+type TransactionManager struct {
+	db *sql.DB
+}
+
+// BeginTransaction starts a new transaction
+func (t *TransactionManager) BeginTransaction(ctx context.Context) (interfaces.Transaction, error) {
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Transaction{tx: tx}, nil
+}
+
+// WithTransaction executes a function within a transaction, committing it if fn succeeds
+func (t *TransactionManager) WithTransaction(ctx context.Context, fn func(interfaces.Transaction) error) error {
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(&Transaction{tx: tx}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Transaction implements interfaces.Transaction for a sql.Tx
+type Transaction struct {
+	tx *sql.Tx
+}
+
+func (t *Transaction) Commit() error              { return t.tx.Commit() }
+func (t *Transaction) Rollback() error            { return t.tx.Rollback() }
+func (t *Transaction) IsActive() bool             { return true }
+func (t *Transaction) GetRepository() interface{} { return nil }
+
+// Helper functions
+
+func getLogLevel(level string) logger.LogLevel {
+	switch level {
+	case "silent":
+		return logger.Silent
+	case "error":
+		return logger.Error
+	case "warn":
+		return logger.Warn
+	case "info":
+		return logger.Info
+	default:
+		return logger.Info
+	}
+}
@@ -0,0 +1,173 @@
+package observability
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"backend-core/logging"
+	"backend-core/telemetry"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// BaseRepository provides the tracing, metrics and query logging shared by repositories.
+// Embed it in a repository to get StartSpan, RecordError, RecordMetric, LogQuery and Observe.
+// Without telemetry, or with telemetry disabled, spans and metrics are no-ops and only logging remains.
+type BaseRepository struct {
+	name      string
+	logger    *logging.Logger
+	telemetry *telemetry.Telemetry
+
+	mu         sync.Mutex
+	counters   map[string]metric.Float64Counter
+	histograms map[string]metric.Float64Histogram
+}
+
+// NewBaseRepository creates a base for the repository called name, which is attached to
+// every metric it records. telemetry may be nil.
+func NewBaseRepository(name string, logger *logging.Logger, telemetry *telemetry.Telemetry) *BaseRepository {
+	return &BaseRepository{
+		name:       name,
+		logger:     logger,
+		telemetry:  telemetry,
+		counters:   make(map[string]metric.Float64Counter),
+		histograms: make(map[string]metric.Float64Histogram),
+	}
+}
+
+// Telemetry returns the telemetry the base records to, which may be nil
+func (b *BaseRepository) Telemetry() *telemetry.Telemetry {
+	return b.telemetry
+}
+
+// StartSpan starts a span for operation, or returns the span already in ctx when telemetry is off
+func (b *BaseRepository) StartSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	if !b.enabled() {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	return b.telemetry.StartSpan(ctx, operation, trace.WithAttributes(attribute.String("repository", b.name)))
+}
+
+// RecordError records err on span and marks the span as failed
+func (b *BaseRepository) RecordError(span trace.Span, err error) {
+	if span == nil || err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// RecordMetric records value for the metric called name. Names ending in "_total" are
+// counters and everything else is a histogram. Instruments are created on first use.
+func (b *BaseRepository) RecordMetric(ctx context.Context, name string, value float64, labels map[string]string) {
+	if !b.enabled() {
+		return
+	}
+
+	attrs := make([]attribute.KeyValue, 0, len(labels)+1)
+	attrs = append(attrs, attribute.String("repository", b.name))
+	for k, v := range labels {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+
+	if strings.HasSuffix(name, "_total") {
+		counter, err := b.counter(name)
+		if err != nil {
+			b.logger.Warn("Failed to create repository counter", logging.String("metric", name), logging.Error(err))
+			return
+		}
+		counter.Add(ctx, value, metric.WithAttributes(attrs...))
+		return
+	}
+
+	histogram, err := b.histogram(name)
+	if err != nil {
+		b.logger.Warn("Failed to create repository histogram", logging.String("metric", name), logging.Error(err))
+		return
+	}
+	histogram.Record(ctx, value, metric.WithAttributes(attrs...))
+}
+
+// LogQuery logs a completed query and records its duration.
+// args is accepted so callers can pass the query input, but it is not logged since it may hold sensitive values.
+func (b *BaseRepository) LogQuery(query string, args interface{}, duration time.Duration, err error) {
+	status := "ok"
+	if err != nil {
+		status = "error"
+		b.logger.Error("Database query failed",
+			logging.String("repository", b.name),
+			logging.String("query", query),
+			logging.Duration("duration", duration),
+			logging.Error(err))
+	} else {
+		b.logger.Info("Database query completed",
+			logging.String("repository", b.name),
+			logging.String("query", query),
+			logging.Duration("duration", duration))
+	}
+
+	b.RecordMetric(context.Background(), "repository_query_duration_ms", float64(duration.Milliseconds()),
+		map[string]string{"query": query, "status": status})
+}
+
+// Observe runs fn inside a span for operation and records its duration, count and errors
+// as "<operation>_duration_ms", "<operation>_total" and "<operation>_errors_total".
+func (b *BaseRepository) Observe(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	ctx, span := b.StartSpan(ctx, operation)
+	defer span.End()
+
+	prefix := strings.ReplaceAll(operation, ".", "_")
+	start := time.Now()
+	err := fn(ctx)
+
+	b.RecordMetric(ctx, prefix+"_duration_ms", float64(time.Since(start).Milliseconds()), nil)
+	b.RecordMetric(ctx, prefix+"_total", 1, nil)
+	if err != nil {
+		b.RecordError(span, err)
+		b.RecordMetric(ctx, prefix+"_errors_total", 1, nil)
+	}
+
+	return err
+}
+
+// enabled reports whether spans and metrics should be recorded
+func (b *BaseRepository) enabled() bool {
+	return b.telemetry != nil && b.telemetry.Config.Enabled
+}
+
+// counter returns the counter called name, creating it on first use
+func (b *BaseRepository) counter(name string) (metric.Float64Counter, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if counter, ok := b.counters[name]; ok {
+		return counter, nil
+	}
+	counter, err := b.telemetry.GetMeter().Float64Counter(name)
+	if err != nil {
+		return nil, err
+	}
+	b.counters[name] = counter
+	return counter, nil
+}
+
+// histogram returns the histogram called name, creating it on first use
+func (b *BaseRepository) histogram(name string) (metric.Float64Histogram, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if histogram, ok := b.histograms[name]; ok {
+		return histogram, nil
+	}
+	histogram, err := b.telemetry.GetMeter().Float64Histogram(name)
+	if err != nil {
+		return nil, err
+	}
+	b.histograms[name] = histogram
+	return histogram, nil
+}
@@ -0,0 +1,118 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"backend-core/logging"
+	"backend-core/telemetry"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recordingMeterProvider hands out a meter that records the instruments it was given values for
+type recordingMeterProvider struct {
+	noop.MeterProvider
+	meter *recordingMeter
+}
+
+func (p *recordingMeterProvider) Meter(name string, opts ...metric.MeterOption) metric.Meter {
+	return p.meter
+}
+
+type recordingMeter struct {
+	noop.Meter
+	mu     sync.Mutex
+	values map[string]int
+}
+
+func (m *recordingMeter) record(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[name]++
+}
+
+func (m *recordingMeter) Float64Counter(name string, opts ...metric.Float64CounterOption) (metric.Float64Counter, error) {
+	return &recordingCounter{name: name, meter: m}, nil
+}
+
+func (m *recordingMeter) Float64Histogram(name string, opts ...metric.Float64HistogramOption) (metric.Float64Histogram, error) {
+	return &recordingHistogram{name: name, meter: m}, nil
+}
+
+type recordingCounter struct {
+	noop.Float64Counter
+	name  string
+	meter *recordingMeter
+}
+
+func (c *recordingCounter) Add(ctx context.Context, value float64, opts ...metric.AddOption) {
+	c.meter.record(c.name)
+}
+
+type recordingHistogram struct {
+	noop.Float64Histogram
+	name  string
+	meter *recordingMeter
+}
+
+func (h *recordingHistogram) Record(ctx context.Context, value float64, opts ...metric.RecordOption) {
+	h.meter.record(h.name)
+}
+
+func TestObserveRecordsSpanAndMetrics(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	meter := &recordingMeter{values: make(map[string]int)}
+	previousTracer, previousMeter := otel.GetTracerProvider(), otel.GetMeterProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+	otel.SetMeterProvider(&recordingMeterProvider{meter: meter})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousTracer)
+		otel.SetMeterProvider(previousMeter)
+	})
+
+	logger := logging.NewNopLogger()
+	base := NewBaseRepository("permissions", logger, &telemetry.Telemetry{
+		Config: telemetry.TelemetryConfig{ServiceName: "test", Enabled: true},
+	})
+
+	failure := errors.New("connection reset")
+	if err := base.Observe(context.Background(), "permission.get", func(ctx context.Context) error {
+		return failure
+	}); err != failure {
+		t.Fatalf("Observe() error = %v, want the wrapped error", err)
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 1 || ended[0].Name() != "permission.get" {
+		t.Fatalf("ended spans = %v, want one permission.get span", ended)
+	}
+	if ended[0].Status().Code != codes.Error {
+		t.Errorf("span status = %v, want Error", ended[0].Status())
+	}
+	for _, name := range []string{"permission_get_duration_ms", "permission_get_total", "permission_get_errors_total"} {
+		if meter.values[name] != 1 {
+			t.Errorf("metric %s recorded %d times, want 1", name, meter.values[name])
+		}
+	}
+}
+
+func TestObserveWithoutTelemetry(t *testing.T) {
+	logger := logging.NewNopLogger()
+	base := NewBaseRepository("permissions", logger, nil)
+
+	called := false
+	if err := base.Observe(context.Background(), "permission.get", func(ctx context.Context) error {
+		called = true
+		return nil
+	}); err != nil || !called {
+		t.Errorf("Observe() = %v, called = %v, want the operation run without telemetry", err, called)
+	}
+}
@@ -251,6 +251,14 @@ func (p *PostgreSQLDatabase) Connect(ctx context.Context) error {
 		return fmt.Errorf("failed to connect to PostgreSQL: %w", err)
 	}
 
+	// Restrict GORM operations to the context tenant
+	if cfg.MultiTenant {
+		if err := gorm.RegisterTenantScope(gormDB); err != nil {
+			p.LogConnection("tenant_scope_failed", err)
+			return err
+		}
+	}
+
 	// Get underlying sql.DB for connection pool management
 	sqlDB, err := gormDB.DB()
 	if err != nil {
@@ -0,0 +1,53 @@
+// Package tenancy decides which tenant a repository operation is scoped to. Repositories with
+// multi-tenancy enabled restrict every read and write to the tenant in the context
+// (ctxkeys.TenantID) and refuse to run without one, unless the context was explicitly
+// marked with WithoutTenantScope.
+package tenancy
+
+import (
+	"context"
+	"errors"
+
+	"backend-core/ctxkeys"
+)
+
+// Column is the column or document field holding the tenant of a row
+const Column = "tenant_id"
+
+var (
+	// ErrTenantRequired is returned for tenant-scoped operations whose context has no tenant
+	ErrTenantRequired = errors.New("tenant-scoped operation requires a tenant ID in the context")
+	// ErrTenantMismatch is returned when an entity written belongs to another tenant than the context
+	ErrTenantMismatch = errors.New("entity belongs to a different tenant")
+)
+
+// unscopedKey marks a context whose operations bypass tenant scoping
+type unscopedKey struct{}
+
+// WithoutTenantScope returns a context whose repository operations are not restricted to a
+// tenant. It is meant for admin and maintenance operations that must span tenants.
+func WithoutTenantScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unscopedKey{}, true)
+}
+
+// IsUnscoped reports whether ctx was marked with WithoutTenantScope
+func IsUnscoped(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	unscoped, _ := ctx.Value(unscopedKey{}).(bool)
+	return unscoped
+}
+
+// Resolve returns the tenant an operation under ctx is scoped to. scoped is false when ctx
+// was marked with WithoutTenantScope; otherwise a missing tenant is ErrTenantRequired.
+func Resolve(ctx context.Context) (tenantID string, scoped bool, err error) {
+	if IsUnscoped(ctx) {
+		return "", false, nil
+	}
+	tenantID = ctxkeys.TenantIDFrom(ctx)
+	if tenantID == "" {
+		return "", false, ErrTenantRequired
+	}
+	return tenantID, true, nil
+}
@@ -59,6 +59,7 @@ type QueryConfig = query.Config
 const (
 	PostgreSQL = core.PostgreSQL
 	MongoDB    = core.MongoDB
+	MySQL      = core.MySQL
 )
 
 const (
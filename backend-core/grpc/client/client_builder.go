@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"backend-core/grpc/interceptors/auth"
+	"backend-core/grpc/interceptors/correlation"
 	grpclogging "backend-core/grpc/interceptors/logging"
 	"backend-core/grpc/interceptors/metrics"
 	"backend-core/grpc/interceptors/retry"
@@ -99,6 +100,16 @@ func (b *ClientBuilder) WithTracing() *ClientBuilder {
 	return b
 }
 
+// WithCorrelation propagates the request and correlation IDs of the context in the
+// outgoing metadata
+func (b *ClientBuilder) WithCorrelation() *ClientBuilder {
+	b.unaryInterceptors = append(b.unaryInterceptors,
+		correlation.UnaryClientInterceptor())
+	b.streamInterceptors = append(b.streamInterceptors,
+		correlation.StreamClientInterceptor())
+	return b
+}
+
 // WithUnaryInterceptor adds a custom unary interceptor
 func (b *ClientBuilder) WithUnaryInterceptor(interceptor grpc.UnaryClientInterceptor) *ClientBuilder {
 	b.unaryInterceptors = append(b.unaryInterceptors, interceptor)
@@ -178,5 +189,6 @@ func BuildWithDefaults(address string, logger *logging.Logger) (*grpc.ClientConn
 		WithRetry(DefaultRetryConfig()).
 		WithLogging(logger).
 		WithTracing().
+		WithCorrelation().
 		Build()
 }
@@ -96,27 +96,8 @@ func UnaryServerInterceptor(logger *logging.Logger, config *AuthConfig) grpc.Una
 			return nil, grpcerrors.NewUnauthenticatedError("invalid or expired token")
 		}
 
-		// Extract user information from claims
-		userID := claims["user_id"].(string)
-		username := ""
-		if un, ok := claims["username"].(string); ok {
-			username = un
-		}
-
-		// Extract roles if present
-		var roles []string
-		if r, ok := claims["roles"].([]interface{}); ok {
-			for _, role := range r {
-				if roleStr, ok := role.(string); ok {
-					roles = append(roles, roleStr)
-				}
-			}
-		}
-
 		// Add user information to context
-		ctx = grpcmiddleware.WithUserID(ctx, userID)
-		ctx = grpcmiddleware.WithUsername(ctx, username)
-		ctx = grpcmiddleware.WithRoles(ctx, roles)
+		ctx, userID, username := withClaims(ctx, claims)
 
 		logger.Debug("Authentication successful",
 			logging.String("user_id", userID),
@@ -158,10 +139,12 @@ func StreamServerInterceptor(logger *logging.Logger, config *AuthConfig) grpc.St
 		if apiKeys := md.Get("x-api-key"); len(apiKeys) > 0 {
 			apiKey := apiKeys[0]
 			if serviceName, valid := validateAPIKey(config, apiKey); valid {
+				ctx = grpcmiddleware.WithUserID(ctx, serviceName)
+				ctx = grpcmiddleware.WithUsername(ctx, serviceName)
 				logger.Debug("API key authentication successful for stream",
 					logging.String("service", serviceName),
 					logging.String("method", info.FullMethod))
-				return handler(srv, ss)
+				return handler(srv, grpcmiddleware.WrapServerStream(ss, ctx))
 			}
 		}
 
@@ -186,7 +169,7 @@ func StreamServerInterceptor(logger *logging.Logger, config *AuthConfig) grpc.St
 			Audience: config.JWTAudience,
 		}
 
-		_, err := security.ValidateJWT(token, jwtConfig)
+		claims, err := security.ValidateJWT(token, jwtConfig)
 		if err != nil {
 			logger.Warn("JWT validation failed for stream",
 				logging.String("method", info.FullMethod),
@@ -194,10 +177,38 @@ func StreamServerInterceptor(logger *logging.Logger, config *AuthConfig) grpc.St
 			return grpcerrors.NewUnauthenticatedError("invalid or expired token")
 		}
 
-		return handler(srv, ss)
+		// Add user information to the stream context
+		ctx, _, _ = withClaims(ctx, claims)
+
+		return handler(srv, grpcmiddleware.WrapServerStream(ss, ctx))
 	}
 }
 
+// withClaims adds the user ID, username and roles in claims to ctx. The user ID is read
+// from the user_id claim, falling back to the standard sub claim.
+func withClaims(ctx context.Context, claims map[string]interface{}) (context.Context, string, string) {
+	userID, _ := claims["user_id"].(string)
+	if userID == "" {
+		userID, _ = claims["sub"].(string)
+	}
+	username, _ := claims["username"].(string)
+
+	// Extract roles if present
+	var roles []string
+	if r, ok := claims["roles"].([]interface{}); ok {
+		for _, role := range r {
+			if roleStr, ok := role.(string); ok {
+				roles = append(roles, roleStr)
+			}
+		}
+	}
+
+	ctx = grpcmiddleware.WithUserID(ctx, userID)
+	ctx = grpcmiddleware.WithUsername(ctx, username)
+	ctx = grpcmiddleware.WithRoles(ctx, roles)
+	return ctx, userID, username
+}
+
 // extractBearerToken extracts the token from "Bearer <token>" format
 func extractBearerToken(authHeader string) string {
 	parts := strings.SplitN(authHeader, " ", 2)
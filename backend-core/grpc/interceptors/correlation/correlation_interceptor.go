@@ -0,0 +1,120 @@
+package correlation
+
+import (
+	"context"
+
+	"backend-core/ctxkeys"
+	grpcmiddleware "backend-core/grpc/middleware"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Metadata keys the IDs are carried in, matching the HTTP headers of the request
+// correlation middleware
+const (
+	RequestIDKey     = "x-request-id"
+	CorrelationIDKey = "x-correlation-id"
+	// traceIDKey is the correlation ID key of the tracing interceptor, read as a fallback
+	traceIDKey = "x-trace-id"
+)
+
+// Inject adds the request and correlation IDs to the outgoing metadata of ctx, leaving
+// IDs already in the metadata as they are
+func Inject(ctx context.Context, requestID, correlationID string) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+
+	var pairs []string
+	if requestID != "" && len(md.Get(RequestIDKey)) == 0 {
+		pairs = append(pairs, RequestIDKey, requestID)
+	}
+	if correlationID != "" && len(md.Get(CorrelationIDKey)) == 0 {
+		pairs = append(pairs, CorrelationIDKey, correlationID)
+	}
+	if len(pairs) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+// Extract returns the request and correlation IDs in the incoming metadata of ctx. The
+// correlation ID falls back to the trace ID and then to the request ID.
+func Extract(ctx context.Context) (requestID, correlationID string) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", ""
+	}
+
+	requestID = first(md, RequestIDKey)
+	correlationID = first(md, CorrelationIDKey)
+	if correlationID == "" {
+		correlationID = first(md, traceIDKey)
+	}
+	if correlationID == "" {
+		correlationID = requestID
+	}
+	return requestID, correlationID
+}
+
+// UnaryServerInterceptor returns a unary server interceptor that adds the request and
+// correlation IDs of the incoming metadata to the context
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(withIDs(ctx), req)
+	}
+}
+
+// StreamServerInterceptor returns a stream server interceptor that adds the request and
+// correlation IDs of the incoming metadata to the stream context
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, grpcmiddleware.WrapServerStream(ss, withIDs(ss.Context())))
+	}
+}
+
+// UnaryClientInterceptor returns a unary client interceptor that propagates the request and
+// correlation IDs of the context, e.g. those set by the HTTP request correlation middleware,
+// in the outgoing metadata
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(propagate(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor returns a stream client interceptor that propagates the request
+// and correlation IDs of the context in the outgoing metadata
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(propagate(ctx), desc, cc, method, opts...)
+	}
+}
+
+// withIDs stores the IDs of the incoming metadata in ctx, where both the HTTP helpers and
+// the gRPC context helpers find them
+func withIDs(ctx context.Context) context.Context {
+	requestID, correlationID := Extract(ctx)
+	if requestID != "" {
+		ctx = ctxkeys.WithRequestID(ctx, requestID)
+	}
+	if correlationID != "" {
+		ctx = ctxkeys.WithCorrelationID(ctx, correlationID)
+		ctx = grpcmiddleware.WithCorrelationID(ctx, correlationID)
+	}
+	return ctx
+}
+
+// propagate injects the IDs stored in ctx into its outgoing metadata
+func propagate(ctx context.Context) context.Context {
+	correlationID := ctxkeys.CorrelationIDFrom(ctx)
+	if correlationID == "" {
+		correlationID, _ = grpcmiddleware.GetCorrelationID(ctx)
+	}
+	return Inject(ctx, ctxkeys.RequestIDFrom(ctx), correlationID)
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,29 @@
+package inflight
+
+import (
+	"context"
+
+	"backend-core/inflight"
+
+	"google.golang.org/grpc"
+)
+
+// UnaryServerInterceptor returns a new unary server interceptor counting in-flight calls in tracker
+func UnaryServerInterceptor(tracker *inflight.Tracker) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		done := tracker.Track()
+		defer done()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor counting open streams in tracker
+func StreamServerInterceptor(tracker *inflight.Tracker) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		done := tracker.Track()
+		defer done()
+
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"backend-core/telemetry"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BusinessMetricsUnaryServerInterceptor returns a unary server interceptor recording the count
+// and duration of every call with bm, labelled by full method name and status code, and
+// counting calls that end with a status other than OK as errors. A handler that panics is
+// recorded as Internal, the code the recovery interceptor turns the panic into.
+func BusinessMetricsUnaryServerInterceptor(bm *telemetry.BusinessMetrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		startTime := time.Now()
+		completed := false
+		defer func() {
+			// Still unwinding a panic; recording without recovering keeps its stack intact
+			if !completed {
+				recordBusinessMetrics(ctx, bm, info.FullMethod, codes.Internal, startTime)
+			}
+		}()
+
+		resp, err := handler(ctx, req)
+		completed = true
+
+		recordBusinessMetrics(ctx, bm, info.FullMethod, status.Code(err), startTime)
+		return resp, err
+	}
+}
+
+// BusinessMetricsStreamServerInterceptor returns a stream server interceptor recording every
+// stream with bm once it ends, like BusinessMetricsUnaryServerInterceptor
+func BusinessMetricsStreamServerInterceptor(bm *telemetry.BusinessMetrics) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		startTime := time.Now()
+		completed := false
+		defer func() {
+			if !completed {
+				recordBusinessMetrics(ctx, bm, info.FullMethod, codes.Internal, startTime)
+			}
+		}()
+
+		err := handler(srv, ss)
+		completed = true
+
+		recordBusinessMetrics(ctx, bm, info.FullMethod, status.Code(err), startTime)
+		return err
+	}
+}
+
+func recordBusinessMetrics(ctx context.Context, bm *telemetry.BusinessMetrics, method string, code codes.Code, startTime time.Time) {
+	duration := time.Since(startTime).Seconds()
+	bm.RecordGRPCRequest(ctx, method, code.String(), duration)
+	if code != codes.OK {
+		bm.RecordGRPCError(ctx, method, code.String())
+	}
+}
@@ -0,0 +1,205 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	grpcerrors "backend-core/grpc/errors"
+	grpcmiddleware "backend-core/grpc/middleware"
+	"backend-core/logging"
+
+	"google.golang.org/grpc"
+)
+
+// SubjectLimit is the token bucket of one subject: it holds up to Burst requests and refills
+// at RequestsPerSecond
+type SubjectLimit struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// SubjectLimitConfig holds configuration for per-subject rate limiting
+type SubjectLimitConfig struct {
+	// Default is the limit of subjects without their own limit
+	Default SubjectLimit
+	// Subjects are per-subject limits, keyed by API key service name or JWT subject
+	Subjects map[string]SubjectLimit
+	// ExemptMethods are full method names that are never rate limited, e.g. health checks
+	// and reflection
+	ExemptMethods []string
+}
+
+// bucketIdleTimeout is how long a bucket must go unused before Cleanup may evict it
+const bucketIdleTimeout = 5 * time.Minute
+
+// SubjectLimiter rate limits calls per authenticated subject with an in-memory token bucket
+// each. The subject is the user ID the auth interceptor adds to the context, which is the
+// service name for API keys and the subject for JWTs, so the limiter must come after it.
+// Calls without a subject, e.g. with authentication disabled, are limited per peer IP, so
+// a client cannot get a fresh bucket by reconnecting from another port.
+type SubjectLimiter struct {
+	config *SubjectLimitConfig
+	exempt map[string]bool
+	logger *logging.Logger
+
+	mu          sync.Mutex
+	buckets     map[string]*tokenBucket
+	lastCleanup time.Time
+}
+
+// NewSubjectLimiter creates a per-subject rate limiter
+func NewSubjectLimiter(config *SubjectLimitConfig, logger *logging.Logger) *SubjectLimiter {
+	exempt := make(map[string]bool, len(config.ExemptMethods))
+	for _, method := range config.ExemptMethods {
+		exempt[method] = true
+	}
+
+	return &SubjectLimiter{
+		config:      config,
+		exempt:      exempt,
+		logger:      logger,
+		buckets:     make(map[string]*tokenBucket),
+		lastCleanup: time.Now(),
+	}
+}
+
+// Allow takes a token from the bucket of subject, reporting whether one was available.
+// It implements Limiter.
+func (l *SubjectLimiter) Allow(ctx context.Context, subject string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastCleanup) >= bucketIdleTimeout {
+		l.cleanup(now)
+	}
+
+	bucket, ok := l.buckets[subject]
+	if !ok {
+		bucket = newTokenBucket(l.limitFor(subject), now)
+		l.buckets[subject] = bucket
+	}
+	return bucket.take(now), nil
+}
+
+// Cleanup evicts the buckets of subjects idle for more than bucketIdleTimeout that have
+// refilled, so forgetting them changes no decision. Allow runs it at most once per
+// bucketIdleTimeout.
+func (l *SubjectLimiter) Cleanup() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.cleanup(time.Now())
+}
+
+// cleanup evicts idle buckets. Callers must hold mu.
+func (l *SubjectLimiter) cleanup(now time.Time) {
+	for subject, bucket := range l.buckets {
+		if now.Sub(bucket.last) > bucketIdleTimeout && bucket.full(now) {
+			delete(l.buckets, subject)
+		}
+	}
+	l.lastCleanup = now
+}
+
+// limitFor returns the limit of subject
+func (l *SubjectLimiter) limitFor(subject string) SubjectLimit {
+	if limit, ok := l.config.Subjects[subject]; ok {
+		return limit
+	}
+	return l.config.Default
+}
+
+// UnaryServerInterceptor returns a unary server interceptor that fails calls over their
+// subject's limit with ResourceExhausted
+func (l *SubjectLimiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := l.check(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a stream server interceptor that fails streams over their
+// subject's limit with ResourceExhausted. Each stream counts as one request.
+func (l *SubjectLimiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := l.check(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// check returns the error to fail a call to method with, or nil if it is allowed
+func (l *SubjectLimiter) check(ctx context.Context, method string) error {
+	if l.exempt[method] {
+		return nil
+	}
+
+	subject := subjectKey(ctx)
+	allowed, _ := l.Allow(ctx, subject)
+	if allowed {
+		return nil
+	}
+
+	l.logger.Warn("Rate limit exceeded",
+		logging.String("subject", subject),
+		logging.String("method", method))
+	return grpcerrors.NewRateLimitError("rate limit exceeded")
+}
+
+// subjectKey returns the authenticated subject of ctx, or the peer IP if there is none
+func subjectKey(ctx context.Context) string {
+	if userID, ok := grpcmiddleware.GetUserID(ctx); ok && userID != "" {
+		return userID
+	}
+	addr := grpcmiddleware.ExtractPeerInfo(ctx)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return "peer:" + addr
+}
+
+// tokenBucket holds up to burst tokens and refills at rate tokens per second
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit SubjectLimit, now time.Time) *tokenBucket {
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   limit.RequestsPerSecond,
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+}
+
+// take refills the bucket for the time since the last call and takes a token if one is left
+func (b *tokenBucket) take(now time.Time) bool {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// full reports whether the bucket would be full after refilling up to now
+func (b *tokenBucket) full(now time.Time) bool {
+	return b.tokens+now.Sub(b.last).Seconds()*b.rate >= b.burst
+}
@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	grpcmiddleware "backend-core/grpc/middleware"
+	"backend-core/logging"
+
+	"google.golang.org/grpc/peer"
+)
+
+func newTestSubjectLimiter(t *testing.T, limit SubjectLimit) *SubjectLimiter {
+	t.Helper()
+
+	logger := logging.NewNopLogger()
+	return NewSubjectLimiter(&SubjectLimitConfig{Default: limit}, logger)
+}
+
+func peerContext(addr string) context.Context {
+	tcpAddr, _ := net.ResolveTCPAddr("tcp", addr)
+	return peer.NewContext(context.Background(), &peer.Peer{Addr: tcpAddr})
+}
+
+func TestSubjectKeyUsesPeerIPWithoutSubject(t *testing.T) {
+	first := subjectKey(peerContext("10.0.0.1:5000"))
+	second := subjectKey(peerContext("10.0.0.1:5001"))
+
+	if first != "peer:10.0.0.1" || first != second {
+		t.Errorf("subjectKey() = %q and %q, want both %q", first, second, "peer:10.0.0.1")
+	}
+
+	ctx := grpcmiddleware.WithUserID(peerContext("10.0.0.1:5000"), "auth-service")
+	if got := subjectKey(ctx); got != "auth-service" {
+		t.Errorf("subjectKey() of an authenticated call = %q, want %q", got, "auth-service")
+	}
+}
+
+func TestReconnectingFromAnotherPortSharesBucket(t *testing.T) {
+	limiter := newTestSubjectLimiter(t, SubjectLimit{RequestsPerSecond: 0.001, Burst: 1})
+
+	if err := limiter.check(peerContext("10.0.0.1:5000"), "/svc/Method"); err != nil {
+		t.Fatalf("first call error = %v", err)
+	}
+	if err := limiter.check(peerContext("10.0.0.1:5001"), "/svc/Method"); err == nil {
+		t.Error("call from a new port of the same IP was allowed, want it rate limited")
+	}
+}
+
+func TestCleanupEvictsOnlyIdleRefilledBuckets(t *testing.T) {
+	limiter := newTestSubjectLimiter(t, SubjectLimit{RequestsPerSecond: 1, Burst: 2})
+	now := time.Now()
+
+	limiter.buckets["idle"] = &tokenBucket{rate: 1, burst: 2, tokens: 0, last: now.Add(-2 * bucketIdleTimeout)}
+	limiter.buckets["active"] = &tokenBucket{rate: 1, burst: 2, tokens: 0, last: now}
+	limiter.buckets["draining"] = &tokenBucket{rate: 0, burst: 2, tokens: 0, last: now.Add(-2 * bucketIdleTimeout)}
+
+	limiter.cleanup(now)
+
+	if _, ok := limiter.buckets["idle"]; ok {
+		t.Error("idle refilled bucket was kept")
+	}
+	if _, ok := limiter.buckets["active"]; !ok {
+		t.Error("recently used bucket was evicted")
+	}
+	if _, ok := limiter.buckets["draining"]; !ok {
+		t.Error("bucket that has not refilled was evicted")
+	}
+}
@@ -19,6 +19,20 @@ const (
 	StateHalfOpen                            // Testing if recovered
 )
 
+// String returns the state name
+func (s CircuitBreakerState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
 // CircuitBreaker implements the circuit breaker pattern
 type CircuitBreaker struct {
 	mu              sync.RWMutex
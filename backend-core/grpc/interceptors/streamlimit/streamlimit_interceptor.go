@@ -0,0 +1,131 @@
+package streamlimit
+
+import (
+	"context"
+	"sync/atomic"
+
+	"backend-core/logging"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// StreamLimiter enforces a server-wide cap on concurrently active RPC streams
+// and records the active stream gauge and rejection counter.
+//
+// Every RPC, unary or streaming, occupies an HTTP/2 stream, so both are tracked.
+type StreamLimiter struct {
+	maxStreams int64
+	active     atomic.Int64
+	rejected   atomic.Int64
+	logger     *logging.Logger
+
+	rejections metric.Int64Counter
+}
+
+// NewStreamLimiter creates a stream limiter for maxStreams concurrent streams.
+// A maxStreams of zero disables enforcement but still reports active streams.
+// When meter is nil the global OpenTelemetry meter provider is used.
+func NewStreamLimiter(maxStreams uint32, meter metric.Meter, logger *logging.Logger) (*StreamLimiter, error) {
+	if meter == nil {
+		meter = otel.Meter("backend-core/grpc")
+	}
+
+	l := &StreamLimiter{
+		maxStreams: int64(maxStreams),
+		logger:     logger,
+	}
+
+	_, err := meter.Int64ObservableGauge(
+		"grpc_active_streams",
+		metric.WithDescription("Number of active gRPC streams"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			o.Observe(l.ActiveStreams())
+			return nil
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	l.rejections, err = meter.Int64Counter(
+		"grpc_stream_rejections_total",
+		metric.WithDescription("Total number of gRPC streams rejected because the concurrent stream limit was reached"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// ActiveStreams returns the number of currently active streams
+func (l *StreamLimiter) ActiveStreams() int64 {
+	return l.active.Load()
+}
+
+// Rejections returns the number of streams rejected since the limiter was created
+func (l *StreamLimiter) Rejections() int64 {
+	return l.rejected.Load()
+}
+
+// MaxStreams returns the configured concurrent stream limit
+func (l *StreamLimiter) MaxStreams() int64 {
+	return l.maxStreams
+}
+
+// acquire reserves a stream slot, returning false when the limit is reached
+func (l *StreamLimiter) acquire(ctx context.Context, method string) bool {
+	current := l.active.Add(1)
+	if l.maxStreams <= 0 || current <= l.maxStreams {
+		return true
+	}
+
+	l.active.Add(-1)
+	l.rejected.Add(1)
+	l.rejections.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("method", method),
+	))
+
+	if l.logger != nil {
+		l.logger.Warn("gRPC stream rejected, concurrent stream limit reached",
+			"method", method,
+			"max_streams", l.maxStreams,
+		)
+	}
+
+	return false
+}
+
+// release frees a stream slot
+func (l *StreamLimiter) release() {
+	l.active.Add(-1)
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor enforcing the stream limit
+func (l *StreamLimiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !l.acquire(ctx, info.FullMethod) {
+			return nil, status.Error(codes.ResourceExhausted, "too many concurrent streams")
+		}
+		defer l.release()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor enforcing the stream limit
+func (l *StreamLimiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !l.acquire(ss.Context(), info.FullMethod) {
+			return status.Error(codes.ResourceExhausted, "too many concurrent streams")
+		}
+		defer l.release()
+
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,106 @@
+package streamlimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"backend-core/logging"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// testServerStream is a server stream that only carries a context
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// collect returns the value of the grpc_active_streams gauge and the grpc_stream_rejections_total counter
+func collect(t *testing.T, reader *sdkmetric.ManualReader) (active, rejections int64) {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch m.Name {
+			case "grpc_active_streams":
+				for _, dp := range m.Data.(metricdata.Gauge[int64]).DataPoints {
+					active += dp.Value
+				}
+			case "grpc_stream_rejections_total":
+				for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+					rejections += dp.Value
+				}
+			}
+		}
+	}
+	return active, rejections
+}
+
+func TestStreamLimiterTracksActiveStreamsAndRejections(t *testing.T) {
+	const streams = 4
+
+	reader := sdkmetric.NewManualReader()
+	limiter, err := NewStreamLimiter(streams-1, sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test"), logging.NewNopLogger())
+	if err != nil {
+		t.Fatalf("NewStreamLimiter() error = %v", err)
+	}
+	interceptor := limiter.StreamServerInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Watch"}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, streams)
+	open := func() {
+		defer wg.Done()
+		errs <- interceptor(nil, &testServerStream{ctx: context.Background()}, info, handler)
+	}
+	for i := 0; i < streams-1; i++ {
+		wg.Add(1)
+		go open()
+		<-started
+	}
+
+	// The limit is reached, so the last stream is rejected before its handler runs
+	wg.Add(1)
+	open()
+	if err := <-errs; status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("stream over the limit error = %v, want %s", err, codes.ResourceExhausted)
+	}
+
+	if active, rejections := collect(t, reader); active != streams-1 || rejections != 1 {
+		t.Errorf("grpc_active_streams = %d, grpc_stream_rejections_total = %d, want %d and 1", active, rejections, streams-1)
+	}
+
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("stream within the limit error = %v", err)
+		}
+	}
+
+	if active, rejections := collect(t, reader); active != 0 || rejections != 1 {
+		t.Errorf("after the handlers returned grpc_active_streams = %d, grpc_stream_rejections_total = %d, want 0 and 1", active, rejections)
+	}
+}
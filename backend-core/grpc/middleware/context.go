@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 )
@@ -112,3 +113,18 @@ func InjectMetadata(ctx context.Context, key, value string) context.Context {
 	md.Set(key, value)
 	return metadata.NewOutgoingContext(ctx, md)
 }
+
+// WrapServerStream returns ss with its context replaced by ctx, so values a stream
+// interceptor adds to the context reach the interceptors and handler after it
+func WrapServerStream(ss grpc.ServerStream, ctx context.Context) grpc.ServerStream {
+	return &wrappedServerStream{ServerStream: ss, ctx: ctx}
+}
+
+type wrappedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *wrappedServerStream) Context() context.Context {
+	return s.ctx
+}
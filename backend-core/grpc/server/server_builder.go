@@ -3,13 +3,18 @@ package server
 import (
 	"backend-core/cache"
 	"backend-core/grpc/interceptors/auth"
+	"backend-core/grpc/interceptors/correlation"
+	grpcinflight "backend-core/grpc/interceptors/inflight"
 	grpclogging "backend-core/grpc/interceptors/logging"
 	"backend-core/grpc/interceptors/metrics"
 	"backend-core/grpc/interceptors/ratelimit"
 	"backend-core/grpc/interceptors/recovery"
+	"backend-core/grpc/interceptors/streamlimit"
 	"backend-core/grpc/interceptors/tracing"
 	"backend-core/grpc/interceptors/validation"
+	"backend-core/inflight"
 	"backend-core/logging"
+	"backend-core/telemetry"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/keepalive"
@@ -86,6 +91,16 @@ func (b *ServerBuilder) WithRateLimit(cache cache.Cache, config *ratelimit.RateL
 	return b
 }
 
+// WithSubjectRateLimit adds a rate limiting interceptor with a token bucket per authenticated
+// subject. It must be added after WithAuth, which puts the subject in the context.
+func (b *ServerBuilder) WithSubjectRateLimit(limiter *ratelimit.SubjectLimiter) *ServerBuilder {
+	if limiter != nil {
+		b.unaryInterceptors = append(b.unaryInterceptors, limiter.UnaryServerInterceptor())
+		b.streamInterceptors = append(b.streamInterceptors, limiter.StreamServerInterceptor())
+	}
+	return b
+}
+
 // WithMetrics adds metrics interceptor
 func (b *ServerBuilder) WithMetrics(reporter metrics.MetricsReporter) *ServerBuilder {
 	if reporter != nil {
@@ -97,6 +112,41 @@ func (b *ServerBuilder) WithMetrics(reporter metrics.MetricsReporter) *ServerBui
 	return b
 }
 
+// WithBusinessMetrics adds an interceptor recording the count, duration and status code of
+// every call, and the calls that fail, with bm. Added after WithRecovery it still records
+// panicking handlers, as Internal.
+func (b *ServerBuilder) WithBusinessMetrics(bm *telemetry.BusinessMetrics) *ServerBuilder {
+	if bm != nil {
+		b.unaryInterceptors = append(b.unaryInterceptors,
+			metrics.BusinessMetricsUnaryServerInterceptor(bm))
+		b.streamInterceptors = append(b.streamInterceptors,
+			metrics.BusinessMetricsStreamServerInterceptor(bm))
+	}
+	return b
+}
+
+// WithStreamLimit adds concurrent stream limiting interceptor
+func (b *ServerBuilder) WithStreamLimit(limiter *streamlimit.StreamLimiter) *ServerBuilder {
+	if limiter != nil {
+		b.unaryInterceptors = append(b.unaryInterceptors,
+			limiter.UnaryServerInterceptor())
+		b.streamInterceptors = append(b.streamInterceptors,
+			limiter.StreamServerInterceptor())
+	}
+	return b
+}
+
+// WithInFlight adds the interceptor counting in-flight calls in tracker
+func (b *ServerBuilder) WithInFlight(tracker *inflight.Tracker) *ServerBuilder {
+	if tracker != nil {
+		b.unaryInterceptors = append(b.unaryInterceptors,
+			grpcinflight.UnaryServerInterceptor(tracker))
+		b.streamInterceptors = append(b.streamInterceptors,
+			grpcinflight.StreamServerInterceptor(tracker))
+	}
+	return b
+}
+
 // WithTracing adds tracing interceptor
 func (b *ServerBuilder) WithTracing() *ServerBuilder {
 	b.unaryInterceptors = append(b.unaryInterceptors,
@@ -106,6 +156,16 @@ func (b *ServerBuilder) WithTracing() *ServerBuilder {
 	return b
 }
 
+// WithCorrelation adds the request and correlation IDs of the incoming metadata to the
+// context, so they reach handlers and outgoing calls
+func (b *ServerBuilder) WithCorrelation() *ServerBuilder {
+	b.unaryInterceptors = append(b.unaryInterceptors,
+		correlation.UnaryServerInterceptor())
+	b.streamInterceptors = append(b.streamInterceptors,
+		correlation.StreamServerInterceptor())
+	return b
+}
+
 // WithUnaryInterceptor adds a custom unary interceptor
 func (b *ServerBuilder) WithUnaryInterceptor(interceptor grpc.UnaryServerInterceptor) *ServerBuilder {
 	b.unaryInterceptors = append(b.unaryInterceptors, interceptor)
@@ -177,6 +237,7 @@ func BuildWithDefaults(config *ServerConfig, logger *logging.Logger, authConfig
 			LogPayloadOnError: true,
 		}).
 		WithTracing().
+		WithCorrelation().
 		WithValidation()
 
 	if authConfig != nil && authConfig.Enabled {
@@ -0,0 +1,80 @@
+// Package inflight counts requests that are being served so shutdown can wait for
+// them to finish instead of sleeping for a fixed time.
+package inflight
+
+import (
+	"context"
+	"sync"
+)
+
+// Tracker counts in-flight requests. One tracker is usually shared by every server of a
+// process, through the HTTP middleware and the gRPC interceptors, so a single
+// WaitForInFlight covers all of them. The zero value is not usable; use NewTracker.
+type Tracker struct {
+	mu    sync.Mutex
+	count int64
+	// idle is closed whenever count is zero and replaced when a request starts
+	idle chan struct{}
+}
+
+// NewTracker creates a tracker with no requests in flight
+func NewTracker() *Tracker {
+	idle := make(chan struct{})
+	close(idle)
+	return &Tracker{idle: idle}
+}
+
+// Track records the start of a request and returns the function that records its end.
+// The returned function must be called exactly once; extra calls have no effect.
+func (t *Tracker) Track() func() {
+	t.mu.Lock()
+	if t.count == 0 {
+		t.idle = make(chan struct{})
+	}
+	t.count++
+	t.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(t.done)
+	}
+}
+
+// done records the end of a request
+func (t *Tracker) done() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.count--
+	if t.count == 0 {
+		close(t.idle)
+	}
+}
+
+// Count returns the number of requests in flight
+func (t *Tracker) Count() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.count
+}
+
+// WaitForInFlight blocks until no requests are in flight or ctx is done, returning
+// ctx.Err() in the latter case. Stop accepting new requests first, e.g. with
+// http.Server.Shutdown, or new requests may keep it waiting.
+func (t *Tracker) WaitForInFlight(ctx context.Context) error {
+	for {
+		t.mu.Lock()
+		idle := t.idle
+		t.mu.Unlock()
+
+		select {
+		case <-idle:
+			// A request may have started between the count reaching zero and now
+			if t.Count() == 0 {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
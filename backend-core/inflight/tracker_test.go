@@ -0,0 +1,59 @@
+package inflight
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitForInFlightWaitsForRequests(t *testing.T) {
+	tracker := NewTracker()
+	first, second := tracker.Track(), tracker.Track()
+
+	waited := make(chan error, 1)
+	go func() {
+		waited <- tracker.WaitForInFlight(context.Background())
+	}()
+
+	first()
+	first()
+	select {
+	case err := <-waited:
+		t.Fatalf("WaitForInFlight() returned %v with a request in flight", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	second()
+	select {
+	case err := <-waited:
+		if err != nil {
+			t.Errorf("WaitForInFlight() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("WaitForInFlight() did not return after the last request finished")
+	}
+	if tracker.Count() != 0 {
+		t.Errorf("Count() = %d, want 0", tracker.Count())
+	}
+}
+
+func TestWaitForInFlightStopsAtDeadline(t *testing.T) {
+	tracker := NewTracker()
+	defer tracker.Track()()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := tracker.WaitForInFlight(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitForInFlight() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestWaitForInFlightWhenIdle(t *testing.T) {
+	tracker := NewTracker()
+	tracker.Track()()
+
+	if err := tracker.WaitForInFlight(context.Background()); err != nil {
+		t.Errorf("WaitForInFlight() error = %v", err)
+	}
+}
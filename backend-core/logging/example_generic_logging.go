@@ -2,6 +2,7 @@ package logging
 
 import (
 	"backend-core/config"
+	"backend-core/ctxkeys"
 	"context"
 	"fmt"
 	"time"
@@ -40,7 +41,7 @@ func ExampleGenericLogging() {
 	logger.Info("user created", "user", user, "source", "api", "version", "v1.2.3")
 
 	// Example 5: With context
-	ctx := context.WithValue(context.Background(), "request_id", "req-456")
+	ctx := ctxkeys.WithRequestID(context.Background(), "req-456")
 	_ = ctx // Use context in real application
 	logger.Debug("request processing", "step", "validation", "duration", 150*time.Millisecond)
 }
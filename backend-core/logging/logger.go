@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"backend-core/config"
+	"backend-core/ctxkeys"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -125,28 +126,38 @@ func NewLoggerFromZapConfig(cfg *config.Zap) (*Logger, error) {
 	return NewZapLogger(cfg)
 }
 
+// NewNopLogger creates a logger that discards every entry
+func NewNopLogger() *Logger {
+	return &Logger{level: zapcore.DebugLevel, Core: zapcore.NewNopCore()}
+}
+
 // WithContext adds context fields to the logger and returns a zap.Logger
 func (l *Logger) WithContext(ctx context.Context) *zap.Logger {
 	fields := []zap.Field{}
 
 	// Add correlation ID if present
-	if correlationID := ctx.Value("correlation_id"); correlationID != nil {
-		fields = append(fields, zap.Any("correlation_id", correlationID))
+	if correlationID := ctxkeys.CorrelationIDFrom(ctx); correlationID != "" {
+		fields = append(fields, zap.String("correlation_id", correlationID))
 	}
 
 	// Add user ID if present
-	if userID := ctx.Value("user_id"); userID != nil {
-		fields = append(fields, zap.Any("user_id", userID))
+	if userID := ctxkeys.UserIDFrom(ctx); userID != "" {
+		fields = append(fields, zap.String("user_id", userID))
 	}
 
 	// Add request ID if present
-	if requestID := ctx.Value("request_id"); requestID != nil {
-		fields = append(fields, zap.Any("request_id", requestID))
+	if requestID := ctxkeys.RequestIDFrom(ctx); requestID != "" {
+		fields = append(fields, zap.String("request_id", requestID))
+	}
+
+	// Add trace ID if present
+	if traceID, _ := ctx.Value(ctxkeys.TraceID).(string); traceID != "" {
+		fields = append(fields, zap.String("trace_id", traceID))
 	}
 
 	// Add service name if present
-	if serviceName := ctx.Value("service_name"); serviceName != nil {
-		fields = append(fields, zap.Any("service_name", serviceName))
+	if serviceName := ctxkeys.ServiceNameFrom(ctx); serviceName != "" {
+		fields = append(fields, zap.String("service_name", serviceName))
 	}
 
 	// Create a new zap.Logger with the core and fields
@@ -2,6 +2,7 @@ package consumer
 
 import (
 	"context"
+	"strings"
 	"time"
 )
 
@@ -27,6 +28,34 @@ type ConsumerMessage struct {
 	Timestamp time.Time
 }
 
+// Header returns the value of the header key, matched case-insensitively as producers
+// differ in how they case header names. An exact match is preferred.
+func (m *ConsumerMessage) Header(key string) (string, bool) {
+	if value, ok := m.Headers[key]; ok {
+		return value, true
+	}
+	for name, value := range m.Headers {
+		if strings.EqualFold(name, key) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// HeadersLower returns a copy of the headers with lower-cased names, for case-insensitive
+// lookups of several headers. When names differ only in case, the already lower-cased one wins.
+func (m *ConsumerMessage) HeadersLower() map[string]string {
+	headers := make(map[string]string, len(m.Headers))
+	for name, value := range m.Headers {
+		lower := strings.ToLower(name)
+		if _, exists := headers[lower]; exists && name != lower {
+			continue
+		}
+		headers[lower] = value
+	}
+	return headers
+}
+
 // Consumer defines the interface for Kafka consumers
 type Consumer interface {
 	// Subscribe subscribes to topics
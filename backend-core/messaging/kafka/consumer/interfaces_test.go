@@ -0,0 +1,76 @@
+package consumer
+
+import "testing"
+
+func TestHeaderIsCaseInsensitive(t *testing.T) {
+	message := &ConsumerMessage{Headers: map[string]string{
+		"X-Request-ID": "req-1",
+		"content-type": "application/json",
+	}}
+
+	tests := []struct {
+		key   string
+		value string
+		found bool
+	}{
+		{key: "X-Request-ID", value: "req-1", found: true},
+		{key: "x-request-id", value: "req-1", found: true},
+		{key: "X-REQUEST-ID", value: "req-1", found: true},
+		{key: "Content-Type", value: "application/json", found: true},
+		{key: "X-Correlation-ID", found: false},
+	}
+
+	for _, tt := range tests {
+		value, found := message.Header(tt.key)
+		if value != tt.value || found != tt.found {
+			t.Errorf("Header(%q) = %q, %v, want %q, %v", tt.key, value, found, tt.value, tt.found)
+		}
+	}
+}
+
+func TestHeaderPrefersExactMatch(t *testing.T) {
+	message := &ConsumerMessage{Headers: map[string]string{
+		"X-Request-ID": "upper",
+		"x-request-id": "lower",
+	}}
+
+	if value, _ := message.Header("X-Request-ID"); value != "upper" {
+		t.Errorf("Header(X-Request-ID) = %q, want upper", value)
+	}
+	if value, _ := message.Header("x-request-id"); value != "lower" {
+		t.Errorf("Header(x-request-id) = %q, want lower", value)
+	}
+}
+
+func TestHeaderWithoutHeaders(t *testing.T) {
+	message := &ConsumerMessage{}
+
+	if value, found := message.Header("X-Request-ID"); found || value != "" {
+		t.Errorf("Header() = %q, %v, want no header", value, found)
+	}
+	if headers := message.HeadersLower(); len(headers) != 0 {
+		t.Errorf("HeadersLower() = %v, want empty", headers)
+	}
+}
+
+func TestHeadersLower(t *testing.T) {
+	message := &ConsumerMessage{Headers: map[string]string{
+		"X-Request-ID":     "upper",
+		"x-request-id":     "lower",
+		"X-Correlation-ID": "corr-1",
+	}}
+
+	headers := message.HeadersLower()
+	if len(headers) != 2 {
+		t.Fatalf("HeadersLower() = %v, want 2 headers", headers)
+	}
+	if headers["x-request-id"] != "lower" {
+		t.Errorf("x-request-id = %q, want the already lower-cased header", headers["x-request-id"])
+	}
+	if headers["x-correlation-id"] != "corr-1" {
+		t.Errorf("x-correlation-id = %q, want corr-1", headers["x-correlation-id"])
+	}
+	if _, ok := message.Headers["x-correlation-id"]; ok {
+		t.Error("HeadersLower must not modify the message headers")
+	}
+}
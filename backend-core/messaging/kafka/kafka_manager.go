@@ -2,6 +2,7 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -101,7 +102,8 @@ func (m *KafkaManager) CreateProducer(producerConfig *producer.Config) (producer
 		},
 	}
 
-	producer, err := producer.NewKafkaProducer(cfg, m.logger)
+	producer, err := producer.NewKafkaProducer(cfg, m.logger,
+		producer.WithDeliveryHandler(producerConfig.OnDelivery))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create producer: %w", err)
 	}
@@ -362,7 +364,8 @@ func (m *KafkaManager) GetTopicMetadata(topic string) (*management.TopicMetadata
 	return metadataResult, nil
 }
 
-// Close closes the manager and all its resources
+// Close closes the manager and all its resources. Every producer, consumer and consumer
+// group is closed even if some fail; the returned error joins all the failures.
 func (m *KafkaManager) Close() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -394,13 +397,14 @@ func (m *KafkaManager) Close() error {
 		}
 	}
 
-	// Close admin client
+	// Close admin client, whatever failed above
 	m.adminClient.Close()
 
 	m.closed = true
 
-	if len(errs) > 0 {
-		return fmt.Errorf("errors closing manager: %v", errs)
+	// Joined so callers can match each failure with errors.Is and errors.As
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("errors closing manager: %w", err)
 	}
 
 	m.logger.Info("Kafka manager closed successfully")
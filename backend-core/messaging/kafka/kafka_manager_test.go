@@ -0,0 +1,65 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+
+	"backend-core/logging"
+	"backend-core/messaging/kafka/consumer"
+	"backend-core/messaging/kafka/producer"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// failingProducer is a producer whose Close fails with err
+type failingProducer struct {
+	producer.Producer
+	err error
+}
+
+func (p *failingProducer) Close() error { return p.err }
+
+// failingConsumer is a consumer whose Close fails with err
+type failingConsumer struct {
+	consumer.Consumer
+	err error
+}
+
+func (c *failingConsumer) Close() error { return c.err }
+
+func TestCloseJoinsEveryFailure(t *testing.T) {
+	logger := logging.NewNopLogger()
+	// The admin client connects lazily, so no broker is needed
+	adminClient, err := kafka.NewAdminClient(&kafka.ConfigMap{"bootstrap.servers": "127.0.0.1:1"})
+	if err != nil {
+		t.Fatalf("NewAdminClient: %v", err)
+	}
+
+	errProducerClose := errors.New("producer close failed")
+	errConsumerClose := errors.New("consumer close failed")
+	manager := &KafkaManager{
+		logger:         logger,
+		adminClient:    adminClient,
+		producers:      map[string]producer.Producer{"orders": &failingProducer{err: errProducerClose}},
+		consumers:      map[string]consumer.Consumer{"payments": &failingConsumer{err: errConsumerClose}},
+		consumerGroups: map[string]consumer.ConsumerGroup{},
+	}
+
+	err = manager.Close()
+	if err == nil {
+		t.Fatal("expected Close to fail")
+	}
+	if !errors.Is(err, errProducerClose) {
+		t.Errorf("expected the producer failure in %v", err)
+	}
+	if !errors.Is(err, errConsumerClose) {
+		t.Errorf("expected the consumer failure in %v", err)
+	}
+	if !adminClient.IsClosed() {
+		t.Error("expected the admin client to be closed despite the failures")
+	}
+
+	if err := manager.Close(); err != nil {
+		t.Errorf("expected a second Close to be a no-op, got %v", err)
+	}
+}
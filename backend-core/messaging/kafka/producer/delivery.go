@@ -0,0 +1,136 @@
+package producer
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+// DeliveryReport is the outcome of delivering one message
+type DeliveryReport struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	// Err is nil when the message was written, otherwise why it was not. A message is
+	// only reported as failed once the producer has given up retrying it.
+	Err error
+}
+
+// DeliveryReportHandler is called once for every message produced, from Send and
+// SendBatch on the caller's goroutine and from a background goroutine for SendAsync.
+// It must not block.
+type DeliveryReportHandler func(report DeliveryReport)
+
+// ProducerOption configures a KafkaProducer
+type ProducerOption func(*KafkaProducer)
+
+// WithDeliveryHandler sets the handler called with the delivery report of every message
+func WithDeliveryHandler(handler DeliveryReportHandler) ProducerOption {
+	return func(p *KafkaProducer) {
+		p.onDelivery = handler
+	}
+}
+
+// WithMeter sets the meter the delivery failure counter is recorded with.
+// By default the global OpenTelemetry meter provider is used.
+func WithMeter(meter metric.Meter) ProducerOption {
+	return func(p *KafkaProducer) {
+		p.meter = meter
+	}
+}
+
+// deliveryMetrics counts failed deliveries
+type deliveryMetrics struct {
+	failures      metric.Int64Counter
+	failuresCount atomic.Int64
+}
+
+func newDeliveryMetrics(meter metric.Meter) (*deliveryMetrics, error) {
+	if meter == nil {
+		meter = otel.Meter("backend-core/messaging/kafka")
+	}
+
+	failures, err := meter.Int64Counter(
+		"kafka_delivery_failures_total",
+		metric.WithDescription("Total number of Kafka messages the producer failed to deliver"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &deliveryMetrics{failures: failures}, nil
+}
+
+// DeliveryFailures returns how many messages failed to be delivered since the producer was created
+func (p *KafkaProducer) DeliveryFailures() int64 {
+	return p.metrics.failuresCount.Load()
+}
+
+// handleDeliveryEvent processes one event from a delivery channel, returning the delivery
+// error for a failed message or the producer error
+func (p *KafkaProducer) handleDeliveryEvent(e kafka.Event) error {
+	switch ev := e.(type) {
+	case *kafka.Message:
+		return p.report(ev)
+	case kafka.Error:
+		p.logger.Error("Producer error", zap.Error(ev))
+		return fmt.Errorf("producer error: %w", ev)
+	}
+	return nil
+}
+
+// report records the delivery outcome of msg and passes it to the delivery handler
+func (p *KafkaProducer) report(msg *kafka.Message) error {
+	report := DeliveryReport{
+		Partition: msg.TopicPartition.Partition,
+		Offset:    int64(msg.TopicPartition.Offset),
+		Err:       msg.TopicPartition.Error,
+	}
+	if msg.TopicPartition.Topic != nil {
+		report.Topic = *msg.TopicPartition.Topic
+	}
+
+	if report.Err != nil {
+		p.metrics.failuresCount.Add(1)
+		p.metrics.failures.Add(context.Background(), 1,
+			metric.WithAttributes(attribute.String("topic", report.Topic)))
+		p.logger.Error("Message delivery failed",
+			zap.String("topic", report.Topic),
+			zap.Int32("partition", report.Partition),
+			zap.Error(report.Err),
+		)
+	} else {
+		p.logger.Debug("Message delivered successfully",
+			zap.String("topic", report.Topic),
+			zap.Int32("partition", report.Partition),
+			zap.Int64("offset", report.Offset),
+		)
+	}
+
+	if p.onDelivery != nil {
+		p.onDelivery(report)
+	}
+
+	if report.Err != nil {
+		return fmt.Errorf("message delivery failed: %w", report.Err)
+	}
+	return nil
+}
+
+// awaitDeliveries reports the next n events from deliveryChan in the background, for
+// messages whose caller stopped waiting before they were delivered
+func (p *KafkaProducer) awaitDeliveries(deliveryChan chan kafka.Event, n int) {
+	if n <= 0 {
+		return
+	}
+	go func() {
+		for i := 0; i < n; i++ {
+			p.handleDeliveryEvent(<-deliveryChan)
+		}
+	}()
+}
@@ -0,0 +1,76 @@
+package producer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend-core/logging"
+	"backend-core/messaging/kafka/config"
+)
+
+// newUnreachableProducer creates a producer for a broker that is not listening, so every
+// message fails once the delivery timeout passes
+func newUnreachableProducer(t *testing.T, opts ...ProducerOption) *KafkaProducer {
+	t.Helper()
+
+	logger := logging.NewNopLogger()
+	cfg := config.DefaultKafkaConfig()
+	cfg.BootstrapServers = []string{"127.0.0.1:1"}
+	cfg.Producer.DeliveryTimeout = 200 * time.Millisecond
+
+	p, err := NewKafkaProducer(cfg, logger, opts...)
+	if err != nil {
+		t.Fatalf("NewKafkaProducer: %v", err)
+	}
+	t.Cleanup(func() { p.Close() })
+	return p.(*KafkaProducer)
+}
+
+func TestDeliveryHandlerReportsFailedSend(t *testing.T) {
+	reports := make(chan DeliveryReport, 1)
+	p := newUnreachableProducer(t, WithDeliveryHandler(func(report DeliveryReport) {
+		reports <- report
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := p.Send(ctx, &ProducerMessage{Topic: "orders", Value: []byte("order")}); err == nil {
+		t.Fatal("expected Send to fail when the message cannot be delivered")
+	}
+
+	select {
+	case report := <-reports:
+		if report.Topic != "orders" || report.Err == nil {
+			t.Errorf("expected a failed report for orders, got %+v", report)
+		}
+	default:
+		t.Fatal("expected the delivery handler to be called before Send returned")
+	}
+	if failures := p.DeliveryFailures(); failures != 1 {
+		t.Errorf("expected 1 delivery failure, got %d", failures)
+	}
+}
+
+func TestDeliveryHandlerReportsFailedSendAsync(t *testing.T) {
+	reports := make(chan DeliveryReport, 1)
+	p := newUnreachableProducer(t, WithDeliveryHandler(func(report DeliveryReport) {
+		reports <- report
+	}))
+
+	if err := p.SendAsync(context.Background(), &ProducerMessage{Topic: "orders", Value: []byte("order")}); err != nil {
+		t.Fatalf("SendAsync: %v", err)
+	}
+
+	select {
+	case report := <-reports:
+		if report.Topic != "orders" || report.Err == nil {
+			t.Errorf("expected a failed report for orders, got %+v", report)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected the delivery handler to be called for the async message")
+	}
+	if failures := p.DeliveryFailures(); failures != 1 {
+		t.Errorf("expected 1 delivery failure, got %d", failures)
+	}
+}
@@ -52,6 +52,9 @@ type Config struct {
 	LingerMs         int
 	CompressionType  string
 	SecurityConfig   *SecurityConfig
+	// OnDelivery, when set, is called with the delivery report of every message, e.g. to
+	// retry failed messages from an outbox
+	OnDelivery DeliveryReportHandler
 }
 
 // SecurityConfig contains security configuration
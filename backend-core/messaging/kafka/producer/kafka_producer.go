@@ -10,6 +10,7 @@ import (
 	"backend-core/messaging/kafka/config"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
 )
 
@@ -20,11 +21,17 @@ type KafkaProducer struct {
 	logger   *logging.Logger
 	mu       sync.RWMutex
 	closed   bool
+	// delivery receives the delivery reports of SendAsync messages
 	delivery chan kafka.Event
+
+	onDelivery DeliveryReportHandler
+	meter      metric.Meter
+	metrics    *deliveryMetrics
 }
 
-// NewKafkaProducer creates a new Kafka producer
-func NewKafkaProducer(cfg *config.KafkaConfig, logger *logging.Logger) (Producer, error) {
+// NewKafkaProducer creates a new Kafka producer. Messages the producer cannot deliver within
+// the configured DeliveryTimeout are reported as failed.
+func NewKafkaProducer(cfg *config.KafkaConfig, logger *logging.Logger, opts ...ProducerOption) (Producer, error) {
 	if cfg.Producer == nil {
 		return nil, fmt.Errorf("producer configuration is required")
 	}
@@ -71,6 +78,11 @@ func NewKafkaProducer(cfg *config.KafkaConfig, logger *logging.Logger) (Producer
 	configMap["batch.size"] = cfg.Producer.BatchSize
 	configMap["message.max.bytes"] = cfg.Producer.MaxRequestSize
 
+	// Set delivery timeout, after which a message is reported as failed
+	if cfg.Producer.DeliveryTimeout > 0 {
+		configMap["delivery.timeout.ms"] = int(cfg.Producer.DeliveryTimeout.Milliseconds())
+	}
+
 	// Set idempotence
 	if cfg.Producer.EnableIdempotent {
 		configMap["enable.idempotence"] = true
@@ -93,9 +105,19 @@ func NewKafkaProducer(cfg *config.KafkaConfig, logger *logging.Logger) (Producer
 		logger:   logger,
 		delivery: make(chan kafka.Event, 100),
 	}
+	for _, opt := range opts {
+		opt(kafkaProducer)
+	}
+
+	kafkaProducer.metrics, err = newDeliveryMetrics(kafkaProducer.meter)
+	if err != nil {
+		producer.Close()
+		return nil, fmt.Errorf("failed to create delivery metrics: %w", err)
+	}
 
 	// Start delivery report handler
 	go kafkaProducer.handleDeliveryReports()
+	go kafkaProducer.handleProducerEvents()
 
 	return kafkaProducer, nil
 }
@@ -131,8 +153,9 @@ func (p *KafkaProducer) Send(ctx context.Context, message *ProducerMessage) erro
 		kafkaMessage.Headers = headers
 	}
 
-	// Send message
-	err := p.producer.Produce(kafkaMessage, p.delivery)
+	// Send message; its delivery report comes back on its own channel
+	deliveryChan := make(chan kafka.Event, 1)
+	err := p.producer.Produce(kafkaMessage, deliveryChan)
 	if err != nil {
 		p.logger.Error("Failed to produce message",
 			zap.String("topic", message.Topic),
@@ -143,32 +166,12 @@ func (p *KafkaProducer) Send(ctx context.Context, message *ProducerMessage) erro
 
 	// Wait for delivery report (synchronous behavior)
 	select {
-	case e := <-p.delivery:
-		switch ev := e.(type) {
-		case *kafka.Message:
-			if ev.TopicPartition.Error != nil {
-				p.logger.Error("Message delivery failed",
-					zap.String("topic", *ev.TopicPartition.Topic),
-					zap.Int32("partition", ev.TopicPartition.Partition),
-					zap.Int64("offset", int64(ev.TopicPartition.Offset)),
-					zap.Error(ev.TopicPartition.Error),
-				)
-				return fmt.Errorf("message delivery failed: %w", ev.TopicPartition.Error)
-			}
-			p.logger.Debug("Message sent successfully",
-				zap.String("topic", *ev.TopicPartition.Topic),
-				zap.Int32("partition", ev.TopicPartition.Partition),
-				zap.Int64("offset", int64(ev.TopicPartition.Offset)),
-			)
-		case kafka.Error:
-			p.logger.Error("Producer error", zap.Error(ev))
-			return fmt.Errorf("producer error: %w", ev)
-		}
+	case e := <-deliveryChan:
+		return p.handleDeliveryEvent(e)
 	case <-ctx.Done():
+		p.awaitDeliveries(deliveryChan, 1)
 		return ctx.Err()
 	}
-
-	return nil
 }
 
 // SendBatch sends multiple messages to Kafka
@@ -184,8 +187,9 @@ func (p *KafkaProducer) SendBatch(ctx context.Context, messages []*ProducerMessa
 		return nil
 	}
 
-	// Send all messages
-	for _, message := range messages {
+	// Send all messages; their delivery reports come back on the batch's own channel
+	deliveryChan := make(chan kafka.Event, len(messages))
+	for i, message := range messages {
 		kafkaMessage := &kafka.Message{
 			TopicPartition: kafka.TopicPartition{
 				Topic:     &message.Topic,
@@ -207,35 +211,26 @@ func (p *KafkaProducer) SendBatch(ctx context.Context, messages []*ProducerMessa
 			kafkaMessage.Headers = headers
 		}
 
-		if err := p.producer.Produce(kafkaMessage, p.delivery); err != nil {
+		if err := p.producer.Produce(kafkaMessage, deliveryChan); err != nil {
 			p.logger.Error("Failed to produce batch message",
 				zap.String("topic", message.Topic),
 				zap.Error(err),
 			)
+			p.awaitDeliveries(deliveryChan, i)
 			return fmt.Errorf("failed to produce batch message: %w", err)
 		}
 	}
 
 	// Wait for all messages to be delivered
-	delivered := 0
-	for delivered < len(messages) {
+	for delivered := 0; delivered < len(messages); delivered++ {
 		select {
-		case e := <-p.delivery:
-			switch ev := e.(type) {
-			case *kafka.Message:
-				if ev.TopicPartition.Error != nil {
-					p.logger.Error("Batch message delivery failed",
-						zap.String("topic", *ev.TopicPartition.Topic),
-						zap.Error(ev.TopicPartition.Error),
-					)
-					return fmt.Errorf("batch message delivery failed: %w", ev.TopicPartition.Error)
-				}
-				delivered++
-			case kafka.Error:
-				p.logger.Error("Producer error during batch", zap.Error(ev))
-				return fmt.Errorf("producer error: %w", ev)
+		case e := <-deliveryChan:
+			if err := p.handleDeliveryEvent(e); err != nil {
+				p.awaitDeliveries(deliveryChan, len(messages)-delivered-1)
+				return fmt.Errorf("batch delivery failed: %w", err)
 			}
 		case <-ctx.Done():
+			p.awaitDeliveries(deliveryChan, len(messages)-delivered)
 			return ctx.Err()
 		}
 	}
@@ -277,8 +272,8 @@ func (p *KafkaProducer) SendAsync(ctx context.Context, message *ProducerMessage)
 		kafkaMessage.Headers = headers
 	}
 
-	// Use nil delivery channel for truly async
-	err := p.producer.Produce(kafkaMessage, nil)
+	// Delivery reports are handled in the background by handleDeliveryReports
+	err := p.producer.Produce(kafkaMessage, p.delivery)
 	if err != nil {
 		p.logger.Error("Failed to produce async message",
 			zap.String("topic", message.Topic),
@@ -340,32 +335,18 @@ func (p *KafkaProducer) Close() error {
 	return nil
 }
 
-// handleDeliveryReports handles delivery reports from the producer
+// handleDeliveryReports handles the delivery reports of SendAsync messages
 func (p *KafkaProducer) handleDeliveryReports() {
-	for {
-		select {
-		case e, ok := <-p.delivery:
-			if !ok {
-				return
-			}
-			switch ev := e.(type) {
-			case *kafka.Message:
-				if ev.TopicPartition.Error != nil {
-					p.logger.Error("Message delivery failed",
-						zap.String("topic", *ev.TopicPartition.Topic),
-						zap.Error(ev.TopicPartition.Error),
-					)
-				} else {
-					p.logger.Debug("Message delivered successfully",
-						zap.String("topic", *ev.TopicPartition.Topic),
-						zap.Int32("partition", ev.TopicPartition.Partition),
-						zap.Int64("offset", int64(ev.TopicPartition.Offset)),
-					)
-				}
-			case kafka.Error:
-				p.logger.Error("Producer error", zap.Error(ev))
-			}
-		}
+	for e := range p.delivery {
+		p.handleDeliveryEvent(e)
+	}
+}
+
+// handleProducerEvents handles the producer's own events, such as errors connecting to the
+// brokers. Left unread they would also keep Flush waiting until its timeout.
+func (p *KafkaProducer) handleProducerEvents() {
+	for e := range p.producer.Events() {
+		p.handleDeliveryEvent(e)
 	}
 }
 
@@ -2,6 +2,7 @@ package retry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
@@ -266,8 +267,35 @@ func calculateBackoff(attempt int, config *ConsumerRetryConfig) time.Duration {
 	return time.Duration(backoff)
 }
 
+// nonRetryableError marks an error that retrying cannot fix
+type nonRetryableError struct {
+	err error
+}
+
+func (e *nonRetryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *nonRetryableError) Unwrap() error {
+	return e.err
+}
+
+// NonRetryable marks err as one that retrying cannot fix, e.g. a payload that cannot be
+// decoded, so the message is sent straight to the DLQ
+func NonRetryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &nonRetryableError{err: err}
+}
+
 // IsRetryable determines if an error is retryable
 func IsRetryable(err error) bool {
+	var marked *nonRetryableError
+	if errors.As(err, &marked) {
+		return false
+	}
+
 	// List of non-retryable errors
 	nonRetryable := []string{
 		"invalid",
@@ -6,6 +6,7 @@ import (
 	"runtime/debug"
 	"time"
 
+	"backend-core/ctxkeys"
 	"backend-core/logging"
 	"backend-shared/errors"
 
@@ -63,7 +64,7 @@ func (em *ErrorMiddleware) handlePanic(c *gin.Context, recovered interface{}) {
 		Error:   "Internal Server Error",
 		Message: "An unexpected error occurred",
 		Code:    "INTERNAL_ERROR",
-		TraceID: c.GetString("trace_id"),
+		TraceID: ctxkeys.GetTraceID(c),
 	}
 
 	c.JSON(http.StatusInternalServerError, response)
@@ -80,7 +81,7 @@ func (em *ErrorMiddleware) handleErrors(c *gin.Context) {
 
 	// Log the error
 	em.logger.Error("Service error: %v, path: %s, method: %s, trace_id: %s",
-		err.Err, c.Request.URL.Path, c.Request.Method, c.GetString("trace_id"))
+		err.Err, c.Request.URL.Path, c.Request.Method, ctxkeys.GetTraceID(c))
 
 	// Determine error type and create appropriate response
 	response := em.createErrorResponse(err.Err, c)
@@ -93,7 +94,7 @@ func (em *ErrorMiddleware) handleErrors(c *gin.Context) {
 // createErrorResponse creates an error response based on the error type
 func (em *ErrorMiddleware) createErrorResponse(err error, c *gin.Context) ErrorResponse {
 	response := ErrorResponse{
-		TraceID: c.GetString("trace_id"),
+		TraceID: ctxkeys.GetTraceID(c),
 	}
 
 	// Check for specific error types
@@ -169,19 +170,19 @@ func (em *ErrorMiddleware) CustomErrorHandler(handler ErrorHandlerFunc) gin.Hand
 // LogError logs an error with context
 func (em *ErrorMiddleware) LogError(c *gin.Context, err error, message string) {
 	em.logger.Error("%s: %v, path: %s, method: %s, trace_id: %s, user_id: %s",
-		message, err, c.Request.URL.Path, c.Request.Method, c.GetString("trace_id"), c.GetString("user_id"))
+		message, err, c.Request.URL.Path, c.Request.Method, ctxkeys.GetTraceID(c), c.GetString(ctxkeys.UserID))
 }
 
 // LogWarning logs a warning with context
 func (em *ErrorMiddleware) LogWarning(c *gin.Context, message string, fields ...logging.Field) {
 	em.logger.Warn("%s, path: %s, method: %s, trace_id: %s, user_id: %s",
-		message, c.Request.URL.Path, c.Request.Method, c.GetString("trace_id"), c.GetString("user_id"))
+		message, c.Request.URL.Path, c.Request.Method, ctxkeys.GetTraceID(c), c.GetString(ctxkeys.UserID))
 }
 
 // LogInfo logs an info message with context
 func (em *ErrorMiddleware) LogInfo(c *gin.Context, message string, fields ...logging.Field) {
 	em.logger.Info("%s, path: %s, method: %s, trace_id: %s, user_id: %s",
-		message, c.Request.URL.Path, c.Request.Method, c.GetString("trace_id"), c.GetString("user_id"))
+		message, c.Request.URL.Path, c.Request.Method, ctxkeys.GetTraceID(c), c.GetString(ctxkeys.UserID))
 }
 
 // JSONError sends a JSON error response
@@ -197,7 +198,7 @@ func (em *ErrorMiddleware) ValidationError(c *gin.Context, message string, detai
 		Message: message,
 		Code:    "VALIDATION_ERROR",
 		Details: details,
-		TraceID: c.GetString("trace_id"),
+		TraceID: ctxkeys.GetTraceID(c),
 	}
 	c.JSON(http.StatusBadRequest, response)
 }
@@ -208,7 +209,7 @@ func (em *ErrorMiddleware) BusinessError(c *gin.Context, message string, code st
 		Error:   "Business Error",
 		Message: message,
 		Code:    code,
-		TraceID: c.GetString("trace_id"),
+		TraceID: ctxkeys.GetTraceID(c),
 	}
 	c.JSON(http.StatusBadRequest, response)
 }
@@ -219,7 +220,7 @@ func (em *ErrorMiddleware) NotFoundError(c *gin.Context, message string) {
 		Error:   "Not Found",
 		Message: message,
 		Code:    "NOT_FOUND",
-		TraceID: c.GetString("trace_id"),
+		TraceID: ctxkeys.GetTraceID(c),
 	}
 	c.JSON(http.StatusNotFound, response)
 }
@@ -230,7 +231,7 @@ func (em *ErrorMiddleware) UnauthorizedError(c *gin.Context, message string) {
 		Error:   "Unauthorized",
 		Message: message,
 		Code:    "UNAUTHORIZED",
-		TraceID: c.GetString("trace_id"),
+		TraceID: ctxkeys.GetTraceID(c),
 	}
 	c.JSON(http.StatusUnauthorized, response)
 }
@@ -241,7 +242,7 @@ func (em *ErrorMiddleware) ForbiddenError(c *gin.Context, message string) {
 		Error:   "Forbidden",
 		Message: message,
 		Code:    "FORBIDDEN",
-		TraceID: c.GetString("trace_id"),
+		TraceID: ctxkeys.GetTraceID(c),
 	}
 	c.JSON(http.StatusForbidden, response)
 }
@@ -252,7 +253,7 @@ func (em *ErrorMiddleware) ConflictError(c *gin.Context, message string) {
 		Error:   "Conflict",
 		Message: message,
 		Code:    "CONFLICT",
-		TraceID: c.GetString("trace_id"),
+		TraceID: ctxkeys.GetTraceID(c),
 	}
 	c.JSON(http.StatusConflict, response)
 }
@@ -263,7 +264,7 @@ func (em *ErrorMiddleware) RateLimitError(c *gin.Context, message string) {
 		Error:   "Rate Limit Exceeded",
 		Message: message,
 		Code:    "RATE_LIMIT_EXCEEDED",
-		TraceID: c.GetString("trace_id"),
+		TraceID: ctxkeys.GetTraceID(c),
 	}
 	c.JSON(http.StatusTooManyRequests, response)
 }
@@ -274,7 +275,7 @@ func (em *ErrorMiddleware) ServiceUnavailableError(c *gin.Context, message strin
 		Error:   "Service Unavailable",
 		Message: message,
 		Code:    "SERVICE_UNAVAILABLE",
-		TraceID: c.GetString("trace_id"),
+		TraceID: ctxkeys.GetTraceID(c),
 	}
 	c.JSON(http.StatusServiceUnavailable, response)
 }
@@ -285,7 +286,7 @@ func (em *ErrorMiddleware) InternalServerError(c *gin.Context, message string) {
 		Error:   "Internal Server Error",
 		Message: message,
 		Code:    "INTERNAL_ERROR",
-		TraceID: c.GetString("trace_id"),
+		TraceID: ctxkeys.GetTraceID(c),
 	}
 	c.JSON(http.StatusInternalServerError, response)
 }
@@ -303,10 +304,8 @@ type RequestInfo struct {
 // getRequestID extracts request ID from context or headers
 func (em *ErrorMiddleware) getRequestID(c *gin.Context) string {
 	// Check for request ID in context
-	if requestID, exists := c.Get("request_id"); exists {
-		if id, ok := requestID.(string); ok {
-			return id
-		}
+	if requestID := ctxkeys.GetRequestID(c); requestID != "" {
+		return requestID
 	}
 
 	// Check for request ID in headers
@@ -316,18 +315,14 @@ func (em *ErrorMiddleware) getRequestID(c *gin.Context) string {
 
 	// Generate new request ID
 	requestID := em.generateRequestID()
-	c.Set("request_id", requestID)
+	c.Set(ctxkeys.RequestID, requestID)
 	return requestID
 }
 
 // getUserID extracts user ID from context
 func (em *ErrorMiddleware) getUserID(c *gin.Context) string {
-	if userID, exists := c.Get("user_id"); exists {
-		if id, ok := userID.(string); ok {
-			return id
-		}
-	}
-	return ""
+	userID, _ := ctxkeys.GetUserID(c)
+	return userID
 }
 
 // generateRequestID generates a unique request ID
@@ -7,6 +7,7 @@ import (
 	"runtime"
 	"strings"
 
+	"backend-core/ctxkeys"
 	"backend-core/logging"
 	"backend-shared/errors"
 
@@ -170,9 +171,9 @@ func (h *DefaultExceptionHandler) handleGenericError(err error, requestInfo Requ
 // logException logs the exception with context
 func (h *DefaultExceptionHandler) logException(ctx context.Context, err error, requestInfo RequestInfo) {
 	// Create context with request information
-	ctx = context.WithValue(ctx, "request_id", requestInfo.RequestID)
-	ctx = context.WithValue(ctx, "user_id", requestInfo.UserID)
-	ctx = context.WithValue(ctx, "service_name", h.serviceName)
+	ctx = ctxkeys.WithRequestID(ctx, requestInfo.RequestID)
+	ctx = ctxkeys.WithUserID(ctx, requestInfo.UserID)
+	ctx = ctxkeys.WithServiceName(ctx, h.serviceName)
 
 	// Get logger with context
 	logger := h.logger.WithContext(ctx)
@@ -205,9 +206,9 @@ func (h *DefaultExceptionHandler) logException(ctx context.Context, err error, r
 // logPanic logs the panic with context
 func (h *DefaultExceptionHandler) logPanic(ctx context.Context, panicValue interface{}, requestInfo RequestInfo) {
 	// Create context with request information
-	ctx = context.WithValue(ctx, "request_id", requestInfo.RequestID)
-	ctx = context.WithValue(ctx, "user_id", requestInfo.UserID)
-	ctx = context.WithValue(ctx, "service_name", h.serviceName)
+	ctx = ctxkeys.WithRequestID(ctx, requestInfo.RequestID)
+	ctx = ctxkeys.WithUserID(ctx, requestInfo.UserID)
+	ctx = ctxkeys.WithServiceName(ctx, h.serviceName)
 
 	// Get logger with context
 	logger := h.logger.WithContext(ctx)
@@ -0,0 +1,43 @@
+package http
+
+import (
+	"net/http"
+
+	"backend-core/inflight"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InFlightMiddleware counts requests being served so shutdown can wait for them
+type InFlightMiddleware struct {
+	tracker *inflight.Tracker
+}
+
+// NewInFlightMiddleware creates a middleware counting requests in tracker
+func NewInFlightMiddleware(tracker *inflight.Tracker) *InFlightMiddleware {
+	return &InFlightMiddleware{
+		tracker: tracker,
+	}
+}
+
+// Handler returns the in-flight counting middleware handler. Register it first so the
+// request counts for the whole chain.
+func (m *InFlightMiddleware) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		done := m.tracker.Track()
+		defer done()
+
+		c.Next()
+	}
+}
+
+// Wrap returns next counting requests in the tracker, for handlers whose gin middleware
+// chain is already built
+func (m *InFlightMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		done := m.tracker.Track()
+		defer done()
+
+		next.ServeHTTP(w, r)
+	})
+}
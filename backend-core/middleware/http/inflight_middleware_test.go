@@ -0,0 +1,60 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"backend-core/inflight"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestShutdownWaitsForInFlightRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracker := inflight.NewTracker()
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	router := gin.New()
+	router.Use(NewInFlightMiddleware(tracker).Handler())
+	router.GET("/slow", func(c *gin.Context) {
+		close(started)
+		<-release
+		c.Status(http.StatusOK)
+	})
+
+	served := make(chan int, 1)
+	go func() {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slow", nil))
+		served <- rec.Code
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- tracker.WaitForInFlight(context.Background())
+	}()
+
+	select {
+	case <-shutdown:
+		t.Fatal("shutdown proceeded while a request was in flight")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case err := <-shutdown:
+		if err != nil {
+			t.Fatalf("WaitForInFlight() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("shutdown did not proceed after the request completed")
+	}
+	if code := <-served; code != http.StatusOK {
+		t.Errorf("status = %d, want %d", code, http.StatusOK)
+	}
+}
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend-core/telemetry"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// idPlaceholder replaces path segments NormalizePath recognizes as IDs
+	idPlaceholder = ":id"
+
+	// UnmatchedPath is the path label of requests that matched no route, so paths probed
+	// by scanners do not each become a label of their own
+	UnmatchedPath = "unmatched"
+)
+
+// PathNormalizer maps a route to the path label its metrics are recorded under.
+// It should map every route to one of a bounded set of labels.
+type PathNormalizer func(path string) string
+
+// MetricsOption configures MetricsMiddleware
+type MetricsOption func(*metricsMiddleware)
+
+// WithPathNormalizer replaces NormalizePath as the path normalizer
+func WithPathNormalizer(normalize PathNormalizer) MetricsOption {
+	return func(m *metricsMiddleware) {
+		if normalize != nil {
+			m.normalize = normalize
+		}
+	}
+}
+
+type metricsMiddleware struct {
+	bm        *telemetry.BusinessMetrics
+	normalize PathNormalizer
+}
+
+// MetricsMiddleware records the duration and status of every request with bm, and records
+// 4xx and 5xx responses as errors as well. Paths are labelled by the matched route, such as
+// "/users/:id", passed through NormalizePath unless another normalizer is given; requests
+// that match no route are labelled UnmatchedPath. Streaming responses are recorded when they end.
+func MetricsMiddleware(bm *telemetry.BusinessMetrics, opts ...MetricsOption) gin.HandlerFunc {
+	m := &metricsMiddleware{
+		bm:        bm,
+		normalize: NormalizePath,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		duration := time.Since(start).Seconds()
+		method := c.Request.Method
+		path := UnmatchedPath
+		if route := c.FullPath(); route != "" {
+			path = m.normalize(route)
+		}
+
+		code := c.Writer.Status()
+		// A successful WebSocket upgrade writes its response on the hijacked connection,
+		// leaving the writer at the default status
+		if c.IsWebsocket() && code == http.StatusOK {
+			code = http.StatusSwitchingProtocols
+		}
+		status := strconv.Itoa(code)
+
+		ctx := c.Request.Context()
+		m.bm.RecordHTTPRequest(ctx, method, path, status, duration)
+		if code >= http.StatusBadRequest {
+			m.bm.RecordHTTPError(ctx, method, path, status)
+		}
+	}
+}
+
+// NormalizePath replaces the path segments that are UUIDs or numbers with ":id", so
+// "/users/42/events/0b6f...e1" is recorded as "/users/:id/events/:id"
+func NormalizePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if isNumeric(segment) || isUUID(segment) {
+			segments[i] = idPlaceholder
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// isNumeric reports whether s is a non-empty string of decimal digits
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isUUID reports whether s is a UUID in its canonical 8-4-4-4-12 hex form
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !isHex(s[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isHex(b byte) bool {
+	return '0' <= b && b <= '9' || 'a' <= b && b <= 'f' || 'A' <= b && b <= 'F'
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend-core/telemetry"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recordedRequests is a telemetry.ContextlessMetrics recording the labels of HTTP requests
+type recordedRequests struct {
+	telemetry.ContextlessMetrics
+	paths  []string
+	errors []string
+}
+
+func (r *recordedRequests) RecordHTTPRequest(method, path, status string, duration float64) {
+	r.paths = append(r.paths, path)
+}
+
+func (r *recordedRequests) RecordHTTPError(method, path, status string) {
+	r.errors = append(r.errors, path)
+}
+
+func serveMetrics(t *testing.T, target string, opts ...MetricsOption) *recordedRequests {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	recorded := &recordedRequests{}
+	router := gin.New()
+	router.Use(MetricsMiddleware(&telemetry.BusinessMetrics{HTTPRequestCounter: recorded, HTTPErrorCounter: recorded}, opts...))
+	router.GET("/users/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	return recorded
+}
+
+func TestMetricsMiddlewareLabelsMatchedRoute(t *testing.T) {
+	recorded := serveMetrics(t, "/users/42")
+
+	if len(recorded.paths) != 1 || recorded.paths[0] != "/users/:id" {
+		t.Errorf("recorded paths = %q, want [/users/:id]", recorded.paths)
+	}
+}
+
+func TestMetricsMiddlewareLabelsUnmatchedPathsAsOne(t *testing.T) {
+	for _, target := range []string{"/wp-admin/setup.php", "/.env", "/users/42/secrets"} {
+		recorded := serveMetrics(t, target)
+
+		if len(recorded.paths) != 1 || recorded.paths[0] != UnmatchedPath {
+			t.Errorf("%s: recorded paths = %q, want [%s]", target, recorded.paths, UnmatchedPath)
+		}
+		if len(recorded.errors) != 1 || recorded.errors[0] != UnmatchedPath {
+			t.Errorf("%s: recorded errors = %q, want [%s]", target, recorded.errors, UnmatchedPath)
+		}
+	}
+}
+
+func TestMetricsMiddlewareNormalizesRoute(t *testing.T) {
+	recorded := serveMetrics(t, "/users/42", WithPathNormalizer(func(route string) string {
+		return "route " + route
+	}))
+
+	if len(recorded.paths) != 1 || recorded.paths[0] != "route /users/:id" {
+		t.Errorf("recorded paths = %q, want [route /users/:id]", recorded.paths)
+	}
+}
+
+func TestNormalizePath(t *testing.T) {
+	for path, want := range map[string]string{
+		"/users/42": "/users/:id",
+		"/users/0b6f3c1e-8a2d-4c5b-9e7f-1a2b3c4d5e6f/events": "/users/:id/events",
+		"/users/me": "/users/me",
+		"/v1/users": "/v1/users",
+	} {
+		if got := NormalizePath(path); got != want {
+			t.Errorf("NormalizePath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -1,15 +1,19 @@
 package http
 
 import (
+	"backend-core/ctxkeys"
 	"backend-core/logging"
 	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"hash/fnv"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/metadata"
 )
 
 // RequestCorrelationConfig holds unified request/correlation ID configuration
@@ -28,6 +32,14 @@ type RequestCorrelationConfig struct {
 	LogRequests   bool
 	LogTiming     bool
 
+	// Log sampling. LogSampleRate is the fraction of requests, between 0 and 1, whose start
+	// and completion are logged; 0 logs every request. Requests that fail with a 5xx status
+	// or are slow are always logged.
+	LogSampleRate float64
+	// SlowRequestThreshold, when set, marks requests taking longer as slow: their
+	// completion is logged at WARN level with slow=true. Zero disables it.
+	SlowRequestThreshold time.Duration
+
 	// Propagation settings
 	PropagateTo    []string
 	StoreInContext bool
@@ -89,19 +101,22 @@ func (r *RequestCorrelationMiddleware) Handler() gin.HandlerFunc {
 
 		// Set in context for use in handlers
 		if r.config.SetInContext {
-			ctx.Set("request_id", requestID)
-			ctx.Set("correlation_id", correlationID)
+			ctx.Set(ctxkeys.RequestID, requestID)
+			ctx.Set(ctxkeys.CorrelationID, correlationID)
 		}
 
 		// Add to request context for propagation
-		reqCtx := context.WithValue(ctx.Request.Context(), "request_id", requestID)
-		reqCtx = context.WithValue(reqCtx, "correlation_id", correlationID)
+		reqCtx := ctxkeys.WithRequestID(ctx.Request.Context(), requestID)
+		reqCtx = ctxkeys.WithCorrelationID(reqCtx, correlationID)
 		ctx.Request = ctx.Request.WithContext(reqCtx)
 
+		// Decide once whether this request is logged, so its start and completion logs are
+		// either both written or both skipped
+		sampled := r.sampled(requestID)
+
 		// Log request start
-		if r.config.LogRequests {
-			r.logger.Info("Request started, request_id: %s, correlation_id: %s, method: %s, path: %s, ip_address: %s, user_agent: %s",
-				requestID, correlationID, ctx.Request.Method, ctx.Request.URL.Path, ctx.ClientIP(), ctx.Request.UserAgent())
+		if r.config.LogRequests && sampled {
+			r.logRequestStarted(ctx, requestID, correlationID)
 		}
 
 		// Start timing
@@ -110,15 +125,73 @@ func (r *RequestCorrelationMiddleware) Handler() gin.HandlerFunc {
 		// Process request
 		ctx.Next()
 
+		duration := time.Since(start)
+		if !sampled && r.alwaysLogged(ctx.Writer.Status(), duration) {
+			// The start of a request skipped by sampling is logged late, so a failed or slow
+			// request still has both its logs
+			sampled = true
+			if r.config.LogRequests {
+				r.logRequestStarted(ctx, requestID, correlationID)
+			}
+		}
+
 		// Log completion with timing
-		if r.config.LogTiming {
-			duration := time.Since(start)
-			r.logger.Info("Request completed, request_id: %s, correlation_id: %s, method: %s, path: %s, status_code: %d, duration_ms: %d, response_time: %.2f",
-				requestID, correlationID, ctx.Request.Method, ctx.Request.URL.Path, ctx.Writer.Status(), duration.Milliseconds(), float64(duration.Milliseconds()))
+		if r.config.LogTiming && sampled {
+			fields := []interface{}{
+				"request_id", requestID,
+				"correlation_id", correlationID,
+				"method", ctx.Request.Method,
+				"path", ctx.Request.URL.Path,
+				"status_code", ctx.Writer.Status(),
+				"duration_ms", duration.Milliseconds(),
+				"response_time", float64(duration.Milliseconds()),
+			}
+			if r.isSlow(duration) {
+				r.logger.Warn("Request completed", append(fields, "slow", true)...)
+			} else {
+				r.logger.Info("Request completed", fields...)
+			}
 		}
 	}
 }
 
+// logRequestStarted logs the start of a request
+func (r *RequestCorrelationMiddleware) logRequestStarted(ctx *gin.Context, requestID, correlationID string) {
+	r.logger.Info("Request started",
+		"request_id", requestID,
+		"correlation_id", correlationID,
+		"method", ctx.Request.Method,
+		"path", ctx.Request.URL.Path,
+		"ip_address", ctx.ClientIP(),
+		"user_agent", ctx.Request.UserAgent())
+}
+
+// sampled reports whether the request with requestID falls within the log sample rate. The
+// decision is derived from the request ID, so every service a request passes through makes
+// the same one.
+func (r *RequestCorrelationMiddleware) sampled(requestID string) bool {
+	rate := r.config.LogSampleRate
+	if rate <= 0 || rate >= 1 {
+		return true
+	}
+	h := fnv.New32a()
+	h.Write([]byte(requestID))
+	return float64(h.Sum32())/float64(1<<32) < rate
+}
+
+// alwaysLogged reports whether a request is logged regardless of sampling
+func (r *RequestCorrelationMiddleware) alwaysLogged(status int, duration time.Duration) bool {
+	if status >= http.StatusInternalServerError {
+		return true
+	}
+	return r.isSlow(duration)
+}
+
+// isSlow reports whether duration exceeds the slow request threshold
+func (r *RequestCorrelationMiddleware) isSlow(duration time.Duration) bool {
+	return r.config.SlowRequestThreshold > 0 && duration > r.config.SlowRequestThreshold
+}
+
 // generateOptimizedID generates an optimized ID (UUID v4 or timestamp-based)
 func generateOptimizedID() string {
 	// Generate 16 random bytes
@@ -141,40 +214,26 @@ func generateOptimizedID() string {
 		hexStr[20:32])
 }
 
-// GetRequestIDFromContext extracts request ID from context
+// GetRequestIDFromContext extracts request ID from context. IDs stored under the old bare
+// "request_id" string key are still found for one release.
 func GetRequestIDFromContext(ctx context.Context) string {
-	if requestID, ok := ctx.Value("request_id").(string); ok {
-		return requestID
-	}
-	return ""
+	return ctxkeys.RequestIDFrom(ctx)
 }
 
-// GetCorrelationIDFromContext extracts correlation ID from context
+// GetCorrelationIDFromContext extracts correlation ID from context. IDs stored under the old
+// bare "correlation_id" string key are still found for one release.
 func GetCorrelationIDFromContext(ctx context.Context) string {
-	if correlationID, ok := ctx.Value("correlation_id").(string); ok {
-		return correlationID
-	}
-	return ""
+	return ctxkeys.CorrelationIDFrom(ctx)
 }
 
 // GetRequestIDFromGin extracts request ID from Gin context
 func GetRequestIDFromGin(ctx *gin.Context) string {
-	if requestID, exists := ctx.Get("request_id"); exists {
-		if id, ok := requestID.(string); ok {
-			return id
-		}
-	}
-	return ""
+	return ctxkeys.GetRequestID(ctx)
 }
 
 // GetCorrelationIDFromGin extracts correlation ID from Gin context
 func GetCorrelationIDFromGin(ctx *gin.Context) string {
-	if correlationID, exists := ctx.Get("correlation_id"); exists {
-		if id, ok := correlationID.(string); ok {
-			return id
-		}
-	}
-	return ""
+	return ctxkeys.GetCorrelationID(ctx)
 }
 
 // RequestCorrelationPropagator handles ID propagation to other services
@@ -200,8 +259,10 @@ func (p *RequestCorrelationPropagator) PropagateToHTTPRequest(req *http.Request,
 		req.Header.Set(p.config.CorrelationIDHeader, correlationID)
 	}
 
-	p.logger.Debug("IDs propagated to HTTP request, request_id: %s, correlation_id: %s, url: %s",
-		requestID, correlationID, req.URL.String())
+	p.logger.Debug("IDs propagated to HTTP request",
+		"request_id", requestID,
+		"correlation_id", correlationID,
+		"url", req.URL.String())
 }
 
 // PropagateToKafkaMessage adds IDs to Kafka message headers
@@ -219,23 +280,41 @@ func (p *RequestCorrelationPropagator) PropagateToKafkaMessage(headers map[strin
 		headers["correlation_id"] = correlationID // Also add as correlation_id for compatibility
 	}
 
-	p.logger.Debug("IDs propagated to Kafka message, request_id: %s, correlation_id: %s", requestID, correlationID)
+	p.logger.Debug("IDs propagated to Kafka message", "request_id", requestID, "correlation_id", correlationID)
 	return headers
 }
 
 // PropagateToDatabaseQuery adds IDs to database query context
 func (p *RequestCorrelationPropagator) PropagateToDatabaseQuery(ctx context.Context, requestID, correlationID string) context.Context {
 	if requestID != "" {
-		ctx = context.WithValue(ctx, "request_id", requestID)
+		ctx = ctxkeys.WithRequestID(ctx, requestID)
 	}
 	if correlationID != "" {
-		ctx = context.WithValue(ctx, "correlation_id", correlationID)
+		ctx = ctxkeys.WithCorrelationID(ctx, correlationID)
 	}
 
-	p.logger.Debug("IDs propagated to database query, request_id: %s, correlation_id: %s", requestID, correlationID)
+	p.logger.Debug("IDs propagated to database query", "request_id", requestID, "correlation_id", correlationID)
 	return ctx
 }
 
+// PropagateToGRPCContext adds IDs to the outgoing gRPC metadata of ctx, under the
+// lowercased header names
+func (p *RequestCorrelationPropagator) PropagateToGRPCContext(ctx context.Context, requestID, correlationID string) context.Context {
+	var pairs []string
+	if requestID != "" {
+		pairs = append(pairs, strings.ToLower(p.config.RequestIDHeader), requestID)
+	}
+	if correlationID != "" {
+		pairs = append(pairs, strings.ToLower(p.config.CorrelationIDHeader), correlationID)
+	}
+	if len(pairs) == 0 {
+		return ctx
+	}
+
+	p.logger.Debug("IDs propagated to gRPC metadata", "request_id", requestID, "correlation_id", correlationID)
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
 // ExtractFromHTTPRequest extracts IDs from HTTP request
 func (p *RequestCorrelationPropagator) ExtractFromHTTPRequest(req *http.Request) (requestID, correlationID string) {
 	requestID = req.Header.Get(p.config.RequestIDHeader)
@@ -281,6 +360,29 @@ func (p *RequestCorrelationPropagator) ExtractFromKafkaMessage(headers map[strin
 	return requestID, correlationID
 }
 
+// ExtractFromGRPCContext extracts IDs from the incoming gRPC metadata of ctx
+func (p *RequestCorrelationPropagator) ExtractFromGRPCContext(ctx context.Context) (requestID, correlationID string) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", ""
+	}
+
+	get := func(keys ...string) string {
+		for _, key := range keys {
+			if values := md.Get(key); len(values) > 0 && values[0] != "" {
+				return values[0]
+			}
+		}
+		return ""
+	}
+
+	// Try alternative key names if not found
+	requestID = get(p.config.RequestIDHeader, "x-request-id", "request_id")
+	correlationID = get(p.config.CorrelationIDHeader, "x-correlation-id", "x-trace-id", "correlation_id")
+
+	return requestID, correlationID
+}
+
 // RequestCorrelationContext provides unified context for services
 type RequestCorrelationContext struct {
 	RequestID     string
@@ -390,22 +492,25 @@ func NewRequestCorrelationTestHelper(logger *logging.Logger) *RequestCorrelation
 
 // TestSyncPropagation tests synchronous ID propagation
 func (h *RequestCorrelationTestHelper) TestSyncPropagation(requestID, correlationID string) {
-	h.logger.Info("Testing synchronous propagation, request_id: %s, correlation_id: %s, test_type: sync", requestID, correlationID)
+	h.logger.Info("Testing synchronous propagation", "request_id", requestID, "correlation_id", correlationID, "test_type", "sync")
 
 	// Simulate synchronous service calls
-	h.logger.Info("Calling user service, request_id: %s, correlation_id: %s, service: user-service", requestID, correlationID)
-	h.logger.Info("Calling auth service, request_id: %s, correlation_id: %s, service: auth-service", requestID, correlationID)
-	h.logger.Info("Calling notification service, request_id: %s, correlation_id: %s, service: notification-service", requestID, correlationID)
+	h.logger.Info("Calling user service", "request_id", requestID, "correlation_id", correlationID, "service", "user-service")
+	h.logger.Info("Calling auth service", "request_id", requestID, "correlation_id", correlationID, "service", "auth-service")
+	h.logger.Info("Calling notification service", "request_id", requestID, "correlation_id", correlationID, "service", "notification-service")
 }
 
 // TestAsyncPropagation tests asynchronous ID propagation
 func (h *RequestCorrelationTestHelper) TestAsyncPropagation(requestID, correlationID string) {
-	h.logger.Info("Testing asynchronous propagation, request_id: %s, correlation_id: %s, test_type: async", requestID, correlationID)
+	h.logger.Info("Testing asynchronous propagation", "request_id", requestID, "correlation_id", correlationID, "test_type", "async")
 
 	// Simulate asynchronous event publishing
-	h.logger.Info("Publishing user registered event, request_id: %s, correlation_id: %s, event_type: user.registered, topic: user.events", requestID, correlationID)
-	h.logger.Info("Publishing user activated event, request_id: %s, correlation_id: %s, event_type: user.activated, topic: user.events", requestID, correlationID)
+	h.logger.Info("Publishing user registered event",
+		"request_id", requestID, "correlation_id", correlationID, "event_type", "user.registered", "topic", "user.events")
+	h.logger.Info("Publishing user activated event",
+		"request_id", requestID, "correlation_id", correlationID, "event_type", "user.activated", "topic", "user.events")
 
 	// Simulate event consumption
-	h.logger.Info("Consuming user registered event, request_id: %s, correlation_id: %s, service: notification-service, event_type: user.registered", requestID, correlationID)
+	h.logger.Info("Consuming user registered event",
+		"request_id", requestID, "correlation_id", correlationID, "service", "notification-service", "event_type", "user.registered")
 }
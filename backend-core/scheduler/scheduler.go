@@ -0,0 +1,475 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"backend-core/cache"
+	"backend-core/logging"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	// defaultLockTTL is used when Job.LockTTL is not set
+	defaultLockTTL = time.Minute
+	// defaultMinLockHold is used when Job.MinLockHold is not set
+	defaultMinLockHold = time.Second
+	// lockTimeout bounds a single lock operation against the lock store
+	lockTimeout = 5 * time.Second
+)
+
+// Run results recorded in the job metrics
+const (
+	resultSuccess = "success"
+	resultFailure = "failure"
+	resultPanic   = "panic"
+	resultSkipped = "skipped"
+)
+
+// ErrSchedulerStopped is returned when registering a job on a stopped scheduler
+var ErrSchedulerStopped = errors.New("scheduler stopped")
+
+// Lock is the distributed lock guarding one job, so that when several instances run the
+// same scheduler only one of them runs each occurrence. *cache.RedisLock implements it.
+type Lock interface {
+	TryAcquire(ctx context.Context) (bool, error)
+	Extend(ctx context.Context, ttl time.Duration) error
+	Release(ctx context.Context) error
+}
+
+// LockFactory creates the lock for the job named name
+type LockFactory func(name string, ttl time.Duration) Lock
+
+// RedisLocks returns a LockFactory that locks each job with a Redis lock named
+// "scheduler:<job>" in the key namespace of redisCache
+func RedisLocks(redisCache *cache.RedisCache) LockFactory {
+	return func(name string, ttl time.Duration) Lock {
+		return cache.NewRedisLock(redisCache, "scheduler:"+name, ttl)
+	}
+}
+
+// Job is a named unit of work run on a schedule
+type Job struct {
+	// Name identifies the job in logs, metrics and its lock; it must be unique
+	Name string
+
+	// Schedule decides when the job runs, see Every and ParseSchedule
+	Schedule Schedule
+
+	// Run does the work. Its context is cancelled when the scheduler stops or Timeout
+	// passes. A returned error or a panic counts the run as failed; the job keeps its schedule.
+	Run func(ctx context.Context) error
+
+	// Timeout bounds a single run. Zero lets a run take until the scheduler stops.
+	Timeout time.Duration
+
+	// LockTTL is how long the job lock outlives an instance that dies mid-run. The lock
+	// is extended every LockTTL/3 while the job runs, so runs may take longer than this.
+	LockTTL time.Duration
+
+	// MinLockHold keeps the lock for at least this long after a run starts, so an instance
+	// whose clock is slightly behind cannot run the same occurrence again once a quick run
+	// has finished. It is capped at half the time between runs.
+	MinLockHold time.Duration
+}
+
+// JobStats are the metrics of one job since the scheduler was created
+type JobStats struct {
+	// Runs is the number of times the job ran on this instance
+	Runs int64 `json:"runs"`
+	// Failures is the number of runs that returned an error, including panics
+	Failures int64 `json:"failures"`
+	// Panics is the number of runs that panicked
+	Panics int64 `json:"panics"`
+	// Skipped is the number of occurrences not run here because the lock was held elsewhere
+	// or could not be acquired
+	Skipped int64 `json:"skipped"`
+
+	LastRun      time.Time     `json:"last_run,omitempty"`
+	LastDuration time.Duration `json:"last_duration"`
+	LastError    string        `json:"last_error,omitempty"`
+	NextRun      time.Time     `json:"next_run,omitempty"`
+}
+
+// scheduledJob is a registered job with its lock and stats
+type scheduledJob struct {
+	job  Job
+	lock Lock
+
+	mu    sync.Mutex
+	stats JobStats
+}
+
+// Scheduler runs registered jobs on their schedules. Each job runs in its own goroutine and
+// never overlaps with itself on one instance; a run that overruns its next occurrence makes
+// the job skip to the following one. With a LockFactory, jobs also take a distributed lock
+// before every run so only one instance runs each occurrence.
+type Scheduler struct {
+	locks  LockFactory
+	logger *logging.Logger
+
+	mu      sync.Mutex
+	jobs    map[string]*scheduledJob
+	started bool
+	stopped bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	runs     metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+// NewScheduler creates a scheduler. A nil locks runs every job on every instance.
+// When meter is nil the global OpenTelemetry meter provider is used.
+func NewScheduler(locks LockFactory, meter metric.Meter, logger *logging.Logger) (*Scheduler, error) {
+	if meter == nil {
+		meter = otel.Meter("backend-core/scheduler")
+	}
+
+	runs, err := meter.Int64Counter(
+		"scheduler_job_runs_total",
+		metric.WithDescription("Total number of scheduled job occurrences by job and result"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := meter.Float64Histogram(
+		"scheduler_job_duration_seconds",
+		metric.WithDescription("Duration of scheduled job runs"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		locks:    locks,
+		logger:   logger,
+		jobs:     make(map[string]*scheduledJob),
+		ctx:      ctx,
+		cancel:   cancel,
+		runs:     runs,
+		duration: duration,
+	}, nil
+}
+
+// Register adds a job. Jobs registered after Start begin running right away.
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" {
+		return fmt.Errorf("job name is required")
+	}
+	if job.Schedule == nil {
+		return fmt.Errorf("job %q has no schedule", job.Name)
+	}
+	if job.Run == nil {
+		return fmt.Errorf("job %q has no run function", job.Name)
+	}
+	if job.Schedule.Next(time.Now()).IsZero() {
+		return fmt.Errorf("job %q schedule never runs", job.Name)
+	}
+	if job.LockTTL <= 0 {
+		job.LockTTL = defaultLockTTL
+	}
+	if job.MinLockHold <= 0 {
+		job.MinLockHold = defaultMinLockHold
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return ErrSchedulerStopped
+	}
+	if _, exists := s.jobs[job.Name]; exists {
+		return fmt.Errorf("job %q is already registered", job.Name)
+	}
+
+	sj := &scheduledJob{job: job}
+	if s.locks != nil {
+		sj.lock = s.locks(job.Name, job.LockTTL)
+	}
+	s.jobs[job.Name] = sj
+
+	if s.started {
+		s.launch(sj)
+	}
+
+	s.logger.Info("Scheduled job registered",
+		logging.String("job", job.Name),
+		logging.Bool("distributed_lock", sj.lock != nil))
+	return nil
+}
+
+// RegisterFunc adds a job running run on the schedule spec, see ParseSchedule
+func (s *Scheduler) RegisterFunc(name, spec string, run func(ctx context.Context) error) error {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return fmt.Errorf("job %q: %w", name, err)
+	}
+	return s.Register(Job{Name: name, Schedule: schedule, Run: run})
+}
+
+// Start starts running the registered jobs. Calling it again, or after Stop, has no effect.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started || s.stopped {
+		return
+	}
+	s.started = true
+
+	for _, sj := range s.jobs {
+		s.launch(sj)
+	}
+	s.logger.Info("Scheduler started", logging.Int("jobs", len(s.jobs)))
+}
+
+// Stop cancels running jobs and waits for them to return, or for ctx to be done.
+// The scheduler cannot be restarted. It is safe to call more than once.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.logger.Info("Scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		s.logger.Warn("Scheduler stopped before all jobs returned")
+		return ctx.Err()
+	}
+}
+
+// Stats returns the metrics of every registered job, keyed by job name
+func (s *Scheduler) Stats() map[string]JobStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make(map[string]JobStats, len(s.jobs))
+	for name, sj := range s.jobs {
+		sj.mu.Lock()
+		stats[name] = sj.stats
+		sj.mu.Unlock()
+	}
+	return stats
+}
+
+// launch starts the goroutine running sj. s.mu must be held.
+func (s *Scheduler) launch(sj *scheduledJob) {
+	s.wg.Add(1)
+	go s.loop(sj)
+}
+
+// loop waits for each occurrence of sj and runs it until the scheduler stops
+func (s *Scheduler) loop(sj *scheduledJob) {
+	defer s.wg.Done()
+
+	for {
+		next := sj.job.Schedule.Next(time.Now())
+		if next.IsZero() {
+			s.logger.Info("Scheduled job has no further runs", logging.String("job", sj.job.Name))
+			return
+		}
+
+		sj.mu.Lock()
+		sj.stats.NextRun = next
+		sj.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.execute(sj, next)
+	}
+}
+
+// execute runs the occurrence of sj scheduled at scheduled, under its lock if it has one
+func (s *Scheduler) execute(sj *scheduledJob, scheduled time.Time) {
+	if sj.lock != nil {
+		lockCtx, cancel := context.WithTimeout(s.ctx, lockTimeout)
+		acquired, err := sj.lock.TryAcquire(lockCtx)
+		cancel()
+		if err != nil {
+			s.logger.Warn("Failed to acquire scheduled job lock, skipping run",
+				logging.Error(err),
+				logging.String("job", sj.job.Name))
+		}
+		if !acquired {
+			s.record(sj, resultSkipped, time.Time{}, 0, nil)
+			return
+		}
+	}
+
+	start := time.Now()
+	var stopKeepalive func()
+	if sj.lock != nil {
+		stopKeepalive = s.keepLock(sj)
+	}
+
+	panicked, err := s.run(sj)
+	duration := time.Since(start)
+
+	if sj.lock != nil {
+		stopKeepalive()
+		s.unlock(sj, scheduled, start)
+	}
+
+	switch {
+	case panicked:
+		s.record(sj, resultPanic, start, duration, err)
+	case err != nil:
+		s.logger.Error("Scheduled job failed",
+			logging.Error(err),
+			logging.String("job", sj.job.Name),
+			logging.Duration("duration", duration))
+		s.record(sj, resultFailure, start, duration, err)
+	default:
+		s.logger.Debug("Scheduled job completed",
+			logging.String("job", sj.job.Name),
+			logging.Duration("duration", duration))
+		s.record(sj, resultSuccess, start, duration, nil)
+	}
+}
+
+// run calls the job, turning a panic into an error so one bad run cannot take down the process
+func (s *Scheduler) run(sj *scheduledJob) (panicked bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = true
+			err = fmt.Errorf("job panicked: %v", r)
+			s.logger.Error("Scheduled job panicked",
+				logging.String("job", sj.job.Name),
+				logging.Any("panic", r),
+				logging.String("stack", string(debug.Stack())))
+		}
+	}()
+
+	ctx := s.ctx
+	if sj.job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sj.job.Timeout)
+		defer cancel()
+	}
+	return false, sj.job.Run(ctx)
+}
+
+// keepLock extends the lock of sj every LockTTL/3 until the returned function is called
+func (s *Scheduler) keepLock(sj *scheduledJob) func() {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(sj.job.LockTTL / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+				err := sj.lock.Extend(ctx, sj.job.LockTTL)
+				cancel()
+				if err != nil {
+					s.logger.Warn("Failed to extend scheduled job lock",
+						logging.Error(err),
+						logging.String("job", sj.job.Name))
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// unlock releases the lock of sj after a run, or keeps it until MinLockHold has passed
+// since start when the run was quicker than that
+func (s *Scheduler) unlock(sj *scheduledJob, scheduled, start time.Time) {
+	hold := sj.job.MinLockHold
+	if next := sj.job.Schedule.Next(scheduled); !next.IsZero() {
+		if half := next.Sub(scheduled) / 2; hold > half {
+			hold = half
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+	defer cancel()
+
+	var err error
+	if remaining := hold - time.Since(start); remaining >= time.Millisecond {
+		err = sj.lock.Extend(ctx, remaining)
+	} else {
+		err = sj.lock.Release(ctx)
+	}
+	if err != nil {
+		s.logger.Warn("Failed to release scheduled job lock",
+			logging.Error(err),
+			logging.String("job", sj.job.Name))
+	}
+}
+
+// record updates the stats and metrics of sj for one occurrence. start is zero for skipped ones.
+func (s *Scheduler) record(sj *scheduledJob, result string, start time.Time, duration time.Duration, err error) {
+	attrs := metric.WithAttributes(
+		attribute.String("job", sj.job.Name),
+		attribute.String("result", result),
+	)
+	s.runs.Add(context.Background(), 1, attrs)
+	if result != resultSkipped {
+		s.duration.Record(context.Background(), duration.Seconds(), attrs)
+	}
+
+	sj.mu.Lock()
+	defer sj.mu.Unlock()
+
+	if result == resultSkipped {
+		sj.stats.Skipped++
+		return
+	}
+
+	sj.stats.Runs++
+	sj.stats.LastRun = start
+	sj.stats.LastDuration = duration
+	sj.stats.LastError = ""
+	switch result {
+	case resultPanic:
+		sj.stats.Panics++
+		sj.stats.Failures++
+	case resultFailure:
+		sj.stats.Failures++
+	}
+	if err != nil {
+		sj.stats.LastError = err.Error()
+	}
+}
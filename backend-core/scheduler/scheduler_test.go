@@ -0,0 +1,319 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend-core/logging"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// fakeLockStore holds expiring locks by name, the way Redis holds them for RedisLocks
+type fakeLockStore struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+}
+
+func newFakeLockStore() *fakeLockStore {
+	return &fakeLockStore{expires: make(map[string]time.Time)}
+}
+
+// locks returns a LockFactory whose locks live in s, so schedulers sharing s share their locks
+func (s *fakeLockStore) locks() LockFactory {
+	return func(name string, ttl time.Duration) Lock {
+		return &fakeLock{store: s, name: name, ttl: ttl}
+	}
+}
+
+type fakeLock struct {
+	store *fakeLockStore
+	name  string
+	ttl   time.Duration
+}
+
+func (l *fakeLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.store.mu.Lock()
+	defer l.store.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(l.store.expires[l.name]) {
+		return false, nil
+	}
+	l.store.expires[l.name] = now.Add(l.ttl)
+	return true, nil
+}
+
+func (l *fakeLock) Extend(ctx context.Context, ttl time.Duration) error {
+	l.store.mu.Lock()
+	defer l.store.mu.Unlock()
+
+	l.store.expires[l.name] = time.Now().Add(ttl)
+	return nil
+}
+
+func (l *fakeLock) Release(ctx context.Context) error {
+	l.store.mu.Lock()
+	defer l.store.mu.Unlock()
+
+	delete(l.store.expires, l.name)
+	return nil
+}
+
+func newTestScheduler(t *testing.T, locks LockFactory, reader sdkmetric.Reader) *Scheduler {
+	t.Helper()
+
+	var opts []sdkmetric.Option
+	if reader != nil {
+		opts = append(opts, sdkmetric.WithReader(reader))
+	}
+	s, err := NewScheduler(locks, sdkmetric.NewMeterProvider(opts...).Meter("test"), logging.NewNopLogger())
+	if err != nil {
+		t.Fatalf("NewScheduler() error = %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		s.Stop(ctx)
+	})
+	return s
+}
+
+// waitFor polls cond until it holds or the timeout passes
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before the timeout")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// jobRuns returns the scheduler_job_runs_total value of job with the given result
+func jobRuns(t *testing.T, reader *sdkmetric.ManualReader, job, result string) int64 {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	want := attribute.NewSet(attribute.String("job", job), attribute.String("result", result))
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "scheduler_job_runs_total" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				if dp.Attributes.Equals(&want) {
+					return dp.Value
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func TestSchedulerRunsJobOnSchedule(t *testing.T) {
+	s := newTestScheduler(t, nil, nil)
+
+	var runs atomic.Int32
+	err := s.Register(Job{Name: "tick", Schedule: Every(20 * time.Millisecond), Run: func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	s.Start()
+
+	waitFor(t, time.Second, func() bool { return runs.Load() >= 2 })
+
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	stopped := runs.Load()
+	time.Sleep(50 * time.Millisecond)
+	if got := runs.Load(); got != stopped {
+		t.Errorf("job ran %d more times after Stop", got-stopped)
+	}
+	if stats := s.Stats()["tick"]; stats.Runs < 2 || stats.Failures != 0 {
+		t.Errorf("Stats() = %+v, want at least 2 runs and no failures", stats)
+	}
+}
+
+func TestSchedulerRunsEachOccurrenceOnOneInstance(t *testing.T) {
+	const interval = 100 * time.Millisecond
+	store := newFakeLockStore()
+
+	var mu sync.Mutex
+	runsPerTick := make(map[time.Time]int)
+	run := func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		runsPerTick[time.Now().Truncate(interval)]++
+		return nil
+	}
+
+	schedulers := []*Scheduler{
+		newTestScheduler(t, store.locks(), nil),
+		newTestScheduler(t, store.locks(), nil),
+	}
+	for _, s := range schedulers {
+		if err := s.Register(Job{Name: "shared", Schedule: Every(interval), Run: run}); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+	}
+	for _, s := range schedulers {
+		s.Start()
+	}
+
+	waitFor(t, 2*time.Second, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(runsPerTick) >= 3
+	})
+	for _, s := range schedulers {
+		s.Stop(context.Background())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for tick, runs := range runsPerTick {
+		if runs != 1 {
+			t.Errorf("occurrence at %s ran %d times, want 1", tick.Format(time.StampMilli), runs)
+		}
+	}
+	var skipped int64
+	for _, s := range schedulers {
+		skipped += s.Stats()["shared"].Skipped
+	}
+	if skipped == 0 {
+		t.Error("no occurrence was skipped by the instance that lost the lock")
+	}
+}
+
+func TestSchedulerRecoversFromPanickingRun(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	s := newTestScheduler(t, nil, reader)
+
+	var runs atomic.Int32
+	err := s.Register(Job{Name: "flaky", Schedule: Every(20 * time.Millisecond), Run: func(ctx context.Context) error {
+		if runs.Add(1) == 1 {
+			panic("boom")
+		}
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	s.Start()
+
+	waitFor(t, time.Second, func() bool { return runs.Load() >= 2 })
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	if got := jobRuns(t, reader, "flaky", resultPanic); got != 1 {
+		t.Errorf("scheduler_job_runs_total{result=panic} = %d, want 1", got)
+	}
+	if got := jobRuns(t, reader, "flaky", resultSuccess); got < 1 {
+		t.Errorf("scheduler_job_runs_total{result=success} = %d, want at least 1", got)
+	}
+	if stats := s.Stats()["flaky"]; stats.Panics != 1 || stats.Failures != 1 {
+		t.Errorf("Stats() = %+v, want 1 panic counted as 1 failure", stats)
+	}
+}
+
+func TestRegisterRejectsInvalidJobs(t *testing.T) {
+	s := newTestScheduler(t, nil, nil)
+	run := func(ctx context.Context) error { return nil }
+
+	tests := []struct {
+		name string
+		job  Job
+	}{
+		{"no name", Job{Schedule: Every(time.Second), Run: run}},
+		{"no schedule", Job{Name: "job", Run: run}},
+		{"no run function", Job{Name: "job", Schedule: Every(time.Second)}},
+		{"schedule that never runs", Job{Name: "job", Schedule: Every(0), Run: run}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Register(tt.job); err == nil {
+				t.Error("Register() error = nil, want an error")
+			}
+		})
+	}
+
+	s.Stop(context.Background())
+	if err := s.Register(Job{Name: "late", Schedule: Every(time.Second), Run: run}); !errors.Is(err, ErrSchedulerStopped) {
+		t.Errorf("Register() after Stop error = %v, want %v", err, ErrSchedulerStopped)
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	// A Saturday
+	from := time.Date(2024, 6, 15, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec     string
+		wantNext time.Time
+		wantErr  bool
+	}{
+		{spec: "* * * * *", wantNext: time.Date(2024, 6, 15, 10, 8, 0, 0, time.UTC)},
+		{spec: "30 * * * *", wantNext: time.Date(2024, 6, 15, 10, 30, 0, 0, time.UTC)},
+		{spec: "*/15 * * * *", wantNext: time.Date(2024, 6, 15, 10, 15, 0, 0, time.UTC)},
+		{spec: "5-10/2 * * * *", wantNext: time.Date(2024, 6, 15, 10, 9, 0, 0, time.UTC)},
+		{spec: "0 9,17 * * *", wantNext: time.Date(2024, 6, 15, 17, 0, 0, 0, time.UTC)},
+		{spec: "0 0 1 * *", wantNext: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 * * 1-5", wantNext: time.Date(2024, 6, 17, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 * * 7", wantNext: time.Date(2024, 6, 16, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either one matching is enough
+		{spec: "0 0 20 * 0", wantNext: time.Date(2024, 6, 16, 0, 0, 0, 0, time.UTC)},
+		{spec: "@hourly", wantNext: time.Date(2024, 6, 15, 11, 0, 0, 0, time.UTC)},
+		{spec: "@daily", wantNext: time.Date(2024, 6, 16, 0, 0, 0, 0, time.UTC)},
+		{spec: "@weekly", wantNext: time.Date(2024, 6, 16, 0, 0, 0, 0, time.UTC)},
+		{spec: "@yearly", wantNext: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{spec: "@every 1h30m", wantNext: time.Date(2024, 6, 15, 10, 30, 0, 0, time.UTC)},
+		// February never has a 30th
+		{spec: "0 0 30 2 *", wantNext: time.Time{}},
+
+		{spec: "", wantErr: true},
+		{spec: "* * * *", wantErr: true},
+		{spec: "* * * * * *", wantErr: true},
+		{spec: "60 * * * *", wantErr: true},
+		{spec: "* 24 * * *", wantErr: true},
+		{spec: "* * 0 * *", wantErr: true},
+		{spec: "* * * 13 *", wantErr: true},
+		{spec: "* * * * 8", wantErr: true},
+		{spec: "10-5 * * * *", wantErr: true},
+		{spec: "*/0 * * * *", wantErr: true},
+		{spec: "a * * * *", wantErr: true},
+		{spec: "@sometimes", wantErr: true},
+		{spec: "@every soon", wantErr: true},
+		{spec: "@every -1m", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSchedule(%q) error = %v, want error %v", tt.spec, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := schedule.Next(from); !got.Equal(tt.wantNext) {
+				t.Errorf("Next(%s) = %s, want %s", from, got, tt.wantNext)
+			}
+		})
+	}
+}
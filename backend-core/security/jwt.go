@@ -114,9 +114,28 @@ func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 		return nil, errors.New("invalid token")
 	}
 
+	if err := j.VerifyIssuerAndAudience(claims); err != nil {
+		return nil, err
+	}
+
 	return claims, nil
 }
 
+// VerifyIssuerAndAudience checks the token was minted by the configured issuer for the
+// configured audience. Checks for an unconfigured (empty) issuer or audience are skipped.
+func (j *JWTManager) VerifyIssuerAndAudience(claims *Claims) error {
+	if claims == nil {
+		return errors.New("invalid token")
+	}
+	if j.issuer != "" && !claims.VerifyIssuer(j.issuer, true) {
+		return errors.New("invalid token issuer")
+	}
+	if j.audience != "" && !claims.VerifyAudience(j.audience, true) {
+		return errors.New("invalid token audience")
+	}
+	return nil
+}
+
 // RefreshAccessToken generates a new access token using a valid refresh token
 func (j *JWTManager) RefreshAccessToken(refreshTokenString string) (string, error) {
 	claims, err := j.ValidateRefreshToken(refreshTokenString)
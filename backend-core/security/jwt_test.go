@@ -0,0 +1,36 @@
+package security
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateTokenChecksAudienceAndIssuer(t *testing.T) {
+	manager := NewJWTManager("secret", time.Minute, "auth-service", "admin-api")
+
+	tests := []struct {
+		name    string
+		minter  *JWTManager
+		wantErr bool
+	}{
+		{name: "same issuer and audience", minter: manager},
+		{name: "other audience", minter: NewJWTManager("secret", time.Minute, "auth-service", "billing-api"), wantErr: true},
+		{name: "other issuer", minter: NewJWTManager("secret", time.Minute, "other-service", "admin-api"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := tt.minter.GenerateAccessToken("user-1", "ann", "admin")
+			if err != nil {
+				t.Fatalf("GenerateAccessToken() error = %v", err)
+			}
+
+			claims, err := manager.ValidateToken(token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && claims.UserID != "user-1" {
+				t.Errorf("UserID = %q, want user-1", claims.UserID)
+			}
+		})
+	}
+}
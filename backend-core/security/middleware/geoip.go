@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oschwald/geoip2-golang"
+	"go.uber.org/zap"
+)
+
+const (
+	// geoIPCacheTTL is how long the country of an IP is cached
+	geoIPCacheTTL = time.Hour
+	// geoIPCacheSize bounds the lookup cache; it is emptied when full
+	geoIPCacheSize = 100000
+	// geoIPRetryInterval is how long to wait before opening a database that failed to open again
+	geoIPRetryInterval = time.Minute
+)
+
+// ErrGeoIPUnavailable is returned by GeoIPCountryLookup while its database cannot be opened
+var ErrGeoIPUnavailable = errors.New("GeoIP database unavailable")
+
+// CountryLookup resolves the ISO 3166-1 alpha-2 country code of an IP
+type CountryLookup interface {
+	Country(ip net.IP) (string, error)
+}
+
+// GeoIPCountryLookup looks up countries in a MaxMind GeoLite2 or GeoIP2 country database.
+// The database is opened on the first lookup, and opening is retried while it fails.
+type GeoIPCountryLookup struct {
+	path   string
+	logger *zap.Logger
+
+	mutex      sync.Mutex
+	reader     *geoip2.Reader
+	lastFailed time.Time
+}
+
+// NewGeoIPCountryLookup creates a lookup reading the database at path
+func NewGeoIPCountryLookup(path string, logger *zap.Logger) *GeoIPCountryLookup {
+	return &GeoIPCountryLookup{
+		path:   path,
+		logger: logger,
+	}
+}
+
+// Country returns the country code of ip, or an error if the database is unavailable
+func (g *GeoIPCountryLookup) Country(ip net.IP) (string, error) {
+	reader, err := g.open()
+	if err != nil {
+		return "", err
+	}
+
+	record, err := reader.Country(ip)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up country of %s: %w", ip, err)
+	}
+	return record.Country.IsoCode, nil
+}
+
+// Close closes the database
+func (g *GeoIPCountryLookup) Close() error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.reader == nil {
+		return nil
+	}
+	err := g.reader.Close()
+	g.reader = nil
+	return err
+}
+
+// open returns the database, opening it if it is not yet open
+func (g *GeoIPCountryLookup) open() (*geoip2.Reader, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.reader != nil {
+		return g.reader, nil
+	}
+	if time.Since(g.lastFailed) < geoIPRetryInterval {
+		return nil, fmt.Errorf("%w: %s", ErrGeoIPUnavailable, g.path)
+	}
+
+	reader, err := geoip2.Open(g.path)
+	if err != nil {
+		g.lastFailed = time.Now()
+		g.logger.Error("Failed to open GeoIP database, country blocking is disabled until it opens",
+			zap.String("path", g.path),
+			zap.Duration("retry_in", geoIPRetryInterval),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("%w: %w", ErrGeoIPUnavailable, err)
+	}
+	g.logger.Info("GeoIP database opened", zap.String("path", g.path))
+	g.reader = reader
+	return reader, nil
+}
+
+// countryCache caches the country of IPs
+type countryCache struct {
+	entries map[string]countryCacheEntry
+	mutex   sync.RWMutex
+}
+
+type countryCacheEntry struct {
+	country   string
+	expiresAt time.Time
+}
+
+func newCountryCache() *countryCache {
+	return &countryCache{entries: make(map[string]countryCacheEntry)}
+}
+
+func (c *countryCache) get(ip string) (string, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	entry, ok := c.entries[ip]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return "", false
+	}
+	return entry.country, true
+}
+
+func (c *countryCache) set(ip, country string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if len(c.entries) >= geoIPCacheSize {
+		c.entries = make(map[string]countryCacheEntry)
+	}
+	c.entries[ip] = countryCacheEntry{country: country, expiresAt: time.Now().Add(geoIPCacheTTL)}
+}
+
+// SetCountryLookup makes country blocking resolve countries with lookup instead of the
+// GeoIP database at GeoIPDatabasePath
+func (sm *SecurityMiddleware) SetCountryLookup(lookup CountryLookup) {
+	sm.countryLookup = lookup
+	sm.countryCache = newCountryCache()
+}
+
+// isCountryBlocked reports whether ip is located in one of BlockedCountries. Private IPs
+// are never blocked, and IPs whose country cannot be looked up are allowed.
+func (sm *SecurityMiddleware) isCountryBlocked(ip string) bool {
+	if len(sm.blockedCountries) == 0 || sm.countryLookup == nil {
+		return false
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil || sm.isPrivateIP(ip) {
+		return false
+	}
+
+	country, ok := sm.countryCache.get(parsed.String())
+	if !ok {
+		var err error
+		country, err = sm.countryLookup.Country(parsed)
+		if err != nil {
+			// An unavailable database was already reported when it failed to open
+			if errors.Is(err, ErrGeoIPUnavailable) {
+				return false
+			}
+			sm.logger.Warn("Country lookup failed, allowing request",
+				zap.String("ip", ip),
+				zap.Error(err),
+			)
+			return false
+		}
+		sm.countryCache.set(parsed.String(), country)
+	}
+
+	return sm.blockedCountries[strings.ToUpper(country)]
+}
+
+// parseBlockedCountries returns the set of upper-cased country codes
+func parseBlockedCountries(countries []string) map[string]bool {
+	blocked := make(map[string]bool, len(countries))
+	for _, country := range countries {
+		if country = strings.ToUpper(strings.TrimSpace(country)); country != "" {
+			blocked[country] = true
+		}
+	}
+	return blocked
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GinHandler returns the security middleware as gin middleware. It applies the same checks
+// and headers as Handler, but rejects requests with a JSON body naming the reason, such as
+// {"success": false, "error": "Rate limit exceeded", "reason": "rate_limited", "retry_after": 12}.
+func (sm *SecurityMiddleware) GinHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rejected := sm.screen(c.Request); rejected != nil {
+			body := gin.H{
+				"success": false,
+				"error":   rejected.message,
+				"reason":  rejected.reason,
+			}
+			if rejected.reason == RejectionRateLimited {
+				seconds := retryAfterSeconds(rejected.retryAfter)
+				c.Header("Retry-After", strconv.Itoa(seconds))
+				body["retry_after"] = seconds
+			}
+			c.AbortWithStatusJSON(rejected.status, body)
+			return
+		}
+		sm.admit(c.Writer, c.Request)
+
+		c.Next()
+	}
+}
@@ -1,22 +1,82 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"net"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
 	"go.uber.org/zap"
 )
 
+// Rejection reasons, recorded as the reason label of security_requests_rejected_total
+const (
+	// RejectionIPBlocked is a request from an IP blocked with IPBlocker.BlockIP
+	RejectionIPBlocked = "ip_blocked"
+	// RejectionIPNotAllowed is a request from an IP outside AllowedIPs
+	RejectionIPNotAllowed = "ip_not_allowed"
+	// RejectionCountryBlocked is a request from a country in BlockedCountries
+	RejectionCountryBlocked = "country_blocked"
+	// RejectionPrivateNetwork is a request from a private network when those are not allowed
+	RejectionPrivateNetwork = "private_network"
+	// RejectionRateLimited is a request over the rate limit
+	RejectionRateLimited = "rate_limited"
+	// RejectionInvalidContentType is a request with a missing or disallowed content type
+	RejectionInvalidContentType = "invalid_content_type"
+	// RejectionRequestTooLarge is a request larger than MaxRequestSize
+	RejectionRequestTooLarge = "request_too_large"
+)
+
+// rejectionReasons lists every reason so their counters exist before the first rejection
+var rejectionReasons = []string{
+	RejectionIPBlocked,
+	RejectionIPNotAllowed,
+	RejectionCountryBlocked,
+	RejectionPrivateNetwork,
+	RejectionRateLimited,
+	RejectionInvalidContentType,
+	RejectionRequestTooLarge,
+}
+
 // SecurityMiddleware provides comprehensive security features
 type SecurityMiddleware struct {
 	config      *SecurityConfig
 	logger      *zap.Logger
 	rateLimiter *RateLimiter
 	ipBlocker   *IPBlocker
+
+	// allowedIPs starts as config.AllowedIPs and is changed with AddAllowedIP and
+	// RemoveAllowedIP; "*" maps to a nil network and allows any IP
+	allowedIPs   map[string]*net.IPNet
+	allowedMutex sync.RWMutex
+
+	distributedLimiter DistributedRateLimiter
+	trustedProxies     []*net.IPNet
+	maxRequestSize     int64
+
+	blockedCountries map[string]bool
+	countryLookup    CountryLookup
+	countryCache     *countryCache
+
+	rejections metric.Int64Counter
+	rejected   map[string]*atomic.Int64
+}
+
+// DistributedRateLimiter is a rate limiter whose state is shared across instances,
+// such as cache.RedisRateLimiter
+type DistributedRateLimiter interface {
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (allowed bool, remaining int, resetAt time.Time, err error)
 }
 
 // SecurityConfig holds security middleware configuration
@@ -37,13 +97,27 @@ type SecurityConfig struct {
 		FrameOptions       string `mapstructure:"frame_options"`
 		XSSProtection      bool   `mapstructure:"xss_protection"`
 		ReferrerPolicy     string `mapstructure:"referrer_policy"`
+		// ContentSecurityPolicy is sent as Content-Security-Policy, or as
+		// Content-Security-Policy-Report-Only when ContentSecurityPolicyReportOnly is set
+		ContentSecurityPolicy           string `mapstructure:"content_security_policy"`
+		ContentSecurityPolicyReportOnly bool   `mapstructure:"content_security_policy_report_only"`
+		PermissionsPolicy               string `mapstructure:"permissions_policy"`
 	} `mapstructure:"security_headers"`
 
 	// IP restrictions
-	AllowedIPs           []string `mapstructure:"allowed_ips"`
+	AllowedIPs []string `mapstructure:"allowed_ips"`
+	// BlockedCountries lists ISO 3166-1 alpha-2 codes of countries whose requests are
+	// rejected, located with the GeoLite2 country database at GeoIPDatabasePath. Requests
+	// are allowed when the database is unavailable.
 	BlockedCountries     []string `mapstructure:"blocked_countries"`
+	GeoIPDatabasePath    string   `mapstructure:"geoip_database_path"`
 	AllowPrivateNetworks bool     `mapstructure:"allow_private_networks"`
 
+	// TrustedProxies lists the IPs and CIDR ranges of the proxies in front of the service.
+	// X-Forwarded-For and X-Real-IP are only honored when the connection comes from one
+	// of them; otherwise the client IP is the connection's remote address.
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+
 	// Audit
 	Audit struct {
 		Enabled                     bool `mapstructure:"enabled"`
@@ -62,18 +136,30 @@ type SecurityConfig struct {
 	} `mapstructure:"api_security"`
 }
 
+// Validate checks that the configuration is usable
+func (c *SecurityConfig) Validate() error {
+	_, err := parseMaxRequestSize(c.APISecurity.MaxRequestSize)
+	return err
+}
+
 // RateLimiter manages request rate limiting
 type RateLimiter struct {
 	requests map[string]*ClientRequests
 	mutex    sync.RWMutex
 }
 
-// ClientRequests tracks requests per client
+// ClientRequests is the token bucket of a client
 type ClientRequests struct {
-	count        int
-	windowStart  time.Time
-	blocked      bool
-	blockedUntil time.Time
+	tokens     float64
+	lastRefill time.Time
+	// capacity and ratePerSec are the limits the bucket was last refilled with
+	capacity   float64
+	ratePerSec float64
+}
+
+// full reports whether the bucket would be full after refilling up to now
+func (c *ClientRequests) full(now time.Time) bool {
+	return c.tokens+now.Sub(c.lastRefill).Seconds()*c.ratePerSec >= c.capacity
 }
 
 // IPBlocker manages IP-based blocking
@@ -82,13 +168,44 @@ type IPBlocker struct {
 	mutex      sync.RWMutex
 }
 
-// NewSecurityMiddleware creates a new security middleware
-func NewSecurityMiddleware(config *SecurityConfig, logger *zap.Logger) *SecurityMiddleware {
+// NewSecurityMiddleware creates a new security middleware. It fails when the configuration
+// is not usable. Rejections are counted with the global OpenTelemetry meter provider; use
+// SetMeter to record them elsewhere.
+func NewSecurityMiddleware(config *SecurityConfig, logger *zap.Logger) (*SecurityMiddleware, error) {
+	maxRequestSize, err := parseMaxRequestSize(config.APISecurity.MaxRequestSize)
+	if err != nil {
+		return nil, err
+	}
+
 	sm := &SecurityMiddleware{
 		config:      config,
 		logger:      logger,
 		rateLimiter: NewRateLimiter(),
 		ipBlocker:   NewIPBlocker(),
+		rejected:    make(map[string]*atomic.Int64, len(rejectionReasons)),
+	}
+	for _, reason := range rejectionReasons {
+		sm.rejected[reason] = &atomic.Int64{}
+	}
+	sm.trustedProxies = parseTrustedProxies(config.TrustedProxies, logger)
+	sm.maxRequestSize = maxRequestSize
+	sm.blockedCountries = parseBlockedCountries(config.BlockedCountries)
+	if len(sm.blockedCountries) > 0 {
+		if config.GeoIPDatabasePath != "" {
+			sm.SetCountryLookup(NewGeoIPCountryLookup(config.GeoIPDatabasePath, logger))
+		} else {
+			logger.Error("Blocked countries are configured without a GeoIP database, country blocking is disabled")
+		}
+	}
+	sm.allowedIPs = make(map[string]*net.IPNet, len(config.AllowedIPs))
+	for _, entry := range config.AllowedIPs {
+		if err := sm.AddAllowedIP(entry); err != nil {
+			logger.Warn("Ignoring invalid allowed IP", zap.String("ip", entry), zap.Error(err))
+		}
+	}
+	if err := sm.SetMeter(otel.Meter("backend-core/security")); err != nil {
+		logger.Warn("Failed to create security rejection counter", zap.Error(err))
+		sm.rejections, _ = noop.NewMeterProvider().Meter("").Int64Counter("")
 	}
 
 	// Initialize rate limiter cleanup
@@ -96,110 +213,274 @@ func NewSecurityMiddleware(config *SecurityConfig, logger *zap.Logger) *Security
 		go sm.startRateLimitCleanup()
 	}
 
-	return sm
+	return sm, nil
+}
+
+// Close releases the GeoIP database, if one was opened
+func (sm *SecurityMiddleware) Close() error {
+	if closer, ok := sm.countryLookup.(interface{ Close() error }); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// SetDistributedRateLimiter makes rate limiting use the given shared limiter instead of
+// the in-memory one, so limits hold across instances and restarts
+func (sm *SecurityMiddleware) SetDistributedRateLimiter(limiter DistributedRateLimiter) {
+	sm.distributedLimiter = limiter
+}
+
+// SetMeter records rejections with meter instead of the global meter provider
+func (sm *SecurityMiddleware) SetMeter(meter metric.Meter) error {
+	rejections, err := meter.Int64Counter(
+		"security_requests_rejected_total",
+		metric.WithDescription("Total number of requests rejected by the security middleware, by reason"),
+	)
+	if err != nil {
+		return err
+	}
+	sm.rejections = rejections
+	return nil
+}
+
+// AddAllowedIP adds an IP, CIDR range or "*" to the allowlist. Once the allowlist has an
+// entry, requests from IPs outside it are rejected.
+func (sm *SecurityMiddleware) AddAllowedIP(cidr string) error {
+	key, network, err := parseAllowedIP(cidr)
+	if err != nil {
+		return err
+	}
+
+	sm.allowedMutex.Lock()
+	defer sm.allowedMutex.Unlock()
+	sm.allowedIPs[key] = network
+	return nil
+}
+
+// RemoveAllowedIP removes an entry added to the allowlist, given in any notation that
+// parses to the same IP or range
+func (sm *SecurityMiddleware) RemoveAllowedIP(cidr string) {
+	key, _, err := parseAllowedIP(cidr)
+	if err != nil {
+		key = strings.TrimSpace(cidr)
+	}
+
+	sm.allowedMutex.Lock()
+	defer sm.allowedMutex.Unlock()
+	delete(sm.allowedIPs, key)
+}
+
+// AllowedIPs returns the current allowlist in CIDR notation
+func (sm *SecurityMiddleware) AllowedIPs() []string {
+	sm.allowedMutex.RLock()
+	defer sm.allowedMutex.RUnlock()
+
+	entries := make([]string, 0, len(sm.allowedIPs))
+	for key := range sm.allowedIPs {
+		entries = append(entries, key)
+	}
+	sort.Strings(entries)
+	return entries
+}
+
+// BlockIP rejects requests from ip for duration
+func (sm *SecurityMiddleware) BlockIP(ip string, duration time.Duration) error {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return fmt.Errorf("invalid IP address %q", ip)
+	}
+	if duration <= 0 {
+		return fmt.Errorf("block duration must be positive")
+	}
+	sm.ipBlocker.BlockIP(parsed.String(), duration)
+	sm.logger.Warn("IP blocked", zap.String("ip", parsed.String()), zap.Duration("duration", duration))
+	return nil
+}
+
+// UnblockIP lifts a block on ip before it expires
+func (sm *SecurityMiddleware) UnblockIP(ip string) {
+	if parsed := net.ParseIP(strings.TrimSpace(ip)); parsed != nil {
+		ip = parsed.String()
+	}
+	sm.ipBlocker.UnblockIP(ip)
+}
+
+// BlockedIPs returns the currently blocked IPs and when each block expires
+func (sm *SecurityMiddleware) BlockedIPs() map[string]time.Time {
+	return sm.ipBlocker.BlockedIPs()
+}
+
+// Rejections returns how many requests were rejected for each reason since the middleware was created
+func (sm *SecurityMiddleware) Rejections() map[string]int64 {
+	counts := make(map[string]int64, len(sm.rejected))
+	for reason, count := range sm.rejected {
+		counts[reason] = count.Load()
+	}
+	return counts
+}
+
+// reject counts a rejected request
+func (sm *SecurityMiddleware) reject(r *http.Request, reason string) {
+	sm.rejected[reason].Add(1)
+	sm.rejections.Add(r.Context(), 1, metric.WithAttributes(
+		attribute.String("reason", reason),
+	))
+}
+
+// rejection describes why a request was refused and the response to send
+type rejection struct {
+	status  int
+	reason  string
+	message string
+	// retryAfter is how long a rate limited client should wait before retrying; it is sent
+	// rounded up to at least a second
+	retryAfter time.Duration
 }
 
 // Handler returns the security middleware handler
 func (sm *SecurityMiddleware) Handler() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Apply security measures in order of priority
-
-			// 1. IP-based restrictions
-			if !sm.checkIPRestrictions(r) {
-				sm.logger.Warn("Request blocked by IP restrictions",
-					zap.String("ip", sm.getClientIP(r)),
-					zap.String("path", r.URL.Path),
-				)
-				http.Error(w, "Access denied", http.StatusForbidden)
+			if rejected := sm.screen(r); rejected != nil {
+				if rejected.reason == RejectionRateLimited {
+					w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(rejected.retryAfter)))
+				}
+				http.Error(w, rejected.message, rejected.status)
 				return
 			}
+			sm.admit(w, r)
 
-			// 2. Rate limiting
-			if sm.config.RateLimit.Enabled {
-				if !sm.checkRateLimit(r) {
-					sm.logger.Warn("Request blocked by rate limiting",
-						zap.String("ip", sm.getClientIP(r)),
-						zap.String("path", r.URL.Path),
-					)
-					http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
-					return
-				}
-			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
 
-			// 3. Content type validation
-			if sm.config.APISecurity.ValidateContentType {
-				if !sm.validateContentType(r) {
-					sm.logger.Warn("Request blocked by content type validation",
-						zap.String("ip", sm.getClientIP(r)),
-						zap.String("content_type", r.Header.Get("Content-Type")),
-					)
-					http.Error(w, "Invalid content type", http.StatusBadRequest)
-					return
-				}
+// screen applies the IP, rate limit, content type and size checks to a request, counting
+// and logging a rejection. It returns nil if the request may proceed.
+func (sm *SecurityMiddleware) screen(r *http.Request) *rejection {
+	// Apply security measures in order of priority
+
+	// 1. IP-based restrictions
+	if reason := sm.checkIPRestrictions(r); reason != "" {
+		sm.reject(r, reason)
+		sm.logger.Warn("Request blocked by IP restrictions",
+			zap.String("ip", sm.getClientIP(r)),
+			zap.String("path", r.URL.Path),
+			zap.String("reason", reason),
+		)
+		return &rejection{status: http.StatusForbidden, reason: reason, message: "Access denied"}
+	}
+
+	// 2. Rate limiting
+	if sm.config.RateLimit.Enabled {
+		if allowed, retryAfter := sm.checkRateLimit(r); !allowed {
+			sm.reject(r, RejectionRateLimited)
+			sm.logger.Warn("Request blocked by rate limiting",
+				zap.String("ip", sm.getClientIP(r)),
+				zap.String("path", r.URL.Path),
+				zap.Duration("retry_after", retryAfter),
+			)
+			return &rejection{
+				status:     http.StatusTooManyRequests,
+				reason:     RejectionRateLimited,
+				message:    "Rate limit exceeded",
+				retryAfter: retryAfter,
 			}
+		}
+	}
 
-			// 4. Request size validation
-			if !sm.validateRequestSize(r) {
-				sm.logger.Warn("Request blocked by size validation",
-					zap.String("ip", sm.getClientIP(r)),
-					zap.String("content_length", r.Header.Get("Content-Length")),
-				)
-				http.Error(w, "Request too large", http.StatusRequestEntityTooLarge)
-				return
-			}
+	// 3. Content type validation
+	if sm.config.APISecurity.ValidateContentType {
+		if !sm.validateContentType(r) {
+			sm.reject(r, RejectionInvalidContentType)
+			sm.logger.Warn("Request blocked by content type validation",
+				zap.String("ip", sm.getClientIP(r)),
+				zap.String("content_type", r.Header.Get("Content-Type")),
+			)
+			return &rejection{status: http.StatusBadRequest, reason: RejectionInvalidContentType, message: "Invalid content type"}
+		}
+	}
 
-			// 5. Add security headers
-			if sm.config.SecurityHeaders.Enabled {
-				sm.addSecurityHeaders(w)
-			}
+	// 4. Request size validation
+	if !sm.validateRequestSize(r) {
+		sm.reject(r, RejectionRequestTooLarge)
+		sm.logger.Warn("Request blocked by size validation",
+			zap.String("ip", sm.getClientIP(r)),
+			zap.String("content_length", r.Header.Get("Content-Length")),
+		)
+		return &rejection{status: http.StatusRequestEntityTooLarge, reason: RejectionRequestTooLarge, message: "Request too large"}
+	}
 
-			// 6. Log security events
-			if sm.config.Audit.Enabled {
-				sm.logSecurityEvent(r)
-			}
+	return nil
+}
 
-			next.ServeHTTP(w, r)
-		})
+// admit sets the security headers of a request that passed screen and audits it
+func (sm *SecurityMiddleware) admit(w http.ResponseWriter, r *http.Request) {
+	// 5. Add security headers
+	if sm.config.SecurityHeaders.Enabled {
+		sm.addSecurityHeaders(w)
+	}
+
+	// 6. Log security events
+	if sm.config.Audit.Enabled {
+		sm.logSecurityEvent(r)
 	}
 }
 
-// checkIPRestrictions validates IP-based access
-func (sm *SecurityMiddleware) checkIPRestrictions(r *http.Request) bool {
+// checkIPRestrictions validates IP-based access, returning the rejection reason or "" if allowed
+func (sm *SecurityMiddleware) checkIPRestrictions(r *http.Request) string {
 	clientIP := sm.getClientIP(r)
 
 	// Check if IP is explicitly blocked
-	if sm.ipBlocker.isBlocked(clientIP) {
-		return false
+	blockedKey := clientIP
+	if parsed := net.ParseIP(clientIP); parsed != nil {
+		blockedKey = parsed.String()
+	}
+	if sm.ipBlocker.isBlocked(blockedKey) {
+		return RejectionIPBlocked
 	}
 
 	// Check allowed IPs (if specified)
-	if len(sm.config.AllowedIPs) > 0 {
-		allowed := false
-		for _, allowedIP := range sm.config.AllowedIPs {
-			if sm.ipMatches(clientIP, allowedIP) {
-				allowed = true
-				break
-			}
-		}
-		if !allowed {
-			return false
-		}
+	if !sm.isAllowedIP(clientIP) {
+		return RejectionIPNotAllowed
+	}
+
+	// Check blocked countries
+	if sm.isCountryBlocked(clientIP) {
+		return RejectionCountryBlocked
 	}
 
 	// Check private networks
 	if !sm.config.AllowPrivateNetworks {
 		if sm.isPrivateIP(clientIP) {
-			return false
+			return RejectionPrivateNetwork
 		}
 	}
 
-	return true
+	return ""
 }
 
-// checkRateLimit validates rate limiting
-func (sm *SecurityMiddleware) checkRateLimit(r *http.Request) bool {
+// checkRateLimit validates rate limiting, returning how long a rejected client should wait
+// before retrying
+func (sm *SecurityMiddleware) checkRateLimit(r *http.Request) (bool, time.Duration) {
 	clientIP := sm.getClientIP(r)
+
+	if sm.distributedLimiter != nil {
+		limit := sm.config.RateLimit.RequestsPerMin + sm.config.RateLimit.BurstLimit
+		allowed, _, resetAt, err := sm.distributedLimiter.Allow(r.Context(), clientIP, limit, time.Minute)
+		if err == nil {
+			if allowed {
+				return true, 0
+			}
+			return false, time.Until(resetAt)
+		}
+		// Fall back to the local limiter rather than rejecting traffic when the shared store fails
+		sm.logger.Warn("Distributed rate limiter failed, using in-memory limiter",
+			zap.String("ip", clientIP),
+			zap.Error(err),
+		)
+	}
+
 	return sm.rateLimiter.Allow(clientIP, sm.config.RateLimit.RequestsPerMin, sm.config.RateLimit.BurstLimit)
 }
 
@@ -225,7 +506,7 @@ func (sm *SecurityMiddleware) validateContentType(r *http.Request) bool {
 
 // validateRequestSize validates request size
 func (sm *SecurityMiddleware) validateRequestSize(r *http.Request) bool {
-	if r.ContentLength > sm.parseMaxSize(sm.config.APISecurity.MaxRequestSize) {
+	if r.ContentLength > sm.maxRequestSize {
 		return false
 	}
 	return true
@@ -253,6 +534,18 @@ func (sm *SecurityMiddleware) addSecurityHeaders(w http.ResponseWriter) {
 		w.Header().Set("Referrer-Policy", sm.config.SecurityHeaders.ReferrerPolicy)
 	}
 
+	if sm.config.SecurityHeaders.ContentSecurityPolicy != "" {
+		header := "Content-Security-Policy"
+		if sm.config.SecurityHeaders.ContentSecurityPolicyReportOnly {
+			header = "Content-Security-Policy-Report-Only"
+		}
+		w.Header().Set(header, sm.config.SecurityHeaders.ContentSecurityPolicy)
+	}
+
+	if sm.config.SecurityHeaders.PermissionsPolicy != "" {
+		w.Header().Set("Permissions-Policy", sm.config.SecurityHeaders.PermissionsPolicy)
+	}
+
 	// Additional security headers
 	w.Header().Set("X-DNS-Prefetch-Control", "off")
 	w.Header().Set("X-Download-Options", "noopen")
@@ -274,38 +567,135 @@ func (sm *SecurityMiddleware) logSecurityEvent(r *http.Request) {
 
 // Helper methods
 
+// getClientIP returns the IP of the client. Forwarding headers are only read when the
+// connection comes from a trusted proxy, since anyone else can set them to any value.
 func (sm *SecurityMiddleware) getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header (for proxies/load balancers)
-	forwarded := r.Header.Get("X-Forwarded-For")
-	if forwarded != "" {
-		// Take the first IP in the chain
-		ips := strings.Split(forwarded, ",")
-		return strings.TrimSpace(ips[0])
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		peer = r.RemoteAddr
+	}
+	if !sm.isTrustedProxy(peer) {
+		return peer
+	}
+
+	// Check X-Forwarded-For header (for proxies/load balancers). Each proxy appends the
+	// address it received the request from, so walk the chain from the right and take the
+	// first address that is not one of our proxies; anything left of it may be forged.
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		ips := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(ips) - 1; i >= 0; i-- {
+			ip := strings.TrimSpace(ips[i])
+			if ip == "" {
+				continue
+			}
+			if i == 0 || !sm.isTrustedProxy(ip) {
+				return ip
+			}
+		}
 	}
 
 	// Check X-Real-IP header
-	realIP := r.Header.Get("X-Real-IP")
-	if realIP != "" {
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIP != "" {
 		return realIP
 	}
 
-	// Fall back to remote address
-	ip, _, _ := net.SplitHostPort(r.RemoteAddr)
-	return ip
+	return peer
+}
+
+// isTrustedProxy reports whether ip belongs to a configured trusted proxy
+func (sm *SecurityMiddleware) isTrustedProxy(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, proxy := range sm.trustedProxies {
+		if proxy.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultMaxRequestSize limits requests when no max request size is configured
+const defaultMaxRequestSize = 16 << 20
+
+// parseMaxRequestSize parses the configured max request size, using the default when none
+// is configured
+func parseMaxRequestSize(sizeStr string) (int64, error) {
+	if strings.TrimSpace(sizeStr) == "" {
+		return defaultMaxRequestSize, nil
+	}
+	size, err := ParseSize(sizeStr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max_request_size: %w", err)
+	}
+	return size, nil
 }
 
-func (sm *SecurityMiddleware) ipMatches(ip, pattern string) bool {
-	if pattern == "*" {
+// parseTrustedProxies parses IPs and CIDR ranges, skipping invalid entries
+func parseTrustedProxies(entries []string, logger *zap.Logger) []*net.IPNet {
+	proxies := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		proxy, err := parseIPOrCIDR(entry)
+		if err != nil {
+			logger.Warn("Ignoring invalid trusted proxy", zap.String("proxy", entry))
+			continue
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies
+}
+
+// isAllowedIP reports whether ip is on the allowlist, or the allowlist is empty
+func (sm *SecurityMiddleware) isAllowedIP(ip string) bool {
+	sm.allowedMutex.RLock()
+	defer sm.allowedMutex.RUnlock()
+
+	if len(sm.allowedIPs) == 0 {
 		return true
 	}
+	parsed := net.ParseIP(ip)
+	for _, network := range sm.allowedIPs {
+		if network == nil || parsed != nil && network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
 
-	if strings.Contains(pattern, "/") {
-		// CIDR notation
-		_, cidr, _ := net.ParseCIDR(pattern)
-		return cidr.Contains(net.ParseIP(ip))
+// parseAllowedIP parses an allowlist entry, returning the key it is stored under and its
+// network. A single IP becomes a /32 or /128 range; "*" has no network.
+func parseAllowedIP(entry string) (string, *net.IPNet, error) {
+	entry = strings.TrimSpace(entry)
+	if entry == "*" {
+		return entry, nil, nil
+	}
+	network, err := parseIPOrCIDR(entry)
+	if err != nil {
+		return "", nil, err
 	}
+	return network.String(), network, nil
+}
 
-	return ip == pattern
+// parseIPOrCIDR parses an IP, as a single-address range, or a CIDR range
+func parseIPOrCIDR(entry string) (*net.IPNet, error) {
+	if !strings.Contains(entry, "/") {
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address %q", entry)
+		}
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 8*net.IPv4len
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	_, cidr, err := net.ParseCIDR(entry)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR range %q: %w", entry, err)
+	}
+	return cidr, nil
 }
 
 func (sm *SecurityMiddleware) isPrivateIP(ip string) bool {
@@ -335,30 +725,49 @@ func (sm *SecurityMiddleware) isPrivateIP(ip string) bool {
 	return false
 }
 
-func (sm *SecurityMiddleware) parseMaxSize(sizeStr string) int64 {
-	// Parse size strings like "16MB", "1GB", etc.
-	// Simplified implementation
-	switch {
-	case strings.HasSuffix(sizeStr, "KB"):
-		return parseSize(sizeStr, 1024)
-	case strings.HasSuffix(sizeStr, "MB"):
-		return parseSize(sizeStr, 1024*1024)
-	case strings.HasSuffix(sizeStr, "GB"):
-		return parseSize(sizeStr, 1024*1024*1024)
-	default:
-		return 16 * 1024 * 1024 // Default 16MB
+// ParseSize parses a size such as "512", "512B", "1.5MB" or "2 GB" into bytes. Units are
+// binary (1KB is 1024 bytes) and case-insensitive; a number without a unit is a byte count.
+func ParseSize(sizeStr string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(sizeStr))
+
+	multiplier := float64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
 	}
+
+	num, err := strconv.ParseFloat(value, 64)
+	if err != nil || num < 0 || math.IsInf(num, 0) || math.IsNaN(num) {
+		return 0, fmt.Errorf("invalid size %q", sizeStr)
+	}
+	size := num * multiplier
+	if size >= math.MaxInt64 {
+		return 0, fmt.Errorf("size %q is too large", sizeStr)
+	}
+	return int64(size), nil
 }
 
-func parseSize(sizeStr string, multiplier int64) int64 {
-	// Remove suffix and parse number
-	numStr := strings.TrimSuffix(sizeStr, "KB")
-	numStr = strings.TrimSuffix(numStr, "MB")
-	numStr = strings.TrimSuffix(numStr, "GB")
+// sizeUnits lists the units ParseSize accepts, "B" last so it does not match "KB" and the like
+var sizeUnits = []struct {
+	suffix     string
+	multiplier float64
+}{
+	{"KB", 1 << 10},
+	{"MB", 1 << 20},
+	{"GB", 1 << 30},
+	{"B", 1},
+}
 
-	var num int64
-	fmt.Sscanf(numStr, "%d", &num)
-	return num * multiplier
+// retryAfterSeconds rounds d up to whole seconds for a Retry-After header
+func retryAfterSeconds(d time.Duration) int {
+	seconds := int((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
 }
 
 func (sm *SecurityMiddleware) startRateLimitCleanup() {
@@ -370,6 +779,9 @@ func (sm *SecurityMiddleware) startRateLimitCleanup() {
 	}
 }
 
+// clientIdleTimeout is how long a client must be idle before Cleanup may evict its bucket
+const clientIdleTimeout = 5 * time.Minute
+
 // Rate limiter implementation
 func NewRateLimiter() *RateLimiter {
 	return &RateLimiter{
@@ -377,55 +789,60 @@ func NewRateLimiter() *RateLimiter {
 	}
 }
 
-func (rl *RateLimiter) Allow(clientIP string, requestsPerMin, burstLimit int) bool {
+// Allow takes a token from the bucket of clientIP. Buckets hold up to burstLimit tokens
+// (at least one) and refill at requestsPerMin per minute, so a client can burst up to
+// burstLimit requests and then sustain requestsPerMin. A rejected request gets the time
+// until the next token is available.
+func (rl *RateLimiter) Allow(clientIP string, requestsPerMin, burstLimit int) (bool, time.Duration) {
 	rl.mutex.Lock()
 	defer rl.mutex.Unlock()
 
+	capacity := float64(burstLimit)
+	if capacity < 1 {
+		capacity = 1
+	}
+	ratePerSec := float64(requestsPerMin) / 60
+
 	now := time.Now()
 	client, exists := rl.requests[clientIP]
-
 	if !exists {
-		client = &ClientRequests{
-			count:       1,
-			windowStart: now,
-		}
+		client = &ClientRequests{tokens: capacity, lastRefill: now}
 		rl.requests[clientIP] = client
-		return true
+	} else {
+		client.tokens += now.Sub(client.lastRefill).Seconds() * ratePerSec
+		if client.tokens > capacity {
+			client.tokens = capacity
+		}
+		client.lastRefill = now
 	}
+	client.capacity = capacity
+	client.ratePerSec = ratePerSec
 
-	// Check if blocked
-	if client.blocked && now.Before(client.blockedUntil) {
-		return false
+	if client.tokens >= 1 {
+		client.tokens--
+		return true, 0
 	}
 
-	// Reset if window expired
-	if now.Sub(client.windowStart) > time.Minute {
-		client.count = 1
-		client.windowStart = now
-		client.blocked = false
-		return true
+	if ratePerSec <= 0 {
+		return false, time.Minute
 	}
-
-	// Check rate limit
-	if client.count >= requestsPerMin+burstLimit {
-		// Block for 1 minute
-		client.blocked = true
-		client.blockedUntil = now.Add(time.Minute)
-		return false
-	}
-
-	client.count++
-	return true
+	return false, time.Duration((1 - client.tokens) / ratePerSec * float64(time.Second))
 }
 
+// Cleanup evicts the buckets of clients idle for more than clientIdleTimeout. A bucket is
+// only evicted once it has refilled, since a new bucket starts full and evicting a drained
+// one would hand the client a fresh burst.
 func (rl *RateLimiter) Cleanup() {
 	rl.mutex.Lock()
 	defer rl.mutex.Unlock()
 
-	now := time.Now()
+	rl.cleanup(time.Now())
+}
+
+// cleanup evicts idle, refilled buckets. Callers must hold mutex.
+func (rl *RateLimiter) cleanup(now time.Time) {
 	for ip, client := range rl.requests {
-		// Remove old entries (older than 5 minutes)
-		if now.Sub(client.windowStart) > 5*time.Minute {
+		if now.Sub(client.lastRefill) > clientIdleTimeout && client.full(now) {
 			delete(rl.requests, ip)
 		}
 	}
@@ -466,3 +883,26 @@ func (ib *IPBlocker) BlockIP(ip string, duration time.Duration) {
 
 	ib.blockedIPs[ip] = time.Now().Add(duration)
 }
+
+// UnblockIP removes the block on ip
+func (ib *IPBlocker) UnblockIP(ip string) {
+	ib.mutex.Lock()
+	defer ib.mutex.Unlock()
+
+	delete(ib.blockedIPs, ip)
+}
+
+// BlockedIPs returns the IPs whose block has not expired and when each expires
+func (ib *IPBlocker) BlockedIPs() map[string]time.Time {
+	ib.mutex.RLock()
+	defer ib.mutex.RUnlock()
+
+	now := time.Now()
+	blocked := make(map[string]time.Time, len(ib.blockedIPs))
+	for ip, unblockTime := range ib.blockedIPs {
+		if now.Before(unblockTime) {
+			blocked[ip] = unblockTime
+		}
+	}
+	return blocked
+}
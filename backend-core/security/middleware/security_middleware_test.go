@@ -0,0 +1,369 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.uber.org/zap"
+)
+
+// reasonMeter records the reason label of every rejection counted with it
+type reasonMeter struct {
+	noop.Meter
+	reasons []string
+}
+
+func (m *reasonMeter) Int64Counter(string, ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	return &reasonCounter{meter: m}, nil
+}
+
+type reasonCounter struct {
+	noop.Int64Counter
+	meter *reasonMeter
+}
+
+func (c *reasonCounter) Add(_ context.Context, _ int64, options ...metric.AddOption) {
+	attrs := metric.NewAddConfig(options).Attributes()
+	if reason, ok := attrs.Value(attribute.Key("reason")); ok {
+		c.meter.reasons = append(c.meter.reasons, reason.AsString())
+	}
+}
+
+func newTestSecurityMiddleware(t *testing.T, configure func(*SecurityConfig)) (*SecurityMiddleware, *reasonMeter) {
+	t.Helper()
+
+	config := &SecurityConfig{AllowPrivateNetworks: true}
+	config.APISecurity.MaxRequestSize = "1KB"
+	if configure != nil {
+		configure(config)
+	}
+	sm, err := NewSecurityMiddleware(config, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewSecurityMiddleware: %v", err)
+	}
+	meter := &reasonMeter{}
+	if err := sm.SetMeter(meter); err != nil {
+		t.Fatalf("SetMeter: %v", err)
+	}
+	return sm, meter
+}
+
+func serve(sm *SecurityMiddleware, r *http.Request) int {
+	handler := sm.Handler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w.Code
+}
+
+func TestRejectionsAreCountedByReason(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*SecurityConfig)
+		setup     func(t *testing.T, sm *SecurityMiddleware)
+		request   func() *http.Request
+		// requests is how many times request is sent; only the last is expected to be rejected
+		requests int
+		status   int
+		reason   string
+	}{
+		{
+			name: "blocked IP",
+			setup: func(t *testing.T, sm *SecurityMiddleware) {
+				if err := sm.BlockIP("192.0.2.1", time.Minute); err != nil {
+					t.Fatalf("BlockIP: %v", err)
+				}
+			},
+			request:  func() *http.Request { return httptest.NewRequest(http.MethodGet, "/", nil) },
+			requests: 1,
+			status:   http.StatusForbidden,
+			reason:   RejectionIPBlocked,
+		},
+		{
+			name: "rate limited",
+			configure: func(c *SecurityConfig) {
+				c.RateLimit.Enabled = true
+				c.RateLimit.RequestsPerMin = 1
+				c.RateLimit.BurstLimit = 1
+				c.RateLimit.CleanupInterval = time.Minute
+			},
+			request:  func() *http.Request { return httptest.NewRequest(http.MethodGet, "/", nil) },
+			requests: 2,
+			status:   http.StatusTooManyRequests,
+			reason:   RejectionRateLimited,
+		},
+		{
+			name: "invalid content type",
+			configure: func(c *SecurityConfig) {
+				c.APISecurity.ValidateContentType = true
+				c.APISecurity.AllowedContentTypes = []string{"application/json"}
+			},
+			request: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("a=b"))
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				return r
+			},
+			requests: 1,
+			status:   http.StatusBadRequest,
+			reason:   RejectionInvalidContentType,
+		},
+		{
+			name: "request too large",
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat("x", 2048)))
+			},
+			requests: 1,
+			status:   http.StatusRequestEntityTooLarge,
+			reason:   RejectionRequestTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm, meter := newTestSecurityMiddleware(t, tt.configure)
+			if tt.setup != nil {
+				tt.setup(t, sm)
+			}
+
+			for i := 1; i < tt.requests; i++ {
+				if status := serve(sm, tt.request()); status != http.StatusOK {
+					t.Fatalf("request %d: expected 200, got %d", i, status)
+				}
+			}
+			if status := serve(sm, tt.request()); status != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, status)
+			}
+
+			for reason, count := range sm.Rejections() {
+				want := int64(0)
+				if reason == tt.reason {
+					want = 1
+				}
+				if count != want {
+					t.Errorf("Rejections()[%q] = %d, want %d", reason, count, want)
+				}
+			}
+			if len(meter.reasons) != 1 || meter.reasons[0] != tt.reason {
+				t.Errorf("expected the counter to record reason %q once, got %v", tt.reason, meter.reasons)
+			}
+		})
+	}
+}
+
+func TestAdmittedRequestsAreNotCounted(t *testing.T) {
+	sm, meter := newTestSecurityMiddleware(t, nil)
+
+	if status := serve(sm, httptest.NewRequest(http.MethodGet, "/", nil)); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	for reason, count := range sm.Rejections() {
+		if count != 0 {
+			t.Errorf("Rejections()[%q] = %d, want 0", reason, count)
+		}
+	}
+	if len(meter.reasons) != 0 {
+		t.Errorf("expected no rejections recorded, got %v", meter.reasons)
+	}
+}
+
+func TestGetClientIPOnlyTrustsConfiguredProxies(t *testing.T) {
+	sm, _ := newTestSecurityMiddleware(t, func(c *SecurityConfig) {
+		c.TrustedProxies = []string{"10.0.0.0/24", "172.16.0.5"}
+	})
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		realIP       string
+		expectedIP   string
+	}{
+		{
+			name:       "direct client",
+			remoteAddr: "203.0.113.7:5000",
+			expectedIP: "203.0.113.7",
+		},
+		{
+			name:         "untrusted peer spoofing X-Forwarded-For",
+			remoteAddr:   "203.0.113.7:5000",
+			forwardedFor: []string{"198.51.100.1"},
+			expectedIP:   "203.0.113.7",
+		},
+		{
+			name:       "untrusted peer spoofing X-Real-IP",
+			remoteAddr: "203.0.113.7:5000",
+			realIP:     "198.51.100.1",
+			expectedIP: "203.0.113.7",
+		},
+		{
+			name:         "trusted proxy chain",
+			remoteAddr:   "10.0.0.2:5000",
+			forwardedFor: []string{"198.51.100.1, 172.16.0.5"},
+			expectedIP:   "198.51.100.1",
+		},
+		{
+			name:         "trusted proxy chain across headers",
+			remoteAddr:   "10.0.0.2:5000",
+			forwardedFor: []string{"198.51.100.1", "172.16.0.5"},
+			expectedIP:   "198.51.100.1",
+		},
+		{
+			name:         "forged entry left of the real client",
+			remoteAddr:   "10.0.0.2:5000",
+			forwardedFor: []string{"192.0.2.99, 198.51.100.1, 172.16.0.5"},
+			expectedIP:   "198.51.100.1",
+		},
+		{
+			name:       "trusted proxy with X-Real-IP",
+			remoteAddr: "10.0.0.2:5000",
+			realIP:     "198.51.100.1",
+			expectedIP: "198.51.100.1",
+		},
+		{
+			name:       "trusted proxy without forwarding headers",
+			remoteAddr: "10.0.0.2:5000",
+			expectedIP: "10.0.0.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwardedFor {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			if ip := sm.getClientIP(r); ip != tt.expectedIP {
+				t.Errorf("expected client IP %s, got %s", tt.expectedIP, ip)
+			}
+		})
+	}
+}
+
+func TestSpoofedForwardingHeaderDoesNotBypassBlock(t *testing.T) {
+	sm, _ := newTestSecurityMiddleware(t, nil)
+	if err := sm.BlockIP("203.0.113.7", time.Minute); err != nil {
+		t.Fatalf("BlockIP: %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "203.0.113.7:5000"
+	r.Header.Set("X-Forwarded-For", "198.51.100.1")
+	r.Header.Set("X-Real-IP", "198.51.100.1")
+
+	if status := serve(sm, r); status != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", status)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"0":         0,
+		"1048576":   1 << 20,
+		"512B":      512,
+		"512 b":     512,
+		"1KB":       1 << 10,
+		"1.5MB":     3 << 19,
+		" 2 gb ":    2 << 30,
+		"0.5kb":     512,
+		"1e3":       1000,
+		"16MB":      defaultMaxRequestSize,
+		"1.0000001": 1,
+	}
+
+	for input, want := range tests {
+		got, err := ParseSize(input)
+		if err != nil {
+			t.Errorf("ParseSize(%q) error = %v", input, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseSize(%q) = %d, want %d", input, got, want)
+		}
+	}
+}
+
+func TestParseSizeRejectsMalformedSizes(t *testing.T) {
+	for _, input := range []string{"", "MB", "ten", "-1MB", "1TB", "1.5.5KB", "1 K B", "NaN", "Inf", "1e10GB"} {
+		if got, err := ParseSize(input); err == nil {
+			t.Errorf("ParseSize(%q) = %d, want an error", input, got)
+		}
+	}
+}
+
+func TestSecurityConfigValidateReportsMalformedMaxRequestSize(t *testing.T) {
+	config := &SecurityConfig{}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() with no max request size error = %v", err)
+	}
+
+	config.APISecurity.MaxRequestSize = "1.5MB"
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() with %q error = %v", config.APISecurity.MaxRequestSize, err)
+	}
+
+	config.APISecurity.MaxRequestSize = "lots"
+	if err := config.Validate(); err == nil {
+		t.Errorf("Validate() with %q error = nil, want an error", config.APISecurity.MaxRequestSize)
+	}
+}
+
+func TestNewSecurityMiddlewareRejectsMalformedMaxRequestSize(t *testing.T) {
+	config := &SecurityConfig{}
+	config.APISecurity.MaxRequestSize = "lots"
+	if sm, err := NewSecurityMiddleware(config, zap.NewNop()); err == nil {
+		t.Errorf("NewSecurityMiddleware() = %v, want an error", sm)
+	}
+}
+
+func TestMaxRequestSizeAppliesParsedSize(t *testing.T) {
+	tests := map[string]int64{
+		"":       defaultMaxRequestSize,
+		"2048":   2048,
+		"0.25KB": 256,
+	}
+
+	for size, want := range tests {
+		sm, _ := newTestSecurityMiddleware(t, func(config *SecurityConfig) {
+			config.APISecurity.MaxRequestSize = size
+		})
+		if sm.maxRequestSize != want {
+			t.Errorf("max request size %q = %d bytes, want %d", size, sm.maxRequestSize, want)
+		}
+	}
+}
+
+func TestRateLimiterCleanupKeepsBucketsUntilRefilled(t *testing.T) {
+	rl := NewRateLimiter()
+	// A burst of 10 refilling at 1 token per minute, drained by 10 requests
+	for i := 0; i < 10; i++ {
+		rl.Allow("10.0.0.1", 1, 10)
+	}
+	if allowed, _ := rl.Allow("10.0.0.1", 1, 10); allowed {
+		t.Fatal("request beyond the burst was allowed")
+	}
+	lastRefill := rl.requests["10.0.0.1"].lastRefill
+
+	// Idle past the timeout but only 6 of 10 tokens back: evicting would reset the limit
+	rl.cleanup(lastRefill.Add(6 * time.Minute))
+	if _, ok := rl.requests["10.0.0.1"]; !ok {
+		t.Fatal("drained bucket was evicted before it refilled")
+	}
+
+	rl.cleanup(lastRefill.Add(11 * time.Minute))
+	if _, ok := rl.requests["10.0.0.1"]; ok {
+		t.Error("idle, refilled bucket was not evicted")
+	}
+}
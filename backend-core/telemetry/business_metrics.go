@@ -5,16 +5,20 @@ import (
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type contextAwareMetrics interface {
 	RecordHTTPRequest(ctx context.Context, method, path, status string, duration float64)
 	RecordHTTPError(ctx context.Context, method, path, status string)
+	RecordGRPCRequest(ctx context.Context, method, code string, duration float64)
+	RecordGRPCError(ctx context.Context, method, code string)
 	RecordDBOperation(ctx context.Context, operation, table string, duration float64)
 	SetDBConnectionsActive(ctx context.Context, count float64)
 	RecordCacheHit(ctx context.Context, cacheType, key string)
 	RecordCacheMiss(ctx context.Context, cacheType, key string)
 	RecordCacheOperation(ctx context.Context, operation, cacheType string, duration float64)
+	RecordCacheTimeout(ctx context.Context, cacheType, operation string)
 	RecordKafkaMessageProduced(ctx context.Context, topic string, duration float64)
 	RecordKafkaMessageConsumed(ctx context.Context, topic string)
 	RecordUserCreation(ctx context.Context, username, email string)
@@ -44,16 +48,39 @@ type ContextlessMetrics interface {
 	SetGoroutinesCount(count int64)
 }
 
+// GRPCMetrics records gRPC server calls. It is kept apart from ContextlessMetrics so that
+// implementations without gRPC metrics need not provide it.
+type GRPCMetrics interface {
+	RecordGRPCRequest(method, code string, duration float64)
+	RecordGRPCError(method, code string)
+}
+
+// CacheTimeoutMetrics records cache operations abandoned because the cache did not answer in
+// time. It is kept apart from ContextlessMetrics so that existing implementations need not
+// provide it.
+type CacheTimeoutMetrics interface {
+	RecordCacheTimeout(cacheType, operation string)
+}
+
+// HTTPExemplarRecorder is implemented by ContextlessMetrics that can link a recorded HTTP
+// request duration to the trace the request was served in, e.g. as a Prometheus exemplar.
+type HTTPExemplarRecorder interface {
+	RecordHTTPRequestWithTraceID(method, path, status string, duration float64, traceID string)
+}
+
 // BusinessMetrics provides business-specific metrics and bridges context-aware and context-less consumers.
 type BusinessMetrics struct {
 	recorder contextAwareMetrics
 
 	HTTPRequestCounter    ContextlessMetrics
 	HTTPErrorCounter      ContextlessMetrics
+	GRPCRequestCounter    GRPCMetrics
+	GRPCErrorCounter      GRPCMetrics
 	DBOperationCounter    ContextlessMetrics
 	CacheHitCounter       ContextlessMetrics
 	CacheMissCounter      ContextlessMetrics
 	CacheOperationCounter ContextlessMetrics
+	CacheTimeoutCounter   CacheTimeoutMetrics
 	KafkaMessageCounter   ContextlessMetrics
 	UserCreationCounter   ContextlessMetrics
 	UserRetrievalCounter  ContextlessMetrics
@@ -80,10 +107,13 @@ func NewBusinessMetrics(telemetry *Telemetry) (*BusinessMetrics, error) {
 		recorder:              recorder,
 		HTTPRequestCounter:    adapter,
 		HTTPErrorCounter:      adapter,
+		GRPCRequestCounter:    adapter,
+		GRPCErrorCounter:      adapter,
 		DBOperationCounter:    adapter,
 		CacheHitCounter:       adapter,
 		CacheMissCounter:      adapter,
 		CacheOperationCounter: adapter,
+		CacheTimeoutCounter:   adapter,
 		KafkaMessageCounter:   adapter,
 		UserCreationCounter:   adapter,
 		UserRetrievalCounter:  adapter,
@@ -99,7 +129,8 @@ func NewNoopBusinessMetrics() *BusinessMetrics {
 	return &BusinessMetrics{}
 }
 
-// RecordHTTPRequest records HTTP request metrics.
+// RecordHTTPRequest records HTTP request metrics. When ctx carries a sampled span, the
+// duration is linked to its trace if the recorder supports it.
 func (bm *BusinessMetrics) RecordHTTPRequest(ctx context.Context, method, path, status string, duration float64) {
 	if bm == nil {
 		return
@@ -108,9 +139,17 @@ func (bm *BusinessMetrics) RecordHTTPRequest(ctx context.Context, method, path,
 		bm.recorder.RecordHTTPRequest(ctx, method, path, status, duration)
 		return
 	}
-	if bm.HTTPRequestCounter != nil {
-		bm.HTTPRequestCounter.RecordHTTPRequest(method, path, status, duration)
+	if bm.HTTPRequestCounter == nil {
+		return
+	}
+	// Only sampled traces are exported, so only they are worth linking to
+	if recorder, ok := bm.HTTPRequestCounter.(HTTPExemplarRecorder); ok {
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() && sc.IsSampled() {
+			recorder.RecordHTTPRequestWithTraceID(method, path, status, duration, sc.TraceID().String())
+			return
+		}
 	}
+	bm.HTTPRequestCounter.RecordHTTPRequest(method, path, status, duration)
 }
 
 // RecordHTTPError records HTTP error metrics.
@@ -127,6 +166,35 @@ func (bm *BusinessMetrics) RecordHTTPError(ctx context.Context, method, path, st
 	}
 }
 
+// RecordGRPCRequest records a gRPC server call. method is the full method name and code the
+// status code name, e.g. "OK".
+func (bm *BusinessMetrics) RecordGRPCRequest(ctx context.Context, method, code string, duration float64) {
+	if bm == nil {
+		return
+	}
+	if bm.recorder != nil {
+		bm.recorder.RecordGRPCRequest(ctx, method, code, duration)
+		return
+	}
+	if bm.GRPCRequestCounter != nil {
+		bm.GRPCRequestCounter.RecordGRPCRequest(method, code, duration)
+	}
+}
+
+// RecordGRPCError records a gRPC server call that ended with a status other than OK.
+func (bm *BusinessMetrics) RecordGRPCError(ctx context.Context, method, code string) {
+	if bm == nil {
+		return
+	}
+	if bm.recorder != nil {
+		bm.recorder.RecordGRPCError(ctx, method, code)
+		return
+	}
+	if bm.GRPCErrorCounter != nil {
+		bm.GRPCErrorCounter.RecordGRPCError(method, code)
+	}
+}
+
 // RecordDBOperation records database operation metrics.
 func (bm *BusinessMetrics) RecordDBOperation(ctx context.Context, operation, table string, duration float64) {
 	if bm == nil {
@@ -197,6 +265,21 @@ func (bm *BusinessMetrics) RecordCacheOperation(ctx context.Context, operation,
 	}
 }
 
+// RecordCacheTimeout records a cache operation abandoned because the cache did not answer in
+// time, e.g. while the service fell back to its source of truth.
+func (bm *BusinessMetrics) RecordCacheTimeout(ctx context.Context, cacheType, operation string) {
+	if bm == nil {
+		return
+	}
+	if bm.recorder != nil {
+		bm.recorder.RecordCacheTimeout(ctx, cacheType, operation)
+		return
+	}
+	if bm.CacheTimeoutCounter != nil {
+		bm.CacheTimeoutCounter.RecordCacheTimeout(cacheType, operation)
+	}
+}
+
 // RecordKafkaMessageProduced records Kafka publish metrics.
 func (bm *BusinessMetrics) RecordKafkaMessageProduced(ctx context.Context, topic string, duration float64) {
 	if bm == nil {
@@ -225,11 +308,13 @@ func (bm *BusinessMetrics) RecordKafkaMessageConsumed(ctx context.Context, topic
 	}
 }
 
-// RecordUserCreation records user creation metrics.
+// RecordUserCreation records user creation metrics. The counter has no per-user labels;
+// the username is set on the span in ctx instead, and the email is not recorded.
 func (bm *BusinessMetrics) RecordUserCreation(ctx context.Context, username, email string) {
 	if bm == nil {
 		return
 	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("user.name", username))
 	if bm.recorder != nil {
 		bm.recorder.RecordUserCreation(ctx, username, email)
 		return
@@ -239,11 +324,13 @@ func (bm *BusinessMetrics) RecordUserCreation(ctx context.Context, username, ema
 	}
 }
 
-// RecordUserRetrieval records user retrieval metrics.
+// RecordUserRetrieval records user retrieval metrics. The counter has no per-user labels;
+// the user ID is set on the span in ctx instead.
 func (bm *BusinessMetrics) RecordUserRetrieval(ctx context.Context, userID string) {
 	if bm == nil {
 		return
 	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("user.id", userID))
 	if bm.recorder != nil {
 		bm.recorder.RecordUserRetrieval(ctx, userID)
 		return
@@ -253,11 +340,13 @@ func (bm *BusinessMetrics) RecordUserRetrieval(ctx context.Context, userID strin
 	}
 }
 
-// RecordUserActivation records user activation metrics.
+// RecordUserActivation records user activation metrics. The counter has no per-user labels;
+// the user ID is set on the span in ctx instead.
 func (bm *BusinessMetrics) RecordUserActivation(ctx context.Context, userID string) {
 	if bm == nil {
 		return
 	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("user.id", userID))
 	if bm.recorder != nil {
 		bm.recorder.RecordUserActivation(ctx, userID)
 		return
@@ -327,6 +416,20 @@ func (a *contextlessAdapter) RecordHTTPError(method, path, status string) {
 	a.recorder.RecordHTTPError(context.Background(), method, path, status)
 }
 
+func (a *contextlessAdapter) RecordGRPCRequest(method, code string, duration float64) {
+	if a == nil || a.recorder == nil {
+		return
+	}
+	a.recorder.RecordGRPCRequest(context.Background(), method, code, duration)
+}
+
+func (a *contextlessAdapter) RecordGRPCError(method, code string) {
+	if a == nil || a.recorder == nil {
+		return
+	}
+	a.recorder.RecordGRPCError(context.Background(), method, code)
+}
+
 func (a *contextlessAdapter) RecordDBOperation(operation, table string, duration float64) {
 	if a == nil || a.recorder == nil {
 		return
@@ -362,6 +465,13 @@ func (a *contextlessAdapter) RecordCacheOperation(operation, cacheType string, d
 	a.recorder.RecordCacheOperation(context.Background(), operation, cacheType, duration)
 }
 
+func (a *contextlessAdapter) RecordCacheTimeout(cacheType, operation string) {
+	if a == nil || a.recorder == nil {
+		return
+	}
+	a.recorder.RecordCacheTimeout(context.Background(), cacheType, operation)
+}
+
 func (a *contextlessAdapter) RecordKafkaMessageProduced(topic string, duration float64) {
 	if a == nil || a.recorder == nil {
 		return
@@ -423,6 +533,10 @@ type otelBusinessMetrics struct {
 	httpRequestDuration metric.Float64Histogram
 	httpErrorsTotal     metric.Int64Counter
 
+	grpcRequestsTotal   metric.Int64Counter
+	grpcRequestDuration metric.Float64Histogram
+	grpcErrorsTotal     metric.Int64Counter
+
 	dbOperationsTotal   metric.Int64Counter
 	dbOperationDuration metric.Float64Histogram
 	dbConnectionsActive metric.Float64Gauge
@@ -431,6 +545,7 @@ type otelBusinessMetrics struct {
 	cacheMissesTotal       metric.Int64Counter
 	cacheOperationsTotal   metric.Int64Counter
 	cacheOperationDuration metric.Float64Histogram
+	cacheTimeoutsTotal     metric.Int64Counter
 
 	kafkaMessagesProduced metric.Int64Counter
 	kafkaMessagesConsumed metric.Int64Counter
@@ -472,6 +587,30 @@ func newOTELBusinessMetrics(telemetry *Telemetry) (*otelBusinessMetrics, error)
 		return nil, err
 	}
 
+	grpcRequestsTotal, err := meter.Int64Counter(
+		"grpc_server_requests_total",
+		metric.WithDescription("Total number of gRPC server calls"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	grpcRequestDuration, err := meter.Float64Histogram(
+		"grpc_server_request_duration_seconds",
+		metric.WithDescription("gRPC server call duration in seconds"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	grpcErrorsTotal, err := meter.Int64Counter(
+		"grpc_server_errors_total",
+		metric.WithDescription("Total number of gRPC server calls that did not end with OK"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	dbOperationsTotal, err := meter.Int64Counter(
 		"db_operations_total",
 		metric.WithDescription("Total number of database operations"),
@@ -528,6 +667,14 @@ func newOTELBusinessMetrics(telemetry *Telemetry) (*otelBusinessMetrics, error)
 		return nil, err
 	}
 
+	cacheTimeoutsTotal, err := meter.Int64Counter(
+		"cache_timeouts_total",
+		metric.WithDescription("Total number of cache operations abandoned after timing out"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	kafkaMessagesProduced, err := meter.Int64Counter(
 		"kafka_messages_produced_total",
 		metric.WithDescription("Total number of Kafka messages produced"),
@@ -604,6 +751,9 @@ func newOTELBusinessMetrics(telemetry *Telemetry) (*otelBusinessMetrics, error)
 		httpRequestsTotal:      httpRequestsTotal,
 		httpRequestDuration:    httpRequestDuration,
 		httpErrorsTotal:        httpErrorsTotal,
+		grpcRequestsTotal:      grpcRequestsTotal,
+		grpcRequestDuration:    grpcRequestDuration,
+		grpcErrorsTotal:        grpcErrorsTotal,
 		dbOperationsTotal:      dbOperationsTotal,
 		dbOperationDuration:    dbOperationDuration,
 		dbConnectionsActive:    dbConnectionsActive,
@@ -611,6 +761,7 @@ func newOTELBusinessMetrics(telemetry *Telemetry) (*otelBusinessMetrics, error)
 		cacheMissesTotal:       cacheMissesTotal,
 		cacheOperationsTotal:   cacheOperationsTotal,
 		cacheOperationDuration: cacheOperationDuration,
+		cacheTimeoutsTotal:     cacheTimeoutsTotal,
 		kafkaMessagesProduced:  kafkaMessagesProduced,
 		kafkaMessagesConsumed:  kafkaMessagesConsumed,
 		kafkaPublishDuration:   kafkaPublishDuration,
@@ -629,6 +780,9 @@ func (bm *otelBusinessMetrics) RecordHTTPRequest(ctx context.Context, method, pa
 		attribute.String("path", path),
 		attribute.String("status", status),
 	))
+	// The trace ID is deliberately not an attribute, which would create a time series per
+	// request. Recording with ctx instead lets the SDK keep the trace and span IDs of a
+	// sampled span as an exemplar of the bucket; without an active span nothing is kept.
 	bm.httpRequestDuration.Record(ctx, duration, metric.WithAttributes(
 		attribute.String("method", method),
 		attribute.String("path", path),
@@ -643,6 +797,22 @@ func (bm *otelBusinessMetrics) RecordHTTPError(ctx context.Context, method, path
 	))
 }
 
+func (bm *otelBusinessMetrics) RecordGRPCRequest(ctx context.Context, method, code string, duration float64) {
+	attrs := metric.WithAttributes(
+		attribute.String("method", method),
+		attribute.String("code", code),
+	)
+	bm.grpcRequestsTotal.Add(ctx, 1, attrs)
+	bm.grpcRequestDuration.Record(ctx, duration, attrs)
+}
+
+func (bm *otelBusinessMetrics) RecordGRPCError(ctx context.Context, method, code string) {
+	bm.grpcErrorsTotal.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("method", method),
+		attribute.String("code", code),
+	))
+}
+
 func (bm *otelBusinessMetrics) RecordDBOperation(ctx context.Context, operation, table string, duration float64) {
 	bm.dbOperationsTotal.Add(ctx, 1, metric.WithAttributes(
 		attribute.String("operation", operation),
@@ -691,6 +861,13 @@ func (bm *otelBusinessMetrics) RecordCacheOperation(ctx context.Context, operati
 	))
 }
 
+func (bm *otelBusinessMetrics) RecordCacheTimeout(ctx context.Context, cacheType, operation string) {
+	bm.cacheTimeoutsTotal.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("cache_type", cacheType),
+		attribute.String("operation", operation),
+	))
+}
+
 func (bm *otelBusinessMetrics) RecordKafkaMessageProduced(ctx context.Context, topic string, duration float64) {
 	bm.kafkaMessagesProduced.Add(ctx, 1, metric.WithAttributes(
 		attribute.String("topic", topic),
@@ -706,23 +883,19 @@ func (bm *otelBusinessMetrics) RecordKafkaMessageConsumed(ctx context.Context, t
 	))
 }
 
+// User identifiers are never metric attributes: one time series per user would grow
+// without bound and copy personal data into the metrics backend
+
 func (bm *otelBusinessMetrics) RecordUserCreation(ctx context.Context, username, email string) {
-	bm.userCreationsTotal.Add(ctx, 1, metric.WithAttributes(
-		attribute.String("username", username),
-		attribute.String("email", email),
-	))
+	bm.userCreationsTotal.Add(ctx, 1)
 }
 
 func (bm *otelBusinessMetrics) RecordUserRetrieval(ctx context.Context, userID string) {
-	bm.userRetrievalsTotal.Add(ctx, 1, metric.WithAttributes(
-		attribute.String("user_id", userID),
-	))
+	bm.userRetrievalsTotal.Add(ctx, 1)
 }
 
 func (bm *otelBusinessMetrics) RecordUserActivation(ctx context.Context, userID string) {
-	bm.userActivationsTotal.Add(ctx, 1, metric.WithAttributes(
-		attribute.String("user_id", userID),
-	))
+	bm.userActivationsTotal.Add(ctx, 1)
 }
 
 func (bm *otelBusinessMetrics) SetMemoryUsage(ctx context.Context, bytes float64) {
@@ -0,0 +1,72 @@
+package telemetry
+
+import (
+	"context"
+	"runtime"
+	"time"
+)
+
+// DefaultRuntimeCollectorInterval is the collection interval used when none is given
+const DefaultRuntimeCollectorInterval = 15 * time.Second
+
+// StartRuntimeCollector records the process memory usage, CPU usage and goroutine count
+// with bm every interval, in a background goroutine, until ctx is cancelled.
+//
+// Memory usage is the memory the Go runtime holds from the OS. CPU usage is the process
+// CPU time over the interval as a percentage of all CPUs, so 100 means every CPU was busy;
+// it is not recorded on platforms where process CPU time cannot be read.
+func StartRuntimeCollector(ctx context.Context, bm *BusinessMetrics, interval time.Duration) {
+	if bm == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = DefaultRuntimeCollectorInterval
+	}
+
+	c := &runtimeCollector{bm: bm}
+	c.collect(ctx)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.collect(ctx)
+			}
+		}
+	}()
+}
+
+// runtimeCollector keeps the previous CPU sample so usage can be computed per interval
+type runtimeCollector struct {
+	bm *BusinessMetrics
+
+	lastCPUTime time.Duration
+	lastSample  time.Time
+}
+
+func (c *runtimeCollector) collect(ctx context.Context) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	c.bm.SetMemoryUsage(ctx, float64(mem.Sys-mem.HeapReleased))
+	c.bm.SetGoroutinesCount(ctx, int64(runtime.NumGoroutine()))
+
+	cpuTime, ok := processCPUTime()
+	if !ok {
+		return
+	}
+	now := time.Now()
+	// The first sample only sets the baseline
+	if !c.lastSample.IsZero() {
+		if elapsed := now.Sub(c.lastSample); elapsed > 0 {
+			percent := float64(cpuTime-c.lastCPUTime) / float64(elapsed) / float64(runtime.NumCPU()) * 100
+			c.bm.SetCPUUsage(ctx, percent)
+		}
+	}
+	c.lastCPUTime = cpuTime
+	c.lastSample = now
+}
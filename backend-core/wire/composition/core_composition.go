@@ -1,11 +1,14 @@
 package composition
 
 import (
+	"context"
+
 	"backend-core/cache/decorators"
 	"backend-core/config"
 	"backend-core/database"
 	"backend-core/logging"
 	"backend-core/monitoring"
+	"backend-core/scheduler"
 	"backend-core/security"
 	"backend-core/telemetry"
 	"backend-core/wire/providers"
@@ -23,6 +26,7 @@ type CoreComposition struct {
 	MonitoringManager *monitoring.MonitoringManager
 	Telemetry         telemetry.TelemetryInterface
 	BusinessMetrics   *telemetry.BusinessMetrics
+	Scheduler         *scheduler.Scheduler
 
 	// Factories
 	RepositoryFactory *providers.RepositoryFactory
@@ -46,8 +50,8 @@ func ComposeCoreInfrastructure() *CoreComposition {
 	database := providers.DatabaseProvider(config)
 
 	// 4. Create cache
-	cacheFactory := providers.CacheDecoratorFactoryProvider()
-	cacheDecorator := providers.CacheDecoratorProvider(cacheFactory)
+	cacheFactory := providers.CacheDecoratorFactoryProvider(logger)
+	cacheDecorator := providers.CacheDecoratorProvider(cacheFactory, config)
 
 	// 5. Create security manager
 	securityManager := providers.SecurityManagerProvider()
@@ -62,13 +66,20 @@ func ComposeCoreInfrastructure() *CoreComposition {
 	// 8. Create business metrics
 	businessMetrics := &telemetry.BusinessMetrics{}
 
-	// 9. Create factories
+	// 9. Create and start the job scheduler, locking jobs in the cache's Redis server;
+	// jobs registered later start right away
+	jobScheduler := providers.SchedulerProvider(cacheDecorator, logger)
+	if jobScheduler != nil {
+		jobScheduler.Start()
+	}
+
+	// 10. Create factories
 	repositoryFactory := providers.RepositoryFactoryProvider(database, cacheDecorator)
 	serviceFactory := providers.ServiceFactoryProvider(repositoryFactory, logger)
 	handlerFactory := providers.HandlerFactoryProvider(serviceFactory, logger)
 	middlewareFactory := providers.MiddlewareFactoryProvider(cacheDecorator, securityManager, monitoringManager, logger)
 
-	// 10. Create base application (with placeholder HTTP server and router)
+	// 11. Create base application (with placeholder HTTP server and router)
 	baseApplication := providers.BaseApplicationProvider(
 		logger,
 		database,
@@ -92,6 +103,7 @@ func ComposeCoreInfrastructure() *CoreComposition {
 		MonitoringManager: monitoringManager,
 		Telemetry:         telemetryInstance,
 		BusinessMetrics:   businessMetrics,
+		Scheduler:         jobScheduler,
 		RepositoryFactory: repositoryFactory,
 		ServiceFactory:    serviceFactory,
 		HandlerFactory:    handlerFactory,
@@ -139,3 +151,18 @@ func (c *CoreComposition) GetTelemetry() telemetry.TelemetryInterface {
 func (c *CoreComposition) GetBusinessMetrics() *telemetry.BusinessMetrics {
 	return c.BusinessMetrics
 }
+
+// GetScheduler returns the job scheduler from the composition.
+// It is stopped by Shutdown.
+func (c *CoreComposition) GetScheduler() *scheduler.Scheduler {
+	return c.Scheduler
+}
+
+// Shutdown stops the job scheduler, waiting for running jobs to return until ctx is done,
+// so no job runs against dependencies that are being closed
+func (c *CoreComposition) Shutdown(ctx context.Context) error {
+	if c.Scheduler == nil {
+		return nil
+	}
+	return c.Scheduler.Stop(ctx)
+}
@@ -2,15 +2,25 @@ package providers
 
 import (
 	"backend-core/cache/decorators"
+	"backend-core/config"
+	"backend-core/logging"
 )
 
 // CacheDecoratorFactoryProvider creates a cache decorator factory
-func CacheDecoratorFactoryProvider() *decorators.CacheDecoratorFactory {
-	return &decorators.CacheDecoratorFactory{}
+func CacheDecoratorFactoryProvider(logger *logging.Logger) *decorators.CacheDecoratorFactory {
+	return decorators.NewCacheDecoratorFactory(logger)
 }
 
-// CacheDecoratorProvider creates a cache decorator
-func CacheDecoratorProvider(factory *decorators.CacheDecoratorFactory) *decorators.CacheDecorator {
-	// Simple implementation - would need proper factory method
-	return &decorators.CacheDecorator{}
+// CacheDecoratorProvider creates a cache decorator on the configured Redis server. Without
+// Redis configured it returns an empty decorator, whose GetRedisCache returns nil.
+func CacheDecoratorProvider(factory *decorators.CacheDecoratorFactory, cfg *config.Config) *decorators.CacheDecorator {
+	if cfg.Redis.Addr == "" && len(cfg.Redis.ClusterAddrs) == 0 {
+		return &decorators.CacheDecorator{}
+	}
+
+	cacheDecorator, err := factory.CreateDefaultDecorator(cfg.Redis)
+	if err != nil {
+		return &decorators.CacheDecorator{}
+	}
+	return cacheDecorator
 }
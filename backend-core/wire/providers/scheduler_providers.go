@@ -0,0 +1,26 @@
+package providers
+
+import (
+	"backend-core/cache/decorators"
+	"backend-core/logging"
+	"backend-core/scheduler"
+)
+
+// SchedulerProvider creates a job scheduler. Jobs are locked in the Redis server of
+// cacheDecorator when it has one, so only one instance runs each occurrence; otherwise
+// every instance runs them.
+func SchedulerProvider(cacheDecorator *decorators.CacheDecorator, logger *logging.Logger) *scheduler.Scheduler {
+	var locks scheduler.LockFactory
+	if redisCache := cacheDecorator.GetRedisCache(); redisCache != nil {
+		locks = scheduler.RedisLocks(redisCache)
+	} else {
+		logger.Warn("Redis not configured, scheduled jobs will run on every instance")
+	}
+
+	s, err := scheduler.NewScheduler(locks, nil, logger)
+	if err != nil {
+		logger.Error("Failed to create scheduler metrics", "error", err)
+		return nil
+	}
+	return s
+}
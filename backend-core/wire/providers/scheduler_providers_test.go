@@ -0,0 +1,40 @@
+package providers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend-core/cache/decorators"
+	"backend-core/config"
+	"backend-core/logging"
+)
+
+func TestCacheDecoratorProviderUsesConfiguredRedis(t *testing.T) {
+	logger := logging.NewNopLogger()
+	factory := CacheDecoratorFactoryProvider(logger)
+
+	if redisCache := CacheDecoratorProvider(factory, &config.Config{}).GetRedisCache(); redisCache != nil {
+		t.Error("GetRedisCache() without Redis configured is not nil")
+	}
+
+	cacheDecorator := CacheDecoratorProvider(factory, &config.Config{Redis: config.RedisConfig{Addr: "127.0.0.1:0"}})
+	t.Cleanup(func() { cacheDecorator.Close() })
+	if cacheDecorator.GetRedisCache() == nil {
+		t.Fatal("GetRedisCache() with Redis configured is nil")
+	}
+}
+
+func TestSchedulerProviderRunsWithoutRedis(t *testing.T) {
+	jobScheduler := SchedulerProvider(&decorators.CacheDecorator{}, logging.NewNopLogger())
+	if jobScheduler == nil {
+		t.Fatal("SchedulerProvider() = nil")
+	}
+	jobScheduler.Start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := jobScheduler.Stop(ctx); err != nil {
+		t.Errorf("Stop() error = %v", err)
+	}
+}
@@ -15,6 +15,8 @@ type User struct {
 	LastName  string             `bson:"lastName" json:"lastName"`
 	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
 	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
+	// Version is incremented on every update, so concurrent updates can be detected
+	Version int64 `bson:"version" json:"version"`
 }
 
 // NewUser creates a new user entity
@@ -2,18 +2,30 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"graphql-service/internal/domain/user/entity"
 )
 
+// ErrVersionConflict is returned by UpdateFields when the user no longer has the expected
+// version because it was updated concurrently
+var ErrVersionConflict = errors.New("user was modified concurrently")
+
 // UserRepository defines the interface for user data access
 type UserRepository interface {
 	// Basic CRUD operations
 	Create(ctx context.Context, user *entity.User) error
+	// CreateMany creates users in one batch. One failing user does not stop the others;
+	// the result holds the error of each user by index, nil for those created.
+	CreateMany(ctx context.Context, users []*entity.User) []error
 	GetByID(ctx context.Context, id string) (*entity.User, error)
 	GetByEmail(ctx context.Context, email string) (*entity.User, error)
 	GetByUsername(ctx context.Context, username string) (*entity.User, error)
 	Update(ctx context.Context, user *entity.User) error
+	// UpdateFields sets only the given fields, keyed by their stored names, and returns the
+	// updated user, or nil if it does not exist. With expectedVersion set, the update is
+	// rejected with ErrVersionConflict unless the user still has that version.
+	UpdateFields(ctx context.Context, id string, fields map[string]interface{}, expectedVersion *int64) (*entity.User, error)
 	Delete(ctx context.Context, id string) error
 
 	// Query operations
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend-core/logging"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Connector opens a MongoDB client that has answered a ping
+type Connector func(ctx context.Context, config *MongoConfig) (*mongo.Client, error)
+
+// ConnectMongo connects to MongoDB with the options of config and pings it
+func ConnectMongo(ctx context.Context, config *MongoConfig) (*mongo.Client, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.ConnectTimeout+config.ServerSelectionTimeout)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, config.ClientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+
+	// Test the connection
+	if err = client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
+		return nil, fmt.Errorf("failed to ping MongoDB: %w", err)
+	}
+
+	return client, nil
+}
+
+// ConnectWithRetry calls connect up to config.ConnectAttempts times, backing off
+// exponentially between attempts, so MongoDB being briefly unavailable at startup does not
+// stop the service. It returns the last error once the attempts are used up or ctx is done.
+func ConnectWithRetry(ctx context.Context, config *MongoConfig, connect Connector, logger *logging.Logger) (*mongo.Client, error) {
+	backoff := config.ConnectBackoff
+
+	for attempt := 1; ; attempt++ {
+		client, err := connect(ctx, config)
+		if err == nil {
+			if attempt > 1 {
+				logger.Info("Connected to MongoDB after retrying", "attempt", attempt)
+			}
+			return client, nil
+		}
+
+		if attempt >= config.ConnectAttempts {
+			return nil, fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+
+		logger.Warn("MongoDB connection attempt failed",
+			"attempt", attempt,
+			"max_attempts", config.ConnectAttempts,
+			"retry_in", backoff.String(),
+			"error", err.Error())
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > config.ConnectMaxBackoff {
+			backoff = config.ConnectMaxBackoff
+		}
+	}
+}
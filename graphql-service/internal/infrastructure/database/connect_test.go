@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend-core/logging"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// flakyConnector fails its first failures calls and then returns client
+func flakyConnector(failures int, client *mongo.Client) (Connector, *int) {
+	calls := 0
+	return func(ctx context.Context, config *MongoConfig) (*mongo.Client, error) {
+		calls++
+		if calls <= failures {
+			return nil, errors.New("connection refused")
+		}
+		return client, nil
+	}, &calls
+}
+
+func retryConfig(attempts int) *MongoConfig {
+	config := DefaultMongoConfig()
+	config.ConnectAttempts = attempts
+	config.ConnectBackoff = time.Millisecond
+	config.ConnectMaxBackoff = 2 * time.Millisecond
+	return config
+}
+
+func TestConnectWithRetryConnectsAfterFailures(t *testing.T) {
+	want := &mongo.Client{}
+	connect, calls := flakyConnector(2, want)
+
+	client, err := ConnectWithRetry(context.Background(), retryConfig(5), connect, logging.NewNopLogger())
+	if err != nil {
+		t.Fatalf("ConnectWithRetry() error = %v", err)
+	}
+	if client != want {
+		t.Error("ConnectWithRetry() did not return the connected client")
+	}
+	if *calls != 3 {
+		t.Errorf("connector called %d times, want 3", *calls)
+	}
+}
+
+func TestConnectWithRetryGivesUp(t *testing.T) {
+	connect, calls := flakyConnector(10, &mongo.Client{})
+
+	_, err := ConnectWithRetry(context.Background(), retryConfig(3), connect, logging.NewNopLogger())
+	if err == nil {
+		t.Fatal("ConnectWithRetry() error = nil, want an error")
+	}
+	if *calls != 3 {
+		t.Errorf("connector called %d times, want 3", *calls)
+	}
+}
+
+func TestConnectWithRetryStopsWhenContextIsDone(t *testing.T) {
+	config := retryConfig(5)
+	config.ConnectBackoff = time.Minute
+	config.ConnectMaxBackoff = time.Minute
+	connect, calls := flakyConnector(10, &mongo.Client{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := ConnectWithRetry(ctx, config, connect, logging.NewNopLogger())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ConnectWithRetry() error = %v, want context.DeadlineExceeded", err)
+	}
+	if *calls != 1 {
+		t.Errorf("connector called %d times, want 1", *calls)
+	}
+}
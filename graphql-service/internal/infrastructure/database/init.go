@@ -4,17 +4,22 @@ import (
 	"context"
 	"log"
 
+	"backend-core/logging"
+
 	"graphql-service/internal/infrastructure/database/migration"
+	// Register the service's migrations with the runner
+	_ "graphql-service/internal/infrastructure/database/migration/migrations"
 
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-// Initialize sets up the database with collections and indexes using migrations
-func Initialize(db *mongo.Database) error {
+// Initialize sets up the database with collections and indexes using migrations, which
+// log through logger
+func Initialize(db *mongo.Database, logger *logging.Logger) error {
 	ctx := context.Background()
 
 	// Run migrations
-	migrationRunner := migration.NewMigrationRunner(db, nil)
+	migrationRunner := migration.NewMigrationRunner(db, logger)
 	if err := migrationRunner.RunMigrations(ctx); err != nil {
 		return err
 	}
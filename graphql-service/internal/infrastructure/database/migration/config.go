@@ -0,0 +1,88 @@
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DefaultDatabase is the database used when neither a file nor MONGO_DATABASE names one.
+// The service itself defaults to it as well, so both work on the same database.
+const DefaultDatabase = "graphql_service"
+
+// ConfigPathEnv names the environment variable holding the path of the config file the
+// migration commands read when no -config flag is given
+const ConfigPathEnv = "MIGRATION_CONFIG"
+
+// Config holds the MongoDB connection settings of the migration commands
+type Config struct {
+	URI      string `json:"uri"`
+	Database string `json:"database"`
+	// AuthSource is the database the credentials of URI are checked against. Empty keeps
+	// the authSource given in URI, or the driver's default when URI has none.
+	AuthSource string `json:"authSource"`
+}
+
+// DefaultConfig returns the configuration used when neither a file nor the environment
+// sets a value
+func DefaultConfig() *Config {
+	return &Config{
+		URI:      "mongodb://localhost:27017",
+		Database: DefaultDatabase,
+	}
+}
+
+// LoadConfig returns the default configuration overridden by the JSON file at path, if
+// path is not empty, and then by MONGO_URI, MONGO_DATABASE and MONGO_AUTH_SOURCE
+func LoadConfig(path string) (*Config, error) {
+	config := DefaultConfig()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration config: %w", err)
+		}
+		// Fields missing from the file keep their defaults
+		if err := json.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("invalid migration config %s: %w", path, err)
+		}
+	}
+
+	if value := os.Getenv("MONGO_URI"); value != "" {
+		config.URI = value
+	}
+	if value := os.Getenv("MONGO_DATABASE"); value != "" {
+		config.Database = value
+	}
+	if value, ok := os.LookupEnv("MONGO_AUTH_SOURCE"); ok {
+		config.AuthSource = value
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate checks that the configuration is usable
+func (c *Config) Validate() error {
+	if c.URI == "" {
+		return fmt.Errorf("MongoDB URI is required")
+	}
+	if c.Database == "" {
+		return fmt.Errorf("MongoDB database is required")
+	}
+	return nil
+}
+
+// ClientOptions returns the client options for the configuration. An explicitly set auth
+// source replaces the one in URI, and only applies when URI carries credentials.
+func (c *Config) ClientOptions() *options.ClientOptions {
+	opts := options.Client().ApplyURI(c.URI)
+	if c.AuthSource != "" && opts.Auth != nil {
+		opts.Auth.AuthSource = c.AuthSource
+	}
+	return opts
+}
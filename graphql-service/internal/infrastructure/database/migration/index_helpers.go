@@ -0,0 +1,139 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"backend-core/logging"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// IndexOptions configures an index created by the migration helpers
+type IndexOptions struct {
+	// Name of the index. When empty, MongoDB's default name is derived from
+	// the keys (e.g. "tenant_id_1_email_1").
+	Name               string
+	Unique             bool
+	Sparse             bool
+	ExpireAfterSeconds *int32
+}
+
+// CreateCompoundIndex creates an index over several keys on the given collection.
+// It is idempotent: if an index with the same name already exists, nothing is done.
+// Like the other helpers, it logs through the logger the runner puts in ctx.
+func CreateCompoundIndex(ctx context.Context, db *mongo.Database, collection string, keys bson.D, opts IndexOptions) error {
+	return createIndex(ctx, db, collection, keys, nil, opts)
+}
+
+// CreatePartialIndex creates an index that only covers documents matching filter.
+// It is idempotent: if an index with the same name already exists, nothing is done.
+func CreatePartialIndex(ctx context.Context, db *mongo.Database, collection string, keys bson.D, filter bson.D, opts IndexOptions) error {
+	if len(filter) == 0 {
+		return fmt.Errorf("partial index on %s requires a filter expression", collection)
+	}
+	return createIndex(ctx, db, collection, keys, filter, opts)
+}
+
+// DropIndex drops the named index from the given collection. Dropping an index
+// that does not exist is not an error, so it is safe to use in Down migrations.
+func DropIndex(ctx context.Context, db *mongo.Database, collection, name string) error {
+	coll := db.Collection(collection)
+
+	exists, err := indexExists(ctx, coll, name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		Logger(ctx).Info("Index does not exist, skipping",
+			logging.String("index", name), logging.String("collection", collection))
+		return nil
+	}
+
+	if _, err := coll.Indexes().DropOne(ctx, name); err != nil {
+		return fmt.Errorf("failed to drop index %s on %s: %w", name, collection, err)
+	}
+
+	Logger(ctx).Info("Dropped index",
+		logging.String("index", name), logging.String("collection", collection))
+	return nil
+}
+
+// IndexName returns the name MongoDB assigns to an index over keys by default
+func IndexName(keys bson.D) string {
+	parts := make([]string, 0, len(keys)*2)
+	for _, key := range keys {
+		parts = append(parts, key.Key, fmt.Sprint(key.Value))
+	}
+	return strings.Join(parts, "_")
+}
+
+// createIndex creates an index unless one with the same name already exists
+func createIndex(ctx context.Context, db *mongo.Database, collection string, keys bson.D, filter bson.D, opts IndexOptions) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("index on %s requires at least one key", collection)
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = IndexName(keys)
+	}
+
+	coll := db.Collection(collection)
+
+	exists, err := indexExists(ctx, coll, name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		Logger(ctx).Info("Index already exists, skipping",
+			logging.String("index", name), logging.String("collection", collection))
+		return nil
+	}
+
+	indexOptions := options.Index().SetName(name)
+	if opts.Unique {
+		indexOptions.SetUnique(true)
+	}
+	if opts.Sparse {
+		indexOptions.SetSparse(true)
+	}
+	if opts.ExpireAfterSeconds != nil {
+		indexOptions.SetExpireAfterSeconds(*opts.ExpireAfterSeconds)
+	}
+	if len(filter) > 0 {
+		indexOptions.SetPartialFilterExpression(filter)
+	}
+
+	index := mongo.IndexModel{
+		Keys:    keys,
+		Options: indexOptions,
+	}
+
+	if _, err := coll.Indexes().CreateOne(ctx, index); err != nil {
+		return fmt.Errorf("failed to create index %s on %s: %w", name, collection, err)
+	}
+
+	Logger(ctx).Info("Created index",
+		logging.String("index", name), logging.String("collection", collection))
+	return nil
+}
+
+// indexExists checks whether an index with the given name exists on the collection
+func indexExists(ctx context.Context, coll *mongo.Collection, name string) (bool, error) {
+	specs, err := coll.Indexes().ListSpecifications(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to list indexes on %s: %w", coll.Name(), err)
+	}
+
+	for _, spec := range specs {
+		if spec.Name == name {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,195 @@
+package migration
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// DefaultLockTTL is how long a migration lock outlives the last refresh of its holder,
+	// which bounds how long a crashed instance keeps others from migrating
+	DefaultLockTTL = 2 * time.Minute
+
+	migrationLocksCollection = "migration_locks"
+	migrationLockID          = "migrations"
+	// lockPollInterval is how often an instance waiting for the lock tries to take it
+	lockPollInterval = time.Second
+)
+
+// migrationLock is the lock document held while migrating
+type migrationLock struct {
+	ID        string    `bson:"_id"`
+	Owner     string    `bson:"owner"`
+	LockedAt  time.Time `bson:"lockedAt"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
+
+// SetLockTTL sets how long the migration lock outlives the last refresh of its holder. A
+// non-positive ttl restores DefaultLockTTL.
+func (m *MigrationRunner) SetLockTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultLockTTL
+	}
+	m.lockTTL = ttl
+}
+
+// withLock runs fn while holding the migration lock, so only one instance changes the
+// schema at a time. It waits for another holder to release the lock, or for its lock to
+// expire, until ctx is done. The lock is refreshed while fn runs.
+func (m *MigrationRunner) withLock(ctx context.Context, fn func(ctx context.Context) error) error {
+	ctx = WithLogger(ctx, m.logger)
+	if err := m.ensureLockCollection(ctx); err != nil {
+		return fmt.Errorf("failed to ensure migration locks collection: %w", err)
+	}
+
+	owner, err := newLockOwner()
+	if err != nil {
+		return err
+	}
+	if err := m.acquireLock(ctx, owner); err != nil {
+		return err
+	}
+
+	lockCtx, cancel := context.WithCancel(ctx)
+	refreshed := make(chan struct{})
+	go func() {
+		defer close(refreshed)
+		m.refreshLock(lockCtx, owner)
+	}()
+
+	defer func() {
+		cancel()
+		<-refreshed
+		// Release even if ctx is done, so others need not wait for the lock to expire
+		releaseCtx, releaseCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer releaseCancel()
+		if err := m.releaseLock(releaseCtx, owner); err != nil {
+			log.Printf("Failed to release migration lock, it expires in %s: %v", m.lockTTL, err)
+		}
+	}()
+
+	return fn(lockCtx)
+}
+
+// acquireLock takes the lock for owner, waiting while another owner holds it
+func (m *MigrationRunner) acquireLock(ctx context.Context, owner string) error {
+	waiting := false
+	for {
+		acquired, err := m.tryLock(ctx, owner)
+		if err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		if acquired {
+			log.Printf("Acquired migration lock as %s", owner)
+			return nil
+		}
+
+		if !waiting {
+			m.logLockHolder(ctx)
+			waiting = true
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for migration lock: %w", ctx.Err())
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// logLockHolder logs which instance holds the lock being waited for
+func (m *MigrationRunner) logLockHolder(ctx context.Context) {
+	var lock migrationLock
+	err := m.db.Collection(migrationLocksCollection).FindOne(ctx, bson.M{"_id": migrationLockID}).Decode(&lock)
+	if err != nil {
+		log.Println("Migration lock is held by another instance, waiting")
+		return
+	}
+	log.Printf("Migration lock is held by %s since %s, waiting", lock.Owner, lock.LockedAt.Format(time.RFC3339))
+}
+
+// tryLock takes the lock if it is free or expired. A lock held by someone else makes the
+// upsert insert a second document with the same ID, which fails as a duplicate key.
+func (m *MigrationRunner) tryLock(ctx context.Context, owner string) (bool, error) {
+	now := time.Now()
+	filter := bson.M{"_id": migrationLockID, "expiresAt": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{
+		"owner":     owner,
+		"lockedAt":  now,
+		"expiresAt": now.Add(m.lockTTL),
+	}}
+
+	_, err := m.db.Collection(migrationLocksCollection).UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// refreshLock extends the lock of owner until ctx is done
+func (m *MigrationRunner) refreshLock(ctx context.Context, owner string) {
+	ticker := time.NewTicker(m.lockTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		filter := bson.M{"_id": migrationLockID, "owner": owner}
+		update := bson.M{"$set": bson.M{"expiresAt": time.Now().Add(m.lockTTL)}}
+		result, err := m.db.Collection(migrationLocksCollection).UpdateOne(ctx, filter, update)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Failed to refresh migration lock: %v", err)
+			}
+			continue
+		}
+		if result.MatchedCount == 0 {
+			log.Printf("Migration lock of %s expired while migrating", owner)
+		}
+	}
+}
+
+// releaseLock deletes the lock if owner still holds it
+func (m *MigrationRunner) releaseLock(ctx context.Context, owner string) error {
+	filter := bson.M{"_id": migrationLockID, "owner": owner}
+	_, err := m.db.Collection(migrationLocksCollection).DeleteOne(ctx, filter)
+	return err
+}
+
+// ensureLockCollection creates the TTL index that removes expired locks. Expired locks may
+// also be taken over before MongoDB removes them, since its TTL monitor only runs every minute.
+func (m *MigrationRunner) ensureLockCollection(ctx context.Context) error {
+	expireAfter := int32(0)
+	return CreateCompoundIndex(ctx, m.db, migrationLocksCollection,
+		bson.D{{Key: "expiresAt", Value: 1}},
+		IndexOptions{ExpireAfterSeconds: &expireAfter},
+	)
+}
+
+// newLockOwner returns an ID naming this instance as the holder of the lock
+func newLockOwner() (string, error) {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate migration lock owner: %w", err)
+	}
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix)), nil
+}
@@ -0,0 +1,177 @@
+//go:build integration
+
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// newIntegrationDatabase connects to the MongoDB at MONGO_URI and returns a database of its
+// own for the test, dropped when it ends. The test is skipped without MONGO_URI.
+func newIntegrationDatabase(t *testing.T) *mongo.Database {
+	t.Helper()
+
+	uri := os.Getenv("MONGO_URI")
+	if uri == "" {
+		t.Skip("MONGO_URI not set")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("failed to connect to MongoDB at %s: %v", uri, err)
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		t.Fatalf("failed to reach MongoDB at %s: %v", uri, err)
+	}
+
+	db := client.Database(fmt.Sprintf("migration_test_%d", time.Now().UnixNano()))
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		db.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+	return db
+}
+
+func TestRunMigrationsAppliesInVersionOrder(t *testing.T) {
+	db := newIntegrationDatabase(t)
+	ctx := context.Background()
+	applied := &appliedLog{}
+	runner := NewMigrationRunnerWithMigrations(db, nil, recordingMigrations(applied, "002", "010", "001"))
+
+	if err := runner.RunMigrations(ctx); err != nil {
+		t.Fatalf("RunMigrations() error = %v", err)
+	}
+	if got, want := applied.Versions(), []string{"001", "002", "010"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("applied %v, want %v", got, want)
+	}
+
+	status, err := runner.GetMigrationStatus(ctx)
+	if err != nil {
+		t.Fatalf("GetMigrationStatus() error = %v", err)
+	}
+	if len(status) != 3 || status[0].Version != "001" || status[2].Checksum != "recording_010" {
+		t.Errorf("GetMigrationStatus() = %+v, want the three migrations in version order", status)
+	}
+
+	if err := runner.RunMigrations(ctx); err != nil {
+		t.Fatalf("second RunMigrations() error = %v", err)
+	}
+	if got := applied.Versions(); len(got) != 3 {
+		t.Errorf("applied %v after running again, want no migration applied twice", got)
+	}
+}
+
+// slowMigration holds each Up for delay, so runners started together overlap
+type slowMigration struct {
+	MigrationInterface
+	delay time.Duration
+}
+
+func (m *slowMigration) Up(ctx context.Context, db *mongo.Database) error {
+	time.Sleep(m.delay)
+	return m.MigrationInterface.Up(ctx, db)
+}
+
+func TestConcurrentRunnersApplyEachMigrationOnce(t *testing.T) {
+	db := newIntegrationDatabase(t)
+	applied := &appliedLog{}
+	var list []MigrationInterface
+	for _, migration := range recordingMigrations(applied, "001", "002", "003") {
+		list = append(list, &slowMigration{MigrationInterface: migration, delay: 200 * time.Millisecond})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		runner := NewMigrationRunnerWithMigrations(db, nil, list)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = runner.RunMigrations(ctx)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("runner %d RunMigrations() error = %v", i, err)
+		}
+	}
+	if got, want := applied.Versions(), []string{"001", "002", "003"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("applied %v, want each migration once: %v", got, want)
+	}
+
+	count, err := db.Collection(migrationLocksCollection).CountDocuments(ctx, bson.M{})
+	if err != nil {
+		t.Fatalf("CountDocuments() error = %v", err)
+	}
+	if count != 0 {
+		t.Errorf("%d migration locks left after both runners finished, want none", count)
+	}
+}
+
+func TestRunMigrationsWaitsForHeldLock(t *testing.T) {
+	db := newIntegrationDatabase(t)
+	applied := &appliedLog{}
+	runner := NewMigrationRunnerWithMigrations(db, nil, recordingMigrations(applied, "001"))
+
+	_, err := db.Collection(migrationLocksCollection).InsertOne(context.Background(), migrationLock{
+		ID:        migrationLockID,
+		Owner:     "other-instance",
+		LockedAt:  time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("InsertOne() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+	err = runner.RunMigrations(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("RunMigrations() error = %v, want context.DeadlineExceeded", err)
+	}
+	if got := applied.Versions(); len(got) != 0 {
+		t.Errorf("applied %v while another instance held the lock, want none", got)
+	}
+}
+
+func TestRunMigrationsTakesOverExpiredLock(t *testing.T) {
+	db := newIntegrationDatabase(t)
+	applied := &appliedLog{}
+	runner := NewMigrationRunnerWithMigrations(db, nil, recordingMigrations(applied, "001"))
+
+	_, err := db.Collection(migrationLocksCollection).InsertOne(context.Background(), migrationLock{
+		ID:        migrationLockID,
+		Owner:     "crashed-instance",
+		LockedAt:  time.Now().Add(-time.Hour),
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("InsertOne() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := runner.RunMigrations(ctx); err != nil {
+		t.Fatalf("RunMigrations() error = %v", err)
+	}
+	if got := applied.Versions(); len(got) != 1 {
+		t.Errorf("applied %v, want the migration applied after taking over the expired lock", got)
+	}
+}
@@ -0,0 +1,20 @@
+package migrations
+
+import (
+	"context"
+
+	"graphql-service/internal/infrastructure/database/migration"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// createFieldIndexes creates an ascending index on each of fields, named by MongoDB's default
+func createFieldIndexes(ctx context.Context, db *mongo.Database, collection string, fields ...string) error {
+	for _, field := range fields {
+		if err := migration.CreateCompoundIndex(ctx, db, collection, bson.D{{Key: field, Value: 1}}, migration.IndexOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
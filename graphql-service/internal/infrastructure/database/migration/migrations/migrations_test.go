@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"testing"
+
+	"graphql-service/internal/infrastructure/database/migration"
+)
+
+func TestGetAllMigrationsReturnsRegisteredMigrationsInVersionOrder(t *testing.T) {
+	want := []string{"001", "002", "003", "004", "005", "006"}
+	all := migration.GetAllMigrations()
+	if len(all) != len(want) {
+		t.Fatalf("GetAllMigrations() returned %d migrations, want %v", len(all), want)
+	}
+	for i, registered := range all {
+		if registered.Version() != want[i] {
+			t.Fatalf("GetAllMigrations()[%d] = %s, want %s", i, registered.Version(), want[i])
+		}
+		if registered.Description() == "" || registered.Checksum() == "" {
+			t.Errorf("migration %s has no description or checksum", registered.Version())
+		}
+		if migration.GetMigrationByVersion(registered.Version()) != registered {
+			t.Errorf("GetMigrationByVersion(%s) did not return the registered migration", registered.Version())
+		}
+	}
+	if migration.GetMigrationByVersion("999") != nil {
+		t.Error("GetMigrationByVersion(999) returned a migration, want nil")
+	}
+}
+
+func TestNewMigrationRunnerUsesRegisteredMigrations(t *testing.T) {
+	migrations := migration.NewMigrationRunner(nil, nil).Migrations()
+	if len(migrations) == 0 {
+		t.Fatal("NewMigrationRunner() has no migrations")
+	}
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i-1].Version() >= migrations[i].Version() {
+			t.Errorf("migration %s runs before %s", migrations[i-1].Version(), migrations[i].Version())
+		}
+	}
+}
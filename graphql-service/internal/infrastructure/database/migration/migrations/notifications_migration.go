@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"graphql-service/internal/infrastructure/database/migration"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func init() {
+	migration.Register(NewNotificationsMigration004())
+}
+
+// NotificationsMigration004 creates the notifications collection with indexes
+type NotificationsMigration004 struct{}
+
+// NewNotificationsMigration004 creates a new notifications migration
+func NewNotificationsMigration004() *NotificationsMigration004 {
+	return &NotificationsMigration004{}
+}
+
+// Version returns the migration version
+func (m *NotificationsMigration004) Version() string {
+	return "004"
+}
+
+// Description returns the migration description
+func (m *NotificationsMigration004) Description() string {
+	return "Create notifications collection with indexes"
+}
+
+// Up applies the migration
+func (m *NotificationsMigration004) Up(ctx context.Context, db *mongo.Database) error {
+	if err := createFieldIndexes(ctx, db, "notifications", "userId", "type", "read", "createdAt"); err != nil {
+		return err
+	}
+
+	fmt.Println("Created notifications collection with indexes")
+	return nil
+}
+
+// Down rolls back the migration
+func (m *NotificationsMigration004) Down(ctx context.Context, db *mongo.Database) error {
+	// Drop the notifications collection
+	err := db.Collection("notifications").Drop(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to drop notifications collection: %w", err)
+	}
+
+	fmt.Println("Dropped notifications collection")
+	return nil
+}
+
+// Checksum returns a checksum for the migration
+func (m *NotificationsMigration004) Checksum() string {
+	return "notifications_004"
+}
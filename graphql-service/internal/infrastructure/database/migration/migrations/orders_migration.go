@@ -4,9 +4,15 @@ import (
 	"context"
 	"fmt"
 
+	"graphql-service/internal/infrastructure/database/migration"
+
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+func init() {
+	migration.Register(NewOrdersMigration002())
+}
+
 // OrdersMigration002 creates the orders collection with indexes
 type OrdersMigration002 struct{}
 
@@ -27,48 +33,8 @@ func (m *OrdersMigration002) Description() string {
 
 // Up applies the migration
 func (m *OrdersMigration002) Up(ctx context.Context, db *mongo.Database) error {
-	// Create orders collection
-	err := db.CreateCollection(ctx, "orders")
-	if err != nil {
-		return fmt.Errorf("failed to create orders collection: %w", err)
-	}
-
-	// Create indexes
-	collection := db.Collection("orders")
-
-	// Index on user_id
-	userIDIndex := mongo.IndexModel{
-		Keys: map[string]interface{}{
-			"user_id": 1,
-		},
-	}
-
-	// Index on status
-	statusIndex := mongo.IndexModel{
-		Keys: map[string]interface{}{
-			"status": 1,
-		},
-	}
-
-	// Index on created_at
-	createdAtIndex := mongo.IndexModel{
-		Keys: map[string]interface{}{
-			"created_at": 1,
-		},
-	}
-
-	// Index on total_amount
-	totalAmountIndex := mongo.IndexModel{
-		Keys: map[string]interface{}{
-			"total_amount": 1,
-		},
-	}
-
-	// Create all indexes
-	indexes := []mongo.IndexModel{userIDIndex, statusIndex, createdAtIndex, totalAmountIndex}
-	_, err = collection.Indexes().CreateMany(ctx, indexes)
-	if err != nil {
-		return fmt.Errorf("failed to create indexes: %w", err)
+	if err := createFieldIndexes(ctx, db, "orders", "userId", "status", "createdAt"); err != nil {
+		return err
 	}
 
 	fmt.Println("Created orders collection with indexes")
@@ -89,5 +55,5 @@ func (m *OrdersMigration002) Down(ctx context.Context, db *mongo.Database) error
 
 // Checksum returns a checksum for the migration
 func (m *OrdersMigration002) Checksum() string {
-	return "orders_002_checksum"
+	return "orders_002"
 }
@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"graphql-service/internal/infrastructure/database/migration"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func init() {
+	migration.Register(NewProductsMigration003())
+}
+
+// ProductsMigration003 creates the products collection with indexes
+type ProductsMigration003 struct{}
+
+// NewProductsMigration003 creates a new products migration
+func NewProductsMigration003() *ProductsMigration003 {
+	return &ProductsMigration003{}
+}
+
+// Version returns the migration version
+func (m *ProductsMigration003) Version() string {
+	return "003"
+}
+
+// Description returns the migration description
+func (m *ProductsMigration003) Description() string {
+	return "Create products collection with indexes"
+}
+
+// Up applies the migration
+func (m *ProductsMigration003) Up(ctx context.Context, db *mongo.Database) error {
+	if err := createFieldIndexes(ctx, db, "products", "name", "category", "price"); err != nil {
+		return err
+	}
+
+	fmt.Println("Created products collection with indexes")
+	return nil
+}
+
+// Down rolls back the migration
+func (m *ProductsMigration003) Down(ctx context.Context, db *mongo.Database) error {
+	// Drop the products collection
+	err := db.Collection("products").Drop(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to drop products collection: %w", err)
+	}
+
+	fmt.Println("Dropped products collection")
+	return nil
+}
+
+// Checksum returns a checksum for the migration
+func (m *ProductsMigration003) Checksum() string {
+	return "products_003"
+}
@@ -0,0 +1,182 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"graphql-service/internal/infrastructure/database/migration"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func init() {
+	migration.Register(NewSampleDataMigration005())
+}
+
+// Sample documents are removed on rollback by these keys
+var (
+	sampleUsernames          = []string{"john_doe", "jane_smith", "bob_wilson"}
+	sampleProductNames       = []string{"Laptop", "Smartphone", "Book", "Headphones"}
+	sampleNotificationTitles = []string{"Welcome to our platform!", "Special Offer!"}
+)
+
+// SampleDataMigration005 inserts sample users, products and notifications
+type SampleDataMigration005 struct{}
+
+// NewSampleDataMigration005 creates a new sample data migration
+func NewSampleDataMigration005() *SampleDataMigration005 {
+	return &SampleDataMigration005{}
+}
+
+// Version returns the migration version
+func (m *SampleDataMigration005) Version() string {
+	return "005"
+}
+
+// Description returns the migration description
+func (m *SampleDataMigration005) Description() string {
+	return "Insert sample data"
+}
+
+// Up applies the migration
+func (m *SampleDataMigration005) Up(ctx context.Context, db *mongo.Database) error {
+	// Insert sample users
+	users := []interface{}{
+		bson.M{
+			"username":  "john_doe",
+			"email":     "john@example.com",
+			"firstName": "John",
+			"lastName":  "Doe",
+			"createdAt": time.Now(),
+			"updatedAt": time.Now(),
+		},
+		bson.M{
+			"username":  "jane_smith",
+			"email":     "jane@example.com",
+			"firstName": "Jane",
+			"lastName":  "Smith",
+			"createdAt": time.Now(),
+			"updatedAt": time.Now(),
+		},
+		bson.M{
+			"username":  "bob_wilson",
+			"email":     "bob@example.com",
+			"firstName": "Bob",
+			"lastName":  "Wilson",
+			"createdAt": time.Now(),
+			"updatedAt": time.Now(),
+		},
+	}
+
+	usersCollection := db.Collection("users")
+	_, err := usersCollection.InsertMany(ctx, users)
+	if err != nil {
+		return err
+	}
+
+	// Insert sample products
+	products := []interface{}{
+		bson.M{
+			"name":        "Laptop",
+			"description": "High-performance laptop",
+			"price":       999.99,
+			"category":    "Electronics",
+			"stock":       50,
+			"createdAt":   time.Now(),
+			"updatedAt":   time.Now(),
+		},
+		bson.M{
+			"name":        "Smartphone",
+			"description": "Latest smartphone model",
+			"price":       699.99,
+			"category":    "Electronics",
+			"stock":       100,
+			"createdAt":   time.Now(),
+			"updatedAt":   time.Now(),
+		},
+		bson.M{
+			"name":        "Book",
+			"description": "Programming book",
+			"price":       29.99,
+			"category":    "Books",
+			"stock":       200,
+			"createdAt":   time.Now(),
+			"updatedAt":   time.Now(),
+		},
+		bson.M{
+			"name":        "Headphones",
+			"description": "Wireless headphones",
+			"price":       199.99,
+			"category":    "Electronics",
+			"stock":       75,
+			"createdAt":   time.Now(),
+			"updatedAt":   time.Now(),
+		},
+	}
+
+	productsCollection := db.Collection("products")
+	_, err = productsCollection.InsertMany(ctx, products)
+	if err != nil {
+		return err
+	}
+
+	// Insert sample notifications
+	notifications := []interface{}{
+		bson.M{
+			"userId":    "user_id_1", // This would be replaced with actual user ID
+			"type":      "WELCOME",
+			"title":     "Welcome to our platform!",
+			"message":   "Thank you for joining us. Explore our features and get started.",
+			"read":      false,
+			"createdAt": time.Now(),
+			"updatedAt": time.Now(),
+		},
+		bson.M{
+			"userId":    "user_id_2",
+			"type":      "PROMOTION",
+			"title":     "Special Offer!",
+			"message":   "Get 20% off on all electronics. Limited time offer!",
+			"read":      false,
+			"createdAt": time.Now(),
+			"updatedAt": time.Now(),
+		},
+	}
+
+	notificationsCollection := db.Collection("notifications")
+	_, err = notificationsCollection.InsertMany(ctx, notifications)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Inserted sample data")
+	return nil
+}
+
+// Down rolls back the migration
+func (m *SampleDataMigration005) Down(ctx context.Context, db *mongo.Database) error {
+	samples := []struct {
+		collection string
+		field      string
+		values     []string
+	}{
+		{"users", "username", sampleUsernames},
+		{"products", "name", sampleProductNames},
+		{"notifications", "title", sampleNotificationTitles},
+	}
+	for _, sample := range samples {
+		filter := bson.M{sample.field: bson.M{"$in": sample.values}}
+		if _, err := db.Collection(sample.collection).DeleteMany(ctx, filter); err != nil {
+			return fmt.Errorf("failed to delete sample %s: %w", sample.collection, err)
+		}
+	}
+
+	fmt.Println("Deleted sample data")
+	return nil
+}
+
+// Checksum returns a checksum for the migration
+func (m *SampleDataMigration005) Checksum() string {
+	return "sample_data_005"
+}
@@ -4,10 +4,16 @@ import (
 	"context"
 	"fmt"
 
+	"graphql-service/internal/infrastructure/database/migration"
+
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+func init() {
+	migration.Register(NewUsersMigration001())
+}
+
 // UsersMigration001 creates the users collection with indexes
 type UsersMigration001 struct{}
 
@@ -28,50 +34,16 @@ func (m *UsersMigration001) Description() string {
 
 // Up applies the migration
 func (m *UsersMigration001) Up(ctx context.Context, db *mongo.Database) error {
-	// Create users collection
-	err := db.CreateCollection(ctx, "users")
-	if err != nil {
-		return fmt.Errorf("failed to create users collection: %w", err)
-	}
-
-	// Create indexes
-	collection := db.Collection("users")
-
-	// Index on email (unique)
-	emailIndex := mongo.IndexModel{
-		Keys: map[string]interface{}{
-			"email": 1,
-		},
-		Options: options.Index().SetUnique(true),
+	// Email and username are unique
+	for _, key := range []string{"email", "username"} {
+		err := migration.CreateCompoundIndex(ctx, db, "users", bson.D{{Key: key, Value: 1}}, migration.IndexOptions{Unique: true})
+		if err != nil {
+			return err
+		}
 	}
 
-	// Index on username (unique)
-	usernameIndex := mongo.IndexModel{
-		Keys: map[string]interface{}{
-			"username": 1,
-		},
-		Options: options.Index().SetUnique(true),
-	}
-
-	// Index on created_at
-	createdAtIndex := mongo.IndexModel{
-		Keys: map[string]interface{}{
-			"created_at": 1,
-		},
-	}
-
-	// Index on is_active
-	activeIndex := mongo.IndexModel{
-		Keys: map[string]interface{}{
-			"is_active": 1,
-		},
-	}
-
-	// Create all indexes
-	indexes := []mongo.IndexModel{emailIndex, usernameIndex, createdAtIndex, activeIndex}
-	_, err = collection.Indexes().CreateMany(ctx, indexes)
-	if err != nil {
-		return fmt.Errorf("failed to create indexes: %w", err)
+	if err := createFieldIndexes(ctx, db, "users", "createdAt"); err != nil {
+		return err
 	}
 
 	fmt.Println("Created users collection with indexes")
@@ -92,5 +64,5 @@ func (m *UsersMigration001) Down(ctx context.Context, db *mongo.Database) error
 
 // Checksum returns a checksum for the migration
 func (m *UsersMigration001) Checksum() string {
-	return "users_001_checksum"
+	return "users_001"
 }
@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"context"
+
+	"graphql-service/internal/infrastructure/database/migration"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	usersTenantEmailIndex  = "tenant_id_1_email_1"
+	usersActiveStatusIndex = "status_1_active"
+)
+
+func init() {
+	migration.Register(NewUsersTenantIndexesMigration006())
+}
+
+// UsersTenantIndexesMigration006 adds tenant-scoped and partial indexes to the users collection
+type UsersTenantIndexesMigration006 struct{}
+
+// NewUsersTenantIndexesMigration006 creates a new users tenant indexes migration
+func NewUsersTenantIndexesMigration006() *UsersTenantIndexesMigration006 {
+	return &UsersTenantIndexesMigration006{}
+}
+
+// Version returns the migration version
+func (m *UsersTenantIndexesMigration006) Version() string {
+	return "006"
+}
+
+// Description returns the migration description
+func (m *UsersTenantIndexesMigration006) Description() string {
+	return "Add tenant email and active status indexes to users collection"
+}
+
+// Up applies the migration
+func (m *UsersTenantIndexesMigration006) Up(ctx context.Context, db *mongo.Database) error {
+	// Email is unique per tenant
+	err := migration.CreateCompoundIndex(ctx, db, "users",
+		bson.D{{Key: "tenant_id", Value: 1}, {Key: "email", Value: 1}},
+		migration.IndexOptions{Name: usersTenantEmailIndex, Unique: true},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Status lookups only target active users
+	return migration.CreatePartialIndex(ctx, db, "users",
+		bson.D{{Key: "status", Value: 1}},
+		bson.D{{Key: "status", Value: "active"}},
+		migration.IndexOptions{Name: usersActiveStatusIndex},
+	)
+}
+
+// Down rolls back the migration
+func (m *UsersTenantIndexesMigration006) Down(ctx context.Context, db *mongo.Database) error {
+	if err := migration.DropIndex(ctx, db, "users", usersActiveStatusIndex); err != nil {
+		return err
+	}
+	return migration.DropIndex(ctx, db, "users", usersTenantEmailIndex)
+}
+
+// Checksum returns a checksum for the migration
+func (m *UsersTenantIndexesMigration006) Checksum() string {
+	return "users_tenant_indexes_006"
+}
@@ -0,0 +1,77 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MigrationInterface defines the interface for individual migrations
+type MigrationInterface interface {
+	// Version orders the migrations; versions are zero-padded numbers such as "001"
+	Version() string
+	Description() string
+	Up(ctx context.Context, db *mongo.Database) error
+	Down(ctx context.Context, db *mongo.Database) error
+	Checksum() string
+}
+
+var (
+	registryMutex sync.RWMutex
+	registry      = make(map[string]MigrationInterface)
+)
+
+// Register adds a migration to the registry. Each migration registers itself from an init
+// function in its own file of the migrations package, so adding a file is all it takes to
+// add a migration; binaries running migrations import that package for its side effects.
+// Register panics if the version is empty or already registered.
+func Register(migration MigrationInterface) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	version := migration.Version()
+	if version == "" {
+		panic("migration: Register called with an empty version")
+	}
+	if existing, ok := registry[version]; ok {
+		panic(fmt.Sprintf("migration: version %s registered twice (%q and %q)",
+			version, existing.Description(), migration.Description()))
+	}
+	registry[version] = migration
+}
+
+// GetAllMigrations returns all registered migrations in version order
+func GetAllMigrations() []MigrationInterface {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	migrations := make([]MigrationInterface, 0, len(registry))
+	for _, migration := range registry {
+		migrations = append(migrations, migration)
+	}
+	SortByVersion(migrations)
+	return migrations
+}
+
+// GetMigrationByVersion returns a specific migration by version, or nil if none is registered
+func GetMigrationByVersion(version string) MigrationInterface {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	return registry[version]
+}
+
+// SortByVersion sorts migrations in the order they are applied. Shorter versions sort
+// first, so "10" still follows "9" when a version is not zero-padded.
+func SortByVersion(migrations []MigrationInterface) {
+	sort.SliceStable(migrations, func(i, j int) bool {
+		a, b := migrations[i].Version(), migrations[j].Version()
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return a < b
+	})
+}
@@ -0,0 +1,77 @@
+package migration
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// stubMigration is a migration that does nothing
+type stubMigration struct {
+	version     string
+	description string
+}
+
+func (m *stubMigration) Version() string                                    { return m.version }
+func (m *stubMigration) Description() string                                { return m.description }
+func (m *stubMigration) Up(ctx context.Context, db *mongo.Database) error   { return nil }
+func (m *stubMigration) Down(ctx context.Context, db *mongo.Database) error { return nil }
+func (m *stubMigration) Checksum() string                                   { return "stub_" + m.version }
+
+func versions(list []MigrationInterface) []string {
+	result := make([]string, len(list))
+	for i, migration := range list {
+		result[i] = migration.Version()
+	}
+	return result
+}
+
+func TestSortByVersion(t *testing.T) {
+	list := []MigrationInterface{
+		&stubMigration{version: "10"},
+		&stubMigration{version: "002"},
+		&stubMigration{version: "9"},
+		&stubMigration{version: "001"},
+		&stubMigration{version: "1"},
+	}
+
+	SortByVersion(list)
+
+	want := []string{"1", "9", "10", "001", "002"}
+	got := versions(list)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("SortByVersion() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestRegisterRejectsEmptyAndDuplicateVersions(t *testing.T) {
+	Register(&stubMigration{version: "901", description: "registered stub"})
+	t.Cleanup(func() {
+		registryMutex.Lock()
+		defer registryMutex.Unlock()
+		delete(registry, "901")
+	})
+
+	tests := map[string]MigrationInterface{
+		"empty version":     &stubMigration{description: "no version"},
+		"duplicate version": &stubMigration{version: "901", description: "another stub"},
+	}
+
+	for name, migration := range tests {
+		t.Run(name, func(t *testing.T) {
+			before := len(GetAllMigrations())
+			defer func() {
+				if recover() == nil {
+					t.Error("Register() did not panic")
+				}
+				if after := len(GetAllMigrations()); after != before {
+					t.Errorf("%d migrations registered after the panic, want %d", after, before)
+				}
+			}()
+			Register(migration)
+		})
+	}
+}
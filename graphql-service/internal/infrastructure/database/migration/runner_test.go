@@ -0,0 +1,85 @@
+package migration
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// appliedLog records the versions of the migrations run, in order
+type appliedLog struct {
+	mu       sync.Mutex
+	versions []string
+}
+
+func (l *appliedLog) Versions() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.versions...)
+}
+
+// recordingMigration records its version in applied when it is run
+type recordingMigration struct {
+	version string
+	applied *appliedLog
+}
+
+func (m *recordingMigration) Version() string     { return m.version }
+func (m *recordingMigration) Description() string { return "recording migration " + m.version }
+func (m *recordingMigration) Checksum() string    { return "recording_" + m.version }
+
+func (m *recordingMigration) Up(ctx context.Context, db *mongo.Database) error {
+	m.applied.mu.Lock()
+	defer m.applied.mu.Unlock()
+	m.applied.versions = append(m.applied.versions, m.version)
+	return nil
+}
+
+func (m *recordingMigration) Down(ctx context.Context, db *mongo.Database) error {
+	return nil
+}
+
+// recordingMigrations returns a recordingMigration for each version, in the given order
+func recordingMigrations(applied *appliedLog, versions ...string) []MigrationInterface {
+	list := make([]MigrationInterface, len(versions))
+	for i, version := range versions {
+		list[i] = &recordingMigration{version: version, applied: applied}
+	}
+	return list
+}
+
+func TestNewMigrationRunnerOrdersMigrationsByVersion(t *testing.T) {
+	list := recordingMigrations(&appliedLog{}, "003", "001", "002")
+
+	runner := NewMigrationRunnerWithMigrations(nil, nil, list)
+
+	want := []string{"001", "002", "003"}
+	for i, migration := range runner.Migrations() {
+		if migration.Version() != want[i] {
+			t.Fatalf("Migrations()[%d] = %s, want %s", i, migration.Version(), want[i])
+		}
+	}
+	if list[0].Version() != "003" {
+		t.Error("NewMigrationRunnerWithMigrations() reordered the given list")
+	}
+}
+
+func TestSetLockTTL(t *testing.T) {
+	runner := NewMigrationRunnerWithMigrations(nil, nil, nil)
+	if runner.lockTTL != DefaultLockTTL {
+		t.Errorf("lock TTL = %s, want %s", runner.lockTTL, DefaultLockTTL)
+	}
+
+	runner.SetLockTTL(30 * time.Second)
+	if runner.lockTTL != 30*time.Second {
+		t.Errorf("lock TTL = %s, want 30s", runner.lockTTL)
+	}
+
+	runner.SetLockTTL(0)
+	if runner.lockTTL != DefaultLockTTL {
+		t.Errorf("lock TTL = %s after SetLockTTL(0), want %s", runner.lockTTL, DefaultLockTTL)
+	}
+}
@@ -0,0 +1,143 @@
+package migration
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// MigrationsDir is where the migrations package lives, relative to the service root
+const MigrationsDir = "internal/infrastructure/database/migration/migrations"
+
+var migrationTemplate = template.Must(template.New("migration").Parse(`package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"graphql-service/internal/infrastructure/database/migration"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func init() {
+	migration.Register(New{{.Type}}())
+}
+
+// {{.Type}} is migration {{.Version}}: {{.Description}}
+type {{.Type}} struct{}
+
+// New{{.Type}} creates migration {{.Version}}
+func New{{.Type}}() *{{.Type}} {
+	return &{{.Type}}{}
+}
+
+// Version returns the migration version
+func (m *{{.Type}}) Version() string {
+	return {{printf "%q" .Version}}
+}
+
+// Description returns the migration description
+func (m *{{.Type}}) Description() string {
+	return {{printf "%q" .Description}}
+}
+
+// Up applies the migration
+func (m *{{.Type}}) Up(ctx context.Context, db *mongo.Database) error {
+	// TODO: Implement your migration logic here
+	// Use migration.CreateCompoundIndex and migration.CreatePartialIndex for idempotent index creation
+	fmt.Println("Applied migration {{.Version}}")
+	return nil
+}
+
+// Down rolls back the migration
+func (m *{{.Type}}) Down(ctx context.Context, db *mongo.Database) error {
+	// TODO: Implement your rollback logic here
+	// Use migration.DropIndex to remove indexes created in Up
+	fmt.Println("Rolled back migration {{.Version}}")
+	return nil
+}
+
+// Checksum returns a checksum for the migration
+func (m *{{.Type}}) Checksum() string {
+	return "{{.Name}}_{{.Version}}"
+}
+`))
+
+// CreateMigrationFile writes a migration skeleton to dir, which must hold the migrations
+// package, and returns its path. The migration registers itself, so it runs once the
+// service is rebuilt. An empty version takes the version after the highest registered one.
+func CreateMigrationFile(dir, version, description string) (string, error) {
+	words := strings.FieldsFunc(description, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return "", fmt.Errorf("migration description must contain a word")
+	}
+
+	if version == "" {
+		version = NextVersion()
+	}
+	if _, err := strconv.ParseUint(version, 10, 64); err != nil {
+		return "", fmt.Errorf("migration version %q must be a number", version)
+	}
+	if existing := GetMigrationByVersion(version); existing != nil {
+		return "", fmt.Errorf("migration version %s is already used by %q", version, existing.Description())
+	}
+
+	var typeName strings.Builder
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+		runes := []rune(words[i])
+		typeName.WriteString(strings.ToUpper(string(runes[0])) + string(runes[1:]))
+	}
+	name := strings.Join(words, "_")
+	if unicode.IsDigit([]rune(name)[0]) {
+		return "", fmt.Errorf("migration description must start with a letter")
+	}
+
+	var source bytes.Buffer
+	err := migrationTemplate.Execute(&source, map[string]string{
+		"Type":        typeName.String() + "Migration" + version,
+		"Name":        name,
+		"Version":     version,
+		"Description": strings.Join(strings.Fields(description), " "),
+	})
+	if err != nil {
+		return "", err
+	}
+	formatted, err := format.Source(source.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to format migration: %w", err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%s_%s_migration.go", version, name))
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(formatted); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// NextVersion returns the version after the highest registered migration, zero-padded
+// to three digits
+func NextVersion() string {
+	var highest uint64
+	for _, migration := range GetAllMigrations() {
+		if n, err := strconv.ParseUint(migration.Version(), 10, 64); err == nil && n > highest {
+			highest = n
+		}
+	}
+	return fmt.Sprintf("%03d", highest+1)
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClientOptionsApplyPoolAndTimeouts(t *testing.T) {
+	config := DefaultMongoConfig()
+	config.URI = "mongodb://localhost:27017/graphql_service"
+	config.MaxPoolSize = 50
+	config.MinPoolSize = 5
+	config.ConnectTimeout = 3 * time.Second
+	config.SocketTimeout = 20 * time.Second
+	config.ServerSelectionTimeout = 2 * time.Second
+	config.ReadConcern = "majority"
+	config.WriteConcern = "2"
+
+	opts := config.ClientOptions()
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("ClientOptions() are invalid: %v", err)
+	}
+
+	if opts.MaxPoolSize == nil || *opts.MaxPoolSize != 50 {
+		t.Errorf("MaxPoolSize = %v, want 50", opts.MaxPoolSize)
+	}
+	if opts.MinPoolSize == nil || *opts.MinPoolSize != 5 {
+		t.Errorf("MinPoolSize = %v, want 5", opts.MinPoolSize)
+	}
+	if opts.ConnectTimeout == nil || *opts.ConnectTimeout != 3*time.Second {
+		t.Errorf("ConnectTimeout = %v, want 3s", opts.ConnectTimeout)
+	}
+	if opts.SocketTimeout == nil || *opts.SocketTimeout != 20*time.Second {
+		t.Errorf("SocketTimeout = %v, want 20s", opts.SocketTimeout)
+	}
+	if opts.ServerSelectionTimeout == nil || *opts.ServerSelectionTimeout != 2*time.Second {
+		t.Errorf("ServerSelectionTimeout = %v, want 2s", opts.ServerSelectionTimeout)
+	}
+	if opts.ReadConcern == nil || opts.ReadConcern.Level != "majority" {
+		t.Errorf("ReadConcern = %v, want majority", opts.ReadConcern)
+	}
+	if opts.WriteConcern == nil || opts.WriteConcern.W != 2 {
+		t.Errorf("WriteConcern = %v, want w=2", opts.WriteConcern)
+	}
+}
+
+func TestClientOptionsKeepServerDefaultConcerns(t *testing.T) {
+	opts := DefaultMongoConfig().ClientOptions()
+	if opts.ReadConcern != nil || opts.WriteConcern != nil {
+		t.Errorf("ReadConcern = %v, WriteConcern = %v, want the server defaults", opts.ReadConcern, opts.WriteConcern)
+	}
+}
+
+func TestLoadMongoConfig(t *testing.T) {
+	t.Setenv("MONGO_URI", "mongodb://db:27017")
+	t.Setenv("MONGO_DATABASE", "graphql_staging")
+	t.Setenv("MONGO_MAX_POOL_SIZE", "200")
+	t.Setenv("MONGO_MIN_POOL_SIZE", "20")
+	t.Setenv("MONGO_CONNECT_TIMEOUT", "4s")
+	t.Setenv("MONGO_SOCKET_TIMEOUT", "1m")
+	t.Setenv("MONGO_SERVER_SELECTION_TIMEOUT", "6s")
+	t.Setenv("MONGO_READ_CONCERN", "local")
+	t.Setenv("MONGO_WRITE_CONCERN", "majority")
+
+	config, err := LoadMongoConfig()
+	if err != nil {
+		t.Fatalf("LoadMongoConfig() error = %v", err)
+	}
+	if config.URI != "mongodb://db:27017" || config.Database != "graphql_staging" || config.MaxPoolSize != 200 || config.MinPoolSize != 20 ||
+		config.ConnectTimeout != 4*time.Second || config.SocketTimeout != time.Minute ||
+		config.ServerSelectionTimeout != 6*time.Second || config.ReadConcern != "local" ||
+		config.WriteConcern != "majority" {
+		t.Errorf("LoadMongoConfig() = %+v", *config)
+	}
+}
+
+func TestLoadMongoConfigRejectsInvalidSettings(t *testing.T) {
+	tests := map[string]string{
+		"MONGO_MAX_POOL_SIZE":            "0",
+		"MONGO_MIN_POOL_SIZE":            "1000",
+		"MONGO_CONNECT_TIMEOUT":          "0s",
+		"MONGO_SOCKET_TIMEOUT":           "soon",
+		"MONGO_SERVER_SELECTION_TIMEOUT": "-1s",
+		"MONGO_READ_CONCERN":             "eventual",
+		"MONGO_WRITE_CONCERN":            "-1",
+		"MONGO_CONNECT_ATTEMPTS":         "0",
+	}
+
+	for key, value := range tests {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			if _, err := LoadMongoConfig(); err == nil {
+				t.Errorf("LoadMongoConfig() with %s=%s error = nil, want an error", key, value)
+			}
+		})
+	}
+}
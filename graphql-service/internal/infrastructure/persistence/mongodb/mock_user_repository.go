@@ -36,6 +36,15 @@ func (r *MockUserRepository) Create(ctx context.Context, user *entity.User) erro
 	return nil
 }
 
+// CreateMany creates each user
+func (r *MockUserRepository) CreateMany(ctx context.Context, users []*entity.User) []error {
+	errs := make([]error, len(users))
+	for i, user := range users {
+		errs[i] = r.Create(ctx, user)
+	}
+	return errs
+}
+
 // GetByID finds a user by ID
 func (r *MockUserRepository) GetByID(ctx context.Context, id string) (*entity.User, error) {
 	user, exists := r.users[id]
@@ -68,10 +77,39 @@ func (r *MockUserRepository) GetByUsername(ctx context.Context, username string)
 // Update updates a user
 func (r *MockUserRepository) Update(ctx context.Context, user *entity.User) error {
 	user.UpdatedAt = time.Now()
+	user.Version++
 	r.users[user.GetID()] = user
 	return nil
 }
 
+// UpdateFields sets the given fields, bumping the version
+func (r *MockUserRepository) UpdateFields(ctx context.Context, id string, fields map[string]interface{}, expectedVersion *int64) (*entity.User, error) {
+	user, exists := r.users[id]
+	if !exists {
+		return nil, nil
+	}
+	if expectedVersion != nil && user.Version != *expectedVersion {
+		return nil, repository.ErrVersionConflict
+	}
+
+	for field, value := range fields {
+		value, _ := value.(string)
+		switch field {
+		case "username":
+			user.Username = value
+		case "email":
+			user.Email = value
+		case "firstName":
+			user.FirstName = value
+		case "lastName":
+			user.LastName = value
+		}
+	}
+	user.UpdatedAt = time.Now()
+	user.Version++
+	return user, nil
+}
+
 // Delete deletes a user by ID
 func (r *MockUserRepository) Delete(ctx context.Context, id string) error {
 	delete(r.users, id)
@@ -2,7 +2,9 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"graphql-service/internal/domain/user/entity"
 	"graphql-service/internal/domain/user/repository"
@@ -13,6 +15,9 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// cursorCloseTimeout bounds closing a cursor once its request is done
+const cursorCloseTimeout = 5 * time.Second
+
 // UserRepository implements the user repository interface using MongoDB
 type UserRepository struct {
 	collection *mongo.Collection
@@ -36,6 +41,44 @@ func (r *UserRepository) Create(ctx context.Context, user *entity.User) error {
 	return nil
 }
 
+// CreateMany creates users with one unordered insert, so a failing user does not stop the
+// others from being inserted
+func (r *UserRepository) CreateMany(ctx context.Context, users []*entity.User) []error {
+	errs := make([]error, len(users))
+	if len(users) == 0 {
+		return errs
+	}
+
+	docs := make([]interface{}, len(users))
+	for i, user := range users {
+		if user.ID.IsZero() {
+			user.ID = primitive.NewObjectID()
+		}
+		docs[i] = user
+	}
+
+	_, err := r.collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		return errs
+	}
+
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil {
+		for _, writeErr := range bulkErr.WriteErrors {
+			if writeErr.Index >= 0 && writeErr.Index < len(errs) {
+				errs[writeErr.Index] = fmt.Errorf("failed to create user: %w", writeErr.WriteError)
+			}
+		}
+		return errs
+	}
+
+	// The failure is not attributable to single users, so none can be assumed created
+	for i := range errs {
+		errs[i] = fmt.Errorf("failed to create users: %w", err)
+	}
+	return errs
+}
+
 // GetByID finds a user by ID
 func (r *UserRepository) GetByID(ctx context.Context, id string) (*entity.User, error) {
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -90,6 +133,7 @@ func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
 		return fmt.Errorf("invalid user ID: %w", err)
 	}
 
+	user.Version++
 	_, err = r.collection.ReplaceOne(ctx, bson.M{"_id": objectID}, user)
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
@@ -98,6 +142,52 @@ func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
 	return nil
 }
 
+// UpdateFields sets only the given fields with a single atomic update, bumping the version
+func (r *UserRepository) UpdateFields(ctx context.Context, id string, fields map[string]interface{}, expectedVersion *int64) (*entity.User, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	set := bson.M{"updatedAt": time.Now()}
+	for field, value := range fields {
+		set[field] = value
+	}
+	update := bson.M{"$set": set, "$inc": bson.M{"version": 1}}
+
+	filter := bson.M{"_id": objectID}
+	if expectedVersion != nil {
+		filter["version"] = *expectedVersion
+		if *expectedVersion == 0 {
+			// Users created before versioning have no version field
+			filter["version"] = bson.M{"$in": bson.A{0, nil}}
+		}
+	}
+
+	var user entity.User
+	err = r.collection.FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&user)
+	if err == nil {
+		return &user, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	// Nothing matched: either the user does not exist or its version moved on
+	if expectedVersion == nil {
+		return nil, nil
+	}
+	count, err := r.collection.CountDocuments(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	if count == 0 {
+		return nil, nil
+	}
+	return nil, repository.ErrVersionConflict
+}
+
 // Delete deletes a user by ID
 func (r *UserRepository) Delete(ctx context.Context, id string) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -141,7 +231,7 @@ func (r *UserRepository) GetAll(ctx context.Context, filter map[string]interface
 	if err != nil {
 		return nil, fmt.Errorf("failed to find users: %w", err)
 	}
-	defer cursor.Close(ctx)
+	defer closeCursor(ctx, cursor)
 
 	var users []*entity.User
 	if err = cursor.All(ctx, &users); err != nil {
@@ -167,3 +257,11 @@ func (r *UserRepository) Count(ctx context.Context, filter map[string]interface{
 
 	return count, nil
 }
+
+// closeCursor closes cursor without the cancellation of ctx, so the server-side cursor is
+// still killed when the request timed out or was canceled
+func closeCursor(ctx context.Context, cursor *mongo.Cursor) {
+	closeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cursorCloseTimeout)
+	defer cancel()
+	cursor.Close(closeCtx)
+}
@@ -0,0 +1,101 @@
+package graphql
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend-core/ctxkeys"
+	"backend-core/logging"
+)
+
+// Headers the request and correlation IDs are read from and returned in, matching the
+// request correlation middleware of the other services
+const (
+	requestIDHeader     = "X-Request-ID"
+	correlationIDHeader = "X-Correlation-ID"
+	traceparentHeader   = "traceparent"
+)
+
+// withRequestCorrelation gives every request a request ID, taken from the X-Request-ID
+// header or generated, and a correlation ID, taken from X-Correlation-ID or the request ID.
+// Both are returned in the response headers and stored in the request context together with
+// the trace ID of a W3C traceparent header, so everything logged with logger.WithContext
+// carries them. The completion of every request is logged.
+func withRequestCorrelation(next http.Handler, logger *logging.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestIDHeader)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		correlationID := r.Header.Get(correlationIDHeader)
+		if correlationID == "" {
+			correlationID = requestID
+		}
+
+		w.Header().Set(requestIDHeader, requestID)
+		w.Header().Set(correlationIDHeader, correlationID)
+
+		ctx := ctxkeys.WithRequestID(r.Context(), requestID)
+		ctx = ctxkeys.WithCorrelationID(ctx, correlationID)
+		traceID := traceIDFromTraceparent(r.Header.Get(traceparentHeader))
+		if traceID != "" {
+			ctx = context.WithValue(ctx, ctxkeys.TraceID, traceID)
+		}
+
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		fields := []interface{}{
+			"request_id", requestID,
+			"correlation_id", correlationID,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", recorder.status,
+			"duration_ms", time.Since(start).Milliseconds(),
+		}
+		if traceID != "" {
+			fields = append(fields, "trace_id", traceID)
+		}
+		logger.Info("request completed", fields...)
+	})
+}
+
+// traceIDFromTraceparent returns the trace ID of a W3C traceparent header
+// ("00-<trace-id>-<parent-id>-<flags>"), or "" if the header is missing or malformed
+func traceIDFromTraceparent(traceparent string) string {
+	parts := strings.Split(traceparent, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 {
+		return ""
+	}
+	if _, err := hex.DecodeString(parts[1]); err != nil || parts[1] == strings.Repeat("0", 32) {
+		return ""
+	}
+	return parts[1]
+}
+
+// newRequestID returns a random UUID v4
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	s := hex.EncodeToString(b)
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:32]
+}
+
+// statusRecorder records the status code written through it
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
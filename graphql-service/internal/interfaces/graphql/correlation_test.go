@@ -0,0 +1,157 @@
+package graphql
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"graphql-service/internal/domain/user/entity"
+	"graphql-service/internal/domain/user/repository"
+	"graphql-service/internal/interfaces/graphql/resolvers"
+
+	"backend-core/config"
+	"backend-core/logging"
+)
+
+// newFileLogger returns a JSON logger writing to a file, and a function reading the lines
+// logged so far
+func newFileLogger(t *testing.T) (*logging.Logger, func() []map[string]interface{}) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "graphql.log")
+	logger, err := logging.NewLogger(&config.LoggingConfig{Level: "info", Format: "json", Output: "file", FilePath: path})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+
+	return logger, func() []map[string]interface{} {
+		t.Helper()
+
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("failed to open log: %v", err)
+		}
+		defer file.Close()
+
+		var lines []map[string]interface{}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var line map[string]interface{}
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+				t.Fatalf("log line %q is not JSON: %v", scanner.Text(), err)
+			}
+			lines = append(lines, line)
+		}
+		return lines
+	}
+}
+
+// findLogLine returns the first line logged with message, or nil
+func findLogLine(lines []map[string]interface{}, message string) map[string]interface{} {
+	for _, line := range lines {
+		if line["msg"] == message {
+			return line
+		}
+	}
+	return nil
+}
+
+// createOnlyUserRepository stores nothing and accepts every Create
+type createOnlyUserRepository struct {
+	repository.UserRepository
+}
+
+func (r *createOnlyUserRepository) Create(ctx context.Context, user *entity.User) error {
+	return nil
+}
+
+func TestGraphQLRequestIsLoggedWithRequestID(t *testing.T) {
+	logger, readLog := newFileLogger(t)
+	server := NewTestServer(resolvers.NewUserResolver(&createOnlyUserRepository{}, logger), DefaultServerConfig(), logger)
+
+	body := `{
+		"query": "mutation CreateUser($input: UserInput!) { createUser(input: $input) { id } }",
+		"variables": {"input": {"username": "alice", "email": "alice@example.com", "firstName": "Alice", "lastName": "Smith"}}
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(requestIDHeader, "req-123")
+	req.Header.Set(traceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+
+	server.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if got := rec.Header().Get(requestIDHeader); got != "req-123" {
+		t.Errorf("%s = %q, want req-123", requestIDHeader, got)
+	}
+	if got := rec.Header().Get(correlationIDHeader); got != "req-123" {
+		t.Errorf("%s = %q, want the request ID", correlationIDHeader, got)
+	}
+
+	lines := readLog()
+	for _, message := range []string{"user created", "request completed"} {
+		line := findLogLine(lines, message)
+		if line == nil {
+			t.Errorf("no %q log line in %v", message, lines)
+			continue
+		}
+		if line["request_id"] != "req-123" || line["correlation_id"] != "req-123" {
+			t.Errorf("%q logged request_id %v and correlation_id %v, want req-123", message, line["request_id"], line["correlation_id"])
+		}
+		if line["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Errorf("%q logged trace_id %v, want the traceparent trace ID", message, line["trace_id"])
+		}
+	}
+}
+
+func TestRequestIDIsGeneratedWhenMissing(t *testing.T) {
+	logger, readLog := newFileLogger(t)
+	handler := withRequestCorrelation(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}), logger)
+
+	req := httptest.NewRequest(http.MethodGet, "/livez", nil)
+	req.Header.Set(correlationIDHeader, "corr-1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	requestID := rec.Header().Get(requestIDHeader)
+	if len(requestID) != 36 {
+		t.Fatalf("%s = %q, want a generated UUID", requestIDHeader, requestID)
+	}
+	if got := rec.Header().Get(correlationIDHeader); got != "corr-1" {
+		t.Errorf("%s = %q, want corr-1", correlationIDHeader, got)
+	}
+
+	line := findLogLine(readLog(), "request completed")
+	if line == nil {
+		t.Fatal("no request completed log line")
+	}
+	if line["request_id"] != requestID || line["correlation_id"] != "corr-1" || line["status"] != float64(http.StatusTeapot) {
+		t.Errorf("logged %v, want request_id %s, correlation_id corr-1 and status 418", line, requestID)
+	}
+}
+
+func TestTraceIDFromTraceparent(t *testing.T) {
+	tests := map[string]string{
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01": "4bf92f3577b34da6a3ce929d0e0e4736",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01": "",
+		"00-not-hex-at-all-00f067aa0ba902b7-01":                   "",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736":                     "",
+		"":                                                        "",
+	}
+	for traceparent, want := range tests {
+		if got := traceIDFromTraceparent(traceparent); got != want {
+			t.Errorf("traceIDFromTraceparent(%q) = %q, want %q", traceparent, got, want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend-core/logging"
+
+	"graphql-service/internal/infrastructure/database/migration"
+	// Register the service's migrations with the runner
+	_ "graphql-service/internal/infrastructure/database/migration/migrations"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// readinessTimeout bounds a readiness check, so a hung database fails the probe instead
+// of holding it
+const readinessTimeout = 3 * time.Second
+
+// ReadinessReport is the outcome of a readiness check
+type ReadinessReport struct {
+	Ready bool
+	// Checks holds the status of each dependency checked
+	Checks            map[string]string
+	PendingMigrations []string
+}
+
+// ReadinessCheck reports whether the server can serve requests
+type ReadinessCheck func(ctx context.Context) ReadinessReport
+
+// MongoReadinessCheck returns a readiness check that is ready once MongoDB answers a ping
+// and all migrations have been applied. The readiness endpoint is unauthenticated, so
+// errors are logged and the report only says "unreachable" or "unknown".
+func MongoReadinessCheck(db *mongo.Database, logger *logging.Logger) ReadinessCheck {
+	runner := migration.NewMigrationRunner(db, logger)
+	ping := func(ctx context.Context) error {
+		return db.Client().Ping(ctx, nil)
+	}
+	return newReadinessCheck(ping, runner.PendingMigrations, logger)
+}
+
+// newReadinessCheck returns a readiness check that is ready once ping succeeds and
+// pendingMigrations reports none pending
+func newReadinessCheck(ping func(ctx context.Context) error, pendingMigrations func(ctx context.Context) ([]string, error), logger *logging.Logger) ReadinessCheck {
+	return func(ctx context.Context) ReadinessReport {
+		report := ReadinessReport{Checks: make(map[string]string)}
+
+		if err := ping(ctx); err != nil {
+			logger.Warn("Readiness check failed to reach MongoDB", logging.Error(err))
+			report.Checks["mongodb"] = "unreachable"
+			report.Checks["migrations"] = "unknown"
+			return report
+		}
+		report.Checks["mongodb"] = "ok"
+
+		pending, err := pendingMigrations(ctx)
+		if err != nil {
+			logger.Warn("Readiness check failed to list pending migrations", logging.Error(err))
+			report.Checks["migrations"] = "unknown"
+			return report
+		}
+		if len(pending) > 0 {
+			report.Checks["migrations"] = "pending: " + strings.Join(pending, ", ")
+			report.PendingMigrations = pending
+			return report
+		}
+		report.Checks["migrations"] = "applied"
+
+		report.Ready = true
+		return report
+	}
+}
+
+// healthResponse is the body of the liveness and readiness endpoints
+type healthResponse struct {
+	Status            string            `json:"status"`
+	Service           string            `json:"service"`
+	Checks            map[string]string `json:"checks,omitempty"`
+	PendingMigrations []string          `json:"pendingMigrations,omitempty"`
+}
+
+// serveLiveness reports that the process is up. It checks no dependencies, so an
+// unavailable database does not get the server restarted.
+func serveLiveness(w http.ResponseWriter, service string) {
+	writeJSON(w, http.StatusOK, healthResponse{Status: "alive", Service: service})
+}
+
+// serveReadiness answers 200 when check reports ready and 503 otherwise. Without a check
+// the server is always ready.
+func serveReadiness(w http.ResponseWriter, r *http.Request, service string, check ReadinessCheck) {
+	report := ReadinessReport{Ready: true}
+	if check != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+		report = check(ctx)
+	}
+
+	response := healthResponse{
+		Status:            "ready",
+		Service:           service,
+		Checks:            report.Checks,
+		PendingMigrations: report.PendingMigrations,
+	}
+	code := http.StatusOK
+	if !report.Ready {
+		response.Status = "not ready"
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, response)
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
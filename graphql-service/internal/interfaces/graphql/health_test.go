@@ -0,0 +1,92 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend-core/logging"
+)
+
+// readyz serves one readiness probe with check and returns the status code and body
+func readyz(t *testing.T, check ReadinessCheck) (int, healthResponse, string) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	serveReadiness(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil), "graphql-service", check)
+
+	var body healthResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	return rec.Code, body, rec.Body.String()
+}
+
+func TestReadinessProbe(t *testing.T) {
+	reachable := func(context.Context) error { return nil }
+	nonePending := func(context.Context) ([]string, error) { return nil, nil }
+
+	tests := []struct {
+		name       string
+		ping       func(context.Context) error
+		pending    func(context.Context) ([]string, error)
+		wantCode   int
+		wantChecks map[string]string
+	}{
+		{
+			name:       "ready",
+			ping:       reachable,
+			pending:    nonePending,
+			wantCode:   http.StatusOK,
+			wantChecks: map[string]string{"mongodb": "ok", "migrations": "applied"},
+		},
+		{
+			name:       "database unreachable",
+			ping:       func(context.Context) error { return errors.New("dial tcp mongo-0.internal:27017: auth failed") },
+			pending:    nonePending,
+			wantCode:   http.StatusServiceUnavailable,
+			wantChecks: map[string]string{"mongodb": "unreachable", "migrations": "unknown"},
+		},
+		{
+			name:       "migrations unknown",
+			ping:       reachable,
+			pending:    func(context.Context) ([]string, error) { return nil, errors.New("mongo-0.internal: unauthorized") },
+			wantCode:   http.StatusServiceUnavailable,
+			wantChecks: map[string]string{"mongodb": "ok", "migrations": "unknown"},
+		},
+		{
+			name:       "migrations pending",
+			ping:       reachable,
+			pending:    func(context.Context) ([]string, error) { return []string{"003"}, nil },
+			wantCode:   http.StatusServiceUnavailable,
+			wantChecks: map[string]string{"mongodb": "ok", "migrations": "pending: 003"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body, raw := readyz(t, newReadinessCheck(tt.ping, tt.pending, logging.NewNopLogger()))
+
+			if code != tt.wantCode {
+				t.Errorf("status = %d, want %d", code, tt.wantCode)
+			}
+			for check, want := range tt.wantChecks {
+				if body.Checks[check] != want {
+					t.Errorf("check %s = %q, want %q", check, body.Checks[check], want)
+				}
+			}
+			if strings.Contains(raw, "mongo-0.internal") {
+				t.Errorf("response leaks the error: %s", raw)
+			}
+		})
+	}
+}
+
+func TestReadinessProbeWithoutCheck(t *testing.T) {
+	if code, body, _ := readyz(t, nil); code != http.StatusOK || body.Status != "ready" {
+		t.Errorf("status = %d %q, want %d ready", code, body.Status, http.StatusOK)
+	}
+}
@@ -0,0 +1,246 @@
+package graphql
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// playgroundPath is where both servers serve the GraphQL playground
+const playgroundPath = "/"
+
+// HTTPSecurityConfig holds the CORS and security header configuration of the GraphQL servers
+type HTTPSecurityConfig struct {
+	CORS    CORSConfig
+	Headers SecurityHeadersConfig
+}
+
+// CORSConfig holds CORS configuration. Requests from origins that are not allowed get no
+// CORS headers, and their preflight requests are refused.
+type CORSConfig struct {
+	// AllowedOrigins are the origins browsers may call the server from; "*" allows any
+	// origin. Without any, only same-origin requests work from a browser.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration
+}
+
+// SecurityHeadersConfig holds the security headers set on every response. Empty headers
+// are not set.
+type SecurityHeadersConfig struct {
+	ContentTypeOptions    string
+	FrameOptions          string
+	ReferrerPolicy        string
+	ContentSecurityPolicy string
+	// PlaygroundContentSecurityPolicy replaces ContentSecurityPolicy on the playground page,
+	// which loads its scripts and styles from a CDN
+	PlaygroundContentSecurityPolicy string
+}
+
+// DefaultHTTPSecurityConfig returns the default configuration, which allows no cross-origin
+// requests
+func DefaultHTTPSecurityConfig() *HTTPSecurityConfig {
+	return &HTTPSecurityConfig{
+		CORS: CORSConfig{
+			AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodOptions},
+			AllowedHeaders: []string{"Content-Type", "Authorization", requestIDHeader, correlationIDHeader, traceparentHeader},
+			ExposedHeaders: []string{requestIDHeader, correlationIDHeader},
+			MaxAge:         10 * time.Minute,
+		},
+		Headers: SecurityHeadersConfig{
+			ContentTypeOptions:    "nosniff",
+			FrameOptions:          "DENY",
+			ReferrerPolicy:        "strict-origin-when-cross-origin",
+			ContentSecurityPolicy: "default-src 'none'; frame-ancestors 'none'",
+			PlaygroundContentSecurityPolicy: "default-src 'self'; " +
+				"script-src 'self' 'unsafe-inline' https://cdn.jsdelivr.net; " +
+				"style-src 'self' 'unsafe-inline' https://cdn.jsdelivr.net; " +
+				"img-src 'self' data: https://cdn.jsdelivr.net; " +
+				"font-src 'self' https://cdn.jsdelivr.net; " +
+				"frame-ancestors 'none'",
+		},
+	}
+}
+
+// LoadHTTPSecurityConfig returns the default configuration with the CORS settings overridden
+// by GRAPHQL_CORS_ALLOWED_ORIGINS, GRAPHQL_CORS_ALLOWED_METHODS, GRAPHQL_CORS_ALLOWED_HEADERS
+// (comma-separated), GRAPHQL_CORS_ALLOW_CREDENTIALS and GRAPHQL_CORS_MAX_AGE
+func LoadHTTPSecurityConfig() (*HTTPSecurityConfig, error) {
+	config := DefaultHTTPSecurityConfig()
+
+	if value := os.Getenv("GRAPHQL_CORS_ALLOWED_ORIGINS"); value != "" {
+		config.CORS.AllowedOrigins = splitList(value)
+	}
+	if value := os.Getenv("GRAPHQL_CORS_ALLOWED_METHODS"); value != "" {
+		config.CORS.AllowedMethods = splitList(value)
+	}
+	if value := os.Getenv("GRAPHQL_CORS_ALLOWED_HEADERS"); value != "" {
+		config.CORS.AllowedHeaders = splitList(value)
+	}
+	if value := os.Getenv("GRAPHQL_CORS_ALLOW_CREDENTIALS"); value != "" {
+		allow, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GRAPHQL_CORS_ALLOW_CREDENTIALS: %w", err)
+		}
+		config.CORS.AllowCredentials = allow
+	}
+	if value := os.Getenv("GRAPHQL_CORS_MAX_AGE"); value != "" {
+		maxAge, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GRAPHQL_CORS_MAX_AGE: %w", err)
+		}
+		config.CORS.MaxAge = maxAge
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate checks that the configuration is usable
+func (c *HTTPSecurityConfig) Validate() error {
+	// Browsers reject credentialed responses allowing any origin, and echoing every origin
+	// back instead would let any site make authenticated requests
+	if c.CORS.AllowCredentials && c.CORS.allowsAnyOrigin() {
+		return fmt.Errorf("CORS credentials cannot be allowed for any origin")
+	}
+	if c.CORS.MaxAge < 0 {
+		return fmt.Errorf("CORS max age must not be negative")
+	}
+	return nil
+}
+
+// withHTTPSecurity wraps next with the security headers and CORS handling of config
+func withHTTPSecurity(next http.Handler, config *HTTPSecurityConfig) http.Handler {
+	if config == nil {
+		config = DefaultHTTPSecurityConfig()
+	}
+	return securityHeaders(cors(next, &config.CORS), &config.Headers)
+}
+
+// securityHeaders sets the configured security headers on every response
+func securityHeaders(next http.Handler, config *SecurityHeadersConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := w.Header()
+		setIfNotEmpty(header, "X-Content-Type-Options", config.ContentTypeOptions)
+		setIfNotEmpty(header, "X-Frame-Options", config.FrameOptions)
+		setIfNotEmpty(header, "Referrer-Policy", config.ReferrerPolicy)
+
+		csp := config.ContentSecurityPolicy
+		if r.URL.Path == playgroundPath && config.PlaygroundContentSecurityPolicy != "" {
+			csp = config.PlaygroundContentSecurityPolicy
+		}
+		setIfNotEmpty(header, "Content-Security-Policy", csp)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// cors answers preflight requests and sets the CORS headers on requests from allowed origins
+func cors(next http.Handler, config *CORSConfig) http.Handler {
+	allowedMethods := strings.Join(config.AllowedMethods, ", ")
+	allowedHeaders := strings.Join(config.AllowedHeaders, ", ")
+	exposedHeaders := strings.Join(config.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(config.MaxAge.Seconds()))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := w.Header()
+		// Responses differ by origin, so caches must not share them between origins
+		header.Add("Vary", "Origin")
+
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !config.isOriginAllowed(origin) ||
+			preflight && !containsFold(config.AllowedMethods, r.Header.Get("Access-Control-Request-Method")) {
+			if preflight {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if config.allowsAnyOrigin() {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if config.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+			setIfNotEmpty(header, "Access-Control-Allow-Methods", allowedMethods)
+			setIfNotEmpty(header, "Access-Control-Allow-Headers", allowedHeaders)
+			if config.MaxAge > 0 {
+				header.Set("Access-Control-Max-Age", maxAge)
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		setIfNotEmpty(header, "Access-Control-Expose-Headers", exposedHeaders)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isOriginAllowed reports whether origin is one of the allowed origins
+func (c *CORSConfig) isOriginAllowed(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsAnyOrigin reports whether the allowed origins include "*"
+func (c *CORSConfig) allowsAnyOrigin() bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func setIfNotEmpty(header http.Header, key, value string) {
+	if value != "" {
+		header.Set(key, value)
+	}
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
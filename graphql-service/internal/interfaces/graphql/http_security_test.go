@@ -0,0 +1,173 @@
+package graphql
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"backend-core/logging"
+)
+
+// newSecuredServer serves the test server's routes with the HTTP security settings of config
+func newSecuredServer(t *testing.T, config *HTTPSecurityConfig) http.Handler {
+	t.Helper()
+
+	serverConfig := DefaultServerConfig()
+	serverConfig.HTTPSecurity = config
+	return NewTestServer(nil, serverConfig, logging.NewNopLogger()).Handler()
+}
+
+func corsConfig(origins ...string) *HTTPSecurityConfig {
+	config := DefaultHTTPSecurityConfig()
+	config.CORS.AllowedOrigins = origins
+	return config
+}
+
+func preflight(origin, method string) *http.Request {
+	req := httptest.NewRequest(http.MethodOptions, "/query", nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", method)
+	req.Header.Set("Access-Control-Request-Headers", "Content-Type, Authorization")
+	return req
+}
+
+func TestPreflightFromAllowedOrigin(t *testing.T) {
+	config := corsConfig("https://app.example.com")
+	config.CORS.AllowCredentials = true
+	rec := httptest.NewRecorder()
+
+	newSecuredServer(t, config).ServeHTTP(rec, preflight("https://app.example.com", http.MethodPost))
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	want := map[string]string{
+		"Access-Control-Allow-Origin":      "https://app.example.com",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Methods":     "GET, POST, OPTIONS",
+		"Access-Control-Max-Age":           "600",
+	}
+	for header, value := range want {
+		if got := rec.Header().Get(header); got != value {
+			t.Errorf("%s = %q, want %q", header, got, value)
+		}
+	}
+	if rec.Header().Get("Access-Control-Allow-Headers") == "" {
+		t.Error("expected Access-Control-Allow-Headers to be set")
+	}
+}
+
+func TestPreflightRefused(t *testing.T) {
+	tests := []struct {
+		name    string
+		origins []string
+		origin  string
+		method  string
+	}{
+		{name: "no origins allowed", origin: "https://app.example.com", method: http.MethodPost},
+		{name: "other origin", origins: []string{"https://app.example.com"}, origin: "https://evil.example.com", method: http.MethodPost},
+		{name: "method not allowed", origins: []string{"https://app.example.com"}, origin: "https://app.example.com", method: http.MethodDelete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+
+			newSecuredServer(t, corsConfig(tt.origins...)).ServeHTTP(rec, preflight(tt.origin, tt.method))
+
+			if rec.Code != http.StatusForbidden {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusForbidden)
+			}
+			if origin := rec.Header().Get("Access-Control-Allow-Origin"); origin != "" {
+				t.Errorf("Access-Control-Allow-Origin = %q, want none", origin)
+			}
+		})
+	}
+}
+
+func TestCORSHeadersOnRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		origins    []string
+		origin     string
+		wantOrigin string
+	}{
+		{name: "allowed origin", origins: []string{"https://app.example.com"}, origin: "https://app.example.com", wantOrigin: "https://app.example.com"},
+		{name: "any origin", origins: []string{"*"}, origin: "https://app.example.com", wantOrigin: "*"},
+		{name: "other origin", origins: []string{"https://app.example.com"}, origin: "https://evil.example.com"},
+		{name: "same origin", origins: []string{"https://app.example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/livez", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rec := httptest.NewRecorder()
+
+			newSecuredServer(t, corsConfig(tt.origins...)).ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			if origin := rec.Header().Get("Access-Control-Allow-Origin"); origin != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", origin, tt.wantOrigin)
+			}
+			if tt.wantOrigin != "" && rec.Header().Get("Access-Control-Expose-Headers") == "" {
+				t.Error("expected Access-Control-Expose-Headers to be set")
+			}
+		})
+	}
+}
+
+func TestSecurityHeadersOnResponses(t *testing.T) {
+	config := DefaultHTTPSecurityConfig()
+	handler := newSecuredServer(t, config)
+
+	for _, path := range []string{"/livez", "/", "/missing"} {
+		t.Run(path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+			wantCSP := config.Headers.ContentSecurityPolicy
+			if path == playgroundPath {
+				wantCSP = config.Headers.PlaygroundContentSecurityPolicy
+			}
+			want := map[string]string{
+				"X-Content-Type-Options":  "nosniff",
+				"X-Frame-Options":         "DENY",
+				"Referrer-Policy":         "strict-origin-when-cross-origin",
+				"Content-Security-Policy": wantCSP,
+			}
+			for header, value := range want {
+				if got := rec.Header().Get(header); got != value {
+					t.Errorf("%s = %q, want %q", header, got, value)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadHTTPSecurityConfig(t *testing.T) {
+	t.Setenv("GRAPHQL_CORS_ALLOWED_ORIGINS", "https://a.example.com, https://b.example.com")
+	t.Setenv("GRAPHQL_CORS_ALLOW_CREDENTIALS", "true")
+	t.Setenv("GRAPHQL_CORS_MAX_AGE", "1h")
+
+	config, err := LoadHTTPSecurityConfig()
+	if err != nil {
+		t.Fatalf("LoadHTTPSecurityConfig() error = %v", err)
+	}
+	if len(config.CORS.AllowedOrigins) != 2 || config.CORS.AllowedOrigins[1] != "https://b.example.com" {
+		t.Errorf("AllowedOrigins = %v", config.CORS.AllowedOrigins)
+	}
+	if !config.CORS.AllowCredentials || config.CORS.MaxAge != time.Hour {
+		t.Errorf("AllowCredentials = %v, MaxAge = %v, want true and 1h", config.CORS.AllowCredentials, config.CORS.MaxAge)
+	}
+
+	t.Setenv("GRAPHQL_CORS_ALLOWED_ORIGINS", "*")
+	if _, err := LoadHTTPSecurityConfig(); err == nil {
+		t.Error("expected credentials with any origin to be rejected")
+	}
+}
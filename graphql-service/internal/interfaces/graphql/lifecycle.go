@@ -0,0 +1,46 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// httpLifecycle runs the HTTP server of a GraphQL server and shuts it down gracefully
+type httpLifecycle struct {
+	mu       sync.Mutex
+	server   *http.Server
+	shutdown bool
+}
+
+// serve listens on addr until shutdown is called. It returns nil once shut down.
+func (l *httpLifecycle) serve(addr string, handler http.Handler) error {
+	l.mu.Lock()
+	if l.shutdown {
+		l.mu.Unlock()
+		return nil
+	}
+	l.server = &http.Server{Addr: addr, Handler: handler}
+	server := l.server
+	l.mu.Unlock()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// stop closes the listener, so new connections are refused, and waits for in-flight
+// requests to complete until ctx is done
+func (l *httpLifecycle) stop(ctx context.Context) error {
+	l.mu.Lock()
+	l.shutdown = true
+	server := l.server
+	l.mu.Unlock()
+
+	if server == nil {
+		return nil
+	}
+	return server.Shutdown(ctx)
+}
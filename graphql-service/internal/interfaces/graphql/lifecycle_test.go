@@ -0,0 +1,127 @@
+package graphql
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"graphql-service/internal/domain/user/entity"
+	"graphql-service/internal/domain/user/repository"
+	"graphql-service/internal/interfaces/graphql/resolvers"
+
+	"backend-core/logging"
+)
+
+// gatedUserRepository holds GetAll until release is closed, reporting on entered when a
+// query starts
+type gatedUserRepository struct {
+	repository.UserRepository
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (r *gatedUserRepository) GetAll(ctx context.Context, filter map[string]interface{}, pagination map[string]interface{}) ([]*entity.User, error) {
+	r.entered <- struct{}{}
+	<-r.release
+	return nil, nil
+}
+
+// freePort returns a port nothing is listening on
+func freePort(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	return strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+}
+
+// waitFor polls condition until it holds or a second has passed
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestShutdownDrainsInFlightRequestsAndRefusesNewOnes(t *testing.T) {
+	repo := &gatedUserRepository{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	logger := logging.NewNopLogger()
+	server := NewTestServer(resolvers.NewUserResolver(repo, logger), DefaultServerConfig(), logger)
+
+	port := freePort(t)
+	addr := "127.0.0.1:" + port
+	started := make(chan error, 1)
+	go func() { started <- server.Start(port) }()
+	waitFor(t, "the server to listen", func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+		}
+		return err == nil
+	})
+
+	// Start a request that stays in flight until the repository is released
+	inFlight := make(chan int, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr + "/test/users")
+		if err != nil {
+			inFlight <- 0
+			return
+		}
+		resp.Body.Close()
+		inFlight <- resp.StatusCode
+	}()
+	<-repo.entered
+
+	shutdownDone := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownDone <- server.Shutdown(ctx)
+	}()
+
+	waitFor(t, "new connections to be refused", func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+		}
+		return err != nil
+	})
+	select {
+	case err := <-shutdownDone:
+		t.Fatalf("Shutdown returned %v before the in-flight request completed", err)
+	default:
+	}
+
+	close(repo.release)
+	if status := <-inFlight; status != http.StatusOK {
+		t.Errorf("in-flight request status = %d, want %d", status, http.StatusOK)
+	}
+	if err := <-shutdownDone; err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if err := <-started; err != nil {
+		t.Errorf("Start() error = %v, want nil after shutdown", err)
+	}
+}
+
+func TestStartAfterShutdownReturnsImmediately(t *testing.T) {
+	server := NewTestServer(nil, DefaultServerConfig(), logging.NewNopLogger())
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := server.Start(freePort(t)); err != nil {
+		t.Errorf("Start() error = %v, want nil", err)
+	}
+}
@@ -2,20 +2,26 @@ package resolvers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"backend-core/logging"
+
 	"graphql-service/internal/domain/user/entity"
 	"graphql-service/internal/domain/user/repository"
 )
 
+// ErrRequestTimeout is returned by resolvers whose request ran out of time
+var ErrRequestTimeout = errors.New("request timed out")
+
 // UserResolver handles GraphQL user queries and mutations
 type UserResolver struct {
 	userRepo repository.UserRepository
-	logger   interface{} // Replace with actual logger type
+	logger   *logging.Logger
 }
 
 // NewUserResolver creates a new user resolver
-func NewUserResolver(userRepo repository.UserRepository, logger interface{}) *UserResolver {
+func NewUserResolver(userRepo repository.UserRepository, logger *logging.Logger) *UserResolver {
 	return &UserResolver{
 		userRepo: userRepo,
 		logger:   logger,
@@ -26,7 +32,7 @@ func NewUserResolver(userRepo repository.UserRepository, logger interface{}) *Us
 func (r *UserResolver) User(ctx context.Context, id string) (*entity.User, error) {
 	user, err := r.userRepo.GetByID(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return nil, r.resolverError(ctx, "failed to get user", err)
 	}
 	return user, nil
 }
@@ -61,57 +67,108 @@ func (r *UserResolver) Users(ctx context.Context, filter map[string]interface{},
 
 	users, err := r.userRepo.GetAll(ctx, repoFilter, repoPagination)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get users: %w", err)
+		return nil, r.resolverError(ctx, "failed to get users", err)
 	}
 
 	return users, nil
 }
 
-// CreateUser creates a new user
+// CreateUser creates a new user. Invalid input is rejected with a *ValidationError.
 func (r *UserResolver) CreateUser(ctx context.Context, input map[string]interface{}) (*entity.User, error) {
-	username, _ := input["username"].(string)
-	email, _ := input["email"].(string)
-	firstName, _ := input["firstName"].(string)
-	lastName, _ := input["lastName"].(string)
+	fields, err := parseUserInput(input, true)
+	if err != nil {
+		return nil, err
+	}
 
-	user := entity.NewUser(username, email, firstName, lastName)
+	user := entity.NewUser(*fields.username, *fields.email, *fields.firstName, *fields.lastName)
 
-	err := r.userRepo.Create(ctx, user)
+	err = r.userRepo.Create(ctx, user)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
+		return nil, r.resolverError(ctx, "failed to create user", err)
 	}
 
+	r.logger.WithContext(ctx).Info("user created", logging.String("user_id", user.GetID()))
+
 	return user, nil
 }
 
-// UpdateUser updates an existing user
-func (r *UserResolver) UpdateUser(ctx context.Context, id string, input map[string]interface{}) (*entity.User, error) {
-	user, err := r.userRepo.GetByID(ctx, id)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
-	}
-	if user == nil {
-		return nil, fmt.Errorf("user not found")
-	}
+// BulkCreateUserResult is the outcome of creating one user of a bulk import
+type BulkCreateUserResult struct {
+	Index       int          `json:"index"`
+	Success     bool         `json:"success"`
+	User        *entity.User `json:"user,omitempty"`
+	Error       string       `json:"error,omitempty"`
+	FieldErrors []FieldError `json:"fieldErrors,omitempty"`
+}
 
-	// Update fields if provided
-	if username, ok := input["username"].(string); ok {
-		user.Username = username
+// BulkCreateUsers creates up to MaxBulkCreateUsers users in one batch. Invalid or failing
+// users are reported in their result without stopping the others; only a batch that is
+// empty or too large is rejected as a whole, with a *ValidationError.
+func (r *UserResolver) BulkCreateUsers(ctx context.Context, inputs []map[string]interface{}) ([]*BulkCreateUserResult, error) {
+	if len(inputs) == 0 || len(inputs) > MaxBulkCreateUsers {
+		return nil, &ValidationError{Fields: []FieldError{{
+			Field:   "input",
+			Message: fmt.Sprintf("must contain between 1 and %d users", MaxBulkCreateUsers),
+		}}}
 	}
-	if email, ok := input["email"].(string); ok {
-		user.Email = email
-	}
-	if firstName, ok := input["firstName"].(string); ok {
-		user.FirstName = firstName
+
+	results := make([]*BulkCreateUserResult, len(inputs))
+	var users []*entity.User
+	var userResults []*BulkCreateUserResult
+	for i, input := range inputs {
+		results[i] = &BulkCreateUserResult{Index: i}
+
+		fields, err := parseUserInput(input, true)
+		if err != nil {
+			results[i].Error = err.Error()
+			var validationErr *ValidationError
+			if errors.As(err, &validationErr) {
+				results[i].FieldErrors = validationErr.Fields
+			}
+			continue
+		}
+		users = append(users, entity.NewUser(*fields.username, *fields.email, *fields.firstName, *fields.lastName))
+		userResults = append(userResults, results[i])
 	}
-	if lastName, ok := input["lastName"].(string); ok {
-		user.LastName = lastName
+
+	created := 0
+	for i, err := range r.userRepo.CreateMany(ctx, users) {
+		if err != nil {
+			userResults[i].Error = err.Error()
+			continue
+		}
+		userResults[i].Success = true
+		userResults[i].User = users[i]
+		created++
 	}
 
-	err = r.userRepo.Update(ctx, user)
+	r.logger.WithContext(ctx).Info("users imported",
+		logging.Int("created", created),
+		logging.Int("failed", len(inputs)-created))
+
+	return results, nil
+}
+
+// UpdateUser patches an existing user, changing only the fields given in input. With a
+// version in input, the update is rejected with repository.ErrVersionConflict unless the
+// user still has that version. Invalid input is rejected with a *ValidationError.
+func (r *UserResolver) UpdateUser(ctx context.Context, id string, input map[string]interface{}) (*entity.User, error) {
+	fields, err := parseUserInput(input, false)
 	if err != nil {
+		return nil, err
+	}
+
+	user, err := r.userRepo.UpdateFields(ctx, id, fields.storedFields(), fields.version)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		r.logger.WithContext(ctx).Warn("user update conflicted", logging.String("user_id", id))
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
+	if err != nil {
+		return nil, r.resolverError(ctx, "failed to update user", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user not found")
+	}
 
 	return user, nil
 }
@@ -120,7 +177,7 @@ func (r *UserResolver) UpdateUser(ctx context.Context, id string, input map[stri
 func (r *UserResolver) DeleteUser(ctx context.Context, id string) (bool, error) {
 	err := r.userRepo.Delete(ctx, id)
 	if err != nil {
-		return false, fmt.Errorf("failed to delete user: %w", err)
+		return false, r.resolverError(ctx, "failed to delete user", err)
 	}
 
 	return true, nil
@@ -149,3 +206,14 @@ func (r *UserResolver) CreatedAt(ctx context.Context, user *entity.User) (string
 func (r *UserResolver) UpdatedAt(ctx context.Context, user *entity.User) (string, error) {
 	return user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"), nil
 }
+
+// resolverError logs err with the request and correlation IDs of ctx and wraps it with
+// message, and with ErrRequestTimeout as well when the request deadline has passed
+func (r *UserResolver) resolverError(ctx context.Context, message string, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		r.logger.WithContext(ctx).Warn(message+": request timed out", logging.Error(err))
+		return fmt.Errorf("%s: %w: %w", message, ErrRequestTimeout, err)
+	}
+	r.logger.WithContext(ctx).Error(message, logging.Error(err))
+	return fmt.Errorf("%s: %w", message, err)
+}
@@ -0,0 +1,333 @@
+package resolvers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"graphql-service/internal/domain/user/entity"
+	"graphql-service/internal/domain/user/repository"
+	"graphql-service/internal/infrastructure/persistence/mongodb"
+
+	"backend-core/logging"
+)
+
+func newTestResolver(t *testing.T) (*UserResolver, repository.UserRepository) {
+	t.Helper()
+
+	logger := logging.NewNopLogger()
+	repo := mongodb.NewMockUserRepository(logger)
+	return NewUserResolver(repo, logger), repo
+}
+
+func validUserInput() map[string]interface{} {
+	return map[string]interface{}{
+		"username":  "alice",
+		"email":     "alice@example.com",
+		"firstName": "Alice",
+		"lastName":  "O'Neil-Smith",
+	}
+}
+
+// countUsers returns how many users repo holds
+func countUsers(t *testing.T, repo repository.UserRepository) int64 {
+	t.Helper()
+
+	count, err := repo.Count(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Count() error = %v", err)
+	}
+	return count
+}
+
+// fieldErrors returns the field errors of a *ValidationError, failing the test for any other error
+func fieldErrors(t *testing.T, err error) map[string]string {
+	t.Helper()
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("error = %v, want a *ValidationError", err)
+	}
+	fields := make(map[string]string, len(validationErr.Fields))
+	for _, field := range validationErr.Fields {
+		fields[field.Field] = field.Message
+	}
+	return fields
+}
+
+func TestCreateUserTrimsValidInput(t *testing.T) {
+	resolver, repo := newTestResolver(t)
+	input := validUserInput()
+	input["username"] = "  alice  "
+	input["email"] = " alice@example.com\n"
+
+	user, err := resolver.CreateUser(context.Background(), input)
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if user.Username != "alice" || user.Email != "alice@example.com" || user.LastName != "O'Neil-Smith" {
+		t.Errorf("CreateUser() = %+v, want trimmed fields", *user)
+	}
+	if count := countUsers(t, repo); count != 1 {
+		t.Errorf("%d users stored, want 1", count)
+	}
+}
+
+func TestCreateUserRejectsInvalidInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		field string
+		value interface{}
+	}{
+		{name: "over-length username", field: "username", value: strings.Repeat("a", MaxUsernameLength+1)},
+		{name: "short username", field: "username", value: "al"},
+		{name: "username with spaces", field: "username", value: "alice smith"},
+		{name: "over-length first name", field: "firstName", value: strings.Repeat("A", MaxNameLength+1)},
+		{name: "name with digits", field: "lastName", value: "Smith2"},
+		{name: "malformed email", field: "email", value: "alice@"},
+		{name: "email without domain dot", field: "email", value: "alice@localhost"},
+		{name: "email with display name", field: "email", value: "Alice <alice@example.com>"},
+		{name: "over-length email", field: "email", value: strings.Repeat("a", MaxEmailLength) + "@example.com"},
+		{name: "non-string email", field: "email", value: 42},
+		{name: "missing email", field: "email", value: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, repo := newTestResolver(t)
+			input := validUserInput()
+			if tt.value == nil {
+				delete(input, tt.field)
+			} else {
+				input[tt.field] = tt.value
+			}
+
+			_, err := resolver.CreateUser(context.Background(), input)
+			fields := fieldErrors(t, err)
+			if _, ok := fields[tt.field]; !ok || len(fields) != 1 {
+				t.Errorf("field errors = %v, want only %s", fields, tt.field)
+			}
+			if count := countUsers(t, repo); count != 0 {
+				t.Errorf("%d users stored, want none", count)
+			}
+		})
+	}
+}
+
+func TestCreateUserReportsEveryInvalidField(t *testing.T) {
+	resolver, _ := newTestResolver(t)
+
+	_, err := resolver.CreateUser(context.Background(), map[string]interface{}{
+		"username": "a",
+		"email":    "not-an-email",
+	})
+	fields := fieldErrors(t, err)
+	for _, field := range []string{"username", "email", "firstName", "lastName"} {
+		if _, ok := fields[field]; !ok {
+			t.Errorf("field errors = %v, want one for %s", fields, field)
+		}
+	}
+}
+
+func TestUpdateUserValidatesOnlyGivenFields(t *testing.T) {
+	resolver, _ := newTestResolver(t)
+	user, err := resolver.CreateUser(context.Background(), validUserInput())
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	updated, err := resolver.UpdateUser(context.Background(), user.GetID(), map[string]interface{}{"firstName": " Alicia "})
+	if err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if updated.FirstName != "Alicia" || updated.Email != "alice@example.com" {
+		t.Errorf("UpdateUser() = %+v, want only the first name changed", *updated)
+	}
+
+	_, err = resolver.UpdateUser(context.Background(), user.GetID(), map[string]interface{}{"email": "bad"})
+	if fields := fieldErrors(t, err); len(fields) != 1 || fields["email"] == "" {
+		t.Errorf("field errors = %v, want only email", fields)
+	}
+
+	_, err = resolver.UpdateUser(context.Background(), user.GetID(), map[string]interface{}{})
+	if fields := fieldErrors(t, err); fields["input"] == "" {
+		t.Errorf("field errors = %v, want one for an empty update", fields)
+	}
+}
+
+// duplicateUsernameRepository fails to create users whose username is already taken
+type duplicateUsernameRepository struct {
+	repository.UserRepository
+	taken string
+}
+
+func (r *duplicateUsernameRepository) CreateMany(ctx context.Context, users []*entity.User) []error {
+	errs := make([]error, 0, len(users))
+	for _, user := range users {
+		if user.Username == r.taken {
+			errs = append(errs, errors.New("duplicate key"))
+			continue
+		}
+		errs = append(errs, r.UserRepository.Create(ctx, user))
+	}
+	return errs
+}
+
+func TestBulkCreateUsersReportsEachResult(t *testing.T) {
+	resolver, repo := newTestResolver(t)
+	resolver.userRepo = &duplicateUsernameRepository{UserRepository: repo, taken: "taken"}
+
+	invalid := validUserInput()
+	invalid["username"] = "bob"
+	invalid["email"] = "not-an-email"
+	duplicate := validUserInput()
+	duplicate["username"] = "taken"
+	carol := validUserInput()
+	carol["username"] = "carol"
+
+	results, err := resolver.BulkCreateUsers(context.Background(), []map[string]interface{}{validUserInput(), invalid, duplicate, carol})
+	if err != nil {
+		t.Fatalf("BulkCreateUsers() error = %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("BulkCreateUsers() returned %d results, want 4", len(results))
+	}
+	for i, result := range results {
+		if result.Index != i {
+			t.Errorf("results[%d].Index = %d", i, result.Index)
+		}
+	}
+
+	for _, i := range []int{0, 3} {
+		if !results[i].Success || results[i].User == nil || results[i].Error != "" {
+			t.Errorf("results[%d] = %+v, want a created user", i, *results[i])
+		}
+	}
+	if results[3].User.Username != "carol" {
+		t.Errorf("results[3].User.Username = %q, want carol", results[3].User.Username)
+	}
+	if results[1].Success || len(results[1].FieldErrors) != 1 || results[1].FieldErrors[0].Field != "email" {
+		t.Errorf("results[1] = %+v, want an email field error", *results[1])
+	}
+	if results[2].Success || results[2].Error != "duplicate key" || results[2].FieldErrors != nil {
+		t.Errorf("results[2] = %+v, want the repository error", *results[2])
+	}
+	if count := countUsers(t, repo); count != 2 {
+		t.Errorf("%d users stored, want 2", count)
+	}
+}
+
+func TestBulkCreateUsersRejectsBatchSize(t *testing.T) {
+	tests := map[string]int{
+		"empty":     0,
+		"too large": MaxBulkCreateUsers + 1,
+	}
+
+	for name, size := range tests {
+		t.Run(name, func(t *testing.T) {
+			resolver, repo := newTestResolver(t)
+			inputs := make([]map[string]interface{}, size)
+			for i := range inputs {
+				inputs[i] = validUserInput()
+			}
+
+			results, err := resolver.BulkCreateUsers(context.Background(), inputs)
+			if fields := fieldErrors(t, err); fields["input"] == "" {
+				t.Errorf("field errors = %v, want one for the batch", fields)
+			}
+			if results != nil {
+				t.Errorf("BulkCreateUsers() returned %d results, want none", len(results))
+			}
+			if count := countUsers(t, repo); count != 0 {
+				t.Errorf("%d users stored, want none", count)
+			}
+		})
+	}
+}
+
+func TestBulkCreateUsersAcceptsFullBatch(t *testing.T) {
+	resolver, repo := newTestResolver(t)
+	inputs := make([]map[string]interface{}, MaxBulkCreateUsers)
+	for i := range inputs {
+		inputs[i] = validUserInput()
+	}
+
+	results, err := resolver.BulkCreateUsers(context.Background(), inputs)
+	if err != nil {
+		t.Fatalf("BulkCreateUsers() error = %v", err)
+	}
+	if len(results) != MaxBulkCreateUsers {
+		t.Errorf("BulkCreateUsers() returned %d results, want %d", len(results), MaxBulkCreateUsers)
+	}
+	if count := countUsers(t, repo); count != MaxBulkCreateUsers {
+		t.Errorf("%d users stored, want %d", count, MaxBulkCreateUsers)
+	}
+}
+
+func TestUpdateUserPatchesOnlyGivenFields(t *testing.T) {
+	resolver, repo := newTestResolver(t)
+	user, err := resolver.CreateUser(context.Background(), validUserInput())
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	before := *user
+
+	updated, err := resolver.UpdateUser(context.Background(), user.GetID(), map[string]interface{}{
+		"lastName": "Jones",
+		"version":  before.Version,
+	})
+	if err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if updated.LastName != "Jones" {
+		t.Errorf("LastName = %q, want Jones", updated.LastName)
+	}
+	if updated.Username != before.Username || updated.Email != before.Email || updated.FirstName != before.FirstName {
+		t.Errorf("UpdateUser() = %+v, want only the last name changed from %+v", *updated, before)
+	}
+	if updated.Version != before.Version+1 {
+		t.Errorf("Version = %d, want %d", updated.Version, before.Version+1)
+	}
+
+	stored, err := repo.GetByID(context.Background(), user.GetID())
+	if err != nil || stored == nil || stored.LastName != "Jones" {
+		t.Errorf("stored user = %+v, %v, want the patched user", stored, err)
+	}
+}
+
+func TestUpdateUserRejectsStaleVersion(t *testing.T) {
+	resolver, repo := newTestResolver(t)
+	user, err := resolver.CreateUser(context.Background(), validUserInput())
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	staleVersion := user.Version
+
+	if _, err := resolver.UpdateUser(context.Background(), user.GetID(), map[string]interface{}{
+		"firstName": "Alicia",
+		"version":   staleVersion,
+	}); err != nil {
+		t.Fatalf("first UpdateUser() error = %v", err)
+	}
+
+	_, err = resolver.UpdateUser(context.Background(), user.GetID(), map[string]interface{}{
+		"firstName": "Ally",
+		"version":   staleVersion,
+	})
+	if !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("UpdateUser() with a stale version error = %v, want repository.ErrVersionConflict", err)
+	}
+
+	stored, err := repo.GetByID(context.Background(), user.GetID())
+	if err != nil || stored == nil {
+		t.Fatalf("GetByID() = %v, %v", stored, err)
+	}
+	if stored.FirstName != "Alicia" || stored.Version != staleVersion+1 {
+		t.Errorf("stored user = %+v, want the first update only", *stored)
+	}
+
+	if _, err := resolver.UpdateUser(context.Background(), user.GetID(), map[string]interface{}{"firstName": "Ally"}); err != nil {
+		t.Errorf("UpdateUser() without a version error = %v, want the update applied", err)
+	}
+}
@@ -0,0 +1,199 @@
+package resolvers
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Limits on user input fields
+const (
+	MinUsernameLength = 3
+	MaxUsernameLength = 50
+	MaxEmailLength    = 254
+	MaxNameLength     = 100
+
+	// MaxBulkCreateUsers is the most users a bulk import may create
+	MaxBulkCreateUsers = 100
+)
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// FieldError describes why an input field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned by mutations whose input is invalid, before anything is
+// persisted. It lists every rejected field.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Field + ": " + field.Message
+	}
+	return "invalid input: " + strings.Join(messages, "; ")
+}
+
+// userInput holds the sanitized fields of a user mutation input; nil fields were not given
+type userInput struct {
+	username  *string
+	email     *string
+	firstName *string
+	lastName  *string
+	// version is the version an update expects the user to have
+	version *int64
+}
+
+// storedFields returns the given fields keyed by their stored names
+func (in *userInput) storedFields() map[string]interface{} {
+	fields := make(map[string]interface{})
+	for name, value := range map[string]*string{
+		"username":  in.username,
+		"email":     in.email,
+		"firstName": in.firstName,
+		"lastName":  in.lastName,
+	} {
+		if value != nil {
+			fields[name] = *value
+		}
+	}
+	return fields
+}
+
+// parseUserInput trims and validates the fields of input. With required set, every field
+// must be present, as for creating a user; otherwise only the fields given are checked, at
+// least one must be, and an expected version may be given, as for updating a user.
+func parseUserInput(input map[string]interface{}, required bool) (*userInput, error) {
+	v := &inputValidator{input: input, required: required}
+	parsed := &userInput{
+		username:  v.field("username", validateUsername),
+		email:     v.field("email", validateEmail),
+		firstName: v.field("firstName", validateName),
+		lastName:  v.field("lastName", validateName),
+	}
+	if !required {
+		parsed.version = v.version("version")
+		if len(v.errors) == 0 && len(parsed.storedFields()) == 0 {
+			v.fail("input", "must set at least one field")
+		}
+	}
+	if len(v.errors) > 0 {
+		return nil, &ValidationError{Fields: v.errors}
+	}
+	return parsed, nil
+}
+
+// inputValidator collects the errors of the fields it checks
+type inputValidator struct {
+	input    map[string]interface{}
+	required bool
+	errors   []FieldError
+}
+
+// field returns the trimmed value of name, or nil if it is missing or fails validate
+func (v *inputValidator) field(name string, validate func(string) string) *string {
+	raw, ok := v.input[name]
+	if !ok || raw == nil {
+		if v.required {
+			v.fail(name, "is required")
+		}
+		return nil
+	}
+
+	value, ok := raw.(string)
+	if !ok {
+		v.fail(name, "must be a string")
+		return nil
+	}
+	value = strings.TrimSpace(value)
+	if !utf8.ValidString(value) {
+		v.fail(name, "must be valid UTF-8")
+		return nil
+	}
+	if message := validate(value); message != "" {
+		v.fail(name, message)
+		return nil
+	}
+	return &value
+}
+
+// version returns the non-negative integer value of name, or nil if it is missing or invalid
+func (v *inputValidator) version(name string) *int64 {
+	var version int64
+	switch value := v.input[name].(type) {
+	case nil:
+		return nil
+	case int:
+		version = int64(value)
+	case int64:
+		version = value
+	case float64:
+		// JSON numbers decode as float64
+		if value != float64(int64(value)) {
+			v.fail(name, "must be an integer")
+			return nil
+		}
+		version = int64(value)
+	default:
+		v.fail(name, "must be an integer")
+		return nil
+	}
+	if version < 0 {
+		v.fail(name, "must not be negative")
+		return nil
+	}
+	return &version
+}
+
+func (v *inputValidator) fail(field, message string) {
+	v.errors = append(v.errors, FieldError{Field: field, Message: message})
+}
+
+func validateUsername(value string) string {
+	length := utf8.RuneCountInString(value)
+	if length < MinUsernameLength || length > MaxUsernameLength {
+		return fmt.Sprintf("must be between %d and %d characters", MinUsernameLength, MaxUsernameLength)
+	}
+	if !usernamePattern.MatchString(value) {
+		return "may only contain letters, digits, '.', '_' and '-'"
+	}
+	return ""
+}
+
+func validateEmail(value string) string {
+	if value == "" {
+		return "must not be empty"
+	}
+	if len(value) > MaxEmailLength {
+		return fmt.Sprintf("must be at most %d characters", MaxEmailLength)
+	}
+	// ParseAddress also accepts display names and comments; only a bare address is valid
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Address != value || !strings.Contains(value[strings.LastIndex(value, "@"):], ".") {
+		return "must be a valid email address"
+	}
+	return ""
+}
+
+func validateName(value string) string {
+	if value == "" {
+		return "must not be empty"
+	}
+	if utf8.RuneCountInString(value) > MaxNameLength {
+		return fmt.Sprintf("must be at most %d characters", MaxNameLength)
+	}
+	for _, r := range value {
+		if !unicode.IsLetter(r) && !unicode.IsMark(r) && !strings.ContainsRune(" '-.", r) {
+			return "may only contain letters, spaces, apostrophes, hyphens and periods"
+		}
+	}
+	return ""
+}
@@ -1,10 +1,12 @@
 package graphql
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"net/http"
 
+	"backend-core/logging"
+
 	"graphql-service/internal/domain/user/repository"
 	"graphql-service/internal/infrastructure/persistence/mongodb"
 	"graphql-service/internal/interfaces/graphql/resolvers"
@@ -16,13 +18,17 @@ import (
 
 // Server represents the GraphQL server
 type Server struct {
-	router   *mux.Router
-	userRepo repository.UserRepository
-	logger   interface{} // Replace with actual logger type
+	router    *mux.Router
+	handler   http.Handler
+	userRepo  repository.UserRepository
+	readiness ReadinessCheck
+	logger    *logging.Logger
+
+	lifecycle httpLifecycle
 }
 
-// NewServer creates a new GraphQL server
-func NewServer(db *mongo.Database, logger interface{}) *Server {
+// NewServer creates a new GraphQL server. A nil config uses DefaultServerConfig.
+func NewServer(db *mongo.Database, config *ServerConfig, logger *logging.Logger) *Server {
 	// Initialize repositories
 	userRepo := mongodb.NewUserRepository(db.Collection("users"), logger)
 
@@ -31,13 +37,15 @@ func NewServer(db *mongo.Database, logger interface{}) *Server {
 
 	// Create server
 	server := &Server{
-		router:   mux.NewRouter(),
-		userRepo: userRepo,
-		logger:   logger,
+		router:    mux.NewRouter(),
+		userRepo:  userRepo,
+		readiness: MongoReadinessCheck(db, logger),
+		logger:    logger,
 	}
 
 	// Setup routes
 	server.setupRoutes(userResolver)
+	server.handler = buildHandler(server.router, config, logger)
 
 	return server
 }
@@ -52,6 +60,10 @@ func (s *Server) setupRoutes(userResolver *resolvers.UserResolver) {
 
 	// Health check
 	s.router.HandleFunc("/health", s.healthHandler)
+
+	// Liveness and readiness probes
+	s.router.HandleFunc("/livez", s.livezHandler)
+	s.router.HandleFunc("/readyz", s.readyzHandler)
 }
 
 // graphqlHandler creates the GraphQL handler
@@ -90,10 +102,33 @@ func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(response))
 }
 
-// Start starts the GraphQL server
+// livezHandler handles liveness probes
+func (s *Server) livezHandler(w http.ResponseWriter, r *http.Request) {
+	serveLiveness(w, "graphql-service")
+}
+
+// readyzHandler handles readiness probes; the server is ready once MongoDB is reachable
+// and its migrations are applied
+func (s *Server) readyzHandler(w http.ResponseWriter, r *http.Request) {
+	serveReadiness(w, r, "graphql-service", s.readiness)
+}
+
+// Start starts the GraphQL server and serves until Shutdown is called
 func (s *Server) Start(port string) error {
-	log.Printf("Starting GraphQL server on port %s", port)
-	return http.ListenAndServe(":"+port, s.router)
+	s.logger.Info("Starting GraphQL server", "port", port)
+	return s.lifecycle.serve(":"+port, s.handler)
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests to complete until
+// ctx is done, after which Start returns
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.lifecycle.stop(ctx)
+}
+
+// Handler returns the HTTP handler serving the routes with CORS, security headers, request
+// correlation and the request timeout
+func (s *Server) Handler() http.Handler {
+	return s.handler
 }
 
 // GraphQL schema loader (placeholder)
@@ -0,0 +1,80 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"backend-core/logging"
+)
+
+// DefaultRequestTimeout bounds a request when no timeout is configured
+const DefaultRequestTimeout = 10 * time.Second
+
+// ServerConfig holds the HTTP configuration of the GraphQL servers
+type ServerConfig struct {
+	HTTPSecurity *HTTPSecurityConfig
+	// RequestTimeout cancels the context of a request, and with it the resolvers and
+	// database queries serving it, once it has run this long
+	RequestTimeout time.Duration
+}
+
+// DefaultServerConfig returns the default server configuration
+func DefaultServerConfig() *ServerConfig {
+	return &ServerConfig{
+		HTTPSecurity:   DefaultHTTPSecurityConfig(),
+		RequestTimeout: DefaultRequestTimeout,
+	}
+}
+
+// LoadServerConfig returns the server configuration from the environment: the HTTP security
+// settings of LoadHTTPSecurityConfig and GRAPHQL_REQUEST_TIMEOUT
+func LoadServerConfig() (*ServerConfig, error) {
+	securityConfig, err := LoadHTTPSecurityConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	config := &ServerConfig{
+		HTTPSecurity:   securityConfig,
+		RequestTimeout: DefaultRequestTimeout,
+	}
+	if value := os.Getenv("GRAPHQL_REQUEST_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GRAPHQL_REQUEST_TIMEOUT: %w", err)
+		}
+		if timeout <= 0 {
+			return nil, fmt.Errorf("GRAPHQL_REQUEST_TIMEOUT must be positive")
+		}
+		config.RequestTimeout = timeout
+	}
+
+	return config, nil
+}
+
+// buildHandler wraps router with the CORS, security header, request correlation and timeout
+// handling of config
+func buildHandler(router http.Handler, config *ServerConfig, logger *logging.Logger) http.Handler {
+	if config == nil {
+		config = DefaultServerConfig()
+	}
+	handler := withRequestTimeout(router, config.RequestTimeout)
+	handler = withRequestCorrelation(handler, logger)
+	return withHTTPSecurity(handler, config.HTTPSecurity)
+}
+
+// withRequestTimeout cancels the context of every request after timeout. Handlers must pass
+// the request context on, so a slow query is aborted rather than holding its connection.
+func withRequestTimeout(next http.Handler, timeout time.Duration) http.Handler {
+	if timeout <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
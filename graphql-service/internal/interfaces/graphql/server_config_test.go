@@ -0,0 +1,115 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"graphql-service/internal/domain/user/entity"
+	"graphql-service/internal/domain/user/repository"
+	"graphql-service/internal/interfaces/graphql/resolvers"
+
+	"backend-core/logging"
+)
+
+// slowUserRepository blocks GetAll until its context is done, like a Mongo query that
+// outlives the request
+type slowUserRepository struct {
+	repository.UserRepository
+	canceled chan error
+}
+
+func (r *slowUserRepository) GetAll(ctx context.Context, filter map[string]interface{}, pagination map[string]interface{}) ([]*entity.User, error) {
+	select {
+	case <-ctx.Done():
+		r.canceled <- ctx.Err()
+		return nil, ctx.Err()
+	case <-time.After(5 * time.Second):
+		return nil, nil
+	}
+}
+
+// newSlowTestServer serves the test server with a slow repository and requestTimeout
+func newSlowTestServer(t *testing.T, requestTimeout time.Duration) (http.Handler, *slowUserRepository) {
+	t.Helper()
+
+	repo := &slowUserRepository{canceled: make(chan error, 1)}
+	config := DefaultServerConfig()
+	config.RequestTimeout = requestTimeout
+	logger := logging.NewNopLogger()
+	server := NewTestServer(resolvers.NewUserResolver(repo, logger), config, logger)
+	return server.Handler(), repo
+}
+
+func TestSlowResolverIsCanceledAtRequestTimeout(t *testing.T) {
+	handler, repo := newSlowTestServer(t, 50*time.Millisecond)
+
+	started := time.Now()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/test/users", nil))
+	elapsed := time.Since(started)
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusGatewayTimeout, rec.Body.String())
+	}
+	if elapsed < 50*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("request took %v, want it canceled at the 50ms timeout", elapsed)
+	}
+	select {
+	case err := <-repo.canceled:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("repository context error = %v, want context.DeadlineExceeded", err)
+		}
+	default:
+		t.Error("expected the repository query to be canceled")
+	}
+}
+
+func TestSlowGraphQLQueryReturnsTimeoutError(t *testing.T) {
+	handler, _ := newSlowTestServer(t, 50*time.Millisecond)
+
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(`{"query":"{ users { id } }"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var response struct {
+		Errors []struct {
+			Message    string            `json:"message"`
+			Extensions map[string]string `json:"extensions"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response %s: %v", rec.Body.String(), err)
+	}
+	if len(response.Errors) != 1 || response.Errors[0].Extensions["code"] != "TIMEOUT" {
+		t.Errorf("errors = %+v, want one TIMEOUT error", response.Errors)
+	}
+}
+
+func TestLoadServerConfigRequestTimeout(t *testing.T) {
+	config, err := LoadServerConfig()
+	if err != nil {
+		t.Fatalf("LoadServerConfig() error = %v", err)
+	}
+	if config.RequestTimeout != DefaultRequestTimeout {
+		t.Errorf("RequestTimeout = %v, want %v", config.RequestTimeout, DefaultRequestTimeout)
+	}
+
+	t.Setenv("GRAPHQL_REQUEST_TIMEOUT", "3s")
+	if config, err = LoadServerConfig(); err != nil || config.RequestTimeout != 3*time.Second {
+		t.Errorf("LoadServerConfig() = %v, %v, want a 3s timeout", config, err)
+	}
+
+	for _, value := range []string{"0s", "-1s", "soon"} {
+		t.Setenv("GRAPHQL_REQUEST_TIMEOUT", value)
+		if _, err := LoadServerConfig(); err == nil {
+			t.Errorf("LoadServerConfig() with GRAPHQL_REQUEST_TIMEOUT=%s error = nil, want an error", value)
+		}
+	}
+}
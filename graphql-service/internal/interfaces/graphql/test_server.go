@@ -3,9 +3,12 @@ package graphql
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"errors"
 	"net/http"
 
+	"backend-core/logging"
+
+	"graphql-service/internal/domain/user/repository"
 	"graphql-service/internal/interfaces/graphql/resolvers"
 
 	"github.com/99designs/gqlgen/graphql/playground"
@@ -15,12 +18,16 @@ import (
 // TestServer represents the GraphQL test server
 type TestServer struct {
 	router       *mux.Router
+	handler      http.Handler
 	userResolver *resolvers.UserResolver
-	logger       interface{} // Replace with actual logger type
+	readiness    ReadinessCheck
+	logger       *logging.Logger
+
+	lifecycle httpLifecycle
 }
 
-// NewTestServer creates a new GraphQL test server
-func NewTestServer(userResolver *resolvers.UserResolver, logger interface{}) *TestServer {
+// NewTestServer creates a new GraphQL test server. A nil config uses DefaultServerConfig.
+func NewTestServer(userResolver *resolvers.UserResolver, config *ServerConfig, logger *logging.Logger) *TestServer {
 	server := &TestServer{
 		router:       mux.NewRouter(),
 		userResolver: userResolver,
@@ -29,6 +36,7 @@ func NewTestServer(userResolver *resolvers.UserResolver, logger interface{}) *Te
 
 	// Setup routes
 	server.setupRoutes()
+	server.handler = buildHandler(server.router, config, logger)
 
 	return server
 }
@@ -44,6 +52,10 @@ func (s *TestServer) setupRoutes() {
 	// Health check
 	s.router.HandleFunc("/health", s.healthHandler)
 
+	// Liveness and readiness probes
+	s.router.HandleFunc("/livez", s.livezHandler)
+	s.router.HandleFunc("/readyz", s.readyzHandler)
+
 	// Test endpoints
 	s.router.HandleFunc("/test/users", s.testUsersHandler)
 	s.router.HandleFunc("/test/create-user", s.testCreateUserHandler)
@@ -67,14 +79,14 @@ func (s *TestServer) graphqlHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Process GraphQL query
-	response := s.processGraphQLQuery(req.Query, req.Variables)
+	response := s.processGraphQLQuery(r.Context(), req.Query, req.Variables)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
 // processGraphQLQuery processes GraphQL queries
-func (s *TestServer) processGraphQLQuery(query string, variables map[string]interface{}) map[string]interface{} {
+func (s *TestServer) processGraphQLQuery(ctx context.Context, query string, variables map[string]interface{}) map[string]interface{} {
 	// Simple GraphQL query processing
 	// This is a simplified implementation for testing
 	
@@ -103,20 +115,67 @@ func (s *TestServer) processGraphQLQuery(query string, variables map[string]inte
 		}
 	}
 
+	// Handle bulk user import mutation
+	if contains(query, "bulkCreateUsers") {
+		items, ok := variables["input"].([]interface{})
+		if !ok {
+			return map[string]interface{}{
+				"errors": []map[string]interface{}{
+					{"message": "Invalid input"},
+				},
+			}
+		}
+		inputs := make([]map[string]interface{}, len(items))
+		for i, item := range items {
+			inputs[i], _ = item.(map[string]interface{})
+		}
+
+		results, err := s.userResolver.BulkCreateUsers(ctx, inputs)
+		if err != nil {
+			return graphqlErrorResponse(err)
+		}
+
+		return map[string]interface{}{
+			"data": map[string]interface{}{
+				"bulkCreateUsers": results,
+			},
+		}
+	}
+
 	// Handle user queries
 	if contains(query, "users") {
-		users, err := s.userResolver.Users(context.Background(), nil, nil)
+		users, err := s.userResolver.Users(ctx, nil, nil)
 		if err != nil {
+			return graphqlErrorResponse(err)
+		}
+
+		return map[string]interface{}{
+			"data": map[string]interface{}{
+				"users": users,
+			},
+		}
+	}
+
+	// Handle update user mutation
+	if contains(query, "updateUser") {
+		id, _ := variables["id"].(string)
+		input, ok := variables["input"].(map[string]interface{})
+		if id == "" || !ok {
 			return map[string]interface{}{
 				"errors": []map[string]interface{}{
-					{"message": err.Error()},
+					{"message": "Invalid input"},
 				},
 			}
 		}
 
+		user, err := s.userResolver.UpdateUser(ctx, id, input)
+		if err != nil {
+			return graphqlErrorResponse(err)
+		}
+
 		return map[string]interface{}{
 			"data": map[string]interface{}{
-				"users": users,
+				"updateUser": user,
 			},
 		}
 	}
@@ -133,13 +192,9 @@ func (s *TestServer) processGraphQLQuery(query string, variables map[string]inte
 			}
 		}
 
-		user, err := s.userResolver.CreateUser(context.Background(), input)
+		user, err := s.userResolver.CreateUser(ctx, input)
 		if err != nil {
-			return map[string]interface{}{
-				"errors": []map[string]interface{}{
-					{"message": err.Error()},
-				},
-			}
+			return graphqlErrorResponse(err)
 		}
 
 		return map[string]interface{}{
@@ -160,9 +215,9 @@ func (s *TestServer) processGraphQLQuery(query string, variables map[string]inte
 
 // testUsersHandler tests user queries
 func (s *TestServer) testUsersHandler(w http.ResponseWriter, r *http.Request) {
-	users, err := s.userResolver.Users(context.Background(), nil, nil)
+	users, err := s.userResolver.Users(r.Context(), nil, nil)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
@@ -186,9 +241,9 @@ func (s *TestServer) testCreateUserHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	user, err := s.userResolver.CreateUser(context.Background(), input)
+	user, err := s.userResolver.CreateUser(r.Context(), input)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
@@ -214,10 +269,73 @@ func (s *TestServer) healthHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// Start starts the GraphQL test server
+// SetReadinessCheck sets the check /readyz reports; without one the server is always ready
+func (s *TestServer) SetReadinessCheck(check ReadinessCheck) {
+	s.readiness = check
+}
+
+// livezHandler handles liveness probes
+func (s *TestServer) livezHandler(w http.ResponseWriter, r *http.Request) {
+	serveLiveness(w, "graphql-test-server")
+}
+
+// readyzHandler handles readiness probes
+func (s *TestServer) readyzHandler(w http.ResponseWriter, r *http.Request) {
+	serveReadiness(w, r, "graphql-test-server", s.readiness)
+}
+
+// Start starts the GraphQL test server and serves until Shutdown is called
 func (s *TestServer) Start(port string) error {
-	log.Printf("Starting GraphQL test server on port %s", port)
-	return http.ListenAndServe(":"+port, s.router)
+	s.logger.Info("Starting GraphQL test server", "port", port)
+	return s.lifecycle.serve(":"+port, s.handler)
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests to complete until
+// ctx is done, after which Start returns
+func (s *TestServer) Shutdown(ctx context.Context) error {
+	return s.lifecycle.stop(ctx)
+}
+
+// Handler returns the HTTP handler serving the routes with CORS, security headers, request
+// correlation and the request timeout
+func (s *TestServer) Handler() http.Handler {
+	return s.handler
+}
+
+// graphqlErrorResponse returns the GraphQL response for a resolver error, with the TIMEOUT
+// code for requests that ran out of time, the BAD_USER_INPUT code and rejected fields for
+// invalid input and the CONFLICT code for updates of a stale version
+func graphqlErrorResponse(err error) map[string]interface{} {
+	graphqlErr := map[string]interface{}{"message": err.Error()}
+	var validationErr *resolvers.ValidationError
+	if errors.Is(err, resolvers.ErrRequestTimeout) {
+		graphqlErr["extensions"] = map[string]interface{}{"code": "TIMEOUT"}
+	} else if errors.As(err, &validationErr) {
+		graphqlErr["extensions"] = map[string]interface{}{
+			"code":   "BAD_USER_INPUT",
+			"fields": validationErr.Fields,
+		}
+	} else if errors.Is(err, repository.ErrVersionConflict) {
+		graphqlErr["extensions"] = map[string]interface{}{"code": "CONFLICT"}
+	}
+	return map[string]interface{}{
+		"errors": []map[string]interface{}{graphqlErr},
+	}
+}
+
+// errorStatus returns the HTTP status for a resolver error
+func errorStatus(err error) int {
+	var validationErr *resolvers.ValidationError
+	if errors.Is(err, resolvers.ErrRequestTimeout) {
+		return http.StatusGatewayTimeout
+	}
+	if errors.As(err, &validationErr) {
+		return http.StatusBadRequest
+	}
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
 }
 
 // Helper function to check if string contains substring
@@ -2,16 +2,20 @@ package config
 
 import (
 	"backend-core/config"
+	"backend-core/messaging/kafka/retry"
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Config holds the application configuration
 type Config struct {
-	Server  ServerConfig         `mapstructure:"server" json:"server" yaml:"server"`
-	Kafka   KafkaConfig          `mapstructure:"kafka" json:"kafka" yaml:"kafka"`
-	Logging config.LoggingConfig `mapstructure:"logging" json:"logging" yaml:"logging"`
+	Server   ServerConfig         `mapstructure:"server" json:"server" yaml:"server"`
+	Kafka    KafkaConfig          `mapstructure:"kafka" json:"kafka" yaml:"kafka"`
+	Handlers HandlersConfig       `mapstructure:"handlers" json:"handlers" yaml:"handlers"`
+	Logging  config.LoggingConfig `mapstructure:"logging" json:"logging" yaml:"logging"`
 }
 
 // ServerConfig holds server configuration
@@ -33,6 +37,15 @@ type TopicsConfig struct {
 	UserEvents string `mapstructure:"user_events" json:"user_events" yaml:"user_events"`
 	AuthEvents string `mapstructure:"auth_events" json:"auth_events" yaml:"auth_events"`
 	AuditLogs  string `mapstructure:"audit_logs" json:"audit_logs" yaml:"audit_logs"`
+	// DeadLetter receives the events that still failed after RetryCount retries
+	DeadLetter string `mapstructure:"dead_letter" json:"dead_letter" yaml:"dead_letter"`
+}
+
+// HandlersConfig holds event handler configuration
+type HandlersConfig struct {
+	// FailurePolicy is "any" to fail an event when any of its handlers fails, or "all"
+	// to fail it only when every handler fails. Any other value stops startup.
+	FailurePolicy string `mapstructure:"failure_policy" json:"failure_policy" yaml:"failure_policy"`
 }
 
 // Load loads configuration from environment variables and files
@@ -59,9 +72,13 @@ func (c *Config) setDefaults() {
 	c.Kafka.Topics.UserEvents = "user.events"
 	c.Kafka.Topics.AuthEvents = "auth.events"
 	c.Kafka.Topics.AuditLogs = "audit.logs"
+	c.Kafka.Topics.DeadLetter = "notification.dlq"
 	c.Kafka.RetryCount = 3
 	c.Kafka.RetryBackoff = "1s"
 
+	// Handler defaults
+	c.Handlers.FailurePolicy = "any"
+
 	// Logging defaults
 	c.Logging.Level = "info"
 	c.Logging.Format = "json"
@@ -91,11 +108,22 @@ func (c *Config) loadFromEnv() {
 	if auditLogs := os.Getenv("KAFKA_TOPIC_AUDIT_LOGS"); auditLogs != "" {
 		c.Kafka.Topics.AuditLogs = auditLogs
 	}
+	if deadLetter := os.Getenv("KAFKA_TOPIC_DEAD_LETTER"); deadLetter != "" {
+		c.Kafka.Topics.DeadLetter = deadLetter
+	}
 	if retryCount := os.Getenv("KAFKA_RETRY_COUNT"); retryCount != "" {
 		if count, err := strconv.Atoi(retryCount); err == nil {
 			c.Kafka.RetryCount = count
 		}
 	}
+	if retryBackoff := os.Getenv("KAFKA_RETRY_BACKOFF"); retryBackoff != "" {
+		c.Kafka.RetryBackoff = retryBackoff
+	}
+
+	// Handler configuration
+	if policy := os.Getenv("HANDLER_FAILURE_POLICY"); policy != "" {
+		c.Handlers.FailurePolicy = policy
+	}
 
 	// Logging configuration
 	if level := os.Getenv("LOG_LEVEL"); level != "" {
@@ -108,3 +136,23 @@ func (c *Config) loadFromEnv() {
 		c.Logging.Output = output
 	}
 }
+
+// RetryConfig returns how failed events are retried: up to RetryCount times through retry
+// topics prefixed with the group ID, backing off from RetryBackoff, and then sent to the
+// dead letter topic
+func (k KafkaConfig) RetryConfig() (*retry.ConsumerRetryConfig, error) {
+	backoff, err := time.ParseDuration(k.RetryBackoff)
+	if err != nil {
+		return nil, fmt.Errorf("invalid retry backoff %q: %w", k.RetryBackoff, err)
+	}
+	if k.Topics.DeadLetter == "" {
+		return nil, fmt.Errorf("a dead letter topic is required")
+	}
+
+	retryConfig := retry.DefaultConsumerRetryConfig()
+	retryConfig.MaxAttempts = k.RetryCount
+	retryConfig.InitialBackoff = backoff
+	retryConfig.DLQTopic = k.Topics.DeadLetter
+	retryConfig.RetryTopicPrefix = k.GroupID
+	return retryConfig, nil
+}
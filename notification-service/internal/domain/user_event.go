@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// EventType represents the type of a user event consumed by notification-service
+type EventType string
+
+const (
+	EventTypeUserCreated    EventType = "user.created"
+	EventTypeUserRegistered EventType = "user.registered"
+	EventTypeUserActivated  EventType = "user.activated"
+	EventTypeUserLogin      EventType = "user.login"
+)
+
+// IsValid reports whether t is one of the known event types
+func (t EventType) IsValid() bool {
+	switch t {
+	case EventTypeUserCreated, EventTypeUserRegistered, EventTypeUserActivated, EventTypeUserLogin:
+		return true
+	}
+	return false
+}
+
+// UserEvent is a user event as consumed from Kafka, independent of the format it was
+// published in
+type UserEvent struct {
+	EventID     string
+	EventType   EventType
+	AggregateID string
+	UserID      string
+	Username    string
+	Email       string
+	// IPAddress and UserAgent are only set on login events
+	IPAddress string
+	UserAgent string
+	Timestamp time.Time
+	Metadata  map[string]interface{}
+	Version   int
+}
+
+// Validate checks that the event carries the fields every handler relies on
+func (e *UserEvent) Validate() error {
+	if !e.EventType.IsValid() {
+		return errors.New("unknown event type: " + string(e.EventType))
+	}
+	if e.UserID == "" {
+		return errors.New("user_id is required")
+	}
+	if e.EventType != EventTypeUserLogin && e.Email == "" {
+		return errors.New("email is required")
+	}
+	return nil
+}
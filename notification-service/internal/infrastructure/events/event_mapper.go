@@ -0,0 +1,217 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"notification-service/internal/domain"
+
+	"backend-shared/events"
+)
+
+// ErrUnknownEventType is returned, wrapped in a MappingError, for messages whose event
+// type notification-service does not handle
+var ErrUnknownEventType = errors.New("unknown event type")
+
+// legacyEventTypes maps the event type names used before the shared events package to
+// their current names
+var legacyEventTypes = map[string]domain.EventType{
+	"UserCreated":     domain.EventTypeUserCreated,
+	"user_registered": domain.EventTypeUserRegistered,
+	"user_activated":  domain.EventTypeUserActivated,
+	"user_login":      domain.EventTypeUserLogin,
+}
+
+// MappingError reports a message that could not be mapped to a user event
+type MappingError struct {
+	// EventType is the event type read from the message, if any
+	EventType string
+	Err       error
+}
+
+func (e *MappingError) Error() string {
+	if e.EventType == "" {
+		return fmt.Sprintf("failed to map event: %v", e.Err)
+	}
+	return fmt.Sprintf("failed to map %s event: %v", e.EventType, e.Err)
+}
+
+func (e *MappingError) Unwrap() error {
+	return e.Err
+}
+
+// rawUserFields are the user fields of an event, nested under "data" in the shared
+// envelope format and at the top level in the legacy flat format
+type rawUserFields struct {
+	UserID    string `json:"user_id"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	IPAddress string `json:"ip_address"`
+	UserAgent string `json:"user_agent"`
+}
+
+// rawEvent is a user event message in either format
+type rawEvent struct {
+	// Shared envelope format
+	ID   string         `json:"id"`
+	Type string         `json:"type"`
+	Data *rawUserFields `json:"data"`
+
+	// Legacy flat format
+	EventID     string `json:"event_id"`
+	EventType   string `json:"event_type"`
+	AggregateID string `json:"aggregate_id"`
+	rawUserFields
+
+	Timestamp string                 `json:"timestamp"`
+	Metadata  map[string]interface{} `json:"metadata"`
+	// Version is a string such as "1.0" in the envelope and a number in the flat format
+	Version json.RawMessage `json:"version"`
+}
+
+// MapUserEvent decodes a Kafka message value into a validated user event. Both the shared
+// envelope format, with the user fields nested under "data", and the legacy flat format
+// are accepted. Any failure is returned as a *MappingError.
+func MapUserEvent(value []byte) (*domain.UserEvent, error) {
+	var raw rawEvent
+	if err := json.Unmarshal(value, &raw); err != nil {
+		return nil, &MappingError{Err: fmt.Errorf("invalid JSON payload: %w", err)}
+	}
+
+	// Prefer event_type, falling back to type for backward compatibility
+	rawType := raw.EventType
+	if rawType == "" {
+		rawType = raw.Type
+	}
+	if rawType == "" {
+		return nil, &MappingError{Err: errors.New("missing event_type field")}
+	}
+
+	eventType := domain.EventType(rawType)
+	if legacy, ok := legacyEventTypes[rawType]; ok {
+		eventType = legacy
+	}
+	if !eventType.IsValid() {
+		return nil, &MappingError{EventType: rawType, Err: ErrUnknownEventType}
+	}
+
+	event := &domain.UserEvent{
+		EventType: eventType,
+		Metadata:  raw.Metadata,
+		Version:   parseVersion(raw.Version),
+	}
+
+	fields := raw.rawUserFields
+	if raw.Data != nil {
+		fields = *raw.Data
+		event.EventID = raw.ID
+		event.AggregateID = fields.UserID
+	} else {
+		event.EventID = raw.EventID
+		event.AggregateID = raw.AggregateID
+	}
+	event.UserID = fields.UserID
+	event.Username = fields.Username
+	event.Email = fields.Email
+	event.IPAddress = fields.IPAddress
+	event.UserAgent = fields.UserAgent
+
+	if raw.Timestamp != "" {
+		timestamp, err := time.Parse(time.RFC3339, raw.Timestamp)
+		if err != nil {
+			return nil, &MappingError{EventType: rawType, Err: fmt.Errorf("invalid timestamp: %w", err)}
+		}
+		event.Timestamp = timestamp
+	} else {
+		// Use current time as fallback if the event has no timestamp
+		event.Timestamp = time.Now()
+	}
+
+	if err := event.Validate(); err != nil {
+		return nil, &MappingError{EventType: rawType, Err: err}
+	}
+	return event, nil
+}
+
+// parseVersion reads the event version, defaulting to 1
+func parseVersion(raw json.RawMessage) int {
+	if len(raw) == 0 {
+		return 1
+	}
+
+	var number float64
+	if err := json.Unmarshal(raw, &number); err == nil && number > 0 {
+		return int(number)
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		var version int
+		if _, err := fmt.Sscanf(text, "%d", &version); err == nil && version > 0 {
+			return version
+		}
+	}
+	return 1
+}
+
+// The conversions below build the shared event types EventHandler works with
+
+func toUserCreatedEvent(e *domain.UserEvent) *events.UserCreatedEvent {
+	return &events.UserCreatedEvent{
+		EventID:     e.EventID,
+		EventType:   string(e.EventType),
+		AggregateID: e.AggregateID,
+		UserID:      e.UserID,
+		Username:    e.Username,
+		Email:       e.Email,
+		Timestamp:   e.Timestamp,
+		Metadata:    e.Metadata,
+		Version:     e.Version,
+	}
+}
+
+func toUserRegisteredEvent(e *domain.UserEvent) *events.UserRegisteredEvent {
+	return &events.UserRegisteredEvent{
+		EventID:     e.EventID,
+		EventType:   string(e.EventType),
+		AggregateID: e.AggregateID,
+		UserID:      e.UserID,
+		Username:    e.Username,
+		Email:       e.Email,
+		Timestamp:   e.Timestamp,
+		Metadata:    e.Metadata,
+		Version:     e.Version,
+	}
+}
+
+func toUserActivatedEvent(e *domain.UserEvent) *events.UserActivatedEvent {
+	return &events.UserActivatedEvent{
+		EventID:     e.EventID,
+		EventType:   string(e.EventType),
+		AggregateID: e.AggregateID,
+		UserID:      e.UserID,
+		Username:    e.Username,
+		Email:       e.Email,
+		Timestamp:   e.Timestamp,
+		Metadata:    e.Metadata,
+		Version:     e.Version,
+	}
+}
+
+func toUserLoginEvent(e *domain.UserEvent) *events.UserLoginEvent {
+	return &events.UserLoginEvent{
+		EventID:     e.EventID,
+		EventType:   string(e.EventType),
+		AggregateID: e.AggregateID,
+		UserID:      e.UserID,
+		Username:    e.Username,
+		Email:       e.Email,
+		IPAddress:   e.IPAddress,
+		UserAgent:   e.UserAgent,
+		Timestamp:   e.Timestamp,
+		Metadata:    e.Metadata,
+		Version:     e.Version,
+	}
+}
@@ -0,0 +1,140 @@
+package events
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"notification-service/internal/domain"
+)
+
+func TestMapUserEventEnvelopeFormat(t *testing.T) {
+	for _, eventType := range []domain.EventType{
+		domain.EventTypeUserCreated,
+		domain.EventTypeUserRegistered,
+		domain.EventTypeUserActivated,
+		domain.EventTypeUserLogin,
+	} {
+		t.Run(string(eventType), func(t *testing.T) {
+			value := `{
+				"id": "evt-1",
+				"type": "` + string(eventType) + `",
+				"timestamp": "2024-05-01T10:00:00Z",
+				"version": "2.0",
+				"metadata": {"source": "auth-service"},
+				"data": {
+					"user_id": "u1",
+					"username": "alice",
+					"email": "alice@example.com",
+					"ip_address": "203.0.113.7",
+					"user_agent": "curl"
+				}
+			}`
+
+			event, err := MapUserEvent([]byte(value))
+			if err != nil {
+				t.Fatalf("MapUserEvent() error = %v", err)
+			}
+			want := domain.UserEvent{
+				EventID:     "evt-1",
+				EventType:   eventType,
+				AggregateID: "u1",
+				UserID:      "u1",
+				Username:    "alice",
+				Email:       "alice@example.com",
+				IPAddress:   "203.0.113.7",
+				UserAgent:   "curl",
+				Timestamp:   time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+				Version:     2,
+			}
+			if event.EventID != want.EventID || event.EventType != want.EventType ||
+				event.AggregateID != want.AggregateID || event.UserID != want.UserID ||
+				event.Username != want.Username || event.Email != want.Email ||
+				event.IPAddress != want.IPAddress || event.UserAgent != want.UserAgent ||
+				!event.Timestamp.Equal(want.Timestamp) || event.Version != want.Version {
+				t.Errorf("MapUserEvent() = %+v, want %+v", *event, want)
+			}
+			if event.Metadata["source"] != "auth-service" {
+				t.Errorf("Metadata = %v, want the source kept", event.Metadata)
+			}
+		})
+	}
+}
+
+func TestMapUserEventLegacyFormat(t *testing.T) {
+	tests := []struct {
+		eventType string
+		want      domain.EventType
+	}{
+		{eventType: "UserCreated", want: domain.EventTypeUserCreated},
+		{eventType: "user_registered", want: domain.EventTypeUserRegistered},
+		{eventType: "user_activated", want: domain.EventTypeUserActivated},
+		{eventType: "user_login", want: domain.EventTypeUserLogin},
+		{eventType: "user.activated", want: domain.EventTypeUserActivated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.eventType, func(t *testing.T) {
+			value := `{
+				"event_id": "evt-1",
+				"event_type": "` + tt.eventType + `",
+				"aggregate_id": "agg-1",
+				"user_id": "u1",
+				"email": "alice@example.com",
+				"version": 3
+			}`
+
+			event, err := MapUserEvent([]byte(value))
+			if err != nil {
+				t.Fatalf("MapUserEvent() error = %v", err)
+			}
+			if event.EventType != tt.want || event.EventID != "evt-1" || event.AggregateID != "agg-1" ||
+				event.UserID != "u1" || event.Email != "alice@example.com" || event.Version != 3 {
+				t.Errorf("MapUserEvent() = %+v", *event)
+			}
+			if event.Timestamp.IsZero() {
+				t.Error("expected a timestamp to default to the current time")
+			}
+		})
+	}
+}
+
+func TestMapUserEventRejectsMalformedPayloads(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		eventType string
+		contains  string
+	}{
+		{name: "invalid JSON", value: `{"event_type":`, contains: "invalid JSON payload"},
+		{name: "missing event type", value: `{"user_id":"u1"}`, contains: "missing event_type"},
+		{name: "unknown event type", value: `{"event_type":"user.deleted","user_id":"u1"}`, eventType: "user.deleted", contains: "unknown event type"},
+		{name: "missing user ID", value: `{"event_type":"user.created","email":"a@example.com"}`, eventType: "user.created", contains: "user_id is required"},
+		{name: "missing email", value: `{"event_type":"user.created","user_id":"u1"}`, eventType: "user.created", contains: "email is required"},
+		{name: "invalid timestamp", value: `{"event_type":"user.login","user_id":"u1","timestamp":"yesterday"}`, eventType: "user.login", contains: "invalid timestamp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := MapUserEvent([]byte(tt.value))
+			var mappingErr *MappingError
+			if !errors.As(err, &mappingErr) {
+				t.Fatalf("MapUserEvent() error = %v, want a MappingError", err)
+			}
+			if mappingErr.EventType != tt.eventType {
+				t.Errorf("EventType = %q, want %q", mappingErr.EventType, tt.eventType)
+			}
+			if !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("error %q does not mention %q", err, tt.contains)
+			}
+		})
+	}
+}
+
+func TestMapUserEventUnknownTypeMatchesSentinel(t *testing.T) {
+	_, err := MapUserEvent([]byte(`{"event_type":"user.deleted","user_id":"u1"}`))
+	if !errors.Is(err, ErrUnknownEventType) {
+		t.Errorf("MapUserEvent() error = %v, want ErrUnknownEventType", err)
+	}
+}
@@ -0,0 +1,244 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"notification-service/internal/domain"
+
+	"backend-core/logging"
+	"backend-shared/events"
+)
+
+// FailurePolicy decides whether an event fails when some of its handlers fail
+type FailurePolicy string
+
+const (
+	// FailOnAny fails the event if any of its handlers fails
+	FailOnAny FailurePolicy = "any"
+	// FailOnAll fails the event only if every one of its handlers fails
+	FailOnAll FailurePolicy = "all"
+)
+
+// IsValid reports whether p is one of the known failure policies
+func (p FailurePolicy) IsValid() bool {
+	return p == FailOnAny || p == FailOnAll
+}
+
+// HandlerFailure is the error returned by one handler of an event
+type HandlerFailure struct {
+	Handler string
+	Err     error
+}
+
+// FanOutError reports the handlers that failed for an event that failed per the policy
+type FanOutError struct {
+	EventType domain.EventType
+	Failures  []HandlerFailure
+	// Handled is how many handlers were run for the event
+	Handled int
+}
+
+func (e *FanOutError) Error() string {
+	failures := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		failures[i] = fmt.Sprintf("%s: %v", f.Handler, f.Err)
+	}
+	return fmt.Sprintf("%d of %d handlers failed for %s event: %s",
+		len(e.Failures), e.Handled, e.EventType, strings.Join(failures, "; "))
+}
+
+// Unwrap returns the handler errors
+func (e *FanOutError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f.Err
+	}
+	return errs
+}
+
+// namedHandler is a handler registered for an event type
+type namedHandler struct {
+	name    string
+	handler EventHandler
+}
+
+// FanOutHandler is an EventHandler that passes each event to every handler registered for
+// its type. The handlers run in parallel and independently of each other: each gets its
+// own copy of the event, and a failing or panicking handler does not stop the others.
+// Whether the event as a whole fails, which is what the consumer acts on, is decided by
+// the failure policy.
+type FanOutHandler struct {
+	mu       sync.RWMutex
+	handlers map[domain.EventType][]namedHandler
+	policy   FailurePolicy
+	logger   *logging.Logger
+}
+
+// NewFanOutHandler creates a fan-out handler with no handlers registered. It fails if
+// policy is not one of the known failure policies.
+func NewFanOutHandler(policy FailurePolicy, logger *logging.Logger) (*FanOutHandler, error) {
+	if !policy.IsValid() {
+		return nil, fmt.Errorf("unknown failure policy %q, want %q or %q", policy, FailOnAny, FailOnAll)
+	}
+	return &FanOutHandler{
+		handlers: make(map[domain.EventType][]namedHandler),
+		policy:   policy,
+		logger:   logger,
+	}, nil
+}
+
+// Register adds handler, identified by name in logs and errors, for the given event types
+func (f *FanOutHandler) Register(name string, handler EventHandler, eventTypes ...domain.EventType) error {
+	if handler == nil {
+		return fmt.Errorf("handler %q is nil", name)
+	}
+	for _, eventType := range eventTypes {
+		if !eventType.IsValid() {
+			return fmt.Errorf("cannot register handler %q for unknown event type %q", name, eventType)
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, eventType := range eventTypes {
+		for _, registered := range f.handlers[eventType] {
+			if registered.name == name {
+				return fmt.Errorf("handler %q is already registered for %s", name, eventType)
+			}
+		}
+		f.handlers[eventType] = append(f.handlers[eventType], namedHandler{name: name, handler: handler})
+	}
+
+	f.logger.Info("event handler registered", "handler", name, "event_types", eventTypes)
+	return nil
+}
+
+// HandleUserCreatedEvent passes the event to the handlers registered for user.created
+func (f *FanOutHandler) HandleUserCreatedEvent(event *events.UserCreatedEvent, requestID, correlationID string) error {
+	return f.dispatch(domain.EventTypeUserCreated, event.EventID, requestID, correlationID, func(h EventHandler) error {
+		copied := *event
+		copied.Metadata = copyMetadata(event.Metadata)
+		return h.HandleUserCreatedEvent(&copied, requestID, correlationID)
+	})
+}
+
+// HandleUserRegisteredEvent passes the event to the handlers registered for user.registered
+func (f *FanOutHandler) HandleUserRegisteredEvent(event *events.UserRegisteredEvent, requestID, correlationID string) error {
+	return f.dispatch(domain.EventTypeUserRegistered, event.EventID, requestID, correlationID, func(h EventHandler) error {
+		copied := *event
+		copied.Metadata = copyMetadata(event.Metadata)
+		return h.HandleUserRegisteredEvent(&copied, requestID, correlationID)
+	})
+}
+
+// HandleUserActivatedEvent passes the event to the handlers registered for user.activated
+func (f *FanOutHandler) HandleUserActivatedEvent(event *events.UserActivatedEvent, requestID, correlationID string) error {
+	return f.dispatch(domain.EventTypeUserActivated, event.EventID, requestID, correlationID, func(h EventHandler) error {
+		copied := *event
+		copied.Metadata = copyMetadata(event.Metadata)
+		return h.HandleUserActivatedEvent(&copied, requestID, correlationID)
+	})
+}
+
+// HandleUserLoginEvent passes the event to the handlers registered for user.login
+func (f *FanOutHandler) HandleUserLoginEvent(event *events.UserLoginEvent, requestID, correlationID string) error {
+	return f.dispatch(domain.EventTypeUserLogin, event.EventID, requestID, correlationID, func(h EventHandler) error {
+		copied := *event
+		copied.Metadata = copyMetadata(event.Metadata)
+		return h.HandleUserLoginEvent(&copied, requestID, correlationID)
+	})
+}
+
+// dispatch runs call against every handler registered for eventType, waits for all of
+// them and applies the failure policy to their results
+func (f *FanOutHandler) dispatch(eventType domain.EventType, eventID, requestID, correlationID string, call func(EventHandler) error) error {
+	f.mu.RLock()
+	handlers := f.handlers[eventType]
+	f.mu.RUnlock()
+
+	if len(handlers) == 0 {
+		f.logger.Warn("no handlers registered for event type",
+			"event_type", eventType,
+			"event_id", eventID,
+			"request_id", requestID,
+			"correlation_id", correlationID)
+		return nil
+	}
+
+	errs := make([]error, len(handlers))
+	var wg sync.WaitGroup
+	for i, h := range handlers {
+		wg.Add(1)
+		go func(i int, h namedHandler) {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					errs[i] = fmt.Errorf("handler panicked: %v", r)
+				}
+			}()
+			errs[i] = call(h.handler)
+		}(i, h)
+	}
+	wg.Wait()
+
+	var failures []HandlerFailure
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		failures = append(failures, HandlerFailure{Handler: handlers[i].name, Err: err})
+		f.logger.Error("event handler failed",
+			"handler", handlers[i].name,
+			"error", err,
+			"event_type", eventType,
+			"event_id", eventID,
+			"request_id", requestID,
+			"correlation_id", correlationID)
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+	if f.policy == FailOnAll && len(failures) < len(handlers) {
+		f.logger.Warn("event partially handled",
+			"event_type", eventType,
+			"event_id", eventID,
+			"failed", len(failures),
+			"handlers", len(handlers),
+			"request_id", requestID,
+			"correlation_id", correlationID)
+		return nil
+	}
+	return &FanOutError{EventType: eventType, Failures: failures, Handled: len(handlers)}
+}
+
+// copyMetadata deep-copies event metadata, so a handler changing its copy of an event
+// does not race the other handlers
+func copyMetadata(metadata map[string]interface{}) map[string]interface{} {
+	if metadata == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(metadata))
+	for key, value := range metadata {
+		copied[key] = copyMetadataValue(value)
+	}
+	return copied
+}
+
+// copyMetadataValue deep-copies the maps and slices a decoded metadata value is made of
+func copyMetadataValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return copyMetadata(v)
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = copyMetadataValue(item)
+		}
+		return copied
+	default:
+		return value
+	}
+}
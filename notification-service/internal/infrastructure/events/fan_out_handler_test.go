@@ -0,0 +1,113 @@
+package events
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"notification-service/internal/domain"
+
+	"backend-core/logging"
+	"backend-shared/events"
+)
+
+// stubHandler handles user.created events with handle and embeds EventHandler for the rest
+type stubHandler struct {
+	EventHandler
+	handle func(event *events.UserCreatedEvent) error
+}
+
+func (h *stubHandler) HandleUserCreatedEvent(event *events.UserCreatedEvent, requestID, correlationID string) error {
+	return h.handle(event)
+}
+
+func newFanOut(t *testing.T, policy FailurePolicy, handlers ...func(*events.UserCreatedEvent) error) *FanOutHandler {
+	t.Helper()
+
+	f, err := NewFanOutHandler(policy, logging.NewNopLogger())
+	if err != nil {
+		t.Fatalf("NewFanOutHandler() error = %v", err)
+	}
+	for i, handle := range handlers {
+		name := string(rune('a' + i))
+		if err := f.Register(name, &stubHandler{handle: handle}, domain.EventTypeUserCreated); err != nil {
+			t.Fatalf("Register(%q) error = %v", name, err)
+		}
+	}
+	return f
+}
+
+func TestNewFanOutHandlerRejectsUnknownPolicy(t *testing.T) {
+	for _, policy := range []FailurePolicy{"", "some"} {
+		if _, err := NewFanOutHandler(policy, logging.NewNopLogger()); err == nil {
+			t.Errorf("NewFanOutHandler(%q) error = nil, want an error", policy)
+		}
+	}
+}
+
+func TestFanOutHandlerGivesEachHandlerACopy(t *testing.T) {
+	mutate := func(event *events.UserCreatedEvent) error {
+		event.Email = "changed@example.com"
+		event.Metadata["source"] = "changed"
+		event.Metadata["tags"].([]interface{})[0] = "changed"
+		return nil
+	}
+	f := newFanOut(t, FailOnAny, mutate, mutate)
+
+	event := &events.UserCreatedEvent{
+		EventID:  "event-1",
+		Email:    "user@example.com",
+		Metadata: map[string]interface{}{"source": "signup", "tags": []interface{}{"new"}},
+	}
+	if err := f.HandleUserCreatedEvent(event, "request-1", "correlation-1"); err != nil {
+		t.Fatalf("HandleUserCreatedEvent() error = %v", err)
+	}
+
+	if event.Email != "user@example.com" || event.Metadata["source"] != "signup" || event.Metadata["tags"].([]interface{})[0] != "new" {
+		t.Errorf("event changed by its handlers: %+v", event)
+	}
+}
+
+func TestFanOutHandlerFailurePolicy(t *testing.T) {
+	errFailed := errors.New("failed")
+	var succeeded atomic.Int32
+	succeed := func(*events.UserCreatedEvent) error {
+		succeeded.Add(1)
+		return nil
+	}
+	fail := func(*events.UserCreatedEvent) error { return errFailed }
+	panics := func(*events.UserCreatedEvent) error { panic("boom") }
+
+	tests := []struct {
+		name          string
+		policy        FailurePolicy
+		handlers      []func(*events.UserCreatedEvent) error
+		wantErr       bool
+		wantSucceeded int32
+	}{
+		{"any with one failure", FailOnAny, []func(*events.UserCreatedEvent) error{succeed, fail}, true, 1},
+		{"any with a failure first", FailOnAny, []func(*events.UserCreatedEvent) error{fail, succeed}, true, 1},
+		{"any with a panic", FailOnAny, []func(*events.UserCreatedEvent) error{panics, succeed}, true, 1},
+		{"all with one failure", FailOnAll, []func(*events.UserCreatedEvent) error{fail, succeed}, false, 1},
+		{"all with every handler failing", FailOnAll, []func(*events.UserCreatedEvent) error{fail, panics}, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			succeeded.Store(0)
+			f := newFanOut(t, tt.policy, tt.handlers...)
+
+			err := f.HandleUserCreatedEvent(&events.UserCreatedEvent{EventID: "event-1"}, "request-1", "correlation-1")
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			var fanOutErr *FanOutError
+			if tt.wantErr && (!errors.As(err, &fanOutErr) || fanOutErr.Handled != len(tt.handlers)) {
+				t.Errorf("error = %#v, want a *FanOutError for %d handlers", err, len(tt.handlers))
+			}
+			if got := succeeded.Load(); got != tt.wantSucceeded {
+				t.Errorf("succeeding handlers ran %d times, want %d", got, tt.wantSucceeded)
+			}
+		})
+	}
+}
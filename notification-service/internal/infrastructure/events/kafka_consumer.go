@@ -3,12 +3,17 @@ package events
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
+	"notification-service/internal/domain"
+
 	"backend-core/logging"
 	"backend-core/messaging/kafka/config"
 	"backend-core/messaging/kafka/consumer"
+	"backend-core/messaging/kafka/producer"
+	"backend-core/messaging/kafka/retry"
 	"backend-shared/events"
 )
 
@@ -20,14 +25,20 @@ type EventHandler interface {
 	HandleUserLoginEvent(event *events.UserLoginEvent, requestID, correlationID string) error
 }
 
-// KafkaConsumer handles Kafka message consumption using backend-core
+// KafkaConsumer handles Kafka message consumption using backend-core. Offsets are committed
+// only once every message of a poll was handled, or sent to a retry topic or the dead letter
+// topic, so no event is lost when the service stops mid-batch.
 type KafkaConsumer struct {
 	consumer consumer.Consumer
-	logger   *logging.Logger
+	// producer sends the messages that failed to the retry topics and the dead letter topic
+	producer    producer.Producer
+	retryConfig *retry.ConsumerRetryConfig
+	logger      *logging.Logger
 }
 
-// NewKafkaConsumer creates a new Kafka consumer using backend-core
-func NewKafkaConsumer(brokers []string, groupID string, topics []string, logger *logging.Logger) (*KafkaConsumer, error) {
+// NewKafkaConsumer creates a new Kafka consumer using backend-core. Events that fail are
+// retried and dead-lettered as retryConfig says.
+func NewKafkaConsumer(brokers []string, groupID string, topics []string, retryConfig *retry.ConsumerRetryConfig, logger *logging.Logger) (*KafkaConsumer, error) {
 	// Create Kafka config for backend-core
 	kafkaConfig := &config.KafkaConfig{
 		BootstrapServers: brokers,
@@ -35,7 +46,7 @@ func NewKafkaConsumer(brokers []string, groupID string, topics []string, logger
 		Consumer: &config.ConsumerConfig{
 			GroupID:           groupID,
 			AutoOffsetReset:   "earliest",
-			EnableAutoCommit:  true,
+			EnableAutoCommit:  false,
 			SessionTimeout:    30 * time.Second,
 			HeartbeatInterval: 3 * time.Second,
 			MaxPollRecords:    500,
@@ -52,25 +63,42 @@ func NewKafkaConsumer(brokers []string, groupID string, topics []string, logger
 		return nil, fmt.Errorf("failed to create Kafka consumer: %w", err)
 	}
 
+	// Failed events are only committed once they are safely on a retry or dead letter topic
+	producerConfig := &config.KafkaConfig{
+		BootstrapServers: brokers,
+		ClientID:         "notification-service-retry-producer",
+		Producer: &config.ProducerConfig{
+			Acks:             "all",
+			Retries:          3,
+			EnableIdempotent: true,
+		},
+	}
+	producerInstance, err := producer.NewKafkaProducer(producerConfig, logger)
+	if err != nil {
+		consumerInstance.Close()
+		return nil, fmt.Errorf("failed to create Kafka retry producer: %w", err)
+	}
+
 	return &KafkaConsumer{
-		consumer: consumerInstance,
-		logger:   logger,
+		consumer:    consumerInstance,
+		producer:    producerInstance,
+		retryConfig: retryConfig,
+		logger:      logger,
 	}, nil
 }
 
-// extractCorrelationIDs extracts request and correlation IDs from Kafka message headers
-func (c *KafkaConsumer) extractCorrelationIDs(headers map[string]string) (requestID, correlationID string) {
-	for key, value := range headers {
-		switch key {
-		case "X-Request-ID", "request_id":
-			if requestID == "" {
-				requestID = value
-			}
-		case "X-Correlation-ID", "correlation_id":
-			if correlationID == "" {
-				correlationID = value
-			}
-		}
+// extractCorrelationIDs extracts request and correlation IDs from Kafka message headers,
+// accepting both the HTTP header names and their snake_case forms in any case
+func (c *KafkaConsumer) extractCorrelationIDs(message *consumer.ConsumerMessage) (requestID, correlationID string) {
+	headers := message.HeadersLower()
+
+	requestID = headers["x-request-id"]
+	if requestID == "" {
+		requestID = headers["request_id"]
+	}
+	correlationID = headers["x-correlation-id"]
+	if correlationID == "" {
+		correlationID = headers["correlation_id"]
 	}
 
 	// If correlation ID is empty, use request ID
@@ -81,12 +109,28 @@ func (c *KafkaConsumer) extractCorrelationIDs(headers map[string]string) (reques
 	return requestID, correlationID
 }
 
-// Close closes the Kafka consumer
+// Close closes the Kafka consumer and its retry producer
 func (c *KafkaConsumer) Close() error {
+	var errs []error
 	if c.consumer != nil {
-		return c.consumer.Close()
+		errs = append(errs, c.consumer.Close())
 	}
-	return nil
+	if c.producer != nil {
+		errs = append(errs, c.producer.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// retryTopics returns the retry topics of topics, which failed events are sent to with
+// their attempt number
+func (c *KafkaConsumer) retryTopics(topics []string) []string {
+	var retryTopics []string
+	for attempt := 1; attempt <= c.retryConfig.MaxAttempts; attempt++ {
+		for _, topic := range topics {
+			retryTopics = append(retryTopics, fmt.Sprintf("%s.retry.%d.%s", c.retryConfig.RetryTopicPrefix, attempt, topic))
+		}
+	}
+	return retryTopics
 }
 
 // ConsumeMessages starts consuming messages from Kafka using backend-core
@@ -95,11 +139,18 @@ func (c *KafkaConsumer) ConsumeMessages(ctx context.Context, topics []string, ha
 		"topics", topics,
 		"client_id", "notification-service-consumer")
 
-	// Subscribe to topics
-	if err := c.consumer.Subscribe(topics); err != nil {
+	// Subscribe to topics, and to their retry topics to process the events that failed
+	subscribed := append(append([]string{}, topics...), c.retryTopics(topics)...)
+	if err := c.consumer.Subscribe(subscribed); err != nil {
 		return fmt.Errorf("failed to subscribe to topics: %w", err)
 	}
 
+	// A message whose event fails per the handler's failure policy is sent to the next retry
+	// topic, or to the dead letter topic once its retries are used up
+	retrying := retry.NewRetryableMessageHandler(func(ctx context.Context, message *consumer.ConsumerMessage) error {
+		return c.processMessage(message, handler)
+	}, c.retryConfig, c.producer, c.logger)
+
 	// Start consuming messages
 	for {
 		select {
@@ -118,20 +169,79 @@ func (c *KafkaConsumer) ConsumeMessages(ctx context.Context, topics []string, ha
 				continue
 			}
 
-			// Process each message
+			if len(messages) == 0 {
+				continue
+			}
+
+			// Handle each message. A message that could neither be handled nor sent on to a
+			// retry or dead letter topic stops consumption with the batch uncommitted, so it
+			// is redelivered when the service restarts.
 			for _, message := range messages {
-				if err := c.processMessage(message, handler); err != nil {
-					c.logger.Error("failed to process message", "error", err)
+				if err := waitForRetry(ctx, message); err != nil {
+					c.logger.Info("context canceled, stopping consumption with the batch uncommitted")
+					return nil
 				}
+				if err := retrying.Handle(ctx, originalTopicOf(message)); err != nil {
+					return fmt.Errorf("failed to handle message at %s/%d/%d: %w",
+						message.Topic, message.Partition, message.Offset, err)
+				}
+			}
+
+			if err := c.consumer.Commit(); err != nil {
+				c.logger.Error("failed to commit offsets", "error", err)
 			}
 		}
 	}
 }
 
+// originalTopicOf returns message as consumed from the topic it was first published to.
+// Messages read from a retry topic carry that topic in a header, and the retry handler
+// names the next retry topic and the dead letter metadata after it.
+func originalTopicOf(message *consumer.ConsumerMessage) *consumer.ConsumerMessage {
+	originalTopic, ok := message.Header("original-topic")
+	if !ok || originalTopic == message.Topic {
+		return message
+	}
+	copied := *message
+	copied.Topic = originalTopic
+	return &copied
+}
+
+// waitForRetry waits until a message read from a retry topic is due, per the time it was
+// scheduled at and the backoff it was given. Other messages are due immediately.
+func waitForRetry(ctx context.Context, message *consumer.ConsumerMessage) error {
+	scheduledAt, ok := message.Header("scheduled-at")
+	if !ok {
+		return nil
+	}
+	retryAfter, _ := message.Header("retry-after")
+	scheduled, err := time.Parse(time.RFC3339, scheduledAt)
+	if err != nil {
+		return nil
+	}
+	backoff, err := time.ParseDuration(retryAfter)
+	if err != nil {
+		return nil
+	}
+
+	wait := time.Until(scheduled.Add(backoff))
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // processMessage processes a single Kafka message using backend-core ConsumerMessage
 func (c *KafkaConsumer) processMessage(message *consumer.ConsumerMessage, handler EventHandler) error {
 	// Extract correlation IDs from message headers
-	requestID, correlationID := c.extractCorrelationIDs(message.Headers)
+	requestID, correlationID := c.extractCorrelationIDs(message)
 
 	c.logger.Info("received message",
 		"topic", message.Topic,
@@ -141,135 +251,37 @@ func (c *KafkaConsumer) processMessage(message *consumer.ConsumerMessage, handle
 		"request_id", requestID,
 		"correlation_id", correlationID)
 
-	// Parse the message
-	var eventData map[string]interface{}
-	if err := json.Unmarshal(message.Value, &eventData); err != nil {
-		c.logger.Error("failed to parse message", "error", err)
-		return err
-	}
-
-	// Debug: Log the actual message structure
-	c.logger.Info("parsed message data", "data", eventData)
-
-	// Extract event type from shared events structure
-	eventType, ok := eventData["event_type"].(string)
-	if !ok {
-		// Fallback to "type" field for backward compatibility
-		if fallbackType, fallbackOk := eventData["type"].(string); fallbackOk {
-			eventType = fallbackType
-		} else {
-			c.logger.Error("missing event_type field", "available_fields", getKeys(eventData))
-			return fmt.Errorf("missing event_type field")
-		}
-	}
-
-	// Route to appropriate handler based on shared event types
-	switch eventType {
-	case "user.created":
-		return c.handleUserCreatedEvent(eventData, handler, requestID, correlationID)
-	case "user.registered":
-		return c.handleUserRegisteredEvent(eventData, handler, requestID, correlationID)
-	case "user.activated":
-		return c.handleUserActivatedEvent(eventData, handler, requestID, correlationID)
-	case "user.login":
-		return c.handleUserLoginEvent(eventData, handler, requestID, correlationID)
-	// Backward compatibility
-	case "UserCreated":
-		return c.handleUserCreatedEvent(eventData, handler, requestID, correlationID)
-	case "user_registered":
-		return c.handleUserRegisteredEvent(eventData, handler, requestID, correlationID)
-	case "user_activated":
-		return c.handleUserActivatedEvent(eventData, handler, requestID, correlationID)
-	case "user_login":
-		return c.handleUserLoginEvent(eventData, handler, requestID, correlationID)
-	default:
-		c.logger.Warn("unknown event type", "event_type", eventType)
-		return &EventProcessingError{Message: "Unknown event type: " + eventType}
-	}
-}
-
-// handleUserCreatedEvent handles user created events
-func (c *KafkaConsumer) handleUserCreatedEvent(data map[string]interface{}, handler EventHandler, requestID, correlationID string) error {
-	// Handle both shared event format and legacy format
-	var eventID, eventType, aggregateID, userID, username, email string
-	var timestamp time.Time
-	var version int = 1
-	var metadata map[string]interface{}
-
-	// Check if this is the new shared event format (nested data)
-	if nestedData, ok := data["data"].(map[string]interface{}); ok {
-		// This is the new format with nested data
-		eventID = getStringFromMap(data, "id")
-		eventType = getStringFromMap(data, "type")
-		aggregateID = getStringFromMap(nestedData, "user_id")
-		userID = getStringFromMap(nestedData, "user_id")
-		username = getStringFromMap(nestedData, "username")
-		email = getStringFromMap(nestedData, "email")
-
-		// Parse timestamp
-		if timestampStr, ok := data["timestamp"].(string); ok {
-			if parsedTime, err := time.Parse(time.RFC3339, timestampStr); err == nil {
-				timestamp = parsedTime
-			}
-		}
-
-		// Parse metadata
-		if meta, ok := data["metadata"].(map[string]interface{}); ok {
-			metadata = meta
-		}
-
-		// Parse version
-		if v, ok := data["version"].(string); ok {
-			if parsedVersion, err := fmt.Sscanf(v, "%d", &version); err == nil && parsedVersion > 0 {
-				// version is already set
-			}
-		}
-	} else {
-		// This is the legacy format (flat structure)
-		eventID = getStringFromMap(data, "event_id")
-		eventType = getStringFromMap(data, "event_type")
-		aggregateID = getStringFromMap(data, "aggregate_id")
-		userID = getStringFromMap(data, "user_id")
-		username = getStringFromMap(data, "username")
-		email = getStringFromMap(data, "email")
-
-		// Parse timestamp
-		if timestampStr, ok := data["timestamp"].(string); ok {
-			if parsedTime, err := time.Parse(time.RFC3339, timestampStr); err == nil {
-				timestamp = parsedTime
-			}
-		}
-
-		// Parse metadata
-		if meta, ok := data["metadata"].(map[string]interface{}); ok {
-			metadata = meta
+	// Map the message to a typed user event
+	event, err := MapUserEvent(message.Value)
+	if err != nil {
+		var mappingErr *MappingError
+		if errors.As(err, &mappingErr) && errors.Is(err, ErrUnknownEventType) {
+			// Retrying cannot make an unknown event known, so it goes straight to the dead
+			// letter topic, from where it can be replayed once it is handled
+			c.logger.Warn("unknown event type",
+				"event_type", mappingErr.EventType,
+				"available_fields", messageFields(message.Value),
+				"topic", message.Topic,
+				"partition", message.Partition,
+				"offset", message.Offset,
+				"key", string(message.Key),
+				"request_id", requestID,
+				"correlation_id", correlationID)
+			return retry.NonRetryable(&EventProcessingError{Message: "Unknown event type: " + mappingErr.EventType})
 		}
 
-		// Parse version
-		if v, ok := data["version"].(float64); ok {
-			version = int(v)
-		}
+		c.logger.Error("failed to map message",
+			"error", err,
+			"available_fields", messageFields(message.Value),
+			"topic", message.Topic,
+			"partition", message.Partition,
+			"offset", message.Offset,
+			"request_id", requestID,
+			"correlation_id", correlationID)
+		return retry.NonRetryable(err)
 	}
 
-	// Create UserCreatedEvent
-	event := &events.UserCreatedEvent{
-		EventID:     eventID,
-		EventType:   eventType,
-		AggregateID: aggregateID,
-		UserID:      userID,
-		Username:    username,
-		Email:       email,
-		Timestamp:   timestamp,
-		Version:     version,
-		Metadata:    metadata,
-	}
-
-	// Use current time as fallback if timestamp is zero
-	if event.Timestamp.IsZero() {
-		event.Timestamp = time.Now()
-	}
-
-	c.logger.Info("processing user created event",
+	c.logger.Info("processing user event",
 		"event_id", event.EventID,
 		"event_type", event.EventType,
 		"user_id", event.UserID,
@@ -280,73 +292,33 @@ func (c *KafkaConsumer) handleUserCreatedEvent(data map[string]interface{}, hand
 		"request_id", requestID,
 		"correlation_id", correlationID)
 
-	return handler.HandleUserCreatedEvent(event, requestID, correlationID)
-}
-
-// getStringFromMap safely extracts string values from map
-func getStringFromMap(data map[string]interface{}, key string) string {
-	if value, ok := data[key]; ok {
-		if str, ok := value.(string); ok {
-			return str
-		}
-	}
-	return ""
-}
-
-// handleUserRegisteredEvent handles user registered events
-func (c *KafkaConsumer) handleUserRegisteredEvent(data map[string]interface{}, handler EventHandler, requestID, correlationID string) error {
-	var event events.UserRegisteredEvent
-	if err := json.Unmarshal([]byte(fmt.Sprintf("%v", data)), &event); err != nil {
-		c.logger.Error("failed to parse user registered event", "error", err)
-		return err
+	// Route to appropriate handler; MapUserEvent only returns known event types
+	switch event.EventType {
+	case domain.EventTypeUserCreated:
+		return handler.HandleUserCreatedEvent(toUserCreatedEvent(event), requestID, correlationID)
+	case domain.EventTypeUserRegistered:
+		return handler.HandleUserRegisteredEvent(toUserRegisteredEvent(event), requestID, correlationID)
+	case domain.EventTypeUserActivated:
+		return handler.HandleUserActivatedEvent(toUserActivatedEvent(event), requestID, correlationID)
+	case domain.EventTypeUserLogin:
+		return handler.HandleUserLoginEvent(toUserLoginEvent(event), requestID, correlationID)
+	default:
+		return &EventProcessingError{Message: "Unknown event type: " + string(event.EventType)}
 	}
-
-	c.logger.Info("processing user registered event",
-		"event_id", event.EventID,
-		"user_id", event.UserID,
-		"username", event.Username,
-		"email", event.Email,
-		"timestamp", event.Timestamp.Format(time.RFC3339))
-
-	return handler.HandleUserRegisteredEvent(&event, requestID, correlationID)
 }
 
-// handleUserActivatedEvent handles user activated events
-func (c *KafkaConsumer) handleUserActivatedEvent(data map[string]interface{}, handler EventHandler, requestID, correlationID string) error {
-	var event events.UserActivatedEvent
-	if err := json.Unmarshal([]byte(fmt.Sprintf("%v", data)), &event); err != nil {
-		c.logger.Error("failed to parse user activated event", "error", err)
-		return err
+// messageFields returns the top-level field names of a message value, for logging
+// messages that could not be mapped
+func messageFields(value []byte) []string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(value, &fields); err != nil {
+		return nil
 	}
-
-	c.logger.Info("processing user activated event",
-		"event_id", event.EventID,
-		"user_id", event.UserID,
-		"username", event.Username,
-		"email", event.Email,
-		"timestamp", event.Timestamp.Format(time.RFC3339))
-
-	return handler.HandleUserActivatedEvent(&event, requestID, correlationID)
-}
-
-// handleUserLoginEvent handles user login events
-func (c *KafkaConsumer) handleUserLoginEvent(data map[string]interface{}, handler EventHandler, requestID, correlationID string) error {
-	var event events.UserLoginEvent
-	if err := json.Unmarshal([]byte(fmt.Sprintf("%v", data)), &event); err != nil {
-		c.logger.Error("failed to parse user login event", "error", err)
-		return err
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
 	}
-
-	c.logger.Info("processing user login event",
-		"event_id", event.EventID,
-		"user_id", event.UserID,
-		"username", event.Username,
-		"email", event.Email,
-		"ip_address", event.IPAddress,
-		"user_agent", event.UserAgent,
-		"timestamp", event.Timestamp.Format(time.RFC3339))
-
-	return handler.HandleUserLoginEvent(&event, requestID, correlationID)
+	return keys
 }
 
 // EventProcessingError represents an error in event processing
@@ -357,12 +329,3 @@ type EventProcessingError struct {
 func (e *EventProcessingError) Error() string {
 	return e.Message
 }
-
-// getKeys returns the keys of a map
-func getKeys(m map[string]interface{}) []string {
-	keys := make([]string, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
-	}
-	return keys
-}
@@ -0,0 +1,338 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"backend-core/logging"
+	"backend-core/messaging/kafka/consumer"
+	"backend-core/messaging/kafka/producer"
+	"backend-core/messaging/kafka/retry"
+	"backend-shared/events"
+)
+
+func TestExtractCorrelationIDs(t *testing.T) {
+	tests := []struct {
+		name          string
+		headers       map[string]string
+		requestID     string
+		correlationID string
+	}{
+		{
+			name:          "HTTP header names",
+			headers:       map[string]string{"X-Request-ID": "req-1", "X-Correlation-ID": "corr-1"},
+			requestID:     "req-1",
+			correlationID: "corr-1",
+		},
+		{
+			name:          "lower-cased header names",
+			headers:       map[string]string{"x-request-id": "req-1", "x-correlation-id": "corr-1"},
+			requestID:     "req-1",
+			correlationID: "corr-1",
+		},
+		{
+			name:          "snake_case header names",
+			headers:       map[string]string{"Request_ID": "req-1", "correlation_id": "corr-1"},
+			requestID:     "req-1",
+			correlationID: "corr-1",
+		},
+		{
+			name:          "correlation ID defaults to the request ID",
+			headers:       map[string]string{"X-Request-Id": "req-1"},
+			requestID:     "req-1",
+			correlationID: "req-1",
+		},
+		{
+			name:    "no headers",
+			headers: nil,
+		},
+	}
+
+	c := &KafkaConsumer{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestID, correlationID := c.extractCorrelationIDs(&consumer.ConsumerMessage{Headers: tt.headers})
+			if requestID != tt.requestID || correlationID != tt.correlationID {
+				t.Errorf("extractCorrelationIDs() = %q, %q, want %q, %q",
+					requestID, correlationID, tt.requestID, tt.correlationID)
+			}
+		})
+	}
+}
+
+// recordingHandler records which handler was called, with the user ID and correlation ID it got
+type recordingHandler struct {
+	called        string
+	userID        string
+	correlationID string
+}
+
+func (h *recordingHandler) record(called, userID, correlationID string) error {
+	h.called, h.userID, h.correlationID = called, userID, correlationID
+	return nil
+}
+
+func (h *recordingHandler) HandleUserCreatedEvent(event *events.UserCreatedEvent, requestID, correlationID string) error {
+	return h.record("created", event.UserID, correlationID)
+}
+
+func (h *recordingHandler) HandleUserRegisteredEvent(event *events.UserRegisteredEvent, requestID, correlationID string) error {
+	return h.record("registered", event.UserID, correlationID)
+}
+
+func (h *recordingHandler) HandleUserActivatedEvent(event *events.UserActivatedEvent, requestID, correlationID string) error {
+	return h.record("activated", event.UserID, correlationID)
+}
+
+func (h *recordingHandler) HandleUserLoginEvent(event *events.UserLoginEvent, requestID, correlationID string) error {
+	return h.record("login", event.UserID, correlationID)
+}
+
+func TestProcessMessageRoutesEachEventType(t *testing.T) {
+	tests := []struct {
+		value  string
+		called string
+	}{
+		{value: `{"event_type":"user.created","user_id":"u1","email":"a@example.com"}`, called: "created"},
+		{value: `{"event_type":"user.registered","user_id":"u1","email":"a@example.com"}`, called: "registered"},
+		{value: `{"event_type":"user.activated","user_id":"u1","email":"a@example.com"}`, called: "activated"},
+		{value: `{"event_type":"user.login","user_id":"u1"}`, called: "login"},
+		{value: `{"type":"user.activated","data":{"user_id":"u1","email":"a@example.com"}}`, called: "activated"},
+		{value: `{"event_type":"user_activated","user_id":"u1","email":"a@example.com"}`, called: "activated"},
+	}
+
+	c := &KafkaConsumer{logger: logging.NewNopLogger()}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			handler := &recordingHandler{}
+			message := &consumer.ConsumerMessage{
+				Topic:   "user-events",
+				Value:   []byte(tt.value),
+				Headers: map[string]string{"X-Correlation-ID": "corr-1"},
+			}
+
+			if err := c.processMessage(message, handler); err != nil {
+				t.Fatalf("processMessage() error = %v", err)
+			}
+			if handler.called != tt.called || handler.userID != "u1" || handler.correlationID != "corr-1" {
+				t.Errorf("handler got %q for user %q with correlation ID %q, want %q for u1 with corr-1",
+					handler.called, handler.userID, handler.correlationID, tt.called)
+			}
+		})
+	}
+}
+
+func TestProcessMessageDeadLettersUnknownEventTypes(t *testing.T) {
+	c := &KafkaConsumer{logger: logging.NewNopLogger()}
+	handler := &recordingHandler{}
+	message := &consumer.ConsumerMessage{
+		Topic: "user-events",
+		Value: []byte(`{"event_type":"user.deleted","user_id":"u1"}`),
+	}
+
+	err := c.processMessage(message, handler)
+	var processingErr *EventProcessingError
+	if !errors.As(err, &processingErr) {
+		t.Fatalf("processMessage() error = %v, want an EventProcessingError", err)
+	}
+	if retry.IsRetryable(err) {
+		t.Errorf("processMessage() error = %v is retryable, want it dead-lettered", err)
+	}
+	if handler.called != "" {
+		t.Errorf("expected no handler to be called, %q was", handler.called)
+	}
+}
+
+func TestProcessMessageRejectsInvalidEvents(t *testing.T) {
+	c := &KafkaConsumer{logger: logging.NewNopLogger()}
+	for _, value := range []string{
+		`not json`,
+		`{"user_id":"u1"}`,
+		`{"event_type":"user.created","email":"a@example.com"}`,
+	} {
+		handler := &recordingHandler{}
+		err := c.processMessage(&consumer.ConsumerMessage{Value: []byte(value)}, handler)
+		var mappingErr *MappingError
+		if !errors.As(err, &mappingErr) {
+			t.Errorf("processMessage(%s) error = %v, want a MappingError", value, err)
+		}
+		if retry.IsRetryable(err) {
+			t.Errorf("processMessage(%s) error = %v is retryable, want it dead-lettered", value, err)
+		}
+		if handler.called != "" {
+			t.Errorf("processMessage(%s) called the %q handler", value, handler.called)
+		}
+	}
+}
+
+// fakeConsumer hands out batches one poll at a time and cancels the consumption once they
+// are used up
+type fakeConsumer struct {
+	consumer.Consumer
+	batches    [][]*consumer.ConsumerMessage
+	cancel     context.CancelFunc
+	subscribed []string
+	commits    int
+}
+
+func (c *fakeConsumer) Subscribe(topics []string) error {
+	c.subscribed = topics
+	return nil
+}
+
+func (c *fakeConsumer) Poll(ctx context.Context, timeout time.Duration) ([]*consumer.ConsumerMessage, error) {
+	if len(c.batches) == 0 {
+		c.cancel()
+		return nil, context.Canceled
+	}
+	batch := c.batches[0]
+	c.batches = c.batches[1:]
+	return batch, nil
+}
+
+func (c *fakeConsumer) Commit() error {
+	c.commits++
+	return nil
+}
+
+// fakeProducer records the topics it sends to and fails with err
+type fakeProducer struct {
+	producer.Producer
+	err  error
+	sent []*producer.ProducerMessage
+}
+
+func (p *fakeProducer) Send(ctx context.Context, message *producer.ProducerMessage) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.sent = append(p.sent, message)
+	return nil
+}
+
+func userCreatedMessage(topic string, offset int64) *consumer.ConsumerMessage {
+	return &consumer.ConsumerMessage{
+		Topic:  topic,
+		Offset: offset,
+		Value:  []byte(`{"event_type":"user.created","user_id":"u1","email":"a@example.com"}`),
+	}
+}
+
+func TestConsumeMessagesCommitsOnlyHandledOrRoutedBatches(t *testing.T) {
+	errFailed := errors.New("smtp unavailable")
+	retryConfig := retry.DefaultConsumerRetryConfig()
+	retryConfig.MaxAttempts = 2
+	retryConfig.DLQTopic = "notification.dlq"
+	retryConfig.RetryTopicPrefix = "notification-service"
+
+	tests := []struct {
+		name        string
+		message     *consumer.ConsumerMessage
+		handle      func(*events.UserCreatedEvent) error
+		sendErr     error
+		wantErr     bool
+		wantTopics  []string
+		wantCommits int
+	}{
+		{
+			name:        "handled",
+			message:     userCreatedMessage("user.events", 1),
+			handle:      func(*events.UserCreatedEvent) error { return nil },
+			wantCommits: 1,
+		},
+		{
+			name:        "policy failure is retried",
+			message:     userCreatedMessage("user.events", 1),
+			handle:      func(*events.UserCreatedEvent) error { return errFailed },
+			wantTopics:  []string{"notification-service.retry.1.user.events"},
+			wantCommits: 1,
+		},
+		{
+			name: "retries used up are dead-lettered",
+			message: &consumer.ConsumerMessage{
+				Topic:   "notification-service.retry.2.user.events",
+				Value:   userCreatedMessage("", 0).Value,
+				Headers: map[string]string{"retry-attempt": "2", "original-topic": "user.events"},
+			},
+			handle:      func(*events.UserCreatedEvent) error { return errFailed },
+			wantTopics:  []string{"notification.dlq"},
+			wantCommits: 1,
+		},
+		{
+			name:        "unknown event is dead-lettered",
+			message:     &consumer.ConsumerMessage{Topic: "user.events", Value: []byte(`{"event_type":"user.deleted"}`)},
+			wantTopics:  []string{"notification.dlq"},
+			wantCommits: 1,
+		},
+		{
+			name:    "unroutable failure is not committed",
+			message: userCreatedMessage("user.events", 1),
+			handle:  func(*events.UserCreatedEvent) error { return errFailed },
+			sendErr: errors.New("broker unavailable"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			source := &fakeConsumer{batches: [][]*consumer.ConsumerMessage{{tt.message}}, cancel: cancel}
+			sink := &fakeProducer{err: tt.sendErr}
+			c := &KafkaConsumer{consumer: source, producer: sink, retryConfig: retryConfig, logger: logging.NewNopLogger()}
+			handler := newFanOut(t, FailOnAny, func(event *events.UserCreatedEvent) error {
+				if tt.handle == nil {
+					t.Error("handler called for an event that cannot be mapped")
+					return nil
+				}
+				return tt.handle(event)
+			})
+
+			err := c.ConsumeMessages(ctx, []string{"user.events"}, handler)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ConsumeMessages() error = %v, want error %v", err, tt.wantErr)
+			}
+			var topics []string
+			for _, sent := range sink.sent {
+				topics = append(topics, sent.Topic)
+			}
+			if !reflect.DeepEqual(topics, tt.wantTopics) {
+				t.Errorf("messages sent to %q, want %q", topics, tt.wantTopics)
+			}
+			if source.commits != tt.wantCommits {
+				t.Errorf("commits = %d, want %d", source.commits, tt.wantCommits)
+			}
+			wantSubscribed := []string{"user.events"}
+			for attempt := 1; attempt <= retryConfig.MaxAttempts; attempt++ {
+				wantSubscribed = append(wantSubscribed, "notification-service.retry."+strconv.Itoa(attempt)+".user.events")
+			}
+			if !reflect.DeepEqual(source.subscribed, wantSubscribed) {
+				t.Errorf("subscribed to %q, want %q", source.subscribed, wantSubscribed)
+			}
+		})
+	}
+}
+
+func TestWaitForRetryHonorsTheBackoff(t *testing.T) {
+	due := &consumer.ConsumerMessage{Headers: map[string]string{
+		"scheduled-at": time.Now().Add(-time.Minute).Format(time.RFC3339),
+		"retry-after":  "1s",
+	}}
+	if err := waitForRetry(context.Background(), due); err != nil {
+		t.Errorf("waitForRetry() of a due message error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	pending := &consumer.ConsumerMessage{Headers: map[string]string{
+		"scheduled-at": time.Now().Format(time.RFC3339),
+		"retry-after":  "1m",
+	}}
+	if err := waitForRetry(ctx, pending); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("waitForRetry() of a pending message error = %v, want it to wait until canceled", err)
+	}
+}
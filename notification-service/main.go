@@ -10,12 +10,16 @@ import (
 
 	"notification-service/internal/application/notification"
 	"notification-service/internal/config"
+	"notification-service/internal/domain"
 	"notification-service/internal/infrastructure/events"
 
 	"backend-core/logging"
 	"backend-core/wire"
 )
 
+// shutdownTimeout bounds how long shutdown waits for the consumer to stop
+const shutdownTimeout = 10 * time.Second
+
 func main() {
 	// Load configuration
 	cfg, err := config.Load()
@@ -30,11 +34,27 @@ func main() {
 
 	logger.Info("starting notification-service", "version", "1.0.0", "port", cfg.Server.Port)
 
-	// Create notification handler
+	// Create notification handler and fan events out to it
 	notificationHandler := notification.NewNotificationHandler(logger)
+	eventHandler, err := events.NewFanOutHandler(events.FailurePolicy(cfg.Handlers.FailurePolicy), logger)
+	if err != nil {
+		logger.Fatal("Invalid event handler configuration", logging.Error(err))
+	}
+	if err := eventHandler.Register("notifications", notificationHandler,
+		domain.EventTypeUserCreated,
+		domain.EventTypeUserRegistered,
+		domain.EventTypeUserActivated,
+		domain.EventTypeUserLogin,
+	); err != nil {
+		logger.Fatal("Failed to register notification handler", logging.Error(err))
+	}
 
 	// Create Kafka consumer using backend-core
-	consumer, err := events.NewKafkaConsumer(cfg.Kafka.Brokers, cfg.Kafka.GroupID, []string{cfg.Kafka.Topics.UserEvents}, logger)
+	retryConfig, err := cfg.Kafka.RetryConfig()
+	if err != nil {
+		logger.Fatal("Invalid Kafka retry configuration", logging.Error(err))
+	}
+	consumer, err := events.NewKafkaConsumer(cfg.Kafka.Brokers, cfg.Kafka.GroupID, []string{cfg.Kafka.Topics.UserEvents}, retryConfig, logger)
 	if err != nil {
 		logger.Fatal("Failed to create Kafka consumer", logging.Error(err))
 	}
@@ -45,8 +65,10 @@ func main() {
 	defer cancel()
 
 	// Start consuming messages
+	consumerDone := make(chan struct{})
 	go func() {
-		if err := consumer.ConsumeMessages(ctx, []string{cfg.Kafka.Topics.UserEvents}, notificationHandler); err != nil {
+		defer close(consumerDone)
+		if err := consumer.ConsumeMessages(ctx, []string{cfg.Kafka.Topics.UserEvents}, eventHandler); err != nil {
 			logger.Error("failed to consume messages", "error", err)
 		}
 	}()
@@ -59,13 +81,23 @@ func main() {
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+
+	// A consumer that stopped on its own left its batch uncommitted; exit so a restart redelivers it
+	select {
+	case <-quit:
+	case <-consumerDone:
+		logger.Error("Kafka consumer stopped, shutting down")
+	}
 
 	logger.Info("Shutting down notification service...")
 	cancel()
 
-	// Give some time for graceful shutdown
-	time.Sleep(2 * time.Second)
+	// Wait for the message being handled, if any, to finish
+	select {
+	case <-consumerDone:
+	case <-time.After(shutdownTimeout):
+		logger.Warn("Notification service stopped before the consumer finished")
+	}
 
 	logger.Info("Notification service stopped")
 }